@@ -0,0 +1,300 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// BalanceClient is the part of ripple.Client needed by a Tracker
+type BalanceClient interface {
+	AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error)
+	AccountLinesCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountLinesResult, error)
+}
+
+// Balance is a balance of a tracked account, in XRP when Currency is zero
+type Balance struct {
+	Counterparty   data.Account  // Zero for XRP
+	Currency       data.Currency // Zero for XRP
+	Value          data.Value
+	LedgerSequence uint32 // Of the ledger it was loaded from or last changed in
+}
+
+// BalanceEvent is a change to a balance of a tracked account, made by a
+// validated transaction
+type BalanceEvent struct {
+	data.BalanceChange
+	LedgerSequence uint32
+	Hash           data.Hash256 // Of the transaction
+	// Whether the transaction was a partial payment. The change is what
+	// it delivered all the same, rather than its Amount.
+	PartialPayment bool
+}
+
+func (e BalanceEvent) String() string {
+	return fmt.Sprintf("%s Ledger: %d Hash: %s", e.BalanceChange, e.LedgerSequence, e.Hash)
+}
+
+// Tracker keeps the XRP and IOU balances of a set of accounts. Each
+// account is loaded with account_info and account_lines from the current
+// ledger when tracked, and afterwards its balances are those after the
+// transactions passed to Update, taken from their metadata. Transactions
+// in ledgers before that loaded from are ignored, so the transactions
+// stream should be subscribed to before accounts are tracked. Changes to
+// their trust lines are decoded from the RippleState entries of the
+// metadata too.
+type Tracker struct {
+	// Where changes to the trust lines of tracked accounts are sent,
+	// unless nil. Set before Update is first called. Events must be
+	// received, or Update blocks.
+	TrustLines chan<- *TrustLineEvent
+
+	client   BalanceClient
+	events   chan<- *BalanceEvent
+	mu       sync.Mutex
+	accounts map[data.Account]*balances
+}
+
+type balanceKey struct {
+	counterparty data.Account
+	currency     data.Currency
+}
+
+// balances holds what is known of one account
+type balances struct {
+	loaded bool
+	// Transactions in later ledgers are applied. A ledger loaded from is
+	// open, so its own are too.
+	after    uint32
+	values   map[balanceKey]*Balance
+	deferred []update // Received while loading
+}
+
+// update is a transaction passed to Update, with its balance and trust
+// line changes
+type update struct {
+	msg     *websockets.TransactionStreamMsg
+	changes []data.BalanceChange
+	lines   []rippleStateChange
+}
+
+// NewTracker returns a Tracker which sends every change to a balance to
+// events, unless it is nil. Events must be received, or Update blocks.
+func NewTracker(client BalanceClient, events chan<- *BalanceEvent) *Tracker {
+	return &Tracker{
+		client:   client,
+		events:   events,
+		accounts: make(map[data.Account]*balances),
+	}
+}
+
+// Track loads the balances of accounts which aren't tracked already
+func (t *Tracker) Track(ctx context.Context, accounts ...data.Account) error {
+	for _, account := range accounts {
+		t.mu.Lock()
+		if _, ok := t.accounts[account]; ok {
+			t.mu.Unlock()
+			continue
+		}
+		b := &balances{}
+		t.accounts[account] = b
+		t.mu.Unlock()
+		if err := t.load(ctx, account, b); err != nil {
+			t.mu.Lock()
+			if t.accounts[account] == b {
+				delete(t.accounts, account)
+			}
+			t.mu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+// load fills in b, the balances of account, unless it has been untracked
+// meanwhile
+func (t *Tracker) load(ctx context.Context, account data.Account, b *balances) error {
+	info, err := t.client.AccountInfoCtx(ctx, account)
+	if err != nil {
+		return err
+	}
+	if info.AccountData.Balance == nil {
+		return fmt.Errorf("Account %s has no balance", account)
+	}
+	current, err := t.client.AccountLinesCtx(ctx, account, "current")
+	if err != nil {
+		return err
+	}
+	ledger := info.LedgerSequence
+	values := map[balanceKey]*Balance{
+		{}: {Value: *info.AccountData.Balance, LedgerSequence: ledger},
+	}
+	for _, line := range current.Lines {
+		values[balanceKey{line.Account, line.Currency}] = &Balance{
+			Counterparty:   line.Account,
+			Currency:       line.Currency,
+			Value:          line.Balance.Value,
+			LedgerSequence: ledger,
+		}
+	}
+
+	t.mu.Lock()
+	if t.accounts[account] != b {
+		t.mu.Unlock()
+		return nil
+	}
+	b.loaded, b.values = true, values
+	if ledger > 0 {
+		b.after = ledger - 1
+	}
+	var (
+		events []*BalanceEvent
+		lines  []*TrustLineEvent
+	)
+	for _, u := range b.deferred {
+		e, l := b.apply(account, u)
+		events, lines = append(events, e...), append(lines, l...)
+	}
+	b.deferred = nil
+	t.mu.Unlock()
+	t.send(events, lines)
+	return nil
+}
+
+// Untrack forgets the balances of account
+func (t *Tracker) Untrack(account data.Account) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.accounts, account)
+}
+
+// Balances returns the balances of account, XRP first and then by currency
+// and counterparty, or false if it isn't tracked or hasn't been loaded
+func (t *Tracker) Balances(account data.Account) ([]Balance, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.accounts[account]
+	if !ok || !b.loaded {
+		return nil, false
+	}
+	var all []Balance
+	for _, balance := range b.values {
+		all = append(all, *balance)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if cmp := all[i].Currency.Compare(all[j].Currency); cmp != 0 {
+			return cmp < 0
+		}
+		return all[i].Counterparty.Less(all[j].Counterparty)
+	})
+	return all, true
+}
+
+// Update applies the balance changes of a validated transaction to the
+// accounts it affected which are tracked. Proposed transactions are
+// ignored, as are those whose metadata can't be analysed.
+func (t *Tracker) Update(msg *websockets.TransactionStreamMsg) {
+	if !msg.Validated {
+		return
+	}
+	analysis, err := msg.Transaction.Analyse()
+	if err != nil {
+		return
+	}
+	u := update{msg, analysis.Balances, rippleStateChanges(&msg.Transaction)}
+	t.mu.Lock()
+	var (
+		events []*BalanceEvent
+		lines  []*TrustLineEvent
+	)
+	for account, b := range t.accounts {
+		if !b.loaded {
+			b.deferred = append(b.deferred, u)
+			continue
+		}
+		e, l := b.apply(account, u)
+		events, lines = append(events, e...), append(lines, l...)
+	}
+	t.mu.Unlock()
+	t.send(events, lines)
+}
+
+// Watch calls Update for every message on transactions until it is
+// closed, such as the Transactions channel of a Subscription
+func (t *Tracker) Watch(transactions <-chan *websockets.TransactionStreamMsg) {
+	go func() {
+		for msg := range transactions {
+			t.Update(msg)
+		}
+	}()
+}
+
+// apply sets the balances and trust lines of account changed by u, unless
+// it is from a ledger loaded already. A trust line which is created
+// without a balance is added at zero, and one removed is forgotten.
+func (b *balances) apply(account data.Account, u update) ([]*BalanceEvent, []*TrustLineEvent) {
+	ledger := u.msg.LedgerSequence
+	if ledger == 0 {
+		ledger = u.msg.Transaction.LedgerSequence
+	}
+	if ledger <= b.after {
+		return nil, nil
+	}
+	hash, partial := *u.msg.Transaction.GetHash(), u.msg.Transaction.IsPartialPayment()
+	var events []*BalanceEvent
+	for _, change := range u.changes {
+		if change.Account != account {
+			continue
+		}
+		key := balanceKey{change.Counterparty, change.Currency}
+		balance, ok := b.values[key]
+		if !ok {
+			balance = &Balance{Counterparty: change.Counterparty, Currency: change.Currency}
+			b.values[key] = balance
+		}
+		balance.Value, balance.LedgerSequence = *change.Balance.Clone(), ledger
+		events = append(events, &BalanceEvent{
+			BalanceChange:  change,
+			LedgerSequence: ledger,
+			Hash:           hash,
+			PartialPayment: partial,
+		})
+	}
+	var lines []*TrustLineEvent
+	for _, change := range u.lines {
+		for _, event := range change.events(account) {
+			event.LedgerSequence, event.Hash = ledger, hash
+			key := balanceKey{event.Line.Counterparty, event.Line.Currency}
+			switch _, ok := b.values[key]; {
+			case event.Type == TrustLineRemoved:
+				delete(b.values, key)
+			case !ok:
+				b.values[key] = &Balance{
+					Counterparty:   event.Line.Counterparty,
+					Currency:       event.Line.Currency,
+					Value:          event.Line.Balance,
+					LedgerSequence: ledger,
+				}
+			}
+			lines = append(lines, event)
+		}
+	}
+	return events, lines
+}
+
+func (t *Tracker) send(events []*BalanceEvent, lines []*TrustLineEvent) {
+	if t.events != nil {
+		for _, event := range events {
+			t.events <- event
+		}
+	}
+	if t.TrustLines != nil {
+		for _, event := range lines {
+			t.TrustLines <- event
+		}
+	}
+}
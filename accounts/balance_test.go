@@ -0,0 +1,175 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+type TrackerSuite struct{}
+
+var _ = Suite(&TrackerSuite{})
+
+// snapshot is a BalanceClient which serves one account with 100 XRP and
+// lines of USD and EUR
+type snapshot struct {
+	ledger  uint32
+	loading func() // Called while loading
+	fail    bool
+}
+
+func (s *snapshot) AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error) {
+	if s.fail {
+		return nil, &websockets.CommandError{Name: "actNotFound", Code: 19}
+	}
+	if s.loading != nil {
+		s.loading()
+	}
+	balance, err := data.NewNativeValue(100000000)
+	if err != nil {
+		return nil, err
+	}
+	return &websockets.AccountInfoResult{
+		LedgerSequence: s.ledger,
+		AccountData:    data.AccountRoot{Account: &a, Balance: balance},
+	}, nil
+}
+
+func (s *snapshot) AccountLinesCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountLinesResult, error) {
+	var result websockets.AccountLinesResult
+	return &result, json.Unmarshal([]byte(`{
+		"account": "`+account.String()+`",
+		"lines": [{
+			"account": "rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2",
+			"balance": "1",
+			"currency": "USD",
+			"limit": "10000",
+			"limit_peer": "0"
+		}, {
+			"account": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B",
+			"balance": "2.5",
+			"currency": "EUR",
+			"limit": "10000",
+			"limit_peer": "0"
+		}]
+	}`), &result)
+}
+
+// payment returns a validated Payment of USD from
+// rGgj3GurcrAqgBXGVoS9wvQG3Hjkj5oCbj in ledger 7206416, and its changes
+// to the balances of that account
+func payment(c *C) (*websockets.TransactionStreamMsg, data.Account, []data.BalanceChange) {
+	b, err := ioutil.ReadFile("testdata/payment.json")
+	c.Assert(err, IsNil)
+	msg := &websockets.TransactionStreamMsg{LedgerSequence: 7206416, Validated: true}
+	c.Assert(json.Unmarshal(b, &msg.Transaction), IsNil)
+	sender := msg.Transaction.Transaction.GetBase().Account
+	analysis, err := msg.Transaction.Analyse()
+	c.Assert(err, IsNil)
+	var changes []data.BalanceChange
+	for _, change := range analysis.Balances {
+		if change.Account == sender {
+			changes = append(changes, change)
+		}
+	}
+	c.Assert(len(changes) > 1, Equals, true)
+	return msg, sender, changes
+}
+
+func balancesOf(c *C, t *Tracker, account data.Account) map[string]Balance {
+	all, ok := t.Balances(account)
+	c.Assert(ok, Equals, true)
+	m := make(map[string]Balance)
+	for _, balance := range all {
+		m[fmt.Sprintf("%s/%s", balance.Currency, balance.Counterparty)] = balance
+	}
+	c.Check(all[0].Currency.IsNative(), Equals, true)
+	return m
+}
+
+func (s *TrackerSuite) TestUpdate(c *C) {
+	msg, sender, changes := payment(c)
+	events := make(chan *BalanceEvent, 10)
+	tracker := NewTracker(&snapshot{ledger: 7206416}, events)
+	_, ok := tracker.Balances(sender)
+	c.Check(ok, Equals, false)
+	c.Assert(tracker.Track(context.Background(), sender), IsNil)
+	before := balancesOf(c, tracker, sender)
+	c.Check(before, HasLen, 3)
+	for _, balance := range before {
+		c.Check(balance.LedgerSequence, Equals, uint32(7206416))
+	}
+
+	proposed := *msg
+	proposed.Validated = false
+	tracker.Update(&proposed)
+	c.Check(events, HasLen, 0)
+
+	tracker.Update(msg)
+	c.Assert(events, HasLen, len(changes))
+	after := balancesOf(c, tracker, sender)
+	for _, change := range changes {
+		event := <-events
+		c.Check(event.BalanceChange, DeepEquals, change)
+		c.Check(event.LedgerSequence, Equals, uint32(7206416))
+		c.Check(event.Hash, Equals, *msg.Transaction.GetHash())
+		c.Check(event.PartialPayment, Equals, false)
+		balance := after[fmt.Sprintf("%s/%s", change.Currency, change.Counterparty)]
+		c.Check(balance.Value.Equals(change.Balance), Equals, true, Commentf("%s", change))
+	}
+	eur := after["EUR/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"]
+	c.Check(eur.Value.String(), Equals, "2.5")
+
+	tracker.Untrack(sender)
+	tracker.Update(msg)
+	c.Check(events, HasLen, 0)
+}
+
+func (s *TrackerSuite) TestPartialPayment(c *C) {
+	msg, sender, changes := payment(c)
+	partial := data.TxPartialPayment
+	msg.Transaction.Transaction.(*data.Payment).Flags = &partial
+	events := make(chan *BalanceEvent, 10)
+	tracker := NewTracker(&snapshot{ledger: 7206416}, events)
+	c.Assert(tracker.Track(context.Background(), sender), IsNil)
+	tracker.Update(msg)
+	c.Assert(events, HasLen, len(changes))
+	for range changes {
+		c.Check((<-events).PartialPayment, Equals, true)
+	}
+}
+
+func (s *TrackerSuite) TestLoadedLater(c *C) {
+	msg, sender, _ := payment(c)
+	events := make(chan *BalanceEvent, 10)
+	tracker := NewTracker(&snapshot{ledger: 7206417}, events)
+	c.Assert(tracker.Track(context.Background(), sender), IsNil)
+	tracker.Update(msg)
+	c.Check(events, HasLen, 0)
+	usd := balancesOf(c, tracker, sender)["USD/rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2"]
+	c.Check(usd.Value.String(), Equals, "1")
+}
+
+func (s *TrackerSuite) TestUpdateWhileLoading(c *C) {
+	msg, sender, changes := payment(c)
+	events := make(chan *BalanceEvent, 10)
+	client := &snapshot{ledger: 7206416}
+	tracker := NewTracker(client, events)
+	client.loading = func() { tracker.Update(msg) }
+	c.Assert(tracker.Track(context.Background(), sender), IsNil)
+	c.Check(events, HasLen, len(changes))
+}
+
+func (s *TrackerSuite) TestTrackFails(c *C) {
+	_, sender, _ := payment(c)
+	tracker := NewTracker(&snapshot{fail: true}, nil)
+	c.Check(tracker.Track(context.Background(), sender), ErrorMatches, "actNotFound.*")
+	_, ok := tracker.Balances(sender)
+	c.Check(ok, Equals, false)
+	c.Check(tracker.accounts, HasLen, 0)
+}
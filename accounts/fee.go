@@ -0,0 +1,162 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// How long a fee command result is used when FeeProvider.MaxAge is zero,
+// which is about the time between ledgers
+const DefaultFeeMaxAge = 4 * time.Second
+
+// FeeClient is the part of ripple.Client needed by a FeeProvider
+type FeeClient interface {
+	FeeCtx(ctx context.Context) (*websockets.FeeResult, error)
+}
+
+// FeePolicy decides how much a transaction pays
+type FeePolicy struct {
+	// FeeLow pays the minimum to be queued, FeeMedium the open ledger
+	// fee and FeeHigh enough to be applied ahead of the queue
+	Urgency websockets.FeeUrgency
+
+	// Multiplies the fee, to allow for it rising before the transaction
+	// is submitted. Zero means no cushion.
+	Cushion float64
+
+	// The most to pay, in drops. The fee is lowered to Max, unless that
+	// is below the minimum, when filling fails. Zero means no limit.
+	Max uint64
+}
+
+// FeeProvider fills in the Fee of transactions according to a FeePolicy.
+// The fee command is requested at most once every MaxAge, and again
+// whenever a server stream message passed to Update shows the load has
+// changed. Those messages also set a floor for the minimum fee.
+type FeeProvider struct {
+	// How long to use a fee command result for. Zero means DefaultFeeMaxAge.
+	MaxAge time.Duration
+
+	client  FeeClient
+	policy  FeePolicy
+	mu      sync.Mutex
+	fee     *websockets.FeeResult
+	fetched time.Time
+	load    uint64 // Transaction cost at the server's load, in drops
+}
+
+func NewFeeProvider(client FeeClient, policy FeePolicy) *FeeProvider {
+	return &FeeProvider{
+		client: client,
+		policy: policy,
+	}
+}
+
+// Update takes the load from a server stream message, which is also
+// taken as a sign that the fee command should be requested again
+func (p *FeeProvider) Update(msg *websockets.ServerStreamMsg) {
+	if msg.LoadBase == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.load = msg.TransactionCost()
+	p.fee = nil
+}
+
+// Watch calls Update for every message on server until it is closed,
+// such as the Server channel of a Subscription
+func (p *FeeProvider) Watch(server <-chan *websockets.ServerStreamMsg) {
+	go func() {
+		for msg := range server {
+			p.Update(msg)
+		}
+	}()
+}
+
+// current returns the latest fee command result, requesting it if needed
+func (p *FeeProvider) current(ctx context.Context) (*websockets.FeeResult, uint64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	maxAge := p.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultFeeMaxAge
+	}
+	if p.fee == nil || time.Since(p.fetched) > maxAge {
+		fee, err := p.client.FeeCtx(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		p.fee, p.fetched = fee, time.Now()
+	}
+	return p.fee, p.load, nil
+}
+
+// Fee returns what a transaction should pay, in drops. signers is how
+// many will sign a multisigned transaction, which costs the fee of a
+// single signature for each of them, plus one, or zero for one signed
+// singly.
+func (p *FeeProvider) Fee(ctx context.Context, signers int) (*data.Value, error) {
+	if signers < 0 {
+		return nil, fmt.Errorf("Bad number of signers: %d", signers)
+	}
+	fee, load, err := p.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	suggested, err := fee.SuggestedFee(p.policy.Urgency)
+	if err != nil {
+		return nil, err
+	}
+	minimum := fee.Drops.MinimumFee.Rat().Num().Uint64()
+	if load > minimum {
+		minimum = load
+	}
+	drops := suggested.Rat().Num().Uint64()
+	if minimum > drops {
+		drops = minimum
+	}
+	signatures := uint64(1 + signers)
+	minimum *= signatures
+	drops *= signatures
+	if p.policy.Cushion > 0 {
+		drops = uint64(math.Ceil(float64(drops) * p.policy.Cushion))
+	}
+	if drops < minimum {
+		drops = minimum
+	}
+	if p.policy.Max != 0 && drops > p.policy.Max {
+		if minimum > p.policy.Max {
+			return nil, fmt.Errorf("Fee of %d drops exceeds the maximum of %d", minimum, p.policy.Max)
+		}
+		drops = p.policy.Max
+	}
+	if drops > math.MaxInt64 {
+		return nil, fmt.Errorf("Fee of %d drops out of range", drops)
+	}
+	return data.NewNativeValue(int64(drops))
+}
+
+// Fill sets the Fee of tx, which is signed singly, replacing any already
+// there
+func (p *FeeProvider) Fill(ctx context.Context, tx data.Transaction) error {
+	return p.FillMultisigned(ctx, tx, 0)
+}
+
+// FillMultisigned sets the Fee of tx, which signers will sign, replacing
+// any already there. The Signers are only added once the Fee is signed
+// over, so they can't be counted from tx.
+func (p *FeeProvider) FillMultisigned(ctx context.Context, tx data.Transaction, signers int) error {
+	fee, err := p.Fee(ctx, signers)
+	if err != nil {
+		return err
+	}
+	tx.GetBase().Fee = *fee
+	return nil
+}
@@ -0,0 +1,94 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+type FeeSuite struct{}
+
+var _ = Suite(&FeeSuite{})
+
+const feeJSON = `{
+	"current_ledger_size": "30",
+	"current_queue_size": "10",
+	"drops": {
+		"base_fee": "10",
+		"median_fee": "11000",
+		"minimum_fee": "10",
+		"open_ledger_fee": "17188"
+	},
+	"expected_ledger_size": "24",
+	"ledger_current_index": 26575101,
+	"levels": {
+		"median_level": "281600",
+		"minimum_level": "256",
+		"open_ledger_level": "440000",
+		"reference_level": "256"
+	},
+	"max_queue_size": "480"
+}`
+
+// feeServer is a FeeClient which counts its requests
+type feeServer struct {
+	sync.Mutex
+	requests int
+}
+
+func (f *feeServer) FeeCtx(ctx context.Context) (*websockets.FeeResult, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.requests++
+	var result websockets.FeeResult
+	return &result, json.Unmarshal([]byte(feeJSON), &result)
+}
+
+func (s *FeeSuite) TestPolicies(c *C) {
+	for _, test := range []struct {
+		policy   FeePolicy
+		signers  int
+		expected string
+	}{
+		{FeePolicy{Urgency: websockets.FeeLow}, 0, "10"},
+		{FeePolicy{Urgency: websockets.FeeMedium}, 0, "17188"},
+		{FeePolicy{Urgency: websockets.FeeMedium, Cushion: 1.5}, 0, "25782"},
+		{FeePolicy{Urgency: websockets.FeeMedium, Cushion: 0.5}, 0, "8594"},
+		{FeePolicy{Urgency: websockets.FeeLow, Cushion: 0.5}, 0, "10"},
+		{FeePolicy{Urgency: websockets.FeeMedium, Max: 12000}, 0, "12000"},
+		{FeePolicy{Urgency: websockets.FeeMedium}, 2, "51564"},
+		{FeePolicy{Urgency: websockets.FeeMedium, Max: 30}, 2, "30"},
+		{FeePolicy{Urgency: websockets.FeeHigh}, 0, "30556"},
+	} {
+		p := NewFeeProvider(&feeServer{}, test.policy)
+		tx := newPayment(c)
+		c.Assert(p.FillMultisigned(context.Background(), tx, test.signers), IsNil)
+		c.Check(tx.Fee.Rat().RatString(), Equals, test.expected, Commentf("%+v", test.policy))
+	}
+
+	p := NewFeeProvider(&feeServer{}, FeePolicy{Urgency: websockets.FeeLow, Max: 20})
+	_, err := p.Fee(context.Background(), 2)
+	c.Check(err, ErrorMatches, "Fee of 30 drops exceeds the maximum of 20")
+	_, err = p.Fee(context.Background(), -1)
+	c.Check(err, ErrorMatches, "Bad number of signers: -1")
+}
+
+func (s *FeeSuite) TestServerLoad(c *C) {
+	server := &feeServer{}
+	p := NewFeeProvider(server, FeePolicy{Urgency: websockets.FeeLow})
+	for i := 0; i < 3; i++ {
+		tx := newPayment(c)
+		c.Assert(p.Fill(context.Background(), tx), IsNil)
+		c.Check(tx.Fee.Rat().RatString(), Equals, "10")
+	}
+	c.Check(server.requests, Equals, 1)
+
+	p.Update(&websockets.ServerStreamMsg{BaseFee: 10, LoadBase: 256, LoadFactor: 768})
+	tx := newPayment(c)
+	c.Assert(p.Fill(context.Background(), tx), IsNil)
+	c.Check(tx.Fee.Rat().RatString(), Equals, "30")
+	c.Check(server.requests, Equals, 2)
+}
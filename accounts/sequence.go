@@ -0,0 +1,217 @@
+// Package accounts keeps track of the state of accounts which are
+// submitting transactions, of the fees they need to pay, and of their
+// balances.
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// Client is the part of ripple.Client needed to learn an account's
+// sequence number and tickets.
+type Client interface {
+	AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error)
+	AccountObjectsCtx(ctx context.Context, account data.Account, objectType string, ledgerIndex interface{}) (*websockets.AccountObjectsResult, error)
+}
+
+// SequenceManager hands out the sequence numbers and tickets of accounts
+// to goroutines preparing transactions, so that none are used twice.
+// An account is loaded from the current ledger when first used, and again
+// after any Report which shows the cached state to be wrong.
+type SequenceManager struct {
+	client   Client
+	mu       sync.Mutex
+	accounts map[data.Account]*sequences
+}
+
+// sequences holds what is known of one account. The lock is held while
+// loading, so that concurrent first users wait for a single request.
+type sequences struct {
+	sync.Mutex
+	loaded  bool
+	next    uint32
+	tickets data.LedgerSlice // Sorted
+}
+
+func NewSequenceManager(client Client) *SequenceManager {
+	return &SequenceManager{
+		client:   client,
+		accounts: make(map[data.Account]*sequences),
+	}
+}
+
+// lock returns the locked state of account, loading it when needed
+func (m *SequenceManager) lock(ctx context.Context, account data.Account) (*sequences, error) {
+	m.mu.Lock()
+	s, ok := m.accounts[account]
+	if !ok {
+		s = &sequences{}
+		m.accounts[account] = s
+	}
+	m.mu.Unlock()
+
+	s.Lock()
+	if s.loaded {
+		return s, nil
+	}
+	info, err := m.client.AccountInfoCtx(ctx, account)
+	if err != nil {
+		s.Unlock()
+		return nil, err
+	}
+	if info.AccountData.Sequence == nil {
+		s.Unlock()
+		return nil, fmt.Errorf("Account %s has no sequence", account)
+	}
+	objects, err := m.client.AccountObjectsCtx(ctx, account, "ticket", "current")
+	if err != nil {
+		s.Unlock()
+		return nil, err
+	}
+	s.next = *info.AccountData.Sequence
+	s.tickets = nil
+	for _, object := range objects.AccountObjects {
+		if ticket, ok := object.(*data.Ticket); ok && ticket.TicketSequence != nil {
+			s.tickets = append(s.tickets, *ticket.TicketSequence)
+		}
+	}
+	s.tickets.Sorted()
+	s.loaded = true
+	return s, nil
+}
+
+// Next allocates the next sequence number of account
+func (m *SequenceManager) Next(ctx context.Context, account data.Account) (uint32, error) {
+	s, err := m.lock(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+	defer s.Unlock()
+	s.next++
+	return s.next - 1, nil
+}
+
+// NextTicket allocates the lowest unused ticket of account
+func (m *SequenceManager) NextTicket(ctx context.Context, account data.Account) (uint32, error) {
+	s, err := m.lock(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+	defer s.Unlock()
+	if len(s.tickets) == 0 {
+		return 0, fmt.Errorf("Account %s has no tickets", account)
+	}
+	ticket := s.tickets[0]
+	s.tickets = s.tickets[1:]
+	return ticket, nil
+}
+
+// Assign sets the Sequence of tx to the next of its account
+func (m *SequenceManager) Assign(ctx context.Context, tx data.Transaction) error {
+	base := tx.GetBase()
+	sequence, err := m.Next(ctx, base.Account)
+	if err != nil {
+		return err
+	}
+	base.Sequence, base.TicketSequence = sequence, nil
+	return nil
+}
+
+// AssignTicket sets the TicketSequence of tx to a ticket of its account,
+// so that it may be submitted in any order with other ticketed transactions.
+func (m *SequenceManager) AssignTicket(ctx context.Context, tx data.Transaction) error {
+	base := tx.GetBase()
+	ticket, err := m.NextTicket(ctx, base.Account)
+	if err != nil {
+		return err
+	}
+	base.Sequence, base.TicketSequence = 0, &ticket
+	return nil
+}
+
+// CreateTickets returns a TicketCreate for count tickets, which are
+// available straight away. They can't be used before the TicketCreate
+// has been applied, and are lost if Report shows it failed.
+func (m *SequenceManager) CreateTickets(ctx context.Context, account data.Account, count uint32) (*data.TicketCreate, error) {
+	s, err := m.lock(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unlock()
+	tx := data.TxFactory[data.TICKET_CREATE]().(*data.TicketCreate)
+	tx.Account = account
+	tx.Sequence = s.next
+	tx.TicketCount = count
+	// The tickets take the sequence numbers following the TicketCreate
+	for i := uint32(1); i <= count; i++ {
+		s.tickets = append(s.tickets, s.next+i)
+	}
+	s.tickets.Sorted()
+	s.next += count + 1
+	return tx, nil
+}
+
+// Report updates the account of tx with the result of submitting it.
+// A sequence number or ticket which the transaction did not use is
+// reclaimed where possible, and otherwise the account is reloaded.
+func (m *SequenceManager) Report(tx data.Transaction, result data.TransactionResult) {
+	base := tx.GetBase()
+	switch {
+	case result.Claimed(), result.Queued():
+		return
+	}
+	m.mu.Lock()
+	s, ok := m.accounts[base.Account]
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	switch result {
+	case data.TefPAST_SEQ, data.TerPRE_SEQ, data.TefNO_TICKET, data.TerPRE_TICKET:
+		// The cached state is wrong, or a gap needs filling
+		s.loaded = false
+	default:
+		if !s.loaded || tx.GetTransactionType() == data.TICKET_CREATE {
+			s.loaded = false
+			return
+		}
+		switch {
+		case base.TicketSequence != nil:
+			s.returnTicket(*base.TicketSequence)
+		case base.Sequence == s.next-1:
+			s.next--
+		default:
+			// Later sequence numbers are in use, the gap must be filled
+			s.loaded = false
+		}
+	}
+}
+
+func (s *sequences) returnTicket(ticket uint32) {
+	for _, t := range s.tickets {
+		if t == ticket {
+			return
+		}
+	}
+	s.tickets = append(s.tickets, ticket)
+	s.tickets.Sorted()
+}
+
+// Reset forgets everything known of account, which is reloaded when next used
+func (m *SequenceManager) Reset(account data.Account) {
+	m.mu.Lock()
+	s, ok := m.accounts[account]
+	m.mu.Unlock()
+	if ok {
+		s.Lock()
+		s.loaded = false
+		s.Unlock()
+	}
+}
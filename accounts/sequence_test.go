@@ -0,0 +1,162 @@
+package accounts
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type SequenceSuite struct{}
+
+var _ = Suite(&SequenceSuite{})
+
+// ledger is a Client which serves a single account
+type ledger struct {
+	sync.Mutex
+	sequence uint32
+	tickets  []uint32
+	loads    int
+}
+
+func (l *ledger) AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error) {
+	l.Lock()
+	defer l.Unlock()
+	l.loads++
+	sequence := l.sequence
+	return &websockets.AccountInfoResult{
+		AccountData: data.AccountRoot{Account: &a, Sequence: &sequence},
+	}, nil
+}
+
+func (l *ledger) AccountObjectsCtx(ctx context.Context, account data.Account, objectType string, ledgerIndex interface{}) (*websockets.AccountObjectsResult, error) {
+	l.Lock()
+	defer l.Unlock()
+	var objects data.LedgerEntrySlice
+	for i := range l.tickets {
+		objects = append(objects, &data.Ticket{Account: &account, TicketSequence: &l.tickets[i]})
+	}
+	return &websockets.AccountObjectsResult{AccountObjects: objects}, nil
+}
+
+func newPayment(c *C) *data.Payment {
+	account, err := data.NewAccountFromAddress("rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(err, IsNil)
+	tx := data.TxFactory[data.PAYMENT]().(*data.Payment)
+	tx.Account = *account
+	return tx
+}
+
+func result(c *C, token string) data.TransactionResult {
+	var r data.TransactionResult
+	c.Assert(r.UnmarshalText([]byte(token)), IsNil)
+	return r
+}
+
+func (s *SequenceSuite) TestConcurrentNext(c *C) {
+	l := &ledger{sequence: 10}
+	m := NewSequenceManager(l)
+	account := newPayment(c).Account
+
+	var wg sync.WaitGroup
+	sequences := make(chan uint32, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sequence, err := m.Next(context.Background(), account)
+			c.Check(err, IsNil)
+			sequences <- sequence
+		}()
+	}
+	wg.Wait()
+	close(sequences)
+	seen := make(map[uint32]bool)
+	for sequence := range sequences {
+		c.Check(seen[sequence], Equals, false)
+		c.Check(sequence >= 10 && sequence < 60, Equals, true)
+		seen[sequence] = true
+	}
+	c.Check(l.loads, Equals, 1)
+}
+
+func (s *SequenceSuite) TestReport(c *C) {
+	l := &ledger{sequence: 10}
+	m := NewSequenceManager(l)
+	ctx := context.Background()
+
+	first, second := newPayment(c), newPayment(c)
+	c.Assert(m.Assign(ctx, first), IsNil)
+	c.Assert(m.Assign(ctx, second), IsNil)
+	c.Check(first.Sequence, Equals, uint32(10))
+	c.Check(second.Sequence, Equals, uint32(11))
+
+	// The last sequence number can be handed out again
+	m.Report(second, result(c, "temBAD_AMOUNT"))
+	c.Assert(m.Assign(ctx, second), IsNil)
+	c.Check(second.Sequence, Equals, uint32(11))
+	m.Report(second, result(c, "tecUNFUNDED_PAYMENT"))
+
+	// A gap means reloading
+	m.Report(first, result(c, "temBAD_AMOUNT"))
+	c.Check(l.loads, Equals, 1)
+	c.Assert(m.Assign(ctx, first), IsNil)
+	c.Check(first.Sequence, Equals, uint32(10))
+	c.Check(l.loads, Equals, 2)
+
+	// Another submitter got there first
+	l.sequence = 15
+	m.Report(first, result(c, "tefPAST_SEQ"))
+	c.Assert(m.Assign(ctx, first), IsNil)
+	c.Check(first.Sequence, Equals, uint32(15))
+	c.Check(l.loads, Equals, 3)
+}
+
+func (s *SequenceSuite) TestTickets(c *C) {
+	l := &ledger{sequence: 10, tickets: []uint32{7, 4}}
+	m := NewSequenceManager(l)
+	ctx := context.Background()
+	tx := newPayment(c)
+
+	c.Assert(m.AssignTicket(ctx, tx), IsNil)
+	c.Check(tx.Sequence, Equals, uint32(0))
+	c.Check(*tx.TicketSequence, Equals, uint32(4))
+	m.Report(tx, result(c, "telINSUF_FEE_P"))
+
+	create, err := m.CreateTickets(ctx, tx.Account, 2)
+	c.Assert(err, IsNil)
+	c.Check(create.GetTransactionType(), Equals, data.TICKET_CREATE)
+	c.Check(create.Sequence, Equals, uint32(10))
+	c.Check(create.TicketCount, Equals, uint32(2))
+
+	var tickets []uint32
+	for i := 0; i < 4; i++ {
+		ticket, err := m.NextTicket(ctx, tx.Account)
+		c.Assert(err, IsNil)
+		tickets = append(tickets, ticket)
+	}
+	c.Check(tickets, DeepEquals, []uint32{4, 7, 11, 12})
+	_, err = m.NextTicket(ctx, tx.Account)
+	c.Check(err, ErrorMatches, "Account rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B has no tickets")
+
+	c.Assert(m.Assign(ctx, tx), IsNil)
+	c.Check(tx.Sequence, Equals, uint32(13))
+	c.Check(tx.TicketSequence, IsNil)
+
+	// The new tickets never existed
+	m.Report(create, result(c, "tefMAX_LEDGER"))
+	tickets = nil
+	for i := 0; i < 2; i++ {
+		ticket, err := m.NextTicket(ctx, tx.Account)
+		c.Assert(err, IsNil)
+		tickets = append(tickets, ticket)
+	}
+	c.Check(tickets, DeepEquals, []uint32{4, 7})
+	_, err = m.NextTicket(ctx, tx.Account)
+	c.Check(err, NotNil)
+}
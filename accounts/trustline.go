@@ -0,0 +1,172 @@
+package accounts
+
+import (
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// TrustLine is a trust line as seen by one of its accounts
+type TrustLine struct {
+	Account      data.Account
+	Counterparty data.Account
+	Currency     data.Currency
+	Balance      data.Value // Positive when Account holds the currency
+	Limit        data.Value // Set by Account
+	LimitPeer    data.Value // Set by Counterparty
+	NoRipple     bool
+	NoRipplePeer bool
+	Freeze       bool // Frozen by Account
+	FreezePeer   bool // Frozen by Counterparty
+}
+
+// newTrustLine returns rs as seen by account, or nil if it isn't one of
+// its accounts
+func newTrustLine(rs *data.RippleState, account data.Account) *TrustLine {
+	if rs.Balance == nil || rs.LowLimit == nil || rs.HighLimit == nil {
+		return nil
+	}
+	var flags data.LedgerEntryFlag
+	if rs.Flags != nil {
+		flags = *rs.Flags
+	}
+	low, high := rs.LowLimit, rs.HighLimit
+	line := &TrustLine{Account: account, Currency: rs.Balance.Currency}
+	switch account {
+	case low.Issuer:
+		line.Counterparty = high.Issuer
+		line.Balance = *rs.Balance.Value
+		line.Limit, line.LimitPeer = *low.Value, *high.Value
+		line.NoRipple, line.NoRipplePeer = flags&data.LsLowNoRipple > 0, flags&data.LsHighNoRipple > 0
+		line.Freeze, line.FreezePeer = flags&data.LsLowFreeze > 0, flags&data.LsHighFreeze > 0
+	case high.Issuer:
+		line.Counterparty = low.Issuer
+		line.Balance = *rs.Balance.Value.Negate()
+		line.Limit, line.LimitPeer = *high.Value, *low.Value
+		line.NoRipple, line.NoRipplePeer = flags&data.LsHighNoRipple > 0, flags&data.LsLowNoRipple > 0
+		line.Freeze, line.FreezePeer = flags&data.LsHighFreeze > 0, flags&data.LsLowFreeze > 0
+	default:
+		return nil
+	}
+	return line
+}
+
+// TrustLineEventType is what happened to a trust line
+type TrustLineEventType int
+
+const (
+	TrustLineCreated TrustLineEventType = iota
+	TrustLineRemoved
+	// Frozen by either account
+	TrustLineFrozen
+	TrustLineUnfrozen
+	// Limit or LimitPeer changed
+	TrustLineLimitChanged
+	// NoRipple or NoRipplePeer changed
+	TrustLineNoRippleChanged
+)
+
+var trustLineEventTypeNames = [...]string{"created", "removed", "frozen", "unfrozen", "limit changed", "no ripple changed"}
+
+func (t TrustLineEventType) String() string {
+	if t < 0 || int(t) >= len(trustLineEventTypeNames) {
+		return "unknown"
+	}
+	return trustLineEventTypeNames[t]
+}
+
+// TrustLineEvent is a change to a trust line of a tracked account, made by
+// a validated transaction. One transaction may make several, such as when
+// a TrustSet changes both the limit and the no ripple flag.
+type TrustLineEvent struct {
+	Type           TrustLineEventType
+	Line           TrustLine  // After the transaction, or before it when removed
+	Previous       *TrustLine // Before the transaction, nil when created
+	LedgerSequence uint32
+	Hash           data.Hash256 // Of the transaction
+}
+
+func (e TrustLineEvent) String() string {
+	return fmt.Sprintf("%-34s %s/%s %s Ledger: %d Hash: %s", e.Line.Account, e.Line.Currency, e.Line.Counterparty,
+		e.Type, e.LedgerSequence, e.Hash)
+}
+
+// rippleStateChange is a trust line created, modified or deleted by a
+// transaction
+type rippleStateChange struct {
+	state           data.LedgerEntryState
+	final, previous *data.RippleState // Previous is nil when created
+}
+
+// rippleStateChanges returns the trust lines changed by txm, from its
+// metadata
+func rippleStateChanges(txm *data.TransactionWithMetaData) []rippleStateChange {
+	var changes []rippleStateChange
+	for i := range txm.MetaData.AffectedNodes {
+		_, final, previous, state := txm.MetaData.AffectedNodes[i].AffectedNode()
+		rs, ok := final.(*data.RippleState)
+		if !ok {
+			continue
+		}
+		change := rippleStateChange{state: state, final: rs}
+		if state != data.Created {
+			change.previous = before(rs, previous.(*data.RippleState))
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// before returns the trust line final was, given the previous values of
+// the fields which changed
+func before(final, previous *data.RippleState) *data.RippleState {
+	rs := *final
+	if previous.Flags != nil {
+		rs.Flags = previous.Flags
+	}
+	if previous.LowLimit != nil {
+		rs.LowLimit = previous.LowLimit
+	}
+	if previous.HighLimit != nil {
+		rs.HighLimit = previous.HighLimit
+	}
+	if previous.Balance != nil {
+		rs.Balance = previous.Balance
+	}
+	return &rs
+}
+
+// events returns what c did to the trust line as seen by account
+func (c rippleStateChange) events(account data.Account) []*TrustLineEvent {
+	line := newTrustLine(c.final, account)
+	if line == nil {
+		return nil
+	}
+	switch c.state {
+	case data.Created:
+		return []*TrustLineEvent{{Type: TrustLineCreated, Line: *line}}
+	case data.Deleted:
+		return []*TrustLineEvent{{Type: TrustLineRemoved, Line: *line, Previous: newTrustLine(c.previous, account)}}
+	}
+	previous := newTrustLine(c.previous, account)
+	if previous == nil {
+		return nil
+	}
+	var events []*TrustLineEvent
+	add := func(t TrustLineEventType) {
+		events = append(events, &TrustLineEvent{Type: t, Line: *line, Previous: previous})
+	}
+	if line.Freeze && !previous.Freeze || line.FreezePeer && !previous.FreezePeer {
+		add(TrustLineFrozen)
+	}
+	if !line.Freeze && previous.Freeze || !line.FreezePeer && previous.FreezePeer {
+		add(TrustLineUnfrozen)
+	}
+	if !line.Limit.Equals(previous.Limit) || !line.LimitPeer.Equals(previous.LimitPeer) {
+		add(TrustLineLimitChanged)
+	}
+	if line.NoRipple != previous.NoRipple || line.NoRipplePeer != previous.NoRipplePeer {
+		add(TrustLineNoRippleChanged)
+	}
+	return events
+}
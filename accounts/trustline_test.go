@@ -0,0 +1,184 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+type TrustLineSuite struct{}
+
+var _ = Suite(&TrustLineSuite{})
+
+// A CAD trust line from rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B, the low account,
+// to rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2, created, then frozen by the issuer
+// while its limit and no ripple flag were changed, then removed
+var trustSets = []string{`{
+	"TransactionType": "TrustSet",
+	"Account": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B",
+	"Fee": "10",
+	"Sequence": 1,
+	"LimitAmount": {"currency": "CAD", "issuer": "rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2", "value": "100"},
+	"hash": "1000000000000000000000000000000000000000000000000000000000000001",
+	"ledger_index": 100,
+	"meta": {
+		"TransactionIndex": 0,
+		"TransactionResult": "tesSUCCESS",
+		"AffectedNodes": [{"CreatedNode": {
+			"LedgerEntryType": "RippleState",
+			"LedgerIndex": "2000000000000000000000000000000000000000000000000000000000000002",
+			"NewFields": {
+				"Balance": {"currency": "CAD", "issuer": "rrrrrrrrrrrrrrrrrrrrBZbvji", "value": "0"},
+				"Flags": 65536,
+				"LowLimit": {"currency": "CAD", "issuer": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "value": "100"},
+				"HighLimit": {"currency": "CAD", "issuer": "rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2", "value": "0"}
+			}
+		}}]
+	}
+}`, `{
+	"TransactionType": "TrustSet",
+	"Account": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B",
+	"Fee": "10",
+	"Sequence": 2,
+	"LimitAmount": {"currency": "CAD", "issuer": "rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2", "value": "200"},
+	"hash": "1000000000000000000000000000000000000000000000000000000000000002",
+	"ledger_index": 101,
+	"meta": {
+		"TransactionIndex": 0,
+		"TransactionResult": "tesSUCCESS",
+		"AffectedNodes": [{"ModifiedNode": {
+			"LedgerEntryType": "RippleState",
+			"LedgerIndex": "2000000000000000000000000000000000000000000000000000000000000002",
+			"FinalFields": {
+				"Balance": {"currency": "CAD", "issuer": "rrrrrrrrrrrrrrrrrrrrBZbvji", "value": "0"},
+				"Flags": 9502720,
+				"LowLimit": {"currency": "CAD", "issuer": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "value": "200"},
+				"HighLimit": {"currency": "CAD", "issuer": "rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2", "value": "0"}
+			},
+			"PreviousFields": {
+				"Flags": 65536,
+				"LowLimit": {"currency": "CAD", "issuer": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "value": "100"}
+			}
+		}}]
+	}
+}`, `{
+	"TransactionType": "TrustSet",
+	"Account": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B",
+	"Fee": "10",
+	"Sequence": 3,
+	"LimitAmount": {"currency": "CAD", "issuer": "rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2", "value": "0"},
+	"hash": "1000000000000000000000000000000000000000000000000000000000000003",
+	"ledger_index": 102,
+	"meta": {
+		"TransactionIndex": 0,
+		"TransactionResult": "tesSUCCESS",
+		"AffectedNodes": [{"DeletedNode": {
+			"LedgerEntryType": "RippleState",
+			"LedgerIndex": "2000000000000000000000000000000000000000000000000000000000000002",
+			"FinalFields": {
+				"Balance": {"currency": "CAD", "issuer": "rrrrrrrrrrrrrrrrrrrrBZbvji", "value": "0"},
+				"Flags": 0,
+				"LowLimit": {"currency": "CAD", "issuer": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "value": "0"},
+				"HighLimit": {"currency": "CAD", "issuer": "rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2", "value": "0"}
+			}
+		}}]
+	}
+}`}
+
+func trustSet(c *C, i int) *websockets.TransactionStreamMsg {
+	msg := &websockets.TransactionStreamMsg{Validated: true}
+	c.Assert(json.Unmarshal([]byte(trustSets[i]), &msg.Transaction), IsNil)
+	msg.LedgerSequence = msg.Transaction.LedgerSequence
+	return msg
+}
+
+func (s *TrustLineSuite) TestEvents(c *C) {
+	alice, bob := account(c, "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"), account(c, "rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2")
+	cad, err := data.NewCurrency("CAD")
+	c.Assert(err, IsNil)
+	lines := make(chan *TrustLineEvent, 10)
+	tracker := NewTracker(&snapshot{ledger: 100}, nil)
+	tracker.TrustLines = lines
+	c.Assert(tracker.Track(context.Background(), alice, bob), IsNil)
+	hasCAD := func(account data.Account) bool {
+		all, ok := tracker.Balances(account)
+		c.Assert(ok, Equals, true)
+		for _, balance := range all {
+			if balance.Currency == cad {
+				c.Check(balance.Value.IsZero(), Equals, true)
+				return true
+			}
+		}
+		return false
+	}
+
+	tracker.Update(trustSet(c, 0))
+	c.Assert(lines, HasLen, 2)
+	for i := 0; i < 2; i++ {
+		event := <-lines
+		c.Check(event.Type, Equals, TrustLineCreated)
+		c.Check(event.Previous, IsNil)
+		c.Check(event.LedgerSequence, Equals, uint32(100))
+		c.Check(event.Hash.String(), Equals, "1000000000000000000000000000000000000000000000000000000000000001")
+		c.Check(event.Line.Currency, Equals, cad)
+	}
+	c.Check(hasCAD(alice), Equals, true)
+	c.Check(hasCAD(bob), Equals, true)
+
+	tracker.Update(trustSet(c, 1))
+	c.Assert(lines, HasLen, 6)
+	byAccount := map[data.Account][]*TrustLineEvent{}
+	for i := 0; i < 6; i++ {
+		event := <-lines
+		byAccount[event.Line.Account] = append(byAccount[event.Line.Account], event)
+	}
+	var types []TrustLineEventType
+	for _, event := range byAccount[alice] {
+		types = append(types, event.Type)
+		c.Check(event.Line.Counterparty, Equals, bob)
+		c.Check(event.Line.Limit.String(), Equals, "200")
+		c.Check(event.Previous.Limit.String(), Equals, "100")
+		c.Check(event.Line.NoRipple, Equals, true)
+		c.Check(event.Previous.NoRipple, Equals, false)
+		c.Check(event.Line.FreezePeer, Equals, true)
+		c.Check(event.Line.Freeze, Equals, false)
+	}
+	c.Check(types, DeepEquals, []TrustLineEventType{TrustLineFrozen, TrustLineLimitChanged, TrustLineNoRippleChanged})
+	for _, event := range byAccount[bob] {
+		c.Check(event.Line.Counterparty, Equals, alice)
+		c.Check(event.Line.LimitPeer.String(), Equals, "200")
+		c.Check(event.Line.NoRipplePeer, Equals, true)
+		c.Check(event.Line.Freeze, Equals, true)
+	}
+	c.Check(byAccount[bob], HasLen, 3)
+
+	tracker.Update(trustSet(c, 2))
+	c.Assert(lines, HasLen, 2)
+	for i := 0; i < 2; i++ {
+		event := <-lines
+		c.Check(event.Type, Equals, TrustLineRemoved)
+		c.Check(event.Previous.Freeze || event.Previous.FreezePeer, Equals, false)
+	}
+	c.Check(hasCAD(alice), Equals, false)
+	c.Check(hasCAD(bob), Equals, false)
+
+	// From before the accounts were loaded
+	stale := trustSet(c, 0)
+	stale.LedgerSequence = 99
+	tracker.Update(stale)
+	c.Check(lines, HasLen, 0)
+}
+
+func (s *TrustLineSuite) TestEventTypeString(c *C) {
+	c.Check(TrustLineFrozen.String(), Equals, "frozen")
+	c.Check(TrustLineEventType(99).String(), Equals, "unknown")
+}
+
+func account(c *C, address string) data.Account {
+	a, err := data.NewAccountFromAddress(address)
+	c.Assert(err, IsNil)
+	return *a
+}
@@ -0,0 +1,217 @@
+// Package builder constructs transactions with fluent methods, checking
+// their fields before they are signed and, when asked, filling in the
+// Sequence, Fee and LastLedgerSequence from a server.
+//
+//	tx, err := builder.NewPayment(from, to, amount).
+//		WithDestinationTag(42).
+//		WithMemo("invoice", "1234").
+//		WithAutoFill(&builder.AutoFill{Client: remote}).
+//		Build()
+//
+// Pseudo-transactions, such as SetFee, can't be built.
+package builder
+
+//go:generate go run gen_common.go
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/accounts"
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// How many ledgers after the current one an auto-filled transaction
+// may be applied in, when AutoFill.LedgerOffset is zero
+const DefaultLedgerOffset = 20
+
+// Client is the part of ripple.Client needed by AutoFill
+type Client interface {
+	AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error)
+	FeeCtx(ctx context.Context) (*websockets.FeeResult, error)
+	LedgerCurrentCtx(ctx context.Context) (uint32, error)
+}
+
+// AutoFill sets whichever of Sequence, Fee and LastLedgerSequence have
+// not been set already. Without Sequences the account's next sequence
+// number is requested, and without Fees the open ledger fee is paid.
+type AutoFill struct {
+	Client    Client
+	Sequences *accounts.SequenceManager
+	Fees      *accounts.FeeProvider
+
+	// How many will sign a multisigned transaction, whose fee is paid for
+	// each of them. Zero means it is signed singly.
+	Signers int
+
+	// Ledgers after the current one in which tx may be applied.
+	// Zero means DefaultLedgerOffset.
+	LedgerOffset uint32
+}
+
+// Fill sets the missing fields of tx
+func (f *AutoFill) Fill(ctx context.Context, tx data.Transaction) error {
+	base := tx.GetBase()
+	needSequence := base.Sequence == 0 && base.TicketSequence == nil
+	needFee := base.Fee.IsZero()
+	if f.Client == nil && (needSequence && f.Sequences == nil || needFee && f.Fees == nil || base.LastLedgerSequence == nil) {
+		return fmt.Errorf("AutoFill needs a Client")
+	}
+	if needSequence {
+		if err := f.sequence(ctx, tx); err != nil {
+			return err
+		}
+	}
+	if needFee {
+		fees := f.Fees
+		if fees == nil {
+			fees = accounts.NewFeeProvider(f.Client, accounts.FeePolicy{Urgency: websockets.FeeMedium})
+		}
+		if err := fees.FillMultisigned(ctx, tx, f.Signers); err != nil {
+			return err
+		}
+	}
+	if base.LastLedgerSequence == nil {
+		current, err := f.Client.LedgerCurrentCtx(ctx)
+		if err != nil {
+			return err
+		}
+		offset := f.LedgerOffset
+		if offset == 0 {
+			offset = DefaultLedgerOffset
+		}
+		last := current + offset
+		base.LastLedgerSequence = &last
+	}
+	return nil
+}
+
+func (f *AutoFill) sequence(ctx context.Context, tx data.Transaction) error {
+	if f.Sequences != nil {
+		return f.Sequences.Assign(ctx, tx)
+	}
+	base := tx.GetBase()
+	info, err := f.Client.AccountInfoCtx(ctx, base.Account)
+	if err != nil {
+		return err
+	}
+	if info.AccountData.Sequence == nil {
+		return fmt.Errorf("Account %s has no sequence", base.Account)
+	}
+	base.Sequence = *info.AccountData.Sequence
+	return nil
+}
+
+// builder holds what is common to building every transaction type
+type builder struct {
+	tx      data.Transaction
+	fill    *AutoFill
+	network *data.Network
+}
+
+func (b *builder) flags(flags data.TransactionFlag) {
+	base := b.tx.GetBase()
+	if base.Flags == nil {
+		base.Flags = new(data.TransactionFlag)
+	}
+	*base.Flags |= flags
+}
+
+func (b *builder) sourceTag(tag uint32) {
+	b.tx.GetBase().SourceTag = &tag
+}
+
+func (b *builder) memo(memoType, memoData string) {
+	var memo data.Memo
+	memo.Memo.MemoType = data.VariableLength(memoType)
+	memo.Memo.MemoData = data.VariableLength(memoData)
+	base := b.tx.GetBase()
+	base.Memos = append(base.Memos, memo)
+}
+
+func (b *builder) memos(memos []data.Memo) {
+	base := b.tx.GetBase()
+	base.Memos = append(base.Memos, memos...)
+}
+
+func (b *builder) sequence(sequence uint32) {
+	base := b.tx.GetBase()
+	base.Sequence, base.TicketSequence = sequence, nil
+}
+
+func (b *builder) ticket(ticket uint32) {
+	base := b.tx.GetBase()
+	base.Sequence, base.TicketSequence = 0, &ticket
+}
+
+func (b *builder) fee(fee data.Value) {
+	b.tx.GetBase().Fee = fee
+}
+
+func (b *builder) lastLedgerSequence(sequence uint32) {
+	b.tx.GetBase().LastLedgerSequence = &sequence
+}
+
+// build checks the common fields, then those of the transaction type,
+// and finally fills in any missing fields when asked to
+func (b *builder) build(ctx context.Context, check func() error) error {
+	if err := b.validate(check); err != nil {
+		return fmt.Errorf("%s: %s", b.tx.GetType(), err)
+	}
+	if b.fill != nil {
+		return b.fill.Fill(ctx, b.tx)
+	}
+	return nil
+}
+
+func (b *builder) validate(check func() error) error {
+	base := b.tx.GetBase()
+	if b.network != nil {
+		b.network.SetNetworkID(b.tx)
+	}
+	switch {
+	case base.Account.IsZero():
+		return fmt.Errorf("Account is missing")
+	case base.Sequence != 0 && base.TicketSequence != nil:
+		return fmt.Errorf("Sequence and TicketSequence are both set")
+	case !base.Fee.IsZero() && (!base.Fee.IsNative() || base.Fee.IsNegative()):
+		return fmt.Errorf("Fee must be XRP: %s", base.Fee)
+	case base.NetworkID != nil && *base.NetworkID <= data.MaxLegacyNetworkID:
+		return fmt.Errorf("NetworkID must not be set for network %d", *base.NetworkID)
+	}
+	if err := base.Memos.Check(); err != nil {
+		return err
+	}
+	return check()
+}
+
+// positive returns an error unless amount is above zero
+func positive(name string, amount *data.Amount) error {
+	if amount == nil || amount.Value == nil || amount.IsZero() || amount.IsNegative() {
+		return fmt.Errorf("%s must be positive", name)
+	}
+	return nil
+}
+
+// positiveXRP returns an error unless amount is XRP above zero
+func positiveXRP(name string, amount *data.Amount) error {
+	if err := positive(name, amount); err != nil {
+		return err
+	}
+	if !amount.IsNative() {
+		return fmt.Errorf("%s must be XRP", name)
+	}
+	return nil
+}
+
+// otherAccount returns an error unless destination is set and isn't account
+func otherAccount(account, destination data.Account) error {
+	switch {
+	case destination.IsZero():
+		return fmt.Errorf("Destination is missing")
+	case destination == account:
+		return fmt.Errorf("Destination is the Account")
+	}
+	return nil
+}
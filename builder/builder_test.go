@@ -0,0 +1,208 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type BuilderSuite struct{}
+
+var _ = Suite(&BuilderSuite{})
+
+const (
+	alice = "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"
+	bob   = "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"
+	gw    = "rMwjYedjc7qqtKYVLiAccJSmCwih4LnE2q"
+)
+
+func account(c *C, address string) data.Account {
+	a, err := data.NewAccountFromAddress(address)
+	c.Assert(err, IsNil)
+	return *a
+}
+
+func amount(c *C, s string) data.Amount {
+	a, err := data.NewAmount(s)
+	c.Assert(err, IsNil)
+	return *a
+}
+
+// server is a Client for a ledger with a single account
+type server struct{}
+
+func (s server) AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error) {
+	sequence := uint32(7)
+	return &websockets.AccountInfoResult{
+		AccountData: data.AccountRoot{Account: &a, Sequence: &sequence},
+	}, nil
+}
+
+func (s server) FeeCtx(ctx context.Context) (*websockets.FeeResult, error) {
+	var result websockets.FeeResult
+	return &result, json.Unmarshal([]byte(`{
+		"drops": {"base_fee": "10", "minimum_fee": "10", "open_ledger_fee": "12"}
+	}`), &result)
+}
+
+func (s server) LedgerCurrentCtx(ctx context.Context) (uint32, error) {
+	return 1000, nil
+}
+
+func (s *BuilderSuite) TestPayment(c *C) {
+	paths, err := data.NewPath("USD/" + gw + " => " + gw)
+	c.Assert(err, IsNil)
+	tx, err := NewPayment(account(c, alice), account(c, bob), amount(c, "10/USD/"+gw)).
+		WithDestinationTag(42).
+		WithMemo("text/plain", "Thanks").
+		WithMemos(data.NewTextMemo("invoice", "1234")).
+		WithSendMax(amount(c, "20")).
+		WithPaths(data.PathSet{paths}).
+		WithDeliverMin(amount(c, "5/USD/"+gw)).
+		WithFlags(data.TxNoDirectRipple).
+		WithSequence(3).
+		Build()
+	c.Assert(err, IsNil)
+	c.Check(*tx.DestinationTag, Equals, uint32(42))
+	c.Check(string(tx.Memos[0].Memo.MemoData), Equals, "Thanks")
+	c.Check(tx.Memos[1].Format(), Equals, data.MemoFormatText)
+	c.Check(tx.Paths, NotNil)
+	c.Check(*tx.Flags, Equals, data.TxPartialPayment|data.TxNoDirectRipple)
+	c.Check(tx.Sequence, Equals, uint32(3))
+	c.Check(tx.LastLedgerSequence, IsNil)
+	c.Check(tx.Fee.IsZero(), Equals, true)
+}
+
+func (s *BuilderSuite) TestAutoFill(c *C) {
+	fill := &AutoFill{Client: server{}}
+	tx, err := NewPayment(account(c, alice), account(c, bob), amount(c, "10")).
+		WithAutoFill(fill).
+		Build()
+	c.Assert(err, IsNil)
+	c.Check(tx.Sequence, Equals, uint32(7))
+	c.Check(tx.Fee.Rat().RatString(), Equals, "12")
+	c.Check(*tx.LastLedgerSequence, Equals, uint32(1000+DefaultLedgerOffset))
+
+	// Paying for each signer
+	tx, err = NewPayment(account(c, alice), account(c, bob), amount(c, "10")).
+		WithAutoFill(&AutoFill{Client: server{}, Signers: 2}).
+		Build()
+	c.Assert(err, IsNil)
+	c.Check(tx.Fee.Rat().RatString(), Equals, "36")
+
+	fee, err := data.NewNativeValue(15)
+	c.Assert(err, IsNil)
+	offer, err := NewOfferCreate(account(c, alice), amount(c, "10"), amount(c, "10/USD/"+gw)).
+		WithTicket(4).
+		WithFee(*fee).
+		WithAutoFill(&AutoFill{Client: server{}, LedgerOffset: 5}).
+		Build()
+	c.Assert(err, IsNil)
+	c.Check(offer.Sequence, Equals, uint32(0))
+	c.Check(*offer.TicketSequence, Equals, uint32(4))
+	c.Check(offer.Fee.Rat().RatString(), Equals, "15")
+	c.Check(*offer.LastLedgerSequence, Equals, uint32(1005))
+
+	_, err = NewTicketCreate(account(c, alice), 2).WithAutoFill(&AutoFill{}).Build()
+	c.Check(err, ErrorMatches, "AutoFill needs a Client")
+}
+
+func (s *BuilderSuite) TestChecks(c *C) {
+	a, b := account(c, alice), account(c, bob)
+	usd := amount(c, "10/USD/"+gw)
+	xrp := amount(c, "10")
+	var check data.Hash256
+	check[0] = 1
+
+	for _, test := range []struct {
+		build func() error
+		err   string
+	}{
+		{func() error { _, err := NewPayment(data.Account{}, b, xrp).Build(); return err }, "Payment: Account is missing"},
+		{func() error { _, err := NewPayment(a, b, amount(c, "0")).Build(); return err }, "Payment: Amount must be positive"},
+		{func() error { _, err := NewPayment(a, a, xrp).Build(); return err }, "Payment: Destination is the Account"},
+		{func() error { _, err := NewPayment(a, b, xrp).WithSendMax(xrp).Build(); return err }, "Payment: SendMax is not needed .*"},
+		{func() error { _, err := NewPayment(a, b, xrp).WithSequence(1).WithFee(*usd.Value).Build(); return err }, "Payment: Fee must be XRP.*"},
+		{func() error { _, err := NewAccountSet(a).WithTransferRate(5).Build(); return err }, "AccountSet: TransferRate 5 .*"},
+		{func() error { _, err := NewAccountSet(a).WithMemo("in voice", "1").Build(); return err }, "AccountSet: MemoType \"in voice\" has a character .*"},
+		{func() error {
+			_, err := NewAccountSet(a).WithMemos(data.NewMemo("", "", make([]byte, 1024))).Build()
+			return err
+		}, "AccountSet: Memos take 1029 bytes, more than 1024"},
+		{func() error {
+			_, err := NewAccountSet(a).WithSetFlag(data.TxSetRequireDest).WithClearFlag(data.TxSetRequireDest).Build()
+			return err
+		}, "AccountSet: SetFlag and ClearFlag are both 1"},
+		{func() error { _, err := NewSetRegularKey(a).WithRegularKey(data.RegularKey(a)).Build(); return err }, "SetRegularKey: RegularKey is the Account"},
+		{func() error { _, err := NewOfferCreate(a, xrp, xrp).Build(); return err }, "OfferCreate: TakerPays and TakerGets are both XRP"},
+		{func() error { _, err := NewOfferCreate(a, usd, usd).Build(); return err }, "OfferCreate: TakerPays and TakerGets are both .*"},
+		{func() error { _, err := NewOfferCancel(a, 0).Build(); return err }, "OfferCancel: OfferSequence is missing"},
+		{func() error { _, err := NewTrustSet(a, xrp).Build(); return err }, "TrustSet: LimitAmount can't be XRP"},
+		{func() error { _, err := NewEscrowCreate(a, b, usd).Build(); return err }, "EscrowCreate: Amount must be XRP"},
+		{func() error { _, err := NewEscrowCreate(a, b, xrp).Build(); return err }, "EscrowCreate: FinishAfter or Digest is needed"},
+		{func() error {
+			_, err := NewEscrowCreate(a, b, xrp).WithFinishAfter(10).WithCancelAfter(10).Build()
+			return err
+		}, "EscrowCreate: CancelAfter is not after FinishAfter"},
+		{func() error { _, err := NewEscrowFinish(a, data.Account{}, 1).Build(); return err }, "EscrowFinish: Owner is missing"},
+		{func() error { _, err := NewEscrowCancel(a, data.Account{}, 1).Build(); return err }, "EscrowCancel: Owner is missing"},
+		{func() error {
+			_, err := NewPaymentChannelCreate(a, b, xrp, 60, data.PublicKey{}).Build()
+			return err
+		}, "PaymentChannelCreate: PublicKey is missing"},
+		{func() error { _, err := NewPaymentChannelFund(a, check, usd).Build(); return err }, "PaymentChannelFund: Amount must be XRP"},
+		{func() error {
+			_, err := NewPaymentChannelClaim(a, check).WithSignature([]byte{1}, data.PublicKey{2}).Build()
+			return err
+		}, "PaymentChannelClaim: Signature needs a Balance and Amount"},
+		{func() error { _, err := NewCheckCreate(a, a, usd).Build(); return err }, "CheckCreate: Destination is the Account"},
+		{func() error { _, err := NewCheckCash(a, check).Build(); return err }, "CheckCash: Amount or DeliverMin is needed"},
+		{func() error { _, err := NewCheckCancel(a, data.Hash256{}).Build(); return err }, "CheckCancel: CheckID is missing"},
+		{func() error { _, err := NewDepositPreauth(a).Build(); return err }, "DepositPreauth: Authorize or Unauthorize is needed"},
+		{func() error { _, err := NewDepositPreauth(a).WithAuthorize(a).Build(); return err }, "DepositPreauth: Authorize is the Account"},
+		{func() error {
+			_, err := NewDepositPreauth(a).WithAuthorize(b).WithUnauthorize(b).Build()
+			return err
+		}, "DepositPreauth: Authorize and Unauthorize are both set"},
+		{func() error { _, err := NewTicketCreate(a, 251).Build(); return err }, "TicketCreate: TicketCount 251 .*"},
+		{func() error { _, err := NewSignerListSet(a, 3).WithSigner(b, 2).Build(); return err }, "SignerListSet: SignerQuorum 3 is more than the total weight 2"},
+		{func() error { _, err := NewSignerListSet(a, 1).WithSigner(b, 1).WithSigner(b, 1).Build(); return err }, "SignerListSet: SignerEntries include .* twice"},
+		{func() error { _, err := NewAccountDelete(a, data.Account{}).Build(); return err }, "AccountDelete: Destination is missing"},
+	} {
+		c.Check(test.build(), ErrorMatches, test.err)
+	}
+
+	// And some which are fine
+	_, err := NewSignerListSet(a, 0).Build()
+	c.Check(err, IsNil)
+	_, err = NewCheckCash(a, check).WithDeliverMin(usd).Build()
+	c.Check(err, IsNil)
+	_, err = NewTrustSet(a, usd).WithQualityIn(1).Build()
+	c.Check(err, IsNil)
+	_, err = NewPayment(a, a, usd).WithSendMax(xrp).Build()
+	c.Check(err, IsNil)
+}
+
+func (s *BuilderSuite) TestNetwork(c *C) {
+	sidechain, err := data.NewNetwork("sidechain", 21337, 10000000, 2000000)
+	c.Assert(err, IsNil)
+	tx, err := NewAccountSet(account(c, alice)).WithNetwork(sidechain).Build()
+	c.Assert(err, IsNil)
+	c.Check(*tx.NetworkID, Equals, uint32(21337))
+
+	tx, err = NewAccountSet(account(c, alice)).WithNetwork(data.Mainnet).Build()
+	c.Assert(err, IsNil)
+	c.Check(tx.NetworkID, IsNil)
+
+	b := NewAccountSet(account(c, alice))
+	id := data.Testnet.ID
+	b.tx.NetworkID = &id
+	_, err = b.Build()
+	c.Check(err, ErrorMatches, "AccountSet: NetworkID must not be set for network 1")
+}
@@ -0,0 +1,1042 @@
+// Code generated by gen_common.go. DO NOT EDIT.
+
+package builder
+
+import (
+	"context"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// The methods every builder has. WithFlags adds to any flags already set,
+// WithMemo adds a plain text memo and WithMemos any others, and
+// WithSequence and WithTicket replace each other. WithNetwork sets or
+// removes the NetworkID for the network, and Build refuses transactions
+// meant for another. Build checks the fields, including the memos, and
+// fills in any left missing when WithAutoFill has been used.
+
+func (b *Payment) WithFlags(flags data.TransactionFlag) *Payment {
+	b.flags(flags)
+	return b
+}
+
+func (b *Payment) WithSourceTag(tag uint32) *Payment {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *Payment) WithMemo(memoType, memoData string) *Payment {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *Payment) WithMemos(memos ...data.Memo) *Payment {
+	b.memos(memos)
+	return b
+}
+
+func (b *Payment) WithSequence(sequence uint32) *Payment {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *Payment) WithTicket(ticket uint32) *Payment {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *Payment) WithFee(fee data.Value) *Payment {
+	b.fee(fee)
+	return b
+}
+
+func (b *Payment) WithLastLedgerSequence(sequence uint32) *Payment {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *Payment) WithAutoFill(fill *AutoFill) *Payment {
+	b.fill = fill
+	return b
+}
+
+func (b *Payment) WithNetwork(network *data.Network) *Payment {
+	b.network = network
+	return b
+}
+
+func (b *Payment) Build() (*data.Payment, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *AccountSet) WithFlags(flags data.TransactionFlag) *AccountSet {
+	b.flags(flags)
+	return b
+}
+
+func (b *AccountSet) WithSourceTag(tag uint32) *AccountSet {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *AccountSet) WithMemo(memoType, memoData string) *AccountSet {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *AccountSet) WithMemos(memos ...data.Memo) *AccountSet {
+	b.memos(memos)
+	return b
+}
+
+func (b *AccountSet) WithSequence(sequence uint32) *AccountSet {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *AccountSet) WithTicket(ticket uint32) *AccountSet {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *AccountSet) WithFee(fee data.Value) *AccountSet {
+	b.fee(fee)
+	return b
+}
+
+func (b *AccountSet) WithLastLedgerSequence(sequence uint32) *AccountSet {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *AccountSet) WithAutoFill(fill *AutoFill) *AccountSet {
+	b.fill = fill
+	return b
+}
+
+func (b *AccountSet) WithNetwork(network *data.Network) *AccountSet {
+	b.network = network
+	return b
+}
+
+func (b *AccountSet) Build() (*data.AccountSet, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *SetRegularKey) WithFlags(flags data.TransactionFlag) *SetRegularKey {
+	b.flags(flags)
+	return b
+}
+
+func (b *SetRegularKey) WithSourceTag(tag uint32) *SetRegularKey {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *SetRegularKey) WithMemo(memoType, memoData string) *SetRegularKey {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *SetRegularKey) WithMemos(memos ...data.Memo) *SetRegularKey {
+	b.memos(memos)
+	return b
+}
+
+func (b *SetRegularKey) WithSequence(sequence uint32) *SetRegularKey {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *SetRegularKey) WithTicket(ticket uint32) *SetRegularKey {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *SetRegularKey) WithFee(fee data.Value) *SetRegularKey {
+	b.fee(fee)
+	return b
+}
+
+func (b *SetRegularKey) WithLastLedgerSequence(sequence uint32) *SetRegularKey {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *SetRegularKey) WithAutoFill(fill *AutoFill) *SetRegularKey {
+	b.fill = fill
+	return b
+}
+
+func (b *SetRegularKey) WithNetwork(network *data.Network) *SetRegularKey {
+	b.network = network
+	return b
+}
+
+func (b *SetRegularKey) Build() (*data.SetRegularKey, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *OfferCreate) WithFlags(flags data.TransactionFlag) *OfferCreate {
+	b.flags(flags)
+	return b
+}
+
+func (b *OfferCreate) WithSourceTag(tag uint32) *OfferCreate {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *OfferCreate) WithMemo(memoType, memoData string) *OfferCreate {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *OfferCreate) WithMemos(memos ...data.Memo) *OfferCreate {
+	b.memos(memos)
+	return b
+}
+
+func (b *OfferCreate) WithSequence(sequence uint32) *OfferCreate {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *OfferCreate) WithTicket(ticket uint32) *OfferCreate {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *OfferCreate) WithFee(fee data.Value) *OfferCreate {
+	b.fee(fee)
+	return b
+}
+
+func (b *OfferCreate) WithLastLedgerSequence(sequence uint32) *OfferCreate {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *OfferCreate) WithAutoFill(fill *AutoFill) *OfferCreate {
+	b.fill = fill
+	return b
+}
+
+func (b *OfferCreate) WithNetwork(network *data.Network) *OfferCreate {
+	b.network = network
+	return b
+}
+
+func (b *OfferCreate) Build() (*data.OfferCreate, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *OfferCancel) WithFlags(flags data.TransactionFlag) *OfferCancel {
+	b.flags(flags)
+	return b
+}
+
+func (b *OfferCancel) WithSourceTag(tag uint32) *OfferCancel {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *OfferCancel) WithMemo(memoType, memoData string) *OfferCancel {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *OfferCancel) WithMemos(memos ...data.Memo) *OfferCancel {
+	b.memos(memos)
+	return b
+}
+
+func (b *OfferCancel) WithSequence(sequence uint32) *OfferCancel {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *OfferCancel) WithTicket(ticket uint32) *OfferCancel {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *OfferCancel) WithFee(fee data.Value) *OfferCancel {
+	b.fee(fee)
+	return b
+}
+
+func (b *OfferCancel) WithLastLedgerSequence(sequence uint32) *OfferCancel {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *OfferCancel) WithAutoFill(fill *AutoFill) *OfferCancel {
+	b.fill = fill
+	return b
+}
+
+func (b *OfferCancel) WithNetwork(network *data.Network) *OfferCancel {
+	b.network = network
+	return b
+}
+
+func (b *OfferCancel) Build() (*data.OfferCancel, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *TrustSet) WithFlags(flags data.TransactionFlag) *TrustSet {
+	b.flags(flags)
+	return b
+}
+
+func (b *TrustSet) WithSourceTag(tag uint32) *TrustSet {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *TrustSet) WithMemo(memoType, memoData string) *TrustSet {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *TrustSet) WithMemos(memos ...data.Memo) *TrustSet {
+	b.memos(memos)
+	return b
+}
+
+func (b *TrustSet) WithSequence(sequence uint32) *TrustSet {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *TrustSet) WithTicket(ticket uint32) *TrustSet {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *TrustSet) WithFee(fee data.Value) *TrustSet {
+	b.fee(fee)
+	return b
+}
+
+func (b *TrustSet) WithLastLedgerSequence(sequence uint32) *TrustSet {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *TrustSet) WithAutoFill(fill *AutoFill) *TrustSet {
+	b.fill = fill
+	return b
+}
+
+func (b *TrustSet) WithNetwork(network *data.Network) *TrustSet {
+	b.network = network
+	return b
+}
+
+func (b *TrustSet) Build() (*data.TrustSet, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *EscrowCreate) WithFlags(flags data.TransactionFlag) *EscrowCreate {
+	b.flags(flags)
+	return b
+}
+
+func (b *EscrowCreate) WithSourceTag(tag uint32) *EscrowCreate {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *EscrowCreate) WithMemo(memoType, memoData string) *EscrowCreate {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *EscrowCreate) WithMemos(memos ...data.Memo) *EscrowCreate {
+	b.memos(memos)
+	return b
+}
+
+func (b *EscrowCreate) WithSequence(sequence uint32) *EscrowCreate {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *EscrowCreate) WithTicket(ticket uint32) *EscrowCreate {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *EscrowCreate) WithFee(fee data.Value) *EscrowCreate {
+	b.fee(fee)
+	return b
+}
+
+func (b *EscrowCreate) WithLastLedgerSequence(sequence uint32) *EscrowCreate {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *EscrowCreate) WithAutoFill(fill *AutoFill) *EscrowCreate {
+	b.fill = fill
+	return b
+}
+
+func (b *EscrowCreate) WithNetwork(network *data.Network) *EscrowCreate {
+	b.network = network
+	return b
+}
+
+func (b *EscrowCreate) Build() (*data.EscrowCreate, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *EscrowFinish) WithFlags(flags data.TransactionFlag) *EscrowFinish {
+	b.flags(flags)
+	return b
+}
+
+func (b *EscrowFinish) WithSourceTag(tag uint32) *EscrowFinish {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *EscrowFinish) WithMemo(memoType, memoData string) *EscrowFinish {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *EscrowFinish) WithMemos(memos ...data.Memo) *EscrowFinish {
+	b.memos(memos)
+	return b
+}
+
+func (b *EscrowFinish) WithSequence(sequence uint32) *EscrowFinish {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *EscrowFinish) WithTicket(ticket uint32) *EscrowFinish {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *EscrowFinish) WithFee(fee data.Value) *EscrowFinish {
+	b.fee(fee)
+	return b
+}
+
+func (b *EscrowFinish) WithLastLedgerSequence(sequence uint32) *EscrowFinish {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *EscrowFinish) WithAutoFill(fill *AutoFill) *EscrowFinish {
+	b.fill = fill
+	return b
+}
+
+func (b *EscrowFinish) WithNetwork(network *data.Network) *EscrowFinish {
+	b.network = network
+	return b
+}
+
+func (b *EscrowFinish) Build() (*data.EscrowFinish, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *EscrowCancel) WithFlags(flags data.TransactionFlag) *EscrowCancel {
+	b.flags(flags)
+	return b
+}
+
+func (b *EscrowCancel) WithSourceTag(tag uint32) *EscrowCancel {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *EscrowCancel) WithMemo(memoType, memoData string) *EscrowCancel {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *EscrowCancel) WithMemos(memos ...data.Memo) *EscrowCancel {
+	b.memos(memos)
+	return b
+}
+
+func (b *EscrowCancel) WithSequence(sequence uint32) *EscrowCancel {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *EscrowCancel) WithTicket(ticket uint32) *EscrowCancel {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *EscrowCancel) WithFee(fee data.Value) *EscrowCancel {
+	b.fee(fee)
+	return b
+}
+
+func (b *EscrowCancel) WithLastLedgerSequence(sequence uint32) *EscrowCancel {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *EscrowCancel) WithAutoFill(fill *AutoFill) *EscrowCancel {
+	b.fill = fill
+	return b
+}
+
+func (b *EscrowCancel) WithNetwork(network *data.Network) *EscrowCancel {
+	b.network = network
+	return b
+}
+
+func (b *EscrowCancel) Build() (*data.EscrowCancel, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *PaymentChannelCreate) WithFlags(flags data.TransactionFlag) *PaymentChannelCreate {
+	b.flags(flags)
+	return b
+}
+
+func (b *PaymentChannelCreate) WithSourceTag(tag uint32) *PaymentChannelCreate {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *PaymentChannelCreate) WithMemo(memoType, memoData string) *PaymentChannelCreate {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *PaymentChannelCreate) WithMemos(memos ...data.Memo) *PaymentChannelCreate {
+	b.memos(memos)
+	return b
+}
+
+func (b *PaymentChannelCreate) WithSequence(sequence uint32) *PaymentChannelCreate {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *PaymentChannelCreate) WithTicket(ticket uint32) *PaymentChannelCreate {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *PaymentChannelCreate) WithFee(fee data.Value) *PaymentChannelCreate {
+	b.fee(fee)
+	return b
+}
+
+func (b *PaymentChannelCreate) WithLastLedgerSequence(sequence uint32) *PaymentChannelCreate {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *PaymentChannelCreate) WithAutoFill(fill *AutoFill) *PaymentChannelCreate {
+	b.fill = fill
+	return b
+}
+
+func (b *PaymentChannelCreate) WithNetwork(network *data.Network) *PaymentChannelCreate {
+	b.network = network
+	return b
+}
+
+func (b *PaymentChannelCreate) Build() (*data.PaymentChannelCreate, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *PaymentChannelFund) WithFlags(flags data.TransactionFlag) *PaymentChannelFund {
+	b.flags(flags)
+	return b
+}
+
+func (b *PaymentChannelFund) WithSourceTag(tag uint32) *PaymentChannelFund {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *PaymentChannelFund) WithMemo(memoType, memoData string) *PaymentChannelFund {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *PaymentChannelFund) WithMemos(memos ...data.Memo) *PaymentChannelFund {
+	b.memos(memos)
+	return b
+}
+
+func (b *PaymentChannelFund) WithSequence(sequence uint32) *PaymentChannelFund {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *PaymentChannelFund) WithTicket(ticket uint32) *PaymentChannelFund {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *PaymentChannelFund) WithFee(fee data.Value) *PaymentChannelFund {
+	b.fee(fee)
+	return b
+}
+
+func (b *PaymentChannelFund) WithLastLedgerSequence(sequence uint32) *PaymentChannelFund {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *PaymentChannelFund) WithAutoFill(fill *AutoFill) *PaymentChannelFund {
+	b.fill = fill
+	return b
+}
+
+func (b *PaymentChannelFund) WithNetwork(network *data.Network) *PaymentChannelFund {
+	b.network = network
+	return b
+}
+
+func (b *PaymentChannelFund) Build() (*data.PaymentChannelFund, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *PaymentChannelClaim) WithFlags(flags data.TransactionFlag) *PaymentChannelClaim {
+	b.flags(flags)
+	return b
+}
+
+func (b *PaymentChannelClaim) WithSourceTag(tag uint32) *PaymentChannelClaim {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *PaymentChannelClaim) WithMemo(memoType, memoData string) *PaymentChannelClaim {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *PaymentChannelClaim) WithMemos(memos ...data.Memo) *PaymentChannelClaim {
+	b.memos(memos)
+	return b
+}
+
+func (b *PaymentChannelClaim) WithSequence(sequence uint32) *PaymentChannelClaim {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *PaymentChannelClaim) WithTicket(ticket uint32) *PaymentChannelClaim {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *PaymentChannelClaim) WithFee(fee data.Value) *PaymentChannelClaim {
+	b.fee(fee)
+	return b
+}
+
+func (b *PaymentChannelClaim) WithLastLedgerSequence(sequence uint32) *PaymentChannelClaim {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *PaymentChannelClaim) WithAutoFill(fill *AutoFill) *PaymentChannelClaim {
+	b.fill = fill
+	return b
+}
+
+func (b *PaymentChannelClaim) WithNetwork(network *data.Network) *PaymentChannelClaim {
+	b.network = network
+	return b
+}
+
+func (b *PaymentChannelClaim) Build() (*data.PaymentChannelClaim, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *CheckCreate) WithFlags(flags data.TransactionFlag) *CheckCreate {
+	b.flags(flags)
+	return b
+}
+
+func (b *CheckCreate) WithSourceTag(tag uint32) *CheckCreate {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *CheckCreate) WithMemo(memoType, memoData string) *CheckCreate {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *CheckCreate) WithMemos(memos ...data.Memo) *CheckCreate {
+	b.memos(memos)
+	return b
+}
+
+func (b *CheckCreate) WithSequence(sequence uint32) *CheckCreate {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *CheckCreate) WithTicket(ticket uint32) *CheckCreate {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *CheckCreate) WithFee(fee data.Value) *CheckCreate {
+	b.fee(fee)
+	return b
+}
+
+func (b *CheckCreate) WithLastLedgerSequence(sequence uint32) *CheckCreate {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *CheckCreate) WithAutoFill(fill *AutoFill) *CheckCreate {
+	b.fill = fill
+	return b
+}
+
+func (b *CheckCreate) WithNetwork(network *data.Network) *CheckCreate {
+	b.network = network
+	return b
+}
+
+func (b *CheckCreate) Build() (*data.CheckCreate, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *CheckCash) WithFlags(flags data.TransactionFlag) *CheckCash {
+	b.flags(flags)
+	return b
+}
+
+func (b *CheckCash) WithSourceTag(tag uint32) *CheckCash {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *CheckCash) WithMemo(memoType, memoData string) *CheckCash {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *CheckCash) WithMemos(memos ...data.Memo) *CheckCash {
+	b.memos(memos)
+	return b
+}
+
+func (b *CheckCash) WithSequence(sequence uint32) *CheckCash {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *CheckCash) WithTicket(ticket uint32) *CheckCash {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *CheckCash) WithFee(fee data.Value) *CheckCash {
+	b.fee(fee)
+	return b
+}
+
+func (b *CheckCash) WithLastLedgerSequence(sequence uint32) *CheckCash {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *CheckCash) WithAutoFill(fill *AutoFill) *CheckCash {
+	b.fill = fill
+	return b
+}
+
+func (b *CheckCash) WithNetwork(network *data.Network) *CheckCash {
+	b.network = network
+	return b
+}
+
+func (b *CheckCash) Build() (*data.CheckCash, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *CheckCancel) WithFlags(flags data.TransactionFlag) *CheckCancel {
+	b.flags(flags)
+	return b
+}
+
+func (b *CheckCancel) WithSourceTag(tag uint32) *CheckCancel {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *CheckCancel) WithMemo(memoType, memoData string) *CheckCancel {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *CheckCancel) WithMemos(memos ...data.Memo) *CheckCancel {
+	b.memos(memos)
+	return b
+}
+
+func (b *CheckCancel) WithSequence(sequence uint32) *CheckCancel {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *CheckCancel) WithTicket(ticket uint32) *CheckCancel {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *CheckCancel) WithFee(fee data.Value) *CheckCancel {
+	b.fee(fee)
+	return b
+}
+
+func (b *CheckCancel) WithLastLedgerSequence(sequence uint32) *CheckCancel {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *CheckCancel) WithAutoFill(fill *AutoFill) *CheckCancel {
+	b.fill = fill
+	return b
+}
+
+func (b *CheckCancel) WithNetwork(network *data.Network) *CheckCancel {
+	b.network = network
+	return b
+}
+
+func (b *CheckCancel) Build() (*data.CheckCancel, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *DepositPreauth) WithFlags(flags data.TransactionFlag) *DepositPreauth {
+	b.flags(flags)
+	return b
+}
+
+func (b *DepositPreauth) WithSourceTag(tag uint32) *DepositPreauth {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *DepositPreauth) WithMemo(memoType, memoData string) *DepositPreauth {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *DepositPreauth) WithMemos(memos ...data.Memo) *DepositPreauth {
+	b.memos(memos)
+	return b
+}
+
+func (b *DepositPreauth) WithSequence(sequence uint32) *DepositPreauth {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *DepositPreauth) WithTicket(ticket uint32) *DepositPreauth {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *DepositPreauth) WithFee(fee data.Value) *DepositPreauth {
+	b.fee(fee)
+	return b
+}
+
+func (b *DepositPreauth) WithLastLedgerSequence(sequence uint32) *DepositPreauth {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *DepositPreauth) WithAutoFill(fill *AutoFill) *DepositPreauth {
+	b.fill = fill
+	return b
+}
+
+func (b *DepositPreauth) WithNetwork(network *data.Network) *DepositPreauth {
+	b.network = network
+	return b
+}
+
+func (b *DepositPreauth) Build() (*data.DepositPreauth, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *TicketCreate) WithFlags(flags data.TransactionFlag) *TicketCreate {
+	b.flags(flags)
+	return b
+}
+
+func (b *TicketCreate) WithSourceTag(tag uint32) *TicketCreate {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *TicketCreate) WithMemo(memoType, memoData string) *TicketCreate {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *TicketCreate) WithMemos(memos ...data.Memo) *TicketCreate {
+	b.memos(memos)
+	return b
+}
+
+func (b *TicketCreate) WithSequence(sequence uint32) *TicketCreate {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *TicketCreate) WithTicket(ticket uint32) *TicketCreate {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *TicketCreate) WithFee(fee data.Value) *TicketCreate {
+	b.fee(fee)
+	return b
+}
+
+func (b *TicketCreate) WithLastLedgerSequence(sequence uint32) *TicketCreate {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *TicketCreate) WithAutoFill(fill *AutoFill) *TicketCreate {
+	b.fill = fill
+	return b
+}
+
+func (b *TicketCreate) WithNetwork(network *data.Network) *TicketCreate {
+	b.network = network
+	return b
+}
+
+func (b *TicketCreate) Build() (*data.TicketCreate, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *SignerListSet) WithFlags(flags data.TransactionFlag) *SignerListSet {
+	b.flags(flags)
+	return b
+}
+
+func (b *SignerListSet) WithSourceTag(tag uint32) *SignerListSet {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *SignerListSet) WithMemo(memoType, memoData string) *SignerListSet {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *SignerListSet) WithMemos(memos ...data.Memo) *SignerListSet {
+	b.memos(memos)
+	return b
+}
+
+func (b *SignerListSet) WithSequence(sequence uint32) *SignerListSet {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *SignerListSet) WithTicket(ticket uint32) *SignerListSet {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *SignerListSet) WithFee(fee data.Value) *SignerListSet {
+	b.fee(fee)
+	return b
+}
+
+func (b *SignerListSet) WithLastLedgerSequence(sequence uint32) *SignerListSet {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *SignerListSet) WithAutoFill(fill *AutoFill) *SignerListSet {
+	b.fill = fill
+	return b
+}
+
+func (b *SignerListSet) WithNetwork(network *data.Network) *SignerListSet {
+	b.network = network
+	return b
+}
+
+func (b *SignerListSet) Build() (*data.SignerListSet, error) {
+	return b.BuildCtx(context.Background())
+}
+
+func (b *AccountDelete) WithFlags(flags data.TransactionFlag) *AccountDelete {
+	b.flags(flags)
+	return b
+}
+
+func (b *AccountDelete) WithSourceTag(tag uint32) *AccountDelete {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *AccountDelete) WithMemo(memoType, memoData string) *AccountDelete {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *AccountDelete) WithMemos(memos ...data.Memo) *AccountDelete {
+	b.memos(memos)
+	return b
+}
+
+func (b *AccountDelete) WithSequence(sequence uint32) *AccountDelete {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *AccountDelete) WithTicket(ticket uint32) *AccountDelete {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *AccountDelete) WithFee(fee data.Value) *AccountDelete {
+	b.fee(fee)
+	return b
+}
+
+func (b *AccountDelete) WithLastLedgerSequence(sequence uint32) *AccountDelete {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *AccountDelete) WithAutoFill(fill *AutoFill) *AccountDelete {
+	b.fill = fill
+	return b
+}
+
+func (b *AccountDelete) WithNetwork(network *data.Network) *AccountDelete {
+	b.network = network
+	return b
+}
+
+func (b *AccountDelete) Build() (*data.AccountDelete, error) {
+	return b.BuildCtx(context.Background())
+}
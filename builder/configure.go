@@ -0,0 +1,146 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// The TransferRate which charges nothing, as does none
+const parTransferRate = 1000000000
+
+// AccountInfoClient is the part of ripple.Client needed by Configure
+type AccountInfoClient interface {
+	AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error)
+}
+
+// AccountConfig is how an account should be set up. Each nil field is
+// left as it is.
+type AccountConfig struct {
+	RequireDestTag *bool
+	DefaultRipple  *bool
+	DepositAuth    *bool
+	// As WithTransferRate takes it. Zero charges nothing.
+	TransferRate *uint32
+	// Zero uses the network's tick size
+	TickSize *uint8
+	// Empty removes the domain
+	Domain *string
+	// Empty removes the key
+	MessageKey *[]byte
+	// A zero account removes the minter
+	NFTokenMinter *data.Account
+}
+
+// flag is a setting of an account, kept in its flags and changed by an
+// AccountSet flag
+type flag struct {
+	want   *bool
+	ledger data.LedgerEntryFlag
+	tx     data.TransactionFlag
+}
+
+// Configure returns the AccountSets which would make the account in the
+// current ledger as config says, which are none if it is already
+func Configure(ctx context.Context, client AccountInfoClient, account data.Account, config AccountConfig) ([]*AccountSet, error) {
+	info, err := client.AccountInfoCtx(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	return config.AccountSets(account, &info.AccountData), nil
+}
+
+// AccountSets returns the AccountSets which would change root as config
+// says. The first sets every field which differs, and each setting that
+// is turned on or off needs one of its own, as an AccountSet changes one
+// at a time.
+func (config *AccountConfig) AccountSets(account data.Account, root *data.AccountRoot) []*AccountSet {
+	var flags data.LedgerEntryFlag
+	if root.Flags != nil {
+		flags = *root.Flags
+	}
+	var sets []*AccountSet
+	fields := NewAccountSet(account)
+	changed := false
+
+	if config.TransferRate != nil && rate(config.TransferRate) != rate(root.TransferRate) {
+		fields.WithTransferRate(*config.TransferRate)
+		changed = true
+	}
+	if config.TickSize != nil && *config.TickSize != size(root.TickSize) {
+		fields.WithTickSize(*config.TickSize)
+		changed = true
+	}
+	if config.Domain != nil && *config.Domain != string(value(root.Domain)) {
+		fields.WithDomain(*config.Domain)
+		changed = true
+	}
+	if config.MessageKey != nil && !bytes.Equal(*config.MessageKey, value(root.MessageKey)) {
+		fields.WithMessageKey(*config.MessageKey)
+		changed = true
+	}
+	if changed {
+		sets = append(sets, fields)
+	}
+
+	// The first setting to change goes with the fields, if any
+	next := func() *AccountSet {
+		if changed {
+			changed = false
+			return fields
+		}
+		b := NewAccountSet(account)
+		sets = append(sets, b)
+		return b
+	}
+	if config.NFTokenMinter != nil {
+		var minter data.Account
+		if root.NFTokenMinter != nil {
+			minter = *root.NFTokenMinter
+		}
+		switch {
+		case *config.NFTokenMinter == minter:
+		case config.NFTokenMinter.IsZero():
+			next().WithClearFlag(data.TxNFTokenMinter)
+		default:
+			next().WithNFTokenMinter(*config.NFTokenMinter)
+		}
+	}
+	for _, f := range []flag{
+		{config.RequireDestTag, data.LsRequireDestTag, data.TxSetRequireDest},
+		{config.DefaultRipple, data.LsDefaultRipple, data.TxDefaultRipple},
+		{config.DepositAuth, data.LsDepositAuth, data.TxDepositAuth},
+	} {
+		switch {
+		case f.want == nil || *f.want == (flags&f.ledger != 0):
+		case *f.want:
+			next().WithSetFlag(f.tx)
+		default:
+			next().WithClearFlag(f.tx)
+		}
+	}
+	return sets
+}
+
+func rate(r *uint32) uint32 {
+	if r == nil || *r == parTransferRate {
+		return 0
+	}
+	return *r
+}
+
+func size(s *uint8) uint8 {
+	if s == nil {
+		return 0
+	}
+	return *s
+}
+
+func value(v *data.VariableLength) data.VariableLength {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -0,0 +1,85 @@
+package builder
+
+import (
+	"context"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+// configured is an AccountInfoClient for an account with root
+type configured struct {
+	root data.AccountRoot
+}
+
+func (s configured) AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error) {
+	return &websockets.AccountInfoResult{AccountData: s.root}, nil
+}
+
+func newRoot() data.AccountRoot {
+	flags := data.LsRequireDestTag | data.LsDefaultRipple
+	rate, size := uint32(1002000000), uint8(5)
+	domain := data.VariableLength("example.com")
+	return data.AccountRoot{Flags: &flags, TransferRate: &rate, TickSize: &size, Domain: &domain}
+}
+
+func (s *BuilderSuite) TestConfigure(c *C) {
+	yes, no := true, false
+	rate, size, domain, minter := uint32(1002000000), uint8(0), "example.org", account(c, bob)
+	config := AccountConfig{
+		RequireDestTag: &yes,
+		DefaultRipple:  &no,
+		DepositAuth:    &yes,
+		TransferRate:   &rate,
+		TickSize:       &size,
+		Domain:         &domain,
+		NFTokenMinter:  &minter,
+	}
+	sets, err := Configure(context.Background(), configured{newRoot()}, account(c, alice), config)
+	c.Assert(err, IsNil)
+	c.Assert(sets, HasLen, 3)
+	var txs []*data.AccountSet
+	for _, set := range sets {
+		tx, err := set.WithSequence(1).Build()
+		c.Assert(err, IsNil)
+		c.Check(data.VerifyRoundTrip(tx), IsNil)
+		txs = append(txs, tx)
+	}
+	c.Check(txs[0].TransferRate, IsNil)
+	c.Check(*txs[0].TickSize, Equals, uint8(0))
+	c.Check(string(*txs[0].Domain), Equals, "example.org")
+	c.Check(*txs[0].NFTokenMinter, Equals, minter)
+	c.Check(*txs[0].SetFlag, Equals, uint32(data.TxNFTokenMinter))
+	c.Check(*txs[1].ClearFlag, Equals, uint32(data.TxDefaultRipple))
+	c.Check(txs[1].Domain, IsNil)
+	c.Check(*txs[2].SetFlag, Equals, uint32(data.TxDepositAuth))
+
+	// Only a setting
+	root := newRoot()
+	root.NFTokenMinter = &minter
+	sets = (&AccountConfig{NFTokenMinter: &data.Account{}, TickSize: root.TickSize}).AccountSets(account(c, alice), &root)
+	c.Assert(sets, HasLen, 1)
+	tx, err := sets[0].Build()
+	c.Assert(err, IsNil)
+	c.Check(*tx.ClearFlag, Equals, uint32(data.TxNFTokenMinter))
+	c.Check(tx.TickSize, IsNil)
+
+	// Nothing to do
+	par, empty := uint32(1000000000), ""
+	root = data.AccountRoot{}
+	sets = (&AccountConfig{RequireDestTag: &no, TransferRate: &par, Domain: &empty, MessageKey: &[]byte{}}).AccountSets(account(c, alice), &root)
+	c.Check(sets, HasLen, 0)
+	sets = (&AccountConfig{}).AccountSets(account(c, alice), &data.AccountRoot{})
+	c.Check(sets, HasLen, 0)
+}
+
+func (s *BuilderSuite) TestNFTokenMinter(c *C) {
+	_, err := NewAccountSet(account(c, alice)).WithNFTokenMinter(account(c, alice)).Build()
+	c.Check(err, ErrorMatches, "AccountSet: NFTokenMinter is the Account")
+	b := NewAccountSet(account(c, alice))
+	minter := account(c, bob)
+	b.tx.NFTokenMinter = &minter
+	_, err = b.Build()
+	c.Check(err, ErrorMatches, "AccountSet: NFTokenMinter needs SetFlag 10")
+}
@@ -0,0 +1,146 @@
+//go:build ignore
+
+// Writes common.go, giving every builder in transactions.go the methods
+// they all have. Run with go generate after adding a builder or a method.
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"text/template"
+)
+
+var common = template.Must(template.New("common").Parse(`// Code generated by gen_common.go. DO NOT EDIT.
+
+package builder
+
+import (
+	"context"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// The methods every builder has. WithFlags adds to any flags already set,
+// WithMemo adds a plain text memo and WithMemos any others, and
+// WithSequence and WithTicket replace each other. WithNetwork sets or
+// removes the NetworkID for the network, and Build refuses transactions
+// meant for another. Build checks the fields, including the memos, and
+// fills in any left missing when WithAutoFill has been used.
+{{range .}}
+func (b *{{.Name}}) WithFlags(flags data.TransactionFlag) *{{.Name}} {
+	b.flags(flags)
+	return b
+}
+
+func (b *{{.Name}}) WithSourceTag(tag uint32) *{{.Name}} {
+	b.sourceTag(tag)
+	return b
+}
+
+func (b *{{.Name}}) WithMemo(memoType, memoData string) *{{.Name}} {
+	b.memo(memoType, memoData)
+	return b
+}
+
+func (b *{{.Name}}) WithMemos(memos ...data.Memo) *{{.Name}} {
+	b.memos(memos)
+	return b
+}
+
+func (b *{{.Name}}) WithSequence(sequence uint32) *{{.Name}} {
+	b.sequence(sequence)
+	return b
+}
+
+func (b *{{.Name}}) WithTicket(ticket uint32) *{{.Name}} {
+	b.ticket(ticket)
+	return b
+}
+
+func (b *{{.Name}}) WithFee(fee data.Value) *{{.Name}} {
+	b.fee(fee)
+	return b
+}
+
+func (b *{{.Name}}) WithLastLedgerSequence(sequence uint32) *{{.Name}} {
+	b.lastLedgerSequence(sequence)
+	return b
+}
+
+func (b *{{.Name}}) WithAutoFill(fill *AutoFill) *{{.Name}} {
+	b.fill = fill
+	return b
+}
+
+func (b *{{.Name}}) WithNetwork(network *data.Network) *{{.Name}} {
+	b.network = network
+	return b
+}
+
+func (b *{{.Name}}) Build() (*data.{{.Tx}}, error) {
+	return b.BuildCtx(context.Background())
+}
+{{end}}`))
+
+// builderType is a builder, and the name of the transaction it builds
+type builderType struct {
+	Name string
+	Tx   string
+}
+
+// builders returns the types in file which embed builder, in order
+func builders(file *ast.File) []builderType {
+	var types []builderType
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typ := spec.(*ast.TypeSpec)
+			s, ok := typ.Type.(*ast.StructType)
+			if !ok || !embedsBuilder(s) {
+				continue
+			}
+			for _, field := range s.Fields.List {
+				if len(field.Names) == 1 && field.Names[0].Name == "tx" {
+					tx := field.Type.(*ast.StarExpr).X.(*ast.SelectorExpr)
+					types = append(types, builderType{typ.Name.Name, tx.Sel.Name})
+				}
+			}
+		}
+	}
+	return types
+}
+
+func embedsBuilder(s *ast.StructType) bool {
+	for _, field := range s.Fields.List {
+		if ident, ok := field.Type.(*ast.Ident); ok && len(field.Names) == 0 && ident.Name == "builder" {
+			return true
+		}
+	}
+	return false
+}
+
+func main() {
+	file, err := parser.ParseFile(token.NewFileSet(), "transactions.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := common.Execute(&b, builders(file)); err != nil {
+		log.Fatal(err)
+	}
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("common.go", source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,147 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// SignerListClient is the part of ripple.Client needed by ConfigureSigners
+type SignerListClient interface {
+	AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error)
+	AccountObjectsCtx(ctx context.Context, account data.Account, objectType string, ledgerIndex interface{}) (*websockets.AccountObjectsResult, error)
+}
+
+// Signers are the accounts which may sign for another, with the weight of
+// each, and the total weight of a set of signatures which makes a quorum.
+// No signers and a zero Quorum are no signer list.
+type Signers struct {
+	Quorum  uint32
+	Weights map[data.Account]uint16
+}
+
+// SignersOf returns the Signers of list, which may be nil
+func SignersOf(list *data.SignerList) Signers {
+	var signers Signers
+	if list == nil {
+		return signers
+	}
+	if list.SignerQuorum != nil {
+		signers.Quorum = *list.SignerQuorum
+	}
+	for _, entry := range list.SignerEntries {
+		if entry.Account == nil || entry.SignerWeight == nil {
+			continue
+		}
+		if signers.Weights == nil {
+			signers.Weights = make(map[data.Account]uint16)
+		}
+		signers.Weights[*entry.Account] = *entry.SignerWeight
+	}
+	return signers
+}
+
+// Total returns the weight of every signer together
+func (s Signers) Total() uint32 {
+	var total uint32
+	for _, weight := range s.Weights {
+		total += uint32(weight)
+	}
+	return total
+}
+
+// Equals reports whether s and other have the same quorum, signers and
+// weights
+func (s Signers) Equals(other Signers) bool {
+	if s.Quorum != other.Quorum || len(s.Weights) != len(other.Weights) {
+		return false
+	}
+	for account, weight := range s.Weights {
+		if w, ok := other.Weights[account]; !ok || w != weight {
+			return false
+		}
+	}
+	return true
+}
+
+// SignerListSet returns the builder of the SignerListSet which gives
+// account the signers, listed in the order of their accounts
+func (s Signers) SignerListSet(account data.Account) *SignerListSet {
+	b := NewSignerListSet(account, s.Quorum)
+	accounts := make([]data.Account, 0, len(s.Weights))
+	for a := range s.Weights {
+		accounts = append(accounts, a)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return bytes.Compare(accounts[i][:], accounts[j][:]) < 0 })
+	for _, a := range accounts {
+		b.WithSigner(a, s.Weights[a])
+	}
+	return b
+}
+
+// Validate returns an error unless s can be the signer list of account:
+// no more than 32 signers, none of them account, each with some weight,
+// and together weighing at least the quorum
+func (s Signers) Validate(account data.Account) error {
+	_, err := s.SignerListSet(account).Build()
+	return err
+}
+
+// Redundant reports whether the quorum can be reached without the
+// heaviest signer, so that losing any one key doesn't lock the account
+func (s Signers) Redundant() bool {
+	var heaviest uint16
+	for _, weight := range s.Weights {
+		if weight > heaviest {
+			heaviest = weight
+		}
+	}
+	return s.Total()-uint32(heaviest) >= s.Quorum
+}
+
+// SignerList returns the signer list of account in the validated ledger,
+// or nil if it has none
+func SignerList(ctx context.Context, client SignerListClient, account data.Account) (*data.SignerList, error) {
+	objects, err := client.AccountObjectsCtx(ctx, account, "signer_list", "validated")
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects.AccountObjects {
+		if list, ok := object.(*data.SignerList); ok {
+			return list, nil
+		}
+	}
+	return nil, nil
+}
+
+// ConfigureSigners returns the SignerListSet which gives account the
+// signers in place of those of its validated signer list, or nil if it
+// has them already. The signer list isn't removed if the account would
+// then have no key to sign with.
+func ConfigureSigners(ctx context.Context, client SignerListClient, account data.Account, signers Signers) (*SignerListSet, error) {
+	if err := signers.Validate(account); err != nil {
+		return nil, err
+	}
+	list, err := SignerList(ctx, client, account)
+	if err != nil {
+		return nil, err
+	}
+	if SignersOf(list).Equals(signers) {
+		return nil, nil
+	}
+	if signers.Quorum == 0 && len(signers.Weights) == 0 {
+		info, err := client.AccountInfoCtx(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		root := info.AccountData
+		if root.Flags != nil && *root.Flags&data.LsDisableMaster != 0 && root.RegularKey == nil {
+			return nil, fmt.Errorf("Account %s would have no key to sign with", account)
+		}
+	}
+	return signers.SignerListSet(account), nil
+}
@@ -0,0 +1,103 @@
+package builder
+
+import (
+	"context"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+// multisigned is a SignerListClient for an account with list, if any,
+// and root
+type multisigned struct {
+	list *data.SignerList
+	root data.AccountRoot
+}
+
+func (m multisigned) AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error) {
+	return &websockets.AccountInfoResult{AccountData: m.root}, nil
+}
+
+func (m multisigned) AccountObjectsCtx(ctx context.Context, account data.Account, objectType string, ledgerIndex interface{}) (*websockets.AccountObjectsResult, error) {
+	result := &websockets.AccountObjectsResult{Account: account}
+	if m.list != nil && objectType == "signer_list" {
+		result.AccountObjects = data.LedgerEntrySlice{m.list}
+	}
+	return result, nil
+}
+
+func signerList(c *C, quorum uint32, weights ...uint16) *data.SignerList {
+	list := &data.SignerList{SignerQuorum: &quorum}
+	for i, address := range []string{bob, gw}[:len(weights)] {
+		a, weight := account(c, address), weights[i]
+		list.SignerEntries = append(list.SignerEntries, data.SignerEntry{Account: &a, SignerWeight: &weight})
+	}
+	return list
+}
+
+func (s *BuilderSuite) TestSigners(c *C) {
+	a, b, g := account(c, alice), account(c, bob), account(c, gw)
+	signers := SignersOf(signerList(c, 2, 1, 1))
+	c.Check(signers.Quorum, Equals, uint32(2))
+	c.Check(signers.Weights, DeepEquals, map[data.Account]uint16{b: 1, g: 1})
+	c.Check(signers.Total(), Equals, uint32(2))
+	c.Check(signers.Redundant(), Equals, false)
+	c.Check(signers.Validate(a), IsNil)
+	c.Check(SignersOf(nil).Equals(Signers{}), Equals, true)
+	c.Check(signers.Equals(SignersOf(signerList(c, 2, 1, 2))), Equals, false)
+	c.Check(signers.Equals(SignersOf(signerList(c, 1, 1, 1))), Equals, false)
+	c.Check(signers.Equals(SignersOf(signerList(c, 2, 1, 1))), Equals, true)
+	c.Check(Signers{Quorum: 2, Weights: map[data.Account]uint16{b: 2, g: 2}}.Redundant(), Equals, true)
+
+	for _, test := range []struct {
+		signers Signers
+		err     string
+	}{
+		{Signers{Quorum: 3, Weights: map[data.Account]uint16{b: 1, g: 1}}, "SignerListSet: SignerQuorum 3 is more than the total weight 2"},
+		{Signers{Quorum: 1, Weights: map[data.Account]uint16{a: 1}}, "SignerListSet: SignerEntries include the Account"},
+		{Signers{Quorum: 1, Weights: map[data.Account]uint16{b: 0}}, "SignerListSet: SignerWeight of .* is zero"},
+		{Signers{Quorum: 1}, "SignerListSet: 0 SignerEntries is outside 1 to 32"},
+		{Signers{Weights: map[data.Account]uint16{b: 1}}, "SignerListSet: SignerEntries need a SignerQuorum"},
+	} {
+		c.Check(test.signers.Validate(a), ErrorMatches, test.err)
+	}
+}
+
+func (s *BuilderSuite) TestConfigureSigners(c *C) {
+	a, b, g := account(c, alice), account(c, bob), account(c, gw)
+	client := multisigned{list: signerList(c, 2, 1, 1)}
+	want := Signers{Quorum: 3, Weights: map[data.Account]uint16{g: 2, b: 1}}
+
+	set, err := ConfigureSigners(context.Background(), client, a, want)
+	c.Assert(err, IsNil)
+	tx, err := set.WithSequence(1).Build()
+	c.Assert(err, IsNil)
+	c.Check(data.VerifyRoundTrip(tx), IsNil)
+	c.Check(tx.SignerQuorum, Equals, uint32(3))
+	c.Assert(tx.SignerEntries, HasLen, 2)
+	c.Check(SignersOf(&data.SignerList{SignerQuorum: &tx.SignerQuorum, SignerEntries: tx.SignerEntries}).Equals(want), Equals, true)
+
+	// Already
+	set, err = ConfigureSigners(context.Background(), client, a, SignersOf(client.list))
+	c.Check(err, IsNil)
+	c.Check(set, IsNil)
+	set, err = ConfigureSigners(context.Background(), multisigned{}, a, Signers{})
+	c.Check(err, IsNil)
+	c.Check(set, IsNil)
+
+	_, err = ConfigureSigners(context.Background(), client, a, Signers{Quorum: 3, Weights: map[data.Account]uint16{b: 1}})
+	c.Check(err, ErrorMatches, "SignerListSet: SignerQuorum 3 is more than the total weight 1")
+
+	// Removed, unless that would lock the account
+	set, err = ConfigureSigners(context.Background(), client, a, Signers{})
+	c.Assert(err, IsNil)
+	tx, err = set.Build()
+	c.Assert(err, IsNil)
+	c.Check(tx.SignerQuorum, Equals, uint32(0))
+	c.Check(tx.SignerEntries, HasLen, 0)
+	flags := data.LsDisableMaster
+	client.root.Flags = &flags
+	_, err = ConfigureSigners(context.Background(), client, a, Signers{})
+	c.Check(err, ErrorMatches, "Account "+alice+" would have no key to sign with")
+}
@@ -0,0 +1,850 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// The most TicketCreate may create, and signers SignerListSet may list
+const (
+	maxTickets = 250
+	maxSigners = 32
+)
+
+type Payment struct {
+	builder
+	tx *data.Payment
+}
+
+func NewPayment(from, to data.Account, amount data.Amount) *Payment {
+	tx := data.TxFactory[data.PAYMENT]().(*data.Payment)
+	tx.Account, tx.Destination, tx.Amount = from, to, amount
+	return &Payment{builder{tx: tx}, tx}
+}
+
+func (b *Payment) WithDestinationTag(tag uint32) *Payment {
+	b.tx.DestinationTag = &tag
+	return b
+}
+
+func (b *Payment) WithInvoiceID(id data.Hash256) *Payment {
+	b.tx.InvoiceID = &id
+	return b
+}
+
+// WithSendMax sets the most to spend, in the currency to be spent
+func (b *Payment) WithSendMax(amount data.Amount) *Payment {
+	b.tx.SendMax = &amount
+	return b
+}
+
+// WithDeliverMin makes a partial payment, which fails unless at least
+// amount is delivered
+func (b *Payment) WithDeliverMin(amount data.Amount) *Payment {
+	b.tx.DeliverMin = &amount
+	b.flags(data.TxPartialPayment)
+	return b
+}
+
+func (b *Payment) WithPaths(paths data.PathSet) *Payment {
+	b.tx.Paths = &paths
+	return b
+}
+
+func (b *Payment) check() error {
+	tx := b.tx
+	if err := positive("Amount", &tx.Amount); err != nil {
+		return err
+	}
+	if tx.Destination.IsZero() {
+		return fmt.Errorf("Destination is missing")
+	}
+	xrp := tx.Amount.IsNative() && (tx.SendMax == nil || tx.SendMax.IsNative())
+	if tx.SendMax != nil {
+		if err := positive("SendMax", tx.SendMax); err != nil {
+			return err
+		}
+		if xrp {
+			return fmt.Errorf("SendMax is not needed to pay XRP with XRP")
+		}
+	}
+	if tx.DeliverMin != nil {
+		if err := positive("DeliverMin", tx.DeliverMin); err != nil {
+			return err
+		}
+		if tx.Flags == nil || *tx.Flags&data.TxPartialPayment == 0 {
+			return fmt.Errorf("DeliverMin needs the PartialPayment flag")
+		}
+	}
+	switch {
+	case xrp && tx.Paths != nil && len(*tx.Paths) > 0:
+		return fmt.Errorf("Paths are not used to pay XRP with XRP")
+	case xrp && tx.Destination == tx.Account:
+		return fmt.Errorf("Destination is the Account")
+	}
+	return nil
+}
+
+func (b *Payment) BuildCtx(ctx context.Context) (*data.Payment, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type AccountSet struct {
+	builder
+	tx *data.AccountSet
+}
+
+func NewAccountSet(account data.Account) *AccountSet {
+	tx := data.TxFactory[data.ACCOUNT_SET]().(*data.AccountSet)
+	tx.Account = account
+	return &AccountSet{builder{tx: tx}, tx}
+}
+
+func (b *AccountSet) WithDomain(domain string) *AccountSet {
+	v := data.VariableLength(domain)
+	b.tx.Domain = &v
+	return b
+}
+
+func (b *AccountSet) WithEmailHash(hash data.Hash128) *AccountSet {
+	b.tx.EmailHash = &hash
+	return b
+}
+
+func (b *AccountSet) WithMessageKey(key []byte) *AccountSet {
+	v := data.VariableLength(key)
+	b.tx.MessageKey = &v
+	return b
+}
+
+// WithTransferRate sets the charge for transferring the account's
+// issued currencies, in billionths of the amount, plus a billion
+func (b *AccountSet) WithTransferRate(rate uint32) *AccountSet {
+	b.tx.TransferRate = &rate
+	return b
+}
+
+func (b *AccountSet) WithTickSize(size uint8) *AccountSet {
+	b.tx.TickSize = &size
+	return b
+}
+
+// WithNFTokenMinter authorises minter to mint tokens for the account,
+// replacing any minter authorised before
+func (b *AccountSet) WithNFTokenMinter(minter data.Account) *AccountSet {
+	b.tx.NFTokenMinter = &minter
+	return b.WithSetFlag(data.TxNFTokenMinter)
+}
+
+// WithSetFlag enables an account setting, such as TxSetRequireDest
+func (b *AccountSet) WithSetFlag(flag data.TransactionFlag) *AccountSet {
+	v := uint32(flag)
+	b.tx.SetFlag = &v
+	return b
+}
+
+// WithClearFlag disables an account setting, such as TxSetRequireDest
+func (b *AccountSet) WithClearFlag(flag data.TransactionFlag) *AccountSet {
+	v := uint32(flag)
+	b.tx.ClearFlag = &v
+	return b
+}
+
+func (b *AccountSet) check() error {
+	tx := b.tx
+	switch {
+	case tx.Domain != nil && len(*tx.Domain) > 256:
+		return fmt.Errorf("Domain is longer than 256 bytes")
+	case tx.TransferRate != nil && *tx.TransferRate != 0 && (*tx.TransferRate < 1000000000 || *tx.TransferRate > 2000000000):
+		return fmt.Errorf("TransferRate %d is outside 1000000000 to 2000000000", *tx.TransferRate)
+	case tx.TickSize != nil && *tx.TickSize != 0 && (*tx.TickSize < 3 || *tx.TickSize > 15):
+		return fmt.Errorf("TickSize %d is outside 3 to 15", *tx.TickSize)
+	case tx.SetFlag != nil && tx.ClearFlag != nil && *tx.SetFlag == *tx.ClearFlag:
+		return fmt.Errorf("SetFlag and ClearFlag are both %d", *tx.SetFlag)
+	case tx.NFTokenMinter != nil && (tx.SetFlag == nil || *tx.SetFlag != uint32(data.TxNFTokenMinter)):
+		return fmt.Errorf("NFTokenMinter needs SetFlag %d", data.TxNFTokenMinter)
+	case tx.NFTokenMinter != nil && *tx.NFTokenMinter == tx.Account:
+		return fmt.Errorf("NFTokenMinter is the Account")
+	}
+	return nil
+}
+
+func (b *AccountSet) BuildCtx(ctx context.Context) (*data.AccountSet, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+// SetRegularKey removes the regular key of the account, unless
+// WithRegularKey is used
+type SetRegularKey struct {
+	builder
+	tx *data.SetRegularKey
+}
+
+func NewSetRegularKey(account data.Account) *SetRegularKey {
+	tx := data.TxFactory[data.SET_REGULAR_KEY]().(*data.SetRegularKey)
+	tx.Account = account
+	return &SetRegularKey{builder{tx: tx}, tx}
+}
+
+func (b *SetRegularKey) WithRegularKey(key data.RegularKey) *SetRegularKey {
+	b.tx.RegularKey = &key
+	return b
+}
+
+func (b *SetRegularKey) check() error {
+	if b.tx.RegularKey != nil && data.Account(*b.tx.RegularKey) == b.tx.Account {
+		return fmt.Errorf("RegularKey is the Account")
+	}
+	return nil
+}
+
+func (b *SetRegularKey) BuildCtx(ctx context.Context) (*data.SetRegularKey, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type OfferCreate struct {
+	builder
+	tx *data.OfferCreate
+}
+
+func NewOfferCreate(account data.Account, takerPays, takerGets data.Amount) *OfferCreate {
+	tx := data.TxFactory[data.OFFER_CREATE]().(*data.OfferCreate)
+	tx.Account, tx.TakerPays, tx.TakerGets = account, takerPays, takerGets
+	return &OfferCreate{builder{tx: tx}, tx}
+}
+
+func (b *OfferCreate) WithExpiration(expiration uint32) *OfferCreate {
+	b.tx.Expiration = &expiration
+	return b
+}
+
+// WithOfferSequence replaces the offer created by sequence
+func (b *OfferCreate) WithOfferSequence(sequence uint32) *OfferCreate {
+	b.tx.OfferSequence = &sequence
+	return b
+}
+
+func (b *OfferCreate) check() error {
+	tx := b.tx
+	if err := positive("TakerPays", &tx.TakerPays); err != nil {
+		return err
+	}
+	if err := positive("TakerGets", &tx.TakerGets); err != nil {
+		return err
+	}
+	switch {
+	case tx.TakerPays.IsNative() && tx.TakerGets.IsNative():
+		return fmt.Errorf("TakerPays and TakerGets are both XRP")
+	case tx.TakerPays.Currency == tx.TakerGets.Currency && tx.TakerPays.Issuer == tx.TakerGets.Issuer:
+		return fmt.Errorf("TakerPays and TakerGets are both %s", tx.TakerPays.Asset())
+	}
+	return nil
+}
+
+func (b *OfferCreate) BuildCtx(ctx context.Context) (*data.OfferCreate, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type OfferCancel struct {
+	builder
+	tx *data.OfferCancel
+}
+
+func NewOfferCancel(account data.Account, sequence uint32) *OfferCancel {
+	tx := data.TxFactory[data.OFFER_CANCEL]().(*data.OfferCancel)
+	tx.Account, tx.OfferSequence = account, sequence
+	return &OfferCancel{builder{tx: tx}, tx}
+}
+
+func (b *OfferCancel) check() error {
+	if b.tx.OfferSequence == 0 {
+		return fmt.Errorf("OfferSequence is missing")
+	}
+	return nil
+}
+
+func (b *OfferCancel) BuildCtx(ctx context.Context) (*data.OfferCancel, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type TrustSet struct {
+	builder
+	tx *data.TrustSet
+}
+
+func NewTrustSet(account data.Account, limit data.Amount) *TrustSet {
+	tx := data.TxFactory[data.TRUST_SET]().(*data.TrustSet)
+	tx.Account, tx.LimitAmount = account, limit
+	return &TrustSet{builder{tx: tx}, tx}
+}
+
+func (b *TrustSet) WithQualityIn(quality uint32) *TrustSet {
+	b.tx.QualityIn = &quality
+	return b
+}
+
+func (b *TrustSet) WithQualityOut(quality uint32) *TrustSet {
+	b.tx.QualityOut = &quality
+	return b
+}
+
+func (b *TrustSet) check() error {
+	limit := &b.tx.LimitAmount
+	switch {
+	case limit.Value == nil:
+		return fmt.Errorf("LimitAmount is missing")
+	case limit.IsNative():
+		return fmt.Errorf("LimitAmount can't be XRP")
+	case limit.IsNegative():
+		return fmt.Errorf("LimitAmount is negative")
+	case limit.Issuer.IsZero():
+		return fmt.Errorf("LimitAmount has no issuer")
+	case limit.Issuer == b.tx.Account:
+		return fmt.Errorf("LimitAmount is issued by the Account")
+	}
+	return nil
+}
+
+func (b *TrustSet) BuildCtx(ctx context.Context) (*data.TrustSet, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type EscrowCreate struct {
+	builder
+	tx *data.EscrowCreate
+}
+
+func NewEscrowCreate(account, destination data.Account, amount data.Amount) *EscrowCreate {
+	tx := data.TxFactory[data.ESCROW_CREATE]().(*data.EscrowCreate)
+	tx.Account, tx.Destination, tx.Amount = account, destination, amount
+	return &EscrowCreate{builder{tx: tx}, tx}
+}
+
+func (b *EscrowCreate) WithDigest(digest data.Hash256) *EscrowCreate {
+	b.tx.Digest = &digest
+	return b
+}
+
+func (b *EscrowCreate) WithCancelAfter(t uint32) *EscrowCreate {
+	b.tx.CancelAfter = &t
+	return b
+}
+
+func (b *EscrowCreate) WithFinishAfter(t uint32) *EscrowCreate {
+	b.tx.FinishAfter = &t
+	return b
+}
+
+func (b *EscrowCreate) WithDestinationTag(tag uint32) *EscrowCreate {
+	b.tx.DestinationTag = &tag
+	return b
+}
+
+func (b *EscrowCreate) check() error {
+	tx := b.tx
+	if err := positiveXRP("Amount", &tx.Amount); err != nil {
+		return err
+	}
+	switch {
+	case tx.Destination.IsZero():
+		return fmt.Errorf("Destination is missing")
+	case tx.FinishAfter == nil && tx.Digest == nil:
+		return fmt.Errorf("FinishAfter or Digest is needed")
+	case tx.FinishAfter != nil && tx.CancelAfter != nil && *tx.CancelAfter <= *tx.FinishAfter:
+		return fmt.Errorf("CancelAfter is not after FinishAfter")
+	}
+	return nil
+}
+
+func (b *EscrowCreate) BuildCtx(ctx context.Context) (*data.EscrowCreate, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type EscrowFinish struct {
+	builder
+	tx *data.EscrowFinish
+}
+
+// NewEscrowFinish finishes the escrow created by owner with sequence
+func NewEscrowFinish(account, owner data.Account, sequence uint32) *EscrowFinish {
+	tx := data.TxFactory[data.ESCROW_FINISH]().(*data.EscrowFinish)
+	tx.Account, tx.Owner, tx.OfferSequence = account, owner, sequence
+	return &EscrowFinish{builder{tx: tx}, tx}
+}
+
+func (b *EscrowFinish) WithDigest(digest data.Hash256) *EscrowFinish {
+	b.tx.Digest = &digest
+	return b
+}
+
+func (b *EscrowFinish) WithProof(proof data.Hash256) *EscrowFinish {
+	b.tx.Proof = &proof
+	return b
+}
+
+func (b *EscrowFinish) check() error {
+	switch {
+	case b.tx.Owner.IsZero():
+		return fmt.Errorf("Owner is missing")
+	case b.tx.Proof != nil && b.tx.Digest == nil:
+		return fmt.Errorf("Proof needs a Digest")
+	}
+	return nil
+}
+
+func (b *EscrowFinish) BuildCtx(ctx context.Context) (*data.EscrowFinish, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type EscrowCancel struct {
+	builder
+	tx *data.EscrowCancel
+}
+
+// NewEscrowCancel cancels the escrow created by owner with sequence
+func NewEscrowCancel(account, owner data.Account, sequence uint32) *EscrowCancel {
+	tx := data.TxFactory[data.ESCROW_CANCEL]().(*data.EscrowCancel)
+	tx.Account, tx.Owner, tx.OfferSequence = account, owner, sequence
+	return &EscrowCancel{builder{tx: tx}, tx}
+}
+
+func (b *EscrowCancel) check() error {
+	if b.tx.Owner.IsZero() {
+		return fmt.Errorf("Owner is missing")
+	}
+	return nil
+}
+
+func (b *EscrowCancel) BuildCtx(ctx context.Context) (*data.EscrowCancel, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type PaymentChannelCreate struct {
+	builder
+	tx *data.PaymentChannelCreate
+}
+
+// NewPaymentChannelCreate opens a channel which can be closed settleDelay
+// seconds after the source asks, with claims signed by publicKey
+func NewPaymentChannelCreate(account, destination data.Account, amount data.Amount, settleDelay uint32, publicKey data.PublicKey) *PaymentChannelCreate {
+	tx := data.TxFactory[data.PAYCHAN_CREATE]().(*data.PaymentChannelCreate)
+	tx.Account, tx.Destination, tx.Amount = account, destination, amount
+	tx.SettleDelay, tx.PublicKey = settleDelay, publicKey
+	return &PaymentChannelCreate{builder{tx: tx}, tx}
+}
+
+func (b *PaymentChannelCreate) WithCancelAfter(t uint32) *PaymentChannelCreate {
+	b.tx.CancelAfter = &t
+	return b
+}
+
+func (b *PaymentChannelCreate) WithDestinationTag(tag uint32) *PaymentChannelCreate {
+	b.tx.DestinationTag = &tag
+	return b
+}
+
+func (b *PaymentChannelCreate) check() error {
+	tx := b.tx
+	if err := positiveXRP("Amount", &tx.Amount); err != nil {
+		return err
+	}
+	if err := otherAccount(tx.Account, tx.Destination); err != nil {
+		return err
+	}
+	if tx.PublicKey.IsZero() {
+		return fmt.Errorf("PublicKey is missing")
+	}
+	return nil
+}
+
+func (b *PaymentChannelCreate) BuildCtx(ctx context.Context) (*data.PaymentChannelCreate, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type PaymentChannelFund struct {
+	builder
+	tx *data.PaymentChannelFund
+}
+
+func NewPaymentChannelFund(account data.Account, channel data.Hash256, amount data.Amount) *PaymentChannelFund {
+	tx := data.TxFactory[data.PAYCHAN_FUND]().(*data.PaymentChannelFund)
+	tx.Account, tx.Channel, tx.Amount = account, channel, amount
+	return &PaymentChannelFund{builder{tx: tx}, tx}
+}
+
+func (b *PaymentChannelFund) WithExpiration(expiration uint32) *PaymentChannelFund {
+	b.tx.Expiration = &expiration
+	return b
+}
+
+func (b *PaymentChannelFund) check() error {
+	if b.tx.Channel.IsZero() {
+		return fmt.Errorf("Channel is missing")
+	}
+	return positiveXRP("Amount", &b.tx.Amount)
+}
+
+func (b *PaymentChannelFund) BuildCtx(ctx context.Context) (*data.PaymentChannelFund, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type PaymentChannelClaim struct {
+	builder
+	tx *data.PaymentChannelClaim
+}
+
+func NewPaymentChannelClaim(account data.Account, channel data.Hash256) *PaymentChannelClaim {
+	tx := data.TxFactory[data.PAYCHAN_CLAIM]().(*data.PaymentChannelClaim)
+	tx.Account, tx.Channel = account, channel
+	return &PaymentChannelClaim{builder{tx: tx}, tx}
+}
+
+// WithBalance sets the total delivered by the channel once claimed
+func (b *PaymentChannelClaim) WithBalance(balance data.Amount) *PaymentChannelClaim {
+	b.tx.Balance = &balance
+	return b
+}
+
+// WithAmount sets the total authorised by the signature
+func (b *PaymentChannelClaim) WithAmount(amount data.Amount) *PaymentChannelClaim {
+	b.tx.Amount = &amount
+	return b
+}
+
+func (b *PaymentChannelClaim) WithSignature(signature []byte, publicKey data.PublicKey) *PaymentChannelClaim {
+	v := data.VariableLength(signature)
+	b.tx.Signature, b.tx.PublicKey = &v, &publicKey
+	return b
+}
+
+func (b *PaymentChannelClaim) check() error {
+	tx := b.tx
+	if tx.Channel.IsZero() {
+		return fmt.Errorf("Channel is missing")
+	}
+	if tx.Balance != nil {
+		if err := positiveXRP("Balance", tx.Balance); err != nil {
+			return err
+		}
+	}
+	if tx.Amount != nil {
+		if err := positiveXRP("Amount", tx.Amount); err != nil {
+			return err
+		}
+	}
+	if tx.Signature != nil && (tx.Balance == nil || tx.Amount == nil) {
+		return fmt.Errorf("Signature needs a Balance and Amount")
+	}
+	return nil
+}
+
+func (b *PaymentChannelClaim) BuildCtx(ctx context.Context) (*data.PaymentChannelClaim, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type CheckCreate struct {
+	builder
+	tx *data.CheckCreate
+}
+
+func NewCheckCreate(account, destination data.Account, sendMax data.Amount) *CheckCreate {
+	tx := data.TxFactory[data.CHECK_CREATE]().(*data.CheckCreate)
+	tx.Account, tx.Destination, tx.SendMax = account, destination, sendMax
+	return &CheckCreate{builder{tx: tx}, tx}
+}
+
+func (b *CheckCreate) WithDestinationTag(tag uint32) *CheckCreate {
+	b.tx.DestinationTag = &tag
+	return b
+}
+
+func (b *CheckCreate) WithExpiration(expiration uint32) *CheckCreate {
+	b.tx.Expiration = &expiration
+	return b
+}
+
+func (b *CheckCreate) WithInvoiceID(id data.Hash256) *CheckCreate {
+	b.tx.InvoiceID = &id
+	return b
+}
+
+func (b *CheckCreate) check() error {
+	if err := positive("SendMax", &b.tx.SendMax); err != nil {
+		return err
+	}
+	return otherAccount(b.tx.Account, b.tx.Destination)
+}
+
+func (b *CheckCreate) BuildCtx(ctx context.Context) (*data.CheckCreate, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+// CheckCash needs either WithAmount or WithDeliverMin
+type CheckCash struct {
+	builder
+	tx *data.CheckCash
+}
+
+func NewCheckCash(account data.Account, check data.Hash256) *CheckCash {
+	tx := data.TxFactory[data.CHECK_CASH]().(*data.CheckCash)
+	tx.Account, tx.CheckID = account, check
+	return &CheckCash{builder{tx: tx}, tx}
+}
+
+func (b *CheckCash) WithAmount(amount data.Amount) *CheckCash {
+	b.tx.Amount = &amount
+	return b
+}
+
+func (b *CheckCash) WithDeliverMin(amount data.Amount) *CheckCash {
+	b.tx.DeliverMin = &amount
+	return b
+}
+
+func (b *CheckCash) check() error {
+	tx := b.tx
+	switch {
+	case tx.CheckID.IsZero():
+		return fmt.Errorf("CheckID is missing")
+	case tx.Amount != nil && tx.DeliverMin != nil:
+		return fmt.Errorf("Amount and DeliverMin are both set")
+	case tx.Amount != nil:
+		return positive("Amount", tx.Amount)
+	case tx.DeliverMin != nil:
+		return positive("DeliverMin", tx.DeliverMin)
+	}
+	return fmt.Errorf("Amount or DeliverMin is needed")
+}
+
+func (b *CheckCash) BuildCtx(ctx context.Context) (*data.CheckCash, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type CheckCancel struct {
+	builder
+	tx *data.CheckCancel
+}
+
+func NewCheckCancel(account data.Account, check data.Hash256) *CheckCancel {
+	tx := data.TxFactory[data.CHECK_CANCEL]().(*data.CheckCancel)
+	tx.Account, tx.CheckID = account, check
+	return &CheckCancel{builder{tx: tx}, tx}
+}
+
+func (b *CheckCancel) check() error {
+	if b.tx.CheckID.IsZero() {
+		return fmt.Errorf("CheckID is missing")
+	}
+	return nil
+}
+
+func (b *CheckCancel) BuildCtx(ctx context.Context) (*data.CheckCancel, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+// DepositPreauth needs either WithAuthorize or WithUnauthorize
+type DepositPreauth struct {
+	builder
+	tx *data.DepositPreauth
+}
+
+func NewDepositPreauth(account data.Account) *DepositPreauth {
+	tx := data.TxFactory[data.DEPOSIT_PREAUTH]().(*data.DepositPreauth)
+	tx.Account = account
+	return &DepositPreauth{builder{tx: tx}, tx}
+}
+
+// WithAuthorize lets sender deposit to the account
+func (b *DepositPreauth) WithAuthorize(sender data.Account) *DepositPreauth {
+	b.tx.Authorize = &sender
+	return b
+}
+
+// WithUnauthorize withdraws the preauthorisation of sender
+func (b *DepositPreauth) WithUnauthorize(sender data.Account) *DepositPreauth {
+	b.tx.Unauthorize = &sender
+	return b
+}
+
+func (b *DepositPreauth) check() error {
+	tx := b.tx
+	switch {
+	case tx.Authorize != nil && tx.Unauthorize != nil:
+		return fmt.Errorf("Authorize and Unauthorize are both set")
+	case tx.Authorize != nil:
+		if *tx.Authorize == tx.Account {
+			return fmt.Errorf("Authorize is the Account")
+		}
+		return nil
+	case tx.Unauthorize != nil:
+		return nil
+	}
+	return fmt.Errorf("Authorize or Unauthorize is needed")
+}
+
+func (b *DepositPreauth) BuildCtx(ctx context.Context) (*data.DepositPreauth, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type TicketCreate struct {
+	builder
+	tx *data.TicketCreate
+}
+
+func NewTicketCreate(account data.Account, count uint32) *TicketCreate {
+	tx := data.TxFactory[data.TICKET_CREATE]().(*data.TicketCreate)
+	tx.Account, tx.TicketCount = account, count
+	return &TicketCreate{builder{tx: tx}, tx}
+}
+
+func (b *TicketCreate) check() error {
+	if b.tx.TicketCount < 1 || b.tx.TicketCount > maxTickets {
+		return fmt.Errorf("TicketCount %d is outside 1 to %d", b.tx.TicketCount, maxTickets)
+	}
+	return nil
+}
+
+func (b *TicketCreate) BuildCtx(ctx context.Context) (*data.TicketCreate, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+// SignerListSet with a zero quorum, and no signers, removes the signer list
+type SignerListSet struct {
+	builder
+	tx *data.SignerListSet
+}
+
+func NewSignerListSet(account data.Account, quorum uint32) *SignerListSet {
+	tx := data.TxFactory[data.SIGNER_LIST_SET]().(*data.SignerListSet)
+	tx.Account, tx.SignerQuorum = account, quorum
+	return &SignerListSet{builder{tx: tx}, tx}
+}
+
+func (b *SignerListSet) WithSigner(account data.Account, weight uint16) *SignerListSet {
+	b.tx.SignerEntries = append(b.tx.SignerEntries, data.SignerEntry{
+		Account:      &account,
+		SignerWeight: &weight,
+	})
+	return b
+}
+
+func (b *SignerListSet) check() error {
+	tx := b.tx
+	if tx.SignerQuorum == 0 {
+		if len(tx.SignerEntries) > 0 {
+			return fmt.Errorf("SignerEntries need a SignerQuorum")
+		}
+		return nil
+	}
+	if len(tx.SignerEntries) < 1 || len(tx.SignerEntries) > maxSigners {
+		return fmt.Errorf("%d SignerEntries is outside 1 to %d", len(tx.SignerEntries), maxSigners)
+	}
+	seen := make(map[data.Account]bool)
+	var total uint32
+	for _, entry := range tx.SignerEntries {
+		switch {
+		case entry.Account == nil || entry.SignerWeight == nil:
+			return fmt.Errorf("SignerEntry is incomplete")
+		case *entry.Account == tx.Account:
+			return fmt.Errorf("SignerEntries include the Account")
+		case seen[*entry.Account]:
+			return fmt.Errorf("SignerEntries include %s twice", entry.Account)
+		case *entry.SignerWeight == 0:
+			return fmt.Errorf("SignerWeight of %s is zero", entry.Account)
+		}
+		seen[*entry.Account] = true
+		total += uint32(*entry.SignerWeight)
+	}
+	if total < tx.SignerQuorum {
+		return fmt.Errorf("SignerQuorum %d is more than the total weight %d", tx.SignerQuorum, total)
+	}
+	return nil
+}
+
+func (b *SignerListSet) BuildCtx(ctx context.Context) (*data.SignerListSet, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
+
+type AccountDelete struct {
+	builder
+	tx *data.AccountDelete
+}
+
+// NewAccountDelete deletes account and sends its XRP to destination
+func NewAccountDelete(account, destination data.Account) *AccountDelete {
+	tx := data.TxFactory[data.ACCOUNT_DELETE]().(*data.AccountDelete)
+	tx.Account, tx.Destination = account, destination
+	return &AccountDelete{builder{tx: tx}, tx}
+}
+
+func (b *AccountDelete) WithDestinationTag(tag uint32) *AccountDelete {
+	b.tx.DestinationTag = &tag
+	return b
+}
+
+func (b *AccountDelete) check() error {
+	return otherAccount(b.tx.Account, b.tx.Destination)
+}
+
+func (b *AccountDelete) BuildCtx(ctx context.Context) (*data.AccountDelete, error) {
+	if err := b.build(ctx, b.check); err != nil {
+		return nil, err
+	}
+	return b.tx, nil
+}
@@ -0,0 +1,156 @@
+// Package ripple holds the interfaces shared by the transports in its
+// subpackages, so that application code and tests need not care how
+// they reach rippled.
+package ripple
+
+import (
+	"context"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/rpc"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// TransactionReader looks up transactions, singly or by account
+type TransactionReader interface {
+	Tx(hash data.Hash256) (*websockets.TxResult, error)
+	TxCtx(ctx context.Context, hash data.Hash256) (*websockets.TxResult, error)
+	TransactionEntry(hash data.Hash256, ledger interface{}) (*data.TransactionWithMetaData, error)
+	TransactionEntryCtx(ctx context.Context, hash data.Hash256, ledger interface{}) (*data.TransactionWithMetaData, error)
+	TxHistory(start uint32) (*websockets.TxHistoryResult, error)
+	TxHistoryCtx(ctx context.Context, start uint32) (*websockets.TxHistoryResult, error)
+	AccountTx(account data.Account, pageSize int, minLedger, maxLedger int64) chan *data.TransactionWithMetaData
+	AccountTxCtx(ctx context.Context, account data.Account, options websockets.AccountTxOptions) (<-chan *data.TransactionWithMetaData, <-chan error)
+	AccountTxPagesCtx(ctx context.Context, account data.Account, options websockets.AccountTxOptions) (<-chan *websockets.AccountTxResult, <-chan error)
+}
+
+// LedgerReader reads ledgers, their headers and their state
+type LedgerReader interface {
+	LedgerData(ledger interface{}, marker *data.Hash256) (*websockets.LedgerDataResult, error)
+	LedgerDataCtx(ctx context.Context, ledger interface{}, marker *data.Hash256) (*websockets.LedgerDataResult, error)
+	StreamLedgerData(ledger interface{}) chan data.LedgerEntrySlice
+	StreamLedgerDataCtx(ctx context.Context, ledger interface{}, options websockets.LedgerDataOptions) *websockets.LedgerDataIterator
+	Ledger(ledger interface{}, transactions bool) (*websockets.LedgerResult, error)
+	LedgerCtx(ctx context.Context, ledger interface{}, transactions bool) (*websockets.LedgerResult, error)
+	LedgerHeader(ledger interface{}) (*websockets.LedgerHeaderResult, error)
+	LedgerHeaderCtx(ctx context.Context, ledger interface{}) (*websockets.LedgerHeaderResult, error)
+	LedgerEntry(selector websockets.LedgerEntrySelector, ledger interface{}) (data.LedgerEntry, error)
+	LedgerEntryCtx(ctx context.Context, selector websockets.LedgerEntrySelector, ledger interface{}) (data.LedgerEntry, error)
+	LedgerClosed() (*websockets.LedgerClosedResult, error)
+	LedgerClosedCtx(ctx context.Context) (*websockets.LedgerClosedResult, error)
+	LedgerCurrent() (uint32, error)
+	LedgerCurrentCtx(ctx context.Context) (uint32, error)
+	BookChanges(ledger interface{}) (*websockets.BookChangesResult, error)
+	BookChangesCtx(ctx context.Context, ledger interface{}) (*websockets.BookChangesResult, error)
+}
+
+// AccountReader reads the state of accounts
+type AccountReader interface {
+	AccountInfo(a data.Account) (*websockets.AccountInfoResult, error)
+	AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error)
+	AccountLines(account data.Account, ledgerIndex interface{}) (*websockets.AccountLinesResult, error)
+	AccountLinesCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountLinesResult, error)
+	AccountOffers(account data.Account, ledgerIndex interface{}) (*websockets.AccountOffersResult, error)
+	AccountOffersCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountOffersResult, error)
+	AccountObjects(account data.Account, objectType string, ledgerIndex interface{}) (*websockets.AccountObjectsResult, error)
+	AccountObjectsCtx(ctx context.Context, account data.Account, objectType string, ledgerIndex interface{}) (*websockets.AccountObjectsResult, error)
+	AccountChannels(account data.Account, destination *data.Account, ledgerIndex interface{}) (*websockets.AccountChannelsResult, error)
+	AccountChannelsCtx(ctx context.Context, account data.Account, destination *data.Account, ledgerIndex interface{}) (*websockets.AccountChannelsResult, error)
+	AccountCurrencies(account data.Account, ledgerIndex interface{}) (*websockets.AccountCurrenciesResult, error)
+	AccountCurrenciesCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountCurrenciesResult, error)
+	AccountNFTs(account data.Account, ledgerIndex interface{}) (*websockets.AccountNFTsResult, error)
+	AccountNFTsCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountNFTsResult, error)
+	DepositAuthorized(source, destination data.Account, ledgerIndex interface{}) (bool, error)
+	DepositAuthorizedCtx(ctx context.Context, source, destination data.Account, ledgerIndex interface{}) (bool, error)
+	GatewayBalances(account data.Account, hotWallets []data.Account, ledgerIndex interface{}) (*websockets.GatewayBalancesResult, error)
+	GatewayBalancesCtx(ctx context.Context, account data.Account, hotWallets []data.Account, ledgerIndex interface{}) (*websockets.GatewayBalancesResult, error)
+	NoRippleCheck(account data.Account, role string, ledgerIndex interface{}) (*websockets.NoRippleCheckResult, error)
+	NoRippleCheckCtx(ctx context.Context, account data.Account, role string, ledgerIndex interface{}) (*websockets.NoRippleCheckResult, error)
+}
+
+// MarketReader reads order books, AMMs, NFT offers and payment paths
+type MarketReader interface {
+	RipplePathFind(src, dest data.Account, amount data.Amount, srcCurr *[]data.Currency) (*websockets.RipplePathFindResult, error)
+	RipplePathFindCtx(ctx context.Context, src, dest data.Account, amount data.Amount, srcCurr *[]data.Currency) (*websockets.RipplePathFindResult, error)
+	BookOffers(taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*websockets.BookOffersResult, error)
+	BookOffersCtx(ctx context.Context, taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*websockets.BookOffersResult, error)
+	AMMInfo(asset, asset2 data.Asset, ledgerIndex interface{}) (*websockets.AMMInfoResult, error)
+	AMMInfoCtx(ctx context.Context, asset, asset2 data.Asset, ledgerIndex interface{}) (*websockets.AMMInfoResult, error)
+	AMMInfoByAccount(ammAccount data.Account, ledgerIndex interface{}) (*websockets.AMMInfoResult, error)
+	AMMInfoByAccountCtx(ctx context.Context, ammAccount data.Account, ledgerIndex interface{}) (*websockets.AMMInfoResult, error)
+	NFTBuyOffers(nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTOffersResult, error)
+	NFTBuyOffersCtx(ctx context.Context, nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTOffersResult, error)
+	NFTSellOffers(nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTOffersResult, error)
+	NFTSellOffersCtx(ctx context.Context, nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTOffersResult, error)
+	NFTInfo(nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTInfoResult, error)
+	NFTInfoCtx(ctx context.Context, nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTInfoResult, error)
+}
+
+// ServerReader asks about the server, its validators, amendments and fees
+type ServerReader interface {
+	ServerInfo() (*websockets.ServerInfo, error)
+	ServerInfoCtx(ctx context.Context) (*websockets.ServerInfo, error)
+	ServerState() (*websockets.ServerState, error)
+	ServerStateCtx(ctx context.Context) (*websockets.ServerState, error)
+	Manifest(publicKey string) (*websockets.ManifestResult, error)
+	ManifestCtx(ctx context.Context, publicKey string) (*websockets.ManifestResult, error)
+	Validators() (*websockets.ValidatorsResult, error)
+	ValidatorsCtx(ctx context.Context) (*websockets.ValidatorsResult, error)
+	ValidatorListSites() ([]websockets.ValidatorSite, error)
+	ValidatorListSitesCtx(ctx context.Context) ([]websockets.ValidatorSite, error)
+	Feature(feature string) (websockets.Features, error)
+	FeatureCtx(ctx context.Context, feature string) (websockets.Features, error)
+	Fee() (*websockets.FeeResult, error)
+	FeeCtx(ctx context.Context) (*websockets.FeeResult, error)
+}
+
+// Reader is every command which only reads
+type Reader interface {
+	TransactionReader
+	LedgerReader
+	AccountReader
+	MarketReader
+	ServerReader
+}
+
+// Submitter submits signed transactions
+type Submitter interface {
+	Submit(tx data.Transaction) (*websockets.SubmitResult, error)
+	SubmitCtx(ctx context.Context, tx data.Transaction) (*websockets.SubmitResult, error)
+	SubmitMultisigned(tx data.Transaction) (*websockets.SubmitResult, error)
+	SubmitMultisignedCtx(ctx context.Context, tx data.Transaction) (*websockets.SubmitResult, error)
+	SubmitBatch(txs []data.Transaction) ([]*websockets.SubmitResult, error)
+	SubmitBatchCtx(ctx context.Context, txs []data.Transaction) ([]*websockets.SubmitResult, error)
+}
+
+// Channels signs and verifies payment channel claims. ChannelAuthorize
+// sends the secret to the server, so only use it with a trusted one.
+type Channels interface {
+	ChannelAuthorize(channel data.Hash256, amount data.Value, secret string) (data.VariableLength, error)
+	ChannelAuthorizeCtx(ctx context.Context, channel data.Hash256, amount data.Value, secret string) (data.VariableLength, error)
+	ChannelVerify(channel data.Hash256, amount data.Value, publicKey data.PublicKey, signature data.VariableLength) (bool, error)
+	ChannelVerifyCtx(ctx context.Context, channel data.Hash256, amount data.Value, publicKey data.PublicKey, signature data.VariableLength) (bool, error)
+}
+
+// Admin holds the commands a server only allows admins, which the
+// standalone servers of tests do
+type Admin interface {
+	LedgerAccept() (uint32, error)
+	LedgerAcceptCtx(ctx context.Context) (uint32, error)
+}
+
+// Client is implemented by both websockets.Remote and rpc.Client. Code
+// which only needs some of it should take the smaller interfaces, as
+// it would an io.Reader rather than an *os.File.
+type Client interface {
+	Reader
+	Submitter
+	Channels
+}
+
+var (
+	_ Client = (*websockets.Remote)(nil)
+	_ Client = (*rpc.Client)(nil)
+	_ Admin  = (*websockets.Remote)(nil)
+	_ Admin  = (*rpc.Client)(nil)
+)
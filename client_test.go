@@ -0,0 +1,21 @@
+package ripple
+
+import (
+	"github.com/kr-jaydeepp/ripple/deposits"
+	"github.com/kr-jaydeepp/ripple/testharness"
+	"github.com/kr-jaydeepp/ripple/withdrawals"
+)
+
+// Each package takes no more than the roles it needs
+var (
+	_ deposits.Client    = TransactionReader(nil)
+	_ withdrawals.Client = (interface {
+		Reader
+		Submitter
+	})(nil)
+	_ testharness.Client = (interface {
+		Reader
+		Submitter
+		Admin
+	})(nil)
+)
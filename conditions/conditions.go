@@ -0,0 +1,242 @@
+// Package conditions implements the PREIMAGE-SHA-256 crypto-conditions of
+// draft-thomas-crypto-conditions-04, which are the only type escrows
+// accept. A condition is the hash of a secret preimage, and the
+// fulfillment which releases the escrow is the preimage itself.
+package conditions
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// The limits rippled puts on the sizes of serialised fulfillments and
+// conditions
+const (
+	MaxFulfillmentSize = 256
+	MaxConditionSize   = 128
+)
+
+// The DER tags of the only supported type, and of the fields of its
+// fulfillment and condition
+const (
+	tagPreimageSha256 = 0xA0
+	tagPreimage       = 0x80
+	tagFingerprint    = 0x80
+	tagCost           = 0x81
+)
+
+// Fulfillment is a PREIMAGE-SHA-256 fulfillment
+type Fulfillment struct {
+	Preimage []byte
+}
+
+// Condition is the condition a fulfillment meets. Cost is the length of
+// the preimage.
+type Condition struct {
+	Fingerprint [sha256.Size]byte
+	Cost        uint64
+}
+
+// NewFulfillment returns the fulfillment of preimage
+func NewFulfillment(preimage []byte) (*Fulfillment, error) {
+	f := &Fulfillment{Preimage: preimage}
+	if size := len(f.Bytes()); size > MaxFulfillmentSize {
+		return nil, fmt.Errorf("Fulfillment too large: %d bytes", size)
+	}
+	return f, nil
+}
+
+// GenerateFulfillment returns the fulfillment of 32 random bytes
+func GenerateFulfillment() (*Fulfillment, error) {
+	preimage := make([]byte, 32)
+	if _, err := rand.Read(preimage); err != nil {
+		return nil, err
+	}
+	return NewFulfillment(preimage)
+}
+
+// Bytes returns the fulfillment as the Fulfillment field of EscrowFinish
+func (f *Fulfillment) Bytes() []byte {
+	return encodeTLV(tagPreimageSha256, encodeTLV(tagPreimage, f.Preimage))
+}
+
+func (f *Fulfillment) String() string {
+	return strings.ToUpper(hex.EncodeToString(f.Bytes()))
+}
+
+// Condition returns the condition the fulfillment meets
+func (f *Fulfillment) Condition() *Condition {
+	return &Condition{
+		Fingerprint: sha256.Sum256(f.Preimage),
+		Cost:        uint64(len(f.Preimage)),
+	}
+}
+
+// Bytes returns the condition as the Condition field of EscrowCreate
+// and EscrowFinish
+func (c *Condition) Bytes() []byte {
+	fields := append(encodeTLV(tagFingerprint, c.Fingerprint[:]), encodeTLV(tagCost, encodeCost(c.Cost))...)
+	return encodeTLV(tagPreimageSha256, fields)
+}
+
+func (c *Condition) String() string {
+	return strings.ToUpper(hex.EncodeToString(c.Bytes()))
+}
+
+// ParseFulfillment decodes a serialised fulfillment
+func ParseFulfillment(b []byte) (*Fulfillment, error) {
+	if len(b) > MaxFulfillmentSize {
+		return nil, fmt.Errorf("Fulfillment too large: %d bytes", len(b))
+	}
+	if len(b) > 0 && b[0] != tagPreimageSha256 {
+		return nil, fmt.Errorf("Unsupported fulfillment type: %02X", b[0])
+	}
+	body, err := decodeOnly(b, tagPreimageSha256, "fulfillment")
+	if err != nil {
+		return nil, err
+	}
+	preimage, err := decodeOnly(body, tagPreimage, "preimage")
+	if err != nil {
+		return nil, err
+	}
+	return &Fulfillment{Preimage: preimage}, nil
+}
+
+// ParseCondition decodes a serialised condition
+func ParseCondition(b []byte) (*Condition, error) {
+	if len(b) > MaxConditionSize {
+		return nil, fmt.Errorf("Condition too large: %d bytes", len(b))
+	}
+	if len(b) > 0 && b[0] != tagPreimageSha256 {
+		return nil, fmt.Errorf("Unsupported condition type: %02X", b[0])
+	}
+	body, err := decodeOnly(b, tagPreimageSha256, "condition")
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, rest, err := decodeTLV(body, tagFingerprint, "fingerprint")
+	if err != nil {
+		return nil, err
+	}
+	if len(fingerprint) != sha256.Size {
+		return nil, fmt.Errorf("Bad fingerprint length: %d", len(fingerprint))
+	}
+	cost, err := decodeOnly(rest, tagCost, "cost")
+	if err != nil {
+		return nil, err
+	}
+	c := &Condition{}
+	copy(c.Fingerprint[:], fingerprint)
+	if c.Cost, err = decodeCost(cost); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate checks that the serialised fulfillment meets the serialised
+// condition, as rippled does before it finishes an escrow
+func Validate(fulfillment, condition []byte) error {
+	f, err := ParseFulfillment(fulfillment)
+	if err != nil {
+		return err
+	}
+	c, err := ParseCondition(condition)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(f.Condition().Bytes(), c.Bytes()) {
+		return fmt.Errorf("Fulfillment does not meet condition %s", c)
+	}
+	return nil
+}
+
+// FinishFee returns the fee of an EscrowFinish with the serialised
+// fulfillment, which is the base fee times 33 plus one for every 16
+// bytes of the fulfillment
+func FinishFee(base data.Value, fulfillment []byte) (*data.Value, error) {
+	factor, err := data.NewNativeValue(33 + int64(len(fulfillment))/16)
+	if err != nil {
+		return nil, err
+	}
+	return base.Multiply(*factor)
+}
+
+func encodeTLV(tag byte, value []byte) []byte {
+	b := []byte{tag}
+	switch n := len(value); {
+	case n < 0x80:
+		b = append(b, byte(n))
+	case n <= 0xFF:
+		b = append(b, 0x81, byte(n))
+	default:
+		b = append(b, 0x82, byte(n>>8), byte(n))
+	}
+	return append(b, value...)
+}
+
+// decodeTLV returns the value of the field with tag at the start of b,
+// and what follows it
+func decodeTLV(b []byte, tag byte, name string) ([]byte, []byte, error) {
+	if len(b) < 2 {
+		return nil, nil, fmt.Errorf("Truncated %s", name)
+	}
+	if b[0] != tag {
+		return nil, nil, fmt.Errorf("Bad %s tag: %02X", name, b[0])
+	}
+	n, b := int(b[1]), b[2:]
+	switch {
+	case n == 0x81 && len(b) >= 1 && b[0] >= 0x80:
+		n, b = int(b[0]), b[1:]
+	case n == 0x82 && len(b) >= 2 && b[0] != 0:
+		n, b = int(b[0])<<8|int(b[1]), b[2:]
+	case n >= 0x80:
+		return nil, nil, fmt.Errorf("Bad %s length", name)
+	}
+	if n > len(b) {
+		return nil, nil, fmt.Errorf("Truncated %s", name)
+	}
+	return b[:n], b[n:], nil
+}
+
+// decodeOnly is like decodeTLV but nothing may follow the field
+func decodeOnly(b []byte, tag byte, name string) ([]byte, error) {
+	value, rest, err := decodeTLV(b, tag, name)
+	if err == nil && len(rest) > 0 {
+		err = fmt.Errorf("Trailing bytes after %s", name)
+	}
+	return value, err
+}
+
+// The cost is a DER integer, so has a leading zero when its top bit is set
+func encodeCost(cost uint64) []byte {
+	var b []byte
+	for ; cost > 0; cost >>= 8 {
+		b = append([]byte{byte(cost)}, b...)
+	}
+	if len(b) == 0 || b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return b
+}
+
+func decodeCost(b []byte) (uint64, error) {
+	switch {
+	case len(b) == 0 || len(b) > 9 || len(b) == 9 && b[0] != 0:
+		return 0, fmt.Errorf("Bad cost length: %d", len(b))
+	case b[0]&0x80 != 0:
+		return 0, fmt.Errorf("Negative cost")
+	case len(b) > 1 && b[0] == 0 && b[1]&0x80 == 0:
+		return 0, fmt.Errorf("Cost is not minimally encoded")
+	}
+	var cost uint64
+	for _, c := range b {
+		cost = cost<<8 | uint64(c)
+	}
+	return cost, nil
+}
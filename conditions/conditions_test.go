@@ -0,0 +1,118 @@
+package conditions
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type ConditionsSuite struct{}
+
+var _ = Suite(&ConditionsSuite{})
+
+func h2b(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (s *ConditionsSuite) TestPreimageSha256(c *C) {
+	for _, test := range []struct {
+		preimage    string
+		fulfillment string
+		condition   string
+	}{
+		// From the examples of the draft
+		{"", "A0028000", "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100"},
+		{"616161", "A0058003616161", "A02580209834876DCFB05CB167A5C24953EBA58C4AC89B1ADF57F28F2F9D09AF107EE8F0810103"},
+	} {
+		f, err := NewFulfillment(h2b(test.preimage))
+		c.Assert(err, IsNil)
+		c.Check(f.String(), Equals, test.fulfillment)
+		c.Check(f.Condition().String(), Equals, test.condition)
+		c.Check(Validate(h2b(test.fulfillment), h2b(test.condition)), IsNil)
+
+		parsed, err := ParseFulfillment(h2b(test.fulfillment))
+		c.Assert(err, IsNil)
+		c.Check(parsed.String(), Equals, test.fulfillment)
+		condition, err := ParseCondition(h2b(test.condition))
+		c.Assert(err, IsNil)
+		c.Check(condition.Cost, Equals, uint64(len(test.preimage)/2))
+	}
+}
+
+func (s *ConditionsSuite) TestGenerate(c *C) {
+	f, err := GenerateFulfillment()
+	c.Assert(err, IsNil)
+	c.Check(f.Preimage, HasLen, 32)
+	c.Check(len(f.Bytes()), Equals, 36)
+	c.Check(f.Condition().String(), Matches, "A0258020[0-9A-F]{64}810120")
+	c.Check(Validate(f.Bytes(), f.Condition().Bytes()), IsNil)
+
+	other, err := GenerateFulfillment()
+	c.Assert(err, IsNil)
+	c.Check(Validate(other.Bytes(), f.Condition().Bytes()), ErrorMatches, "Fulfillment does not meet condition A025.*")
+
+	// Long preimages need long form lengths and costs with a leading zero
+	f, err = NewFulfillment(make([]byte, 200))
+	c.Assert(err, IsNil)
+	c.Check(f.String(), Matches, "A081CB8081C80+")
+	c.Check(f.Condition().String(), Matches, "A0268020[0-9A-F]{64}810200C8")
+	c.Check(Validate(f.Bytes(), f.Condition().Bytes()), IsNil)
+	_, err = NewFulfillment(make([]byte, 250))
+	c.Check(err, IsNil)
+	_, err = NewFulfillment(make([]byte, 251))
+	c.Check(err, ErrorMatches, "Fulfillment too large: 257 bytes")
+}
+
+func (s *ConditionsSuite) TestBadEncodings(c *C) {
+	for _, test := range []struct {
+		fulfillment, condition, err string
+	}{
+		{"A0058003616161", "A3258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100", "Unsupported condition type: A3"},
+		{"A40580036161", "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100", "Unsupported fulfillment type: A4"},
+		{"A006800361616161", "", "Trailing bytes after preimage"},
+		{"A005800361616161", "", "Trailing bytes after fulfillment"},
+		{"A0068003616161", "", "Truncated fulfillment"},
+		{"A00480036161", "", "Truncated preimage"},
+		{"A0058103616161", "", "Bad preimage tag: 81"},
+		{"A0028000", "A0248020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B8558100", "Bad cost length: 0"},
+		{"A0028000", "A0268020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B85581020000", "Cost is not minimally encoded"},
+		{"A0028000", "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810180", "Negative cost"},
+		{"A0028000", "A0268020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810101", "Truncated condition"},
+	} {
+		condition := test.condition
+		if condition == "" {
+			condition = "A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100"
+		}
+		c.Check(Validate(h2b(test.fulfillment), h2b(condition)), ErrorMatches, test.err, Commentf(test.fulfillment))
+	}
+	c.Check(Validate(h2b("A0028000"), h2b("A0"+strings.Repeat("00", 128))), ErrorMatches, "Condition too large: 129 bytes")
+}
+
+func (s *ConditionsSuite) TestFinishFee(c *C) {
+	base, err := data.NewNativeValue(10)
+	c.Assert(err, IsNil)
+	f, err := GenerateFulfillment()
+	c.Assert(err, IsNil)
+	for _, test := range []struct {
+		fulfillment []byte
+		drops       int64
+	}{
+		{f.Bytes(), 350}, // 10 * (33 + 36/16)
+		{nil, 330},
+	} {
+		fee, err := FinishFee(*base, test.fulfillment)
+		c.Assert(err, IsNil)
+		expected, err := data.NewNativeValue(test.drops)
+		c.Assert(err, IsNil)
+		c.Check(fee.Equals(*expected), Equals, true, Commentf("%s", fee))
+	}
+}
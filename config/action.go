@@ -7,6 +7,7 @@ import (
 	"io"
 
 	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/sign"
 	"github.com/kr-jaydeepp/ripple/websockets"
 )
 
@@ -68,14 +69,14 @@ func (s ActionSlice) each(f actionFunc) error {
 func (s ActionSlice) Prepare() error {
 	var prepare = func(seed data.Seed, fee data.Value, keyType data.KeyType, tx data.Transaction, txType data.TransactionType) error {
 		var (
-			sequence uint32
-			key      = seed.Key(keyType)
-			base     = tx.GetBase()
+			key  = sign.NewKeyFromSeed(seed, keyType)
+			base = tx.GetBase()
 		)
 		base.TransactionType = txType
 		base.Fee = fee
-		base.Account = seed.AccountId(keyType, &sequence)
-		return data.Sign(tx, key, &sequence)
+		base.Account = key.Account()
+		_, err := sign.Transaction(tx, key)
+		return err
 	}
 	return s.each(prepare)
 }
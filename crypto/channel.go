@@ -0,0 +1,41 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The prefix rippled puts before a payment channel claim, "CLM\0"
+var channelClaimPrefix = []byte{'C', 'L', 'M', 0}
+
+// ChannelClaim serialises a claim of drops from a payment channel as it
+// is signed: the prefix, the channel ID and the big-endian drops
+func ChannelClaim(channel []byte, drops uint64) ([]byte, error) {
+	if len(channel) != 32 {
+		return nil, fmt.Errorf("Wrong channel length: %d", len(channel))
+	}
+	msg := make([]byte, 44)
+	copy(msg, channelClaimPrefix)
+	copy(msg[4:], channel)
+	binary.BigEndian.PutUint64(msg[36:], drops)
+	return msg, nil
+}
+
+// SignChannelClaim authorizes the redemption of drops from a payment
+// channel, as channel_authorize does, without contacting rippled.
+func SignChannelClaim(key Key, sequence *uint32, channel []byte, drops uint64) ([]byte, error) {
+	msg, err := ChannelClaim(channel, drops)
+	if err != nil {
+		return nil, err
+	}
+	return Sign(key.Private(sequence), Sha512Half(msg), msg)
+}
+
+// VerifyChannelClaim is the offline equivalent of channel_verify
+func VerifyChannelClaim(publicKey, channel []byte, drops uint64, signature []byte) (bool, error) {
+	msg, err := ChannelClaim(channel, drops)
+	if err != nil {
+		return false, err
+	}
+	return Verify(publicKey, Sha512Half(msg), msg, signature)
+}
@@ -0,0 +1,38 @@
+package crypto
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type ChannelSuite struct{}
+
+var _ = Suite(&ChannelSuite{})
+
+func (s *ChannelSuite) TestChannelClaim(c *C) {
+	channel := h2b("5DB01B7FFED6B67E6B0414DED11E051D2EE2B7619CE0EAA6286D67A3A4D5BDB3")
+	var sequenceZero uint32
+	ecdsa, err := NewECDSAKey(h2b("71ED064155FFADFA38782C5E0158CB26"))
+	c.Assert(err, IsNil)
+	ed, err := NewEd25519Key(h2b("71ED064155FFADFA38782C5E0158CB26"))
+	c.Assert(err, IsNil)
+
+	for _, k := range []struct {
+		key      Key
+		sequence *uint32
+	}{{ecdsa, &sequenceZero}, {ed, nil}} {
+		sig, err := SignChannelClaim(k.key, k.sequence, channel, 1000000)
+		c.Assert(err, IsNil)
+		public := k.key.Public(k.sequence)
+
+		ok, err := VerifyChannelClaim(public, channel, 1000000, sig)
+		c.Assert(err, IsNil)
+		c.Check(ok, Equals, true)
+
+		ok, err = VerifyChannelClaim(public, channel, 1000001, sig)
+		c.Assert(err, IsNil)
+		c.Check(ok, Equals, false)
+	}
+
+	_, err = SignChannelClaim(ed, nil, channel[1:], 1)
+	c.Assert(err, ErrorMatches, "Wrong channel length: 31")
+}
@@ -60,9 +60,9 @@ func (k *ecdsaKey) Id(sequence *uint32) []byte {
 
 func (k *ecdsaKey) Private(sequence *uint32) []byte {
 	if sequence == nil {
-		return k.D.Bytes()
+		return k.D.FillBytes(make([]byte, btcec.PrivKeyBytesLen))
 	}
-	return k.generateKey(*sequence).D.Bytes()
+	return k.generateKey(*sequence).D.FillBytes(make([]byte, btcec.PrivKeyBytesLen))
 }
 
 func (k *ecdsaKey) Public(sequence *uint32) []byte {
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 )
@@ -84,6 +85,38 @@ func GenerateFamilySeed(password string) (Hash, error) {
 	return NewFamilySeed(Sha512Quarter([]byte(password)))
 }
 
+// Family seeds for Ed25519 keys have a three byte version, so that they
+// are written as "sEd..."
+var ed25519SeedVersion = []byte{0x01, 0xE1, 0x4B}
+
+// Ed25519FamilySeed encodes b as a family seed for an Ed25519 key
+func Ed25519FamilySeed(b []byte) (string, error) {
+	n := hashTypes[RIPPLE_FAMILY_SEED].Payload
+	if len(b) != n {
+		return "", fmt.Errorf("Seed is wrong size, expected: %d got: %d", n, len(b))
+	}
+	return Base58Encode(append(append([]byte(nil), ed25519SeedVersion...), b...), ALPHABET), nil
+}
+
+// DecodeFamilySeed returns the payload of a family seed of either kind,
+// and whether it is for an Ed25519 key
+func DecodeFamilySeed(s string) ([]byte, bool, error) {
+	decoded, err := Base58Decode(s, ALPHABET)
+	if err != nil {
+		return nil, false, err
+	}
+	decoded = decoded[:len(decoded)-4]
+	n := hashTypes[RIPPLE_FAMILY_SEED].Payload
+	switch {
+	case len(decoded) == len(ed25519SeedVersion)+n && bytes.HasPrefix(decoded, ed25519SeedVersion):
+		return decoded[len(ed25519SeedVersion):], true, nil
+	case len(decoded) == 1+n && HashVersion(decoded[0]) == RIPPLE_FAMILY_SEED:
+		return decoded[1:], false, nil
+	default:
+		return nil, false, fmt.Errorf("Not a family seed: %s", s)
+	}
+}
+
 func newHash(b []byte, version HashVersion) (Hash, error) {
 	n := hashTypes[version].Payload
 	if len(b) > n {
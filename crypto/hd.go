@@ -0,0 +1,127 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Hierarchical deterministic keys, derived from a BIP39 seed as BIP32
+// describes for secp256k1 and SLIP-0010 for Ed25519. Unlike family
+// keys, these are used with a nil sequence.
+
+// Added to an index in a path to make it hardened, written as 0'
+const HardenedIndex uint32 = 0x80000000
+
+// The path of the first account of a wallet, as used by XUMM and Ledger
+const DefaultHDPath = "m/44'/144'/0'/0/0"
+
+// HDPath returns the standard path of the key at index of account
+func HDPath(account, index uint32) string {
+	return fmt.Sprintf("m/44'/144'/%d'/0/%d", account, index)
+}
+
+// MnemonicSeed returns the BIP39 seed of a mnemonic and passphrase.
+// Both are used as given, without Unicode normalisation, so a mnemonic
+// in a language other than English should be NFKD normalised first.
+func MnemonicSeed(mnemonic, passphrase string) []byte {
+	words := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(words), []byte("mnemonic"+passphrase), 2048, 64, sha512.New)
+}
+
+// DeriveECDSAKey derives the secp256k1 key at path from a BIP39 seed
+func DeriveECDSAKey(seed []byte, path string) (Key, error) {
+	indexes, err := ParseHDPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key, chain := hmacSHA512([]byte("Bitcoin seed"), seed)
+	k := new(big.Int).SetBytes(key)
+	if k.Sign() == 0 || k.Cmp(order) >= 0 {
+		return nil, fmt.Errorf("Invalid master key")
+	}
+	for _, index := range indexes {
+		var data []byte
+		if index >= HardenedIndex {
+			data = append([]byte{0}, k.FillBytes(make([]byte, 32))...)
+		} else {
+			_, public := btcec.PrivKeyFromBytes(btcec.S256(), k.FillBytes(make([]byte, 32)))
+			data = public.SerializeCompressed()
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+		var tweak []byte
+		tweak, chain = hmacSHA512(chain, data)
+		t := new(big.Int).SetBytes(tweak)
+		if t.Cmp(order) >= 0 {
+			return nil, fmt.Errorf("Invalid key at index %d of %s", index, path)
+		}
+		k.Add(k, t).Mod(k, order)
+		if k.Sign() == 0 {
+			return nil, fmt.Errorf("Invalid key at index %d of %s", index, path)
+		}
+	}
+	private, _ := btcec.PrivKeyFromBytes(btcec.S256(), k.FillBytes(make([]byte, 32)))
+	return &ecdsaKey{private}, nil
+}
+
+// DeriveEd25519Key derives the Ed25519 key at path from a BIP39 seed.
+// Every index of the path must be hardened.
+func DeriveEd25519Key(seed []byte, path string) (Key, error) {
+	indexes, err := ParseHDPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key, chain := hmacSHA512([]byte("ed25519 seed"), seed)
+	for _, index := range indexes {
+		if index < HardenedIndex {
+			return nil, fmt.Errorf("Ed25519 keys only have hardened children: %s", path)
+		}
+		data := append([]byte{0}, key...)
+		data = binary.BigEndian.AppendUint32(data, index)
+		key, chain = hmacSHA512(chain, data)
+	}
+	var e ed25519key
+	copy(e.priv[:], ed25519.NewKeyFromSeed(key))
+	return &e, nil
+}
+
+// ParseHDPath returns the indexes of a path such as m/44'/144'/0'/0/0,
+// where a hardened index is marked with ' or H
+func ParseHDPath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("Path must begin with m: %s", path)
+	}
+	var indexes []uint32
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "H")
+		if hardened {
+			part = part[:len(part)-1]
+		}
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(index) >= HardenedIndex {
+			return nil, fmt.Errorf("Bad index in path: %s", path)
+		}
+		if hardened {
+			index += uint64(HardenedIndex)
+		}
+		indexes = append(indexes, uint32(index))
+	}
+	return indexes, nil
+}
+
+// hmacSHA512 returns the halves of the HMAC, which are the key and chain code
+func hmacSHA512(key, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
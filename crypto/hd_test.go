@@ -0,0 +1,52 @@
+package crypto
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type HDSuite struct{}
+
+var _ = Suite(&HDSuite{})
+
+const abandon = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func (s *HDSuite) TestMnemonicSeed(c *C) {
+	c.Check(b2h(MnemonicSeed(abandon, "TREZOR")), Equals, "C55257C360C07C72029AEBC1B53C05ED0362ADA38EAD3E3E9EFA3708E53495531F09A6987599D18264C1E1C92F2CF141630C7A3C4AB7C81B2F001698E7463B04")
+}
+
+var hdTests = []struct {
+	path    string
+	ecdsa   string
+	ed25519 string
+}{
+	{"m", "E8F32E723DECF4051AEFAC8E2C93C9C5B214313817CDB01A1494B917C8436B35", "2B4BE7F19EE27BBF30C667B642D5F4AA69FD169872F8FC3059C08EBAE2EB19E7"},
+	{"m/0'", "EDB2E14F9EE77D26DD93B4ECEDE8D16ED408CE149B6CD80B0715A2D911A0AFEA", "68E0FE46DFB67E368C75379ACEC591DAD19DF3CDE26E63B93A8E704F1DADE7A3"},
+	{"m/0H/1", "3C6CB8D0F6A264C91EA8B5030FADAA8E538B020F0A387421A12DE9319DC93368", ""},
+}
+
+func (s *HDSuite) TestDerive(c *C) {
+	seed := h2b("000102030405060708090A0B0C0D0E0F")
+	for _, test := range hdTests {
+		key, err := DeriveECDSAKey(seed, test.path)
+		c.Assert(err, IsNil)
+		c.Check(b2h(key.Private(nil)), Equals, test.ecdsa)
+		ed, err := DeriveEd25519Key(seed, test.path)
+		if test.ed25519 == "" {
+			c.Check(err, ErrorMatches, "Ed25519 keys only have hardened children: .*")
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Check(b2h(ed.Private(nil)[:32]), Equals, test.ed25519)
+	}
+	for _, path := range []string{"", "44'/144'", "m/x", "m/2147483648"} {
+		_, err := DeriveECDSAKey(seed, path)
+		c.Check(err, NotNil, Commentf(path))
+	}
+}
+
+func (s *HDSuite) TestXRPLPath(c *C) {
+	c.Check(HDPath(0, 0), Equals, DefaultHDPath)
+	key, err := DeriveECDSAKey(MnemonicSeed(abandon, ""), DefaultHDPath)
+	c.Assert(err, IsNil)
+	c.Check(b2h(key.Public(nil)), Equals, "031D68BC1A142E6766B2BDFB006CCFE135EF2E0E2E94ABB5CF5C9AB6104776FBAE")
+}
@@ -0,0 +1,92 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+var bip39Indexes = func() map[string]int {
+	indexes := make(map[string]int, len(bip39Words))
+	for i, word := range bip39Words {
+		indexes[word] = i
+	}
+	return indexes
+}()
+
+// NewMnemonic returns the English BIP39 mnemonic of entropy, which must be
+// 16 to 32 bytes in steps of 4
+func NewMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", fmt.Errorf("Entropy is wrong size: %d", len(entropy))
+	}
+	// The checksum is at most 8 bits, so fits in the first byte of the hash
+	checksum := sha256.Sum256(entropy)
+	data := append(append([]byte(nil), entropy...), checksum[0])
+	words := make([]string, (len(entropy)*8+len(entropy)/4)/11)
+	for i := range words {
+		index := 0
+		for bit := i * 11; bit < (i+1)*11; bit++ {
+			index = index<<1 | int(data[bit/8]>>(7-uint(bit%8))&1)
+		}
+		words[i] = bip39Words[index]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicEntropy returns the entropy of an English BIP39 mnemonic,
+// after checking its words and checksum
+func MnemonicEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("Mnemonic must have 12, 15, 18, 21 or 24 words, not %d", len(words))
+	}
+	data := make([]byte, (len(words)*11+7)/8)
+	for i, word := range words {
+		index, ok := bip39Indexes[strings.ToLower(word)]
+		if !ok {
+			return nil, fmt.Errorf("Unknown word in mnemonic: %s", word)
+		}
+		for bit := 0; bit < 11; bit++ {
+			if index&(1<<uint(10-bit)) != 0 {
+				n := i*11 + bit
+				data[n/8] |= 1 << (7 - uint(n%8))
+			}
+		}
+	}
+	n := len(words) * 4 / 3
+	entropy, bits := data[:n], uint(n/4)
+	checksum := sha256.Sum256(entropy)
+	if checksum[0]>>(8-bits) != data[n]>>(8-bits) {
+		return nil, fmt.Errorf("Bad mnemonic checksum")
+	}
+	return entropy, nil
+}
+
+// FamilySeedMnemonic returns the 12 word mnemonic whose entropy is the
+// seed of secret. The keys of secret are still those of the family seed,
+// and differ from the HD keys of the mnemonic.
+func FamilySeedMnemonic(secret string) (string, error) {
+	seed, _, err := DecodeFamilySeed(secret)
+	if err != nil {
+		return "", err
+	}
+	return NewMnemonic(seed)
+}
+
+// MnemonicFamilySeed returns the family seed, as an "sEd..." secret if
+// ed25519 is set, whose seed is the entropy of a 12 word mnemonic
+func MnemonicFamilySeed(mnemonic string, ed25519 bool) (string, error) {
+	entropy, err := MnemonicEntropy(mnemonic)
+	if err != nil {
+		return "", err
+	}
+	if ed25519 {
+		return Ed25519FamilySeed(entropy)
+	}
+	seed, err := NewFamilySeed(entropy)
+	if err != nil {
+		return "", err
+	}
+	return seed.String(), nil
+}
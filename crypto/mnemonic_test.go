@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type MnemonicSuite struct{}
+
+var _ = Suite(&MnemonicSuite{})
+
+var mnemonicTests = []struct {
+	entropy  string
+	mnemonic string
+}{
+	{"00000000000000000000000000000000", abandon},
+	{"7F7F7F7F7F7F7F7F7F7F7F7F7F7F7F7F", "legal winner thank year wave sausage worth useful legal winner thank yellow"},
+	{"80808080808080808080808080808080", "letter advice cage absurd amount doctor acoustic avoid letter advice cage above"},
+	{"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong"},
+	{"0000000000000000000000000000000000000000000000000000000000000000", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"},
+}
+
+func (s *MnemonicSuite) TestMnemonic(c *C) {
+	c.Assert(bip39Words, HasLen, 2048)
+	for _, test := range mnemonicTests {
+		mnemonic, err := NewMnemonic(h2b(test.entropy))
+		c.Assert(err, IsNil)
+		c.Check(mnemonic, Equals, test.mnemonic)
+		entropy, err := MnemonicEntropy(test.mnemonic)
+		c.Assert(err, IsNil)
+		c.Check(b2h(entropy), Equals, test.entropy)
+	}
+	_, err := NewMnemonic(make([]byte, 15))
+	c.Check(err, ErrorMatches, "Entropy is wrong size: 15")
+	_, err = MnemonicEntropy("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon")
+	c.Check(err, ErrorMatches, "Bad mnemonic checksum")
+	_, err = MnemonicEntropy("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon xrpl")
+	c.Check(err, ErrorMatches, "Unknown word in mnemonic: xrpl")
+	_, err = MnemonicEntropy("abandon about")
+	c.Check(err, ErrorMatches, "Mnemonic must have .*, not 2")
+}
+
+func (s *MnemonicSuite) TestFamilySeed(c *C) {
+	for _, secret := range []string{"snoPBrXtMeMyMHUVTgbuqAfg1SUTb", "sEdVQ4wvD1AaTG6JA54qt38TengAuiz"} {
+		mnemonic, err := FamilySeedMnemonic(secret)
+		c.Assert(err, IsNil)
+		_, ed25519, err := DecodeFamilySeed(secret)
+		c.Assert(err, IsNil)
+		back, err := MnemonicFamilySeed(mnemonic, ed25519)
+		c.Assert(err, IsNil)
+		c.Check(back, Equals, secret)
+	}
+	_, err := FamilySeedMnemonic("snoPBrXtMeMyMHUVTgbuqAfg1SUTc")
+	c.Check(err, ErrorMatches, "Bad Base58 checksum: .*")
+}
@@ -0,0 +1,106 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+type AMMSuite struct{}
+
+var _ = Suite(&AMMSuite{})
+
+func (s *AMMSuite) TestAMMEntry(c *C) {
+	b, err := ioutil.ReadFile("testdata/amm.json")
+	c.Assert(err, IsNil)
+	var entries LedgerEntrySlice
+	c.Assert(json.Unmarshal(b, &entries), IsNil)
+	c.Assert(entries, HasLen, 1)
+	amm, ok := entries[0].(*AMMEntry)
+	c.Assert(ok, Equals, true)
+	c.Assert(amm.Asset.IsNative(), Equals, true)
+	c.Assert(*amm.Asset2, Equals, Asset{Currency: "TST", Issuer: "rP9jPyP5kyvFRb6ZiRghAGw5u8SGAmU4bd"})
+	c.Assert(*amm.TradingFee, Equals, uint16(600))
+	c.Assert(amm.VoteSlots, HasLen, 1)
+	c.Assert(amm.VoteSlots[0].VoteEntry.VoteWeight, Equals, uint32(100000))
+
+	// Round trip through the binary format. The unexported leBase
+	// fields are not encoded, so the type is written by hand.
+	var buf bytes.Buffer
+	c.Assert(encode(&buf, amm, false), IsNil)
+	encoded := append([]byte(nil), buf.Bytes()...)
+	node := append([]byte{0x11, 0x00, byte(AMM)}, encoded...)
+	node = append(node, amm.LedgerIndex[:]...)
+	le, err := ReadLedgerEntry(bytes.NewReader(node), *amm.LedgerIndex)
+	c.Assert(err, IsNil)
+	decoded, ok := le.(*AMMEntry)
+	c.Assert(ok, Equals, true)
+	c.Assert(decoded.Account.String(), Equals, "rE54zDvgnghAoPopCgvtiqWNq3dU5y836S")
+	c.Assert(*decoded.Asset, Equals, *amm.Asset)
+	c.Assert(*decoded.Asset2, Equals, *amm.Asset2)
+	c.Assert(decoded.LPTokenBalance.String(), Equals, amm.LPTokenBalance.String())
+	c.Assert(decoded.AuctionSlot, NotNil)
+	c.Assert(decoded.AuctionSlot.AuthAccounts, HasLen, 2)
+	c.Assert(decoded.AuctionSlot.AuthAccounts[1].AuthAccount.Account.String(), Equals, "rBepJuTLFJt3WmtLXYAxSjtBWAeQxVbncv")
+	c.Assert(*decoded.AuctionSlot.DiscountedFee, Equals, uint16(60))
+	c.Assert(decoded.AuctionSlot.Price.String(), Equals, amm.AuctionSlot.Price.String())
+	c.Assert(decoded.VoteSlots, DeepEquals, amm.VoteSlots)
+
+	buf.Reset()
+	c.Assert(encode(&buf, decoded, false), IsNil)
+	c.Assert(buf.Bytes(), DeepEquals, encoded)
+}
+
+const ammAccount = `"Account":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","Fee":"10","Sequence":5`
+
+const ammAssets = `"Asset":{"currency":"XRP"},"Asset2":{"currency":"TST","issuer":"rP9jPyP5kyvFRb6ZiRghAGw5u8SGAmU4bd"}`
+
+var ammTransactions = []string{
+	`{"TransactionType":"AMMCreate",` + ammAccount + `,"Amount":"20000000","Amount2":{"currency":"TST","issuer":"rP9jPyP5kyvFRb6ZiRghAGw5u8SGAmU4bd","value":"25"},"TradingFee":500}`,
+	`{"TransactionType":"AMMDeposit",` + ammAccount + `,"Flags":1048576,` + ammAssets + `,"Amount":"1000000","Amount2":{"currency":"TST","issuer":"rP9jPyP5kyvFRb6ZiRghAGw5u8SGAmU4bd","value":"1.25"}}`,
+	`{"TransactionType":"AMMWithdraw",` + ammAccount + `,"Flags":65536,` + ammAssets + `,"LPTokenIn":{"currency":"039C99CD9AB0B70B32ECDA51EAAE471625608EA2","issuer":"rE54zDvgnghAoPopCgvtiqWNq3dU5y836S","value":"100"}}`,
+	`{"TransactionType":"AMMVote",` + ammAccount + `,` + ammAssets + `,"TradingFee":600}`,
+	`{"TransactionType":"AMMBid",` + ammAccount + `,` + ammAssets + `,"BidMax":{"currency":"039C99CD9AB0B70B32ECDA51EAAE471625608EA2","issuer":"rE54zDvgnghAoPopCgvtiqWNq3dU5y836S","value":"100"},"AuthAccounts":[{"AuthAccount":{"Account":"rMKXGCbJ5d8LbrqthdG46q3f969MVK2Qeg"}}]}`,
+	`{"TransactionType":"AMMDelete",` + ammAccount + `,` + ammAssets + `}`,
+}
+
+func (s *AMMSuite) TestAMMTransactions(c *C) {
+	for _, test := range ammTransactions {
+		var txm TransactionWithMetaData
+		c.Assert(json.Unmarshal([]byte(test), &txm), IsNil, Commentf(test))
+		tx := txm.Transaction
+		_, raw, err := Raw(tx)
+		c.Assert(err, IsNil, Commentf(test))
+		decoded, err := ReadTransaction(bytes.NewReader(raw))
+		c.Assert(err, IsNil, Commentf(test))
+		c.Check(decoded, DeepEquals, tx, Commentf(test))
+		_, again, err := Raw(decoded)
+		c.Assert(err, IsNil)
+		c.Check(again, DeepEquals, raw, Commentf(test))
+	}
+}
+
+func (s *AMMSuite) TestAMMFlags(c *C) {
+	var txm TransactionWithMetaData
+	c.Assert(json.Unmarshal([]byte(ammTransactions[2]), &txm), IsNil)
+	c.Check(txm.Transaction.GetBase().Flags.Explain(txm.Transaction), DeepEquals, []string{"LPToken"})
+	c.Check((TxTwoAssetIfEmpty | TxSingleAsset).Explain(txm.Transaction), DeepEquals, []string{"SingleAsset"})
+}
+
+func (s *AMMSuite) TestGetAMMIndex(c *C) {
+	xrp := Asset{Currency: "XRP"}
+	tst := Asset{Currency: "TST", Issuer: "rP9jPyP5kyvFRb6ZiRghAGw5u8SGAmU4bd"}
+	index, err := GetAMMIndex(xrp, tst)
+	c.Assert(err, IsNil)
+	c.Check(index.String(), Equals, "97DD92D4F3A791254A530BA769F6669DEBF6B2FC8CCA46842B9031ADCD4D1ADA")
+	reversed, err := GetAMMIndex(tst, xrp)
+	c.Assert(err, IsNil)
+	c.Check(*reversed, Equals, *index)
+	other, err := GetAMMIndex(xrp, Asset{Currency: "TST", Issuer: "rE54zDvgnghAoPopCgvtiqWNq3dU5y836S"})
+	c.Assert(err, IsNil)
+	c.Check(*other, Not(Equals), *index)
+	_, err = GetAMMIndex(xrp, Asset{Currency: "TST", Issuer: "bad"})
+	c.Check(err, NotNil)
+}
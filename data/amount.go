@@ -17,6 +17,17 @@ type Amount struct {
 
 type ExchangeRate uint64
 
+// CurrencyMismatchError is returned by operations which need Amounts of
+// the same currency and issuer
+type CurrencyMismatchError struct {
+	Op   string
+	A, B Amount
+}
+
+func (e *CurrencyMismatchError) Error() string {
+	return fmt.Sprintf("Cannot %s %s and %s", e.Op, e.A.Machine(), e.B.Machine())
+}
+
 func newAmount(value *Value, currency Currency, issuer Account) *Amount {
 	return &Amount{
 		Value:    value,
@@ -114,7 +125,19 @@ func (a Amount) Abs() *Amount {
 	return clone
 }
 
+// SameAsset returns true if a and b are both XRP, or of the same
+// currency and issuer
+func (a Amount) SameAsset(b Amount) bool {
+	if a.IsNative() || b.IsNative() {
+		return a.IsNative() == b.IsNative()
+	}
+	return a.Currency == b.Currency && a.Issuer == b.Issuer
+}
+
 func (a Amount) Add(b *Amount) (*Amount, error) {
+	if !a.SameAsset(*b) {
+		return nil, &CurrencyMismatchError{"add", a, *b}
+	}
 	sum, err := a.Value.Add(*b.Value)
 	if err != nil {
 		return nil, err
@@ -123,9 +146,47 @@ func (a Amount) Add(b *Amount) (*Amount, error) {
 }
 
 func (a Amount) Subtract(b *Amount) (*Amount, error) {
+	if !a.SameAsset(*b) {
+		return nil, &CurrencyMismatchError{"subtract", a, *b}
+	}
 	return a.Add(b.Negate())
 }
 
+// Compare returns -1, 0 or +1 as a is less than, equal to or greater
+// than b, which must be of the same asset
+func (a Amount) Compare(b Amount) (int, error) {
+	if !a.SameAsset(b) {
+		return 0, &CurrencyMismatchError{"compare", a, b}
+	}
+	return a.Value.Compare(*b.Value), nil
+}
+
+// Min returns a copy of the lesser of a and b
+func (a Amount) Min(b Amount) (*Amount, error) {
+	cmp, err := a.Compare(b)
+	switch {
+	case err != nil:
+		return nil, err
+	case cmp > 0:
+		return b.Clone(), nil
+	default:
+		return a.Clone(), nil
+	}
+}
+
+// Max returns a copy of the greater of a and b
+func (a Amount) Max(b Amount) (*Amount, error) {
+	cmp, err := a.Compare(b)
+	switch {
+	case err != nil:
+		return nil, err
+	case cmp < 0:
+		return b.Clone(), nil
+	default:
+		return a.Clone(), nil
+	}
+}
+
 func (a Amount) multiply(b *Amount) (*Amount, error) {
 	product, err := a.Value.Multiply(*b.Value)
 	if err != nil {
@@ -240,23 +301,6 @@ func (a Amount) Asset() *Asset {
 	}
 }
 
-func NewExchangeRate(a, b *Amount) (ExchangeRate, error) {
-	if b.IsZero() {
-		return 0, nil
-	}
-	rate, err := a.Divide(b)
-	if err != nil {
-		return 0, err
-	}
-	if rate.IsZero() {
-		return 0, nil
-	}
-	if rate.offset >= -100 || rate.offset <= 155 {
-		panic("Impossible Rate")
-	}
-	return ExchangeRate(uint64(rate.offset+100)<<54 | uint64(rate.num)), nil
-}
-
 func (e *ExchangeRate) Bytes() []byte {
 	if e == nil {
 		return nil
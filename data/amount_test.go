@@ -107,6 +107,15 @@ var amountTests = TestSlice{
 	{equalCheck("1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL", "1"), Equals, false, "1 USD != 1 XRP"},
 	{equalCheck("1", "1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL"), Equals, false, "1 XRP != 1 USD"},
 	{ErrorCheck(amountCheck("1").Divide(amountCheck("0"))), ErrorMatches, "Division by zero", "Divide one by zero"},
+	{ErrorCheck(amountCheck("1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL").Add(amountCheck("1/USD/rH5aWQJ4R7v4Mpyf4kDBUvDFT5cbpFq3XP"))), ErrorMatches, "Cannot add 1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL and 1/USD/rH5aWQJ4R7v4Mpyf4kDBUvDFT5cbpFq3XP", "Add USD of different issuers"},
+	{ErrorCheck(amountCheck("1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL").Subtract(amountCheck("1/EUR/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL"))), FitsTypeOf, &CurrencyMismatchError{}, "Subtract EUR from USD"},
+	{ErrorCheck(amountCheck("1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL").Compare(*amountCheck("1"))), ErrorMatches, "Cannot compare .*", "Compare USD with XRP"},
+	{compareCheck("1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL", "2/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL"), Equals, -1, "1 USD < 2 USD"},
+	{compareCheck("2", "1"), Equals, 1, "2 XRP > 1 XRP"},
+	{compareCheck("-1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL", "-1.0/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL"), Equals, 0, "-1 USD == -1.0 USD"},
+	{minCheck("1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL", "-2/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL").String(), Equals, "-2/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL", "Min 1 USD -2 USD"},
+	{maxCheck("1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL", "-2/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL").String(), Equals, "1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL", "Max 1 USD -2 USD"},
+	{ErrorCheck(amountCheck("1").Max(*amountCheck("1/USD/rNDKeo9RrCiRdfsMG8AdoZvNZxHASGzbZL"))), ErrorMatches, "Cannot compare .*", "Max XRP USD"},
 	{amountCheck("-1/XRP").Abs().String(), Equals, "1/XRP", "Abs -1"},
 	// {ErrorCheck(NewAmount("xx")), ErrorMatches, "Bad amount:.*", "IsValid xx"},
 	{ErrorCheck(NewAmount(nil)), ErrorMatches, "Bad type:.*", "IsValid nil"},
@@ -152,6 +161,30 @@ func divCheck(a, b string) *Amount {
 	}
 }
 
+func compareCheck(a, b string) int {
+	if cmp, err := amountCheck(a).Compare(*amountCheck(b)); err != nil {
+		panic(err)
+	} else {
+		return cmp
+	}
+}
+
+func minCheck(a, b string) *Amount {
+	if min, err := amountCheck(a).Min(*amountCheck(b)); err != nil {
+		panic(err)
+	} else {
+		return min
+	}
+}
+
+func maxCheck(a, b string) *Amount {
+	if max, err := amountCheck(a).Max(*amountCheck(b)); err != nil {
+		panic(err)
+	} else {
+		return max
+	}
+}
+
 func amountCheck(v interface{}) *Amount {
 	if a, err := NewAmount(v); err != nil {
 		panic(err)
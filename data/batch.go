@@ -0,0 +1,175 @@
+package data
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+)
+
+// EnableBatch registers the Batch transaction (XLS-56), so that it can be
+// read, written and signed. It is off by default as the format may still
+// change before the amendment is enabled on mainnet. Call it before
+// reading any transactions, as the registries are not locked.
+func EnableBatch() {
+	TxFactory[BATCH] = func() Transaction { return &Batch{TxBase: TxBase{TransactionType: BATCH}} }
+	txNames[BATCH] = "Batch"
+	txTypes["Batch"] = BATCH
+}
+
+// Batch applies its inner transactions together, as its flags say. The
+// inner transactions are neither signed nor pay a fee themselves: the
+// accounts of those not sent by the Account sign the batch as
+// BatchSigners instead.
+type Batch struct {
+	TxBase
+	RawTransactions []RawTransaction
+	BatchSigners    []BatchSigner `json:",omitempty"`
+}
+
+type RawTransaction struct {
+	RawTransaction Transaction
+}
+
+// BatchSigner is signed by either a key of the account or its Signers
+type BatchSigner struct {
+	BatchSigner struct {
+		Account       Account
+		SigningPubKey *PublicKey      `json:",omitempty"`
+		TxnSignature  *VariableLength `json:",omitempty"`
+		Signers       MultiSigners    `json:",omitempty"`
+	}
+}
+
+// Add makes tx an inner transaction and appends it to the batch
+func (b *Batch) Add(tx Transaction) error {
+	base := tx.GetBase()
+	if base == nil || tx.GetTransactionType() == BATCH {
+		return fmt.Errorf("%s can't be in a Batch", tx.GetType())
+	}
+	flags := TxInnerBatch
+	if base.Flags != nil {
+		flags |= *base.Flags
+	}
+	zero, err := NewNativeValue(0)
+	if err != nil {
+		return err
+	}
+	base.Flags, base.Fee = &flags, *zero
+	base.SigningPubKey, base.TxnSignature, base.Signers = new(PublicKey), nil, nil
+	b.RawTransactions = append(b.RawTransactions, RawTransaction{tx})
+	return nil
+}
+
+// Transactions returns the inner transactions
+func (b *Batch) Transactions() []Transaction {
+	txs := make([]Transaction, len(b.RawTransactions))
+	for i := range b.RawTransactions {
+		txs[i] = b.RawTransactions[i].RawTransaction
+	}
+	return txs
+}
+
+// BatchSignerAccounts returns the accounts, other than the Account, which
+// send inner transactions, and so must be BatchSigners
+func (b *Batch) BatchSignerAccounts() []Account {
+	var accounts []Account
+	seen := map[Account]bool{b.Account: true}
+	for _, raw := range b.RawTransactions {
+		account := raw.RawTransaction.GetBase().Account
+		if !seen[account] {
+			seen[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts
+}
+
+// BatchMessage returns what BatchSigners sign: the prefix, the flags of
+// the batch, the number of inner transactions and their hashes
+func (b *Batch) BatchMessage() ([]byte, error) {
+	var flags TransactionFlag
+	if b.Flags != nil {
+		flags = *b.Flags
+	}
+	msg := make([]byte, 12, 12+32*len(b.RawTransactions))
+	copy(msg, HP_BATCH.Bytes())
+	binary.BigEndian.PutUint32(msg[4:], uint32(flags))
+	binary.BigEndian.PutUint32(msg[8:], uint32(len(b.RawTransactions)))
+	for _, raw := range b.RawTransactions {
+		hash, _, err := Raw(raw.RawTransaction)
+		if err != nil {
+			return nil, err
+		}
+		msg = append(msg, hash[:]...)
+	}
+	return msg, nil
+}
+
+// SignBatch adds the BatchSigner of account, signed by key, which may be
+// its master or regular key
+func SignBatch(b *Batch, account Account, key crypto.Key, sequence *uint32) error {
+	msg, err := b.BatchMessage()
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(key.Private(sequence), crypto.Sha512Half(msg), msg)
+	if err != nil {
+		return err
+	}
+	var signer BatchSigner
+	signer.BatchSigner.Account = account
+	signer.BatchSigner.SigningPubKey = new(PublicKey)
+	copy(signer.BatchSigner.SigningPubKey[:], key.Public(sequence))
+	signature := VariableLength(sig)
+	signer.BatchSigner.TxnSignature = &signature
+	b.BatchSigners = append(b.BatchSigners, signer)
+	return nil
+}
+
+func checkBatchSignature(public PublicKey, msg []byte, sig VariableLength, account Account) error {
+	ok, err := crypto.Verify(public.Bytes(), crypto.Sha512Half(msg), msg, sig)
+	switch {
+	case err != nil:
+		return fmt.Errorf("BatchSigner %s: %s", account, err)
+	case !ok:
+		return fmt.Errorf("Bad batch signature by %s", account)
+	}
+	return nil
+}
+
+// VerifyBatchSigners checks the signatures of the BatchSigners, and that
+// each account which needs to has signed. Whether the keys may sign for
+// their accounts is not checked.
+func VerifyBatchSigners(b *Batch) error {
+	msg, err := b.BatchMessage()
+	if err != nil {
+		return err
+	}
+	signed := make(map[Account]bool)
+	for _, s := range b.BatchSigners {
+		signer := &s.BatchSigner
+		switch {
+		case len(signer.Signers) > 0:
+			for _, m := range signer.Signers {
+				multi := append(append([]byte(nil), msg...), m.Signer.Account[:]...)
+				if err := checkBatchSignature(m.Signer.SigningPubKey, multi, m.Signer.TxnSignature, m.Signer.Account); err != nil {
+					return err
+				}
+			}
+		case signer.SigningPubKey == nil || signer.TxnSignature == nil:
+			return fmt.Errorf("BatchSigner %s is not signed", signer.Account)
+		default:
+			if err := checkBatchSignature(*signer.SigningPubKey, msg, *signer.TxnSignature, signer.Account); err != nil {
+				return err
+			}
+		}
+		signed[signer.Account] = true
+	}
+	for _, account := range b.BatchSignerAccounts() {
+		if !signed[account] {
+			return fmt.Errorf("Missing BatchSigner: %s", account)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package data
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+	. "gopkg.in/check.v1"
+)
+
+type BatchSuite struct{}
+
+var _ = Suite(&BatchSuite{})
+
+func batchAccount(c *C, seed string) (Account, crypto.Key) {
+	key, err := crypto.NewEd25519Key([]byte(seed))
+	c.Assert(err, IsNil)
+	var account Account
+	copy(account[:], key.Id(nil))
+	return account, key
+}
+
+func batchPayment(c *C, from, to Account, sequence uint32) *Payment {
+	amount, err := NewAmount("1000000")
+	c.Assert(err, IsNil)
+	tx := TxFactory[PAYMENT]().(*Payment)
+	tx.Account, tx.Destination, tx.Amount, tx.Sequence = from, to, *amount, sequence
+	return tx
+}
+
+func (s *BatchSuite) TestBatch(c *C) {
+	EnableBatch()
+	alice, _ := batchAccount(c, "alice")
+	bob, bobKey := batchAccount(c, "bob")
+
+	batch := TxFactory[BATCH]().(*Batch)
+	flags := TxAllOrNothing
+	fee, err := NewNativeValue(40)
+	c.Assert(err, IsNil)
+	batch.Account, batch.Sequence, batch.Fee, batch.Flags = alice, 1, *fee, &flags
+	c.Assert(batch.Add(batchPayment(c, alice, bob, 2)), IsNil)
+	c.Assert(batch.Add(batchPayment(c, bob, alice, 5)), IsNil)
+	c.Check(batch.Add(TxFactory[BATCH]()), ErrorMatches, "Batch can't be in a Batch")
+	c.Check(batch.BatchSignerAccounts(), DeepEquals, []Account{bob})
+	c.Check(batch.Transactions(), HasLen, 2)
+	inner := batch.Transactions()[1]
+	c.Check(inner.GetBase().Flags.Explain(inner), DeepEquals, []string{"InnerBatchTxn"})
+	c.Check(batch.Flags.Explain(batch), DeepEquals, []string{"AllOrNothing"})
+
+	msg, err := batch.BatchMessage()
+	c.Assert(err, IsNil)
+	c.Check(msg, HasLen, 12+2*32)
+	c.Check(string(b2h(msg[:12])), Equals, "424348000001000000000002")
+
+	unsigned, _, err := SigningHash(batch)
+	c.Assert(err, IsNil)
+	c.Check(VerifyBatchSigners(batch), ErrorMatches, "Missing BatchSigner: "+bob.String())
+	c.Assert(SignBatch(batch, bob, bobKey, nil), IsNil)
+	c.Check(VerifyBatchSigners(batch), IsNil)
+	signed, _, err := SigningHash(batch)
+	c.Assert(err, IsNil)
+	c.Check(signed, Equals, unsigned)
+
+	_, raw, err := Raw(batch)
+	c.Assert(err, IsNil)
+	// The inner transactions pay no fee and have an empty SigningPubKey
+	c.Check(regexp.MustCompile("E0221200002240000000.*6840000000000000007300").Match(b2h(raw)), Equals, true)
+	decoded, err := ReadTransaction(bytes.NewReader(raw))
+	c.Assert(err, IsNil)
+	c.Check(decoded, DeepEquals, Transaction(batch))
+	c.Check(VerifyBatchSigners(decoded.(*Batch)), IsNil)
+
+	b, err := json.Marshal(batch)
+	c.Assert(err, IsNil)
+	var txm TransactionWithMetaData
+	c.Assert(json.Unmarshal(b, &txm), IsNil)
+	_, fromJSON, err := Raw(txm.Transaction)
+	c.Assert(err, IsNil)
+	c.Check(fromJSON, DeepEquals, raw)
+
+	flags = TxIndependent
+	c.Check(VerifyBatchSigners(batch), ErrorMatches, "Bad batch signature by "+bob.String())
+}
+
+// batchBlob is a Batch from rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh with a
+// Payment of 1 XRP to rf1BiGeXwwQoi8Z2ueFYTEXSwuJYfV2Jpn, assembled field
+// by field from the SField codes of rippled rather than by this package
+const (
+	batchInner = "120000" + // TransactionType Payment
+		"2240000000" + // Flags tfInnerBatchTxn
+		"2400000002" + // Sequence 2
+		"6140000000000F4240" + // Amount 1000000
+		"684000000000000000" + // Fee 0
+		"7300" + // SigningPubKey, empty
+		"8114B5F762798A53D543A014CAF8B297CFF8F2F937E8" + // Account
+		"83144B4E9C06F24296074F7BC48F92A97916C6DC5EA9" // Destination
+	batchBlob = "120047" + // TransactionType Batch
+		"2200010000" + // Flags tfAllOrNothing
+		"2400000001" + // Sequence 1
+		"684000000000000028" + // Fee 40
+		"8114B5F762798A53D543A014CAF8B297CFF8F2F937E8" + // Account
+		"F01E" + // RawTransactions, STArray 30
+		"E022" + batchInner + "E1" + // RawTransaction, STObject 34
+		"F1"
+)
+
+func (s *BatchSuite) TestBatchBinary(c *C) {
+	EnableBatch()
+	blob, err := hex.DecodeString(batchBlob)
+	c.Assert(err, IsNil)
+	inner, err := hex.DecodeString(batchInner)
+	c.Assert(err, IsNil)
+	txID := func(b []byte) Hash256 {
+		var id Hash256
+		copy(id[:], crypto.Sha512Half(append(HP_TRANSACTION_ID.Bytes(), b...)))
+		return id
+	}
+
+	tx, err := ReadTransaction(bytes.NewReader(blob))
+	c.Assert(err, IsNil)
+	batch := tx.(*Batch)
+	c.Assert(batch.Transactions(), HasLen, 1)
+	c.Check(batch.Transactions()[0].GetBase().Account.String(), Equals, "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh")
+	c.Check(batch.Transactions()[0].(*Payment).Destination.String(), Equals, "rf1BiGeXwwQoi8Z2ueFYTEXSwuJYfV2Jpn")
+
+	hash, raw, err := Raw(batch)
+	c.Assert(err, IsNil)
+	c.Check(string(b2h(raw)), Equals, batchBlob)
+	c.Check(hash, Equals, txID(blob))
+
+	innerID := txID(inner)
+	msg, err := batch.BatchMessage()
+	c.Assert(err, IsNil)
+	c.Check(string(b2h(msg)), Equals, "42434800"+"00010000"+"00000001"+innerID.String())
+}
@@ -0,0 +1,98 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type CheckSuite struct{}
+
+var _ = Suite(&CheckSuite{})
+
+const checkEntries = `[{
+	"Account": "rUn84CUYbNjRoTQ6mSW7BVJPSVJNLb1QLo",
+	"Destination": "rfkE1aSy9G8Upk4JssnwBxhEv5p4mn2KTy",
+	"DestinationNode": "0000000000000000",
+	"DestinationTag": 1,
+	"Expiration": 570113521,
+	"Flags": 0,
+	"InvoiceID": "46060241FABCF692D4D934BA2A6C4427CD4279083E38C77CBE642243E43BE291",
+	"LedgerEntryType": "Check",
+	"OwnerNode": "0000000000000000",
+	"PreviousTxnID": "5463C6E08862A1FAE5EDAC12D70ADB16546A1F674930521295BC082494B62924",
+	"PreviousTxnLgrSeq": 6,
+	"SendMax": "100000000",
+	"Sequence": 2,
+	"index": "49647F0D748DC3FE26BDACBC57F251AADEFFF391403EC9BF87C97F67E9977FB0"
+}, {
+	"Account": "rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de",
+	"Flags": 0,
+	"LedgerEntryType": "Ticket",
+	"OwnerNode": "0000000000000000",
+	"PreviousTxnID": "F19AD4577212D3BEACA0F75FE1BA1644F2E854D46E8D62E9C95D18E9708CBFB1",
+	"PreviousTxnLgrSeq": 4,
+	"TicketSequence": 3,
+	"index": "F78AC975CA66541A1CF6039EC1463687394E2AB1CF18BF76F8786D1493A22FFB"
+}, {
+	"LedgerEntryType": "DepositPreauth",
+	"Account": "rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8",
+	"Authorize": "rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de",
+	"Flags": 0,
+	"OwnerNode": "0000000000000000",
+	"PreviousTxnID": "3E8964D5A86B3CD6B9ECB33310D4E073D64C865A5B866200AD2B7E29F8326702",
+	"PreviousTxnLgrSeq": 7,
+	"index": "4A255038CC3ADCC1A9C91509279B59908251728D0DAADB248FFE297D0F7E068C"
+}]`
+
+func (s *CheckSuite) TestLedgerEntries(c *C) {
+	var entries LedgerEntrySlice
+	c.Assert(json.Unmarshal([]byte(checkEntries), &entries), IsNil)
+	c.Assert(entries, HasLen, 3)
+	for _, le := range entries {
+		index, err := LedgerIndex(le)
+		c.Assert(err, IsNil)
+		c.Check(*index, Equals, *le.GetLedgerIndex(), Commentf("%s", le.GetType()))
+
+		var buf bytes.Buffer
+		c.Assert(encode(&buf, le, false), IsNil)
+		node := append([]byte{0x11, 0x00, byte(le.GetLedgerEntryType())}, buf.Bytes()...)
+		node = append(node, index[:]...)
+		decoded, err := ReadLedgerEntry(bytes.NewReader(node), *index)
+		c.Assert(err, IsNil, Commentf("%s", le.GetType()))
+		c.Check(decoded.GetType(), Equals, le.GetType())
+		decodedIndex, err := LedgerIndex(decoded)
+		c.Assert(err, IsNil)
+		c.Check(*decodedIndex, Equals, *index)
+	}
+	check := entries[0].(*Check)
+	c.Check(*check.DestinationTag, Equals, uint32(1))
+	c.Check(check.InvoiceID.String(), Equals, "46060241FABCF692D4D934BA2A6C4427CD4279083E38C77CBE642243E43BE291")
+	preauth := entries[2].(*DepositPreAuth)
+	c.Check(preauth.Affects(*preauth.Authorize), Equals, true)
+	b, err := json.Marshal(preauth)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Matches, `\{"LedgerEntryType":"DepositPreauth",.*`)
+}
+
+func (s *CheckSuite) TestTransactions(c *C) {
+	for _, test := range []string{
+		`{"TransactionType":"CheckCreate","Account":"rUn84CUYbNjRoTQ6mSW7BVJPSVJNLb1QLo","Fee":"12","Sequence":2,"Destination":"rfkE1aSy9G8Upk4JssnwBxhEv5p4mn2KTy","SendMax":"100000000","Expiration":570113521,"InvoiceID":"6F1DFD1D0FE8A32E40E1F2C05CF1C15545BAB56B617F9C6C2D63A6B704BEF59B","DestinationTag":1}`,
+		`{"TransactionType":"CheckCash","Account":"rfkE1aSy9G8Upk4JssnwBxhEv5p4mn2KTy","Fee":"12","Sequence":3,"CheckID":"838766BA2B995C00744175F69A1B11E32C3DBC40E64801A4056FCBD657F57334","Amount":"100000000"}`,
+		`{"TransactionType":"CheckCash","Account":"rfkE1aSy9G8Upk4JssnwBxhEv5p4mn2KTy","Fee":"12","Sequence":4,"CheckID":"838766BA2B995C00744175F69A1B11E32C3DBC40E64801A4056FCBD657F57334","DeliverMin":{"currency":"USD","issuer":"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh","value":"5"}}`,
+		`{"TransactionType":"CheckCancel","Account":"rUn84CUYbNjRoTQ6mSW7BVJPSVJNLb1QLo","Fee":"12","Sequence":5,"CheckID":"49647F0D748DC3FE26BDACBC57F251AADEFFF391403EC9BF87C97F67E9977FB0"}`,
+		`{"TransactionType":"TicketCreate","Account":"rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de","Fee":"10","Sequence":381,"TicketCount":10}`,
+		`{"TransactionType":"Payment","Account":"rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de","Fee":"10","Sequence":0,"TicketSequence":382,"Destination":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","Amount":"1000"}`,
+		`{"TransactionType":"DepositPreauth","Account":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","Fee":"10","Sequence":2,"Authorize":"rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de"}`,
+		`{"TransactionType":"DepositPreauth","Account":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","Fee":"10","Sequence":3,"Unauthorize":"rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de"}`,
+	} {
+		var txm TransactionWithMetaData
+		c.Assert(json.Unmarshal([]byte(test), &txm), IsNil, Commentf(test))
+		_, raw, err := Raw(txm.Transaction)
+		c.Assert(err, IsNil, Commentf(test))
+		decoded, err := ReadTransaction(bytes.NewReader(raw))
+		c.Assert(err, IsNil, Commentf(test))
+		c.Check(decoded, DeepEquals, txm.Transaction, Commentf(test))
+	}
+}
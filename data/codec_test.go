@@ -1,6 +1,7 @@
 package data
 
 import (
+	"bytes"
 	"encoding/json"
 
 	internal "github.com/kr-jaydeepp/ripple/testing"
@@ -93,3 +94,35 @@ func (s *CodecSuite) TestParseMetaData(c *C) {
 		}
 	}
 }
+
+func (s *CodecSuite) TestClawback(c *C) {
+	var txm TransactionWithMetaData
+	c.Assert(json.Unmarshal([]byte(`{"TransactionType":"Clawback","Account":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","Fee":"10","Sequence":5,"Amount":{"currency":"USD","issuer":"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh","value":"100"}}`), &txm), IsNil)
+	clawback, ok := txm.Transaction.(*Clawback)
+	c.Assert(ok, Equals, true)
+	c.Check(clawback.Holder().String(), Equals, "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh")
+	_, raw, err := Raw(clawback)
+	c.Assert(err, IsNil)
+	c.Check(string(b2h(raw[:3])), Equals, "12001E")
+	decoded, err := ReadTransaction(bytes.NewReader(raw))
+	c.Assert(err, IsNil)
+	c.Check(decoded, DeepEquals, txm.Transaction)
+
+	account := LedgerEntryFactory[ACCOUNT_ROOT]()
+	c.Check((LsAllowClawback | LsNoFreeze).Explain(account), DeepEquals, []string{"NoFreeze", "AllowTrustLineClawback"})
+}
+
+func (s *CodecSuite) TestEscrowConditions(c *C) {
+	for _, test := range []string{
+		`{"TransactionType":"EscrowCreate","Account":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","Fee":"10","Sequence":5,"Destination":"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh","Amount":"1000000","Condition":"A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100","CancelAfter":700000000}`,
+		`{"TransactionType":"EscrowFinish","Account":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","Fee":"330","Sequence":6,"Owner":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","OfferSequence":5,"Condition":"A0258020E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855810100","Fulfillment":"A0028000"}`,
+	} {
+		var txm TransactionWithMetaData
+		c.Assert(json.Unmarshal([]byte(test), &txm), IsNil, Commentf(test))
+		_, raw, err := Raw(txm.Transaction)
+		c.Assert(err, IsNil, Commentf(test))
+		decoded, err := ReadTransaction(bytes.NewReader(raw))
+		c.Assert(err, IsNil, Commentf(test))
+		c.Check(decoded, DeepEquals, txm.Transaction, Commentf(test))
+	}
+}
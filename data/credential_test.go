@@ -0,0 +1,89 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type CredentialSuite struct{}
+
+var _ = Suite(&CredentialSuite{})
+
+const credentialEntries = `[{
+	"CredentialType": "6D795F63726564656E7469616C",
+	"Flags": 65536,
+	"Issuer": "ra5nK24KXen9AHvsdFTKHSANinZseWnPcX",
+	"IssuerNode": "0",
+	"LedgerEntryType": "Credential",
+	"PreviousTxnID": "7D1257779E2D298C07C7E0C73CD446534B143FBD1F13DB268A878E40FD153B9A",
+	"PreviousTxnLgrSeq": 234644,
+	"Subject": "rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8",
+	"SubjectNode": "0",
+	"URI": "6973737565722E636F6D2F63726564656E7469616C732F7573722F3132333435",
+	"index": "429D58B6699DAC393A4FF6068522493A5999131881E2C00D563DA65D2839A344"
+}, {
+	"LedgerEntryType": "PermissionedDomain",
+	"Owner": "rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8",
+	"Flags": 0,
+	"OwnerNode": "0",
+	"Sequence": 390,
+	"AcceptedCredentials": [{
+		"Credential": {
+			"Issuer": "ra5nK24KXen9AHvsdFTKHSANinZseWnPcX",
+			"CredentialType": "6D795F63726564656E7469616C"
+		}
+	}],
+	"PreviousTxnID": "E7E3F2BBAAF48CF893896E48DC4A02BDA0C747B198D5AE18BC3D7567EE64B904",
+	"PreviousTxnLgrSeq": 8734523,
+	"index": "CA0ED11C05F5BE3201BD83F9452D7EAEB49CFFC161837095E375E1DA1F3D820C"
+}]`
+
+func (s *CredentialSuite) TestLedgerEntries(c *C) {
+	var entries LedgerEntrySlice
+	c.Assert(json.Unmarshal([]byte(credentialEntries), &entries), IsNil)
+	c.Assert(entries, HasLen, 2)
+	for _, le := range entries {
+		index, err := LedgerIndex(le)
+		c.Assert(err, IsNil)
+		c.Check(*index, Equals, *le.GetLedgerIndex(), Commentf("%s", le.GetType()))
+
+		var buf bytes.Buffer
+		c.Assert(encode(&buf, le, false), IsNil)
+		node := append([]byte{0x11, 0x00, byte(le.GetLedgerEntryType())}, buf.Bytes()...)
+		node = append(node, index[:]...)
+		decoded, err := ReadLedgerEntry(bytes.NewReader(node), *index)
+		c.Assert(err, IsNil, Commentf("%s", le.GetType()))
+		var again bytes.Buffer
+		c.Assert(encode(&again, decoded, false), IsNil)
+		c.Check(again.Bytes(), DeepEquals, buf.Bytes(), Commentf("%s", le.GetType()))
+	}
+	credential := entries[0].(*Credential)
+	c.Check(credential.Accepted(), Equals, true)
+	c.Check(credential.Flags.Explain(credential), DeepEquals, []string{"Accepted"})
+	c.Check(credential.Affects(*credential.Issuer), Equals, true)
+	c.Check(string(*credential.CredentialType), Equals, "my_credential")
+	domain := entries[1].(*PermissionedDomain)
+	c.Assert(domain.AcceptedCredentials, HasLen, 1)
+	c.Check(domain.AcceptedCredentials[0].Credential.Issuer, Equals, *credential.Issuer)
+}
+
+func (s *CredentialSuite) TestTransactions(c *C) {
+	for _, test := range []string{
+		`{"TransactionType":"CredentialCreate","Account":"ra5nK24KXen9AHvsdFTKHSANinZseWnPcX","Fee":"10","Sequence":234203,"Subject":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","CredentialType":"6D795F63726564656E7469616C","Expiration":789004799,"URI":"6973737565722E636F6D2F63726564656E7469616C732F7573722F3132333435"}`,
+		`{"TransactionType":"CredentialAccept","Account":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","Fee":"10","Sequence":4,"Issuer":"ra5nK24KXen9AHvsdFTKHSANinZseWnPcX","CredentialType":"6D795F63726564656E7469616C"}`,
+		`{"TransactionType":"CredentialDelete","Account":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","Fee":"10","Sequence":5,"Issuer":"ra5nK24KXen9AHvsdFTKHSANinZseWnPcX","CredentialType":"6D795F63726564656E7469616C"}`,
+		`{"TransactionType":"PermissionedDomainSet","Account":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","Fee":"10","Sequence":390,"AcceptedCredentials":[{"Credential":{"Issuer":"ra5nK24KXen9AHvsdFTKHSANinZseWnPcX","CredentialType":"6D795F63726564656E7469616C"}}]}`,
+		`{"TransactionType":"PermissionedDomainSet","Account":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","Fee":"10","Sequence":391,"DomainID":"CA0ED11C05F5BE3201BD83F9452D7EAEB49CFFC161837095E375E1DA1F3D820C","AcceptedCredentials":[{"Credential":{"Issuer":"ra5nK24KXen9AHvsdFTKHSANinZseWnPcX","CredentialType":"6D795F63726564656E7469616C"}},{"Credential":{"Issuer":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","CredentialType":"6B7963"}}]}`,
+		`{"TransactionType":"PermissionedDomainDelete","Account":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","Fee":"10","Sequence":392,"DomainID":"CA0ED11C05F5BE3201BD83F9452D7EAEB49CFFC161837095E375E1DA1F3D820C"}`,
+	} {
+		var txm TransactionWithMetaData
+		c.Assert(json.Unmarshal([]byte(test), &txm), IsNil, Commentf(test))
+		_, raw, err := Raw(txm.Transaction)
+		c.Assert(err, IsNil, Commentf(test))
+		decoded, err := ReadTransaction(bytes.NewReader(raw))
+		c.Assert(err, IsNil, Commentf(test))
+		c.Check(decoded, DeepEquals, txm.Transaction, Commentf(test))
+	}
+}
@@ -3,6 +3,7 @@ package data
 import (
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 )
 
@@ -66,16 +67,31 @@ func ReadValidation(r Reader) (*Validation, error) {
 	return validation, nil
 }
 
+func ReadManifest(r Reader) (*Manifest, error) {
+	manifest := new(Manifest)
+	v := reflect.ValueOf(manifest)
+	if err := readObject(r, &v); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
 func ReadTransaction(r Reader) (Transaction, error) {
 	txType, err := expectType(r, "TransactionType")
 	if err != nil {
 		return nil, err
 	}
+	if int(txType) >= len(TxFactory) || TxFactory[txType] == nil {
+		return nil, fmt.Errorf("Unknown TransactionType: %d", txType)
+	}
 	tx := TxFactory[txType]()
 	v := reflect.ValueOf(tx)
 	if err := readObject(r, &v); err != nil {
 		return nil, err
 	}
+	if tx.GetTransactionType() != TransactionType(txType) {
+		return nil, fmt.Errorf("TransactionType %d is repeated as %d", txType, tx.GetTransactionType())
+	}
 	return tx, nil
 }
 
@@ -156,18 +172,29 @@ func readCompressedInnerNode(r Reader, typ NodeType, nodeId Hash256) (*InnerNode
 }
 
 func ReadLedgerEntry(r Reader, nodeId Hash256) (LedgerEntry, error) {
-	leType, err := expectType(r, "LedgerEntryType")
+	// LedgerEntries have 32 bytes of index suffixed
+	// but don't have a variable bytes indicator
+	return readLedgerEntry(r, r.Len()-32, nodeId)
+}
+
+// readLedgerEntry reads an entry of length bytes, followed by its index
+func readLedgerEntry(r Reader, length int, nodeId Hash256) (LedgerEntry, error) {
+	lr := LimitedByteReader(r, int64(length))
+	leType, err := expectType(lr, "LedgerEntryType")
 	if err != nil {
 		return nil, err
 	}
+	if int(leType) >= len(LedgerEntryFactory) || LedgerEntryFactory[leType] == nil {
+		return nil, fmt.Errorf("Unknown LedgerEntryType: %d", leType)
+	}
 	le := LedgerEntryFactory[leType]()
 	v := reflect.ValueOf(le)
-	// LedgerEntries have 32 bytes of index suffixed
-	// but don't have a variable bytes indicator
-	lr := LimitedByteReader(r, int64(r.Len()-32))
 	if err := readObject(lr, &v); err != nil {
 		return nil, err
 	}
+	if le.GetLedgerEntryType() != LedgerEntryType(leType) {
+		return nil, fmt.Errorf("LedgerEntryType %d is repeated as %d", leType, le.GetLedgerEntryType())
+	}
 	hash, err := readHash(r)
 	if err != nil {
 		return nil, err
@@ -189,7 +216,7 @@ func readHeader(r Reader) (*NodeHeader, error) {
 
 func readHash(r Reader) (*Hash256, error) {
 	var h Hash256
-	n, err := r.Read(h[:])
+	n, err := io.ReadFull(r, h[:])
 	switch {
 	case err != nil:
 		return nil, err
@@ -218,8 +245,14 @@ var (
 	errorEndOfArray  = errors.New("EndOfArray")
 )
 
-func readObject(r Reader, v *reflect.Value) error {
-	var err error
+// Malformed input, such as an object where it can't be, makes the
+// reflection panic, which is returned as an error instead
+func readObject(r Reader, v *reflect.Value) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("Bad %s: %v", v.Type(), p)
+		}
+	}()
 	for enc, err := readEncoding(r); err == nil; enc, err = readEncoding(r) {
 		name := encodings[*enc]
 		// fmt.Println(name, v, v.IsValid(), enc.typ, enc.field)
@@ -229,6 +262,15 @@ func readObject(r Reader, v *reflect.Value) error {
 				return errorEndOfArray
 			}
 			array := getField(v, enc)
+			if !array.IsValid() {
+				if ok, err := readDynamicField(r, v, *enc); ok {
+					if err != nil {
+						return err
+					}
+					continue
+				}
+				return fmt.Errorf("Missing field: %s %+v", name, enc)
+			}
 		loop:
 			for {
 				child := reflect.New(array.Type().Elem()).Elem()
@@ -274,6 +316,13 @@ func readObject(r Reader, v *reflect.Value) error {
 				err := readObject(r, &s)
 				v.Set(s.Elem())
 				return err
+			case "Signer":
+				var signer MultiSigner
+				m := reflect.ValueOf(&signer)
+				inner := reflect.ValueOf(&signer.Signer)
+				err := readObject(r, &inner)
+				v.Set(m.Elem())
+				return err
 			case "Majority":
 				var majority Majority
 				m := reflect.ValueOf(&majority)
@@ -287,7 +336,89 @@ func readObject(r Reader, v *reflect.Value) error {
 				err := readObject(r, &inner)
 				v.Set(m.Elem())
 				return err
+			case "NFToken":
+				var token NFToken
+				t := reflect.ValueOf(&token)
+				inner := reflect.ValueOf(&token.NFToken)
+				err := readObject(r, &inner)
+				v.Set(t.Elem())
+				return err
+			case "VoteEntry":
+				var vote VoteEntry
+				e := reflect.ValueOf(&vote)
+				inner := reflect.ValueOf(&vote.VoteEntry)
+				err := readObject(r, &inner)
+				v.Set(e.Elem())
+				return err
+			case "AuthAccount":
+				var auth AuthAccount
+				a := reflect.ValueOf(&auth)
+				inner := reflect.ValueOf(&auth.AuthAccount)
+				err := readObject(r, &inner)
+				v.Set(a.Elem())
+				return err
+			case "XChainClaimAttestationCollectionElement":
+				var attestation XChainClaimAttestation
+				a := reflect.ValueOf(&attestation)
+				inner := reflect.ValueOf(&attestation.XChainClaimAttestationCollectionElement)
+				err := readObject(r, &inner)
+				v.Set(a.Elem())
+				return err
+			case "XChainCreateAccountAttestationCollectionElement":
+				var attestation XChainCreateAccountAttestation
+				a := reflect.ValueOf(&attestation)
+				inner := reflect.ValueOf(&attestation.XChainCreateAccountAttestationCollectionElement)
+				err := readObject(r, &inner)
+				v.Set(a.Elem())
+				return err
+			case "PriceData":
+				var price PriceData
+				p := reflect.ValueOf(&price)
+				inner := reflect.ValueOf(&price.PriceData)
+				err := readObject(r, &inner)
+				v.Set(p.Elem())
+				return err
+			case "Credential":
+				var credential AcceptedCredential
+				a := reflect.ValueOf(&credential)
+				inner := reflect.ValueOf(&credential.Credential)
+				err := readObject(r, &inner)
+				v.Set(a.Elem())
+				return err
+			case "RawTransaction":
+				txType, err := expectType(r, "TransactionType")
+				if err != nil {
+					return err
+				}
+				if int(txType) >= len(TxFactory) || TxFactory[txType] == nil {
+					return fmt.Errorf("Unknown inner TransactionType: %d", txType)
+				}
+				raw := RawTransaction{RawTransaction: TxFactory[txType]()}
+				inner := reflect.ValueOf(raw.RawTransaction)
+				err = readObject(r, &inner)
+				v.Set(reflect.ValueOf(raw))
+				return err
+			case "BatchSigner":
+				var signer BatchSigner
+				b := reflect.ValueOf(&signer)
+				inner := reflect.ValueOf(&signer.BatchSigner)
+				err := readObject(r, &inner)
+				v.Set(b.Elem())
+				return err
+			case "AuctionSlot":
+				// A field of the entry rather than an array member
+				slot := v.Elem().FieldByName(name)
+				slot.Set(reflect.New(slot.Type().Elem()))
+				if err := readObject(r, &slot); err != errorEndOfObject {
+					return err
+				}
 			default:
+				if ok, err := readDynamicField(r, v, *enc); ok {
+					if err != nil {
+						return err
+					}
+					continue
+				}
 				return fmt.Errorf("Unexpected object: %s for field: %s", v.Type(), name)
 			}
 		default:
@@ -295,6 +426,14 @@ func readObject(r Reader, v *reflect.Value) error {
 				return fmt.Errorf("Unexpected struct: %s for field: %s", v.Type(), name)
 			}
 			field := getField(v, enc)
+			if !field.IsValid() {
+				if ok, err := readDynamicField(r, v, *enc); ok {
+					if err != nil {
+						return err
+					}
+					continue
+				}
+			}
 			if !field.CanAddr() {
 				return fmt.Errorf("Missing field: %s %+v", name, enc)
 			}
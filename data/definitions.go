@@ -0,0 +1,208 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Definitions describe the binary format, as in the definitions.json of
+// rippled's server_definitions and of the other client libraries. Once
+// registered, the fields and types added by amendments since this package
+// was released can be read and written, as DynamicFields, a
+// DynamicTransaction or a DynamicLedgerEntry.
+type Definitions struct {
+	Types              map[string]int    `json:"TYPES"`
+	LedgerEntryTypes   map[string]int    `json:"LEDGER_ENTRY_TYPES"`
+	Fields             []FieldDefinition `json:"FIELDS"`
+	TransactionResults map[string]int    `json:"TRANSACTION_RESULTS"`
+	TransactionTypes   map[string]int    `json:"TRANSACTION_TYPES"`
+}
+
+type FieldDefinition struct {
+	Name           string
+	Nth            int    `json:"nth"`
+	IsVLEncoded    bool   `json:"isVLEncoded"`
+	IsSerialized   bool   `json:"isSerialized"`
+	IsSigningField bool   `json:"isSigningField"`
+	Type           string `json:"type"`
+}
+
+// The types of field which can be read and written, by their names in
+// definitions
+var definitionTypes = map[string]uint8{
+	"UInt8":        ST_UINT8,
+	"UInt16":       ST_UINT16,
+	"UInt32":       ST_UINT32,
+	"UInt64":       ST_UINT64,
+	"Hash128":      ST_HASH128,
+	"Hash160":      ST_HASH160,
+	"Hash256":      ST_HASH256,
+	"Amount":       ST_AMOUNT,
+	"Blob":         ST_VL,
+	"AccountID":    ST_ACCOUNT,
+	"STObject":     ST_OBJECT,
+	"STArray":      ST_ARRAY,
+	"PathSet":      ST_PATHSET,
+	"Vector256":    ST_VECTOR256,
+	"Issue":        ST_ISSUE,
+	"XChainBridge": ST_BRIDGE,
+	"Currency":     ST_CURRENCY,
+}
+
+// FIELDS are [name, definition] pairs
+func (f *FieldDefinition) UnmarshalJSON(b []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(b, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("Bad field definition: %s", string(b))
+	}
+	if err := json.Unmarshal(pair[0], &f.Name); err != nil {
+		return err
+	}
+	type definition FieldDefinition
+	return json.Unmarshal(pair[1], (*definition)(f))
+}
+
+func LoadDefinitions(r io.Reader) (*Definitions, error) {
+	d := new(Definitions)
+	if err := json.NewDecoder(r).Decode(d); err != nil {
+		return nil, fmt.Errorf("Bad definitions: %s", err)
+	}
+	return d, nil
+}
+
+// Register adds the fields, transaction types, ledger entry types and
+// results which are not known already. Those which are known must agree
+// with the definitions, and nothing is registered unless they all do.
+// Fields of types which can't be read, such as Number, are left out.
+// Like EnableBatch, call it before reading any transactions.
+func (d *Definitions) Register() error {
+	fields, err := d.newFields()
+	if err != nil {
+		return err
+	}
+	newTxTypes, err := d.newTransactionTypes()
+	if err != nil {
+		return err
+	}
+	newLeTypes, err := d.newLedgerEntryTypes()
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		e := enc{definitionTypes[field.Type], uint8(field.Nth)}
+		encodings[e] = field.Name
+		reverseEncodings[field.Name] = e
+		if !field.IsSigningField {
+			signingFields[e] = struct{}{}
+		}
+	}
+	for name, code := range newTxTypes {
+		typ := code
+		TxFactory[typ] = func() Transaction { return &DynamicTransaction{TxBase{TransactionType: typ}} }
+		txNames[typ] = name
+		txTypes[name] = typ
+	}
+	for name, code := range newLeTypes {
+		typ := code
+		LedgerEntryFactory[typ] = func() LedgerEntry { return &DynamicLedgerEntry{leBase{LedgerEntryType: typ}} }
+		ledgerEntryNames[typ] = name
+		ledgerEntryTypes[name] = typ
+	}
+	for name, code := range d.TransactionResults {
+		if _, ok := reverseResults[name]; ok {
+			continue
+		}
+		result := TransactionResult(code)
+		if _, ok := resultNames[result]; !ok {
+			resultNames[result] = struct {
+				Token string
+				Human string
+			}{name, name}
+		}
+		reverseResults[name] = result
+	}
+	return nil
+}
+
+func (d *Definitions) newFields() ([]FieldDefinition, error) {
+	for name, typ := range definitionTypes {
+		if code, ok := d.Types[name]; ok && code != int(typ) {
+			return nil, fmt.Errorf("Type %s is %d, not %d as defined", name, typ, code)
+		}
+	}
+	var fields []FieldDefinition
+	for _, field := range d.Fields {
+		typ, ok := definitionTypes[field.Type]
+		if !ok || !field.IsSerialized || field.Nth < 1 || field.Nth > 255 {
+			continue
+		}
+		e := enc{typ, uint8(field.Nth)}
+		if _, ok := encodings[e]; ok {
+			continue
+		}
+		if known, ok := reverseEncodings[field.Name]; ok {
+			return nil, fmt.Errorf("Field %s is %s %d, not %s %d as defined", field.Name, typeName(known.typ), known.field, field.Type, field.Nth)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func typeName(typ uint8) string {
+	for name, t := range definitionTypes {
+		if t == typ {
+			return name
+		}
+	}
+	return fmt.Sprint(typ)
+}
+
+func (d *Definitions) newTransactionTypes() (map[string]TransactionType, error) {
+	types := make(map[string]TransactionType)
+	for name, code := range d.TransactionTypes {
+		if code < 0 {
+			continue
+		}
+		if known, ok := txTypes[name]; ok {
+			if int(known) != code {
+				return nil, fmt.Errorf("TransactionType %s is %d, not %d as defined", name, known, code)
+			}
+			continue
+		}
+		if code >= len(TxFactory) {
+			return nil, fmt.Errorf("TransactionType %s is out of range: %d", name, code)
+		}
+		if TxFactory[code] != nil {
+			return nil, fmt.Errorf("TransactionType %d is %s, not %s as defined", code, txNames[code], name)
+		}
+		types[name] = TransactionType(code)
+	}
+	return types, nil
+}
+
+func (d *Definitions) newLedgerEntryTypes() (map[string]LedgerEntryType, error) {
+	types := make(map[string]LedgerEntryType)
+	for name, code := range d.LedgerEntryTypes {
+		if code < 0 {
+			continue
+		}
+		if known, ok := ledgerEntryTypes[name]; ok {
+			if int(known) != code {
+				return nil, fmt.Errorf("LedgerEntryType %s is %d, not %d as defined", name, known, code)
+			}
+			continue
+		}
+		if code >= len(LedgerEntryFactory) {
+			return nil, fmt.Errorf("LedgerEntryType %s is out of range: %d", name, code)
+		}
+		if LedgerEntryFactory[code] != nil {
+			return nil, fmt.Errorf("LedgerEntryType %d is %s, not %s as defined", code, ledgerEntryNames[code], name)
+		}
+		types[name] = LedgerEntryType(code)
+	}
+	return types, nil
+}
@@ -0,0 +1,218 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// DefinitionsSuite restores the tables after each test, so that what the
+// tests register isn't known to the others
+type DefinitionsSuite struct {
+	encodings        map[enc]string
+	reverseEncodings map[string]enc
+	signingFields    map[enc]struct{}
+	txFactory        [len(TxFactory)]func() Transaction
+	txNames          [len(txNames)]string
+	txTypes          map[string]TransactionType
+	leFactory        [len(LedgerEntryFactory)]func() LedgerEntry
+	leNames          [len(ledgerEntryNames)]string
+	leTypes          map[string]LedgerEntryType
+	resultNames      map[TransactionResult]struct{ Token, Human string }
+	reverseResults   map[string]TransactionResult
+}
+
+var _ = Suite(&DefinitionsSuite{})
+
+func (s *DefinitionsSuite) SetUpTest(c *C) {
+	s.encodings = make(map[enc]string)
+	for k, v := range encodings {
+		s.encodings[k] = v
+	}
+	s.reverseEncodings = make(map[string]enc)
+	for k, v := range reverseEncodings {
+		s.reverseEncodings[k] = v
+	}
+	s.signingFields = make(map[enc]struct{})
+	for k, v := range signingFields {
+		s.signingFields[k] = v
+	}
+	s.txFactory, s.txNames, s.txTypes = TxFactory, txNames, make(map[string]TransactionType)
+	for k, v := range txTypes {
+		s.txTypes[k] = v
+	}
+	s.leFactory, s.leNames, s.leTypes = LedgerEntryFactory, ledgerEntryNames, make(map[string]LedgerEntryType)
+	for k, v := range ledgerEntryTypes {
+		s.leTypes[k] = v
+	}
+	s.resultNames = make(map[TransactionResult]struct{ Token, Human string })
+	for k, v := range resultNames {
+		s.resultNames[k] = v
+	}
+	s.reverseResults = make(map[string]TransactionResult)
+	for k, v := range reverseResults {
+		s.reverseResults[k] = v
+	}
+}
+
+func (s *DefinitionsSuite) TearDownTest(c *C) {
+	encodings, reverseEncodings, signingFields = s.encodings, s.reverseEncodings, s.signingFields
+	TxFactory, txNames, txTypes = s.txFactory, s.txNames, s.txTypes
+	LedgerEntryFactory, ledgerEntryNames, ledgerEntryTypes = s.leFactory, s.leNames, s.leTypes
+	resultNames, reverseResults = s.resultNames, s.reverseResults
+}
+
+const exampleDefinitions = `{
+	"TYPES": {"Unknown": -2, "UInt32": 2, "Blob": 7, "AccountID": 8, "Number": 9, "STObject": 14, "STArray": 15},
+	"LEDGER_ENTRY_TYPES": {"Invalid": -1, "AccountRoot": 97, "ExampleObject": 122},
+	"FIELDS": [
+		["Generic", {"nth": 0, "isVLEncoded": false, "isSerialized": false, "isSigningField": false, "type": "Unknown"}],
+		["Account", {"nth": 1, "isVLEncoded": true, "isSerialized": true, "isSigningField": true, "type": "AccountID"}],
+		["ObjectEndMarker", {"nth": 1, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "STObject"}],
+		["ExampleCount", {"nth": 200, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "UInt32"}],
+		["ExampleOwner", {"nth": 200, "isVLEncoded": true, "isSerialized": true, "isSigningField": true, "type": "AccountID"}],
+		["ExampleData", {"nth": 200, "isVLEncoded": true, "isSerialized": true, "isSigningField": true, "type": "Blob"}],
+		["ExampleProof", {"nth": 201, "isVLEncoded": true, "isSerialized": true, "isSigningField": false, "type": "Blob"}],
+		["ExampleItem", {"nth": 200, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "STObject"}],
+		["ExampleItems", {"nth": 200, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "STArray"}],
+		["ExampleNumber", {"nth": 200, "isVLEncoded": false, "isSerialized": true, "isSigningField": true, "type": "Number"}]
+	],
+	"TRANSACTION_RESULTS": {"tesSUCCESS": 0, "tecEXAMPLE": 199},
+	"TRANSACTION_TYPES": {"Invalid": -1, "Payment": 0, "ExampleSet": 90}
+}`
+
+const exampleTransaction = `{
+	"TransactionType": "ExampleSet",
+	"Account": "rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8",
+	"Fee": "10",
+	"Sequence": 7,
+	"ExampleCount": 3,
+	"ExampleData": "CAFE",
+	"ExampleItems": [{"ExampleItem": {"ExampleOwner": "ra5nK24KXen9AHvsdFTKHSANinZseWnPcX", "ExampleCount": 1}}],
+	"hash": "0000000000000000000000000000000000000000000000000000000000000000"
+}`
+
+func registerExampleDefinitions(c *C) {
+	d, err := LoadDefinitions(strings.NewReader(exampleDefinitions))
+	c.Assert(err, IsNil)
+	c.Assert(d.Fields, HasLen, 10)
+	c.Check(d.Fields[3].Name, Equals, "ExampleCount")
+	// Registering again changes nothing
+	c.Assert(d.Register(), IsNil)
+	c.Assert(d.Register(), IsNil)
+}
+
+func (s *DefinitionsSuite) TestTransaction(c *C) {
+	registerExampleDefinitions(c)
+	_, ok := reverseEncodings["ExampleNumber"]
+	c.Check(ok, Equals, false)
+	c.Check(encodings[enc{ST_OBJECT, 1}], Equals, "EndOfObject")
+	c.Check(reverseResults["tecEXAMPLE"].String(), Equals, "tecEXAMPLE")
+
+	tx := GetTxFactoryByType("ExampleSet")()
+	c.Assert(json.Unmarshal([]byte(exampleTransaction), tx), IsNil)
+	c.Check(tx.GetType(), Equals, "ExampleSet")
+	dynamic := tx.GetBase().Dynamic
+	c.Check(dynamic["ExampleCount"], Equals, uint32(3))
+	c.Check(string(dynamic["ExampleData"].(VariableLength)), Equals, "\xca\xfe")
+	items := dynamic["ExampleItems"].([]DynamicFields)
+	c.Assert(items, HasLen, 1)
+	item := items[0]["ExampleItem"].(DynamicFields)
+	c.Check(item["ExampleOwner"].(Account).String(), Equals, "ra5nK24KXen9AHvsdFTKHSANinZseWnPcX")
+
+	_, raw, err := Raw(tx)
+	c.Assert(err, IsNil)
+	decoded, err := ReadTransaction(bytes.NewReader(raw))
+	c.Assert(err, IsNil)
+	c.Check(decoded, DeepEquals, tx)
+
+	b, err := json.Marshal(decoded)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Matches, `.*"ExampleItems":\[\{"ExampleItem":\{"ExampleCount":1,"ExampleOwner":"ra5nK24KXen9AHvsdFTKHSANinZseWnPcX"\}\}\].*`)
+
+	// Fields which aren't signing fields aren't signed
+	hash, _, err := SigningHash(tx)
+	c.Assert(err, IsNil)
+	dynamic["ExampleProof"] = VariableLength("proof")
+	again, _, err := SigningHash(tx)
+	c.Assert(err, IsNil)
+	c.Check(again, Equals, hash)
+	_, withProof, err := Raw(tx)
+	c.Assert(err, IsNil)
+	c.Check(len(withProof), Equals, len(raw)+8)
+}
+
+func (s *DefinitionsSuite) TestKnownTypes(c *C) {
+	registerExampleDefinitions(c)
+	// Fields added to known types are kept in binary
+	tx := TxFactory[ACCOUNT_SET]()
+	tx.GetBase().Dynamic = DynamicFields{"ExampleCount": uint32(9)}
+	_, raw, err := Raw(tx)
+	c.Assert(err, IsNil)
+	decoded, err := ReadTransaction(bytes.NewReader(raw))
+	c.Assert(err, IsNil)
+	c.Check(decoded.GetBase().Dynamic, DeepEquals, DynamicFields{"ExampleCount": uint32(9)})
+
+	var entries LedgerEntrySlice
+	c.Assert(json.Unmarshal([]byte(`[{
+		"LedgerEntryType": "ExampleObject",
+		"ExampleOwner": "rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8",
+		"ExampleCount": 2,
+		"index": "CA0ED11C05F5BE3201BD83F9452D7EAEB49CFFC161837095E375E1DA1F3D820C"
+	}]`), &entries), IsNil)
+	le := entries[0].(*DynamicLedgerEntry)
+	owner, err := NewAccountFromAddress("rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8")
+	c.Assert(err, IsNil)
+	c.Check(le.Affects(*owner), Equals, true)
+
+	var buf bytes.Buffer
+	c.Assert(encode(&buf, le, false), IsNil)
+	node := append([]byte{0x11, 0x00, byte(le.GetLedgerEntryType())}, buf.Bytes()...)
+	node = append(node, le.GetLedgerIndex()[:]...)
+	decodedEntry, err := ReadLedgerEntry(bytes.NewReader(node), *le.GetLedgerIndex())
+	c.Assert(err, IsNil)
+	c.Check(decodedEntry.(*DynamicLedgerEntry).Dynamic, DeepEquals, le.Dynamic)
+}
+
+// testdata/definitions.json is taken from rippled's server_definitions,
+// with the fields, types and results this package has to agree with
+func (s *DefinitionsSuite) TestRippledDefinitions(c *C) {
+	EnableBatch()
+	f, err := os.Open("testdata/definitions.json")
+	c.Assert(err, IsNil)
+	defer f.Close()
+	d, err := LoadDefinitions(f)
+	c.Assert(err, IsNil)
+	c.Assert(d.Register(), IsNil)
+
+	c.Check(reverseEncodings["RawTransactions"], Equals, enc{ST_ARRAY, 30})
+	c.Check(reverseEncodings["HookExecutions"], Equals, enc{ST_ARRAY, 18})
+	c.Check(reverseEncodings["AssetScale"], Equals, enc{ST_UINT8, 5})
+	_, ok := reverseEncodings["MPTokenIssuanceID"]
+	c.Check(ok, Equals, false)
+	c.Check(GetTxFactoryByType("Batch")(), FitsTypeOf, &Batch{})
+	c.Check(GetTxFactoryByType("MPTokenIssuanceCreate")(), FitsTypeOf, &DynamicTransaction{})
+	c.Check(LedgerEntryFactory[ledgerEntryTypes["MPToken"]](), FitsTypeOf, &DynamicLedgerEntry{})
+}
+
+func (s *DefinitionsSuite) TestConflicts(c *C) {
+	for _, test := range []struct {
+		definitions, err string
+	}{
+		{`{"TYPES": {"UInt32": 3}}`, "Type UInt32 is 2, not 3 as defined"},
+		{`{"FIELDS": [["Account", {"nth": 250, "isSerialized": true, "type": "AccountID"}]]}`, "Field Account is AccountID 1, not AccountID 250 as defined"},
+		{`{"TRANSACTION_TYPES": {"Payment": 5}}`, "TransactionType Payment is 0, not 5 as defined"},
+		{`{"TRANSACTION_TYPES": {"Pay": 0}}`, "TransactionType 0 is Payment, not Pay as defined"},
+		{`{"TRANSACTION_TYPES": {"Future": 5000}}`, "TransactionType Future is out of range: 5000"},
+		{`{"LEDGER_ENTRY_TYPES": {"AccountRoot": 1}}`, "LedgerEntryType AccountRoot is 97, not 1 as defined"},
+	} {
+		d, err := LoadDefinitions(strings.NewReader(test.definitions))
+		c.Assert(err, IsNil)
+		c.Check(d.Register(), ErrorMatches, test.err)
+	}
+	_, err := LoadDefinitions(strings.NewReader(`{"FIELDS": [["Account"]]}`))
+	c.Check(err, ErrorMatches, "Bad definitions: Bad field definition: .*")
+}
@@ -0,0 +1,35 @@
+package data
+
+// The first ledger from which the metadata of every transaction which
+// delivered other than its Amount has a DeliveredAmount
+const DeliveredAmountLedger = 4594095
+
+// IsPartialPayment reports whether txm is a Payment with the
+// PartialPayment flag, which may deliver as little as its DeliverMin, or
+// next to nothing without one. Its Amount must never be credited, only
+// what DeliveredAmount returns.
+func (txm *TransactionWithMetaData) IsPartialPayment() bool {
+	payment, ok := txm.Transaction.(*Payment)
+	return ok && payment.Flags != nil && *payment.Flags&TxPartialPayment != 0
+}
+
+// DeliveredAmount returns what txm delivered to its destination. That is
+// the DeliveredAmount of the metadata if it has one, and otherwise the
+// Amount of a Payment, or nothing if it failed. It returns false when
+// what was delivered can't be known: for a partial payment older than
+// DeliveredAmountLedger, and for a transaction of another type without a
+// DeliveredAmount.
+func (txm *TransactionWithMetaData) DeliveredAmount() (*Amount, bool) {
+	payment, isPayment := txm.Transaction.(*Payment)
+	switch {
+	case isPayment && !txm.MetaData.TransactionResult.Success():
+		return payment.Amount.ZeroClone(), true
+	case txm.MetaData.DeliveredAmount != nil:
+		return txm.MetaData.DeliveredAmount, true
+	case !isPayment:
+		return nil, false
+	case txm.IsPartialPayment() && txm.LedgerSequence < DeliveredAmountLedger:
+		return nil, false
+	}
+	return &payment.Amount, true
+}
@@ -0,0 +1,82 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "gopkg.in/check.v1"
+)
+
+type DeliveredSuite struct{}
+
+var _ = Suite(&DeliveredSuite{})
+
+// payment returns a payment of 100 USD with flags, in ledger, with meta
+// as its metadata
+func payment(c *C, flags uint32, ledger uint32, meta string) *TransactionWithMetaData {
+	s := fmt.Sprintf(`{
+		"TransactionType": "Payment",
+		"Account": "rGgj3GurcrAqgBXGVoS9wvQG3Hjkj5oCbj",
+		"Destination": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B",
+		"Amount": {"currency": "USD", "issuer": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "value": "100"},
+		"SendMax": {"currency": "USD", "issuer": "rGgj3GurcrAqgBXGVoS9wvQG3Hjkj5oCbj", "value": "100"},
+		"Fee": "12",
+		"Flags": %d,
+		"Sequence": 1,
+		"hash": "1000000000000000000000000000000000000000000000000000000000000001",
+		"ledger_index": %d,
+		"meta": %s
+	}`, flags, ledger, meta)
+	var txm TransactionWithMetaData
+	c.Assert(json.Unmarshal([]byte(s), &txm), IsNil)
+	return &txm
+}
+
+func (s *DeliveredSuite) TestDeliveredAmount(c *C) {
+	for _, test := range []struct {
+		flags     uint32
+		ledger    uint32
+		meta      string
+		partial   bool
+		delivered string // Empty when unknown
+	}{
+		// As rippled adds it
+		{0x00020000, 7000000, `{"TransactionResult": "tesSUCCESS", "AffectedNodes": [], "delivered_amount": {"currency": "USD", "issuer": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "value": "0.01"}}`, true, "0.01/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"},
+		// As the field of the metadata
+		{0x00020000, 7000000, `{"TransactionResult": "tesSUCCESS", "AffectedNodes": [], "DeliveredAmount": {"currency": "USD", "issuer": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "value": "0.02"}}`, true, "0.02/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"},
+		// Both
+		{0x00020000, 7000000, `{"TransactionResult": "tesSUCCESS", "AffectedNodes": [], "DeliveredAmount": {"currency": "USD", "issuer": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "value": "0.03"}, "delivered_amount": {"currency": "USD", "issuer": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "value": "0.03"}}`, true, "0.03/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"},
+		// Too old to know
+		{0x00020000, 4000000, `{"TransactionResult": "tesSUCCESS", "AffectedNodes": [], "delivered_amount": "unavailable"}`, true, ""},
+		// Too old to have a DeliveredAmount, but not partial
+		{0, 4000000, `{"TransactionResult": "tesSUCCESS", "AffectedNodes": [], "delivered_amount": "unavailable"}`, false, "100/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"},
+		// Partial, but all of it
+		{0x00020000, 7000000, `{"TransactionResult": "tesSUCCESS", "AffectedNodes": []}`, true, "100/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"},
+		{0x80020000, 7000000, `{"TransactionResult": "tecPATH_PARTIAL", "AffectedNodes": []}`, true, "0/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"},
+	} {
+		txm := payment(c, test.flags, test.ledger, test.meta)
+		c.Check(txm.IsPartialPayment(), Equals, test.partial, Commentf("%s", test.meta))
+		delivered, ok := txm.DeliveredAmount()
+		c.Check(ok, Equals, test.delivered != "", Commentf("%s", test.meta))
+		if ok {
+			c.Check(delivered.String(), Equals, test.delivered, Commentf("%s", test.meta))
+		}
+	}
+}
+
+func (s *DeliveredSuite) TestNotPayment(c *C) {
+	txm := readTransaction(c, "testdata/transaction_account_set.json")
+	c.Check(txm.IsPartialPayment(), Equals, false)
+	_, ok := txm.DeliveredAmount()
+	c.Check(ok, Equals, false)
+}
+
+func (s *DeliveredSuite) TestMarshalMetaData(c *C) {
+	txm := payment(c, 0x00020000, 7000000, `{"TransactionResult": "tesSUCCESS", "AffectedNodes": [], "DeliveredAmount": {"currency": "USD", "issuer": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "value": "0.02"}}`)
+	b, err := json.Marshal(txm.MetaData)
+	c.Assert(err, IsNil)
+	var meta MetaData
+	c.Assert(json.Unmarshal(b, &meta), IsNil)
+	c.Assert(meta.DeliveredAmount, NotNil)
+	c.Check(meta.DeliveredAmount.String(), Equals, "0.02/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+}
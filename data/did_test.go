@@ -0,0 +1,70 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type DIDSuite struct{}
+
+var _ = Suite(&DIDSuite{})
+
+const didEntry = `[{
+	"Account": "rpfqJrXg5uidNo2ZsRhRY6TiF1cvYmV9Fg",
+	"DIDDocument": "646F63",
+	"Data": "617474657374",
+	"Flags": 0,
+	"LedgerEntryType": "DID",
+	"OwnerNode": "0",
+	"PreviousTxnID": "A4C15DA185E6092DF5954FF62A1446220C61A5F60F0D93B4B09F708778E41120",
+	"PreviousTxnLgrSeq": 4,
+	"URI": "6469645F6578616D706C65",
+	"index": "46813BE38B798B3752CA590D44E7FEADB17485649074403AD1761A2835CE91FF"
+}]`
+
+func (s *DIDSuite) TestDIDEntry(c *C) {
+	var entries LedgerEntrySlice
+	c.Assert(json.Unmarshal([]byte(didEntry), &entries), IsNil)
+	c.Assert(entries, HasLen, 1)
+	did, ok := entries[0].(*DIDEntry)
+	c.Assert(ok, Equals, true)
+	c.Check(string(*did.URI), Equals, "did_example")
+	c.Check(string(*did.Data), Equals, "attest")
+	index, err := LedgerIndex(did)
+	c.Assert(err, IsNil)
+	c.Check(*index, Equals, *did.LedgerIndex)
+
+	var buf bytes.Buffer
+	c.Assert(encode(&buf, did, false), IsNil)
+	node := append([]byte{0x11, 0x00, byte(DID)}, buf.Bytes()...)
+	node = append(node, did.LedgerIndex[:]...)
+	le, err := ReadLedgerEntry(bytes.NewReader(node), *did.LedgerIndex)
+	c.Assert(err, IsNil)
+	decoded, ok := le.(*DIDEntry)
+	c.Assert(ok, Equals, true)
+	c.Check(decoded.DIDDocument, DeepEquals, did.DIDDocument)
+	c.Check(decoded.URI, DeepEquals, did.URI)
+	c.Check(decoded.Data, DeepEquals, did.Data)
+	c.Check(decoded.Affects(*did.Account), Equals, true)
+
+	b, err := json.Marshal(decoded)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Matches, `\{"LedgerEntryType":"DID",.*"DIDDocument":"646F63","URI":"6469645F6578616D706C65","Data":"617474657374".*`)
+}
+
+func (s *DIDSuite) TestDIDTransactions(c *C) {
+	for _, test := range []string{
+		`{"TransactionType":"DIDSet","Account":"rpfqJrXg5uidNo2ZsRhRY6TiF1cvYmV9Fg","Fee":"10","Sequence":3,"DIDDocument":"646F63","URI":"6469645F6578616D706C65"}`,
+		`{"TransactionType":"DIDDelete","Account":"rpfqJrXg5uidNo2ZsRhRY6TiF1cvYmV9Fg","Fee":"10","Sequence":4}`,
+	} {
+		var txm TransactionWithMetaData
+		c.Assert(json.Unmarshal([]byte(test), &txm), IsNil, Commentf(test))
+		_, raw, err := Raw(txm.Transaction)
+		c.Assert(err, IsNil, Commentf(test))
+		decoded, err := ReadTransaction(bytes.NewReader(raw))
+		c.Assert(err, IsNil, Commentf(test))
+		c.Check(decoded, DeepEquals, txm.Transaction, Commentf(test))
+	}
+}
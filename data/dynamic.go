@@ -0,0 +1,304 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DynamicFields holds, by name, the fields of a transaction or ledger
+// entry which its struct has no field for, such as those registered from
+// definitions. Objects are DynamicFields and arrays are []DynamicFields
+// whose members each hold a single object, so that
+//
+//	"Entries": [{"Entry": {"Owner": "r..."}}]
+//
+// is []DynamicFields{{"Entry": DynamicFields{"Owner": Account{...}}}}.
+// Other values have the type the field is read as: uint8, uint16, uint32,
+// Uint64Hex, Hash128, Hash160, Hash256, Amount, VariableLength, Account,
+// PathSet, Vector256, Asset, XChainBridge or Currency.
+type DynamicFields map[string]interface{}
+
+var dynamicFieldsType = reflect.TypeOf(DynamicFields(nil))
+
+var dynamicTypes = map[uint8]reflect.Type{
+	ST_UINT8:     reflect.TypeOf(uint8(0)),
+	ST_UINT16:    reflect.TypeOf(uint16(0)),
+	ST_UINT32:    reflect.TypeOf(uint32(0)),
+	ST_UINT64:    reflect.TypeOf(Uint64Hex(0)),
+	ST_HASH128:   reflect.TypeOf(Hash128{}),
+	ST_HASH160:   reflect.TypeOf(Hash160{}),
+	ST_HASH256:   reflect.TypeOf(Hash256{}),
+	ST_AMOUNT:    reflect.TypeOf(Amount{}),
+	ST_VL:        reflect.TypeOf(VariableLength(nil)),
+	ST_ACCOUNT:   reflect.TypeOf(Account{}),
+	ST_PATHSET:   reflect.TypeOf(PathSet(nil)),
+	ST_VECTOR256: reflect.TypeOf(Vector256(nil)),
+	ST_ISSUE:     reflect.TypeOf(Asset{}),
+	ST_BRIDGE:    reflect.TypeOf(XChainBridge{}),
+	ST_CURRENCY:  reflect.TypeOf(Currency{}),
+}
+
+// DynamicTransaction is a transaction type registered from definitions,
+// all of whose own fields are in Dynamic
+type DynamicTransaction struct {
+	TxBase
+}
+
+// DynamicLedgerEntry is a ledger entry type registered from definitions,
+// all of whose own fields are in Dynamic
+type DynamicLedgerEntry struct {
+	leBase
+}
+
+// Affects reports whether any account field of the entry is account
+func (le *DynamicLedgerEntry) Affects(account Account) bool {
+	for _, value := range le.Dynamic {
+		if a, ok := value.(Account); ok && a == account {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *DynamicTransaction) MarshalJSON() ([]byte, error) {
+	return marshalDynamic(&t.TxBase, t.Dynamic)
+}
+
+func (t *DynamicTransaction) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &t.TxBase); err != nil {
+		return err
+	}
+	return unmarshalDynamic(b, reflect.TypeOf(t.TxBase), &t.Dynamic)
+}
+
+func (le *DynamicLedgerEntry) MarshalJSON() ([]byte, error) {
+	return marshalDynamic(&le.leBase, le.Dynamic)
+}
+
+func (le *DynamicLedgerEntry) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &le.leBase); err != nil {
+		return err
+	}
+	return unmarshalDynamic(b, reflect.TypeOf(le.leBase), &le.Dynamic)
+}
+
+// marshalDynamic writes the fields of base and dynamic as one object
+func marshalDynamic(base interface{}, dynamic DynamicFields) ([]byte, error) {
+	b, err := json.Marshal(base)
+	if err != nil || len(dynamic) == 0 {
+		return b, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range dynamic {
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
+
+// unmarshalDynamic reads the fields in b which are neither in base nor
+// unknown, such as "hash" or "meta", into dynamic
+func unmarshalDynamic(b []byte, base reflect.Type, dynamic *DynamicFields) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	known := make(map[string]bool)
+	for i := 0; i < base.NumField(); i++ {
+		name := strings.Split(base.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = base.Field(i).Name
+		}
+		known[name] = true
+	}
+	for name, value := range raw {
+		if _, ok := reverseEncodings[name]; !ok || known[name] {
+			continue
+		}
+		v, err := dynamicFromJSON(name, value)
+		if err != nil {
+			return err
+		}
+		if *dynamic == nil {
+			*dynamic = make(DynamicFields)
+		}
+		(*dynamic)[name] = v
+	}
+	return nil
+}
+
+func (d *DynamicFields) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*d = make(DynamicFields, len(raw))
+	for name, value := range raw {
+		v, err := dynamicFromJSON(name, value)
+		if err != nil {
+			return err
+		}
+		(*d)[name] = v
+	}
+	return nil
+}
+
+func dynamicFromJSON(name string, raw json.RawMessage) (interface{}, error) {
+	e, ok := reverseEncodings[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown field: %s", name)
+	}
+	switch e.typ {
+	case ST_OBJECT:
+		var fields DynamicFields
+		return fields, json.Unmarshal(raw, &fields)
+	case ST_ARRAY:
+		var array []DynamicFields
+		return array, json.Unmarshal(raw, &array)
+	}
+	typ, ok := dynamicTypes[e.typ]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported type of field: %s", name)
+	}
+	v := reflect.New(typ)
+	if err := json.Unmarshal(raw, v.Interface()); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return v.Elem().Interface(), nil
+}
+
+// getDynamicFields returns the DynamicFields of the struct v points to,
+// creating them if need be, when it has them
+func getDynamicFields(v *reflect.Value) (DynamicFields, bool) {
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, false
+	}
+	field := v.Elem().FieldByName("Dynamic")
+	if !field.IsValid() || field.Type() != dynamicFieldsType || !field.CanSet() {
+		return nil, false
+	}
+	if field.IsNil() {
+		field.Set(reflect.MakeMap(dynamicFieldsType))
+	}
+	return field.Interface().(DynamicFields), true
+}
+
+// readDynamicField reads a field which the struct v points to has no
+// field of its own for into its DynamicFields, if it has them
+func readDynamicField(r Reader, v *reflect.Value, e enc) (bool, error) {
+	fields, ok := getDynamicFields(v)
+	if !ok {
+		return false, nil
+	}
+	switch name := encodings[e]; name {
+	case "LedgerEntryType", "TransactionType":
+		// These say what is being read, so can't be extra
+		return true, fmt.Errorf("Unexpected field: %s", name)
+	}
+	return true, readDynamic(r, e, fields)
+}
+
+// readDynamic reads the value of the field e into fields
+func readDynamic(r Reader, e enc, fields DynamicFields) error {
+	name, ok := encodings[e]
+	if !ok {
+		return fmt.Errorf("Unknown field: %+v", e)
+	}
+	switch e.typ {
+	case ST_OBJECT:
+		object := make(DynamicFields)
+		if err := readDynamicObject(r, object); err != errorEndOfObject {
+			return err
+		}
+		fields[name] = object
+		return nil
+	case ST_ARRAY:
+		var array []DynamicFields
+		for {
+			child, err := readEncoding(r)
+			switch {
+			case err != nil:
+				return err
+			case encodings[*child] == "EndOfArray":
+				fields[name] = array
+				return nil
+			case child.typ != ST_OBJECT:
+				return fmt.Errorf("Unexpected field: %s in array: %s", encodings[*child], name)
+			}
+			object := make(DynamicFields)
+			if err := readDynamic(r, *child, object); err != nil {
+				return err
+			}
+			array = append(array, object)
+		}
+	}
+	typ, ok := dynamicTypes[e.typ]
+	if !ok {
+		return fmt.Errorf("Unsupported type of field: %s", name)
+	}
+	v := reflect.New(typ)
+	switch w := v.Interface().(type) {
+	case Wire:
+		if err := w.Unmarshal(r); err != nil {
+			return err
+		}
+	default:
+		if err := read(r, w); err != nil {
+			return err
+		}
+	}
+	fields[name] = v.Elem().Interface()
+	return nil
+}
+
+// readDynamicObject reads fields until the end of the object, when it
+// returns errorEndOfObject
+func readDynamicObject(r Reader, fields DynamicFields) error {
+	for {
+		e, err := readEncoding(r)
+		if err != nil {
+			return err
+		}
+		if encodings[*e] == "EndOfObject" {
+			return errorEndOfObject
+		}
+		if err := readDynamic(r, *e, fields); err != nil {
+			return err
+		}
+	}
+}
+
+// getFields returns the fields to encode, skipping those with unknown
+// names
+func (d DynamicFields) getFields() fieldSlice {
+	var fields fieldSlice
+	for name, value := range d {
+		e, ok := reverseEncodings[name]
+		if !ok {
+			continue
+		}
+		switch v := value.(type) {
+		case DynamicFields:
+			children := v.getFields()
+			children.Append(reverseEncodings["EndOfObject"], nil, nil)
+			fields.Append(e, nil, children)
+		case []DynamicFields:
+			var children fieldSlice
+			for _, object := range v {
+				children = append(children, object.getFields()...)
+			}
+			children.Append(reverseEncodings["EndOfArray"], nil, nil)
+			fields.Append(e, nil, children)
+		default:
+			p := reflect.New(reflect.TypeOf(value))
+			p.Elem().Set(reflect.ValueOf(value))
+			fields.Append(e, p.Interface(), nil)
+		}
+	}
+	fields.Sort()
+	return fields
+}
@@ -3,6 +3,7 @@ package data
 import (
 	"bytes"
 	"crypto/sha512"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
@@ -23,6 +24,20 @@ func SigningHash(s Signer) (Hash256, []byte, error) {
 	return raw(s, s.SigningPrefix(), true)
 }
 
+// MultiSigningHash returns the hash signed by account when multisigning s,
+// and the message, without prefix, which Ed25519 signs instead
+func MultiSigningHash(s Signer, account Account) (Hash256, []byte, error) {
+	_, msg, err := raw(s, HP_TRANSACTION_MULTISIGN, true)
+	if err != nil {
+		return zero256, nil, err
+	}
+	msg = append(msg, account[:]...)
+	sum := sha512.Sum512(append(HP_TRANSACTION_MULTISIGN.Bytes(), msg...))
+	var hash Hash256
+	copy(hash[:], sum[:])
+	return hash, msg, nil
+}
+
 func Node(h Storer) (Hash256, []byte, error) {
 	var header bytes.Buffer
 	for _, v := range []interface{}{h.Ledger(), h.Ledger(), h.NodeType(), h.Prefix()} {
@@ -59,7 +74,7 @@ func writeRaw(w io.Writer, value interface{}, ignoreSigningFields bool) error {
 		return write(w, v.LedgerHeader)
 	case *InnerNode:
 		return write(w, v.Children)
-	case *Validation:
+	case *Validation, *Manifest:
 		return encode(w, value, ignoreSigningFields)
 	case *Proposal:
 		if ignoreSigningFields {
@@ -89,7 +104,7 @@ func writeRaw(w io.Writer, value interface{}, ignoreSigningFields bool) error {
 		if err := encode(w, v, ignoreSigningFields); err != nil {
 			return err
 		}
-		index, err := LedgerIndex(v)
+		index, err := entryIndex(v)
 		if err != nil {
 			return err
 		}
@@ -105,7 +120,7 @@ func encode(w io.Writer, value interface{}, ignoreSigningFields bool) error {
 	// fmt.Println(fields.String())
 	return fields.Each(func(e enc, v interface{}) error {
 		if ignoreSigningFields && e.SigningField() {
-			return nil
+			return errSkipField
 		}
 		if err := writeEncoding(w, e); err != nil {
 			return err
@@ -151,12 +166,17 @@ func getFields(v *reflect.Value, depth int) fieldSlice {
 		if fieldName == "Hash" || fieldName == "Id" {
 			continue
 		}
-		// Stops LedgerEntryType being encoded for Fields
-		if fieldName == "LedgerEntryType" && depth > 1 && typ.Name() == "leBase" {
+		// Stops LedgerEntryType being encoded for Fields, and the index,
+		// which is not a field of the entry
+		if typ.Name() == "leBase" && (fieldName == "LedgerIndex" || fieldName == "LedgerEntryType" && depth > 1) {
 			continue
 		}
 		encoding := reverseEncodings[fieldName]
 		f := v.Field(i)
+		if f.Type() == dynamicFieldsType {
+			fields = append(fields, f.Interface().(DynamicFields).getFields()...)
+			continue
+		}
 		// fmt.Println(fieldName, encoding, f, f.Kind())
 		if f.Kind() == reflect.Interface {
 			f = f.Elem()
@@ -164,18 +184,29 @@ func getFields(v *reflect.Value, depth int) fieldSlice {
 		if f.Kind() == reflect.Ptr {
 			f = f.Elem()
 		}
-		if !f.IsValid() || !f.CanInterface() || (f.Kind() == reflect.Slice && f.Len() == 0) {
+		// The exported fields of an unexported embedded struct, such as
+		// leBase, can still be encoded
+		if !f.IsValid() || (!f.CanInterface() && !typ.Field(i).Anonymous) || (f.Kind() == reflect.Slice && f.Len() == 0) {
 			continue
 		}
 		switch encoding.typ {
 		case ST_UINT8, ST_UINT16, ST_UINT32, ST_UINT64:
 			fields.Append(encoding, f.Addr().Interface(), nil)
-		case ST_HASH128, ST_HASH256, ST_AMOUNT, ST_VL, ST_ACCOUNT, ST_HASH160, ST_PATHSET, ST_VECTOR256:
+		case ST_HASH128, ST_HASH256, ST_AMOUNT, ST_VL, ST_ACCOUNT, ST_HASH160, ST_PATHSET, ST_VECTOR256, ST_ISSUE, ST_BRIDGE, ST_CURRENCY:
 			fields.Append(encoding, f.Addr().Interface(), nil)
 		case ST_ARRAY:
 			var children fieldSlice
 			for i := 0; i < f.Len(); i++ {
 				f2 := f.Index(i)
+				// Members such as SignerEntry are the object itself, rather
+				// than a wrapper with the object as its only field
+				object, ok := reverseEncodings[f2.Type().Name()]
+				if _, wrapper := f2.Type().FieldByName(f2.Type().Name()); ok && object.typ == ST_OBJECT && !wrapper {
+					inner := getFields(&f2, depth+1)
+					inner.Append(reverseEncodings["EndOfObject"], nil, nil)
+					children.Append(object, nil, inner)
+					continue
+				}
 				children = append(children, getFields(&f2, depth+1)...)
 			}
 			children.Append(reverseEncodings["EndOfArray"], nil, nil)
@@ -192,9 +223,16 @@ func getFields(v *reflect.Value, depth int) fieldSlice {
 	return fields
 }
 
+// Returned by the function passed to Each to skip a field and its children
+var errSkipField = errors.New("skip field")
+
 func (s fieldSlice) Each(f func(e enc, v interface{}) error) error {
 	for _, field := range s {
-		if err := f(field.encoding, field.value); err != nil {
+		switch err := f(field.encoding, field.value); err {
+		case nil:
+		case errSkipField:
+			continue
+		default:
 			return err
 		}
 		if err := field.children.Each(f); err != nil {
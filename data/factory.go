@@ -19,33 +19,82 @@ const (
 	FEE_SETTINGS     LedgerEntryType = 0x73 // 's'
 	ESCROW           LedgerEntryType = 0x75 // 'u'
 	PAY_CHANNEL      LedgerEntryType = 0x78 // 'x'
-	CHECK            LedgerEntryType = 0x63 // 'C'
+	CHECK            LedgerEntryType = 0x43 // 'C'
 	DEPOSIT_PRE_AUTH LedgerEntryType = 0x70 // 'p'
 	NEGATIVE_UNL     LedgerEntryType = 0x4e
+	NFTOKEN_PAGE     LedgerEntryType = 0x50 // 'P'
+	NFTOKEN_OFFER    LedgerEntryType = 0x37
+	AMM              LedgerEntryType = 0x79 // 'y'
+	DID              LedgerEntryType = 0x49 // 'I'
+	BRIDGE           LedgerEntryType = 0x69 // 'i'
+	ORACLE           LedgerEntryType = 0x80
+	CREDENTIAL       LedgerEntryType = 0x81
+
+	PERMISSIONED_DOMAIN LedgerEntryType = 0x82
+
+	XCHAIN_OWNED_CLAIM_ID                LedgerEntryType = 0x71 // 'q'
+	XCHAIN_OWNED_CREATE_ACCOUNT_CLAIM_ID LedgerEntryType = 0x74 // 't'
 
 	// TransactionType values come from rippled's "TxFormats.h"
-	PAYMENT         TransactionType = 0
-	ESCROW_CREATE   TransactionType = 1
-	ESCROW_FINISH   TransactionType = 2
-	ACCOUNT_SET     TransactionType = 3
-	ESCROW_CANCEL   TransactionType = 4
-	SET_REGULAR_KEY TransactionType = 5
-	OFFER_CREATE    TransactionType = 7
-	OFFER_CANCEL    TransactionType = 8
-	TICKET_CREATE   TransactionType = 10
-	TICKET_CANCEL   TransactionType = 11
-	SIGNER_LIST_SET TransactionType = 12
-	PAYCHAN_CREATE  TransactionType = 13
-	PAYCHAN_FUND    TransactionType = 14
-	PAYCHAN_CLAIM   TransactionType = 15
-	CHECK_CREATE    TransactionType = 16
-	CHECK_CASH      TransactionType = 17
-	CHECK_CANCEL    TransactionType = 18
-	TRUST_SET       TransactionType = 20
-	ACCOUNT_DELETE  TransactionType = 21
-	AMENDMENT       TransactionType = 100
-	SET_FEE         TransactionType = 101
-	UNL_MODIFY      TransactionType = 102
+	PAYMENT              TransactionType = 0
+	ESCROW_CREATE        TransactionType = 1
+	ESCROW_FINISH        TransactionType = 2
+	ACCOUNT_SET          TransactionType = 3
+	ESCROW_CANCEL        TransactionType = 4
+	SET_REGULAR_KEY      TransactionType = 5
+	OFFER_CREATE         TransactionType = 7
+	OFFER_CANCEL         TransactionType = 8
+	TICKET_CREATE        TransactionType = 10
+	TICKET_CANCEL        TransactionType = 11
+	SIGNER_LIST_SET      TransactionType = 12
+	PAYCHAN_CREATE       TransactionType = 13
+	PAYCHAN_FUND         TransactionType = 14
+	PAYCHAN_CLAIM        TransactionType = 15
+	CHECK_CREATE         TransactionType = 16
+	CHECK_CASH           TransactionType = 17
+	CHECK_CANCEL         TransactionType = 18
+	DEPOSIT_PREAUTH      TransactionType = 19
+	TRUST_SET            TransactionType = 20
+	ACCOUNT_DELETE       TransactionType = 21
+	NFTOKEN_MINT         TransactionType = 25
+	NFTOKEN_BURN         TransactionType = 26
+	NFTOKEN_CREATE_OFFER TransactionType = 27
+	NFTOKEN_CANCEL_OFFER TransactionType = 28
+	NFTOKEN_ACCEPT_OFFER TransactionType = 29
+	CLAWBACK             TransactionType = 30
+	AMM_CREATE           TransactionType = 35
+	AMM_DEPOSIT          TransactionType = 36
+	AMM_WITHDRAW         TransactionType = 37
+	AMM_VOTE             TransactionType = 38
+	AMM_BID              TransactionType = 39
+	AMM_DELETE           TransactionType = 40
+	DID_SET              TransactionType = 49
+	DID_DELETE           TransactionType = 50
+	ORACLE_SET           TransactionType = 51
+	ORACLE_DELETE        TransactionType = 52
+	CREDENTIAL_CREATE    TransactionType = 58
+	CREDENTIAL_ACCEPT    TransactionType = 59
+	CREDENTIAL_DELETE    TransactionType = 60
+	AMENDMENT            TransactionType = 100
+	SET_FEE              TransactionType = 101
+	UNL_MODIFY           TransactionType = 102
+
+	// Cross-chain bridge transactions
+	XCHAIN_CREATE_CLAIM_ID                TransactionType = 41
+	XCHAIN_COMMIT                         TransactionType = 42
+	XCHAIN_CLAIM                          TransactionType = 43
+	XCHAIN_ACCOUNT_CREATE_COMMIT          TransactionType = 44
+	XCHAIN_ADD_CLAIM_ATTESTATION          TransactionType = 45
+	XCHAIN_ADD_ACCOUNT_CREATE_ATTESTATION TransactionType = 46
+	XCHAIN_MODIFY_BRIDGE                  TransactionType = 47
+	XCHAIN_CREATE_BRIDGE                  TransactionType = 48
+
+	// Permissioned domain transactions
+	PERMISSIONED_DOMAIN_SET    TransactionType = 62
+	PERMISSIONED_DOMAIN_DELETE TransactionType = 63
+
+	// Registered by EnableBatch
+	BATCH TransactionType = 71
 )
 
 var LedgerFactory = [...]func() Hashable{
@@ -67,29 +116,99 @@ var LedgerEntryFactory = [...]func() LedgerEntry{
 	CHECK:            func() LedgerEntry { return &Check{leBase: leBase{LedgerEntryType: CHECK}} },
 	DEPOSIT_PRE_AUTH: func() LedgerEntry { return &DepositPreAuth{leBase: leBase{LedgerEntryType: DEPOSIT_PRE_AUTH}} },
 	NEGATIVE_UNL:     func() LedgerEntry { return &NegativeUNL{leBase: leBase{LedgerEntryType: NEGATIVE_UNL}} },
+	NFTOKEN_PAGE:     func() LedgerEntry { return &NFTokenPage{leBase: leBase{LedgerEntryType: NFTOKEN_PAGE}} },
+	NFTOKEN_OFFER:    func() LedgerEntry { return &NFTokenOffer{leBase: leBase{LedgerEntryType: NFTOKEN_OFFER}} },
+	AMM:              func() LedgerEntry { return &AMMEntry{leBase: leBase{LedgerEntryType: AMM}} },
+	DID:              func() LedgerEntry { return &DIDEntry{leBase: leBase{LedgerEntryType: DID}} },
+	BRIDGE:           func() LedgerEntry { return &Bridge{leBase: leBase{LedgerEntryType: BRIDGE}} },
+	ORACLE:           func() LedgerEntry { return &Oracle{leBase: leBase{LedgerEntryType: ORACLE}} },
+	CREDENTIAL:       func() LedgerEntry { return &Credential{leBase: leBase{LedgerEntryType: CREDENTIAL}} },
+
+	PERMISSIONED_DOMAIN: func() LedgerEntry {
+		return &PermissionedDomain{leBase: leBase{LedgerEntryType: PERMISSIONED_DOMAIN}}
+	},
+
+	XCHAIN_OWNED_CLAIM_ID: func() LedgerEntry {
+		return &XChainOwnedClaimID{leBase: leBase{LedgerEntryType: XCHAIN_OWNED_CLAIM_ID}}
+	},
+	XCHAIN_OWNED_CREATE_ACCOUNT_CLAIM_ID: func() LedgerEntry {
+		return &XChainOwnedCreateAccountClaimID{leBase: leBase{LedgerEntryType: XCHAIN_OWNED_CREATE_ACCOUNT_CLAIM_ID}}
+	},
 }
 
 var TxFactory = [...]func() Transaction{
-	PAYMENT:         func() Transaction { return &Payment{TxBase: TxBase{TransactionType: PAYMENT}} },
-	ACCOUNT_SET:     func() Transaction { return &AccountSet{TxBase: TxBase{TransactionType: ACCOUNT_SET}} },
-	SET_REGULAR_KEY: func() Transaction { return &SetRegularKey{TxBase: TxBase{TransactionType: SET_REGULAR_KEY}} },
-	OFFER_CREATE:    func() Transaction { return &OfferCreate{TxBase: TxBase{TransactionType: OFFER_CREATE}} },
-	OFFER_CANCEL:    func() Transaction { return &OfferCancel{TxBase: TxBase{TransactionType: OFFER_CANCEL}} },
-	TRUST_SET:       func() Transaction { return &TrustSet{TxBase: TxBase{TransactionType: TRUST_SET}} },
-	AMENDMENT:       func() Transaction { return &Amendment{TxBase: TxBase{TransactionType: AMENDMENT}} },
-	SET_FEE:         func() Transaction { return &SetFee{TxBase: TxBase{TransactionType: SET_FEE}} },
-	UNL_MODIFY:      func() Transaction { return &UNLModify{} },
-	ESCROW_CREATE:   func() Transaction { return &EscrowCreate{TxBase: TxBase{TransactionType: ESCROW_CREATE}} },
-	ESCROW_FINISH:   func() Transaction { return &EscrowFinish{TxBase: TxBase{TransactionType: ESCROW_FINISH}} },
-	ESCROW_CANCEL:   func() Transaction { return &EscrowCancel{TxBase: TxBase{TransactionType: ESCROW_CANCEL}} },
-	SIGNER_LIST_SET: func() Transaction { return &SignerListSet{TxBase: TxBase{TransactionType: SIGNER_LIST_SET}} },
-	PAYCHAN_CREATE:  func() Transaction { return &PaymentChannelCreate{TxBase: TxBase{TransactionType: PAYCHAN_CREATE}} },
-	PAYCHAN_FUND:    func() Transaction { return &PaymentChannelFund{TxBase: TxBase{TransactionType: PAYCHAN_FUND}} },
-	PAYCHAN_CLAIM:   func() Transaction { return &PaymentChannelClaim{TxBase: TxBase{TransactionType: PAYCHAN_CLAIM}} },
-	CHECK_CREATE:    func() Transaction { return &CheckCreate{TxBase: TxBase{TransactionType: CHECK_CREATE}} },
-	CHECK_CASH:      func() Transaction { return &CheckCash{TxBase: TxBase{TransactionType: CHECK_CASH}} },
-	CHECK_CANCEL:    func() Transaction { return &CheckCancel{TxBase: TxBase{TransactionType: CHECK_CANCEL}} },
-	ACCOUNT_DELETE:  func() Transaction { return &AccountDelete{TxBase: TxBase{TransactionType: ACCOUNT_DELETE}} },
+	PAYMENT:              func() Transaction { return &Payment{TxBase: TxBase{TransactionType: PAYMENT}} },
+	ACCOUNT_SET:          func() Transaction { return &AccountSet{TxBase: TxBase{TransactionType: ACCOUNT_SET}} },
+	SET_REGULAR_KEY:      func() Transaction { return &SetRegularKey{TxBase: TxBase{TransactionType: SET_REGULAR_KEY}} },
+	OFFER_CREATE:         func() Transaction { return &OfferCreate{TxBase: TxBase{TransactionType: OFFER_CREATE}} },
+	OFFER_CANCEL:         func() Transaction { return &OfferCancel{TxBase: TxBase{TransactionType: OFFER_CANCEL}} },
+	TRUST_SET:            func() Transaction { return &TrustSet{TxBase: TxBase{TransactionType: TRUST_SET}} },
+	AMENDMENT:            func() Transaction { return &Amendment{TxBase: TxBase{TransactionType: AMENDMENT}} },
+	SET_FEE:              func() Transaction { return &SetFee{TxBase: TxBase{TransactionType: SET_FEE}} },
+	UNL_MODIFY:           func() Transaction { return &UNLModify{} },
+	ESCROW_CREATE:        func() Transaction { return &EscrowCreate{TxBase: TxBase{TransactionType: ESCROW_CREATE}} },
+	ESCROW_FINISH:        func() Transaction { return &EscrowFinish{TxBase: TxBase{TransactionType: ESCROW_FINISH}} },
+	ESCROW_CANCEL:        func() Transaction { return &EscrowCancel{TxBase: TxBase{TransactionType: ESCROW_CANCEL}} },
+	SIGNER_LIST_SET:      func() Transaction { return &SignerListSet{TxBase: TxBase{TransactionType: SIGNER_LIST_SET}} },
+	PAYCHAN_CREATE:       func() Transaction { return &PaymentChannelCreate{TxBase: TxBase{TransactionType: PAYCHAN_CREATE}} },
+	PAYCHAN_FUND:         func() Transaction { return &PaymentChannelFund{TxBase: TxBase{TransactionType: PAYCHAN_FUND}} },
+	PAYCHAN_CLAIM:        func() Transaction { return &PaymentChannelClaim{TxBase: TxBase{TransactionType: PAYCHAN_CLAIM}} },
+	CHECK_CREATE:         func() Transaction { return &CheckCreate{TxBase: TxBase{TransactionType: CHECK_CREATE}} },
+	CHECK_CASH:           func() Transaction { return &CheckCash{TxBase: TxBase{TransactionType: CHECK_CASH}} },
+	CHECK_CANCEL:         func() Transaction { return &CheckCancel{TxBase: TxBase{TransactionType: CHECK_CANCEL}} },
+	DEPOSIT_PREAUTH:      func() Transaction { return &DepositPreauth{TxBase: TxBase{TransactionType: DEPOSIT_PREAUTH}} },
+	ACCOUNT_DELETE:       func() Transaction { return &AccountDelete{TxBase: TxBase{TransactionType: ACCOUNT_DELETE}} },
+	TICKET_CREATE:        func() Transaction { return &TicketCreate{TxBase: TxBase{TransactionType: TICKET_CREATE}} },
+	NFTOKEN_MINT:         func() Transaction { return &NFTokenMint{TxBase: TxBase{TransactionType: NFTOKEN_MINT}} },
+	NFTOKEN_BURN:         func() Transaction { return &NFTokenBurn{TxBase: TxBase{TransactionType: NFTOKEN_BURN}} },
+	NFTOKEN_CREATE_OFFER: func() Transaction { return &NFTokenCreateOffer{TxBase: TxBase{TransactionType: NFTOKEN_CREATE_OFFER}} },
+	NFTOKEN_CANCEL_OFFER: func() Transaction { return &NFTokenCancelOffer{TxBase: TxBase{TransactionType: NFTOKEN_CANCEL_OFFER}} },
+	NFTOKEN_ACCEPT_OFFER: func() Transaction { return &NFTokenAcceptOffer{TxBase: TxBase{TransactionType: NFTOKEN_ACCEPT_OFFER}} },
+	CLAWBACK:             func() Transaction { return &Clawback{TxBase: TxBase{TransactionType: CLAWBACK}} },
+	AMM_CREATE:           func() Transaction { return &AMMCreate{TxBase: TxBase{TransactionType: AMM_CREATE}} },
+	AMM_DEPOSIT:          func() Transaction { return &AMMDeposit{TxBase: TxBase{TransactionType: AMM_DEPOSIT}} },
+	AMM_WITHDRAW:         func() Transaction { return &AMMWithdraw{TxBase: TxBase{TransactionType: AMM_WITHDRAW}} },
+	AMM_VOTE:             func() Transaction { return &AMMVote{TxBase: TxBase{TransactionType: AMM_VOTE}} },
+	AMM_BID:              func() Transaction { return &AMMBid{TxBase: TxBase{TransactionType: AMM_BID}} },
+	AMM_DELETE:           func() Transaction { return &AMMDelete{TxBase: TxBase{TransactionType: AMM_DELETE}} },
+	DID_SET:              func() Transaction { return &DIDSet{TxBase: TxBase{TransactionType: DID_SET}} },
+	DID_DELETE:           func() Transaction { return &DIDDelete{TxBase: TxBase{TransactionType: DID_DELETE}} },
+	ORACLE_SET:           func() Transaction { return &OracleSet{TxBase: TxBase{TransactionType: ORACLE_SET}} },
+	ORACLE_DELETE:        func() Transaction { return &OracleDelete{TxBase: TxBase{TransactionType: ORACLE_DELETE}} },
+	CREDENTIAL_CREATE:    func() Transaction { return &CredentialCreate{TxBase: TxBase{TransactionType: CREDENTIAL_CREATE}} },
+	CREDENTIAL_ACCEPT:    func() Transaction { return &CredentialAccept{TxBase: TxBase{TransactionType: CREDENTIAL_ACCEPT}} },
+	CREDENTIAL_DELETE:    func() Transaction { return &CredentialDelete{TxBase: TxBase{TransactionType: CREDENTIAL_DELETE}} },
+
+	XCHAIN_CREATE_CLAIM_ID: func() Transaction {
+		return &XChainCreateClaimID{TxBase: TxBase{TransactionType: XCHAIN_CREATE_CLAIM_ID}}
+	},
+	XCHAIN_COMMIT: func() Transaction {
+		return &XChainCommit{TxBase: TxBase{TransactionType: XCHAIN_COMMIT}}
+	},
+	XCHAIN_CLAIM: func() Transaction {
+		return &XChainClaim{TxBase: TxBase{TransactionType: XCHAIN_CLAIM}}
+	},
+	XCHAIN_ACCOUNT_CREATE_COMMIT: func() Transaction {
+		return &XChainAccountCreateCommit{TxBase: TxBase{TransactionType: XCHAIN_ACCOUNT_CREATE_COMMIT}}
+	},
+	XCHAIN_ADD_CLAIM_ATTESTATION: func() Transaction {
+		return &XChainAddClaimAttestation{TxBase: TxBase{TransactionType: XCHAIN_ADD_CLAIM_ATTESTATION}}
+	},
+	XCHAIN_ADD_ACCOUNT_CREATE_ATTESTATION: func() Transaction {
+		return &XChainAddAccountCreateAttestation{TxBase: TxBase{TransactionType: XCHAIN_ADD_ACCOUNT_CREATE_ATTESTATION}}
+	},
+	XCHAIN_MODIFY_BRIDGE: func() Transaction {
+		return &XChainModifyBridge{TxBase: TxBase{TransactionType: XCHAIN_MODIFY_BRIDGE}}
+	},
+	XCHAIN_CREATE_BRIDGE: func() Transaction {
+		return &XChainCreateBridge{TxBase: TxBase{TransactionType: XCHAIN_CREATE_BRIDGE}}
+	},
+	PERMISSIONED_DOMAIN_SET: func() Transaction {
+		return &PermissionedDomainSet{TxBase: TxBase{TransactionType: PERMISSIONED_DOMAIN_SET}}
+	},
+	PERMISSIONED_DOMAIN_DELETE: func() Transaction {
+		return &PermissionedDomainDelete{TxBase: TxBase{TransactionType: PERMISSIONED_DOMAIN_DELETE}}
+	},
 }
 
 var ledgerEntryNames = [...]string{
@@ -105,8 +224,20 @@ var ledgerEntryNames = [...]string{
 	TICKET:           "Ticket",
 	PAY_CHANNEL:      "PayChannel",
 	CHECK:            "Check",
-	DEPOSIT_PRE_AUTH: "DepositPreAuth",
+	DEPOSIT_PRE_AUTH: "DepositPreauth",
 	NEGATIVE_UNL:     "NegativeUNL",
+	NFTOKEN_PAGE:     "NFTokenPage",
+	NFTOKEN_OFFER:    "NFTokenOffer",
+	AMM:              "AMM",
+	DID:              "DID",
+	BRIDGE:           "Bridge",
+	ORACLE:           "Oracle",
+	CREDENTIAL:       "Credential",
+
+	PERMISSIONED_DOMAIN: "PermissionedDomain",
+
+	XCHAIN_OWNED_CLAIM_ID:                "XChainOwnedClaimID",
+	XCHAIN_OWNED_CREATE_ACCOUNT_CLAIM_ID: "XChainOwnedCreateAccountClaimID",
 }
 
 var ledgerEntryTypes = map[string]LedgerEntryType{
@@ -122,31 +253,77 @@ var ledgerEntryTypes = map[string]LedgerEntryType{
 	"Ticket":         TICKET,
 	"PayChannel":     PAY_CHANNEL,
 	"Check":          CHECK,
-	"DepositPreAuth": DEPOSIT_PRE_AUTH,
+	"DepositPreauth": DEPOSIT_PRE_AUTH,
+	"DepositPreAuth": DEPOSIT_PRE_AUTH, // As this package used to spell it
 	"NegativeUNL":    NEGATIVE_UNL,
+	"NFTokenPage":    NFTOKEN_PAGE,
+	"NFTokenOffer":   NFTOKEN_OFFER,
+	"AMM":            AMM,
+	"DID":            DID,
+	"Bridge":         BRIDGE,
+	"Oracle":         ORACLE,
+	"Credential":     CREDENTIAL,
+
+	"PermissionedDomain": PERMISSIONED_DOMAIN,
+
+	"XChainOwnedClaimID":              XCHAIN_OWNED_CLAIM_ID,
+	"XChainOwnedCreateAccountClaimID": XCHAIN_OWNED_CREATE_ACCOUNT_CLAIM_ID,
 }
 
 var txNames = [...]string{
-	PAYMENT:         "Payment",
-	ACCOUNT_SET:     "AccountSet",
-	SET_REGULAR_KEY: "SetRegularKey",
-	OFFER_CREATE:    "OfferCreate",
-	OFFER_CANCEL:    "OfferCancel",
-	TRUST_SET:       "TrustSet",
-	AMENDMENT:       "EnableAmendment",
-	SET_FEE:         "SetFee",
-	ESCROW_CREATE:   "EscrowCreate",
-	ESCROW_FINISH:   "EscrowFinish",
-	ESCROW_CANCEL:   "EscrowCancel",
-	SIGNER_LIST_SET: "SignerListSet",
-	PAYCHAN_CREATE:  "PaymentChannelCreate",
-	PAYCHAN_FUND:    "PaymentChannelFund",
-	PAYCHAN_CLAIM:   "PaymentChannelClaim",
-	CHECK_CREATE:    "CheckCreate",
-	CHECK_CASH:      "CheckCash",
-	CHECK_CANCEL:    "CheckCancel",
-	ACCOUNT_DELETE:  "AccountDelete",
-	UNL_MODIFY:      "UNLModify",
+	PAYMENT:              "Payment",
+	ACCOUNT_SET:          "AccountSet",
+	SET_REGULAR_KEY:      "SetRegularKey",
+	OFFER_CREATE:         "OfferCreate",
+	OFFER_CANCEL:         "OfferCancel",
+	TRUST_SET:            "TrustSet",
+	AMENDMENT:            "EnableAmendment",
+	SET_FEE:              "SetFee",
+	ESCROW_CREATE:        "EscrowCreate",
+	ESCROW_FINISH:        "EscrowFinish",
+	ESCROW_CANCEL:        "EscrowCancel",
+	SIGNER_LIST_SET:      "SignerListSet",
+	PAYCHAN_CREATE:       "PaymentChannelCreate",
+	PAYCHAN_FUND:         "PaymentChannelFund",
+	PAYCHAN_CLAIM:        "PaymentChannelClaim",
+	CHECK_CREATE:         "CheckCreate",
+	CHECK_CASH:           "CheckCash",
+	CHECK_CANCEL:         "CheckCancel",
+	DEPOSIT_PREAUTH:      "DepositPreauth",
+	ACCOUNT_DELETE:       "AccountDelete",
+	TICKET_CREATE:        "TicketCreate",
+	UNL_MODIFY:           "UNLModify",
+	NFTOKEN_MINT:         "NFTokenMint",
+	NFTOKEN_BURN:         "NFTokenBurn",
+	NFTOKEN_CREATE_OFFER: "NFTokenCreateOffer",
+	NFTOKEN_CANCEL_OFFER: "NFTokenCancelOffer",
+	NFTOKEN_ACCEPT_OFFER: "NFTokenAcceptOffer",
+	CLAWBACK:             "Clawback",
+	AMM_CREATE:           "AMMCreate",
+	AMM_DEPOSIT:          "AMMDeposit",
+	AMM_WITHDRAW:         "AMMWithdraw",
+	AMM_VOTE:             "AMMVote",
+	AMM_BID:              "AMMBid",
+	AMM_DELETE:           "AMMDelete",
+	DID_SET:              "DIDSet",
+	DID_DELETE:           "DIDDelete",
+	ORACLE_SET:           "OracleSet",
+	ORACLE_DELETE:        "OracleDelete",
+	CREDENTIAL_CREATE:    "CredentialCreate",
+	CREDENTIAL_ACCEPT:    "CredentialAccept",
+	CREDENTIAL_DELETE:    "CredentialDelete",
+
+	XCHAIN_CREATE_CLAIM_ID:                "XChainCreateClaimID",
+	XCHAIN_COMMIT:                         "XChainCommit",
+	XCHAIN_CLAIM:                          "XChainClaim",
+	XCHAIN_ACCOUNT_CREATE_COMMIT:          "XChainAccountCreateCommit",
+	XCHAIN_ADD_CLAIM_ATTESTATION:          "XChainAddClaimAttestation",
+	XCHAIN_ADD_ACCOUNT_CREATE_ATTESTATION: "XChainAddAccountCreateAttestation",
+	XCHAIN_MODIFY_BRIDGE:                  "XChainModifyBridge",
+	XCHAIN_CREATE_BRIDGE:                  "XChainCreateBridge",
+
+	PERMISSIONED_DOMAIN_SET:    "PermissionedDomainSet",
+	PERMISSIONED_DOMAIN_DELETE: "PermissionedDomainDelete",
 }
 
 var txTypes = map[string]TransactionType{
@@ -168,8 +345,41 @@ var txTypes = map[string]TransactionType{
 	"CheckCreate":          CHECK_CREATE,
 	"CheckCash":            CHECK_CASH,
 	"CheckCancel":          CHECK_CANCEL,
+	"DepositPreauth":       DEPOSIT_PREAUTH,
 	"AccountDelete":        ACCOUNT_DELETE,
+	"TicketCreate":         TICKET_CREATE,
 	"UNLModify":            UNL_MODIFY,
+	"NFTokenMint":          NFTOKEN_MINT,
+	"NFTokenBurn":          NFTOKEN_BURN,
+	"NFTokenCreateOffer":   NFTOKEN_CREATE_OFFER,
+	"NFTokenCancelOffer":   NFTOKEN_CANCEL_OFFER,
+	"NFTokenAcceptOffer":   NFTOKEN_ACCEPT_OFFER,
+	"Clawback":             CLAWBACK,
+	"AMMCreate":            AMM_CREATE,
+	"AMMDeposit":           AMM_DEPOSIT,
+	"AMMWithdraw":          AMM_WITHDRAW,
+	"AMMVote":              AMM_VOTE,
+	"AMMBid":               AMM_BID,
+	"AMMDelete":            AMM_DELETE,
+	"DIDSet":               DID_SET,
+	"DIDDelete":            DID_DELETE,
+	"OracleSet":            ORACLE_SET,
+	"OracleDelete":         ORACLE_DELETE,
+	"CredentialCreate":     CREDENTIAL_CREATE,
+	"CredentialAccept":     CREDENTIAL_ACCEPT,
+	"CredentialDelete":     CREDENTIAL_DELETE,
+
+	"XChainCreateClaimID":               XCHAIN_CREATE_CLAIM_ID,
+	"XChainCommit":                      XCHAIN_COMMIT,
+	"XChainClaim":                       XCHAIN_CLAIM,
+	"XChainAccountCreateCommit":         XCHAIN_ACCOUNT_CREATE_COMMIT,
+	"XChainAddClaimAttestation":         XCHAIN_ADD_CLAIM_ATTESTATION,
+	"XChainAddAccountCreateAttestation": XCHAIN_ADD_ACCOUNT_CREATE_ATTESTATION,
+	"XChainModifyBridge":                XCHAIN_MODIFY_BRIDGE,
+	"XChainCreateBridge":                XCHAIN_CREATE_BRIDGE,
+
+	"PermissionedDomainSet":    PERMISSIONED_DOMAIN_SET,
+	"PermissionedDomainDelete": PERMISSIONED_DOMAIN_DELETE,
 }
 
 var HashableTypes []string
@@ -189,11 +399,19 @@ func init() {
 	}
 }
 
+// String returns the name of the type, or "" if it is unknown
 func (t TransactionType) String() string {
+	if int(t) >= len(txNames) {
+		return ""
+	}
 	return txNames[t]
 }
 
+// String returns the name of the type, or "" if it is unknown
 func (le LedgerEntryType) String() string {
+	if int(le) >= len(ledgerEntryNames) {
+		return ""
+	}
 	return ledgerEntryNames[le]
 }
 
@@ -11,6 +11,7 @@ type LedgerEntryFlag uint32
 const (
 	//Universal flags
 	TxCanonicalSignature TransactionFlag = 0x80000000
+	TxInnerBatch         TransactionFlag = 0x40000000 // Set on the inner transactions of a Batch
 
 	// Payment flags
 	TxNoDirectRipple TransactionFlag = 0x00010000
@@ -27,6 +28,9 @@ const (
 	TxNoFreeze         TransactionFlag = 0x00000006
 	TxGlobalFreeze     TransactionFlag = 0x00000007
 	TxDefaultRipple    TransactionFlag = 0x00000008
+	TxDepositAuth      TransactionFlag = 0x00000009
+	TxNFTokenMinter    TransactionFlag = 0x0000000A // asfAuthorizedNFTokenMinter, with the NFTokenMinter
+	TxAllowClawback    TransactionFlag = 0x00000010 // Only while the account owns nothing
 	TxRequireDestTag   TransactionFlag = 0x00010000
 	TxOptionalDestTag  TransactionFlag = 0x00020000
 	TxRequireAuth      TransactionFlag = 0x00040000
@@ -54,6 +58,36 @@ const (
 	// PaymentChannelClaim flags
 	TxRenew TransactionFlag = 0x00010000
 	TxClose TransactionFlag = 0x00020000
+
+	// NFTokenMint flags, which become the flags of the token
+	TxBurnable     TransactionFlag = 0x00000001
+	TxOnlyXRP      TransactionFlag = 0x00000002
+	TxTrustLine    TransactionFlag = 0x00000004
+	TxTransferable TransactionFlag = 0x00000008
+
+	// NFTokenCreateOffer flags
+	TxSellNFToken TransactionFlag = 0x00000001
+
+	// AMMDeposit and AMMWithdraw flags, which choose how the amounts are
+	// deposited or withdrawn. Exactly one must be set.
+	TxLPToken             TransactionFlag = 0x00010000
+	TxWithdrawAll         TransactionFlag = 0x00020000 // AMMWithdraw only
+	TxOneAssetWithdrawAll TransactionFlag = 0x00040000 // AMMWithdraw only
+	TxSingleAsset         TransactionFlag = 0x00080000
+	TxTwoAsset            TransactionFlag = 0x00100000
+	TxOneAssetLPToken     TransactionFlag = 0x00200000
+	TxLimitLPToken        TransactionFlag = 0x00400000
+	TxTwoAssetIfEmpty     TransactionFlag = 0x00800000 // AMMDeposit only
+
+	// XChainModifyBridge flags
+	TxClearAccountCreateAmount TransactionFlag = 0x00010000
+
+	// Batch flags, which decide which inner transactions are applied.
+	// Exactly one must be set.
+	TxAllOrNothing TransactionFlag = 0x00010000
+	TxOnlyOne      TransactionFlag = 0x00020000
+	TxUntilFailure TransactionFlag = 0x00040000
+	TxIndependent  TransactionFlag = 0x00080000
 )
 
 // Ledger entry flags
@@ -67,6 +101,8 @@ const (
 	LsNoFreeze       LedgerEntryFlag = 0x00200000
 	LsGlobalFreeze   LedgerEntryFlag = 0x00400000
 	LsDefaultRipple  LedgerEntryFlag = 0x00800000
+	LsDepositAuth    LedgerEntryFlag = 0x01000000
+	LsAllowClawback  LedgerEntryFlag = 0x80000000 // lsfAllowTrustLineClawback
 
 	// Offer flags
 	LsPassive LedgerEntryFlag = 0x00010000
@@ -81,6 +117,12 @@ const (
 	LsHighNoRipple LedgerEntryFlag = 0x00200000
 	LsLowFreeze    LedgerEntryFlag = 0x00400000
 	LsHighFreeze   LedgerEntryFlag = 0x00800000
+
+	// NFTokenOffer flags
+	LsSellNFToken LedgerEntryFlag = 0x00000001
+
+	// Credential flags
+	LsAccepted LedgerEntryFlag = 0x00010000
 )
 
 var txFlagNames = map[TransactionType][]struct {
@@ -112,6 +154,41 @@ var txFlagNames = map[TransactionType][]struct {
 		{TxFillOrKill, "FillOrKill"},
 		{TxSell, "Sell"},
 	},
+	NFTOKEN_MINT: {
+		{TxBurnable, "Burnable"},
+		{TxOnlyXRP, "OnlyXRP"},
+		{TxTrustLine, "TrustLine"},
+		{TxTransferable, "Transferable"},
+	},
+	NFTOKEN_CREATE_OFFER: {
+		{TxSellNFToken, "SellNFToken"},
+	},
+	AMM_DEPOSIT: {
+		{TxLPToken, "LPToken"},
+		{TxSingleAsset, "SingleAsset"},
+		{TxTwoAsset, "TwoAsset"},
+		{TxOneAssetLPToken, "OneAssetLPToken"},
+		{TxLimitLPToken, "LimitLPToken"},
+		{TxTwoAssetIfEmpty, "TwoAssetIfEmpty"},
+	},
+	AMM_WITHDRAW: {
+		{TxLPToken, "LPToken"},
+		{TxWithdrawAll, "WithdrawAll"},
+		{TxOneAssetWithdrawAll, "OneAssetWithdrawAll"},
+		{TxSingleAsset, "SingleAsset"},
+		{TxTwoAsset, "TwoAsset"},
+		{TxOneAssetLPToken, "OneAssetLPToken"},
+		{TxLimitLPToken, "LimitLPToken"},
+	},
+	XCHAIN_MODIFY_BRIDGE: {
+		{TxClearAccountCreateAmount, "ClearAccountCreateAmount"},
+	},
+	BATCH: {
+		{TxAllOrNothing, "AllOrNothing"},
+		{TxOnlyOne, "OnlyOne"},
+		{TxUntilFailure, "UntilFailure"},
+		{TxIndependent, "Independent"},
+	},
 	TRUST_SET: {
 		{TxSetAuth, "SetAuth"},
 		{TxSetNoRipple, "SetNoRipple"},
@@ -132,6 +209,8 @@ var leFlagNames = map[LedgerEntryType][]struct {
 		{LsDisallowXRP, "DisallowXRP"},
 		{LsDisableMaster, "DisableMaster"},
 		{LsNoFreeze, "NoFreeze"},
+		{LsDepositAuth, "DepositAuth"},
+		{LsAllowClawback, "AllowTrustLineClawback"},
 	},
 	OFFER: {
 		{LsPassive, "Passive"},
@@ -147,6 +226,12 @@ var leFlagNames = map[LedgerEntryType][]struct {
 		{LsLowFreeze, "LowFreeze"},
 		{LsHighFreeze, "HighFreeze"},
 	},
+	NFTOKEN_OFFER: {
+		{LsSellNFToken, "SellNFToken"},
+	},
+	CREDENTIAL: {
+		{LsAccepted, "Accepted"},
+	},
 }
 
 func (f TransactionFlag) String() string {
@@ -162,6 +247,9 @@ func (f TransactionFlag) Explain(tx Transaction) []string {
 	if f&TxCanonicalSignature > 0 {
 		flags = append(flags, "CanonicalSignature")
 	}
+	if f&TxInnerBatch > 0 {
+		flags = append(flags, "InnerBatchTxn")
+	}
 	for _, n := range txFlagNames[tx.GetTransactionType()] {
 		if f&n.Flag > 0 {
 			flags = append(flags, n.Name)
@@ -22,6 +22,10 @@ const (
 	HP_TRANSACTION_SIGN HashPrefix = 0x53545800 // 'STX' inner transaction to sign
 	HP_VALIDATION       HashPrefix = 0x56414C00 // 'VAL' validation for signing
 	HP_PROPOSAL         HashPrefix = 0x50525000 // 'PRP' proposal for signing
+	HP_MANIFEST         HashPrefix = 0x4D414E00 // 'MAN' manifest for signing
+
+	HP_TRANSACTION_MULTISIGN HashPrefix = 0x534D5400 // 'SMT' inner transaction to multisign
+	HP_BATCH                 HashPrefix = 0x42434800 // 'BCH' inner transactions of a batch for signing
 
 	// Node Types
 	NT_UNKNOWN          NodeType = 0
@@ -49,6 +53,15 @@ const (
 	NS_TICKET          LedgerNamespace = 'T'
 	NS_SIGNER_LIST     LedgerNamespace = 'S'
 	NS_XRPU_CHANNEL    LedgerNamespace = 'x'
+	NS_NFTOKEN_OFFER   LedgerNamespace = 'q'
+	NS_AMM             LedgerNamespace = 'A'
+	NS_DID             LedgerNamespace = 'I'
+	NS_ORACLE          LedgerNamespace = 'R'
+	NS_CHECK           LedgerNamespace = 'C'
+	NS_DEPOSIT_PREAUTH LedgerNamespace = 'p'
+	NS_CREDENTIAL      LedgerNamespace = 'D'
+
+	NS_PERMISSIONED_DOMAIN LedgerNamespace = 'm'
 )
 
 var nodeTypes = [...]string{
@@ -84,6 +97,9 @@ const (
 	ST_HASH160   uint8 = 17
 	ST_PATHSET   uint8 = 18
 	ST_VECTOR256 uint8 = 19
+	ST_ISSUE     uint8 = 24
+	ST_BRIDGE    uint8 = 25
+	ST_CURRENCY  uint8 = 26
 )
 
 // See rippled's SField.cpp for the strings and corresponding encoding values.
@@ -92,9 +108,13 @@ var encodings = map[enc]string{
 	enc{ST_UINT16, 1}: "LedgerEntryType",
 	enc{ST_UINT16, 2}: "TransactionType",
 	enc{ST_UINT16, 3}: "SignerWeight",
+	enc{ST_UINT16, 4}: "TransferFee",
+	enc{ST_UINT16, 5}: "TradingFee",
+	enc{ST_UINT16, 6}: "DiscountedFee",
 	// 16-bit unsigned integers (uncommon)
 	enc{ST_UINT16, 16}: "Version",
 	// 32-bit unsigned integers (common)
+	enc{ST_UINT32, 1}:  "NetworkID",
 	enc{ST_UINT32, 2}:  "Flags",
 	enc{ST_UINT32, 3}:  "SourceTag",
 	enc{ST_UINT32, 4}:  "Sequence",
@@ -108,6 +128,7 @@ var encodings = map[enc]string{
 	enc{ST_UINT32, 12}: "WalletSize",
 	enc{ST_UINT32, 13}: "OwnerCount",
 	enc{ST_UINT32, 14}: "DestinationTag",
+	enc{ST_UINT32, 15}: "LastUpdateTime",
 	// 32-bit unsigned integers (uncommon)
 	enc{ST_UINT32, 16}: "HighQualityIn",
 	enc{ST_UINT32, 17}: "HighQualityOut",
@@ -133,6 +154,14 @@ var encodings = map[enc]string{
 	enc{ST_UINT32, 37}: "FinishAfter",
 	enc{ST_UINT32, 38}: "SignerListID",
 	enc{ST_UINT32, 39}: "SettleDelay",
+	enc{ST_UINT32, 40}: "TicketCount",
+	enc{ST_UINT32, 41}: "TicketSequence",
+	enc{ST_UINT32, 42}: "NFTokenTaxon",
+	enc{ST_UINT32, 43}: "MintedNFTokens",
+	enc{ST_UINT32, 44}: "BurnedNFTokens",
+	enc{ST_UINT32, 48}: "VoteWeight",
+	enc{ST_UINT32, 50}: "FirstNFTokenSequence",
+	enc{ST_UINT32, 51}: "OracleDocumentID",
 	// 64-bit unsigned integers (common)
 	enc{ST_UINT64, 1}:  "IndexNext",
 	enc{ST_UINT64, 2}:  "IndexPrevious",
@@ -144,18 +173,28 @@ var encodings = map[enc]string{
 	enc{ST_UINT64, 8}:  "HighNode",
 	enc{ST_UINT64, 9}:  "DestinationNode",
 	enc{ST_UINT64, 10}: "Cookie",
+	enc{ST_UINT64, 11}: "ServerVersion",
+	enc{ST_UINT64, 12}: "NFTokenOfferNode",
+	enc{ST_UINT64, 20}: "XChainClaimID",
+	enc{ST_UINT64, 21}: "XChainAccountCreateCount",
+	enc{ST_UINT64, 22}: "XChainAccountClaimCount",
+	enc{ST_UINT64, 23}: "AssetPrice",
+	enc{ST_UINT64, 27}: "IssuerNode",
+	enc{ST_UINT64, 28}: "SubjectNode",
 	// 128-bit (common)
 	enc{ST_HASH128, 1}: "EmailHash",
 	// 256-bit (common)
-	enc{ST_HASH256, 1}: "LedgerHash",
-	enc{ST_HASH256, 2}: "ParentHash",
-	enc{ST_HASH256, 3}: "TransactionHash",
-	enc{ST_HASH256, 4}: "AccountHash",
-	enc{ST_HASH256, 5}: "PreviousTxnID",
-	enc{ST_HASH256, 6}: "LedgerIndex",
-	enc{ST_HASH256, 7}: "WalletLocator",
-	enc{ST_HASH256, 8}: "RootIndex",
-	enc{ST_HASH256, 9}: "AccountTxnID",
+	enc{ST_HASH256, 1}:  "LedgerHash",
+	enc{ST_HASH256, 2}:  "ParentHash",
+	enc{ST_HASH256, 3}:  "TransactionHash",
+	enc{ST_HASH256, 4}:  "AccountHash",
+	enc{ST_HASH256, 5}:  "PreviousTxnID",
+	enc{ST_HASH256, 6}:  "LedgerIndex",
+	enc{ST_HASH256, 7}:  "WalletLocator",
+	enc{ST_HASH256, 8}:  "RootIndex",
+	enc{ST_HASH256, 9}:  "AccountTxnID",
+	enc{ST_HASH256, 10}: "NFTokenID",
+	enc{ST_HASH256, 14}: "AMMID",
 	// 256-bit (uncommon)
 	enc{ST_HASH256, 16}: "BookDirectory",
 	enc{ST_HASH256, 17}: "InvoiceID",
@@ -164,7 +203,14 @@ var encodings = map[enc]string{
 	enc{ST_HASH256, 20}: "TicketID",
 	enc{ST_HASH256, 21}: "Digest",
 	enc{ST_HASH256, 22}: "Channel",
+	enc{ST_HASH256, 23}: "ConsensusHash",
 	enc{ST_HASH256, 24}: "CheckID",
+	enc{ST_HASH256, 25}: "ValidatedHash",
+	enc{ST_HASH256, 26}: "PreviousPageMin",
+	enc{ST_HASH256, 27}: "NextPageMin",
+	enc{ST_HASH256, 28}: "NFTokenBuyOffer",
+	enc{ST_HASH256, 29}: "NFTokenSellOffer",
+	enc{ST_HASH256, 34}: "DomainID",
 	// currency amount (common)
 	enc{ST_AMOUNT, 1}:  "Amount",
 	enc{ST_AMOUNT, 2}:  "Balance",
@@ -176,16 +222,30 @@ var encodings = map[enc]string{
 	enc{ST_AMOUNT, 8}:  "Fee",
 	enc{ST_AMOUNT, 9}:  "SendMax",
 	enc{ST_AMOUNT, 10}: "DeliverMin",
+	enc{ST_AMOUNT, 11}: "Amount2",
+	enc{ST_AMOUNT, 12}: "BidMin",
+	enc{ST_AMOUNT, 13}: "BidMax",
 	// currency amount (uncommon)
 	enc{ST_AMOUNT, 16}: "MinimumOffer",
 	enc{ST_AMOUNT, 17}: "RippleEscrow",
 	enc{ST_AMOUNT, 18}: "DeliveredAmount",
+	enc{ST_AMOUNT, 19}: "NFTokenBrokerFee",
+	enc{ST_AMOUNT, 22}: "BaseFeeDrops",
+	enc{ST_AMOUNT, 23}: "ReserveBaseDrops",
+	enc{ST_AMOUNT, 24}: "ReserveIncrementDrops",
+	enc{ST_AMOUNT, 25}: "LPTokenOut",
+	enc{ST_AMOUNT, 26}: "LPTokenIn",
+	enc{ST_AMOUNT, 27}: "EPrice",
+	enc{ST_AMOUNT, 28}: "Price",
+	enc{ST_AMOUNT, 29}: "SignatureReward",
+	enc{ST_AMOUNT, 30}: "MinAccountCreateAmount",
+	enc{ST_AMOUNT, 31}: "LPTokenBalance",
 	// variable length (common)
 	enc{ST_VL, 1}:  "PublicKey",
 	enc{ST_VL, 2}:  "MessageKey",
 	enc{ST_VL, 3}:  "SigningPubKey",
 	enc{ST_VL, 4}:  "TxnSignature",
-	enc{ST_VL, 5}:  "Generator",
+	enc{ST_VL, 5}:  "URI",
 	enc{ST_VL, 6}:  "Signature",
 	enc{ST_VL, 7}:  "Domain",
 	enc{ST_VL, 8}:  "FundCode",
@@ -199,6 +259,11 @@ var encodings = map[enc]string{
 	enc{ST_VL, 16}: "Fulfillment",
 	enc{ST_VL, 17}: "Condition",
 	enc{ST_VL, 18}: "MasterSignature",
+	enc{ST_VL, 26}: "DIDDocument",
+	enc{ST_VL, 27}: "Data",
+	enc{ST_VL, 28}: "AssetClass",
+	enc{ST_VL, 29}: "Provider",
+	enc{ST_VL, 31}: "CredentialType",
 	// account
 	enc{ST_ACCOUNT, 1}: "Account",
 	enc{ST_ACCOUNT, 2}: "Owner",
@@ -208,6 +273,16 @@ var encodings = map[enc]string{
 	enc{ST_ACCOUNT, 6}: "Unauthorize",
 	enc{ST_ACCOUNT, 7}: "Target",
 	enc{ST_ACCOUNT, 8}: "RegularKey",
+	enc{ST_ACCOUNT, 9}: "NFTokenMinter",
+
+	// account (uncommon)
+	enc{ST_ACCOUNT, 18}: "OtherChainSource",
+	enc{ST_ACCOUNT, 19}: "OtherChainDestination",
+	enc{ST_ACCOUNT, 20}: "AttestationSignerAccount",
+	enc{ST_ACCOUNT, 21}: "AttestationRewardAccount",
+	enc{ST_ACCOUNT, 22}: "LockingChainDoor",
+	enc{ST_ACCOUNT, 23}: "IssuingChainDoor",
+	enc{ST_ACCOUNT, 24}: "Subject",
 	// inner object
 	enc{ST_OBJECT, 1}:  "EndOfObject",
 	enc{ST_OBJECT, 2}:  "TransactionMetaData",
@@ -220,27 +295,50 @@ var encodings = map[enc]string{
 	enc{ST_OBJECT, 9}:  "TemplateEntry",
 	enc{ST_OBJECT, 10}: "Memo",
 	enc{ST_OBJECT, 11}: "SignerEntry",
+	enc{ST_OBJECT, 12}: "NFToken",
 	// inner object (uncommon)
 	enc{ST_OBJECT, 16}: "Signer",
 	enc{ST_OBJECT, 18}: "Majority",
+	enc{ST_OBJECT, 25}: "VoteEntry",
+	enc{ST_OBJECT, 26}: "AuctionSlot",
+	enc{ST_OBJECT, 27}: "AuthAccount",
+	enc{ST_OBJECT, 30}: "XChainClaimAttestationCollectionElement",
+	enc{ST_OBJECT, 31}: "XChainCreateAccountAttestationCollectionElement",
+	enc{ST_OBJECT, 32}: "PriceData",
+	enc{ST_OBJECT, 33}: "Credential",
+	enc{ST_OBJECT, 34}: "RawTransaction",
+	enc{ST_OBJECT, 35}: "BatchSigner",
 	// array of objects
-	enc{ST_ARRAY, 1}: "EndOfArray",
-	enc{ST_ARRAY, 2}: "SigningAccounts",
-	enc{ST_ARRAY, 3}: "Signers",
-	enc{ST_ARRAY, 4}: "SignerEntries",
-	enc{ST_ARRAY, 5}: "Template",
-	enc{ST_ARRAY, 6}: "Necessary",
-	enc{ST_ARRAY, 7}: "Sufficient",
-	enc{ST_ARRAY, 8}: "AffectedNodes",
-	enc{ST_ARRAY, 9}: "Memos",
+	enc{ST_ARRAY, 1}:  "EndOfArray",
+	enc{ST_ARRAY, 2}:  "SigningAccounts",
+	enc{ST_ARRAY, 3}:  "Signers",
+	enc{ST_ARRAY, 4}:  "SignerEntries",
+	enc{ST_ARRAY, 5}:  "Template",
+	enc{ST_ARRAY, 6}:  "Necessary",
+	enc{ST_ARRAY, 7}:  "Sufficient",
+	enc{ST_ARRAY, 8}:  "AffectedNodes",
+	enc{ST_ARRAY, 9}:  "Memos",
+	enc{ST_ARRAY, 10}: "NFTokens",
+	enc{ST_ARRAY, 12}: "VoteSlots",
 	// array of objects (uncommon)
 	enc{ST_ARRAY, 16}: "Majorities",
+	enc{ST_ARRAY, 21}: "XChainClaimAttestations",
+	enc{ST_ARRAY, 22}: "XChainCreateAccountAttestations",
+	enc{ST_ARRAY, 24}: "PriceDataSeries",
+	enc{ST_ARRAY, 25}: "AuthAccounts",
+	enc{ST_ARRAY, 26}: "AuthorizeCredentials",
+	enc{ST_ARRAY, 27}: "UnauthorizeCredentials",
+	enc{ST_ARRAY, 28}: "AcceptedCredentials",
+	enc{ST_ARRAY, 30}: "RawTransactions",
+	enc{ST_ARRAY, 31}: "BatchSigners",
 	// 8-bit unsigned integers (common)
 	enc{ST_UINT8, 1}: "CloseResolution",
 	enc{ST_UINT8, 2}: "Method",
 	enc{ST_UINT8, 3}: "TransactionResult",
+	enc{ST_UINT8, 4}: "Scale",
 	// 8-bit unsigned integers (uncommon)
 	enc{ST_UINT8, 16}: "TickSize",
+	enc{ST_UINT8, 19}: "WasLockingChainSend",
 	// 160-bit (common)
 	enc{ST_HASH160, 1}: "TakerPaysCurrency",
 	enc{ST_HASH160, 2}: "TakerPaysIssuer",
@@ -252,6 +350,18 @@ var encodings = map[enc]string{
 	enc{ST_VECTOR256, 1}: "Indexes",
 	enc{ST_VECTOR256, 2}: "Hashes",
 	enc{ST_VECTOR256, 3}: "Amendments",
+	enc{ST_VECTOR256, 4}: "NFTokenOffers",
+	enc{ST_VECTOR256, 5}: "CredentialIDs",
+	// issue
+	enc{ST_ISSUE, 1}: "LockingChainIssue",
+	enc{ST_ISSUE, 2}: "IssuingChainIssue",
+	enc{ST_ISSUE, 3}: "Asset",
+	enc{ST_ISSUE, 4}: "Asset2",
+	// bridge
+	enc{ST_BRIDGE, 1}: "XChainBridge",
+	// currency
+	enc{ST_CURRENCY, 1}: "BaseAsset",
+	enc{ST_CURRENCY, 2}: "QuoteAsset",
 }
 
 var reverseEncodings map[string]enc
@@ -262,7 +372,9 @@ func init() {
 	signingFields = make(map[enc]struct{})
 	for e, name := range encodings {
 		reverseEncodings[name] = e
-		if strings.Contains(name, "Signature") {
+		// Each of the Signers signs the transaction without them, and
+		// BatchSigners sign the inner transactions rather than the batch
+		if strings.Contains(name, "Signature") || name == "Signers" || name == "BatchSigners" {
 			signingFields[e] = struct{}{}
 		}
 	}
@@ -394,7 +506,7 @@ func readVariableLength(r Reader) (int, error) {
 }
 
 func unmarshalSlice(s []byte, r Reader, prefix string) error {
-	n, err := r.Read(s)
+	n, err := io.ReadFull(r, s)
 	if n != len(s) {
 		return fmt.Errorf("%s: short read: %d expected: %d", prefix, n, len(s))
 	}
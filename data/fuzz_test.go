@@ -0,0 +1,26 @@
+package data
+
+import (
+	"bytes"
+	"testing"
+)
+
+// The corpus in testdata/fuzz/FuzzReadTransaction has a transaction of
+// each common type, and is run by go test. To look for more:
+//
+//	go test -fuzz FuzzReadTransaction ./data
+func FuzzReadTransaction(f *testing.F) {
+	f.Fuzz(func(t *testing.T, b []byte) {
+		tx, err := ReadTransaction(bytes.NewReader(b))
+		if err != nil || tx.GetBase() == nil {
+			return
+		}
+		// Such as when a required field is missing
+		if _, _, err := Raw(tx); err != nil {
+			return
+		}
+		if err := VerifyRoundTrip(tx); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -183,8 +183,11 @@ func (p *PublicKey) Bytes() []byte {
 	return []byte(nil)
 }
 
-// Expects address in base58 form
+// Expects address in base58 form, or an X-address if AcceptXAddresses is set
 func NewAccountFromAddress(s string) (*Account, error) {
+	if AcceptXAddresses && isXAddress(s) {
+		return accountFromXAddress(s)
+	}
 	hash, err := crypto.NewRippleHashCheck(s, crypto.RIPPLE_ACCOUNT_ID)
 	if err != nil {
 		return nil, err
@@ -276,6 +279,33 @@ func NewSeedFromAddress(s string) (*Seed, error) {
 	return &seed, nil
 }
 
+// Expects a secret in base58 form, either "s..." for an ECDSA key or
+// "sEd..." for an Ed25519 key, and returns the type of key it is for
+func NewSeedFromSecret(s string) (*Seed, KeyType, error) {
+	payload, ed25519, err := crypto.DecodeFamilySeed(s)
+	if err != nil {
+		return nil, ECDSA, err
+	}
+	var seed Seed
+	copy(seed[:], payload)
+	if ed25519 {
+		return &seed, Ed25519, nil
+	}
+	return &seed, ECDSA, nil
+}
+
+// Secret encodes the seed in the form which shows the type of key
+func (s Seed) Secret(keyType KeyType) string {
+	if keyType == Ed25519 {
+		secret, err := crypto.Ed25519FamilySeed(s[:])
+		if err != nil {
+			return fmt.Sprintf("Bad Secret: %s", b2h(s[:]))
+		}
+		return secret
+	}
+	return s.String()
+}
+
 func (s Seed) Hash() (crypto.Hash, error) {
 	return crypto.NewFamilySeed(s[:])
 }
@@ -41,11 +41,36 @@ func LedgerIndex(le LedgerEntry) (*Hash256, error) {
 		return buildIndex([]interface{}{NS_FEE})
 	case *Amendments:
 		return buildIndex([]interface{}{NS_AMENDMENT})
+	case *DIDEntry:
+		return GetDIDIndex(*v.Account)
+	case *Check:
+		return GetCheckIndex(*v.Account, *v.Sequence)
+	case *Ticket:
+		return GetTicketIndex(*v.Account, *v.TicketSequence)
+	case *DepositPreAuth:
+		return GetDepositPreauthIndex(*v.Account, *v.Authorize)
+	case *Credential:
+		return GetCredentialIndex(*v.Subject, *v.Issuer, *v.CredentialType)
+	case *PermissionedDomain:
+		return GetPermissionedDomainIndex(*v.Owner, *v.Sequence)
 	default:
 		return nil, fmt.Errorf("Unknown LedgerEntry")
 	}
 }
 
+// entryIndex returns the index the entry was read with, from the index
+// field of JSON or the suffix of a binary node, or else computes it
+func entryIndex(le LedgerEntry) (*Hash256, error) {
+	switch {
+	case le.GetLedgerIndex() != nil:
+		return le.GetLedgerIndex(), nil
+	case !le.GetHash().IsZero():
+		return le.GetHash(), nil
+	default:
+		return LedgerIndex(le)
+	}
+}
+
 func GetAccountRootIndex(account Account) (*Hash256, error) {
 	return buildIndex([]interface{}{NS_ACCOUNT, account.Bytes()})
 }
@@ -68,6 +93,82 @@ func GetDirectoryNodeIndex(root Hash256, index *NodeIndex) (*Hash256, error) {
 	return buildIndex([]interface{}{NS_DIRECTORY_NODE, root, *index})
 }
 
+// GetNFTokenOfferIndex returns the index of the offer created by the
+// NFTokenCreateOffer of account with sequence
+func GetNFTokenOfferIndex(account Account, sequence uint32) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_NFTOKEN_OFFER, account.Bytes(), sequence})
+}
+
+// GetCheckIndex returns the index of the check created by the
+// CheckCreate of account with sequence
+func GetCheckIndex(account Account, sequence uint32) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_CHECK, account.Bytes(), sequence})
+}
+
+// GetTicketIndex returns the index of the ticket of account for
+// ticketSequence
+func GetTicketIndex(account Account, ticketSequence uint32) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_TICKET, account.Bytes(), ticketSequence})
+}
+
+// GetDepositPreauthIndex returns the index of the preauthorisation by
+// owner of deposits from authorized
+func GetDepositPreauthIndex(owner, authorized Account) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_DEPOSIT_PREAUTH, owner.Bytes(), authorized.Bytes()})
+}
+
+// GetCredentialIndex returns the index of the credential of credentialType
+// issued by issuer to subject
+func GetCredentialIndex(subject, issuer Account, credentialType VariableLength) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_CREDENTIAL, subject.Bytes(), issuer.Bytes(), credentialType.Bytes()})
+}
+
+// GetPermissionedDomainIndex returns the index of the domain created by
+// the PermissionedDomainSet of owner with sequence
+func GetPermissionedDomainIndex(owner Account, sequence uint32) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_PERMISSIONED_DOMAIN, owner.Bytes(), sequence})
+}
+
+// GetDIDIndex returns the index of the DID of account
+func GetDIDIndex(account Account) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_DID, account.Bytes()})
+}
+
+// GetOracleIndex returns the index of the price oracle of owner with
+// documentID
+func GetOracleIndex(owner Account, documentID uint32) (*Hash256, error) {
+	return buildIndex([]interface{}{NS_ORACLE, owner.Bytes(), documentID})
+}
+
+// GetAMMIndex returns the index of the AMM of two assets, in either order
+func GetAMMIndex(a, b Asset) (*Hash256, error) {
+	type issue struct {
+		currency Currency
+		issuer   Account
+	}
+	var issues [2]issue
+	for i, asset := range []Asset{a, b} {
+		currency, err := NewCurrency(asset.Currency)
+		if err != nil {
+			return nil, err
+		}
+		issues[i].currency = currency
+		if !currency.IsNative() {
+			issuer, err := NewAccountFromAddress(asset.Issuer)
+			if err != nil {
+				return nil, err
+			}
+			issues[i].issuer = *issuer
+		}
+	}
+	// Ordered by currency, then issuer
+	order := bytes.Compare(issues[0].currency.Bytes(), issues[1].currency.Bytes())
+	if order > 0 || order == 0 && bytes.Compare(issues[0].issuer.Bytes(), issues[1].issuer.Bytes()) > 0 {
+		issues[0], issues[1] = issues[1], issues[0]
+	}
+	return buildIndex([]interface{}{NS_AMM, issues[0].issuer.Bytes(), issues[0].currency.Bytes(), issues[1].issuer.Bytes(), issues[1].currency.Bytes()})
+}
+
 func GetOwnerDirectoryIndex(account Account) (*Hash256, error) {
 	return buildIndex([]interface{}{NS_OWNER_DIRECTORY, account.Bytes()})
 }
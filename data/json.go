@@ -53,6 +53,9 @@ type txmNormal TransactionWithMetaData
 
 var (
 	txmSplitTypeRegex       = regexp.MustCompile(`"tx":`)
+	txmJSONTypeRegex        = regexp.MustCompile(`"tx_json":`)
+	txmDeliverMaxRegex      = regexp.MustCompile(`"DeliverMax"\s*:`)
+	txmAmountRegex          = regexp.MustCompile(`"Amount"\s*:`)
 	txmMetaDataRegex        = regexp.MustCompile(`"metaData":`)
 	txmTransactionTypeRegex = regexp.MustCompile(`"TransactionType"\s*:\s*"(\w+)"`)
 )
@@ -61,6 +64,38 @@ var (
 // inconsistencies in the presentation of a transaction
 // by the rippled API.  Indeed.
 func (txm *TransactionWithMetaData) UnmarshalJSON(b []byte) error {
+	if txmJSONTypeRegex.Match(b) {
+		// Transaction has the form {"tx_json":{}, "meta":{}, "hash":...}
+		// i.e. returned under api_version 2, which also renames the
+		// Amount of a Payment to DeliverMax.
+		var split struct {
+			Tx             json.RawMessage `json:"tx_json"`
+			Meta           json.RawMessage `json:"meta"`
+			Hash           *Hash256        `json:"hash"`
+			LedgerSequence uint32          `json:"ledger_index"`
+		}
+		if err := json.Unmarshal(b, &split); err != nil {
+			return err
+		}
+		tx := split.Tx
+		if txmDeliverMaxRegex.Match(tx) && !txmAmountRegex.Match(tx) {
+			tx = txmDeliverMaxRegex.ReplaceAll(tx, []byte(`"Amount":`))
+		}
+		if err := json.Unmarshal(tx, txm); err != nil {
+			return err
+		}
+		if hash := txm.GetHash(); hash != nil && split.Hash != nil {
+			*hash = *split.Hash
+		}
+		if split.LedgerSequence != 0 {
+			txm.LedgerSequence = split.LedgerSequence
+		}
+		if len(split.Meta) == 0 {
+			return nil
+		}
+		return json.Unmarshal(split.Meta, &txm.MetaData)
+	}
+
 	if txmSplitTypeRegex.Match(b) {
 		// Transaction has the form {"tx":{}, "meta":{}, "validated": true}
 		// i.e. returned from `account_tx` command.
@@ -207,6 +242,9 @@ func (l *LedgerEntrySlice) UnmarshalJSON(b []byte) error {
 		if indexMatch == nil {
 			return fmt.Errorf("Missing LedgerEntry index")
 		}
+		if _, ok := ledgerEntryTypes[leTypeMatch[1]]; !ok {
+			return fmt.Errorf("Unknown LedgerEntryType: %s", leTypeMatch[1])
+		}
 		le := GetLedgerEntryFactoryByType(leTypeMatch[1])()
 		if err := json.Unmarshal(raw, &le); err != nil {
 			return err
@@ -284,6 +322,68 @@ func (a *AffectedNode) MarshalJSON() ([]byte, error) {
 	return []byte(fixed), err
 }
 
+// Wrapper to stop recursive marshalling
+type signerEntryJSON SignerEntry
+
+// SignerEntries are wrapped in an object of the same name
+func (s SignerEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		SignerEntry signerEntryJSON
+	}{signerEntryJSON(s)})
+}
+
+func (s *SignerEntry) UnmarshalJSON(b []byte) error {
+	var wrapper struct {
+		SignerEntry *signerEntryJSON
+	}
+	wrapper.SignerEntry = (*signerEntryJSON)(s)
+	return json.Unmarshal(b, &wrapper)
+}
+
+// Wrapper to stop recursive unmarshalling
+type metaDataJSON MetaData
+
+// UnmarshalJSON takes the delivered amount from either delivered_amount,
+// which rippled adds, or DeliveredAmount, the field of the metadata.
+// Rippled sets delivered_amount to "unavailable" for partial payments
+// older than DeliveredAmountLedger, which leaves DeliveredAmount nil.
+func (m *MetaData) UnmarshalJSON(b []byte) error {
+	extract := struct {
+		*metaDataJSON
+		Delivered json.RawMessage `json:"delivered_amount"`
+		Field     json.RawMessage `json:"DeliveredAmount"`
+	}{metaDataJSON: (*metaDataJSON)(m)}
+	if err := json.Unmarshal(b, &extract); err != nil {
+		return err
+	}
+	m.DeliveredAmount = nil
+	for _, raw := range []json.RawMessage{extract.Delivered, extract.Field} {
+		if raw == nil || string(raw) == `"unavailable"` || string(raw) == "null" {
+			continue
+		}
+		m.DeliveredAmount = new(Amount)
+		return json.Unmarshal(raw, m.DeliveredAmount)
+	}
+	return nil
+}
+
+// UnmarshalJSON sniffs the type of the inner transaction, as
+// TransactionWithMetaData does
+func (r *RawTransaction) UnmarshalJSON(b []byte) error {
+	var wrapper struct {
+		RawTransaction json.RawMessage
+	}
+	if err := json.Unmarshal(b, &wrapper); err != nil {
+		return err
+	}
+	txTypeMatch := txmTransactionTypeRegex.FindSubmatch(wrapper.RawTransaction)
+	if txTypeMatch == nil {
+		return fmt.Errorf("RawTransaction is missing its TransactionType")
+	}
+	r.RawTransaction = GetTxFactoryByType(string(txTypeMatch[1]))()
+	return json.Unmarshal(wrapper.RawTransaction, r.RawTransaction)
+}
+
 func (i NodeIndex) MarshalText() ([]byte, error) {
 	return []byte(fmt.Sprintf("%016X", i)), nil
 }
@@ -317,7 +417,7 @@ func (r *TransactionResult) UnmarshalText(b []byte) error {
 }
 
 func (l LedgerEntryType) MarshalText() ([]byte, error) {
-	return []byte(ledgerEntryNames[l]), nil
+	return []byte(l.String()), nil
 }
 
 func (l *LedgerEntryType) UnmarshalText(b []byte) error {
@@ -330,7 +430,7 @@ func (l *LedgerEntryType) UnmarshalText(b []byte) error {
 }
 
 func (t TransactionType) MarshalText() ([]byte, error) {
-	return []byte(txNames[t]), nil
+	return []byte(t.String()), nil
 }
 
 func (t *TransactionType) UnmarshalText(b []byte) error {
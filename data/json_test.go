@@ -51,3 +51,39 @@ func (s *JSONSuite) TestLedgersJSON(c *C) {
 		compare(c, f, b, out)
 	}
 }
+
+// Under api_version 2 the transaction is nested in "tx_json", with the
+// hash and metadata beside it, and the Amount of a Payment is DeliverMax
+func (s *JSONSuite) TestTransactionsJSONAPIVersion2(c *C) {
+	files, err := filepath.Glob("testdata/transaction_*.json")
+	c.Assert(err, IsNil)
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		c.Assert(err, IsNil)
+		var expected TransactionWithMetaData
+		c.Assert(json.Unmarshal(b, &expected), IsNil)
+
+		var tx map[string]json.RawMessage
+		c.Assert(json.Unmarshal(b, &tx), IsNil)
+		v2 := map[string]json.RawMessage{
+			"hash":         tx["hash"],
+			"ledger_index": tx["ledger_index"],
+			"meta":         tx["meta"],
+		}
+		for _, field := range []string{"hash", "ledger_index", "inLedger", "meta"} {
+			delete(tx, field)
+		}
+		if amount, ok := tx["Amount"]; ok && expected.GetType() == "Payment" {
+			tx["DeliverMax"] = amount
+			delete(tx, "Amount")
+		}
+		v2["tx_json"], err = json.Marshal(tx)
+		c.Assert(err, IsNil)
+		b, err = json.Marshal(v2)
+		c.Assert(err, IsNil)
+
+		var obtained TransactionWithMetaData
+		c.Assert(json.Unmarshal(b, &obtained), IsNil, Commentf(f))
+		c.Check(obtained, DeepEquals, expected, Commentf(f))
+	}
+}
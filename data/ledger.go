@@ -1,5 +1,10 @@
 package data
 
+import (
+	"bytes"
+	"fmt"
+)
+
 type LedgerHeader struct {
 	LedgerSequence  uint32     `json:"ledger_index,string"`
 	TotalXRP        uint64     `json:"total_coins,string"`
@@ -35,3 +40,40 @@ func (l Ledger) NodeType() NodeType { return NT_LEDGER }
 func (l Ledger) Ledger() uint32     { return l.LedgerSequence }
 func (l Ledger) NodeId() *Hash256   { return &l.Hash }
 func (l Ledger) GetHash() *Hash256  { return &l.Hash }
+
+// MarshalBinary returns the header in the form which is hashed, with each
+// field in big-endian order
+func (h LedgerHeader) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	err := write(&buf, h)
+	return buf.Bytes(), err
+}
+
+// LedgerHash computes the hash of a ledger, the SHA512Half of
+// HP_LEDGER_MASTER and the binary form of its header
+func LedgerHash(h *LedgerHeader) (Hash256, error) {
+	return NodeId(&Ledger{LedgerHeader: *h})
+}
+
+// Verify checks the hash of the ledger is that of its header
+func (l *Ledger) Verify() error {
+	hash, err := LedgerHash(&l.LedgerHeader)
+	if err != nil {
+		return err
+	}
+	if hash != l.Hash {
+		return fmt.Errorf("Ledger %d has hash %s but its header hashes to %s", l.LedgerSequence, l.Hash, hash)
+	}
+	return nil
+}
+
+// VerifyParent checks the ledger follows its parent
+func (l *Ledger) VerifyParent(parent *Ledger) error {
+	if l.LedgerSequence != parent.LedgerSequence+1 {
+		return fmt.Errorf("Ledger %d does not follow ledger %d", l.LedgerSequence, parent.LedgerSequence)
+	}
+	if l.PreviousLedger != parent.Hash {
+		return fmt.Errorf("Ledger %d has parent %s not %s", l.LedgerSequence, l.PreviousLedger, parent.Hash)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package data
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	. "gopkg.in/check.v1"
+)
+
+type LedgerSuite struct{}
+
+var _ = Suite(&LedgerSuite{})
+
+// The ledger_data of ledger 32570 and its hash
+const (
+	ledger32570     = "00007F3A016345785D89F1A060A01EBF11537D8394EA1235253293508BDA7131D5F8710EFE9413AA129653A200000000000000000000000000000000000000000000000000000000000000003806AF8F22037DE598D30D38C8861FADF391171D26F7DE34ACFA038996EA6BEB1875129C187512A60A00"
+	ledger32570Hash = "4109C6F2045FC7EFF4CDE8F9905D19C28820D86304080FF886B299F0206E42B5"
+)
+
+func (s *LedgerSuite) TestLedgerHash(c *C) {
+	b, err := hex.DecodeString(ledger32570)
+	c.Assert(err, IsNil)
+	hash, err := NewHash256(ledger32570Hash)
+	c.Assert(err, IsNil)
+	ledger, err := ReadLedger(bytes.NewReader(b), *hash)
+	c.Assert(err, IsNil)
+	c.Check(ledger.LedgerSequence, Equals, uint32(32570))
+	c.Check(ledger.CloseTime.Uint32(), Equals, uint32(410325670))
+
+	computed, err := LedgerHash(&ledger.LedgerHeader)
+	c.Assert(err, IsNil)
+	c.Check(computed, Equals, *hash)
+	c.Check(ledger.Verify(), IsNil)
+	header, err := ledger.LedgerHeader.MarshalBinary()
+	c.Assert(err, IsNil)
+	c.Check(header, DeepEquals, b)
+
+	child := NewEmptyLedger(32571)
+	child.PreviousLedger = *hash
+	child.Hash, err = LedgerHash(&child.LedgerHeader)
+	c.Assert(err, IsNil)
+	c.Check(child.Verify(), IsNil)
+	c.Check(child.VerifyParent(ledger), IsNil)
+	c.Check(ledger.VerifyParent(child), ErrorMatches, "Ledger 32570 does not follow ledger 32571")
+
+	child.CloseFlags = 1
+	c.Check(child.Verify(), ErrorMatches, "Ledger 32571 has hash .* but its header hashes to .*")
+	child.PreviousLedger = ledger.StateHash
+	c.Check(child.VerifyParent(ledger), ErrorMatches, "Ledger 32571 has parent 3806AF.* not 4109C6.*")
+}
@@ -1,5 +1,11 @@
 package data
 
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
 type LedgerEntrySlice []LedgerEntry
 
 type leBase struct {
@@ -9,25 +15,33 @@ type leBase struct {
 	PreviousTxnLgrSeq *uint32  `json:",omitempty"`
 	Hash              Hash256  `json:"-"`
 	Id                Hash256  `json:"-"`
+
+	// Binary only, except in a DynamicLedgerEntry
+	Dynamic DynamicFields `json:"-"`
 }
 
 type AccountRoot struct {
 	leBase
-	Flags         *LedgerEntryFlag `json:",omitempty"`
-	Account       *Account         `json:",omitempty"`
-	Sequence      *uint32          `json:",omitempty"`
-	Balance       *Value           `json:",omitempty"`
-	OwnerCount    *uint32          `json:",omitempty"`
-	AccountTxnID  *Hash256         `json:",omitempty"`
-	RegularKey    *RegularKey      `json:",omitempty"`
-	EmailHash     *Hash128         `json:",omitempty"`
-	WalletLocator *Hash256         `json:",omitempty"`
-	WalletSize    *uint32          `json:",omitempty"`
-	MessageKey    *VariableLength  `json:",omitempty"`
-	TickSize      *uint8           `json:",omitempty"`
-	TransferRate  *uint32          `json:",omitempty"`
-	Domain        *VariableLength  `json:",omitempty"`
-	Signers       *VariableLength  `json:",omitempty"`
+	Flags                *LedgerEntryFlag `json:",omitempty"`
+	Account              *Account         `json:",omitempty"`
+	Sequence             *uint32          `json:",omitempty"`
+	Balance              *Value           `json:",omitempty"`
+	OwnerCount           *uint32          `json:",omitempty"`
+	AccountTxnID         *Hash256         `json:",omitempty"`
+	RegularKey           *RegularKey      `json:",omitempty"`
+	EmailHash            *Hash128         `json:",omitempty"`
+	WalletLocator        *Hash256         `json:",omitempty"`
+	WalletSize           *uint32          `json:",omitempty"`
+	MessageKey           *VariableLength  `json:",omitempty"`
+	TickSize             *uint8           `json:",omitempty"`
+	TransferRate         *uint32          `json:",omitempty"`
+	Domain               *VariableLength  `json:",omitempty"`
+	Signers              *VariableLength  `json:",omitempty"`
+	AMMID                *Hash256         `json:",omitempty"`
+	NFTokenMinter        *Account         `json:",omitempty"`
+	MintedNFTokens       *uint32          `json:",omitempty"`
+	BurnedNFTokens       *uint32          `json:",omitempty"`
+	FirstNFTokenSequence *uint32          `json:",omitempty"`
 }
 
 type RippleState struct {
@@ -132,12 +146,13 @@ type SignerList struct {
 
 type Ticket struct {
 	leBase
-	Flags      *LedgerEntryFlag `json:",omitempty"`
-	Account    *Account         `json:",omitempty"`
-	Sequence   *uint32          `json:",omitempty"`
-	OwnerNode  *NodeIndex       `json:",omitempty"`
-	Target     *Account         `json:",omitempty"`
-	Expiration *uint32          `json:",omitempty"`
+	Flags          *LedgerEntryFlag `json:",omitempty"`
+	Account        *Account         `json:",omitempty"`
+	Sequence       *uint32          `json:",omitempty"`
+	OwnerNode      *NodeIndex       `json:",omitempty"`
+	Target         *Account         `json:",omitempty"`
+	Expiration     *uint32          `json:",omitempty"`
+	TicketSequence *uint32          `json:",omitempty"`
 }
 
 type PayChannel struct {
@@ -158,11 +173,17 @@ type PayChannel struct {
 
 type Check struct {
 	leBase
-	Account     *Account `json:",omitempty"`
-	Destination *Account `json:",omitempty"`
-	Expiration  *uint32  `json:",omitempty"`
-	SendMax     *Amount  `json:",omitempty"`
-	Sequence    *uint32  `json:",omitempty"`
+	Flags           *LedgerEntryFlag `json:",omitempty"`
+	Account         *Account         `json:",omitempty"`
+	Destination     *Account         `json:",omitempty"`
+	Expiration      *uint32          `json:",omitempty"`
+	SendMax         *Amount          `json:",omitempty"`
+	Sequence        *uint32          `json:",omitempty"`
+	OwnerNode       *NodeIndex       `json:",omitempty"`
+	DestinationNode *NodeIndex       `json:",omitempty"`
+	DestinationTag  *uint32          `json:",omitempty"`
+	SourceTag       *uint32          `json:",omitempty"`
+	InvoiceID       *Hash256         `json:",omitempty"`
 }
 
 type DepositPreAuth struct {
@@ -177,8 +198,267 @@ type NegativeUNL struct {
 	leBase
 }
 
+type NFToken struct {
+	NFToken struct {
+		NFTokenID Hash256
+		URI       *VariableLength `json:",omitempty"`
+	}
+}
+
+type NFTokenPage struct {
+	leBase
+	Flags           *LedgerEntryFlag `json:",omitempty"`
+	PreviousPageMin *Hash256         `json:",omitempty"`
+	NextPageMin     *Hash256         `json:",omitempty"`
+	NFTokens        []NFToken        `json:",omitempty"`
+}
+
+type NFTokenOffer struct {
+	leBase
+	Flags            *LedgerEntryFlag `json:",omitempty"`
+	Owner            *Account         `json:",omitempty"`
+	NFTokenID        *Hash256         `json:",omitempty"`
+	Amount           *Amount          `json:",omitempty"`
+	Destination      *Account         `json:",omitempty"`
+	Expiration       *uint32          `json:",omitempty"`
+	OwnerNode        *NodeIndex       `json:",omitempty"`
+	NFTokenOfferNode *NodeIndex       `json:",omitempty"`
+}
+
+// AMMEntry is the AMM ledger entry, named so as not to clash with
+// its LedgerEntryType
+type AMMEntry struct {
+	leBase
+	Flags          *LedgerEntryFlag `json:",omitempty"`
+	Account        *Account         `json:",omitempty"`
+	Asset          *Asset           `json:",omitempty"`
+	Asset2         *Asset           `json:",omitempty"`
+	LPTokenBalance *Amount          `json:",omitempty"`
+	TradingFee     *uint16          `json:",omitempty"`
+	AuctionSlot    *AuctionSlot     `json:",omitempty"`
+	VoteSlots      []VoteEntry      `json:",omitempty"`
+	OwnerNode      *NodeIndex       `json:",omitempty"`
+}
+
+type AuctionSlot struct {
+	Account       *Account      `json:",omitempty"`
+	AuthAccounts  []AuthAccount `json:",omitempty"`
+	DiscountedFee *uint16       `json:",omitempty"`
+	Expiration    *uint32       `json:",omitempty"`
+	Price         *Amount       `json:",omitempty"`
+}
+
+type AuthAccount struct {
+	AuthAccount struct {
+		Account Account
+	}
+}
+
+// DIDEntry is the DID ledger entry of an account, named so as not to
+// clash with its LedgerEntryType. It has at least one of DIDDocument, URI
+// and Data.
+type DIDEntry struct {
+	leBase
+	Flags       *LedgerEntryFlag `json:",omitempty"`
+	Account     *Account         `json:",omitempty"`
+	DIDDocument *VariableLength  `json:",omitempty"`
+	URI         *VariableLength  `json:",omitempty"`
+	Data        *VariableLength  `json:",omitempty"`
+	OwnerNode   *NodeIndex       `json:",omitempty"`
+}
+
+// Bridge is the ledger entry of a bridge, owned by its door on this chain
+type Bridge struct {
+	leBase
+	Flags                    *LedgerEntryFlag `json:",omitempty"`
+	Account                  *Account         `json:",omitempty"`
+	SignatureReward          *Amount          `json:",omitempty"`
+	MinAccountCreateAmount   *Amount          `json:",omitempty"`
+	XChainBridge             *XChainBridge    `json:",omitempty"`
+	XChainClaimID            *Uint64Hex       `json:",omitempty"`
+	XChainAccountCreateCount *Uint64Hex       `json:",omitempty"`
+	XChainAccountClaimCount  *Uint64Hex       `json:",omitempty"`
+	OwnerNode                *NodeIndex       `json:",omitempty"`
+}
+
+// XChainOwnedClaimID is a claim ID created by XChainCreateClaimID, which
+// collects attestations until it is claimed
+type XChainOwnedClaimID struct {
+	leBase
+	Flags                   *LedgerEntryFlag         `json:",omitempty"`
+	Account                 *Account                 `json:",omitempty"`
+	XChainBridge            *XChainBridge            `json:",omitempty"`
+	XChainClaimID           *Uint64Hex               `json:",omitempty"`
+	OtherChainSource        *Account                 `json:",omitempty"`
+	XChainClaimAttestations []XChainClaimAttestation `json:",omitempty"`
+	SignatureReward         *Amount                  `json:",omitempty"`
+	OwnerNode               *NodeIndex               `json:",omitempty"`
+}
+
+// XChainOwnedCreateAccountClaimID collects the attestations of an
+// XChainAccountCreateCommit, and is owned by the door account
+type XChainOwnedCreateAccountClaimID struct {
+	leBase
+	Flags                           *LedgerEntryFlag                 `json:",omitempty"`
+	Account                         *Account                         `json:",omitempty"`
+	XChainBridge                    *XChainBridge                    `json:",omitempty"`
+	XChainAccountCreateCount        *Uint64Hex                       `json:",omitempty"`
+	XChainCreateAccountAttestations []XChainCreateAccountAttestation `json:",omitempty"`
+	OwnerNode                       *NodeIndex                       `json:",omitempty"`
+}
+
+type XChainClaimAttestation struct {
+	XChainClaimAttestationCollectionElement struct {
+		AttestationSignerAccount Account
+		PublicKey                PublicKey
+		Amount                   Amount
+		AttestationRewardAccount Account
+		WasLockingChainSend      uint8
+		Destination              *Account `json:",omitempty"`
+	}
+}
+
+type XChainCreateAccountAttestation struct {
+	XChainCreateAccountAttestationCollectionElement struct {
+		AttestationSignerAccount Account
+		PublicKey                PublicKey
+		Amount                   Amount
+		AttestationRewardAccount Account
+		WasLockingChainSend      uint8
+		Destination              Account
+		SignatureReward          Amount
+	}
+}
+
+// Oracle is a price oracle, with the latest prices of up to ten pairs
+type Oracle struct {
+	leBase
+	Flags           *LedgerEntryFlag `json:",omitempty"`
+	Owner           *Account         `json:",omitempty"`
+	Provider        *VariableLength  `json:",omitempty"`
+	AssetClass      *VariableLength  `json:",omitempty"`
+	URI             *VariableLength  `json:",omitempty"`
+	LastUpdateTime  *uint32          `json:",omitempty"`
+	PriceDataSeries []PriceData      `json:",omitempty"`
+	OwnerNode       *NodeIndex       `json:",omitempty"`
+}
+
+// PriceData is the price of BaseAsset in QuoteAsset, which is
+// AssetPrice*10^-Scale. OracleSet deletes a pair by omitting AssetPrice.
+type PriceData struct {
+	PriceData struct {
+		BaseAsset  Currency
+		QuoteAsset Currency
+		AssetPrice *Uint64Hex `json:",omitempty"`
+		Scale      *uint8     `json:",omitempty"`
+	}
+}
+
+// Credential is issued by Issuer to Subject, which accepts it to make it
+// count, as the LsAccepted flag shows
+type Credential struct {
+	leBase
+	Flags          *LedgerEntryFlag `json:",omitempty"`
+	Subject        *Account         `json:",omitempty"`
+	Issuer         *Account         `json:",omitempty"`
+	CredentialType *VariableLength  `json:",omitempty"`
+	Expiration     *uint32          `json:",omitempty"`
+	URI            *VariableLength  `json:",omitempty"`
+	IssuerNode     *NodeIndex       `json:",omitempty"`
+	SubjectNode    *NodeIndex       `json:",omitempty"`
+}
+
+// PermissionedDomain admits the holders of any of its accepted credentials
+type PermissionedDomain struct {
+	leBase
+	Flags               *LedgerEntryFlag     `json:",omitempty"`
+	Owner               *Account             `json:",omitempty"`
+	Sequence            *uint32              `json:",omitempty"`
+	AcceptedCredentials []AcceptedCredential `json:",omitempty"`
+	OwnerNode           *NodeIndex           `json:",omitempty"`
+}
+
+// AcceptedCredential names a type of credential by its issuer
+type AcceptedCredential struct {
+	Credential struct {
+		Issuer         Account
+		CredentialType VariableLength
+	}
+}
+
+type VoteEntry struct {
+	VoteEntry struct {
+		Account    Account
+		TradingFee uint16
+		VoteWeight uint32
+	}
+}
+
 func (_ *NegativeUNL) Affects(account Account) bool { return false }
 
+// The owner of a page is the first 20 bytes of its index
+func (p *NFTokenPage) Affects(account Account) bool {
+	return p.LedgerIndex != nil && bytes.Equal(p.LedgerIndex[:20], account[:])
+}
+
+func (o *NFTokenOffer) Affects(account Account) bool {
+	return (o.Owner != nil && o.Owner.Equals(account)) ||
+		(o.Destination != nil && o.Destination.Equals(account))
+}
+
+func (a *AMMEntry) Affects(account Account) bool {
+	return a.Account != nil && a.Account.Equals(account)
+}
+
+func (d *DIDEntry) Affects(account Account) bool {
+	return d.Account != nil && d.Account.Equals(account)
+}
+
+func (b *Bridge) Affects(account Account) bool {
+	return b.Account != nil && b.Account.Equals(account)
+}
+
+func (c *XChainOwnedClaimID) Affects(account Account) bool {
+	return c.Account != nil && c.Account.Equals(account)
+}
+
+func (c *XChainOwnedCreateAccountClaimID) Affects(account Account) bool {
+	return c.Account != nil && c.Account.Equals(account)
+}
+
+func (o *Oracle) Affects(account Account) bool {
+	return o.Owner != nil && o.Owner.Equals(account)
+}
+
+func (c *Credential) Affects(account Account) bool {
+	return (c.Subject != nil && c.Subject.Equals(account)) || (c.Issuer != nil && c.Issuer.Equals(account))
+}
+
+// Accepted reports whether the subject has accepted the credential
+func (c *Credential) Accepted() bool {
+	return c.Flags != nil && *c.Flags&LsAccepted != 0
+}
+
+func (d *PermissionedDomain) Affects(account Account) bool {
+	return d.Owner != nil && d.Owner.Equals(account)
+}
+
+// Price returns AssetPrice scaled by Scale, or nil when it is absent
+func (p *PriceData) Price() (*Value, error) {
+	d := &p.PriceData
+	if d.AssetPrice == nil {
+		return nil, nil
+	}
+	if *d.AssetPrice > math.MaxInt64 {
+		return nil, fmt.Errorf("AssetPrice too large: %d", *d.AssetPrice)
+	}
+	var scale int64
+	if d.Scale != nil {
+		scale = int64(*d.Scale)
+	}
+	return NewNonNativeValue(int64(*d.AssetPrice), -scale)
+}
+
 func (a *AccountRoot) Affects(account Account) bool {
 	return a.Account != nil && a.Account.Equals(account)
 }
@@ -213,7 +493,7 @@ func (d *DepositPreAuth) Affects(account Account) bool {
 	return (d.Account != nil && d.Account.Equals(account)) || (d.Authorize != nil && d.Authorize.Equals(account))
 }
 
-func (le *leBase) GetType() string                     { return ledgerEntryNames[le.LedgerEntryType] }
+func (le *leBase) GetType() string                     { return le.LedgerEntryType.String() }
 func (le *leBase) GetLedgerEntryType() LedgerEntryType { return le.LedgerEntryType }
 func (le *leBase) Prefix() HashPrefix                  { return HP_LEAF_NODE }
 func (le *leBase) NodeType() NodeType                  { return NT_ACCOUNT_NODE }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/bits-and-blooms/bitset"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -117,3 +119,71 @@ func (l *LedgerSet) TakeTop(n uint32) LedgerSlice {
 	}
 	return ledgers.Sorted()
 }
+
+// LedgerRanges is the form of rippled's complete_ledgers field,
+// e.g. "32570-6595042,6595044-6595045". Max is left unset.
+type LedgerRanges []LedgerRange
+
+// ParseLedgerRanges accepts a comma separated list of ledger sequences
+// and inclusive ranges, or "empty".
+func ParseLedgerRanges(s string) (LedgerRanges, error) {
+	if s == "" || s == "empty" {
+		return nil, nil
+	}
+	var ranges LedgerRanges
+	for _, part := range strings.Split(s, ",") {
+		var r LedgerRange
+		bounds := strings.SplitN(part, "-", 2)
+		start, err := strconv.ParseUint(bounds[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Bad ledger range: %s", part)
+		}
+		r.Start, r.End = uint32(start), uint32(start)
+		if len(bounds) == 2 {
+			end, err := strconv.ParseUint(bounds[1], 10, 32)
+			if err != nil || end < start {
+				return nil, fmt.Errorf("Bad ledger range: %s", part)
+			}
+			r.End = uint32(end)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func (l LedgerRanges) Contains(sequence uint32) bool {
+	for _, r := range l {
+		if sequence >= r.Start && sequence <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+func (l LedgerRanges) String() string {
+	if len(l) == 0 {
+		return "empty"
+	}
+	parts := make([]string, len(l))
+	for i, r := range l {
+		if r.Start == r.End {
+			parts[i] = strconv.FormatUint(uint64(r.Start), 10)
+		} else {
+			parts[i] = fmt.Sprintf("%d-%d", r.Start, r.End)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l LedgerRanges) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *LedgerRanges) UnmarshalText(b []byte) error {
+	ranges, err := ParseLedgerRanges(string(b))
+	if err != nil {
+		return err
+	}
+	*l = ranges
+	return nil
+}
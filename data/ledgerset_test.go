@@ -42,6 +42,27 @@ func (s *LedgerSetSuite) TestLedgerSetMiddle(c *C) {
 	c.Assert(l.Max(), Equals, uint32(40000))
 }
 
+func (s *LedgerSetSuite) TestLedgerRanges(c *C) {
+	r, err := ParseLedgerRanges("32570-6595042,6595044,6595046-6595050")
+	c.Assert(err, IsNil)
+	c.Assert(r, DeepEquals, LedgerRanges{{Start: 32570, End: 6595042}, {Start: 6595044, End: 6595044}, {Start: 6595046, End: 6595050}})
+	c.Assert(r.Contains(32570), Equals, true)
+	c.Assert(r.Contains(6595043), Equals, false)
+	c.Assert(r.Contains(6595044), Equals, true)
+	c.Assert(r.Contains(6595051), Equals, false)
+	c.Assert(r.String(), Equals, "32570-6595042,6595044,6595046-6595050")
+
+	empty, err := ParseLedgerRanges("empty")
+	c.Assert(err, IsNil)
+	c.Assert(empty, HasLen, 0)
+	c.Assert(empty.String(), Equals, "empty")
+
+	for _, bad := range []string{"1-", "a-b", "10-5", "1,,2"} {
+		_, err := ParseLedgerRanges(bad)
+		c.Assert(err, NotNil, Commentf(bad))
+	}
+}
+
 // func (s *LedgerSetSuite) TestLargeLedgerSet(c *C) {
 // 	l := NewLedgerSet(32570, 5500000)
 // 	l.Set(32570)
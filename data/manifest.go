@@ -0,0 +1,211 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+)
+
+// The sequence of a manifest which revokes the master key of a validator
+const ManifestRevoked uint32 = math.MaxUint32
+
+// Manifest binds the master key of a validator to the ephemeral key with
+// which it signs validations. It is signed by both keys, and replaced by
+// a manifest with a higher sequence when the validator rotates its key.
+type Manifest struct {
+	Hash            Hash256
+	Sequence        uint32
+	PublicKey       PublicKey // The master key
+	SigningPubKey   *PublicKey
+	Domain          *VariableLength
+	Signature       *VariableLength
+	MasterSignature VariableLength
+}
+
+func (m Manifest) GetType() string            { return "Manifest" }
+func (m *Manifest) Prefix() HashPrefix        { return HP_MANIFEST }
+func (m *Manifest) SigningPrefix() HashPrefix { return HP_MANIFEST }
+func (m *Manifest) GetHash() *Hash256         { return &m.Hash }
+
+// Revoked reports whether the manifest revokes the master key, in which
+// case it has no signing key
+func (m *Manifest) Revoked() bool {
+	return m.Sequence == ManifestRevoked
+}
+
+func (m *Manifest) String() string {
+	switch {
+	case m.Revoked():
+		return fmt.Sprintf("Manifest of %s revoked", m.PublicKey.NodePublicKey())
+	case m.SigningPubKey == nil:
+		return fmt.Sprintf("Manifest of %s %d without signing key", m.PublicKey.NodePublicKey(), m.Sequence)
+	default:
+		return fmt.Sprintf("Manifest of %s %d: %s", m.PublicKey.NodePublicKey(), m.Sequence, m.SigningPubKey.NodePublicKey())
+	}
+}
+
+// MarshalBinary returns the manifest as it is sent between servers
+func (m *Manifest) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	if err := encode(&b, m, false); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func checkManifestSignature(key PublicKey, hash Hash256, msg []byte, sig []byte) error {
+	ok, err := crypto.Verify(key.Bytes(), hash.Bytes(), msg, sig)
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		return fmt.Errorf("Bad signature by %s", key.NodePublicKey())
+	}
+	return nil
+}
+
+// Verify checks the master signature and, unless the manifest revokes the
+// master key, the signature of the signing key
+func (m *Manifest) Verify() error {
+	hash, msg, err := raw(m, HP_MANIFEST, true)
+	if err != nil {
+		return err
+	}
+	msg = append(HP_MANIFEST.Bytes(), msg...)
+	if err := checkManifestSignature(m.PublicKey, hash, msg, m.MasterSignature); err != nil {
+		return fmt.Errorf("%s: master %s", m, err)
+	}
+	if m.Revoked() {
+		return nil
+	}
+	switch {
+	case m.SigningPubKey == nil:
+		return fmt.Errorf("%s", m)
+	case *m.SigningPubKey == m.PublicKey:
+		return fmt.Errorf("%s: signing key is the master key", m)
+	case m.Signature == nil:
+		return fmt.Errorf("%s: missing signature", m)
+	}
+	if err := checkManifestSignature(*m.SigningPubKey, hash, msg, *m.Signature); err != nil {
+		return fmt.Errorf("%s: %s", m, err)
+	}
+	return nil
+}
+
+// SignManifest sets the keys of the manifest and signs it with both of
+// them. The signing key is nil for a revocation.
+func SignManifest(m *Manifest, master, signing crypto.Key) error {
+	copy(m.PublicKey[:], master.Public(nil))
+	m.SigningPubKey, m.Signature = nil, nil
+	if signing != nil {
+		m.SigningPubKey = new(PublicKey)
+		copy(m.SigningPubKey[:], signing.Public(nil))
+	}
+	hash, msg, err := raw(m, HP_MANIFEST, true)
+	if err != nil {
+		return err
+	}
+	msg = append(HP_MANIFEST.Bytes(), msg...)
+	sig, err := crypto.Sign(master.Private(nil), hash.Bytes(), msg)
+	if err != nil {
+		return err
+	}
+	m.MasterSignature = VariableLength(sig)
+	if signing != nil {
+		if sig, err = crypto.Sign(signing.Private(nil), hash.Bytes(), msg); err != nil {
+			return err
+		}
+		m.Signature = (*VariableLength)(&sig)
+	}
+	m.Hash, _, err = Raw(m)
+	return err
+}
+
+// ManifestCache keeps the latest manifest of each validator, so that its
+// current signing key can be found as it rotates, and the master key of
+// the signer of a validation
+type ManifestCache struct {
+	mu        sync.Mutex
+	manifests map[PublicKey]*Manifest
+	masters   map[PublicKey]PublicKey
+}
+
+func NewManifestCache() *ManifestCache {
+	return &ManifestCache{
+		manifests: make(map[PublicKey]*Manifest),
+		masters:   make(map[PublicKey]PublicKey),
+	}
+}
+
+// Apply verifies a manifest and keeps it if it is newer than the one
+// already known for its master key. It reports whether it was kept.
+func (c *ManifestCache) Apply(m *Manifest) (bool, error) {
+	if err := m.Verify(); err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.masters[m.PublicKey]; ok {
+		return false, fmt.Errorf("%s: master key is the signing key of another validator", m)
+	}
+	if !m.Revoked() {
+		if _, ok := c.manifests[*m.SigningPubKey]; ok {
+			return false, fmt.Errorf("%s: signing key is the master key of another validator", m)
+		}
+		if master, ok := c.masters[*m.SigningPubKey]; ok && master != m.PublicKey {
+			return false, fmt.Errorf("%s: signing key is used by %s", m, master.NodePublicKey())
+		}
+	}
+	previous := c.manifests[m.PublicKey]
+	if previous != nil {
+		if previous.Sequence >= m.Sequence {
+			return false, nil
+		}
+		if previous.SigningPubKey != nil {
+			delete(c.masters, *previous.SigningPubKey)
+		}
+	}
+	c.manifests[m.PublicKey] = m
+	if !m.Revoked() {
+		c.masters[*m.SigningPubKey] = m.PublicKey
+	}
+	return true, nil
+}
+
+// Manifest returns the latest manifest of the master key
+func (c *ManifestCache) Manifest(master PublicKey) (*Manifest, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.manifests[master]
+	return m, ok
+}
+
+// SigningKey returns the current signing key of the master key, which
+// is unknown once the master key is revoked
+func (c *ManifestCache) SigningKey(master PublicKey) (PublicKey, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m, ok := c.manifests[master]; ok && !m.Revoked() {
+		return *m.SigningPubKey, true
+	}
+	return PublicKey{}, false
+}
+
+// MasterKey returns the master key of a current signing key
+func (c *ManifestCache) MasterKey(signing PublicKey) (PublicKey, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	master, ok := c.masters[signing]
+	return master, ok
+}
+
+// Revoked reports whether the master key has been revoked
+func (c *ManifestCache) Revoked(master PublicKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m, ok := c.manifests[master]
+	return ok && m.Revoked()
+}
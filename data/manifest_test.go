@@ -0,0 +1,122 @@
+package data
+
+import (
+	"bytes"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+	. "gopkg.in/check.v1"
+)
+
+type ManifestSuite struct{}
+
+var _ = Suite(&ManifestSuite{})
+
+func manifestKeys(c *C, seeds ...string) []crypto.Key {
+	var keys []crypto.Key
+	for i, seed := range seeds {
+		var key crypto.Key
+		var err error
+		// Master keys are usually Ed25519, signing keys either
+		if i%2 == 0 {
+			key, err = crypto.NewEd25519Key([]byte(seed))
+		} else {
+			key, err = crypto.NewECDSAKey([]byte(seed))
+		}
+		c.Assert(err, IsNil)
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func signedManifest(c *C, sequence uint32, master, signing crypto.Key) *Manifest {
+	domain := VariableLength("example.com")
+	m := &Manifest{Sequence: sequence, Domain: &domain}
+	c.Assert(SignManifest(m, master, signing), IsNil)
+	return m
+}
+
+func (s *ManifestSuite) TestManifest(c *C) {
+	keys := manifestKeys(c, "master", "signing")
+	m := signedManifest(c, 1, keys[0], keys[1])
+	c.Assert(m.Verify(), IsNil)
+
+	b, err := m.MarshalBinary()
+	c.Assert(err, IsNil)
+	c.Check(b[0], Equals, byte(0x24)) // Sequence comes first
+	decoded, err := ReadManifest(bytes.NewReader(b))
+	c.Assert(err, IsNil)
+	c.Check(decoded.Verify(), IsNil)
+	c.Check(decoded.Sequence, Equals, uint32(1))
+	c.Check(decoded.PublicKey, Equals, m.PublicKey)
+	c.Check(*decoded.SigningPubKey, Equals, *m.SigningPubKey)
+	c.Check(string(*decoded.Domain), Equals, "example.com")
+	hash, err := NodeId(decoded)
+	c.Assert(err, IsNil)
+	c.Check(hash, Equals, m.Hash)
+
+	decoded.Sequence++
+	c.Check(decoded.Verify(), ErrorMatches, "Manifest of n.* 2: n.*: master Bad signature by n.*")
+	decoded.Sequence--
+	(*decoded.Signature)[10]++
+	c.Check(decoded.Verify(), ErrorMatches, "Manifest of n.* 1: n.*: .*")
+	decoded.Signature = nil
+	c.Check(decoded.Verify(), ErrorMatches, ".*: missing signature")
+
+	revocation := signedManifest(c, ManifestRevoked, keys[0], nil)
+	c.Check(revocation.Verify(), IsNil)
+	c.Check(revocation.String(), Matches, "Manifest of n.* revoked")
+	b, err = revocation.MarshalBinary()
+	c.Assert(err, IsNil)
+	decoded, err = ReadManifest(bytes.NewReader(b))
+	c.Assert(err, IsNil)
+	c.Check(decoded.Verify(), IsNil)
+	c.Check(decoded.SigningPubKey, IsNil)
+}
+
+func (s *ManifestSuite) TestManifestCache(c *C) {
+	keys := manifestKeys(c, "master", "first", "other", "second")
+	master, other := keys[0], keys[2]
+	first := signedManifest(c, 1, master, keys[1])
+	second := signedManifest(c, 2, master, keys[3])
+	var masterKey PublicKey
+	copy(masterKey[:], master.Public(nil))
+
+	cache := NewManifestCache()
+	_, ok := cache.SigningKey(masterKey)
+	c.Check(ok, Equals, false)
+	for _, test := range []struct {
+		manifest *Manifest
+		applied  bool
+		err      string
+		signing  *PublicKey
+	}{
+		{first, true, "", first.SigningPubKey},
+		{first, false, "", first.SigningPubKey},
+		{second, true, "", second.SigningPubKey},
+		{first, false, "", second.SigningPubKey},
+		{signedManifest(c, 1, other, keys[3]), false, ".*: signing key is used by n.*", second.SigningPubKey},
+		{signedManifest(c, 1, other, master), false, ".*: signing key is the master key of another validator", second.SigningPubKey},
+		{signedManifest(c, ManifestRevoked, master, nil), true, "", nil},
+	} {
+		applied, err := cache.Apply(test.manifest)
+		c.Check(applied, Equals, test.applied, Commentf("%s", test.manifest))
+		if test.err == "" {
+			c.Check(err, IsNil)
+		} else {
+			c.Check(err, ErrorMatches, test.err)
+		}
+		signing, ok := cache.SigningKey(masterKey)
+		c.Check(ok, Equals, test.signing != nil)
+		if test.signing != nil {
+			c.Check(signing, Equals, *test.signing)
+			found, ok := cache.MasterKey(signing)
+			c.Check(ok, Equals, true)
+			c.Check(found, Equals, masterKey)
+		}
+	}
+	c.Check(cache.Revoked(masterKey), Equals, true)
+	_, ok = cache.MasterKey(*first.SigningPubKey)
+	c.Check(ok, Equals, false)
+	_, ok = cache.MasterKey(*second.SigningPubKey)
+	c.Check(ok, Equals, false)
+}
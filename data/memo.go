@@ -1,5 +1,15 @@
 package data
 
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
 type Memo struct {
 	Memo struct {
 		MemoType   VariableLength
@@ -9,3 +19,177 @@ type Memo struct {
 }
 
 type Memos []Memo
+
+// The most the Memos of a transaction may take when serialised
+const MaxMemosSize = 1024
+
+// MemoFormats understood by Text and UnmarshalData
+const (
+	MemoFormatText = "text/plain"
+	MemoFormatJSON = "application/json"
+)
+
+// The characters allowed in a MemoType or MemoFormat, which are those
+// allowed in a URL
+const memoCharacters = "0123456789-._~:/?#[]@!$&'()*+,;=%" +
+	"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// NewMemo returns a memo of data with the type and format, either of
+// which may be empty
+func NewMemo(memoType, memoFormat string, memoData []byte) Memo {
+	var memo Memo
+	memo.Memo.MemoType = VariableLength(memoType)
+	memo.Memo.MemoFormat = VariableLength(memoFormat)
+	memo.Memo.MemoData = VariableLength(memoData)
+	return memo
+}
+
+// NewTextMemo returns a plain text memo
+func NewTextMemo(memoType, text string) Memo {
+	return NewMemo(memoType, MemoFormatText, []byte(text))
+}
+
+// NewJSONMemo returns a memo of v encoded as JSON
+func NewJSONMemo(memoType string, v interface{}) (Memo, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return Memo{}, err
+	}
+	return NewMemo(memoType, MemoFormatJSON, b), nil
+}
+
+func (m Memo) Type() string   { return string(m.Memo.MemoType) }
+func (m Memo) Format() string { return string(m.Memo.MemoFormat) }
+func (m Memo) Data() []byte   { return []byte(m.Memo.MemoData) }
+
+// mediaType returns the MemoFormat without any parameters, in lower case
+func (m Memo) mediaType() string {
+	mediaType, _, err := mime.ParseMediaType(m.Format())
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(m.Format()))
+	}
+	return mediaType
+}
+
+// IsText reports whether the data is text: a text or JSON MemoFormat, or
+// no MemoFormat and valid UTF-8
+func (m Memo) IsText() bool {
+	switch mediaType := m.mediaType(); {
+	case strings.HasPrefix(mediaType, "text/"), mediaType == MemoFormatJSON:
+		return true
+	case mediaType == "":
+		return utf8.Valid(m.Memo.MemoData)
+	default:
+		return false
+	}
+}
+
+// Text returns the data as a string, and whether it is text
+func (m Memo) Text() (string, bool) {
+	if !m.IsText() {
+		return "", false
+	}
+	return string(m.Memo.MemoData), true
+}
+
+// UnmarshalData decodes the data as JSON into v. The MemoFormat must be
+// JSON, or missing.
+func (m Memo) UnmarshalData(v interface{}) error {
+	if mediaType := m.mediaType(); mediaType != MemoFormatJSON && mediaType != "" {
+		return fmt.Errorf("Memo is %s not %s", m.Format(), MemoFormatJSON)
+	}
+	return json.Unmarshal(m.Memo.MemoData, v)
+}
+
+// Check returns an error if the memo would be rejected: an empty memo, or
+// a MemoType or MemoFormat with characters which aren't allowed in a URL
+func (m Memo) Check() error {
+	if len(m.Memo.MemoType) == 0 && len(m.Memo.MemoData) == 0 && len(m.Memo.MemoFormat) == 0 {
+		return fmt.Errorf("Memo is empty")
+	}
+	disallowed := func(r rune) bool { return !strings.ContainsRune(memoCharacters, r) }
+	if i := bytes.IndexFunc(m.Memo.MemoType, disallowed); i >= 0 {
+		return fmt.Errorf("MemoType %q has a character not allowed in a URL at %d", m.Memo.MemoType, i)
+	}
+	if i := bytes.IndexFunc(m.Memo.MemoFormat, disallowed); i >= 0 {
+		return fmt.Errorf("MemoFormat %q has a character not allowed in a URL at %d", m.Memo.MemoFormat, i)
+	}
+	return nil
+}
+
+// Size returns how many bytes the memos take when serialised, as counted
+// against MaxMemosSize
+func (m Memos) Size() int {
+	var b bytes.Buffer
+	for _, memo := range m {
+		b.WriteByte(0xEA)
+		for i, field := range []VariableLength{memo.Memo.MemoType, memo.Memo.MemoData, memo.Memo.MemoFormat} {
+			if len(field) > 0 {
+				b.WriteByte(0x7C + byte(i))
+				writeVariableLength(&b, field)
+			}
+		}
+		b.WriteByte(0xE1)
+	}
+	return b.Len()
+}
+
+// Check returns an error if any memo would be rejected, or if together
+// they are larger than MaxMemosSize
+func (m Memos) Check() error {
+	for _, memo := range m {
+		if err := memo.Check(); err != nil {
+			return err
+		}
+	}
+	if size := m.Size(); size > MaxMemosSize {
+		return fmt.Errorf("Memos take %d bytes, more than %d", size, MaxMemosSize)
+	}
+	return nil
+}
+
+// Find returns the first memo with memoType, if any
+func (m Memos) Find(memoType string) (Memo, bool) {
+	for _, memo := range m {
+		if memo.Type() == memoType {
+			return memo, true
+		}
+	}
+	return Memo{}, false
+}
+
+// PlainMemo is a memo as text, for applications which show or accept
+// memos as JSON. Data which isn't text is hex, and Hex is set.
+type PlainMemo struct {
+	Type   string `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+	Data   string `json:"data,omitempty"`
+	Hex    bool   `json:"hex,omitempty"`
+}
+
+// Plain returns the memo as text
+func (m Memo) Plain() PlainMemo {
+	plain := PlainMemo{Type: m.Type(), Format: m.Format()}
+	if text, ok := m.Text(); ok {
+		plain.Data = text
+	} else {
+		plain.Data, plain.Hex = hex.EncodeToString(m.Memo.MemoData), true
+	}
+	return plain
+}
+
+// Memo returns the memo which p is
+func (p PlainMemo) Memo() (Memo, error) {
+	data := []byte(p.Data)
+	if p.Hex {
+		var err error
+		if data, err = hex.DecodeString(p.Data); err != nil {
+			return Memo{}, fmt.Errorf("Bad hex memo data: %s", err)
+		}
+	}
+	memo := NewMemo(p.Type, p.Format, data)
+	if err := memo.Check(); err != nil {
+		return Memo{}, err
+	}
+	return memo, nil
+}
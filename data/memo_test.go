@@ -0,0 +1,93 @@
+package data
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type MemoSuite struct{}
+
+var _ = Suite(&MemoSuite{})
+
+func (s *MemoSuite) TestMemos(c *C) {
+	text := NewTextMemo("invoice", "Thanks")
+	c.Check(text.Type(), Equals, "invoice")
+	c.Check(text.Format(), Equals, "text/plain")
+	plain, ok := text.Text()
+	c.Check(plain, Equals, "Thanks")
+	c.Check(ok, Equals, true)
+	b, err := json.Marshal(text)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, `{"Memo":{"MemoType":"696E766F696365","MemoData":"5468616E6B73","MemoFormat":"746578742F706C61696E"}}`)
+
+	order, err := NewJSONMemo("order", map[string]int{"id": 7})
+	c.Assert(err, IsNil)
+	var decoded struct{ ID int }
+	c.Check(order.UnmarshalData(&decoded), IsNil)
+	c.Check(decoded.ID, Equals, 7)
+	c.Check(text.UnmarshalData(&decoded), ErrorMatches, "Memo is text/plain not application/json")
+	c.Check(NewMemo("", "text/plain;charset=utf-8", []byte{0xff}).IsText(), Equals, true)
+
+	binary := NewMemo("", "application/octet-stream", []byte{0xca, 0xfe})
+	_, ok = binary.Text()
+	c.Check(ok, Equals, false)
+	c.Check(NewMemo("", "", []byte{0xca, 0xfe}).IsText(), Equals, false)
+	c.Check(NewMemo("", "", []byte("cafe")).IsText(), Equals, true)
+
+	memos := Memos{text, order, binary}
+	found, ok := memos.Find("order")
+	c.Check(ok, Equals, true)
+	c.Check(found.Data(), DeepEquals, []byte(`{"id":7}`))
+	_, ok = memos.Find("refund")
+	c.Check(ok, Equals, false)
+
+	// Size counts what is serialised
+	tx := &AccountSet{TxBase: TxBase{TransactionType: ACCOUNT_SET, Memos: memos}}
+	_, raw, err := Raw(tx)
+	c.Assert(err, IsNil)
+	without := &AccountSet{TxBase: TxBase{TransactionType: ACCOUNT_SET}}
+	_, rawWithout, err := Raw(without)
+	c.Assert(err, IsNil)
+	// The Memos field and its end
+	c.Check(memos.Size(), Equals, len(raw)-len(rawWithout)-2)
+	c.Check(memos.Check(), IsNil)
+}
+
+func (s *MemoSuite) TestCheck(c *C) {
+	for _, test := range []struct {
+		memos Memos
+		err   string
+	}{
+		{Memos{{}}, "Memo is empty"},
+		{Memos{NewMemo("invoice number", "", nil)}, `MemoType "invoice number" has a character not allowed in a URL at 7`},
+		{Memos{NewMemo("", "text/plain; charset=utf-8", nil)}, `MemoFormat "text/plain; charset=utf-8" has a character .* at 11`},
+		{Memos{NewMemo("", "", make([]byte, 510)), NewMemo("", "", make([]byte, 510))}, "Memos take 1030 bytes, more than 1024"},
+		{Memos{NewMemo("", "", make([]byte, 1020))}, "Memos take 1025 bytes, more than 1024"},
+	} {
+		c.Check(test.memos.Check(), ErrorMatches, test.err)
+	}
+	c.Check(Memos{NewMemo("", "", make([]byte, 1019))}.Check(), IsNil)
+	c.Check(Memos(nil).Check(), IsNil)
+}
+
+func (s *MemoSuite) TestPlainMemo(c *C) {
+	for _, memo := range []Memo{
+		NewTextMemo("invoice", "Thanks"),
+		NewMemo("key", "application/octet-stream", []byte{0xca, 0xfe}),
+	} {
+		plain := memo.Plain()
+		b, err := json.Marshal(plain)
+		c.Assert(err, IsNil)
+		var back PlainMemo
+		c.Assert(json.Unmarshal(b, &back), IsNil)
+		again, err := back.Memo()
+		c.Assert(err, IsNil)
+		c.Check(again, DeepEquals, memo)
+	}
+	c.Check(NewMemo("key", "", []byte{0xca, 0xfe}).Plain(), Equals, PlainMemo{Type: "key", Data: "cafe", Hex: true})
+	_, err := PlainMemo{Data: "xyz", Hex: true}.Memo()
+	c.Check(err, ErrorMatches, "Bad hex memo data: .*")
+	_, err = PlainMemo{}.Memo()
+	c.Check(err, ErrorMatches, "Memo is empty")
+}
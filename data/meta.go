@@ -0,0 +1,226 @@
+package data
+
+import "fmt"
+
+// BalanceChange is the change to an account's balance of XRP, or of a
+// currency on its trust line with a counterparty. The XRP change of the
+// sender of a transaction includes the fee, as with ripple-lib's
+// parseBalanceChanges.
+type BalanceChange struct {
+	Account      Account
+	Counterparty Account  // Zero for XRP
+	Currency     Currency // Zero for XRP
+	Balance      Value    // After the transaction
+	Change       Value
+}
+
+// Amount returns the change as an Amount issued by the counterparty
+func (b BalanceChange) Amount() *Amount {
+	return newAmount(b.Change.Clone(), b.Currency, b.Counterparty)
+}
+
+func (b BalanceChange) String() string {
+	return fmt.Sprintf("%-34s %s Balance: %s", b.Account, b.Amount().Machine(), b.Balance)
+}
+
+// EntryChange is a ledger entry affected by a transaction, with the
+// previous values of the fields that changed, if any
+type EntryChange struct {
+	LedgerIndex *Hash256
+	Final       LedgerEntry
+	Previous    LedgerEntry
+}
+
+// OfferExecution is the part of an offer taken by a transaction, in which
+// the taker paid the owner of the offer and got from them in return
+type OfferExecution struct {
+	Owner    Account
+	Sequence uint32
+	Taker    Account
+	Paid     Amount
+	Got      Amount
+	Deleted  bool // No more of the offer remains in the ledger
+}
+
+func (o OfferExecution) String() string {
+	return fmt.Sprintf("%-34s paid %s to %-34s for %s", o.Taker, o.Paid.Machine(), o.Owner, o.Got.Machine())
+}
+
+// MetaAnalysis is what the metadata of a transaction says it did
+type MetaAnalysis struct {
+	Balances []BalanceChange
+	Created  []EntryChange
+	Modified []EntryChange
+	Deleted  []EntryChange
+	Offers   []OfferExecution
+}
+
+// ByAccount groups the balance changes by account
+func (m *MetaAnalysis) ByAccount() map[Account][]BalanceChange {
+	accounts := make(map[Account][]BalanceChange)
+	for _, b := range m.Balances {
+		accounts[b.Account] = append(accounts[b.Account], b)
+	}
+	return accounts
+}
+
+// Analyse returns the balance changes, affected ledger entries and offer
+// executions of a transaction, in the order of its affected nodes
+func (txm *TransactionWithMetaData) Analyse() (*MetaAnalysis, error) {
+	var m MetaAnalysis
+	taker := txm.Transaction.GetBase().Account
+	for i := range txm.MetaData.AffectedNodes {
+		node, final, previous, state := txm.MetaData.AffectedNodes[i].AffectedNode()
+		entry := EntryChange{LedgerIndex: node.LedgerIndex, Final: final, Previous: node.PreviousFields}
+		switch state {
+		case Created:
+			m.Created = append(m.Created, entry)
+		case Modified:
+			m.Modified = append(m.Modified, entry)
+		case Deleted:
+			m.Deleted = append(m.Deleted, entry)
+		}
+		var err error
+		switch v := final.(type) {
+		case *AccountRoot:
+			err = m.addAccountRoot(v, previous.(*AccountRoot), state)
+		case *RippleState:
+			err = m.addRippleState(v, previous.(*RippleState), state)
+		case *Offer:
+			if state != Created {
+				err = m.addOffer(taker, v, previous.(*Offer), state)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &m, nil
+}
+
+// balanceChange returns the change from the previous balance, which is
+// zero for a created entry, or nil if the balance did not change
+func balanceChange(final, previous *Value, state LedgerEntryState) (*Value, error) {
+	switch {
+	case final == nil:
+		return nil, nil
+	case state == Created:
+		previous = final.ZeroClone()
+	case previous == nil:
+		return nil, nil
+	}
+	change, err := final.Subtract(*previous)
+	if err != nil || change.IsZero() {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (m *MetaAnalysis) addAccountRoot(final, previous *AccountRoot, state LedgerEntryState) error {
+	change, err := balanceChange(final.Balance, previous.Balance, state)
+	if err != nil || change == nil {
+		return err
+	}
+	m.Balances = append(m.Balances, BalanceChange{
+		Account: *final.Account,
+		Balance: *final.Balance,
+		Change:  *change,
+	})
+	return nil
+}
+
+// addRippleState adds the change for each side of a trust line. The
+// balance is held from the point of view of the low account.
+func (m *MetaAnalysis) addRippleState(final, previous *RippleState, state LedgerEntryState) error {
+	if final.Balance == nil || final.LowLimit == nil || final.HighLimit == nil {
+		return nil
+	}
+	var before *Value
+	if previous.Balance != nil {
+		before = previous.Balance.Value
+	}
+	change, err := balanceChange(final.Balance.Value, before, state)
+	if err != nil || change == nil {
+		return err
+	}
+	low, high, currency := final.LowLimit.Issuer, final.HighLimit.Issuer, final.Balance.Currency
+	m.Balances = append(m.Balances, BalanceChange{
+		Account:      low,
+		Counterparty: high,
+		Currency:     currency,
+		Balance:      *final.Balance.Value,
+		Change:       *change,
+	}, BalanceChange{
+		Account:      high,
+		Counterparty: low,
+		Currency:     currency,
+		Balance:      *final.Balance.Value.Negate(),
+		Change:       *change.Negate(),
+	})
+	return nil
+}
+
+// addOffer adds what was taken from an offer. Either side may be
+// unchanged when a tiny amount is taken, and an offer deleted without
+// a change was cancelled, expired or unfunded rather than taken.
+func (m *MetaAnalysis) addOffer(taker Account, final, previous *Offer, state LedgerEntryState) error {
+	if final.TakerPays == nil || final.TakerGets == nil || final.Account == nil {
+		return nil
+	}
+	if previous.TakerPays == nil && previous.TakerGets == nil {
+		return nil
+	}
+	taken := func(final, previous *Amount) (*Amount, error) {
+		if previous == nil {
+			return final.ZeroClone(), nil
+		}
+		return previous.Subtract(final)
+	}
+	paid, err := taken(final.TakerPays, previous.TakerPays)
+	if err != nil {
+		return err
+	}
+	got, err := taken(final.TakerGets, previous.TakerGets)
+	if err != nil {
+		return err
+	}
+	execution := OfferExecution{
+		Owner:   *final.Account,
+		Taker:   taker,
+		Paid:    *paid,
+		Got:     *got,
+		Deleted: state == Deleted,
+	}
+	if final.Sequence != nil {
+		execution.Sequence = *final.Sequence
+	}
+	m.Offers = append(m.Offers, execution)
+	return nil
+}
+
+// OfferChange is a change to an offer in a book. A created offer was
+// placed, a modified one was partly consumed, and a deleted one was
+// consumed, cancelled, expired or found unfunded. Offer holds the final
+// fields.
+type OfferChange struct {
+	State       LedgerEntryState
+	LedgerIndex *Hash256
+	Offer       *Offer
+}
+
+// OfferChanges returns the changes to offers by a transaction, by book and
+// in the order of its affected nodes, which is enough to keep a local copy
+// of the books up to date from the transactions stream
+func (txm *TransactionWithMetaData) OfferChanges() map[Book][]OfferChange {
+	books := make(map[Book][]OfferChange)
+	for i := range txm.MetaData.AffectedNodes {
+		node, final, _, state := txm.MetaData.AffectedNodes[i].AffectedNode()
+		offer, ok := final.(*Offer)
+		if !ok || offer.TakerPays == nil || offer.TakerGets == nil {
+			continue
+		}
+		book := offer.Book()
+		books[book] = append(books[book], OfferChange{State: state, LedgerIndex: node.LedgerIndex, Offer: offer})
+	}
+	return books
+}
@@ -0,0 +1,122 @@
+package data
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+type MetaSuite struct{}
+
+var _ = Suite(&MetaSuite{})
+
+func readTransaction(c *C, filename string) *TransactionWithMetaData {
+	b, err := ioutil.ReadFile(filename)
+	c.Assert(err, IsNil)
+	var txm TransactionWithMetaData
+	c.Assert(json.Unmarshal(b, &txm), IsNil)
+	return &txm
+}
+
+// checkBalances checks that XRP is only destroyed by the fee and that both
+// sides of each trust line agree
+func checkBalances(c *C, txm *TransactionWithMetaData, m *MetaAnalysis) {
+	xrp := zeroNative.Clone()
+	lines := make(map[[2]Account]*Value)
+	for _, b := range m.Balances {
+		c.Check(b.Change.IsZero(), Equals, false)
+		if b.Change.IsNative() {
+			xrp, _ = xrp.Add(b.Change)
+			continue
+		}
+		key := [2]Account{b.Account, b.Counterparty}
+		if b.Counterparty.Less(b.Account) {
+			key = [2]Account{b.Counterparty, b.Account}
+		}
+		if sum, ok := lines[key]; ok {
+			lines[key], _ = sum.Add(b.Change)
+		} else {
+			lines[key] = b.Change.Clone()
+		}
+	}
+	c.Check(xrp.Equals(*txm.GetBase().Fee.Negate()), Equals, true, Commentf("%s", xrp))
+	for _, sum := range lines {
+		c.Check(sum.IsZero(), Equals, true)
+	}
+}
+
+func (s *MetaSuite) TestPayment(c *C) {
+	txm := readTransaction(c, "testdata/transaction_payment_with_rippling.json")
+	m, err := txm.Analyse()
+	c.Assert(err, IsNil)
+	c.Check(m.Created, HasLen, 0)
+	c.Check(m.Modified, HasLen, 6)
+	c.Check(m.Deleted, HasLen, 0)
+	c.Check(m.Offers, HasLen, 0)
+	c.Check(m.Balances, HasLen, 11)
+	checkBalances(c, txm, m)
+	sender := m.ByAccount()[txm.GetBase().Account]
+	c.Assert(sender, HasLen, 3)
+	c.Check(sender[1].Amount().Equals(*amountCheck("-12")), Equals, true)
+	c.Check(sender[1].Balance.Equals(*valueCheck("17317999772")), Equals, true)
+}
+
+func (s *MetaSuite) TestOfferCreate(c *C) {
+	txm := readTransaction(c, "testdata/transaction_offercreate.json")
+	m, err := txm.Analyse()
+	c.Assert(err, IsNil)
+	c.Check(m.Created, HasLen, 1)
+	c.Check(m.Deleted, HasLen, 14)
+	c.Check(m.Created[0].Final.GetLedgerEntryType(), Equals, RIPPLE_STATE)
+	c.Check(m.Created[0].Previous, IsNil)
+	checkBalances(c, txm, m)
+
+	// The taker paid the owners of the offers all the XRP they lost
+	// except the fee
+	c.Assert(m.Offers, HasLen, 8)
+	paid := zeroNative.Clone()
+	for i, offer := range m.Offers {
+		c.Check(offer.Taker, Equals, txm.GetBase().Account)
+		c.Check(offer.Deleted, Equals, i > 0)
+		paid, err = paid.Add(*offer.Paid.Value)
+		c.Assert(err, IsNil)
+	}
+	c.Check(paid.Equals(*valueCheck("516418508783")), Equals, true, Commentf("%s", paid))
+	c.Check(m.Offers[0].Owner.String(), Equals, "rwBYyfufTzk77zUSKEu4MvixfarC35av1J")
+	c.Check(m.Offers[0].Sequence, Equals, uint32(2308))
+	c.Check(m.Offers[0].Got.Equals(*amountCheck("2.032398983215035/BTC/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")), Equals, true, Commentf("%s", m.Offers[0].Got))
+}
+
+func (s *MetaSuite) TestTinyOffer(c *C) {
+	txm := readTransaction(c, "testdata/transaction_payment_bug.json")
+	m, err := txm.Analyse()
+	c.Assert(err, IsNil)
+	checkBalances(c, txm, m)
+	c.Assert(m.Offers, HasLen, 1)
+	c.Check(m.Offers[0].Paid.IsZero(), Equals, true)
+	c.Check(m.Offers[0].Got.Equals(*amountCheck("0.0000000003650523952/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")), Equals, true, Commentf("%s", m.Offers[0].Got))
+}
+
+func (s *MetaSuite) TestOfferChanges(c *C) {
+	txm := readTransaction(c, "testdata/transaction_offercreate.json")
+	books := txm.OfferChanges()
+	c.Assert(books, HasLen, 1)
+	xrpBTC := Book{Pays: Asset{Currency: "XRP"}, Gets: Asset{Currency: "BTC", Issuer: "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"}}
+	changes := books[xrpBTC]
+	c.Assert(changes, HasLen, 8)
+	for i, change := range changes {
+		c.Check(change.State == Modified, Equals, i == 0)
+		c.Check(change.Offer.Book(), Equals, xrpBTC)
+	}
+	c.Check(changes[0].LedgerIndex.String(), Equals, "0CB6C2A241D4BB734233687602F53C4B66179C7EEFAB6967C5A78D10977F99F5")
+	c.Check(*changes[0].Offer.Sequence, Equals, uint32(2308))
+
+	txm = readTransaction(c, "testdata/transaction_payment_bug.json")
+	books = txm.OfferChanges()
+	c.Assert(books, HasLen, 2)
+	usdXRP := Book{Pays: Asset{Currency: "USD", Issuer: "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"}, Gets: Asset{Currency: "XRP"}}
+	c.Assert(books[usdXRP], HasLen, 1)
+	c.Check(books[usdXRP][0].State, Equals, Created)
+	c.Check(books[Book{Pays: usdXRP.Gets, Gets: usdXRP.Pays}][0].State, Equals, Deleted)
+}
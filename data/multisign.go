@@ -0,0 +1,69 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// MultiSigner is one of the Signers of a multisigned transaction
+type MultiSigner struct {
+	Signer struct {
+		Account       Account
+		SigningPubKey PublicKey
+		TxnSignature  VariableLength
+	}
+}
+
+type MultiSigners []MultiSigner
+
+// CheckSigners returns an error explaining why the Signers of tx don't
+// satisfy the signer list, or nil if they do. The signatures themselves
+// are not verified.
+func (s *SignerList) CheckSigners(tx Transaction) error {
+	base := tx.GetBase()
+	if base == nil {
+		return fmt.Errorf("%s cannot be multisigned", tx.GetType())
+	}
+	if base.SigningPubKey != nil && !base.SigningPubKey.IsZero() {
+		return fmt.Errorf("Multisigned transaction has a SigningPubKey")
+	}
+	if len(base.Signers) == 0 {
+		return fmt.Errorf("Multisigned transaction has no Signers")
+	}
+	var quorum uint32
+	if s.SignerQuorum != nil {
+		quorum = *s.SignerQuorum
+	}
+	var weight uint32
+	for i, signer := range base.Signers {
+		account := signer.Signer.Account
+		switch {
+		case i > 0 && bytes.Compare(base.Signers[i-1].Signer.Account[:], account[:]) >= 0:
+			return fmt.Errorf("Signers must be unique and sorted by account")
+		case account.Equals(base.Account):
+			return fmt.Errorf("Account %s cannot sign for itself", account)
+		case signer.Signer.SigningPubKey.IsZero() || len(signer.Signer.TxnSignature) == 0:
+			return fmt.Errorf("Signer %s has not signed", account)
+		}
+		entry := s.find(account)
+		if entry == nil {
+			return fmt.Errorf("Signer %s is not in the signer list", account)
+		}
+		if entry.SignerWeight != nil {
+			weight += uint32(*entry.SignerWeight)
+		}
+	}
+	if weight < quorum {
+		return fmt.Errorf("Signer weight %d is below the quorum of %d", weight, quorum)
+	}
+	return nil
+}
+
+func (s *SignerList) find(account Account) *SignerEntry {
+	for i, entry := range s.SignerEntries {
+		if entry.Account != nil && entry.Account.Equals(account) {
+			return &s.SignerEntries[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "gopkg.in/check.v1"
+)
+
+type MultiSignSuite struct{}
+
+var _ = Suite(&MultiSignSuite{})
+
+const (
+	multisignedPayment = `{
+		"TransactionType": "Payment",
+		"Account": "rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe",
+		"Destination": "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh",
+		"Amount": "1000000",
+		"Fee": "30",
+		"Sequence": 4,
+		"SigningPubKey": "",
+		"Signers": [{
+			"Signer": {
+				"Account": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B",
+				"SigningPubKey": "02B3EC4E5DD96029A647CFA20DA07FE1F85296505552CCAC114087E66B46BD77DF",
+				"TxnSignature": "30440220"
+			}
+		}, {
+			"Signer": {
+				"Account": "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59",
+				"SigningPubKey": "03FDDCF97E9DF6E1E8AC2BF2B0B5A5C7D5A5F5D1B2E5F4B5B3A1B6C5D4E3F2A1B0",
+				"TxnSignature": "30450221"
+			}
+		}]
+	}`
+	signerList = `{
+		"LedgerEntryType": "SignerList",
+		"SignerQuorum": 2,
+		"SignerEntries": [
+			{"SignerEntry": {"Account": "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh", "SignerWeight": 2}},
+			{"SignerEntry": {"Account": "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59", "SignerWeight": 1}},
+			{"SignerEntry": {"Account": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "SignerWeight": 1}}
+		]
+	}`
+)
+
+func (s *MultiSignSuite) TestCheckSigners(c *C) {
+	var tx Payment
+	c.Assert(json.Unmarshal([]byte(multisignedPayment), &tx), IsNil)
+	c.Assert(tx.Signers, HasLen, 2)
+	c.Check(tx.Signers[1].Signer.Account.String(), Equals, "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59")
+	var list SignerList
+	c.Assert(json.Unmarshal([]byte(signerList), &list), IsNil)
+	c.Assert(list.SignerEntries, HasLen, 3)
+
+	c.Check(list.CheckSigners(&tx), IsNil)
+
+	quorum := uint32(3)
+	list.SignerQuorum = &quorum
+	c.Check(list.CheckSigners(&tx), ErrorMatches, "Signer weight 2 is below the quorum of 3")
+
+	list.SignerEntries = list.SignerEntries[:2]
+	c.Check(list.CheckSigners(&tx), ErrorMatches, "Signer rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B is not in the signer list")
+
+	tx.Signers[0], tx.Signers[1] = tx.Signers[1], tx.Signers[0]
+	c.Check(list.CheckSigners(&tx), ErrorMatches, "Signers must be unique and sorted by account")
+
+	tx.Signers = tx.Signers[:1]
+	tx.Signers[0].Signer.TxnSignature = nil
+	c.Check(list.CheckSigners(&tx), ErrorMatches, "Signer r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59 has not signed")
+
+	tx.SigningPubKey = &tx.Signers[0].Signer.SigningPubKey
+	c.Check(list.CheckSigners(&tx), ErrorMatches, "Multisigned transaction has a SigningPubKey")
+}
+
+func (s *MultiSignSuite) TestSignerEntries(c *C) {
+	var list SignerList
+	c.Assert(json.Unmarshal([]byte(signerList), &list), IsNil)
+	tx := &SignerListSet{
+		TxBase:        TxBase{TransactionType: SIGNER_LIST_SET},
+		SignerQuorum:  *list.SignerQuorum,
+		SignerEntries: list.SignerEntries,
+	}
+	_, raw, err := Raw(tx)
+	c.Assert(err, IsNil)
+	// Each entry is an object in the array, as is each Memo
+	c.Check(fmt.Sprintf("%X", raw), Matches, ".*F4EB130002.*E1EB130001.*E1F1.*")
+	c.Check(VerifyRoundTrip(tx), IsNil)
+}
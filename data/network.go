@@ -0,0 +1,91 @@
+package data
+
+import "fmt"
+
+// Networks with IDs up to MaxLegacyNetworkID, which includes mainnet,
+// testnet and devnet, must not have a NetworkID in their transactions.
+// All others must, so that a transaction signed for one of them can't be
+// replayed on another.
+const MaxLegacyNetworkID uint32 = 1024
+
+// Network is a ledger which transactions are signed for, with the
+// reserves of accounts on it
+type Network struct {
+	Name         string
+	ID           uint32
+	BaseReserve  Value // Of an account
+	OwnerReserve Value // Of each object an account owns
+}
+
+func reserveDrops(n int64) Value {
+	v, err := NewNativeValue(n)
+	if err != nil {
+		panic(err)
+	}
+	return *v
+}
+
+var (
+	Mainnet = &Network{Name: "mainnet", ID: 0, BaseReserve: reserveDrops(1000000), OwnerReserve: reserveDrops(200000)}
+	Testnet = &Network{Name: "testnet", ID: 1, BaseReserve: reserveDrops(1000000), OwnerReserve: reserveDrops(200000)}
+	Devnet  = &Network{Name: "devnet", ID: 2, BaseReserve: reserveDrops(1000000), OwnerReserve: reserveDrops(200000)}
+)
+
+// NewNetwork returns a custom network, such as a sidechain, with the
+// reserves in drops
+func NewNetwork(name string, id uint32, baseReserve, ownerReserve int64) (*Network, error) {
+	base, err := NewNativeValue(baseReserve)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := NewNativeValue(ownerReserve)
+	if err != nil {
+		return nil, err
+	}
+	return &Network{Name: name, ID: id, BaseReserve: *base, OwnerReserve: *owner}, nil
+}
+
+func (n *Network) String() string {
+	return fmt.Sprintf("%s (%d)", n.Name, n.ID)
+}
+
+// RequiresNetworkID reports whether transactions must have a NetworkID
+func (n *Network) RequiresNetworkID() bool {
+	return n.ID > MaxLegacyNetworkID
+}
+
+// SetNetworkID sets the NetworkID of tx for the network, removing it on
+// networks which don't want one
+func (n *Network) SetNetworkID(tx Transaction) {
+	base := tx.GetBase()
+	if !n.RequiresNetworkID() {
+		base.NetworkID = nil
+		return
+	}
+	id := n.ID
+	base.NetworkID = &id
+}
+
+// Check returns an error unless tx may be applied on the network
+func (n *Network) Check(tx Transaction) error {
+	base := tx.GetBase()
+	switch {
+	case base.NetworkID == nil && n.RequiresNetworkID():
+		return fmt.Errorf("NetworkID is missing for %s", n)
+	case base.NetworkID != nil && *base.NetworkID != n.ID:
+		return fmt.Errorf("NetworkID %d is not that of %s", *base.NetworkID, n)
+	case base.NetworkID != nil && !n.RequiresNetworkID():
+		return fmt.Errorf("NetworkID must not be set for %s", n)
+	}
+	return nil
+}
+
+// Reserve returns the XRP an account which owns count objects must hold
+func (n *Network) Reserve(count uint32) (*Value, error) {
+	base, owner := n.BaseReserve.Rat(), n.OwnerReserve.Rat()
+	if !base.IsInt() || !owner.IsInt() {
+		return nil, fmt.Errorf("Bad reserves for %s", n)
+	}
+	drops := base.Num().Int64() + int64(count)*owner.Num().Int64()
+	return NewNativeValue(drops)
+}
@@ -0,0 +1,59 @@
+package data
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type NetworkSuite struct{}
+
+var _ = Suite(&NetworkSuite{})
+
+func (s *NetworkSuite) TestNetworkID(c *C) {
+	sidechain, err := NewNetwork("sidechain", 21337, 10000000, 2000000)
+	c.Assert(err, IsNil)
+	c.Check(Mainnet.RequiresNetworkID(), Equals, false)
+	c.Check(sidechain.RequiresNetworkID(), Equals, true)
+
+	tx := TxFactory[ACCOUNT_SET]()
+	c.Check(Mainnet.Check(tx), IsNil)
+	c.Check(sidechain.Check(tx), ErrorMatches, "NetworkID is missing for sidechain \\(21337\\)")
+	sidechain.SetNetworkID(tx)
+	c.Check(*tx.GetBase().NetworkID, Equals, uint32(21337))
+	c.Check(sidechain.Check(tx), IsNil)
+	c.Check(Mainnet.Check(tx), ErrorMatches, "NetworkID 21337 is not that of mainnet \\(0\\)")
+	other, err := NewNetwork("other", 1025, 0, 0)
+	c.Assert(err, IsNil)
+	c.Check(other.Check(tx), ErrorMatches, "NetworkID 21337 is not that of other \\(1025\\)")
+
+	_, raw, err := Raw(tx)
+	c.Assert(err, IsNil)
+	c.Check(strings.HasPrefix(strings.ToUpper(hex.EncodeToString(raw)), "1200032100005359"), Equals, true)
+	decoded, err := ReadTransaction(bytes.NewReader(raw))
+	c.Assert(err, IsNil)
+	c.Check(*decoded.GetBase().NetworkID, Equals, uint32(21337))
+
+	id := Testnet.ID
+	tx.GetBase().NetworkID = &id
+	c.Check(Testnet.Check(tx), ErrorMatches, "NetworkID must not be set for testnet \\(1\\)")
+	Testnet.SetNetworkID(tx)
+	c.Check(tx.GetBase().NetworkID, IsNil)
+	c.Check(Testnet.Check(tx), IsNil)
+}
+
+func (s *NetworkSuite) TestReserve(c *C) {
+	reserve, err := Mainnet.Reserve(0)
+	c.Assert(err, IsNil)
+	c.Check(reserve.String(), Equals, "1")
+	reserve, err = Mainnet.Reserve(3)
+	c.Assert(err, IsNil)
+	c.Check(reserve.String(), Equals, "1.6")
+	sidechain, err := NewNetwork("sidechain", 21337, 10000000, 2000000)
+	c.Assert(err, IsNil)
+	reserve, err = sidechain.Reserve(2)
+	c.Assert(err, IsNil)
+	c.Check(reserve.String(), Equals, "14")
+}
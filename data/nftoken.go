@@ -0,0 +1,55 @@
+package data
+
+import (
+	"encoding/binary"
+)
+
+type NFTokenFlag uint16
+
+// NFToken flags, fixed when the token is minted
+const (
+	NFTokenBurnable     NFTokenFlag = 0x0001
+	NFTokenOnlyXRP      NFTokenFlag = 0x0002
+	NFTokenTrustLine    NFTokenFlag = 0x0004
+	NFTokenTransferable NFTokenFlag = 0x0008
+)
+
+// NFTokenDetails are the properties packed into an NFTokenID
+type NFTokenDetails struct {
+	Flags       NFTokenFlag
+	TransferFee uint16 // In units of 1/100000, so 50000 is 50%
+	Issuer      Account
+	Taxon       uint32
+	Serial      uint32
+}
+
+// NewNFTokenDetails unpacks an NFTokenID. The taxon is stored
+// scrambled by the serial, so that tokens with the same taxon
+// are not stored together in a page.
+func NewNFTokenDetails(id Hash256) *NFTokenDetails {
+	d := &NFTokenDetails{
+		Flags:       NFTokenFlag(binary.BigEndian.Uint16(id[0:2])),
+		TransferFee: binary.BigEndian.Uint16(id[2:4]),
+		Serial:      binary.BigEndian.Uint32(id[28:32]),
+	}
+	copy(d.Issuer[:], id[4:24])
+	d.Taxon = scrambleTaxon(binary.BigEndian.Uint32(id[24:28]), d.Serial)
+	return d
+}
+
+// scrambleTaxon both scrambles and unscrambles a taxon with the serial
+func scrambleTaxon(taxon, serial uint32) uint32 {
+	return taxon ^ (384160001*serial + 2459)
+}
+
+// NFTokenID packs the details into the ID of the token, which for a token
+// about to be minted has the MintedNFTokens of the issuer as its serial
+func (d *NFTokenDetails) NFTokenID() Hash256 {
+	var id Hash256
+	binary.BigEndian.PutUint16(id[0:2], uint16(d.Flags))
+	binary.BigEndian.PutUint16(id[2:4], d.TransferFee)
+	copy(id[4:24], d.Issuer[:])
+	binary.BigEndian.PutUint32(id[24:28], scrambleTaxon(d.Taxon, d.Serial))
+	binary.BigEndian.PutUint32(id[28:32], d.Serial)
+	return id
+}
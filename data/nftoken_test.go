@@ -0,0 +1,77 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	. "gopkg.in/check.v1"
+)
+
+type NFTokenSuite struct{}
+
+var _ = Suite(&NFTokenSuite{})
+
+func (s *NFTokenSuite) TestNFTokenDetails(c *C) {
+	id, err := NewHash256("000801F40A20B3C85F482532A9578DBB3950B85CA06594D1A048C08800000007")
+	c.Assert(err, IsNil)
+	d := NewNFTokenDetails(*id)
+	c.Assert(d.Flags, Equals, NFTokenTransferable)
+	c.Assert(d.TransferFee, Equals, uint16(500))
+	c.Assert(d.Issuer.String(), Equals, "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(d.Taxon, Equals, uint32(42))
+	c.Assert(d.Serial, Equals, uint32(7))
+	c.Assert(d.NFTokenID(), Equals, *id)
+}
+
+const nftokenAccount = `"Account":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","Fee":"10","Sequence":5`
+
+var nftokenTransactions = []string{
+	`{"TransactionType":"NFTokenMint",` + nftokenAccount + `,"Flags":9,"NFTokenTaxon":42,"TransferFee":500,"URI":"697066733A2F2F"}`,
+	`{"TransactionType":"NFTokenBurn",` + nftokenAccount + `,"NFTokenID":"000801F40A20B3C85F482532A9578DBB3950B85CA06594D1A048C08800000007","Owner":"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"}`,
+	`{"TransactionType":"NFTokenCreateOffer",` + nftokenAccount + `,"Flags":1,"NFTokenID":"000801F40A20B3C85F482532A9578DBB3950B85CA06594D1A048C08800000007","Amount":"1000000","Destination":"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh","Expiration":700000000}`,
+	`{"TransactionType":"NFTokenCancelOffer",` + nftokenAccount + `,"NFTokenOffers":["35F0E8C0B4F1D62A8FB5C87B3E0A4EC4BAAB2B12E1C5AEFC4E1D44D0A53C9F15"]}`,
+	`{"TransactionType":"NFTokenAcceptOffer",` + nftokenAccount + `,"NFTokenSellOffer":"35F0E8C0B4F1D62A8FB5C87B3E0A4EC4BAAB2B12E1C5AEFC4E1D44D0A53C9F15","NFTokenBuyOffer":"6B6A1D7D0E4C2E3C1F4D1A6E2F8D3F4B0A7C2D1E5F6A7B8C9D0E1F2A3B4C5D6E","NFTokenBrokerFee":"100"}`,
+}
+
+func (s *NFTokenSuite) TestNFTokenTransactions(c *C) {
+	for _, test := range nftokenTransactions {
+		var txm TransactionWithMetaData
+		c.Assert(json.Unmarshal([]byte(test), &txm), IsNil, Commentf(test))
+		tx := txm.Transaction
+		_, raw, err := Raw(tx)
+		c.Assert(err, IsNil, Commentf(test))
+		decoded, err := ReadTransaction(bytes.NewReader(raw))
+		c.Assert(err, IsNil, Commentf(test))
+		c.Check(decoded.GetType(), Equals, tx.GetType())
+		_, again, err := Raw(decoded)
+		c.Assert(err, IsNil)
+		c.Check(again, DeepEquals, raw, Commentf(test))
+		b, err := json.Marshal(decoded)
+		c.Assert(err, IsNil)
+		c.Check(string(b), Matches, fmt.Sprintf(`\{"TransactionType":"%s".*`, tx.GetType()))
+	}
+}
+
+func (s *NFTokenSuite) TestNFTokenBurnEncoding(c *C) {
+	var txm TransactionWithMetaData
+	c.Assert(json.Unmarshal([]byte(nftokenTransactions[1]), &txm), IsNil)
+	_, raw, err := Raw(txm.Transaction)
+	c.Assert(err, IsNil)
+	c.Check(string(b2h(raw)), Equals, "12001A"+"2400000005"+
+		"5A000801F40A20B3C85F482532A9578DBB3950B85CA06594D1A048C08800000007"+
+		"68400000000000000A"+
+		"81140A20B3C85F482532A9578DBB3950B85CA06594D1"+
+		"8214B5F762798A53D543A014CAF8B297CFF8F2F937E8")
+}
+
+func (s *NFTokenSuite) TestNFTokenMint(c *C) {
+	var txm TransactionWithMetaData
+	c.Assert(json.Unmarshal([]byte(nftokenTransactions[0]), &txm), IsNil)
+	mint := txm.Transaction.(*NFTokenMint)
+	c.Check(TransactionFlag(9).Explain(mint), DeepEquals, []string{"Burnable", "Transferable"})
+	d := mint.NFTokenDetails(7)
+	c.Check(d.Flags, Equals, NFTokenBurnable|NFTokenTransferable)
+	c.Check(d.Issuer, Equals, mint.Account)
+	c.Check(d.NFTokenID().String(), Equals, "000901F40A20B3C85F482532A9578DBB3950B85CA06594D1A048C08800000007")
+}
@@ -0,0 +1,90 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type OracleSuite struct{}
+
+var _ = Suite(&OracleSuite{})
+
+const oracleEntry = `[{
+	"LedgerEntryType": "Oracle",
+	"Flags": 0,
+	"Owner": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B",
+	"Provider": "70726F7669646572",
+	"AssetClass": "63757272656E6379",
+	"LastUpdateTime": 1724871860,
+	"PriceDataSeries": [
+		{"PriceData": {"BaseAsset": "XRP", "QuoteAsset": "USD", "AssetPrice": "30E", "Scale": 3}},
+		{"PriceData": {"BaseAsset": "XRP", "QuoteAsset": "EUR"}}
+	],
+	"OwnerNode": "0",
+	"index": "2B9C5DAE9A1AE0A5A6B37D2F9D0F1F3D0E7B0D7E1A9C6B6F0A5D1E2C3B4A5968"
+}]`
+
+func (s *OracleSuite) TestOracleEntry(c *C) {
+	var entries LedgerEntrySlice
+	c.Assert(json.Unmarshal([]byte(oracleEntry), &entries), IsNil)
+	oracle, ok := entries[0].(*Oracle)
+	c.Assert(ok, Equals, true)
+	c.Assert(oracle.PriceDataSeries, HasLen, 2)
+	price, err := oracle.PriceDataSeries[0].Price()
+	c.Assert(err, IsNil)
+	c.Check(price.String(), Equals, "0.782")
+	price, err = oracle.PriceDataSeries[1].Price()
+	c.Check(price, IsNil)
+	c.Check(err, IsNil)
+
+	var buf bytes.Buffer
+	c.Assert(encode(&buf, oracle, false), IsNil)
+	node := append([]byte{0x11, 0x00, byte(ORACLE)}, buf.Bytes()...)
+	node = append(node, oracle.LedgerIndex[:]...)
+	le, err := ReadLedgerEntry(bytes.NewReader(node), *oracle.LedgerIndex)
+	c.Assert(err, IsNil)
+	decoded, ok := le.(*Oracle)
+	c.Assert(ok, Equals, true)
+	c.Check(decoded.PriceDataSeries, DeepEquals, oracle.PriceDataSeries)
+	c.Check(*decoded.LastUpdateTime, Equals, uint32(1724871860))
+	c.Check(decoded.Affects(*oracle.Owner), Equals, true)
+}
+
+func (s *OracleSuite) TestOracleTransactions(c *C) {
+	for _, test := range []string{
+		`{"TransactionType":"OracleSet","Account":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","Fee":"10","Sequence":5,"OracleDocumentID":1,"Provider":"70726F7669646572","AssetClass":"63757272656E6379","LastUpdateTime":1724871860,"PriceDataSeries":[{"PriceData":{"BaseAsset":"XRP","QuoteAsset":"USD","AssetPrice":"30E","Scale":3}}]}`,
+		`{"TransactionType":"OracleDelete","Account":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","Fee":"10","Sequence":6,"OracleDocumentID":1}`,
+	} {
+		var txm TransactionWithMetaData
+		c.Assert(json.Unmarshal([]byte(test), &txm), IsNil, Commentf(test))
+		_, raw, err := Raw(txm.Transaction)
+		c.Assert(err, IsNil, Commentf(test))
+		decoded, err := ReadTransaction(bytes.NewReader(raw))
+		c.Assert(err, IsNil, Commentf(test))
+		c.Check(decoded, DeepEquals, txm.Transaction, Commentf(test))
+	}
+
+	// The currencies are 20 bytes without a length
+	var txm TransactionWithMetaData
+	c.Assert(json.Unmarshal([]byte(`{"TransactionType":"OracleSet","Account":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","OracleDocumentID":1,"LastUpdateTime":1724871860,"PriceDataSeries":[{"PriceData":{"BaseAsset":"XRP","QuoteAsset":"USD","AssetPrice":"30E","Scale":3}}]}`), &txm), IsNil)
+	_, raw, err := Raw(txm.Transaction)
+	c.Assert(err, IsNil)
+	c.Check(string(b2h(raw)), Matches, ".*"+"F018"+"E020"+
+		"3017000000000000030E"+
+		"041003"+
+		"011A0000000000000000000000000000000000000000"+
+		"021A0000000000000000000000005553440000000000"+
+		"E1"+"F1"+".*")
+}
+
+func (s *OracleSuite) TestGetOracleIndex(c *C) {
+	owner, err := NewAccountFromAddress("rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(err, IsNil)
+	first, err := GetOracleIndex(*owner, 1)
+	c.Assert(err, IsNil)
+	second, err := GetOracleIndex(*owner, 2)
+	c.Assert(err, IsNil)
+	c.Check(*first, Not(Equals), *second)
+}
@@ -43,6 +43,21 @@ func (a Asset) String() string {
 	return fmt.Sprintf("%s/%s", a.Currency, a.Issuer)
 }
 
+// Book is an order book, in which takers pay one asset to get another
+type Book struct {
+	Pays Asset
+	Gets Asset
+}
+
+func (b Book) String() string {
+	return fmt.Sprintf("Pays: %s Gets: %s", b.Pays, b.Gets)
+}
+
+// Book returns the book the offer is in
+func (o *Offer) Book() Book {
+	return Book{Pays: *o.TakerPays.Asset(), Gets: *o.TakerGets.Asset()}
+}
+
 type OrderBookOffer struct {
 	Offer
 	OwnerFunds      Value          `json:"owner_funds"`
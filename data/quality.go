@@ -0,0 +1,106 @@
+package data
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The quality of an offer is TakerPays/TakerGets, with XRP in drops, so a
+// lower quality is a better price for the taker. It is stored in the
+// last 8 bytes of the BookDirectory of an offer, as an ExchangeRate whose
+// top byte is the exponent plus 100 and whose other bytes are the mantissa.
+
+// NewQuality returns the quality of an offer of gets for pays, as a
+// non-native Value
+func NewQuality(pays, gets Amount) (*Value, error) {
+	if gets.IsZero() {
+		return nil, fmt.Errorf("Offer gets nothing: %s", gets.Machine())
+	}
+	num, err := pays.Value.NonNative()
+	if err != nil {
+		return nil, err
+	}
+	den, err := gets.Value.NonNative()
+	if err != nil {
+		return nil, err
+	}
+	return num.Divide(*den)
+}
+
+// InvertQuality returns 1/quality, the quality as seen from the other
+// side of the book
+func InvertQuality(quality Value) (*Value, error) {
+	one, err := NewNonNativeValue(1, 0)
+	if err != nil {
+		return nil, err
+	}
+	q, err := quality.NonNative()
+	if err != nil {
+		return nil, err
+	}
+	return one.Divide(*q)
+}
+
+// NewExchangeRate returns the encoded quality of an offer of b for a,
+// which is zero if b is
+func NewExchangeRate(a, b *Amount) (ExchangeRate, error) {
+	if b.IsZero() {
+		return 0, nil
+	}
+	quality, err := NewQuality(*a, *b)
+	if err != nil {
+		return 0, err
+	}
+	return QualityExchangeRate(*quality)
+}
+
+// QualityExchangeRate encodes a positive quality
+func QualityExchangeRate(quality Value) (ExchangeRate, error) {
+	q, err := quality.NonNative()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case q.IsZero():
+		return 0, nil
+	case q.IsNegative():
+		return 0, fmt.Errorf("Negative quality: %s", q)
+	}
+	return ExchangeRate(uint64(q.offset+100)<<56 | q.num), nil
+}
+
+// Quality decodes the exchange rate
+func (e ExchangeRate) Quality() *Value {
+	if e == 0 {
+		return zeroNonNative.Clone()
+	}
+	return newValue(false, false, uint64(e)&(1<<56-1), int64(e>>56)-100)
+}
+
+// BookQuality returns the quality of the offers in a book directory
+func BookQuality(directory Hash256) *Value {
+	return ExchangeRate(binary.BigEndian.Uint64(directory[24:])).Quality()
+}
+
+// Quality returns the quality of the offer
+func (o *Offer) Quality() (*Value, error) {
+	if o.TakerPays == nil || o.TakerGets == nil {
+		return nil, fmt.Errorf("Offer has no TakerPays or TakerGets")
+	}
+	return NewQuality(*o.TakerPays, *o.TakerGets)
+}
+
+// CompareOffers returns -1 if a is a better offer for the taker than b,
+// +1 if it is worse, and 0 if they are of the same quality. The offers
+// should be in the same book.
+func CompareOffers(a, b *Offer) (int, error) {
+	qa, err := a.Quality()
+	if err != nil {
+		return 0, err
+	}
+	qb, err := b.Quality()
+	if err != nil {
+		return 0, err
+	}
+	return qa.Compare(*qb), nil
+}
@@ -0,0 +1,61 @@
+package data
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type QualitySuite struct{}
+
+var _ = Suite(&QualitySuite{})
+
+// Offers from ledger_6000000.json, with the quality in their BookDirectory
+var qualityTests = []struct {
+	pays, gets, directory string
+}{
+	{"31.5/USD/rhxbkK9jGqPVLZSWPvCEmmf15xHBfJfCEy", "3000000", "2FB4904ACFB96228FC002335B1B5A4C5584D9D727BBE82145003BAF82D03A000"},
+	{"2/USD/rhxbkK9jGqPVLZSWPvCEmmf15xHBfJfCEy", "1739130", "2FB4904ACFB96228FC002335B1B5A4C5584D9D727BBE82144F0415EB4EA0C727"},
+	{"1320/JPY/rhxbkK9jGqPVLZSWPvCEmmf15xHBfJfCEy", "110/USD/rwpRq4gQrb58N7PRJwYEQaoSui6Xd3FC7j", "62AE37A44FE44BDCFC2BA5DD14D74BEC0AC346DA2DC1F04756044364C5BB0000"},
+	{"7.5/BTC/r4DGz8SxHXLaqsA9M2oocXsrty6BMSQvw3", "100/USD/r9aRw8p1jHtR9XhDAE22TjtM7PdupNXhkx", "F774E0321809251174AC85531606FB46B75EEF9F842F9697531AA535D3D0C000"},
+}
+
+func (s *QualitySuite) TestQuality(c *C) {
+	for _, test := range qualityTests {
+		directory, err := NewHash256(test.directory)
+		c.Assert(err, IsNil)
+		offer := &Offer{TakerPays: amountCheck(test.pays), TakerGets: amountCheck(test.gets), BookDirectory: directory}
+		quality, err := offer.Quality()
+		c.Assert(err, IsNil)
+		c.Check(quality.IsNative(), Equals, false)
+		rate, err := NewExchangeRate(offer.TakerPays, offer.TakerGets)
+		c.Assert(err, IsNil)
+		c.Check(rate.Bytes(), DeepEquals, directory[24:], Commentf(test.directory))
+		c.Check(BookQuality(*directory).Equals(*quality), Equals, true)
+	}
+	rate, err := QualityExchangeRate(*valueCheck("0"))
+	c.Assert(err, IsNil)
+	c.Check(rate, Equals, ExchangeRate(0))
+	_, err = QualityExchangeRate(*valueCheck("-1"))
+	c.Check(err, ErrorMatches, "Negative quality: -1")
+	_, err = NewQuality(*amountCheck("1"), *amountCheck("0/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"))
+	c.Check(err, ErrorMatches, "Offer gets nothing: .*")
+}
+
+func (s *QualitySuite) TestInvertAndCompare(c *C) {
+	quality, err := NewQuality(*amountCheck("4/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"), *amountCheck("2000000"))
+	c.Assert(err, IsNil)
+	c.Check(quality.String(), Equals, "0.000002")
+	inverse, err := InvertQuality(*quality)
+	c.Assert(err, IsNil)
+	c.Check(inverse.String(), Equals, "500000")
+
+	cheap := &Offer{TakerPays: amountCheck("1/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"), TakerGets: amountCheck("2000000")}
+	dear := &Offer{TakerPays: amountCheck("2/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"), TakerGets: amountCheck("2000000")}
+	cmp, err := CompareOffers(cheap, dear)
+	c.Assert(err, IsNil)
+	c.Check(cmp, Equals, -1)
+	cmp, err = CompareOffers(dear, dear)
+	c.Assert(err, IsNil)
+	c.Check(cmp, Equals, 0)
+	_, err = CompareOffers(cheap, &Offer{})
+	c.Check(err, ErrorMatches, "Offer has no TakerPays or TakerGets")
+}
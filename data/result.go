@@ -1,7 +1,13 @@
 package data
 
+// TransactionResult is the engine result of applying a transaction, as
+// submit returns it and metadata records it
 type TransactionResult int16
 
+// EngineResult is what rippled calls a TransactionResult when it is
+// returned by submit
+type EngineResult = TransactionResult
+
 const (
 	// 0: S Success (success)
 	// Causes:
@@ -9,10 +15,10 @@ const (
 	// Implications:
 	// - Applied
 	// - Forwarded
-	tesSUCCESS TransactionResult = 0
+	TesSUCCESS TransactionResult = 0
 )
 const (
-	// 100 .. 159 C Claim fee only (ripple transaction with no good paths, pay to non-existent account, no path)
+	// 100 .. 255 C Claim fee only (ripple transaction with no good paths, pay to non-existent account, no path)
 	// Causes:
 	// - Success, but does not achieve optimal result.
 	// - Invalid transaction or no effect, but claim fee to use the sequence number.
@@ -22,46 +28,91 @@ const (
 	// Only allowed as a return code of appliedTransaction when !tapRetry. Otherwise, treated as terRETRY.
 	//
 	// DO NOT CHANGE THESE NUMBERS: They appear in ledger meta data.
-	tecCLAIM TransactionResult = iota + 100
-	tecPATH_PARTIAL
-	tecUNFUNDED_ADD
-	tecUNFUNDED_OFFER
-	tecUNFUNDED_PAYMENT
-	tecFAILED_PROCESSING
+	TecCLAIM TransactionResult = iota + 100
+	TecPATH_PARTIAL
+	TecUNFUNDED_ADD
+	TecUNFUNDED_OFFER
+	TecUNFUNDED_PAYMENT
+	TecFAILED_PROCESSING
 )
 const (
-	tecDIR_FULL TransactionResult = iota + 121
-	tecINSUF_RESERVE_LINE
-	tecINSUF_RESERVE_OFFER
-	tecNO_DST
-	tecNO_DST_INSUF_XRP
-	tecNO_LINE_INSUF_RESERVE
-	tecNO_LINE_REDUNDANT
-	tecPATH_DRY
-	tecUNFUNDED
-	tecNO_ALTERNATIVE_KEY
-	tecNO_REGULAR_KEY
-	tecOWNERS
-	tecNO_ISSUER
-	tecNO_AUTH
-	tecNO_LINE
-	tecINSUFF_FEE
-	tecFROZEN
-	tecNO_TARGET
-	tecNO_PERMISSION
-	tecNO_ENTRY
-	tecINSUFFICIENT_RESERVE
-	tecNEED_MASTER_KEY
-	tecDST_TAG_NEEDED
-	tecINTERNAL
-	tecOVERSIZE
-	tecCRYPTOCONDITION_ERROR
-	tecINVARIANT_FAILED
-	tecEXPIRED
-	tecDUPLICATE
-	tecKILLED
-	tecHAS_OBLIGATIONS
-	tecTOO_SOON
+	TecDIR_FULL TransactionResult = iota + 121
+	TecINSUF_RESERVE_LINE
+	TecINSUF_RESERVE_OFFER
+	TecNO_DST
+	TecNO_DST_INSUF_XRP
+	TecNO_LINE_INSUF_RESERVE
+	TecNO_LINE_REDUNDANT
+	TecPATH_DRY
+	TecUNFUNDED
+	TecNO_ALTERNATIVE_KEY
+	TecNO_REGULAR_KEY
+	TecOWNERS
+	TecNO_ISSUER
+	TecNO_AUTH
+	TecNO_LINE
+	TecINSUFF_FEE
+	TecFROZEN
+	TecNO_TARGET
+	TecNO_PERMISSION
+	TecNO_ENTRY
+	TecINSUFFICIENT_RESERVE
+	TecNEED_MASTER_KEY
+	TecDST_TAG_NEEDED
+	TecINTERNAL
+	TecOVERSIZE
+	TecCRYPTOCONDITION_ERROR
+	TecINVARIANT_FAILED
+	TecEXPIRED
+	TecDUPLICATE
+	TecKILLED
+	TecHAS_OBLIGATIONS
+	TecTOO_SOON
+	TecHOOK_REJECTED
+	TecMAX_SEQUENCE_REACHED
+	TecNO_SUITABLE_NFTOKEN_PAGE
+	TecNFTOKEN_BUY_SELL_MISMATCH
+	TecNFTOKEN_OFFER_TYPE_MISMATCH
+	TecCANT_ACCEPT_OWN_NFTOKEN_OFFER
+	TecINSUFFICIENT_FUNDS
+	TecOBJECT_NOT_FOUND
+	TecINSUFFICIENT_PAYMENT
+	TecUNFUNDED_AMM
+	TecAMM_BALANCE
+	TecAMM_FAILED
+	TecAMM_INVALID_TOKENS
+	TecAMM_EMPTY
+	TecAMM_NOT_EMPTY
+	TecAMM_ACCOUNT
+	TecINCOMPLETE
+	TecXCHAIN_BAD_TRANSFER_ISSUE
+	TecXCHAIN_NO_CLAIM_ID
+	TecXCHAIN_BAD_CLAIM_ID
+	TecXCHAIN_CLAIM_NO_QUORUM
+	TecXCHAIN_PROOF_UNKNOWN_KEY
+	TecXCHAIN_CREATE_ACCOUNT_NONXRP_ISSUE
+	TecXCHAIN_WRONG_CHAIN
+	TecXCHAIN_REWARD_MISMATCH
+	TecXCHAIN_NO_SIGNERS_LIST
+	TecXCHAIN_SENDING_ACCOUNT_MISMATCH
+	TecXCHAIN_INSUFF_CREATE_AMOUNT
+	TecXCHAIN_ACCOUNT_CREATE_PAST
+	TecXCHAIN_ACCOUNT_CREATE_TOO_MANY
+	TecXCHAIN_PAYMENT_FAILED
+	TecXCHAIN_SELF_COMMIT
+	TecXCHAIN_BAD_PUBLIC_KEY_ACCOUNT_PAIR
+	TecXCHAIN_CREATE_ACCOUNT_DISABLED
+	TecEMPTY_DID
+	TecINVALID_UPDATE_TIME
+	TecTOKEN_PAIR_NOT_FOUND
+	TecARRAY_EMPTY
+	TecARRAY_TOO_LARGE
+	TecLOCKED
+	TecBAD_CREDENTIALS
+	TecWRONG_ASSET
+	TecLIMIT_EXCEEDED
+	TecPSEUDO_ACCOUNT
+	TecPRECISION_LOSS
 )
 
 const (
@@ -71,19 +122,23 @@ const (
 	// Implications:
 	// - Not forwarded
 	// - No fee check
-	telLOCAL_ERROR TransactionResult = iota - 399
-	telBAD_DOMAIN
-	telBAD_PATH_COUNT
-	telBAD_PUBLIC_KEY
-	telFAILED_PROCESSING
-	telINSUF_FEE_P
-	telNO_DST_PARTIAL
-	telCAN_NOT_QUEUE
-	telCAN_NOT_QUEUE_BALANCE
-	telCAN_NOT_QUEUE_BLOCKS
-	telCAN_NOT_QUEUE_BLOCKED
-	telCAN_NOT_QUEUE_FEE
-	telCAN_NOT_QUEUE_FULL
+	TelLOCAL_ERROR TransactionResult = iota - 399
+	TelBAD_DOMAIN
+	TelBAD_PATH_COUNT
+	TelBAD_PUBLIC_KEY
+	TelFAILED_PROCESSING
+	TelINSUF_FEE_P
+	TelNO_DST_PARTIAL
+	TelCAN_NOT_QUEUE
+	TelCAN_NOT_QUEUE_BALANCE
+	TelCAN_NOT_QUEUE_BLOCKS
+	TelCAN_NOT_QUEUE_BLOCKED
+	TelCAN_NOT_QUEUE_FEE
+	TelCAN_NOT_QUEUE_FULL
+	TelWRONG_NETWORK
+	TelREQUIRES_NETWORK_ID
+	TelNETWORK_ID_MAKES_TX_NON_CANONICAL
+	TelENV_RPC_FAILED
 )
 const (
 	// -299 .. -200: M Malformed (bad signature)
@@ -94,40 +149,56 @@ const (
 	// - Not forwarded
 	// - Reject
 	// - Can not succeed in any imagined ledger.
-	temMALFORMED TransactionResult = iota - 299
-	temBAD_AMOUNT
-	temBAD_CURRENCY
-	temBAD_EXPIRATION
-	temBAD_FEE
-	temBAD_ISSUER
-	temBAD_LIMIT
-	temBAD_OFFER
-	temBAD_PATH
-	temBAD_PATH_LOOP
-	temBAD_SEND_XRP_LIMIT
-	temBAD_SEND_XRP_MAX
-	temBAD_SEND_XRP_NO_DIRECT
-	temBAD_SEND_XRP_PARTIAL
-	temBAD_SEND_XRP_PATHS
-	temBAD_SEQUENCE
-	temBAD_SIGNATURE
-	temBAD_SRC_ACCOUNT
-	temBAD_TRANSFER_RATE
-	temDST_IS_SRC
-	temDST_NEEDED
-	temINVALID
-	temINVALID_FLAG
-	temREDUNDANT
-	temRIPPLE_EMPTY
-	temDISABLED
-	temBAD_SIGNER
-	temBAD_QUORUM
-	temBAD_WEIGHT
-	temBAD_TICK_SIZE
-	temINVALID_ACCOUNT_ID
-	temCANNOT_PREAUTH_SELF
-	temUNCERTAIN
-	temUNKNOWN
+	TemMALFORMED TransactionResult = iota - 299
+	TemBAD_AMOUNT
+	TemBAD_CURRENCY
+	TemBAD_EXPIRATION
+	TemBAD_FEE
+	TemBAD_ISSUER
+	TemBAD_LIMIT
+	TemBAD_OFFER
+	TemBAD_PATH
+	TemBAD_PATH_LOOP
+	TemBAD_REGKEY
+	TemBAD_SEND_XRP_LIMIT
+	TemBAD_SEND_XRP_MAX
+	TemBAD_SEND_XRP_NO_DIRECT
+	TemBAD_SEND_XRP_PARTIAL
+	TemBAD_SEND_XRP_PATHS
+	TemBAD_SEQUENCE
+	TemBAD_SIGNATURE
+	TemBAD_SRC_ACCOUNT
+	TemBAD_TRANSFER_RATE
+	TemDST_IS_SRC
+	TemDST_NEEDED
+	TemINVALID
+	TemINVALID_FLAG
+	TemREDUNDANT
+	TemRIPPLE_EMPTY
+	TemDISABLED
+	TemBAD_SIGNER
+	TemBAD_QUORUM
+	TemBAD_WEIGHT
+	TemBAD_TICK_SIZE
+	TemINVALID_ACCOUNT_ID
+	TemCANNOT_PREAUTH_SELF
+	TemINVALID_COUNT
+	TemUNCERTAIN
+	TemUNKNOWN
+	TemSEQ_AND_TICKET
+	TemBAD_NFTOKEN_TRANSFER_FEE
+	TemBAD_AMM_TOKENS
+	TemXCHAIN_EQUAL_DOOR_ACCOUNTS
+	TemXCHAIN_BAD_PROOF
+	TemXCHAIN_BRIDGE_BAD_ISSUES
+	TemXCHAIN_BRIDGE_NONDOOR_OWNER
+	TemXCHAIN_BRIDGE_BAD_MIN_ACCOUNT_CREATE_AMOUNT
+	TemXCHAIN_BRIDGE_BAD_REWARD_AMOUNT
+	TemEMPTY_DID
+	TemARRAY_EMPTY
+	TemARRAY_TOO_LARGE
+	TemBAD_TRANSFER_FEE
+	TemINVALID_INNER_BATCH
 )
 const (
 	// -199 .. -100: F Failure (sequence number previously used)
@@ -139,30 +210,28 @@ const (
 	// - Not applied
 	// - Not forwarded
 	// - Could succeed in an imagined ledger.
-	tefFAILURE TransactionResult = iota - 199
-	tefALREADY
-	tefBAD_ADD_AUTH
-	tefBAD_AUTH
-	tefBAD_CLAIM_ID
-	tefBAD_GEN_AUTH
-	tefBAD_LEDGER
-	tefCLAIMED
-	tefCREATED
-	tefDST_TAG_NEEDED
-	tefEXCEPTION
-	tefGEN_IN_USE
-	tefINTERNAL
-	tefNO_AUTH_REQUIRED // Can't set auth if auth is not required.
-	tefPAST_SEQ
-	tefWRONG_PRIOR
-	tefMASTER_DISABLED
-	tefMAX_LEDGER
-	tefBAD_SIGNATURE
-	tefBAD_QUORUM
-	tefNOT_MULTI_SIGNING
-	tefBAD_AUTH_MASTER
-	tefINVARIANT_FAILED
-	tefTOO_BIG
+	TefFAILURE TransactionResult = iota - 199
+	TefALREADY
+	TefBAD_ADD_AUTH
+	TefBAD_AUTH
+	TefBAD_LEDGER
+	TefCREATED
+	TefEXCEPTION
+	TefINTERNAL
+	TefNO_AUTH_REQUIRED // Can't set auth if auth is not required.
+	TefPAST_SEQ
+	TefWRONG_PRIOR
+	TefMASTER_DISABLED
+	TefMAX_LEDGER
+	TefBAD_SIGNATURE
+	TefBAD_QUORUM
+	TefNOT_MULTI_SIGNING
+	TefBAD_AUTH_MASTER
+	TefINVARIANT_FAILED
+	TefTOO_BIG
+	TefNO_TICKET
+	TefNFTOKEN_IS_NOT_TRANSFERABLE
+	TefINVALID_LEDGER_FIX_TYPE
 )
 const (
 	// -99 .. -1: R Retry (sequence too high, no funds for txn fee, originating account non-existent)
@@ -174,143 +243,220 @@ const (
 	// - Might succeed later
 	// - Hold
 	// - Makes hole in sequence which jams transactions.
-	terRETRY       TransactionResult = iota - 99
-	terFUNDS_SPENT                   // This is a free transaction, therefore don't burden network.
-	terINSUF_FEE_B                   // Can't pay fee TransactionError     = -99 therefore don't burden network.
-	terNO_ACCOUNT                    // Can't pay fee, therefore don't burden network.
-	terNO_AUTH                       // Not authorized to hold IOUs.
-	terNO_LINE                       // Internal flag.
-	terOWNERS                        // Can't succeed with non-zero owner count.
-	terPRE_SEQ                       // Can't pay fee, no point in forwarding, therefore don't burden network.
-	terLAST                          // Process after all other transactions
-	terNO_RIPPLE                     // Rippling not allowed
-	terQUEUED                        // Transaction is being held in TxQ until fee drops
+	TerRETRY                  TransactionResult = iota - 99
+	TerFUNDS_SPENT                              // This is a free transaction, therefore don't burden network.
+	TerINSUF_FEE_B                              // Can't pay fee TransactionError     = -99 therefore don't burden network.
+	TerNO_ACCOUNT                               // Can't pay fee, therefore don't burden network.
+	TerNO_AUTH                                  // Not authorized to hold IOUs.
+	TerNO_LINE                                  // Internal flag.
+	TerOWNERS                                   // Can't succeed with non-zero owner count.
+	TerPRE_SEQ                                  // Can't pay fee, no point in forwarding, therefore don't burden network.
+	TerLAST                                     // Process after all other transactions
+	TerNO_RIPPLE                                // Rippling not allowed
+	TerQUEUED                                   // Transaction is being held in TxQ until fee drops
+	TerPRE_TICKET                               // Ticket is not yet in ledger
+	TerNO_AMM                                   // AMM doesn't exist for the asset pair
+	TerADDRESS_COLLISION                        // Pseudo account would take the address of an existing account
+	TerNO_DELEGATE_PERMISSION                   // Delegate has no permission for the transaction
 )
 
 var resultNames = map[TransactionResult]struct {
 	Token string
 	Human string
 }{
-	tesSUCCESS:               {"tesSUCCESS", "The transaction was applied."},
-	tecCLAIM:                 {"tecCLAIM", "Fee claimed. Sequence used. No action."},
-	tecDIR_FULL:              {"tecDIR_FULL", "Can not add entry to full directory."},
-	tecFAILED_PROCESSING:     {"tecFAILED_PROCESSING", "Failed to correctly process transaction."},
-	tecINSUF_RESERVE_LINE:    {"tecINSUF_RESERVE_LINE", "Insufficient reserve to add trust line."},
-	tecINSUF_RESERVE_OFFER:   {"tecINSUF_RESERVE_OFFER", "Insufficient reserve to create offer."},
-	tecNO_DST:                {"tecNO_DST", "Destination does not exist. Send XRP to create it."},
-	tecNO_DST_INSUF_XRP:      {"tecNO_DST_INSUF_XRP", "Destination does not exist. Too little XRP sent to create it."},
-	tecNO_LINE_INSUF_RESERVE: {"tecNO_LINE_INSUF_RESERVE", "No such line. Too little reserve to create it."},
-	tecNO_LINE_REDUNDANT:     {"tecNO_LINE_REDUNDANT", "Can't set non-existant line to default."},
-	tecPATH_DRY:              {"tecPATH_DRY", "Path could not send partial amount."},
-	tecPATH_PARTIAL:          {"tecPATH_PARTIAL", "Path could not send full amount."},
-	tecNO_ALTERNATIVE_KEY:    {"tecNO_ALTERNATIVE_KEY", "The operation would remove the ability to sign transactions with the account."},
-	tecNO_REGULAR_KEY:        {"tecNO_REGULAR_KEY", "Regular key is not set."},
-	tecUNFUNDED:              {"tecUNFUNDED", "One of _ADD, _OFFER, or _SEND. Deprecated."},
-	tecUNFUNDED_ADD:          {"tecUNFUNDED_ADD", "Insufficient XRP balance for WalletAdd."},
-	tecUNFUNDED_OFFER:        {"tecUNFUNDED_OFFER", "Insufficient balance to fund created offer."},
-	tecUNFUNDED_PAYMENT:      {"tecUNFUNDED_PAYMENT", "Insufficient XRP balance to send."},
-	tecOWNERS:                {"tecOWNERS", "Non-zero owner count."},
-	tecNO_ISSUER:             {"tecNO_ISSUER", "Issuer account does not exist."},
-	tecNO_AUTH:               {"tecNO_AUTH", "Not authorized to hold asset."},
-	tecNO_LINE:               {"tecNO_LINE", "No such line."},
-	tecINSUFF_FEE:            {"tecINSUFF_FEE", "Insufficient balance to pay fee."},
-	tecFROZEN:                {"tecFROZEN", "Asset is frozen."},
-	tecNO_TARGET:             {"tecNO_TARGET", "Target account does not exist."},
-	tecNO_PERMISSION:         {"tecNO_PERMISSION", "No permission to perform requested operation."},
-	tecNO_ENTRY:              {"tecNO_ENTRY", "No matching entry found."},
-	tecINSUFFICIENT_RESERVE:  {"tecINSUFFICIENT_RESERVE", "Insufficient reserve to complete requested operation."},
-	tecNEED_MASTER_KEY:       {"tecNEED_MASTER_KEY", "The operation requires the use of the Master Key."},
-	tecDST_TAG_NEEDED:        {"tecDST_TAG_NEEDED", "A destination tag is required."},
-	tecINTERNAL:              {"tecINTERNAL", "An internal error has occurred during processing."},
-	tecCRYPTOCONDITION_ERROR: {"tecCRYPTOCONDITION_ERROR", "Malformed, invalid, or mismatched conditional or fulfillment."},
-	tecINVARIANT_FAILED:      {"tecINVARIANT_FAILED", "One or more invariants for the transaction were not satisfied."},
-	tecOVERSIZE:              {"tecOVERSIZE", "Object exceeded serialization limits"},
-	tecEXPIRED:               {"tecEXPIRED", "Expiration time is passed."},
-	tecDUPLICATE:             {"tecDUPLICATE", "Ledger object already exists."},
-	tecKILLED:                {"tecKILLED", "FillOrKill offer killed."},
+	TesSUCCESS: {"tesSUCCESS", "The transaction was applied."},
 
-	tefFAILURE:          {"tefFAILURE", "Failed to apply."},
-	tefALREADY:          {"tefALREADY", "The exact transaction was already in this ledger."},
-	tefBAD_ADD_AUTH:     {"tefBAD_ADD_AUTH", "Not authorized to add account."},
-	tefBAD_AUTH:         {"tefBAD_AUTH", "Transaction's public key is not authorized."},
-	tefBAD_CLAIM_ID:     {"tefBAD_CLAIM_ID", "Malformed: Bad claim id."},
-	tefBAD_GEN_AUTH:     {"tefBAD_GEN_AUTH", "Not authorized to claim generator."},
-	tefBAD_LEDGER:       {"tefBAD_LEDGER", "Ledger in unexpected state."},
-	tefCLAIMED:          {"tefCLAIMED", "Can not claim a previously claimed account."},
-	tefCREATED:          {"tefCREATED", "Can't add an already created account."},
-	tefDST_TAG_NEEDED:   {"tefDST_TAG_NEEDED", "Destination tag required."},
-	tefEXCEPTION:        {"tefEXCEPTION", "Unexpected program state."},
-	tefGEN_IN_USE:       {"tefGEN_IN_USE", "Generator already in use."},
-	tefINTERNAL:         {"tefINTERNAL", "Internal error."},
-	tefNO_AUTH_REQUIRED: {"tefNO_AUTH_REQUIRED", "Auth is not required."},
-	tefPAST_SEQ:         {"tefPAST_SEQ", "This sequence number has already past."},
-	tefWRONG_PRIOR:      {"tefWRONG_PRIOR", "This previous transaction does not match."},
-	tefMASTER_DISABLED:  {"tefMASTER_DISABLED", "Master key is disabled."},
-	tefMAX_LEDGER:       {"tefMAX_LEDGER", "Ledger sequence too high."},
-	tefBAD_AUTH_MASTER:  {"tefBAD_AUTH_MASTER", "Auth for unclaimed account needs correct master key."},
-	tefINVARIANT_FAILED: {"tefINVARIANT_FAILED", "Fee claim violated invariants for the transaction."},
+	TecCLAIM:                              {"tecCLAIM", "Fee claimed. Sequence used. No action."},
+	TecPATH_PARTIAL:                       {"tecPATH_PARTIAL", "Path could not send full amount."},
+	TecUNFUNDED_ADD:                       {"tecUNFUNDED_ADD", "Insufficient XRP balance for WalletAdd."},
+	TecUNFUNDED_OFFER:                     {"tecUNFUNDED_OFFER", "Insufficient balance to fund created offer."},
+	TecUNFUNDED_PAYMENT:                   {"tecUNFUNDED_PAYMENT", "Insufficient XRP balance to send."},
+	TecFAILED_PROCESSING:                  {"tecFAILED_PROCESSING", "Failed to correctly process transaction."},
+	TecDIR_FULL:                           {"tecDIR_FULL", "Can not add entry to full directory."},
+	TecINSUF_RESERVE_LINE:                 {"tecINSUF_RESERVE_LINE", "Insufficient reserve to add trust line."},
+	TecINSUF_RESERVE_OFFER:                {"tecINSUF_RESERVE_OFFER", "Insufficient reserve to create offer."},
+	TecNO_DST:                             {"tecNO_DST", "Destination does not exist. Send XRP to create it."},
+	TecNO_DST_INSUF_XRP:                   {"tecNO_DST_INSUF_XRP", "Destination does not exist. Too little XRP sent to create it."},
+	TecNO_LINE_INSUF_RESERVE:              {"tecNO_LINE_INSUF_RESERVE", "No such line. Too little reserve to create it."},
+	TecNO_LINE_REDUNDANT:                  {"tecNO_LINE_REDUNDANT", "Can't set non-existant line to default."},
+	TecPATH_DRY:                           {"tecPATH_DRY", "Path could not send partial amount."},
+	TecUNFUNDED:                           {"tecUNFUNDED", "One of _ADD, _OFFER, or _SEND. Deprecated."},
+	TecNO_ALTERNATIVE_KEY:                 {"tecNO_ALTERNATIVE_KEY", "The operation would remove the ability to sign transactions with the account."},
+	TecNO_REGULAR_KEY:                     {"tecNO_REGULAR_KEY", "Regular key is not set."},
+	TecOWNERS:                             {"tecOWNERS", "Non-zero owner count."},
+	TecNO_ISSUER:                          {"tecNO_ISSUER", "Issuer account does not exist."},
+	TecNO_AUTH:                            {"tecNO_AUTH", "Not authorized to hold asset."},
+	TecNO_LINE:                            {"tecNO_LINE", "No such line."},
+	TecINSUFF_FEE:                         {"tecINSUFF_FEE", "Insufficient balance to pay fee."},
+	TecFROZEN:                             {"tecFROZEN", "Asset is frozen."},
+	TecNO_TARGET:                          {"tecNO_TARGET", "Target account does not exist."},
+	TecNO_PERMISSION:                      {"tecNO_PERMISSION", "No permission to perform requested operation."},
+	TecNO_ENTRY:                           {"tecNO_ENTRY", "No matching entry found."},
+	TecINSUFFICIENT_RESERVE:               {"tecINSUFFICIENT_RESERVE", "Insufficient reserve to complete requested operation."},
+	TecNEED_MASTER_KEY:                    {"tecNEED_MASTER_KEY", "The operation requires the use of the Master Key."},
+	TecDST_TAG_NEEDED:                     {"tecDST_TAG_NEEDED", "A destination tag is required."},
+	TecINTERNAL:                           {"tecINTERNAL", "An internal error has occurred during processing."},
+	TecOVERSIZE:                           {"tecOVERSIZE", "Object exceeded serialization limits"},
+	TecCRYPTOCONDITION_ERROR:              {"tecCRYPTOCONDITION_ERROR", "Malformed, invalid, or mismatched conditional or fulfillment."},
+	TecINVARIANT_FAILED:                   {"tecINVARIANT_FAILED", "One or more invariants for the transaction were not satisfied."},
+	TecEXPIRED:                            {"tecEXPIRED", "Expiration time is passed."},
+	TecDUPLICATE:                          {"tecDUPLICATE", "Ledger object already exists."},
+	TecKILLED:                             {"tecKILLED", "FillOrKill offer killed."},
+	TecHAS_OBLIGATIONS:                    {"tecHAS_OBLIGATIONS", "Account to be deleted is connected to objects that cannot be deleted in the ledger."},
+	TecTOO_SOON:                           {"tecTOO_SOON", "Occurs if the sender's Sequence number is too high."},
+	TecHOOK_REJECTED:                      {"tecHOOK_REJECTED", "Rejected by hook on sending or receiving account."},
+	TecMAX_SEQUENCE_REACHED:               {"tecMAX_SEQUENCE_REACHED", "The maximum sequence number was reached."},
+	TecNO_SUITABLE_NFTOKEN_PAGE:           {"tecNO_SUITABLE_NFTOKEN_PAGE", "A suitable NFToken page could not be located."},
+	TecNFTOKEN_BUY_SELL_MISMATCH:          {"tecNFTOKEN_BUY_SELL_MISMATCH", "The purchase and sale offers are not compatible."},
+	TecNFTOKEN_OFFER_TYPE_MISMATCH:        {"tecNFTOKEN_OFFER_TYPE_MISMATCH", "The type of offer is not correct."},
+	TecCANT_ACCEPT_OWN_NFTOKEN_OFFER:      {"tecCANT_ACCEPT_OWN_NFTOKEN_OFFER", "An NFToken offer cannot be accepted by its owner."},
+	TecINSUFFICIENT_FUNDS:                 {"tecINSUFFICIENT_FUNDS", "Not enough funds available to complete requested transaction."},
+	TecOBJECT_NOT_FOUND:                   {"tecOBJECT_NOT_FOUND", "A requested object could not be located."},
+	TecINSUFFICIENT_PAYMENT:               {"tecINSUFFICIENT_PAYMENT", "The payment is not sufficient."},
+	TecUNFUNDED_AMM:                       {"tecUNFUNDED_AMM", "Insufficient balance to fund AMM."},
+	TecAMM_BALANCE:                        {"tecAMM_BALANCE", "AMM has invalid balance."},
+	TecAMM_FAILED:                         {"tecAMM_FAILED", "AMM transaction failed."},
+	TecAMM_INVALID_TOKENS:                 {"tecAMM_INVALID_TOKENS", "AMM invalid LP tokens."},
+	TecAMM_EMPTY:                          {"tecAMM_EMPTY", "AMM is in empty state."},
+	TecAMM_NOT_EMPTY:                      {"tecAMM_NOT_EMPTY", "AMM is not in empty state."},
+	TecAMM_ACCOUNT:                        {"tecAMM_ACCOUNT", "This operation is not allowed on an AMM Account."},
+	TecINCOMPLETE:                         {"tecINCOMPLETE", "Some work was completed, but more submissions required to finish."},
+	TecXCHAIN_BAD_TRANSFER_ISSUE:          {"tecXCHAIN_BAD_TRANSFER_ISSUE", "Bad xchain transfer issue."},
+	TecXCHAIN_NO_CLAIM_ID:                 {"tecXCHAIN_NO_CLAIM_ID", "No such xchain claim id."},
+	TecXCHAIN_BAD_CLAIM_ID:                {"tecXCHAIN_BAD_CLAIM_ID", "Bad xchain claim id."},
+	TecXCHAIN_CLAIM_NO_QUORUM:             {"tecXCHAIN_CLAIM_NO_QUORUM", "Quorum was not reached on the xchain claim."},
+	TecXCHAIN_PROOF_UNKNOWN_KEY:           {"tecXCHAIN_PROOF_UNKNOWN_KEY", "Unknown key for the xchain proof."},
+	TecXCHAIN_CREATE_ACCOUNT_NONXRP_ISSUE: {"tecXCHAIN_CREATE_ACCOUNT_NONXRP_ISSUE", "Only XRP may be used for xchain create account."},
+	TecXCHAIN_WRONG_CHAIN:                 {"tecXCHAIN_WRONG_CHAIN", "XChain claim was sent to the wrong chain."},
+	TecXCHAIN_REWARD_MISMATCH:             {"tecXCHAIN_REWARD_MISMATCH", "The reward amount must match the reward specified in the xchain bridge."},
+	TecXCHAIN_NO_SIGNERS_LIST:             {"tecXCHAIN_NO_SIGNERS_LIST", "The account did not have a signers list."},
+	TecXCHAIN_SENDING_ACCOUNT_MISMATCH:    {"tecXCHAIN_SENDING_ACCOUNT_MISMATCH", "The sending account did not match the expected sending account."},
+	TecXCHAIN_INSUFF_CREATE_AMOUNT:        {"tecXCHAIN_INSUFF_CREATE_AMOUNT", "Insufficient amount to create an account."},
+	TecXCHAIN_ACCOUNT_CREATE_PAST:         {"tecXCHAIN_ACCOUNT_CREATE_PAST", "This account create has already been executed."},
+	TecXCHAIN_ACCOUNT_CREATE_TOO_MANY:     {"tecXCHAIN_ACCOUNT_CREATE_TOO_MANY", "There are too many pending account create transactions to submit a new one."},
+	TecXCHAIN_PAYMENT_FAILED:              {"tecXCHAIN_PAYMENT_FAILED", "Failed to transfer funds in a xchain transaction."},
+	TecXCHAIN_SELF_COMMIT:                 {"tecXCHAIN_SELF_COMMIT", "Account cannot commit funds to itself."},
+	TecXCHAIN_BAD_PUBLIC_KEY_ACCOUNT_PAIR: {"tecXCHAIN_BAD_PUBLIC_KEY_ACCOUNT_PAIR", "Bad public key account pair in an xchain transaction."},
+	TecXCHAIN_CREATE_ACCOUNT_DISABLED:     {"tecXCHAIN_CREATE_ACCOUNT_DISABLED", "Can not create a new account in a xchain transaction."},
+	TecEMPTY_DID:                          {"tecEMPTY_DID", "The DID object did not have a URI or DIDDocument field."},
+	TecINVALID_UPDATE_TIME:                {"tecINVALID_UPDATE_TIME", "The Oracle object has invalid LastUpdateTime field."},
+	TecTOKEN_PAIR_NOT_FOUND:               {"tecTOKEN_PAIR_NOT_FOUND", "Token pair is not found in Oracle object."},
+	TecARRAY_EMPTY:                        {"tecARRAY_EMPTY", "Array is empty."},
+	TecARRAY_TOO_LARGE:                    {"tecARRAY_TOO_LARGE", "Array is too large."},
+	TecLOCKED:                             {"tecLOCKED", "Fund is locked."},
+	TecBAD_CREDENTIALS:                    {"tecBAD_CREDENTIALS", "Bad credentials."},
+	TecWRONG_ASSET:                        {"tecWRONG_ASSET", "Wrong asset given."},
+	TecLIMIT_EXCEEDED:                     {"tecLIMIT_EXCEEDED", "Limit exceeded."},
+	TecPSEUDO_ACCOUNT:                     {"tecPSEUDO_ACCOUNT", "This operation is not allowed against a pseudo-account."},
+	TecPRECISION_LOSS:                     {"tecPRECISION_LOSS", "The amounts used by the transaction cannot interact."},
 
-	telLOCAL_ERROR:           {"telLOCAL_ERROR", "Local failure."},
-	telBAD_DOMAIN:            {"telBAD_DOMAIN", "Domain too long."},
-	telBAD_PATH_COUNT:        {"telBAD_PATH_COUNT", "Malformed: Too many paths."},
-	telBAD_PUBLIC_KEY:        {"telBAD_PUBLIC_KEY", "Public key too long."},
-	telFAILED_PROCESSING:     {"telFAILED_PROCESSING", "Failed to correctly process transaction."},
-	telINSUF_FEE_P:           {"telINSUF_FEE_P", "Fee insufficient."},
-	telNO_DST_PARTIAL:        {"telNO_DST_PARTIAL", "Partial payment to create account not allowed."},
-	telCAN_NOT_QUEUE:         {"telCAN_NOT_QUEUE", "Can not queue at this time."},
-	telCAN_NOT_QUEUE_BALANCE: {"telCAN_NOT_QUEUE_BALANCE", "Can not queue at this time: insufficient balance to pay all queued fees."},
-	telCAN_NOT_QUEUE_BLOCKS:  {"telCAN_NOT_QUEUE_BLOCKS", "Can not queue at this time: would block later queued transaction(s)."},
-	telCAN_NOT_QUEUE_BLOCKED: {"telCAN_NOT_QUEUE_BLOCKED", "Can not queue at this time: blocking transaction in queue."},
-	telCAN_NOT_QUEUE_FEE:     {"telCAN_NOT_QUEUE_FEE", "Can not queue at this time: fee insufficient to replace queued transaction."},
-	telCAN_NOT_QUEUE_FULL:    {"telCAN_NOT_QUEUE_FULL", "Can not queue at this time: queue is full."},
+	TefFAILURE:                     {"tefFAILURE", "Failed to apply."},
+	TefALREADY:                     {"tefALREADY", "The exact transaction was already in this ledger."},
+	TefBAD_ADD_AUTH:                {"tefBAD_ADD_AUTH", "Not authorized to add account."},
+	TefBAD_AUTH:                    {"tefBAD_AUTH", "Transaction's public key is not authorized."},
+	TefBAD_LEDGER:                  {"tefBAD_LEDGER", "Ledger in unexpected state."},
+	TefCREATED:                     {"tefCREATED", "Can't add an already created account."},
+	TefEXCEPTION:                   {"tefEXCEPTION", "Unexpected program state."},
+	TefINTERNAL:                    {"tefINTERNAL", "Internal error."},
+	TefNO_AUTH_REQUIRED:            {"tefNO_AUTH_REQUIRED", "Auth is not required."},
+	TefPAST_SEQ:                    {"tefPAST_SEQ", "This sequence number has already past."},
+	TefWRONG_PRIOR:                 {"tefWRONG_PRIOR", "This previous transaction does not match."},
+	TefMASTER_DISABLED:             {"tefMASTER_DISABLED", "Master key is disabled."},
+	TefMAX_LEDGER:                  {"tefMAX_LEDGER", "Ledger sequence too high."},
+	TefBAD_SIGNATURE:               {"tefBAD_SIGNATURE", "A signature is provided for a non-signer."},
+	TefBAD_QUORUM:                  {"tefBAD_QUORUM", "Signatures provided do not meet the quorum."},
+	TefNOT_MULTI_SIGNING:           {"tefNOT_MULTI_SIGNING", "Account has no appropriate list of multi-signers."},
+	TefBAD_AUTH_MASTER:             {"tefBAD_AUTH_MASTER", "Auth for unclaimed account needs correct master key."},
+	TefINVARIANT_FAILED:            {"tefINVARIANT_FAILED", "Fee claim violated invariants for the transaction."},
+	TefTOO_BIG:                     {"tefTOO_BIG", "Occurs if the sending account is linked to more than 1000 objects in the ledger."},
+	TefNO_TICKET:                   {"tefNO_TICKET", "Ticket is not in ledger."},
+	TefNFTOKEN_IS_NOT_TRANSFERABLE: {"tefNFTOKEN_IS_NOT_TRANSFERABLE", "The specified NFToken is not transferable."},
+	TefINVALID_LEDGER_FIX_TYPE:     {"tefINVALID_LEDGER_FIX_TYPE", "The LedgerFixType field has an invalid value."},
 
-	temMALFORMED:              {"temMALFORMED", "Malformed transaction."},
-	temBAD_AMOUNT:             {"temBAD_AMOUNT", "Can only send positive amounts."},
-	temBAD_CURRENCY:           {"temBAD_CURRENCY", "Malformed: Bad currency."},
-	temBAD_FEE:                {"temBAD_FEE", "Invalid fee, negative or not XRP."},
-	temBAD_EXPIRATION:         {"temBAD_EXPIRATION", "Malformed: Bad expiration."},
-	temBAD_ISSUER:             {"temBAD_ISSUER", "Malformed: Bad issuer."},
-	temBAD_LIMIT:              {"temBAD_LIMIT", "Limits must be non-negative."},
-	temBAD_OFFER:              {"temBAD_OFFER", "Malformed: Bad offer."},
-	temBAD_PATH:               {"temBAD_PATH", "Malformed: Bad path."},
-	temBAD_PATH_LOOP:          {"temBAD_PATH_LOOP", "Malformed: Loop in path."},
-	temBAD_SIGNATURE:          {"temBAD_SIGNATURE", "Malformed: Bad signature."},
-	temBAD_SRC_ACCOUNT:        {"temBAD_SRC_ACCOUNT", "Malformed: Bad source account."},
-	temBAD_TRANSFER_RATE:      {"temBAD_TRANSFER_RATE", "Malformed: Transfer rate must be >= 1.0"},
-	temBAD_SEQUENCE:           {"temBAD_SEQUENCE", "Malformed: Sequence is not in the past."},
-	temBAD_SEND_XRP_LIMIT:     {"temBAD_SEND_XRP_LIMIT", "Malformed: Limit quality is not allowed for XRP to XRP."},
-	temBAD_SEND_XRP_MAX:       {"temBAD_SEND_XRP_MAX", "Malformed: Send max is not allowed for XRP to XRP."},
-	temBAD_SEND_XRP_NO_DIRECT: {"temBAD_SEND_XRP_NO_DIRECT", "Malformed: No Ripple direct is not allowed for XRP to XRP."},
-	temBAD_SEND_XRP_PARTIAL:   {"temBAD_SEND_XRP_PARTIAL", "Malformed: Partial payment is not allowed for XRP to XRP."},
-	temBAD_SEND_XRP_PATHS:     {"temBAD_SEND_XRP_PATHS", "Malformed: Paths are not allowed for XRP to XRP."},
-	temDST_IS_SRC:             {"temDST_IS_SRC", "Destination may not be source."},
-	temDST_NEEDED:             {"temDST_NEEDED", "Destination not specified."},
-	temINVALID:                {"temINVALID", "The transaction is ill-formed."},
-	temINVALID_FLAG:           {"temINVALID_FLAG", "The transaction has an invalid flag."},
-	temREDUNDANT:              {"temREDUNDANT", "Sends same currency to self."},
-	temRIPPLE_EMPTY:           {"temRIPPLE_EMPTY", "PathSet with no paths."},
-	temUNCERTAIN:              {"temUNCERTAIN", "In process of determining result. Never returned."},
-	temUNKNOWN:                {"temUNKNOWN", "The transactions requires logic not implemented yet."},
-	temDISABLED:               {"temDISABLED", "The transaction requires logic that is currently disabled."},
-	temBAD_TICK_SIZE:          {"temBAD_TICK_SIZE", "Malformed: Tick size out of range."},
-	temINVALID_ACCOUNT_ID:     {"temINVALID_ACCOUNT_ID", "Malformed: A field contains an invalid account ID."},
-	temCANNOT_PREAUTH_SELF:    {"temCANNOT_PREAUTH_SELF", "Malformed: An account may not preauthorize itself."},
+	TelLOCAL_ERROR:                       {"telLOCAL_ERROR", "Local failure."},
+	TelBAD_DOMAIN:                        {"telBAD_DOMAIN", "Domain too long."},
+	TelBAD_PATH_COUNT:                    {"telBAD_PATH_COUNT", "Malformed: Too many paths."},
+	TelBAD_PUBLIC_KEY:                    {"telBAD_PUBLIC_KEY", "Public key too long."},
+	TelFAILED_PROCESSING:                 {"telFAILED_PROCESSING", "Failed to correctly process transaction."},
+	TelINSUF_FEE_P:                       {"telINSUF_FEE_P", "Fee insufficient."},
+	TelNO_DST_PARTIAL:                    {"telNO_DST_PARTIAL", "Partial payment to create account not allowed."},
+	TelCAN_NOT_QUEUE:                     {"telCAN_NOT_QUEUE", "Can not queue at this time."},
+	TelCAN_NOT_QUEUE_BALANCE:             {"telCAN_NOT_QUEUE_BALANCE", "Can not queue at this time: insufficient balance to pay all queued fees."},
+	TelCAN_NOT_QUEUE_BLOCKS:              {"telCAN_NOT_QUEUE_BLOCKS", "Can not queue at this time: would block later queued transaction(s)."},
+	TelCAN_NOT_QUEUE_BLOCKED:             {"telCAN_NOT_QUEUE_BLOCKED", "Can not queue at this time: blocking transaction in queue."},
+	TelCAN_NOT_QUEUE_FEE:                 {"telCAN_NOT_QUEUE_FEE", "Can not queue at this time: fee insufficient to replace queued transaction."},
+	TelCAN_NOT_QUEUE_FULL:                {"telCAN_NOT_QUEUE_FULL", "Can not queue at this time: queue is full."},
+	TelWRONG_NETWORK:                     {"telWRONG_NETWORK", "Transaction specifies a Network ID that differs from that of the local node."},
+	TelREQUIRES_NETWORK_ID:               {"telREQUIRES_NETWORK_ID", "Transactions submitted to this node/network must include a correct NetworkID field."},
+	TelNETWORK_ID_MAKES_TX_NON_CANONICAL: {"telNETWORK_ID_MAKES_TX_NON_CANONICAL", "Transactions submitted to this node/network must NOT include a NetworkID field."},
+	TelENV_RPC_FAILED:                    {"telENV_RPC_FAILED", "Unit test RPC failure."},
 
-	terRETRY:       {"terRETRY", "Retry transaction."},
-	terFUNDS_SPENT: {"terFUNDS_SPENT", "Can't set password, password set funds already spent."},
-	terINSUF_FEE_B: {"terINSUF_FEE_B", "Account balance can't pay fee."},
-	terLAST:        {"terLAST", "Process last."},
-	terNO_RIPPLE:   {"terNO_RIPPLE", "Path does not permit rippling."},
-	terNO_ACCOUNT:  {"terNO_ACCOUNT", "The source account does not exist."},
-	terNO_AUTH:     {"terNO_AUTH", "Not authorized to hold IOUs."},
-	terNO_LINE:     {"terNO_LINE", "No such line."},
-	terPRE_SEQ:     {"terPRE_SEQ", "Missing/inapplicable prior transaction."},
-	terOWNERS:      {"terOWNERS", "Non-zero owner count."},
-	terQUEUED:      {"terQUEUED", "Held until escalated fee drops."},
+	TemMALFORMED:                   {"temMALFORMED", "Malformed transaction."},
+	TemBAD_AMOUNT:                  {"temBAD_AMOUNT", "Can only send positive amounts."},
+	TemBAD_CURRENCY:                {"temBAD_CURRENCY", "Malformed: Bad currency."},
+	TemBAD_EXPIRATION:              {"temBAD_EXPIRATION", "Malformed: Bad expiration."},
+	TemBAD_FEE:                     {"temBAD_FEE", "Invalid fee, negative or not XRP."},
+	TemBAD_ISSUER:                  {"temBAD_ISSUER", "Malformed: Bad issuer."},
+	TemBAD_LIMIT:                   {"temBAD_LIMIT", "Limits must be non-negative."},
+	TemBAD_OFFER:                   {"temBAD_OFFER", "Malformed: Bad offer."},
+	TemBAD_PATH:                    {"temBAD_PATH", "Malformed: Bad path."},
+	TemBAD_PATH_LOOP:               {"temBAD_PATH_LOOP", "Malformed: Loop in path."},
+	TemBAD_REGKEY:                  {"temBAD_REGKEY", "Malformed: Regular key cannot be same as master key."},
+	TemBAD_SEND_XRP_LIMIT:          {"temBAD_SEND_XRP_LIMIT", "Malformed: Limit quality is not allowed for XRP to XRP."},
+	TemBAD_SEND_XRP_MAX:            {"temBAD_SEND_XRP_MAX", "Malformed: Send max is not allowed for XRP to XRP."},
+	TemBAD_SEND_XRP_NO_DIRECT:      {"temBAD_SEND_XRP_NO_DIRECT", "Malformed: No Ripple direct is not allowed for XRP to XRP."},
+	TemBAD_SEND_XRP_PARTIAL:        {"temBAD_SEND_XRP_PARTIAL", "Malformed: Partial payment is not allowed for XRP to XRP."},
+	TemBAD_SEND_XRP_PATHS:          {"temBAD_SEND_XRP_PATHS", "Malformed: Paths are not allowed for XRP to XRP."},
+	TemBAD_SEQUENCE:                {"temBAD_SEQUENCE", "Malformed: Sequence is not in the past."},
+	TemBAD_SIGNATURE:               {"temBAD_SIGNATURE", "Malformed: Bad signature."},
+	TemBAD_SRC_ACCOUNT:             {"temBAD_SRC_ACCOUNT", "Malformed: Bad source account."},
+	TemBAD_TRANSFER_RATE:           {"temBAD_TRANSFER_RATE", "Malformed: Transfer rate must be >= 1.0"},
+	TemDST_IS_SRC:                  {"temDST_IS_SRC", "Destination may not be source."},
+	TemDST_NEEDED:                  {"temDST_NEEDED", "Destination not specified."},
+	TemINVALID:                     {"temINVALID", "The transaction is ill-formed."},
+	TemINVALID_FLAG:                {"temINVALID_FLAG", "The transaction has an invalid flag."},
+	TemREDUNDANT:                   {"temREDUNDANT", "Sends same currency to self."},
+	TemRIPPLE_EMPTY:                {"temRIPPLE_EMPTY", "PathSet with no paths."},
+	TemDISABLED:                    {"temDISABLED", "The transaction requires logic that is currently disabled."},
+	TemBAD_SIGNER:                  {"temBAD_SIGNER", "Malformed: No signer may duplicate account or other signers."},
+	TemBAD_QUORUM:                  {"temBAD_QUORUM", "Malformed: Quorum is unreachable."},
+	TemBAD_WEIGHT:                  {"temBAD_WEIGHT", "Malformed: Weight must be a positive value."},
+	TemBAD_TICK_SIZE:               {"temBAD_TICK_SIZE", "Malformed: Tick size out of range."},
+	TemINVALID_ACCOUNT_ID:          {"temINVALID_ACCOUNT_ID", "Malformed: A field contains an invalid account ID."},
+	TemCANNOT_PREAUTH_SELF:         {"temCANNOT_PREAUTH_SELF", "Malformed: An account may not preauthorize itself."},
+	TemINVALID_COUNT:               {"temINVALID_COUNT", "Malformed: Count field outside valid range."},
+	TemUNCERTAIN:                   {"temUNCERTAIN", "In process of determining result. Never returned."},
+	TemUNKNOWN:                     {"temUNKNOWN", "The transactions requires logic not implemented yet."},
+	TemSEQ_AND_TICKET:              {"temSEQ_AND_TICKET", "Transaction contains a TicketSequence and a non-zero Sequence."},
+	TemBAD_NFTOKEN_TRANSFER_FEE:    {"temBAD_NFTOKEN_TRANSFER_FEE", "Malformed: The NFToken transfer fee must be between 1 and 5000, inclusive."},
+	TemBAD_AMM_TOKENS:              {"temBAD_AMM_TOKENS", "Malformed: Invalid LPTokens."},
+	TemXCHAIN_EQUAL_DOOR_ACCOUNTS:  {"temXCHAIN_EQUAL_DOOR_ACCOUNTS", "Malformed: Bridge must have unique door accounts."},
+	TemXCHAIN_BAD_PROOF:            {"temXCHAIN_BAD_PROOF", "Malformed: Bad cross-chain claim proof."},
+	TemXCHAIN_BRIDGE_BAD_ISSUES:    {"temXCHAIN_BRIDGE_BAD_ISSUES", "Malformed: Bad bridge issues."},
+	TemXCHAIN_BRIDGE_NONDOOR_OWNER: {"temXCHAIN_BRIDGE_NONDOOR_OWNER", "Malformed: Bridge owner must be one of the door accounts."},
+	TemXCHAIN_BRIDGE_BAD_MIN_ACCOUNT_CREATE_AMOUNT: {"temXCHAIN_BRIDGE_BAD_MIN_ACCOUNT_CREATE_AMOUNT", "Malformed: Bad min account create amount."},
+	TemXCHAIN_BRIDGE_BAD_REWARD_AMOUNT:             {"temXCHAIN_BRIDGE_BAD_REWARD_AMOUNT", "Malformed: Bad reward amount."},
+	TemEMPTY_DID:                                   {"temEMPTY_DID", "Malformed: No DID data provided."},
+	TemARRAY_EMPTY:                                 {"temARRAY_EMPTY", "Malformed: Array is empty."},
+	TemARRAY_TOO_LARGE:                             {"temARRAY_TOO_LARGE", "Malformed: Array is too large."},
+	TemBAD_TRANSFER_FEE:                            {"temBAD_TRANSFER_FEE", "Malformed: Transfer fee is outside valid range."},
+	TemINVALID_INNER_BATCH:                         {"temINVALID_INNER_BATCH", "Malformed: Invalid inner batch transaction."},
 
-	tecHAS_OBLIGATIONS: {"tecHAS_OBLIGATIONS", "Account to be deleted is connected to objects that cannot be deleted in the ledger."},
-	tecTOO_SOON:        {"tecTOO_SOON", "Occurs if the sender's Sequence number is too high."},
-	tefTOO_BIG:         {"tefTOO_BIG", "Occurs if the sending account is linked to more than 1000 objects in the ledger."},
+	TerRETRY:                  {"terRETRY", "Retry transaction."},
+	TerFUNDS_SPENT:            {"terFUNDS_SPENT", "Can't set password, password set funds already spent."},
+	TerINSUF_FEE_B:            {"terINSUF_FEE_B", "Account balance can't pay fee."},
+	TerNO_ACCOUNT:             {"terNO_ACCOUNT", "The source account does not exist."},
+	TerNO_AUTH:                {"terNO_AUTH", "Not authorized to hold IOUs."},
+	TerNO_LINE:                {"terNO_LINE", "No such line."},
+	TerOWNERS:                 {"terOWNERS", "Non-zero owner count."},
+	TerPRE_SEQ:                {"terPRE_SEQ", "Missing/inapplicable prior transaction."},
+	TerLAST:                   {"terLAST", "Process last."},
+	TerNO_RIPPLE:              {"terNO_RIPPLE", "Path does not permit rippling."},
+	TerQUEUED:                 {"terQUEUED", "Held until escalated fee drops."},
+	TerPRE_TICKET:             {"terPRE_TICKET", "Ticket is not yet in ledger."},
+	TerNO_AMM:                 {"terNO_AMM", "AMM doesn't exist for the asset pair."},
+	TerADDRESS_COLLISION:      {"terADDRESS_COLLISION", "Failed to allocate an unique account address."},
+	TerNO_DELEGATE_PERMISSION: {"terNO_DELEGATE_PERMISSION", "Delegated account lacks permission to perform this transaction."},
 }
 
 var reverseResults map[string]TransactionResult
@@ -322,6 +468,48 @@ func init() {
 	}
 }
 
+// ResultCategory is the kind of a TransactionResult, from the prefix of
+// its name
+type ResultCategory int8
+
+const (
+	ResultUnknown   ResultCategory = iota
+	ResultSuccess                  // tes: applied
+	ResultClaimed                  // tec: applied, but only the fee was claimed
+	ResultRetry                    // ter: not applied, but might be later
+	ResultFailure                  // tef: not applied, and can't be as it is
+	ResultMalformed                // tem: not applied, and never can be
+	ResultLocal                    // tel: not applied by this server
+)
+
+var resultCategoryNames = [...]string{"", "tes", "tec", "ter", "tef", "tem", "tel"}
+
+func (c ResultCategory) String() string {
+	if int(c) < 0 || int(c) >= len(resultCategoryNames) {
+		return ""
+	}
+	return resultCategoryNames[c]
+}
+
+func (r TransactionResult) Category() ResultCategory {
+	switch {
+	case r == TesSUCCESS:
+		return ResultSuccess
+	case r >= 100 && r <= 255:
+		return ResultClaimed
+	case r >= -99 && r < 0:
+		return ResultRetry
+	case r >= -199 && r < -99:
+		return ResultFailure
+	case r >= -299 && r < -199:
+		return ResultMalformed
+	case r >= -399 && r < -299:
+		return ResultLocal
+	default:
+		return ResultUnknown
+	}
+}
+
 func (r TransactionResult) String() string {
 	return resultNames[r].Token
 }
@@ -331,20 +519,57 @@ func (r TransactionResult) Human() string {
 }
 
 func (r TransactionResult) Success() bool {
-	return r == tesSUCCESS
+	return r == TesSUCCESS
+}
+
+// IsSuccess is Success, by the name of the other classifications
+func (r TransactionResult) IsSuccess() bool {
+	return r.Success()
 }
 
 func (r TransactionResult) Queued() bool {
-	return r == terQUEUED
+	return r == TerQUEUED
+}
+
+// IsRetriable reports whether the transaction was neither applied nor
+// queued, but might be if it is submitted again as it is, later or to
+// another server
+func (r TransactionResult) IsRetriable() bool {
+	switch r.Category() {
+	case ResultRetry:
+		return r != TerQUEUED
+	case ResultLocal:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsFinal reports whether submitting the transaction again as it is can't
+// change the result. That of an applied transaction is only final once it
+// is in a validated ledger.
+func (r TransactionResult) IsFinal() bool {
+	switch r.Category() {
+	case ResultSuccess, ResultClaimed, ResultFailure, ResultMalformed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Claimed reports whether the transaction was applied, using up its
+// sequence number or ticket, even if it achieved nothing but the fee.
+func (r TransactionResult) Claimed() bool {
+	return r == TesSUCCESS || r >= TecCLAIM
 }
 
 func (r TransactionResult) Symbol() string {
 	switch r {
-	case tesSUCCESS, tecCLAIM:
+	case TesSUCCESS, TecCLAIM:
 		return "✓"
-	case tecPATH_PARTIAL, tecPATH_DRY:
+	case TecPATH_PARTIAL, TecPATH_DRY:
 		return "½"
-	case tecUNFUNDED, tecUNFUNDED_ADD, tecUNFUNDED_OFFER, tecUNFUNDED_PAYMENT:
+	case TecUNFUNDED, TecUNFUNDED_ADD, TecUNFUNDED_OFFER, TecUNFUNDED_PAYMENT:
 		return "$"
 	default:
 		return "✗"
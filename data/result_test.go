@@ -0,0 +1,75 @@
+package data
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type ResultSuite struct{}
+
+var _ = Suite(&ResultSuite{})
+
+func (s *ResultSuite) TestCodes(c *C) {
+	for _, test := range []struct {
+		result TransactionResult
+		code   int
+	}{
+		{TesSUCCESS, 0},
+		{TecCLAIM, 100},
+		{TecFAILED_PROCESSING, 105},
+		{TecDIR_FULL, 121},
+		{TecPATH_DRY, 128},
+		{TecHOOK_REJECTED, 153},
+		{TecPRECISION_LOSS, 197},
+		{TelLOCAL_ERROR, -399},
+		{TelENV_RPC_FAILED, -383},
+		{TemMALFORMED, -299},
+		{TemBAD_REGKEY, -289},
+		{TemCANNOT_PREAUTH_SELF, -267},
+		{TemINVALID_INNER_BATCH, -250},
+		{TefFAILURE, -199},
+		{TefPAST_SEQ, -190},
+		{TefINVALID_LEDGER_FIX_TYPE, -178},
+		{TerRETRY, -99},
+		{TerQUEUED, -89},
+		{TerNO_DELEGATE_PERMISSION, -85},
+	} {
+		c.Check(int(test.result), Equals, test.code, Commentf("%s", test.result))
+	}
+	for result, name := range resultNames {
+		c.Check(name.Token, Not(Equals), "", Commentf("%d", result))
+		c.Check(name.Human, Not(Equals), "", Commentf("%s", name.Token))
+		c.Check(name.Token[:3], Equals, result.Category().String())
+	}
+}
+
+func (s *ResultSuite) TestClassification(c *C) {
+	for _, test := range []struct {
+		token     string
+		category  ResultCategory
+		success   bool
+		retriable bool
+		final     bool
+	}{
+		{"tesSUCCESS", ResultSuccess, true, false, true},
+		{"tecPATH_DRY", ResultClaimed, false, false, true},
+		{"tecPRECISION_LOSS", ResultClaimed, false, false, true},
+		{"terPRE_SEQ", ResultRetry, false, true, false},
+		{"terQUEUED", ResultRetry, false, false, false},
+		{"tefPAST_SEQ", ResultFailure, false, false, true},
+		{"temBAD_FEE", ResultMalformed, false, false, true},
+		{"telINSUF_FEE_P", ResultLocal, false, true, false},
+	} {
+		var result EngineResult
+		c.Assert(json.Unmarshal([]byte(`"`+test.token+`"`), &result), IsNil)
+		c.Check(result.String(), Equals, test.token)
+		c.Check(result.Category(), Equals, test.category, Commentf(test.token))
+		c.Check(result.IsSuccess(), Equals, test.success, Commentf(test.token))
+		c.Check(result.IsRetriable(), Equals, test.retriable, Commentf(test.token))
+		c.Check(result.IsFinal(), Equals, test.final, Commentf(test.token))
+	}
+	c.Check(TransactionResult(-400).Category(), Equals, ResultUnknown)
+	c.Check(TransactionResult(256).IsFinal(), Equals, false)
+	c.Check(ResultUnknown.String(), Equals, "")
+}
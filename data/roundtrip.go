@@ -0,0 +1,123 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// VerifyRoundTrip serialises tx, reads it back and checks that no field
+// was lost or changed on the way, and that serialising it again gives the
+// same bytes. A field which can't be serialised, or is read back as
+// something else, would otherwise go unnoticed until the transaction
+// failed or, worse, did something other than was meant. Nil and empty
+// fields are taken to be the same, and the Hash is not compared.
+func VerifyRoundTrip(tx Transaction) error {
+	if tx.GetBase() == nil {
+		return fmt.Errorf("%s can't be serialised", tx.GetType())
+	}
+	_, raw, err := Raw(tx)
+	if err != nil {
+		return fmt.Errorf("%s: %s", tx.GetType(), err)
+	}
+	decoded, err := ReadTransaction(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("%s can't be read back: %s", tx.GetType(), err)
+	}
+	if diffs := roundTripDiff(reflect.ValueOf(tx), reflect.ValueOf(decoded), tx.GetType()); len(diffs) > 0 {
+		return fmt.Errorf("%s does not round trip: %s", tx.GetType(), strings.Join(diffs, ", "))
+	}
+	_, again, err := Raw(decoded)
+	if err != nil {
+		return fmt.Errorf("%s: %s", tx.GetType(), err)
+	}
+	if !bytes.Equal(raw, again) {
+		return fmt.Errorf("%s is not serialised canonically: %X then %X", tx.GetType(), raw, again)
+	}
+	return nil
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface:
+		// Empty slices aren't serialised, even when pointed to
+		return v.IsNil() || v.Elem().Kind() == reflect.Slice && v.Elem().Len() == 0
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
+
+// roundTripDiff returns the paths of the fields which differ between a
+// and b
+func roundTripDiff(a, b reflect.Value, path string) []string {
+	switch {
+	case isEmpty(a) && isEmpty(b):
+		return nil
+	case a.Kind() == reflect.Map && b.Kind() == reflect.Map && a.Type() == b.Type():
+		// Compared by key, so that the missing ones are named
+	case isEmpty(a) || isEmpty(b) || a.Type() != b.Type():
+		return []string{path}
+	}
+	// Zero has more than one form
+	if value, ok := a.Interface().(Value); ok {
+		other := b.Interface().(Value)
+		if value.IsNative() != other.IsNative() || value.Rat().Cmp(other.Rat()) != 0 {
+			return []string{path}
+		}
+		return nil
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return roundTripDiff(a.Elem(), b.Elem(), path)
+	case reflect.Struct:
+		var diffs []string
+		for i := 0; i < a.NumField(); i++ {
+			field := a.Type().Field(i)
+			switch {
+			case field.Name == "Hash" || field.Name == "Id":
+			case field.PkgPath != "" && !field.Anonymous:
+				if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+					return []string{path}
+				}
+			case field.Anonymous:
+				diffs = append(diffs, roundTripDiff(a.Field(i), b.Field(i), path)...)
+			default:
+				diffs = append(diffs, roundTripDiff(a.Field(i), b.Field(i), path+"."+field.Name)...)
+			}
+		}
+		return diffs
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return []string{path}
+		}
+		var diffs []string
+		for i := 0; i < a.Len(); i++ {
+			diffs = append(diffs, roundTripDiff(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return diffs
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, key := range append(a.MapKeys(), b.MapKeys()...) {
+			keys[fmt.Sprint(key.Interface())] = key
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var diffs []string
+		for _, name := range names {
+			diffs = append(diffs, roundTripDiff(a.MapIndex(keys[name]), b.MapIndex(keys[name]), path+"."+name)...)
+		}
+		return diffs
+	}
+	if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return []string{path}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package data
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type RoundTripSuite struct{}
+
+var _ = Suite(&RoundTripSuite{})
+
+func (s *RoundTripSuite) TestVerifyRoundTrip(c *C) {
+	tx := TxFactory[ACCOUNT_SET]().(*AccountSet)
+	account, err := NewAccountFromAddress("rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8")
+	c.Assert(err, IsNil)
+	tx.Account = *account
+	tx.Sequence = 3
+	fee, err := NewNativeValue(10)
+	c.Assert(err, IsNil)
+	tx.Fee = *fee
+	tx.Memos = Memos{}
+	c.Check(VerifyRoundTrip(tx), IsNil)
+
+	// A field without an encoding is lost
+	tx.Dynamic = DynamicFields{"NoSuchField": uint32(1)}
+	c.Check(VerifyRoundTrip(tx), ErrorMatches, "AccountSet does not round trip: AccountSet.Dynamic.NoSuchField")
+	tx.Dynamic = nil
+
+	// Zero needn't be in the form it is read back in
+	tx.Fee = Value{}
+	c.Check(VerifyRoundTrip(tx), IsNil)
+	tx.Fee = *fee
+
+	// A field of the wrong type is misread
+	tx.Dynamic = DynamicFields{"Sequence": uint64(1)}
+	c.Check(VerifyRoundTrip(tx), ErrorMatches, "AccountSet can't be read back: .*")
+}
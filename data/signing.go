@@ -27,5 +27,34 @@ func CheckSignature(s Signer) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	// Ed25519 signs the whole message, which begins with the prefix
+	msg = append(s.SigningPrefix().Bytes(), msg...)
 	return crypto.Verify(s.GetPublicKey().Bytes(), hash.Bytes(), msg, s.GetSignature().Bytes())
 }
+
+// MultiSign returns the signature of key, on behalf of account, for s.
+// The SigningPubKey of s must already be empty.
+func MultiSign(s Signer, key crypto.Key, sequence *uint32, account Account) (*MultiSigner, error) {
+	hash, msg, err := MultiSigningHash(s, account)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(key.Private(sequence), hash.Bytes(), append(HP_TRANSACTION_MULTISIGN.Bytes(), msg...))
+	if err != nil {
+		return nil, err
+	}
+	var signer MultiSigner
+	signer.Signer.Account = account
+	copy(signer.Signer.SigningPubKey[:], key.Public(sequence))
+	signer.Signer.TxnSignature = VariableLength(sig)
+	return &signer, nil
+}
+
+func CheckMultiSignature(s Signer, signer *MultiSigner) (bool, error) {
+	hash, msg, err := MultiSigningHash(s, signer.Signer.Account)
+	if err != nil {
+		return false, err
+	}
+	msg = append(HP_TRANSACTION_MULTISIGN.Bytes(), msg...)
+	return crypto.Verify(signer.Signer.SigningPubKey.Bytes(), hash.Bytes(), msg, signer.Signer.TxnSignature.Bytes())
+}
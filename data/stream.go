@@ -0,0 +1,131 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Big enough for most entries, so that few are read in pieces
+const streamBufferSize = 64 << 10
+
+var streamBuffers = sync.Pool{
+	New: func() interface{} { return bufio.NewReaderSize(nil, streamBufferSize) },
+}
+
+// streamReader reads from the buffer of a stream, whose length is unknown,
+// so it must be read through a LimitByteReader
+type streamReader struct {
+	*bufio.Reader
+}
+
+func (s streamReader) Len() int {
+	return s.Buffered()
+}
+
+// LedgerEntryDecoder reads ledger entries one after another from a
+// stream, such as the state of a whole ledger, holding no more of it in
+// memory than its buffer. Each entry is variable length encoded with its
+// index after it, as WriteLedgerEntry writes them. The buffer is pooled,
+// so Close the decoder once done with it.
+type LedgerEntryDecoder struct {
+	r *bufio.Reader
+}
+
+func NewLedgerEntryDecoder(r io.Reader) *LedgerEntryDecoder {
+	br := streamBuffers.Get().(*bufio.Reader)
+	br.Reset(r)
+	return &LedgerEntryDecoder{r: br}
+}
+
+// Decode returns the next entry, or io.EOF when there are no more. An
+// entry which can't be read is skipped, so Decode may be called again
+// after any other error to carry on with the next.
+func (d *LedgerEntryDecoder) Decode() (LedgerEntry, error) {
+	if d.r == nil {
+		return nil, fmt.Errorf("LedgerEntryDecoder is closed")
+	}
+	if _, err := d.r.Peek(1); err != nil {
+		return nil, err
+	}
+	length, err := readVariableLength(streamReader{d.r})
+	switch {
+	case err != nil:
+		return nil, err
+	case length < 32:
+		d.r.Discard(length)
+		return nil, fmt.Errorf("Ledger entry is too short: %d", length)
+	}
+	entry := LimitedByteReader(streamReader{d.r}, int64(length))
+	le, err := readLedgerEntry(entry, length-32, zero256)
+	if entry.N > 0 {
+		if _, discardErr := d.r.Discard(int(entry.N)); err == nil {
+			err = discardErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return le, nil
+}
+
+// Close returns the buffer to the pool
+func (d *LedgerEntryDecoder) Close() {
+	if d.r != nil {
+		d.r.Reset(nil)
+		streamBuffers.Put(d.r)
+		d.r = nil
+	}
+}
+
+// WriteLedgerEntry writes le in the form LedgerEntryDecoder reads
+func WriteLedgerEntry(w io.Writer, le LedgerEntry) error {
+	_, raw, err := Raw(le)
+	if err != nil {
+		return err
+	}
+	return writeVariableLength(w, raw)
+}
+
+// LedgerEntryHexDecoder decodes ledger entries from hex, as ledger_data
+// returns them when binary, into a buffer which it reuses for each, so
+// that only the entries themselves are allocated. It is not safe for
+// concurrent use.
+type LedgerEntryHexDecoder struct {
+	buf []byte
+	r   bytes.Reader
+}
+
+// Decode returns the entry whose node and index are in hex
+func (d *LedgerEntryHexDecoder) Decode(node, index string) (LedgerEntry, error) {
+	if len(index) != 64 {
+		return nil, fmt.Errorf("Bad ledger entry index: %s", index)
+	}
+	n := (len(node) + 1) / 2
+	if cap(d.buf) < n+32 {
+		d.buf = make([]byte, n+32)
+	}
+	buf := d.buf[:n+32]
+	if err := h2b(buf[:n], node); err != nil {
+		return nil, err
+	}
+	if err := h2b(buf[n:], index); err != nil {
+		return nil, err
+	}
+	d.r.Reset(buf)
+	return ReadLedgerEntry(&d.r, zero256)
+}
+
+var hexDecoders = sync.Pool{
+	New: func() interface{} { return new(LedgerEntryHexDecoder) },
+}
+
+// ReadLedgerEntryHex is like LedgerEntryHexDecoder.Decode, with a decoder
+// from a pool
+func ReadLedgerEntryHex(node, index string) (LedgerEntry, error) {
+	d := hexDecoders.Get().(*LedgerEntryHexDecoder)
+	defer hexDecoders.Put(d)
+	return d.Decode(node, index)
+}
@@ -0,0 +1,233 @@
+package data
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	. "gopkg.in/check.v1"
+)
+
+type StreamSuite struct{}
+
+var _ = Suite(&StreamSuite{})
+
+// streamEntries returns n entries, alternating between directories with
+// many indexes and full NFT pages, which are the biggest in a ledger
+func streamEntries(n int) []LedgerEntry {
+	les := make([]LedgerEntry, n)
+	for i := range les {
+		var hash Hash256
+		copy(hash[:], fmt.Sprintf("%032d", i))
+		if i%2 == 0 {
+			indexes := make(Vector256, 256)
+			for j := range indexes {
+				indexes[j][0], indexes[j][31] = byte(i), byte(j)
+			}
+			dir := LedgerEntryFactory[DIRECTORY]().(*Directory)
+			dir.Hash, dir.RootIndex, dir.Indexes = hash, &hash, &indexes
+			les[i] = dir
+			continue
+		}
+		page := LedgerEntryFactory[NFTOKEN_PAGE]().(*NFTokenPage)
+		page.Hash = hash
+		page.NFTokens = make([]NFToken, 32)
+		for j := range page.NFTokens {
+			uri := VariableLength(fmt.Sprintf("ipfs://token/%d/%d", i, j))
+			page.NFTokens[j].NFToken.NFTokenID[0] = byte(j)
+			page.NFTokens[j].NFToken.URI = &uri
+		}
+		les[i] = page
+	}
+	return les
+}
+
+func writeStream(les []LedgerEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, le := range les {
+		if err := WriteLedgerEntry(&buf, le); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *StreamSuite) TestDecode(c *C) {
+	les := streamEntries(6)
+	stream, err := writeStream(les)
+	c.Assert(err, IsNil)
+	for _, r := range []io.Reader{bytes.NewReader(stream), iotest.OneByteReader(bytes.NewReader(stream))} {
+		d := NewLedgerEntryDecoder(r)
+		for _, le := range les {
+			decoded, err := d.Decode()
+			c.Assert(err, IsNil)
+			c.Check(decoded.GetLedgerEntryType(), Equals, le.GetLedgerEntryType())
+			c.Check(decoded.GetHash().String(), Equals, le.GetHash().String())
+			_, want, err := Raw(le)
+			c.Assert(err, IsNil)
+			_, got, err := Raw(decoded)
+			c.Assert(err, IsNil)
+			c.Check(got, DeepEquals, want)
+		}
+		_, err = d.Decode()
+		c.Check(err, Equals, io.EOF)
+		d.Close()
+		_, err = d.Decode()
+		c.Check(err, ErrorMatches, "LedgerEntryDecoder is closed")
+	}
+}
+
+func (s *StreamSuite) TestSkipBadEntry(c *C) {
+	les := streamEntries(2)
+	var buf bytes.Buffer
+	c.Assert(WriteLedgerEntry(&buf, les[0]), IsNil)
+	bad := append([]byte{0x11, 0xFF, 0xFF}, make([]byte, 32)...)
+	c.Assert(writeVariableLength(&buf, bad), IsNil)
+	c.Assert(writeVariableLength(&buf, []byte{0x11}), IsNil)
+	c.Assert(WriteLedgerEntry(&buf, les[1]), IsNil)
+
+	d := NewLedgerEntryDecoder(&buf)
+	defer d.Close()
+	le, err := d.Decode()
+	c.Assert(err, IsNil)
+	c.Check(le.GetLedgerEntryType(), Equals, DIRECTORY)
+	_, err = d.Decode()
+	c.Check(err, ErrorMatches, "Unknown LedgerEntryType: 65535")
+	_, err = d.Decode()
+	c.Check(err, ErrorMatches, "Ledger entry is too short: 1")
+	le, err = d.Decode()
+	c.Assert(err, IsNil)
+	c.Check(le.GetLedgerEntryType(), Equals, NFTOKEN_PAGE)
+	_, err = d.Decode()
+	c.Check(err, Equals, io.EOF)
+}
+
+// hexEntries returns the entries in hex, as ledger_data returns them
+func hexEntries(les []LedgerEntry) ([][2]string, error) {
+	entries := make([][2]string, len(les))
+	for i, le := range les {
+		_, raw, err := Raw(le)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = [2]string{string(b2h(raw[:len(raw)-32])), string(b2h(raw[len(raw)-32:]))}
+	}
+	return entries, nil
+}
+
+func (s *StreamSuite) TestHexDecoder(c *C) {
+	les := streamEntries(4)
+	entries, err := hexEntries(les)
+	c.Assert(err, IsNil)
+	var d LedgerEntryHexDecoder
+	// Biggest first, so that the buffer is reused for the rest
+	for i := len(les) - 1; i >= 0; i-- {
+		le, err := d.Decode(entries[i][0], entries[i][1])
+		c.Assert(err, IsNil)
+		_, want, err := Raw(les[i])
+		c.Assert(err, IsNil)
+		_, got, err := Raw(le)
+		c.Assert(err, IsNil)
+		c.Check(got, DeepEquals, want)
+	}
+	le, err := ReadLedgerEntryHex(strings.ToLower(entries[0][0]), entries[0][1])
+	c.Assert(err, IsNil)
+	c.Check(le.GetHash().String(), Equals, les[0].GetHash().String())
+
+	_, err = d.Decode(entries[0][0][1:], entries[0][1])
+	c.Check(err, Equals, hex.ErrLength)
+	_, err = d.Decode("11ZZ", entries[0][1])
+	c.Check(err, ErrorMatches, `encoding/hex: invalid byte: U\+005A 'Z'`)
+	_, err = d.Decode(entries[0][0], entries[0][1][2:])
+	c.Check(err, ErrorMatches, "Bad ledger entry index: .*")
+}
+
+// Reading each entry whole, as from a ledger_data page
+func BenchmarkReadLedgerEntry(b *testing.B) {
+	les := streamEntries(1000)
+	blobs := make([][]byte, len(les))
+	for i, le := range les {
+		var err error
+		if _, blobs[i], err = Raw(le); err != nil {
+			b.Fatal(err)
+		}
+	}
+	stream, err := writeStream(les)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, blob := range blobs {
+			node := append([]byte(nil), blob...)
+			if _, err := ReadLedgerEntry(bytes.NewReader(node), zero256); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkLedgerEntryDecoder(b *testing.B) {
+	stream, err := writeStream(streamEntries(1000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d := NewLedgerEntryDecoder(bytes.NewReader(stream))
+		for {
+			if _, err := d.Decode(); err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatal(err)
+			}
+		}
+		d.Close()
+	}
+}
+
+// As ledger_data results were decoded, by concatenating the hex
+func BenchmarkHexDecodeString(b *testing.B) {
+	entries, err := hexEntries(streamEntries(1000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, entry := range entries {
+			node, err := hex.DecodeString(entry[0] + entry[1])
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := ReadLedgerEntry(bytes.NewReader(node), zero256); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkLedgerEntryHexDecoder(b *testing.B) {
+	entries, err := hexEntries(streamEntries(1000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var d LedgerEntryHexDecoder
+		for _, entry := range entries {
+			if _, err := d.Decode(entry[0], entry[1]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -2,18 +2,22 @@ package data
 
 type TxBase struct {
 	TransactionType    TransactionType
+	NetworkID          *uint32          `json:",omitempty"`
 	Flags              *TransactionFlag `json:",omitempty"`
 	SourceTag          *uint32          `json:",omitempty"`
 	Account            Account
 	Sequence           uint32
+	TicketSequence     *uint32 `json:",omitempty"`
 	Fee                Value
 	AccountTxnID       *Hash256        `json:",omitempty"`
 	SigningPubKey      *PublicKey      `json:",omitempty"`
 	TxnSignature       *VariableLength `json:",omitempty"`
+	Signers            MultiSigners    `json:",omitempty"`
 	Memos              Memos           `json:",omitempty"`
 	PreviousTxnID      *Hash256        `json:",omitempty"`
 	LastLedgerSequence *uint32         `json:",omitempty"`
 	Hash               Hash256         `json:"hash"`
+	Dynamic            DynamicFields   `json:"-"` // Binary only, except in a DynamicTransaction
 }
 
 type Payment struct {
@@ -36,6 +40,7 @@ type AccountSet struct {
 	Domain        *VariableLength `json:",omitempty"`
 	TransferRate  *uint32         `json:",omitempty"`
 	TickSize      *uint8          `json:",omitempty"`
+	NFTokenMinter *Account        `json:",omitempty"`
 	SetFlag       *uint32         `json:",omitempty"`
 	ClearFlag     *uint32         `json:",omitempty"`
 }
@@ -82,19 +87,22 @@ type EscrowCreate struct {
 	TxBase
 	Destination    Account
 	Amount         Amount
-	Digest         *Hash256 `json:",omitempty"`
-	CancelAfter    *uint32  `json:",omitempty"`
-	FinishAfter    *uint32  `json:",omitempty"`
-	DestinationTag *uint32  `json:",omitempty"`
+	Digest         *Hash256        `json:",omitempty"`
+	Condition      *VariableLength `json:",omitempty"`
+	CancelAfter    *uint32         `json:",omitempty"`
+	FinishAfter    *uint32         `json:",omitempty"`
+	DestinationTag *uint32         `json:",omitempty"`
 }
 
 type EscrowFinish struct {
 	TxBase
 	Owner         Account
 	OfferSequence uint32
-	Method        *uint8   `json:",omitempty"`
-	Digest        *Hash256 `json:",omitempty"`
-	Proof         *Hash256 `json:",omitempty"`
+	Method        *uint8          `json:",omitempty"`
+	Digest        *Hash256        `json:",omitempty"`
+	Proof         *Hash256        `json:",omitempty"`
+	Condition     *VariableLength `json:",omitempty"`
+	Fulfillment   *VariableLength `json:",omitempty"`
 }
 
 type EscrowCancel struct {
@@ -157,9 +165,20 @@ type CheckCancel struct {
 	CheckID Hash256
 }
 
+// DepositPreauth lets Authorize send payments to an account which
+// requires deposit authorisation, or stops Unauthorize doing so.
+// Exactly one of them is set.
+type DepositPreauth struct {
+	TxBase
+	Authorize   *Account `json:",omitempty"`
+	Unauthorize *Account `json:",omitempty"`
+}
+
+// TicketCreate sets aside TicketCount sequence numbers, following its own,
+// for transactions which set TicketSequence instead of Sequence.
 type TicketCreate struct {
-	Target     *Account `json:",omitempty"`
-	Expiration *uint32  `json:",omitempty"`
+	TxBase
+	TicketCount uint32
 }
 
 type TicketCancel struct {
@@ -178,6 +197,291 @@ type AccountDelete struct {
 	DestinationTag *uint32 `json:",omitempty"`
 }
 
+// NFTokenMint mints a token for Issuer, if given, by its authorised
+// minter, otherwise for the Account
+type NFTokenMint struct {
+	TxBase
+	NFTokenTaxon uint32
+	Issuer       *Account        `json:",omitempty"`
+	TransferFee  *uint16         `json:",omitempty"`
+	URI          *VariableLength `json:",omitempty"`
+}
+
+// NFTokenBurn burns a token of the Account, or of Owner when burnt by
+// its issuer
+type NFTokenBurn struct {
+	TxBase
+	NFTokenID Hash256
+	Owner     *Account `json:",omitempty"`
+}
+
+// NFTokenCreateOffer offers to sell a token of the Account, with the
+// TxSellNFToken flag, or to buy the token of Owner
+type NFTokenCreateOffer struct {
+	TxBase
+	NFTokenID   Hash256
+	Amount      Amount
+	Owner       *Account `json:",omitempty"`
+	Destination *Account `json:",omitempty"`
+	Expiration  *uint32  `json:",omitempty"`
+}
+
+type NFTokenCancelOffer struct {
+	TxBase
+	NFTokenOffers Vector256
+}
+
+// NFTokenAcceptOffer accepts a sell offer, a buy offer, or as a broker
+// both, keeping NFTokenBrokerFee from the difference
+type NFTokenAcceptOffer struct {
+	TxBase
+	NFTokenSellOffer *Hash256 `json:",omitempty"`
+	NFTokenBuyOffer  *Hash256 `json:",omitempty"`
+	NFTokenBrokerFee *Amount  `json:",omitempty"`
+}
+
+// Clawback takes back tokens issued by the account from a holder, which
+// the issuer must have allowed by setting TxAllowClawback before it
+// issued anything. Amount is of the issuer's currency, but its issuer is
+// the holder.
+type Clawback struct {
+	TxBase
+	Amount Amount
+}
+
+// Holder returns the account from which the tokens are taken
+func (c *Clawback) Holder() Account {
+	return c.Amount.Issuer
+}
+
+// AMMCreate creates the AMM of the assets of the amounts, which are its
+// first deposit. TradingFee is in units of 1/100000, up to 1%.
+type AMMCreate struct {
+	TxBase
+	Amount     Amount
+	Amount2    Amount
+	TradingFee uint16
+}
+
+// AMMDeposit deposits into the AMM of Asset and Asset2 in return for LP
+// tokens. Its flags say which of the optional fields are used.
+type AMMDeposit struct {
+	TxBase
+	Asset      Asset
+	Asset2     Asset
+	Amount     *Amount `json:",omitempty"`
+	Amount2    *Amount `json:",omitempty"`
+	EPrice     *Amount `json:",omitempty"`
+	LPTokenOut *Amount `json:",omitempty"`
+	TradingFee *uint16 `json:",omitempty"`
+}
+
+// AMMWithdraw returns LP tokens to the AMM of Asset and Asset2 for a share
+// of its pool. Its flags say which of the optional fields are used.
+type AMMWithdraw struct {
+	TxBase
+	Asset     Asset
+	Asset2    Asset
+	Amount    *Amount `json:",omitempty"`
+	Amount2   *Amount `json:",omitempty"`
+	EPrice    *Amount `json:",omitempty"`
+	LPTokenIn *Amount `json:",omitempty"`
+}
+
+// AMMVote votes for the trading fee of the AMM, weighted by the LP tokens
+// held
+type AMMVote struct {
+	TxBase
+	Asset      Asset
+	Asset2     Asset
+	TradingFee uint16
+}
+
+// AMMBid bids LP tokens for the auction slot of the AMM, which discounts
+// the trading fee for its holder and the AuthAccounts
+type AMMBid struct {
+	TxBase
+	Asset        Asset
+	Asset2       Asset
+	BidMin       *Amount       `json:",omitempty"`
+	BidMax       *Amount       `json:",omitempty"`
+	AuthAccounts []AuthAccount `json:",omitempty"`
+}
+
+// AMMDelete deletes an empty AMM which had too many trust lines to be
+// deleted by the withdrawal of its last LP tokens
+type AMMDelete struct {
+	TxBase
+	Asset  Asset
+	Asset2 Asset
+}
+
+// DIDSet creates or updates the DID of the account. An empty field
+// removes it from the DID, but the DID must keep at least one field.
+type DIDSet struct {
+	TxBase
+	DIDDocument *VariableLength `json:",omitempty"`
+	URI         *VariableLength `json:",omitempty"`
+	Data        *VariableLength `json:",omitempty"`
+}
+
+// DIDDelete deletes the DID of the account
+type DIDDelete struct {
+	TxBase
+}
+
+// XChainCreateBridge creates a bridge of which the account is the door on
+// this chain. SignatureReward is shared by the witnesses of each transfer.
+type XChainCreateBridge struct {
+	TxBase
+	XChainBridge           XChainBridge
+	SignatureReward        Amount
+	MinAccountCreateAmount *Amount `json:",omitempty"`
+}
+
+// XChainModifyBridge changes the rewards and minimum account creation
+// amount of a bridge, or clears the latter with TxClearAccountCreateAmount
+type XChainModifyBridge struct {
+	TxBase
+	XChainBridge           XChainBridge
+	SignatureReward        *Amount `json:",omitempty"`
+	MinAccountCreateAmount *Amount `json:",omitempty"`
+}
+
+// XChainCreateClaimID reserves a claim ID on the destination chain for a
+// transfer from OtherChainSource, which must commit with it
+type XChainCreateClaimID struct {
+	TxBase
+	XChainBridge     XChainBridge
+	SignatureReward  Amount
+	OtherChainSource Account
+}
+
+// XChainCommit locks or burns Amount on the source chain for the claim ID
+// created on the destination chain
+type XChainCommit struct {
+	TxBase
+	XChainBridge          XChainBridge
+	XChainClaimID         Uint64Hex
+	Amount                Amount
+	OtherChainDestination *Account `json:",omitempty"`
+}
+
+// XChainClaim claims a committed transfer which has a quorum of
+// attestations but no OtherChainDestination
+type XChainClaim struct {
+	TxBase
+	XChainBridge   XChainBridge
+	XChainClaimID  Uint64Hex
+	Destination    Account
+	DestinationTag *uint32 `json:",omitempty"`
+	Amount         Amount
+}
+
+// XChainAccountCreateCommit commits Amount to create and fund Destination
+// on the other chain, without a claim ID
+type XChainAccountCreateCommit struct {
+	TxBase
+	XChainBridge    XChainBridge
+	Destination     Account
+	Amount          Amount
+	SignatureReward Amount
+}
+
+// XChainAddClaimAttestation submits a witness's attestation of an
+// XChainCommit on the other chain. Anyone may submit it, but it must be
+// signed by the PublicKey of AttestationSignerAccount with Sign.
+type XChainAddClaimAttestation struct {
+	TxBase
+	XChainBridge             XChainBridge
+	AttestationSignerAccount Account
+	PublicKey                PublicKey
+	Signature                VariableLength
+	OtherChainSource         Account
+	Amount                   Amount
+	AttestationRewardAccount Account
+	WasLockingChainSend      uint8
+	XChainClaimID            Uint64Hex
+	Destination              *Account `json:",omitempty"`
+}
+
+// XChainAddAccountCreateAttestation submits a witness's attestation of an
+// XChainAccountCreateCommit on the other chain
+type XChainAddAccountCreateAttestation struct {
+	TxBase
+	XChainBridge             XChainBridge
+	AttestationSignerAccount Account
+	PublicKey                PublicKey
+	Signature                VariableLength
+	OtherChainSource         Account
+	Amount                   Amount
+	AttestationRewardAccount Account
+	WasLockingChainSend      uint8
+	XChainAccountCreateCount Uint64Hex
+	Destination              Account
+	SignatureReward          Amount
+}
+
+// OracleSet creates or updates the price oracle of the account with
+// OracleDocumentID. Provider and AssetClass are required on creation and
+// cannot then be changed. LastUpdateTime is in seconds since the Unix
+// epoch, not the Ripple one.
+type OracleSet struct {
+	TxBase
+	OracleDocumentID uint32
+	Provider         *VariableLength `json:",omitempty"`
+	URI              *VariableLength `json:",omitempty"`
+	AssetClass       *VariableLength `json:",omitempty"`
+	LastUpdateTime   uint32
+	PriceDataSeries  []PriceData `json:",omitempty"`
+}
+
+// OracleDelete deletes the price oracle of the account with
+// OracleDocumentID
+type OracleDelete struct {
+	TxBase
+	OracleDocumentID uint32
+}
+
+// CredentialCreate issues a credential to Subject, which it must accept
+type CredentialCreate struct {
+	TxBase
+	Subject        Account
+	CredentialType VariableLength
+	Expiration     *uint32         `json:",omitempty"`
+	URI            *VariableLength `json:",omitempty"`
+}
+
+// CredentialAccept accepts a credential issued to the Account by Issuer
+type CredentialAccept struct {
+	TxBase
+	Issuer         Account
+	CredentialType VariableLength
+}
+
+// CredentialDelete deletes a credential of which the Account is the
+// Subject or the Issuer, or any expired credential. The Account is
+// whichever of them is missing.
+type CredentialDelete struct {
+	TxBase
+	Subject        *Account `json:",omitempty"`
+	Issuer         *Account `json:",omitempty"`
+	CredentialType VariableLength
+}
+
+// PermissionedDomainSet creates a domain, or replaces the accepted
+// credentials of the domain DomainID
+type PermissionedDomainSet struct {
+	TxBase
+	DomainID            *Hash256 `json:",omitempty"`
+	AcceptedCredentials []AcceptedCredential
+}
+
+type PermissionedDomainDelete struct {
+	TxBase
+	DomainID Hash256
+}
+
 type UNLModify struct {
 }
 
@@ -198,7 +502,7 @@ func (t *UNLModify) Compare(other *TxBase) int {
 func (t *UNLModify) InitialiseForSigning() {}
 
 func (t *TxBase) GetBase() *TxBase                    { return t }
-func (t *TxBase) GetType() string                     { return txNames[t.TransactionType] }
+func (t *TxBase) GetType() string                     { return t.TransactionType.String() }
 func (t *TxBase) GetTransactionType() TransactionType { return t.TransactionType }
 func (t *TxBase) Prefix() HashPrefix                  { return HP_TRANSACTION_ID }
 func (t *TxBase) GetPublicKey() *PublicKey            { return t.SigningPubKey }
@@ -244,3 +548,23 @@ func (p *Payment) PathSet() PathSet {
 	}
 	return *p.Paths
 }
+
+// NFTokenDetails returns the details of the token which will be minted,
+// given the MintedNFTokens of the issuer
+func (m *NFTokenMint) NFTokenDetails(minted uint32) *NFTokenDetails {
+	d := &NFTokenDetails{
+		Issuer: m.Account,
+		Taxon:  m.NFTokenTaxon,
+		Serial: minted,
+	}
+	if m.Issuer != nil {
+		d.Issuer = *m.Issuer
+	}
+	if m.Flags != nil {
+		d.Flags = NFTokenFlag(*m.Flags & 0xFFFF)
+	}
+	if m.TransferFee != nil {
+		d.TransferFee = *m.TransferFee
+	}
+	return d
+}
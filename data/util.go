@@ -2,6 +2,7 @@ package data
 
 import (
 	"crypto/sha512"
+	"encoding/hex"
 )
 
 const hextable = "0123456789ABCDEF"
@@ -16,6 +17,39 @@ func b2h(h []byte) []byte {
 	return b
 }
 
+// The values of hex digits, with 0xFF for other bytes
+var hexValues = func() (values [256]byte) {
+	for i := range values {
+		values[i] = 0xFF
+	}
+	for i, c := range "0123456789abcdef" {
+		values[c] = byte(i)
+	}
+	for i, c := range hextable {
+		values[c] = byte(i)
+	}
+	return
+}()
+
+// h2b decodes s into dst, which must be half its length, without copying
+// s to a []byte first as hex.Decode would need
+func h2b(dst []byte, s string) error {
+	if len(s)%2 == 1 {
+		return hex.ErrLength
+	}
+	for i := range dst {
+		a, b := hexValues[s[i*2]], hexValues[s[i*2+1]]
+		switch {
+		case a == 0xFF:
+			return hex.InvalidByteError(s[i*2])
+		case b == 0xFF:
+			return hex.InvalidByteError(s[i*2+1])
+		}
+		dst[i] = a<<4 | b
+	}
+	return nil
+}
+
 func min(a, b uint32) uint32 {
 	if a < b {
 		return a
@@ -1,5 +1,8 @@
 package data
 
+// Flag of a validation which is full, rather than partial
+const FullValidation uint32 = 0x00000001
+
 type Validation struct {
 	Hash             Hash256
 	Flags            uint32
@@ -14,13 +17,23 @@ type Validation struct {
 	BaseFee          *uint64
 	ReserveBase      *uint32
 	ReserveIncrement *uint32
+	Cookie           *uint64
+	ServerVersion    *uint64
+	ConsensusHash    *Hash256
+	ValidatedHash    *Hash256
+	// The fees voted for once fees are in drops
+	BaseFeeDrops          *Amount
+	ReserveBaseDrops      *Amount
+	ReserveIncrementDrops *Amount
 }
 
 func (v Validation) GetType() string                 { return "Validation" }
-func (v Validation) GetPublicKey() *PublicKey        { return &v.SigningPubKey }
-func (v Validation) GetSignature() *VariableLength   { return &v.Signature }
+func (v *Validation) GetPublicKey() *PublicKey       { return &v.SigningPubKey }
+func (v *Validation) GetSignature() *VariableLength  { return &v.Signature }
 func (v Validation) Prefix() HashPrefix              { return HP_VALIDATION }
 func (v Validation) SigningPrefix() HashPrefix       { return HP_VALIDATION }
 func (v Validation) SuppressionId() (Hash256, error) { return NodeId(&v) }
-func (v Validation) GetHash() *Hash256               { return &v.Hash }
+func (v *Validation) GetHash() *Hash256              { return &v.Hash }
 func (v Validation) InitialiseForSigning()           {}
+
+func (v Validation) IsFull() bool { return v.Flags&FullValidation != 0 }
@@ -144,6 +144,16 @@ func NewValue(s string, native bool) (*Value, error) {
 	return &v, v.canonicalise()
 }
 
+// MustValue is like NewValue but panics if s is not a value, for
+// constants in variable initialisation
+func MustValue(s string, native bool) *Value {
+	v, err := NewValue(s, native)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
 func (v *Value) canonicalise() error {
 	if v.IsNative() {
 		if v.num == 0 {
@@ -269,6 +279,7 @@ func (a Value) Add(b Value) (*Value, error) {
 	}
 }
 
+// Subtract subtracts b from a and returns the difference as a new Value.
 func (a Value) Subtract(b Value) (*Value, error) {
 	return a.Add(*b.Negate())
 }
@@ -289,6 +300,8 @@ func normalise(a, b Value) (uint64, uint64, int64, int64) {
 	return av, bv, ao, bo
 }
 
+// Multiply returns the product of a and b, rounded as rippled does, and
+// native if a is.
 func (a Value) Multiply(b Value) (*Value, error) {
 	if a.IsZero() || b.IsZero() {
 		return a.ZeroClone(), nil
@@ -315,6 +328,8 @@ func (a Value) Multiply(b Value) (*Value, error) {
 	return v, v.canonicalise()
 }
 
+// Divide returns the quotient of num and den, rounded as rippled does,
+// and native if num is.
 func (num Value) Divide(den Value) (*Value, error) {
 	if den.IsZero() {
 		return nil, fmt.Errorf("Division by zero")
@@ -384,6 +399,22 @@ func (a Value) Compare(b Value) int {
 	return a.Rat().Cmp(b.Rat())
 }
 
+// Min returns a copy of the lesser of a and b.
+func (a Value) Min(b Value) *Value {
+	if b.Less(a) {
+		return b.Clone()
+	}
+	return a.Clone()
+}
+
+// Max returns a copy of the greater of a and b.
+func (a Value) Max(b Value) *Value {
+	if a.Less(b) {
+		return b.Clone()
+	}
+	return a.Clone()
+}
+
 // isScientific indicates when the value should be String()ed in scientific notation.
 func (v Value) isScientific() bool {
 	return v.offset != 0 && (v.offset < -25 || v.offset > -5)
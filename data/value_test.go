@@ -97,6 +97,11 @@ var valueTests = TestSlice{
 	{valueCheck("n-20000").Abs().String(), Equals, "0.02", "Abs n-20000"},
 	{valueCheck("n20000").Abs().String(), Equals, "0.02", "Abs n20000"},
 
+	{valueCheck("-1").Min(*valueCheck("0.5")).String(), Equals, "-1", "Min -1 0.5"},
+	{valueCheck("0.5").Min(*valueCheck("-1")).String(), Equals, "-1", "Min 0.5 -1"},
+	{valueCheck("-1").Max(*valueCheck("0.5")).String(), Equals, "0.5", "Max -1 0.5"},
+	{valueCheck("n20000").Max(*valueCheck("n10000")).String(), Equals, "0.02", "Max n20000 n10000"},
+
 	{valueCheck("123").Negate().String(), Equals, "-123", "Negate 123"},
 	{valueCheck("-123").Negate().String(), Equals, "123", "Negate -123"},
 	{valueCheck("0").Negate().String(), Equals, "0", "Negate 0"},
@@ -305,6 +310,12 @@ func (s *ValueSuite) TestValue(c *C) {
 	valueTests.Test(c)
 }
 
+func (s *ValueSuite) TestMustValue(c *C) {
+	c.Check(MustValue("1.5", false), DeepEquals, valueCheck("1.5"))
+	c.Check(MustValue("10", true), DeepEquals, valueCheck("n10"))
+	c.Check(func() { MustValue("1e100", false) }, PanicMatches, "Value overflow: .*")
+}
+
 func checkValBinaryMarshal(v1 *Value) *Value {
 	var b []byte
 	var err error
@@ -46,6 +46,9 @@ func (a *Amount) Unmarshal(r Reader) error {
 }
 
 func (a *Amount) Marshal(w io.Writer) error {
+	if a.Value == nil {
+		return fmt.Errorf("Amount has no value")
+	}
 	return binary.Write(w, binary.BigEndian, a.Bytes())
 }
 
@@ -57,6 +60,66 @@ func (c *Currency) Marshal(w io.Writer) error {
 	return binary.Write(w, binary.BigEndian, c.Bytes())
 }
 
+func (a *Asset) Unmarshal(r Reader) error {
+	var currency Currency
+	if err := currency.Unmarshal(r); err != nil {
+		return err
+	}
+	a.Currency, a.Issuer = currency.Machine(), ""
+	if currency.IsNative() {
+		return nil
+	}
+	var issuer Account
+	if err := unmarshalSlice(issuer[:], r, "Issuer"); err != nil {
+		return err
+	}
+	a.Issuer = issuer.String()
+	return nil
+}
+
+// The issuer is omitted for XRP
+func (a *Asset) Marshal(w io.Writer) error {
+	currency, err := NewCurrency(a.Currency)
+	if err != nil {
+		return err
+	}
+	if err := currency.Marshal(w); err != nil || currency.IsNative() {
+		return err
+	}
+	issuer, err := NewAccountFromAddress(a.Issuer)
+	if err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, issuer.Bytes())
+}
+
+func (b *XChainBridge) Unmarshal(r Reader) error {
+	if err := b.LockingChainDoor.Unmarshal(r); err != nil {
+		return err
+	}
+	if err := b.LockingChainIssue.Unmarshal(r); err != nil {
+		return err
+	}
+	if err := b.IssuingChainDoor.Unmarshal(r); err != nil {
+		return err
+	}
+	return b.IssuingChainIssue.Unmarshal(r)
+}
+
+// The doors are written with their length, as account fields are
+func (b *XChainBridge) Marshal(w io.Writer) error {
+	if err := b.LockingChainDoor.Marshal(w); err != nil {
+		return err
+	}
+	if err := b.LockingChainIssue.Marshal(w); err != nil {
+		return err
+	}
+	if err := b.IssuingChainDoor.Marshal(w); err != nil {
+		return err
+	}
+	return b.IssuingChainIssue.Marshal(w)
+}
+
 func (h *Hash128) Unmarshal(r Reader) error {
 	return unmarshalSlice(h[:], r, "Hash128")
 }
@@ -172,22 +235,25 @@ func (p *PathSet) Unmarshal(r Reader) error {
 			if entry == PATH_END {
 				return nil
 			}
+			if entry&^(PATH_ACCOUNT|PATH_CURRENCY|PATH_ISSUER) != 0 {
+				return fmt.Errorf("Bad path entry: %02X", b)
+			}
 			var pe PathElem
 			if entry&PATH_ACCOUNT > 0 {
 				pe.Account = new(Account)
-				if _, err := r.Read(pe.Account.Bytes()); err != nil {
+				if _, err := io.ReadFull(r, pe.Account.Bytes()); err != nil {
 					return err
 				}
 			}
 			if entry&PATH_CURRENCY > 0 {
 				pe.Currency = new(Currency)
-				if _, err := r.Read(pe.Currency.Bytes()); err != nil {
+				if _, err := io.ReadFull(r, pe.Currency.Bytes()); err != nil {
 					return err
 				}
 			}
 			if entry&PATH_ISSUER > 0 {
 				pe.Issuer = new(Account)
-				if _, err := r.Read(pe.Issuer.Bytes()); err != nil {
+				if _, err := io.ReadFull(r, pe.Issuer.Bytes()); err != nil {
 					return err
 				}
 			}
@@ -0,0 +1,117 @@
+package data
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+)
+
+// AcceptXAddresses makes NewAccountFromAddress, and so the parsing of
+// every Account, accept an X-address without a tag as well as a classic
+// address. An X-address with a tag is refused, as the tag would be lost.
+var AcceptXAddresses = false
+
+var (
+	xAddressMainnet = []byte{0x05, 0x44}
+	xAddressTestnet = []byte{0x04, 0x93}
+)
+
+// XAddress is an account and optional tag, encoded as XLS-5 describes.
+// Mainnet X-addresses begin with X and testnet ones with T.
+type XAddress struct {
+	Account Account
+	Tag     *uint32
+	Testnet bool
+}
+
+// NewXAddress decodes an X-address
+func NewXAddress(s string) (*XAddress, error) {
+	decoded, err := crypto.Base58Decode(s, crypto.ALPHABET)
+	if err != nil {
+		return nil, err
+	}
+	// Prefix, account, flag, 64 bit little endian tag and checksum
+	if len(decoded) != 2+20+1+8+4 {
+		return nil, fmt.Errorf("Not an X-address: %s", s)
+	}
+	var x XAddress
+	switch {
+	case bytes.HasPrefix(decoded, xAddressMainnet):
+	case bytes.HasPrefix(decoded, xAddressTestnet):
+		x.Testnet = true
+	default:
+		return nil, fmt.Errorf("Not an X-address: %s", s)
+	}
+	copy(x.Account[:], decoded[2:22])
+	tag, high := binary.LittleEndian.Uint32(decoded[23:]), binary.LittleEndian.Uint32(decoded[27:])
+	switch {
+	case high != 0:
+		return nil, fmt.Errorf("X-address tag is too large: %s", s)
+	case decoded[22] == 1:
+		x.Tag = &tag
+	case decoded[22] != 0 || tag != 0:
+		return nil, fmt.Errorf("Bad X-address tag: %s", s)
+	}
+	return &x, nil
+}
+
+// NewXAddressFromClassic encodes a classic address and optional tag
+func NewXAddressFromClassic(address string, tag *uint32, testnet bool) (*XAddress, error) {
+	account, err := NewAccountFromAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return &XAddress{Account: *account, Tag: tag, Testnet: testnet}, nil
+}
+
+// Classic returns the classic address and tag
+func (x XAddress) Classic() (string, *uint32) {
+	return x.Account.String(), x.Tag
+}
+
+func (x XAddress) String() string {
+	b := append([]byte(nil), xAddressMainnet...)
+	if x.Testnet {
+		b = append([]byte(nil), xAddressTestnet...)
+	}
+	b = append(b, x.Account[:]...)
+	if x.Tag != nil {
+		b = append(b, 1)
+		b = binary.LittleEndian.AppendUint64(b, uint64(*x.Tag))
+	} else {
+		b = append(b, make([]byte, 9)...)
+	}
+	return crypto.Base58Encode(b, crypto.ALPHABET)
+}
+
+func (x XAddress) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+func (x *XAddress) UnmarshalText(b []byte) error {
+	address, err := NewXAddress(string(b))
+	if err != nil {
+		return err
+	}
+	*x = *address
+	return nil
+}
+
+// isXAddress is true of what may be an X-address, as a classic
+// address always begins with r
+func isXAddress(s string) bool {
+	return len(s) > 0 && (s[0] == 'X' || s[0] == 'T')
+}
+
+func accountFromXAddress(s string) (*Account, error) {
+	x, err := NewXAddress(s)
+	if err != nil {
+		return nil, err
+	}
+	if x.Tag != nil {
+		return nil, fmt.Errorf("X-address has a tag, which an Account can't hold: %s", s)
+	}
+	return &x.Account, nil
+}
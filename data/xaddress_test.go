@@ -0,0 +1,55 @@
+package data
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type XAddressSuite struct{}
+
+var _ = Suite(&XAddressSuite{})
+
+var xAddressTests = []struct {
+	classic  string
+	tag      *uint32
+	testnet  bool
+	xAddress string
+}{
+	{"r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59", nil, false, "X7AcgcsBL6XDcUb289X4mJ8djcdyKaB5hJDWMArnXr61cqZ"},
+	{"r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59", nil, true, "T719a5UwUCnEs54UsxG9CJYYDhwmFCqkr7wxCcNcfZ6p5GZ"},
+	{"rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", newUint32(4294967295), false, "XVLhHMPHU98es4dbozjVtdWzVrDjtV18pX8yuPT7y4xaEHi"},
+	{"rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", newUint32(4294967294), false, "XVLhHMPHU98es4dbozjVtdWzVrDjtV1kAsixQTdMjbWi39u"},
+}
+
+func newUint32(n uint32) *uint32 { return &n }
+
+func (s *XAddressSuite) TestXAddress(c *C) {
+	for _, test := range xAddressTests {
+		x, err := NewXAddressFromClassic(test.classic, test.tag, test.testnet)
+		c.Assert(err, IsNil)
+		c.Check(x.String(), Equals, test.xAddress)
+		decoded, err := NewXAddress(test.xAddress)
+		c.Assert(err, IsNil)
+		c.Check(*decoded, DeepEquals, *x)
+		classic, tag := decoded.Classic()
+		c.Check(classic, Equals, test.classic)
+		c.Check(tag, DeepEquals, test.tag)
+	}
+
+	_, err := NewXAddress("rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf")
+	c.Check(err, ErrorMatches, "Not an X-address: .*")
+}
+
+func (s *XAddressSuite) TestAcceptXAddresses(c *C) {
+	var tx struct{ Account, Destination Account }
+	msg := `{"Account":"X7AcgcsBL6XDcUb289X4mJ8djcdyKaB5hJDWMArnXr61cqZ","Destination":"r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59"}`
+	c.Check(json.Unmarshal([]byte(msg), &tx), NotNil)
+
+	AcceptXAddresses = true
+	defer func() { AcceptXAddresses = false }()
+	c.Assert(json.Unmarshal([]byte(msg), &tx), IsNil)
+	c.Check(tx.Account, Equals, tx.Destination)
+	_, err := NewAccountFromAddress("XVLhHMPHU98es4dbozjVtdWzVrDjtV1kAsixQTdMjbWi39u")
+	c.Check(err, ErrorMatches, "X-address has a tag, which an Account can't hold: .*")
+}
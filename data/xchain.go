@@ -0,0 +1,131 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+)
+
+// XChainBridge identifies a bridge by the door accounts on the locking
+// chain, where the asset is locked, and the issuing chain, where it is
+// issued
+type XChainBridge struct {
+	LockingChainDoor  Account
+	LockingChainIssue Asset
+	IssuingChainDoor  Account
+	IssuingChainIssue Asset
+}
+
+func (b XChainBridge) String() string {
+	return fmt.Sprintf("%s %s -> %s %s", b.LockingChainDoor, b.LockingChainIssue, b.IssuingChainDoor, b.IssuingChainIssue)
+}
+
+// The objects witnesses sign, which are the attested fields of the
+// attestation transactions without their signatures
+type claimAttestation struct {
+	XChainBridge             XChainBridge
+	OtherChainSource         Account
+	Amount                   Amount
+	AttestationRewardAccount Account
+	WasLockingChainSend      uint8
+	XChainClaimID            Uint64Hex
+	Destination              *Account
+}
+
+type createAccountAttestation struct {
+	XChainBridge             XChainBridge
+	OtherChainSource         Account
+	Amount                   Amount
+	AttestationRewardAccount Account
+	WasLockingChainSend      uint8
+	XChainAccountCreateCount Uint64Hex
+	Destination              Account
+	SignatureReward          Amount
+}
+
+// AttestationMessage returns the bytes the witness signs
+func (a *XChainAddClaimAttestation) AttestationMessage() ([]byte, error) {
+	return attestationMessage(&claimAttestation{
+		XChainBridge:             a.XChainBridge,
+		OtherChainSource:         a.OtherChainSource,
+		Amount:                   a.Amount,
+		AttestationRewardAccount: a.AttestationRewardAccount,
+		WasLockingChainSend:      a.WasLockingChainSend,
+		XChainClaimID:            a.XChainClaimID,
+		Destination:              a.Destination,
+	})
+}
+
+// AttestationMessage returns the bytes the witness signs
+func (a *XChainAddAccountCreateAttestation) AttestationMessage() ([]byte, error) {
+	return attestationMessage(&createAccountAttestation{
+		XChainBridge:             a.XChainBridge,
+		OtherChainSource:         a.OtherChainSource,
+		Amount:                   a.Amount,
+		AttestationRewardAccount: a.AttestationRewardAccount,
+		WasLockingChainSend:      a.WasLockingChainSend,
+		XChainAccountCreateCount: a.XChainAccountCreateCount,
+		Destination:              a.Destination,
+		SignatureReward:          a.SignatureReward,
+	})
+}
+
+func attestationMessage(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	if err := encode(&b, v, false); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Attestation is implemented by XChainAddClaimAttestation and
+// XChainAddAccountCreateAttestation
+type Attestation interface {
+	Transaction
+	AttestationMessage() ([]byte, error)
+	attestationKey() (*PublicKey, *VariableLength)
+}
+
+func (a *XChainAddClaimAttestation) attestationKey() (*PublicKey, *VariableLength) {
+	return &a.PublicKey, &a.Signature
+}
+
+func (a *XChainAddAccountCreateAttestation) attestationKey() (*PublicKey, *VariableLength) {
+	return &a.PublicKey, &a.Signature
+}
+
+// SignAttestation sets the PublicKey of the attestation to that of key,
+// which must be the witness's key on the bridge, and signs it. The
+// transaction still has to be signed by its submitter.
+func SignAttestation(a Attestation, key crypto.Key, sequence *uint32) error {
+	msg, err := a.AttestationMessage()
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(key.Private(sequence), crypto.Sha512Half(msg), msg)
+	if err != nil {
+		return err
+	}
+	public, signature := a.attestationKey()
+	copy(public[:], key.Public(sequence))
+	*signature = VariableLength(sig)
+	return nil
+}
+
+// VerifyAttestation checks the witness's signature of the attestation
+func VerifyAttestation(a Attestation) error {
+	msg, err := a.AttestationMessage()
+	if err != nil {
+		return err
+	}
+	public, signature := a.attestationKey()
+	ok, err := crypto.Verify(public.Bytes(), crypto.Sha512Half(msg), msg, *signature)
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		return fmt.Errorf("Bad attestation signature by %s", public)
+	}
+	return nil
+}
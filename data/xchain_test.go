@@ -0,0 +1,108 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+	. "gopkg.in/check.v1"
+)
+
+type XChainSuite struct{}
+
+var _ = Suite(&XChainSuite{})
+
+const (
+	xchainAccount = `"Account":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","Fee":"10","Sequence":5`
+	xchainBridge  = `"XChainBridge":{"LockingChainDoor":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","LockingChainIssue":{"currency":"XRP"},"IssuingChainDoor":"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh","IssuingChainIssue":{"currency":"XRP"}}`
+)
+
+var xchainTransactions = []string{
+	`{"TransactionType":"XChainCreateBridge",` + xchainAccount + `,` + xchainBridge + `,"SignatureReward":"100"}`,
+	`{"TransactionType":"XChainModifyBridge",` + xchainAccount + `,"Flags":65536,` + xchainBridge + `,"SignatureReward":"200"}`,
+	`{"TransactionType":"XChainCreateClaimID",` + xchainAccount + `,` + xchainBridge + `,"SignatureReward":"100","OtherChainSource":"rMKXGCbJ5d8LbrqthdG46q3f969MVK2Qeg"}`,
+	`{"TransactionType":"XChainCommit",` + xchainAccount + `,` + xchainBridge + `,"XChainClaimID":"0000000000000013","Amount":"10000","OtherChainDestination":"rMKXGCbJ5d8LbrqthdG46q3f969MVK2Qeg"}`,
+	`{"TransactionType":"XChainClaim",` + xchainAccount + `,` + xchainBridge + `,"XChainClaimID":"0000000000000013","Destination":"rMKXGCbJ5d8LbrqthdG46q3f969MVK2Qeg","DestinationTag":7,"Amount":"10000"}`,
+	`{"TransactionType":"XChainAccountCreateCommit",` + xchainAccount + `,` + xchainBridge + `,"Destination":"rMKXGCbJ5d8LbrqthdG46q3f969MVK2Qeg","Amount":"20000000","SignatureReward":"100"}`,
+	`{"TransactionType":"XChainAddClaimAttestation",` + xchainAccount + `,` + xchainBridge + `,"AttestationSignerAccount":"rBepJuTLFJt3WmtLXYAxSjtBWAeQxVbncv","OtherChainSource":"rMKXGCbJ5d8LbrqthdG46q3f969MVK2Qeg","Amount":"10000","AttestationRewardAccount":"rBepJuTLFJt3WmtLXYAxSjtBWAeQxVbncv","WasLockingChainSend":1,"XChainClaimID":"0000000000000013","Destination":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"}`,
+	`{"TransactionType":"XChainAddAccountCreateAttestation",` + xchainAccount + `,` + xchainBridge + `,"AttestationSignerAccount":"rBepJuTLFJt3WmtLXYAxSjtBWAeQxVbncv","OtherChainSource":"rMKXGCbJ5d8LbrqthdG46q3f969MVK2Qeg","Amount":"20000000","AttestationRewardAccount":"rBepJuTLFJt3WmtLXYAxSjtBWAeQxVbncv","WasLockingChainSend":1,"XChainAccountCreateCount":"0000000000000002","Destination":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","SignatureReward":"100"}`,
+}
+
+func (s *XChainSuite) TestXChainTransactions(c *C) {
+	for _, test := range xchainTransactions {
+		var txm TransactionWithMetaData
+		c.Assert(json.Unmarshal([]byte(test), &txm), IsNil, Commentf(test))
+		_, raw, err := Raw(txm.Transaction)
+		c.Assert(err, IsNil, Commentf(test))
+		decoded, err := ReadTransaction(bytes.NewReader(raw))
+		c.Assert(err, IsNil, Commentf(test))
+		c.Check(decoded, DeepEquals, txm.Transaction, Commentf(test))
+		b, err := json.Marshal(decoded)
+		c.Assert(err, IsNil)
+		c.Check(string(b), Matches, `.*`+xchainBridge+`.*`)
+	}
+}
+
+func (s *XChainSuite) TestXChainCreateBridgeEncoding(c *C) {
+	var txm TransactionWithMetaData
+	c.Assert(json.Unmarshal([]byte(xchainTransactions[0]), &txm), IsNil)
+	_, raw, err := Raw(txm.Transaction)
+	c.Assert(err, IsNil)
+	c.Check(string(b2h(raw)), Equals, "120030"+"2400000005"+
+		"68400000000000000A"+
+		"601D4000000000000064"+
+		"81140A20B3C85F482532A9578DBB3950B85CA06594D1"+
+		"0119"+
+		"140A20B3C85F482532A9578DBB3950B85CA06594D1"+"0000000000000000000000000000000000000000"+
+		"14B5F762798A53D543A014CAF8B297CFF8F2F937E8"+"0000000000000000000000000000000000000000")
+}
+
+func (s *XChainSuite) TestAttestation(c *C) {
+	key, err := crypto.NewEd25519Key([]byte("witness"))
+	c.Assert(err, IsNil)
+	for _, test := range xchainTransactions[6:] {
+		var txm TransactionWithMetaData
+		c.Assert(json.Unmarshal([]byte(test), &txm), IsNil)
+		attestation := txm.Transaction.(Attestation)
+		c.Assert(SignAttestation(attestation, key, nil), IsNil)
+		c.Check(VerifyAttestation(attestation), IsNil)
+
+		// The attestation survives the transaction being encoded
+		_, raw, err := Raw(attestation)
+		c.Assert(err, IsNil)
+		decoded, err := ReadTransaction(bytes.NewReader(raw))
+		c.Assert(err, IsNil)
+		c.Check(VerifyAttestation(decoded.(Attestation)), IsNil)
+
+		attestation.GetBase().Sequence++
+		c.Check(VerifyAttestation(attestation), IsNil)
+		switch a := attestation.(type) {
+		case *XChainAddClaimAttestation:
+			a.XChainClaimID++
+		case *XChainAddAccountCreateAttestation:
+			a.XChainAccountCreateCount++
+		}
+		c.Check(VerifyAttestation(attestation), ErrorMatches, "Bad attestation signature by .*")
+	}
+}
+
+func (s *XChainSuite) TestXChainOwnedClaimID(c *C) {
+	var entries LedgerEntrySlice
+	c.Assert(json.Unmarshal([]byte(`[{"LedgerEntryType":"XChainOwnedClaimID","Account":"rMKXGCbJ5d8LbrqthdG46q3f969MVK2Qeg","Flags":0,`+xchainBridge+`,"XChainClaimID":"0000000000000013","OtherChainSource":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","SignatureReward":"100","OwnerNode":"0","XChainClaimAttestations":[{"XChainClaimAttestationCollectionElement":{"AttestationSignerAccount":"rBepJuTLFJt3WmtLXYAxSjtBWAeQxVbncv","PublicKey":"ED0A7E2C6A9C3C1C1D6DE0A3C6F6FBE5B4C4B1AE0A0CE9E1AB5B0B4C0F3D3B8C2E","Amount":"10000","AttestationRewardAccount":"rBepJuTLFJt3WmtLXYAxSjtBWAeQxVbncv","WasLockingChainSend":1}}],"index":"5A92F6ED33FDA68FB4B9FD140EA38C056CD2BA9673ECA5B4CEF40F2166BB6F0C"}]`), &entries), IsNil)
+	claim, ok := entries[0].(*XChainOwnedClaimID)
+	c.Assert(ok, Equals, true)
+	c.Assert(claim.XChainClaimAttestations, HasLen, 1)
+
+	var buf bytes.Buffer
+	c.Assert(encode(&buf, claim, false), IsNil)
+	node := append([]byte{0x11, 0x00, byte(XCHAIN_OWNED_CLAIM_ID)}, buf.Bytes()...)
+	node = append(node, claim.LedgerIndex[:]...)
+	le, err := ReadLedgerEntry(bytes.NewReader(node), *claim.LedgerIndex)
+	c.Assert(err, IsNil)
+	decoded, ok := le.(*XChainOwnedClaimID)
+	c.Assert(ok, Equals, true)
+	c.Check(*decoded.XChainBridge, Equals, *claim.XChainBridge)
+	c.Check(*decoded.XChainClaimID, Equals, Uint64Hex(19))
+	c.Check(decoded.XChainClaimAttestations, DeepEquals, claim.XChainClaimAttestations)
+	c.Check(decoded.Affects(*claim.Account), Equals, true)
+}
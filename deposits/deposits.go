@@ -0,0 +1,337 @@
+// Package deposits finds the payments made to a set of receiving
+// addresses, as an exchange crediting its customers needs to. Only
+// validated transactions are credited, and a validated ledger is never
+// undone, so each Deposit is final when it is sent.
+//
+//	credits := make(chan *deposits.Deposit)
+//	w := deposits.NewWatcher(credits)
+//	w.Add(deposits.Address{Account: hot, Tag: &customerTag})
+//	sub, err := remote.SubscribeTo(w.Subscription())
+//	...
+//	w.Watch(sub.Transactions)
+//	err = w.CatchUp(ctx, remote, lastLedgerCredited)
+//
+// A Deposit may be sent again, such as when caught up with transactions
+// which were streamed too, so each has a Key to credit it once only.
+package deposits
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// How many ledgers behind the last one seen the keys of deposits are kept
+// for, so that a Deposit sent within them isn't sent again
+const DefaultMemory = 1024
+
+// Client is the part of ripple.Client needed to catch up with deposits
+type Client interface {
+	AccountTxCtx(ctx context.Context, account data.Account, options websockets.AccountTxOptions) (<-chan *data.TransactionWithMetaData, <-chan error)
+}
+
+// Address is where deposits are received. A nil Tag receives the payments
+// to Account with any destination tag, or none.
+type Address struct {
+	Account data.Account
+	Tag     *uint32
+}
+
+func (a Address) String() string {
+	if a.Tag == nil {
+		return a.Account.String()
+	}
+	return fmt.Sprintf("%s:%d", a.Account, *a.Tag)
+}
+
+// Deposit is a validated payment to a watched Address
+type Deposit struct {
+	// Unique to the deposit, whichever way it was found
+	Key       string
+	Address   Address // With the Tag of the payment, if any
+	Source    data.Account
+	SourceTag *uint32
+	// What reached Address, from the metadata, which for a partial
+	// payment may be much less than its Amount
+	Amount           data.Amount
+	PartialPayment   bool
+	Hash             data.Hash256
+	LedgerSequence   uint32
+	TransactionIndex uint32
+}
+
+func (d Deposit) String() string {
+	return fmt.Sprintf("%s %s from %s Ledger: %d Hash: %s", d.Address, d.Amount, d.Source, d.LedgerSequence, d.Hash)
+}
+
+// watched are the tags of an account which are watched
+type watched struct {
+	any  bool
+	tags map[uint32]bool
+}
+
+// Watcher sends a Deposit for each validated payment to a watched Address.
+// Payments which failed, and those of an account to itself, are not
+// deposits.
+type Watcher struct {
+	// Leave out partial payments, rather than credit what they delivered
+	SkipPartialPayments bool
+	// If not nil, transactions to watched addresses which can't be
+	// credited safely are reported to Errors
+	Errors chan<- error
+	// Ledgers behind the last one seen for which keys are remembered.
+	// Zero means DefaultMemory.
+	Memory uint32
+
+	deposits chan<- *Deposit
+	mu       sync.Mutex
+	accounts map[data.Account]*watched
+	seen     map[string]uint32 // Ledger of each Key sent
+	last     uint32            // The last validated ledger seen
+	// Where to catch up from after a restart, which only follows last
+	// once every account has been caught up with
+	resume   uint32
+	caughtUp bool
+}
+
+// NewWatcher returns a Watcher which sends deposits to deposits, which
+// must be received or Update blocks
+func NewWatcher(deposits chan<- *Deposit) *Watcher {
+	return &Watcher{
+		deposits: deposits,
+		accounts: make(map[data.Account]*watched),
+		seen:     make(map[string]uint32),
+		caughtUp: true,
+	}
+}
+
+// Add watches addresses
+func (w *Watcher) Add(addresses ...Address) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, address := range addresses {
+		account, ok := w.accounts[address.Account]
+		if !ok {
+			account = &watched{tags: make(map[uint32]bool)}
+			w.accounts[address.Account] = account
+		}
+		if address.Tag == nil {
+			account.any = true
+		} else {
+			account.tags[*address.Tag] = true
+		}
+	}
+}
+
+// Remove stops watching address. Removing an Address without a Tag leaves
+// those with one watched.
+func (w *Watcher) Remove(address Address) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	account, ok := w.accounts[address.Account]
+	if !ok {
+		return
+	}
+	if address.Tag == nil {
+		account.any = false
+	} else {
+		delete(account.tags, *address.Tag)
+	}
+	if !account.any && len(account.tags) == 0 {
+		delete(w.accounts, address.Account)
+	}
+}
+
+// Subscription returns the options to subscribe to the transactions of
+// the watched accounts
+func (w *Watcher) Subscription() websockets.SubscriptionOptions {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var options websockets.SubscriptionOptions
+	for account := range w.accounts {
+		options.Accounts = append(options.Accounts, account)
+	}
+	sort.Slice(options.Accounts, func(i, j int) bool { return options.Accounts[i].Less(options.Accounts[j]) })
+	return options
+}
+
+// Validated returns where to catch up from after a restart: the last
+// validated ledger from which a transaction has been seen, or, until
+// CatchUp has finished with every account, the ledger it started from.
+// Zero means from the earliest.
+func (w *Watcher) Validated() uint32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.resume
+}
+
+// Update sends the Deposit made by a validated transaction, if any.
+// Proposed transactions are ignored.
+func (w *Watcher) Update(msg *websockets.TransactionStreamMsg) {
+	if !msg.Validated {
+		return
+	}
+	ledger := msg.LedgerSequence
+	if ledger == 0 {
+		ledger = msg.Transaction.LedgerSequence
+	}
+	w.update(&msg.Transaction, ledger)
+}
+
+// Watch calls Update for every message on transactions until it is
+// closed, such as the Transactions channel of a Subscription
+func (w *Watcher) Watch(transactions <-chan *websockets.TransactionStreamMsg) {
+	go func() {
+		for msg := range transactions {
+			w.Update(msg)
+		}
+	}()
+}
+
+// CatchUp sends the deposits of the validated ledgers from minLedger on,
+// read with account_tx for each watched account in turn, oldest first. The
+// transactions stream should be subscribed to first, so that no ledger
+// is missed between the two, and the deposits found by both are sent
+// once only, unless they are further apart than Memory. Validated stays
+// at minLedger until every account has been caught up with.
+func (w *Watcher) CatchUp(ctx context.Context, client Client, minLedger int64) error {
+	w.mu.Lock()
+	var accounts []data.Account
+	for account := range w.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Less(accounts[j]) })
+	w.caughtUp, w.resume = false, 0
+	if minLedger > 0 {
+		w.resume = uint32(minLedger)
+	}
+	w.mu.Unlock()
+	for _, account := range accounts {
+		txs, errs := client.AccountTxCtx(ctx, account, websockets.AccountTxOptions{
+			MinLedger: minLedger,
+			MaxLedger: -1,
+			Forward:   true,
+		})
+		for txm := range txs {
+			w.update(txm, txm.LedgerSequence)
+		}
+		if err := <-errs; err != nil {
+			return err
+		}
+	}
+	w.mu.Lock()
+	w.caughtUp = true
+	if w.last > w.resume {
+		w.resume = w.last
+	}
+	w.mu.Unlock()
+	return nil
+}
+
+// update sends the deposit made by txm, validated in ledger
+func (w *Watcher) update(txm *data.TransactionWithMetaData, ledger uint32) {
+	w.mu.Lock()
+	if ledger > w.last {
+		w.last = ledger
+		w.forget()
+	}
+	if w.caughtUp && ledger > w.resume {
+		w.resume = ledger
+	}
+	deposit, err := w.deposit(txm, ledger)
+	if deposit != nil {
+		if _, ok := w.seen[deposit.Key]; ok {
+			deposit = nil
+		} else {
+			w.seen[deposit.Key] = ledger
+		}
+	}
+	w.mu.Unlock()
+	switch {
+	case err != nil && w.Errors != nil:
+		w.Errors <- err
+	case deposit != nil && w.deposits != nil:
+		w.deposits <- deposit
+	}
+}
+
+// forget removes the keys of deposits older than Memory
+func (w *Watcher) forget() {
+	memory := w.Memory
+	if memory == 0 {
+		memory = DefaultMemory
+	}
+	if w.last <= memory {
+		return
+	}
+	for key, ledger := range w.seen {
+		if ledger < w.last-memory {
+			delete(w.seen, key)
+		}
+	}
+}
+
+// deposit returns the Deposit made by txm, or nil if it isn't one
+func (w *Watcher) deposit(txm *data.TransactionWithMetaData, ledger uint32) (*Deposit, error) {
+	payment, ok := txm.Transaction.(*data.Payment)
+	if !ok || !txm.MetaData.TransactionResult.Success() || payment.Account == payment.Destination {
+		return nil, nil
+	}
+	account, ok := w.accounts[payment.Destination]
+	switch {
+	case !ok:
+		return nil, nil
+	case payment.DestinationTag == nil && !account.any:
+		return nil, nil
+	case payment.DestinationTag != nil && !account.any && !account.tags[*payment.DestinationTag]:
+		return nil, nil
+	case w.SkipPartialPayments && txm.IsPartialPayment():
+		return nil, nil
+	}
+	amount, err := received(txm, payment)
+	if err != nil {
+		return nil, err
+	}
+	deposit := &Deposit{
+		Key:              fmt.Sprintf("%s:%s", txm.GetHash(), payment.Destination),
+		Address:          Address{Account: payment.Destination, Tag: payment.DestinationTag},
+		Source:           payment.Account,
+		SourceTag:        payment.SourceTag,
+		Amount:           *amount,
+		PartialPayment:   txm.IsPartialPayment(),
+		Hash:             *txm.GetHash(),
+		LedgerSequence:   ledger,
+		TransactionIndex: txm.MetaData.TransactionIndex,
+	}
+	return deposit, nil
+}
+
+// received returns what payment added to the balance of its destination,
+// from the balance changes of its metadata, which must agree with its
+// DeliveredAmount when that is known
+func received(txm *data.TransactionWithMetaData, payment *data.Payment) (*data.Amount, error) {
+	analysis, err := txm.Analyse()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot analyse %s: %s", txm.GetHash(), err)
+	}
+	total := payment.Amount.ZeroClone()
+	for _, change := range analysis.Balances {
+		if change.Account != payment.Destination || change.Currency != payment.Amount.Currency {
+			continue
+		}
+		if total.Value, err = total.Value.Add(change.Change); err != nil {
+			return nil, err
+		}
+	}
+	if !total.IsPositive() {
+		return nil, fmt.Errorf("Payment %s added nothing to the balance of %s", txm.GetHash(), payment.Destination)
+	}
+	if delivered, ok := txm.DeliveredAmount(); ok && !delivered.Value.Equals(*total.Value) {
+		return nil, fmt.Errorf("Payment %s delivered %s but the metadata shows %s", txm.GetHash(), delivered, total)
+	}
+	return total, nil
+}
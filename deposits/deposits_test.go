@@ -0,0 +1,230 @@
+package deposits
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type DepositsSuite struct{}
+
+var _ = Suite(&DepositsSuite{})
+
+const (
+	gateway = "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"
+	sender  = "rGgj3GurcrAqgBXGVoS9wvQG3Hjkj5oCbj"
+	tag     = uint32(54025705)
+	hash    = "AEEAE8066773533225B466CA8200BB56C2B68F3835B08770994D9E096A3B67B1"
+)
+
+// payment returns a validated Payment of 20 USD from sender to gateway,
+// with tag, in ledger 7206416
+func payment(c *C) *websockets.TransactionStreamMsg {
+	b, err := ioutil.ReadFile("../data/testdata/transaction_payment_with_rippling.json")
+	c.Assert(err, IsNil)
+	msg := &websockets.TransactionStreamMsg{Validated: true}
+	c.Assert(json.Unmarshal(b, &msg.Transaction), IsNil)
+	msg.LedgerSequence = msg.Transaction.LedgerSequence
+	return msg
+}
+
+func account(c *C, address string) data.Account {
+	a, err := data.NewAccountFromAddress(address)
+	c.Assert(err, IsNil)
+	return *a
+}
+
+func tagged(c *C, tag uint32) Address {
+	return Address{Account: account(c, gateway), Tag: &tag}
+}
+
+func (s *DepositsSuite) TestUpdate(c *C) {
+	deposits := make(chan *Deposit, 10)
+	w := NewWatcher(deposits)
+	w.Add(tagged(c, tag))
+	msg := payment(c)
+
+	proposed := *msg
+	proposed.Validated = false
+	w.Update(&proposed)
+	c.Check(deposits, HasLen, 0)
+	c.Check(w.Validated(), Equals, uint32(0))
+
+	w.Update(msg)
+	c.Assert(deposits, HasLen, 1)
+	deposit := <-deposits
+	c.Check(deposit.Key, Equals, hash+":"+gateway)
+	c.Check(deposit.Address.String(), Equals, fmt.Sprintf("%s:%d", gateway, tag))
+	c.Check(deposit.Source, Equals, account(c, sender))
+	c.Check(deposit.SourceTag, IsNil)
+	c.Check(deposit.Amount.String(), Equals, "20/USD/"+gateway)
+	c.Check(deposit.PartialPayment, Equals, false)
+	c.Check(deposit.Hash.String(), Equals, hash)
+	c.Check(deposit.LedgerSequence, Equals, uint32(7206416))
+	c.Check(deposit.TransactionIndex, Equals, uint32(5))
+	c.Check(w.Validated(), Equals, uint32(7206416))
+
+	// Once only
+	w.Update(msg)
+	c.Check(deposits, HasLen, 0)
+}
+
+func (s *DepositsSuite) TestAddresses(c *C) {
+	deposits := make(chan *Deposit, 10)
+	w := NewWatcher(deposits)
+	c.Check(w.Subscription().Accounts, HasLen, 0)
+
+	// Another tag
+	w.Add(tagged(c, tag+1))
+	c.Check(w.Subscription().Accounts, DeepEquals, []data.Account{account(c, gateway)})
+	w.Update(payment(c))
+	c.Check(deposits, HasLen, 0)
+
+	// Any tag
+	w.Add(Address{Account: account(c, gateway)})
+	w.Update(payment(c))
+	c.Check(deposits, HasLen, 1)
+
+	w.Remove(Address{Account: account(c, gateway)})
+	w.seen = make(map[string]uint32)
+	w.Update(payment(c))
+	c.Check(deposits, HasLen, 1)
+	w.Remove(tagged(c, tag+1))
+	c.Check(w.Subscription().Accounts, HasLen, 0)
+
+	// Failed
+	w.Add(tagged(c, tag))
+	failed := payment(c)
+	failed.Transaction.MetaData.TransactionResult = data.TecPATH_DRY
+	w.Update(failed)
+	c.Check(deposits, HasLen, 1)
+}
+
+func (s *DepositsSuite) TestPartialPayment(c *C) {
+	deposits := make(chan *Deposit, 10)
+	w := NewWatcher(deposits)
+	w.Add(tagged(c, tag))
+	msg := payment(c)
+	partial := data.TxPartialPayment
+	msg.Transaction.Transaction.(*data.Payment).Flags = &partial
+
+	w.SkipPartialPayments = true
+	w.Update(msg)
+	c.Check(deposits, HasLen, 0)
+
+	w.SkipPartialPayments = false
+	w.Update(msg)
+	c.Assert(deposits, HasLen, 1)
+	deposit := <-deposits
+	c.Check(deposit.PartialPayment, Equals, true)
+	c.Check(deposit.Amount.String(), Equals, "20/USD/"+gateway)
+}
+
+func (s *DepositsSuite) TestMismatch(c *C) {
+	deposits, errs := make(chan *Deposit, 10), make(chan error, 10)
+	w := NewWatcher(deposits)
+	w.Errors = errs
+	w.Add(tagged(c, tag))
+	msg := payment(c)
+	delivered, err := data.NewAmount("2000/USD/" + gateway)
+	c.Assert(err, IsNil)
+	msg.Transaction.MetaData.DeliveredAmount = delivered
+	w.Update(msg)
+	c.Check(deposits, HasLen, 0)
+	c.Assert(errs, HasLen, 1)
+	c.Check(<-errs, ErrorMatches, "Payment "+hash+" delivered 2000/USD/.* but the metadata shows 20/USD/.*")
+}
+
+// history is a Client whose accounts have the same transactions, except
+// for those which fail
+type history struct {
+	txs     []*data.TransactionWithMetaData
+	options []websockets.AccountTxOptions
+	failing map[data.Account]error
+}
+
+func (h *history) AccountTxCtx(ctx context.Context, account data.Account, options websockets.AccountTxOptions) (<-chan *data.TransactionWithMetaData, <-chan error) {
+	h.options = append(h.options, options)
+	txs, errs := make(chan *data.TransactionWithMetaData, len(h.txs)), make(chan error, 1)
+	if err, ok := h.failing[account]; ok {
+		errs <- err
+	} else {
+		for _, tx := range h.txs {
+			txs <- tx
+		}
+	}
+	close(txs)
+	close(errs)
+	return txs, errs
+}
+
+func (s *DepositsSuite) TestCatchUp(c *C) {
+	deposits := make(chan *Deposit, 10)
+	w := NewWatcher(deposits)
+	w.Memory = 10
+	w.Add(tagged(c, tag), Address{Account: account(c, sender)})
+	msg := payment(c)
+	client := &history{txs: []*data.TransactionWithMetaData{&msg.Transaction}}
+
+	c.Assert(w.CatchUp(context.Background(), client, 7206400), IsNil)
+	c.Check(deposits, HasLen, 1)
+	c.Check(client.options, DeepEquals, []websockets.AccountTxOptions{
+		{MinLedger: 7206400, MaxLedger: -1, Forward: true},
+		{MinLedger: 7206400, MaxLedger: -1, Forward: true},
+	})
+
+	// Streamed too
+	w.Update(msg)
+	c.Check(deposits, HasLen, 1)
+
+	// Forgotten
+	later := payment(c)
+	later.LedgerSequence += 11
+	*later.Transaction.GetHash() = data.Hash256{1}
+	w.Update(later)
+	c.Check(deposits, HasLen, 2)
+	w.Update(msg)
+	c.Check(deposits, HasLen, 3)
+}
+
+func (s *DepositsSuite) TestResume(c *C) {
+	deposits := make(chan *Deposit, 10)
+	w := NewWatcher(deposits)
+	addresses := []Address{tagged(c, tag), {Account: account(c, sender)}}
+	w.Add(addresses...)
+	msg := payment(c)
+	// The gateway is caught up with first, then the process dies
+	last := account(c, sender)
+	if last.Less(account(c, gateway)) {
+		last = account(c, gateway)
+	}
+	client := &history{
+		txs:     []*data.TransactionWithMetaData{&msg.Transaction},
+		failing: map[data.Account]error{last: errors.New("Disconnected")},
+	}
+	c.Check(w.CatchUp(context.Background(), client, 7206400), ErrorMatches, "Disconnected")
+	c.Check(w.Validated(), Equals, uint32(7206400))
+	streamed := payment(c)
+	streamed.LedgerSequence += 5
+	*streamed.Transaction.GetHash() = data.Hash256{1}
+	w.Update(streamed)
+	c.Check(w.Validated(), Equals, uint32(7206400))
+
+	// Restarted from where it says
+	restarted := NewWatcher(deposits)
+	restarted.Add(addresses...)
+	client.failing = nil
+	c.Assert(restarted.CatchUp(context.Background(), client, int64(w.Validated())), IsNil)
+	c.Check(restarted.Validated(), Equals, msg.LedgerSequence)
+	restarted.Update(streamed)
+	c.Check(restarted.Validated(), Equals, streamed.LedgerSequence)
+}
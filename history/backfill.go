@@ -0,0 +1,40 @@
+package history
+
+import (
+	"context"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// Client is the part of ripple.Client needed to backfill
+type Client interface {
+	AccountTxPagesCtx(ctx context.Context, account data.Account, options websockets.AccountTxOptions) (<-chan *websockets.AccountTxResult, <-chan error)
+}
+
+// Backfill stores the transactions of account selected by options, a page
+// at a time, saving with each page the marker of the next. Unless
+// options.Marker is set, it resumes after the last page stored by an
+// earlier Backfill of account, and does nothing if that one finished.
+// Binary pages have no dates, so can't be queried by date.
+func (s *Store) Backfill(ctx context.Context, client Client, account data.Account, options websockets.AccountTxOptions) error {
+	marker, complete, err := s.BackfillMarker(ctx, account)
+	if err != nil {
+		return err
+	}
+	if options.Marker == nil {
+		if complete {
+			return nil
+		}
+		options.Marker = marker
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pages, errs := client.AccountTxPagesCtx(ctx, account, options)
+	for page := range pages {
+		if err := s.PutBackfillPage(ctx, account, page); err != nil {
+			return err
+		}
+	}
+	return <-errs
+}
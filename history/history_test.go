@@ -0,0 +1,404 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+var postgresDSN = flag.String("postgres", "", "DSN of a PostgreSQL database to test, whose history tables are emptied")
+
+// HistorySuite tests a Store of each Storage
+type HistorySuite struct {
+	open func(c *C) *Store
+}
+
+var _ = Suite(&HistorySuite{open: openSQLite})
+
+type PostgresSuite struct {
+	HistorySuite
+}
+
+var _ = Suite(&PostgresSuite{HistorySuite{open: openPostgres}})
+
+func (s *PostgresSuite) SetUpSuite(c *C) {
+	if *postgresDSN == "" {
+		c.Skip("-postgres not given")
+	}
+	// So that the batches of the tests are COPYed
+	copyThreshold = 1
+}
+
+func (s *PostgresSuite) TearDownSuite(c *C) {
+	copyThreshold = 16
+}
+
+func readFixture(c *C, name string, v interface{}) {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	c.Assert(err, IsNil)
+	c.Assert(json.Unmarshal(b, v), IsNil)
+}
+
+func accountTx(c *C) *websockets.AccountTxResult {
+	var fixture struct {
+		Result websockets.AccountTxResult `json:"result"`
+	}
+	readFixture(c, "account_tx.json", &fixture)
+	c.Assert(fixture.Result.Transactions, HasLen, 2)
+	return &fixture.Result
+}
+
+func account(c *C, address string) data.Account {
+	a, err := data.NewAccountFromAddress(address)
+	c.Assert(err, IsNil)
+	return *a
+}
+
+func openSQLite(c *C) *Store {
+	store, err := OpenSQLite(filepath.Join(c.MkDir(), "history.db"))
+	c.Assert(err, IsNil)
+	return store
+}
+
+func openPostgres(c *C) *Store {
+	store, err := OpenPostgres(*postgresDSN)
+	c.Assert(err, IsNil)
+	_, err = store.Storage.(*sqlStorage).db.Exec(`TRUNCATE ledgers, transactions, affected_accounts, balance_changes, backfills, account_state`)
+	c.Assert(err, IsNil)
+	return store
+}
+
+func (s *HistorySuite) TestAdd(c *C) {
+	store := s.open(c)
+	defer store.Close()
+	ctx := context.Background()
+	txs := accountTx(c).Transactions
+	c.Assert(store.PutTransactions(ctx, txs...), IsNil)
+	// Again, which does nothing
+	c.Assert(store.PutTransactions(ctx, txs[0]), IsNil)
+
+	stored, err := store.Transactions(ctx, Query{})
+	c.Assert(err, IsNil)
+	c.Assert(stored, HasLen, 2)
+	// Oldest first, where account_tx gave the newest first
+	for i, txm := range stored {
+		tx := txs[1-i]
+		c.Check(txm.GetHash(), DeepEquals, tx.GetHash())
+		c.Check(txm.LedgerSequence, Equals, tx.LedgerSequence)
+		c.Check(txm.Date, Equals, tx.Date)
+		c.Check(txm.MetaData.TransactionIndex, Equals, tx.MetaData.TransactionIndex)
+		c.Check(txm.MetaData.TransactionResult, Equals, tx.MetaData.TransactionResult)
+		c.Check(txm.MetaData.AffectedNodes, HasLen, len(tx.MetaData.AffectedNodes))
+	}
+	c.Check(stored[0].GetType(), Equals, "OfferCreate")
+
+	txm, err := store.GetTransaction(ctx, *txs[0].GetHash())
+	c.Assert(err, IsNil)
+	c.Check(txm.GetHash(), DeepEquals, txs[0].GetHash())
+	_, err = store.GetTransaction(ctx, data.Hash256{})
+	c.Check(err, Equals, ErrNotFound)
+
+	newest, err := store.ByAccount(ctx, txs[0].Transaction.GetBase().Account, 1)
+	c.Assert(err, IsNil)
+	c.Assert(newest, HasLen, 1)
+	c.Check(newest[0].GetHash().String(), Equals, "D49B101D0304AE4B54D215EB82DF0BFF8F65F2A94F7F23C41D55D3C72CC640E3")
+
+	date := txs[0].Date.Time()
+	byDate, err := store.ByDate(ctx, date, date.Add(time.Second))
+	c.Assert(err, IsNil)
+	c.Check(byDate, HasLen, 2)
+	byDate, err = store.ByDate(ctx, date.Add(time.Second), time.Time{})
+	c.Assert(err, IsNil)
+	c.Check(byDate, HasLen, 0)
+}
+
+func (s *HistorySuite) TestIngest(c *C) {
+	store := s.open(c)
+	defer store.Close()
+	store.BatchSize = 1
+	txs := accountTx(c).Transactions
+	c.Check(store.BatchSize, Equals, 1)
+	ch := make(chan *data.TransactionWithMetaData, len(txs))
+	for _, txm := range txs {
+		ch <- txm
+	}
+	close(ch)
+	c.Assert(store.Ingest(context.Background(), ch), IsNil)
+	stored, err := store.Transactions(context.Background(), Query{})
+	c.Assert(err, IsNil)
+	c.Check(stored, HasLen, 2)
+}
+
+func (s *HistorySuite) TestBatch(c *C) {
+	store := &Store{BatchSize: 2}
+	txs := accountTx(c).Transactions
+	ch := make(chan *data.TransactionWithMetaData, 2)
+	ch <- txs[1]
+	ch <- txs[0]
+	batch, open := store.batch(txs[0], ch)
+	c.Check(batch, DeepEquals, []*data.TransactionWithMetaData{txs[0], txs[1]})
+	c.Check(open, Equals, true)
+	close(ch)
+	batch, open = store.batch(txs[1], ch)
+	c.Check(batch, DeepEquals, []*data.TransactionWithMetaData{txs[1], txs[0]})
+	c.Check(open, Equals, true)
+	batch, open = store.batch(txs[1], ch)
+	c.Check(batch, HasLen, 1)
+	c.Check(open, Equals, false)
+}
+
+func (s *HistorySuite) TestIngestStream(c *C) {
+	store := s.open(c)
+	defer store.Close()
+	ctx := context.Background()
+	var msg websockets.TransactionStreamMsg
+	readFixture(c, "transactions_stream.json", &msg)
+	msgs := make(chan *websockets.TransactionStreamMsg, 2)
+	proposed := msg
+	proposed.Validated = false
+	proposed.Transaction.MetaData.TransactionIndex = 99
+	msgs <- &proposed
+	msgs <- &msg
+	close(msgs)
+	c.Assert(store.IngestStream(ctx, msgs), IsNil)
+
+	stored, err := store.Transactions(ctx, Query{})
+	c.Assert(err, IsNil)
+	c.Assert(stored, HasLen, 1)
+	c.Check(stored[0].GetHash(), DeepEquals, msg.Transaction.GetHash())
+	c.Check(stored[0].LedgerSequence, Equals, msg.LedgerSequence)
+	c.Check(stored[0].MetaData.TransactionIndex, Equals, msg.Transaction.MetaData.TransactionIndex)
+	changes, err := store.BalanceChanges(ctx, Query{})
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 1)
+	c.Check(changes[0].Date.Equal(msg.Transaction.Date.Time()), Equals, true)
+}
+
+func (s *HistorySuite) TestBalanceChanges(c *C) {
+	store := s.open(c)
+	defer store.Close()
+	ctx := context.Background()
+	var txm data.TransactionWithMetaData
+	readFixture(c, "payment.json", &txm)
+	c.Assert(store.PutTransactions(ctx, &txm), IsNil)
+
+	analysis, err := txm.Analyse()
+	c.Assert(err, IsNil)
+	changes, err := store.BalanceChanges(ctx, Query{})
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, len(analysis.Balances))
+	for _, change := range changes {
+		c.Check(change.Hash, Equals, *txm.GetHash())
+		c.Check(change.LedgerSequence, Equals, uint32(7206416))
+		c.Check(change.Date.IsZero(), Equals, true)
+	}
+	for _, expected := range analysis.Balances {
+		found := false
+		for _, change := range changes {
+			if change.Account == expected.Account && change.Currency == expected.Currency && change.Counterparty == expected.Counterparty {
+				c.Check(change.Change.Equals(expected.Change), Equals, true, Commentf("%s", expected))
+				c.Check(change.Balance.Equals(expected.Balance), Equals, true, Commentf("%s", expected))
+				found = true
+			}
+		}
+		c.Check(found, Equals, true, Commentf("%s", expected))
+	}
+
+	usd, err := data.NewCurrency("USD")
+	c.Assert(err, IsNil)
+	sender := txm.Transaction.GetBase().Account
+	changes, err = store.BalanceChanges(ctx, Query{Account: &sender, Currency: &usd})
+	c.Assert(err, IsNil)
+	c.Check(changes, HasLen, 2)
+	for _, change := range changes {
+		c.Check(change.Account, Equals, sender)
+		c.Check(change.Currency, Equals, usd)
+	}
+	byCurrency, err := store.ByCurrency(ctx, sender, usd)
+	c.Assert(err, IsNil)
+	c.Check(byCurrency, HasLen, 1)
+	// Every account whose balance changed was affected
+	for _, expected := range analysis.Balances {
+		byAccount, err := store.ByAccount(ctx, expected.Account, 0)
+		c.Assert(err, IsNil)
+		c.Check(byAccount, HasLen, 1)
+	}
+	cny, err := data.NewCurrency("CNY")
+	c.Assert(err, IsNil)
+	byCurrency, err = store.Transactions(ctx, Query{Currency: &cny})
+	c.Assert(err, IsNil)
+	c.Check(byCurrency, HasLen, 0)
+}
+
+// pager is a Client with the pages of account_tx.json, one transaction
+// to a page
+type pager struct {
+	result  *websockets.AccountTxResult
+	markers chan map[string]interface{}
+	fail    bool // After the first page
+}
+
+func (p *pager) AccountTxPagesCtx(ctx context.Context, account data.Account, options websockets.AccountTxOptions) (<-chan *websockets.AccountTxResult, <-chan error) {
+	c, errs := make(chan *websockets.AccountTxResult, 2), make(chan error, 1)
+	defer close(errs)
+	defer close(c)
+	p.markers <- options.Marker
+	if options.Marker == nil {
+		c <- &websockets.AccountTxResult{
+			Marker:       map[string]interface{}{"ledger": 7284002.0, "seq": 7.0},
+			Transactions: p.result.Transactions[:1],
+		}
+		if p.fail {
+			errs <- &websockets.CommandError{Name: "tooBusy", Code: 9}
+			return c, errs
+		}
+	}
+	c <- &websockets.AccountTxResult{Transactions: p.result.Transactions[1:]}
+	return c, errs
+}
+
+func (s *HistorySuite) TestBackfill(c *C) {
+	store := s.open(c)
+	defer store.Close()
+	ctx := context.Background()
+	alice := account(c, "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	client := &pager{result: accountTx(c), markers: make(chan map[string]interface{}, 4), fail: true}
+
+	err := store.Backfill(ctx, client, alice, websockets.AccountTxOptions{})
+	c.Check(err, ErrorMatches, "tooBusy 9 .*")
+	c.Check(<-client.markers, IsNil)
+	stored, err := store.Transactions(ctx, Query{Account: &alice})
+	c.Assert(err, IsNil)
+	c.Check(stored, HasLen, 1)
+
+	// Resumed after the page stored
+	client.fail = false
+	c.Assert(store.Backfill(ctx, client, alice, websockets.AccountTxOptions{}), IsNil)
+	c.Check(<-client.markers, DeepEquals, map[string]interface{}{"ledger": 7284002.0, "seq": 7.0})
+	stored, err = store.Transactions(ctx, Query{Account: &alice})
+	c.Assert(err, IsNil)
+	c.Check(stored, HasLen, 2)
+
+	// Finished, so not asked again until reset
+	c.Assert(store.Backfill(ctx, client, alice, websockets.AccountTxOptions{}), IsNil)
+	c.Check(client.markers, HasLen, 0)
+	c.Assert(store.ResetBackfill(ctx, alice), IsNil)
+	c.Assert(store.Backfill(ctx, client, alice, websockets.AccountTxOptions{}), IsNil)
+	c.Check(<-client.markers, IsNil)
+}
+
+// The header of ledger.json of websockets, which hasn't the parent close
+// time so isn't that of its hash
+const ledgerHeader = `{
+	"accepted": true,
+	"account_hash": "46D3E36FE845B9A18293F4C0F134D7DAFB06D4D9A1C7E4CB03F8B293CCA45FA0",
+	"close_time": 454770710,
+	"close_time_resolution": 10,
+	"closed": true,
+	"hash": "0C5C5B39EA40D40ACA6EB47E50B2B85FD516D1A2BA67BA3E050349D3EF3632A4",
+	"ledger_index": "6917762",
+	"parent_hash": "F8F0363803C30E659AA24D6A62A6512BA24BEA5AC52A29731ABA1E2D80796E8B",
+	"total_coins": "99999990098968782",
+	"transaction_hash": "757CCB586D44F3C58E366EC7618988C0596277D3D5D0B412E49563B5EEDF04FF"
+}`
+
+func (s *HistorySuite) TestLedgers(c *C) {
+	store := s.open(c)
+	defer store.Close()
+	ctx := context.Background()
+	var ledger data.Ledger
+	c.Assert(json.Unmarshal([]byte(ledgerHeader), &ledger), IsNil)
+	c.Assert(store.PutLedgers(ctx, &ledger), IsNil)
+	c.Assert(store.PutLedgers(ctx, &ledger), IsNil)
+
+	stored, err := store.GetLedger(ctx, 6917762)
+	c.Assert(err, IsNil)
+	c.Check(stored.LedgerHeader, DeepEquals, ledger.LedgerHeader)
+	c.Check(stored.Hash, Equals, ledger.Hash)
+	_, err = store.GetLedger(ctx, 6917761)
+	c.Check(err, Equals, ErrNotFound)
+
+	// Without a hash, that of the header is stored
+	next := data.Ledger{LedgerHeader: ledger.LedgerHeader}
+	next.LedgerSequence++
+	next.PreviousLedger = ledger.Hash
+	c.Assert(store.PutLedgers(ctx, &next), IsNil)
+	stored, err = store.GetLedger(ctx, next.LedgerSequence)
+	c.Assert(err, IsNil)
+	c.Check(stored.PreviousLedger, Equals, ledger.Hash)
+	c.Check(stored.Verify(), IsNil)
+}
+
+const accountRoots = `[{
+	"Account": "rKKzk9ghA2iuy3imqMXUHJqdRPMtNDGf4c",
+	"Balance": "601382104",
+	"Flags": 0,
+	"LedgerEntryType": "AccountRoot",
+	"OwnerCount": 0,
+	"PreviousTxnID": "DB4DC693960DAB6030772C3B2CE976839EBD00F6C76EF9A00F9C8CBE91F006A5",
+	"PreviousTxnLgrSeq": 6270804,
+	"Sequence": 1,
+	"index": "00001A2969BE1FC85F1D7A55282FA2E6D95C71D2E4B9C0FDD3D9994F3C00FF8F"
+}, {
+	"Account": "rKKzk9ghA2iuy3imqMXUHJqdRPMtNDGf4c",
+	"Balance": "501382094",
+	"Flags": 0,
+	"LedgerEntryType": "AccountRoot",
+	"OwnerCount": 0,
+	"PreviousTxnID": "757CCB586D44F3C58E366EC7618988C0596277D3D5D0B412E49563B5EEDF04FF",
+	"PreviousTxnLgrSeq": 6917762,
+	"Sequence": 2,
+	"index": "00001A2969BE1FC85F1D7A55282FA2E6D95C71D2E4B9C0FDD3D9994F3C00FF8F"
+}]`
+
+func (s *HistorySuite) TestAccountState(c *C) {
+	store := s.open(c)
+	defer store.Close()
+	ctx := context.Background()
+	var entries data.LedgerEntrySlice
+	c.Assert(json.Unmarshal([]byte(accountRoots), &entries), IsNil)
+	index := *entries[0].GetLedgerIndex()
+	c.Assert(store.PutAccountState(ctx, 6270804, entries[0]), IsNil)
+	c.Assert(store.PutAccountState(ctx, 6917762, entries[1]), IsNil)
+	c.Assert(store.DeleteAccountState(ctx, 7000000, index), IsNil)
+
+	for _, test := range []struct {
+		ledger  uint32
+		balance string
+	}{
+		{6270803, ""},
+		{6270804, "601.382104"},
+		{6917761, "601.382104"},
+		{6917762, "501.382094"},
+		{6999999, "501.382094"},
+		{7000000, ""},
+	} {
+		entry, err := store.GetAccountState(ctx, index, test.ledger)
+		if test.balance == "" {
+			c.Check(err, Equals, ErrNotFound, Commentf("%d", test.ledger))
+			continue
+		}
+		c.Assert(err, IsNil, Commentf("%d", test.ledger))
+		root, ok := entry.(*data.AccountRoot)
+		c.Assert(ok, Equals, true)
+		c.Check(root.Balance.String(), Equals, test.balance, Commentf("%d", test.ledger))
+		c.Check(*root.Account, Equals, account(c, "rKKzk9ghA2iuy3imqMXUHJqdRPMtNDGf4c"))
+		c.Check(*entry.GetHash(), Equals, index)
+	}
+}
+
+func (s *HistorySuite) TestPostgresRebind(c *C) {
+	c.Check(postgresRebind(`SELECT a FROM t WHERE b = ? AND c < ? LIMIT ?`), Equals,
+		`SELECT a FROM t WHERE b = $1 AND c < $2 LIMIT $3`)
+	c.Check(postgresRebind(`DELETE FROM t`), Equals, `DELETE FROM t`)
+}
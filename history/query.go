@@ -0,0 +1,44 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// Query selects stored transactions, oldest first. The zero Query selects
+// them all.
+type Query struct {
+	Account  *data.Account  // Affected by the transaction
+	From, To time.Time      // Closed at or after From and before To, unless zero
+	Currency *data.Currency // Of a balance changed, that of Account when set
+	Limit    int            // Zero for no limit
+	Newest   bool           // Newest first
+}
+
+// ByAccount returns the latest limit transactions which affected account,
+// newest first
+func (s *Store) ByAccount(ctx context.Context, account data.Account, limit int) ([]*data.TransactionWithMetaData, error) {
+	return s.Transactions(ctx, Query{Account: &account, Limit: limit, Newest: true})
+}
+
+// ByDate returns the transactions closed at or after from and before to
+func (s *Store) ByDate(ctx context.Context, from, to time.Time) ([]*data.TransactionWithMetaData, error) {
+	return s.Transactions(ctx, Query{From: from, To: to})
+}
+
+// ByCurrency returns the transactions which changed the balances of
+// account in currency
+func (s *Store) ByCurrency(ctx context.Context, account data.Account, currency data.Currency) ([]*data.TransactionWithMetaData, error) {
+	return s.Transactions(ctx, Query{Account: &account, Currency: &currency})
+}
+
+// BalanceChange is a stored change to a balance, with the transaction
+// which made it
+type BalanceChange struct {
+	data.BalanceChange
+	Hash           data.Hash256
+	LedgerSequence uint32
+	Date           time.Time // Zero when unknown
+}
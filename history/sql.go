@@ -0,0 +1,491 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// dialect is what differs between the databases of sqlStorage
+type dialect struct {
+	schema []string
+	// rebind replaces the ? placeholders of query with the dialect's own
+	rebind func(query string) string
+	// insert adds rows of columns to table, skipping those whose keys
+	// are there already
+	insert func(ctx context.Context, tx *sql.Tx, table string, columns []string, rows [][]interface{}) error
+}
+
+// sqlStorage is a Storage in an SQL database
+type sqlStorage struct {
+	db *sql.DB
+	dialect
+}
+
+var (
+	ledgerColumns      = []string{"ledger_index", "hash", "close_time", "header"}
+	transactionColumns = []string{"hash", "ledger_index", "tx_index", "date", "type", "account", "result", "tx", "meta"}
+	affectedColumns    = []string{"account", "hash"}
+	balanceColumns     = []string{"hash", "account", "counterparty", "currency", "change", "balance"}
+	stateColumns       = []string{"entry_index", "ledger_index", "type", "entry"}
+)
+
+// newSQLStorage creates the tables of d in db, unless they exist
+func newSQLStorage(db *sql.DB, d dialect) (*sqlStorage, error) {
+	for _, statement := range d.schema {
+		if _, err := db.Exec(statement); err != nil {
+			return nil, fmt.Errorf("Creating history schema: %s", err)
+		}
+	}
+	return &sqlStorage{db: db, dialect: d}, nil
+}
+
+func (s *sqlStorage) Close() error {
+	return s.db.Close()
+}
+
+func (s *sqlStorage) inTx(ctx context.Context, f func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertRows is the insert of dialects without a faster way, one row at a
+// time
+func insertRows(ctx context.Context, tx *sql.Tx, rebind func(string) string, table string, columns []string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := tx.PrepareContext(ctx, rebind(fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT DO NOTHING`,
+		table, strings.Join(columns, ", "), placeholders)))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqlStorage) PutLedgers(ctx context.Context, ledgers ...*data.Ledger) error {
+	var rows [][]interface{}
+	for _, ledger := range ledgers {
+		hash, header, err := data.Raw(ledger)
+		if err != nil {
+			return fmt.Errorf("Encoding ledger %d: %s", ledger.LedgerSequence, err)
+		}
+		// That of the server, unless it isn't known
+		if !ledger.Hash.IsZero() {
+			hash = ledger.Hash
+		}
+		rows = append(rows, []interface{}{ledger.LedgerSequence, hash.String(), ledger.CloseTime.Time().Unix(), header})
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.insert(ctx, tx, "ledgers", ledgerColumns, rows)
+	})
+}
+
+func (s *sqlStorage) GetLedger(ctx context.Context, sequence uint32) (*data.Ledger, error) {
+	var (
+		hash   string
+		header []byte
+	)
+	err := s.db.QueryRowContext(ctx, s.rebind(`SELECT hash, header FROM ledgers WHERE ledger_index = ?`),
+		sequence).Scan(&hash, &header)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, ErrNotFound
+	case err != nil:
+		return nil, err
+	}
+	h, err := data.NewHash256(hash)
+	if err != nil {
+		return nil, err
+	}
+	return data.ReadLedger(bytes.NewReader(header), *h)
+}
+
+// Seconds from the Unix epoch to the Ripple epoch, 2000-01-01 UTC
+const rippleEpoch = 946684800
+
+// record is a transaction as stored, with the accounts it affected and
+// the balances it changed
+type record struct {
+	transaction []interface{}
+	affected    [][]interface{}
+	balances    [][]interface{}
+}
+
+func (s *sqlStorage) PutTransactions(ctx context.Context, txs ...*data.TransactionWithMetaData) error {
+	records, err := newRecords(txs)
+	if err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.putRecords(ctx, tx, records)
+	})
+}
+
+func (s *sqlStorage) putRecords(ctx context.Context, tx *sql.Tx, records []record) error {
+	var transactions, affected, balances [][]interface{}
+	for _, r := range records {
+		transactions = append(transactions, r.transaction)
+		affected = append(affected, r.affected...)
+		balances = append(balances, r.balances...)
+	}
+	if err := s.insert(ctx, tx, "transactions", transactionColumns, transactions); err != nil {
+		return err
+	}
+	if err := s.insert(ctx, tx, "affected_accounts", affectedColumns, affected); err != nil {
+		return err
+	}
+	return s.insert(ctx, tx, "balance_changes", balanceColumns, balances)
+}
+
+func newRecords(txs []*data.TransactionWithMetaData, accounts ...data.Account) ([]record, error) {
+	records := make([]record, len(txs))
+	for i, txm := range txs {
+		var err error
+		if records[i], err = newRecord(txm, accounts...); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// newRecord returns the record of txm, which also affected accounts
+func newRecord(txm *data.TransactionWithMetaData, accounts ...data.Account) (record, error) {
+	hash := txm.GetHash().String()
+	raw, meta, err := encode(txm)
+	if err != nil {
+		return record{}, fmt.Errorf("Encoding %s: %s", hash, err)
+	}
+	var date interface{}
+	if txm.Date.Uint32() != 0 {
+		date = txm.Date.Time().Unix()
+	}
+	r := record{transaction: []interface{}{hash, txm.LedgerSequence, txm.MetaData.TransactionIndex, date, txm.GetType(),
+		txm.Transaction.GetBase().Account.String(), txm.MetaData.TransactionResult.String(), raw, meta}}
+	analysis, err := txm.Analyse()
+	if err != nil {
+		return record{}, fmt.Errorf("Analysing %s: %s", hash, err)
+	}
+	affected := map[data.Account]bool{txm.Transaction.GetBase().Account: true}
+	for _, account := range accounts {
+		affected[account] = true
+	}
+	for _, change := range analysis.Balances {
+		affected[change.Account] = true
+		if !change.Counterparty.IsZero() {
+			affected[change.Counterparty] = true
+		}
+		r.balances = append(r.balances, []interface{}{hash, change.Account.String(), counterparty(change),
+			change.Currency.Machine(), change.Amount().Machine(), balance(change).Machine()})
+	}
+	for account := range affected {
+		r.affected = append(r.affected, []interface{}{account.String(), hash})
+	}
+	return r, nil
+}
+
+// The counterparty of an XRP balance change is stored as empty
+func counterparty(change data.BalanceChange) string {
+	if change.Counterparty.IsZero() {
+		return ""
+	}
+	return change.Counterparty.String()
+}
+
+func balance(change data.BalanceChange) *data.Amount {
+	amount := *change.Amount()
+	amount.Value = change.Balance.Clone()
+	return &amount
+}
+
+// encode returns the binary transaction and metadata of txm, as rippled
+// stores them
+func encode(txm *data.TransactionWithMetaData) ([]byte, []byte, error) {
+	_, raw, err := data.Raw(txm)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := bytes.NewReader(raw)
+	var parts [2][]byte
+	for i := range parts {
+		part, err := data.NewVariableByteReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if parts[i], err = io.ReadAll(part); err != nil {
+			return nil, nil, err
+		}
+	}
+	return parts[0], parts[1], nil
+}
+
+func (s *sqlStorage) GetTransaction(ctx context.Context, hash data.Hash256) (*data.TransactionWithMetaData, error) {
+	txs, err := s.transactions(ctx, ` WHERE t.hash = ?`, hash.String())
+	switch {
+	case err != nil:
+		return nil, err
+	case len(txs) == 0:
+		return nil, ErrNotFound
+	}
+	return txs[0], nil
+}
+
+func (s *sqlStorage) Transactions(ctx context.Context, q Query) ([]*data.TransactionWithMetaData, error) {
+	where, args := q.where(false)
+	order, limit := q.order()
+	return s.transactions(ctx, where+order, append(args, limit...)...)
+}
+
+// transactions returns the transactions t selected by the rest of a query
+func (s *sqlStorage) transactions(ctx context.Context, query string, args ...interface{}) ([]*data.TransactionWithMetaData, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT t.hash, t.ledger_index, t.date, t.tx, t.meta
+		FROM transactions t`+query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var txs []*data.TransactionWithMetaData
+	for rows.Next() {
+		var (
+			hash     string
+			ledger   uint32
+			date     sql.NullInt64
+			tx, meta []byte
+		)
+		if err := rows.Scan(&hash, &ledger, &date, &tx, &meta); err != nil {
+			return nil, err
+		}
+		h, err := data.NewHash256(hash)
+		if err != nil {
+			return nil, err
+		}
+		txm, err := data.ReadTransactionAndMetadata(bytes.NewReader(tx), bytes.NewReader(meta), *h, ledger)
+		if err != nil {
+			return nil, fmt.Errorf("Decoding %s: %s", hash, err)
+		}
+		if date.Valid {
+			txm.Date.SetUint32(uint32(date.Int64 - rippleEpoch))
+		}
+		txs = append(txs, txm)
+	}
+	return txs, rows.Err()
+}
+
+func (s *sqlStorage) BalanceChanges(ctx context.Context, q Query) ([]BalanceChange, error) {
+	where, args := q.where(true)
+	order, limit := q.order()
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT b.hash, t.ledger_index, t.date, b.account, b.change, b.balance
+		FROM balance_changes b JOIN transactions t ON t.hash = b.hash`+where+order), append(args, limit...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var changes []BalanceChange
+	for rows.Next() {
+		var (
+			change          BalanceChange
+			hash, account   string
+			date            sql.NullInt64
+			amount, balance string
+		)
+		if err := rows.Scan(&hash, &change.LedgerSequence, &date, &account, &amount, &balance); err != nil {
+			return nil, err
+		}
+		h, err := data.NewHash256(hash)
+		if err != nil {
+			return nil, err
+		}
+		change.Hash = *h
+		a, err := data.NewAccountFromAddress(account)
+		if err != nil {
+			return nil, err
+		}
+		change.Account = *a
+		if date.Valid {
+			change.Date = time.Unix(date.Int64, 0)
+		}
+		changed, err := data.NewAmount(amount)
+		if err != nil {
+			return nil, err
+		}
+		after, err := data.NewAmount(balance)
+		if err != nil {
+			return nil, err
+		}
+		change.Counterparty, change.Currency = changed.Issuer, changed.Currency
+		change.Change, change.Balance = *changed.Value, *after.Value
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// where returns the conditions of q on transactions t, or on their
+// balance changes b, and their arguments
+func (q *Query) where(balances bool) (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	switch {
+	case q.Account != nil && balances:
+		conditions = append(conditions, "b.account = ?")
+		args = append(args, q.Account.String())
+	case q.Account != nil:
+		conditions = append(conditions, "t.hash IN (SELECT hash FROM affected_accounts WHERE account = ?)")
+		args = append(args, q.Account.String())
+	}
+	if !q.From.IsZero() {
+		conditions = append(conditions, "t.date >= ?")
+		args = append(args, q.From.Unix())
+	}
+	if !q.To.IsZero() {
+		conditions = append(conditions, "t.date < ?")
+		args = append(args, q.To.Unix())
+	}
+	switch {
+	case q.Currency != nil && balances:
+		conditions = append(conditions, "b.currency = ?")
+		args = append(args, q.Currency.Machine())
+	case q.Currency != nil && q.Account != nil:
+		conditions = append(conditions, "t.hash IN (SELECT hash FROM balance_changes WHERE currency = ? AND account = ?)")
+		args = append(args, q.Currency.Machine(), q.Account.String())
+	case q.Currency != nil:
+		conditions = append(conditions, "t.hash IN (SELECT hash FROM balance_changes WHERE currency = ?)")
+		args = append(args, q.Currency.Machine())
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// order returns the ORDER BY and LIMIT of q
+func (q *Query) order() (string, []interface{}) {
+	order := " ORDER BY t.ledger_index, t.tx_index"
+	if q.Newest {
+		order = " ORDER BY t.ledger_index DESC, t.tx_index DESC"
+	}
+	if q.Limit > 0 {
+		return order + " LIMIT ?", []interface{}{q.Limit}
+	}
+	return order, nil
+}
+
+func (s *sqlStorage) PutAccountState(ctx context.Context, ledger uint32, entries ...data.LedgerEntry) error {
+	var rows [][]interface{}
+	for _, entry := range entries {
+		_, raw, err := data.Raw(entry)
+		if err != nil {
+			return fmt.Errorf("Encoding %s: %s", entry.GetLedgerEntryType(), err)
+		}
+		// The index is the last 32 bytes
+		index := data.Hash256{}
+		copy(index[:], raw[len(raw)-len(index):])
+		rows = append(rows, []interface{}{index.String(), ledger, entry.GetLedgerEntryType().String(), raw})
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.insert(ctx, tx, "account_state", stateColumns, rows)
+	})
+}
+
+// A deleted entry is stored with no type or entry
+func (s *sqlStorage) DeleteAccountState(ctx context.Context, ledger uint32, indexes ...data.Hash256) error {
+	var rows [][]interface{}
+	for _, index := range indexes {
+		rows = append(rows, []interface{}{index.String(), ledger, "", nil})
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return s.insert(ctx, tx, "account_state", stateColumns, rows)
+	})
+}
+
+func (s *sqlStorage) GetAccountState(ctx context.Context, index data.Hash256, ledger uint32) (data.LedgerEntry, error) {
+	var entry []byte
+	err := s.db.QueryRowContext(ctx, s.rebind(`SELECT entry FROM account_state
+		WHERE entry_index = ? AND ledger_index <= ?
+		ORDER BY ledger_index DESC LIMIT 1`), index.String(), ledger).Scan(&entry)
+	switch {
+	case err == sql.ErrNoRows, err == nil && entry == nil:
+		return nil, ErrNotFound
+	case err != nil:
+		return nil, err
+	}
+	return data.ReadLedgerEntry(bytes.NewReader(entry), index)
+}
+
+func (s *sqlStorage) PutBackfillPage(ctx context.Context, account data.Account, page *websockets.AccountTxResult) error {
+	records, err := newRecords(page.Transactions, account)
+	if err != nil {
+		return err
+	}
+	var (
+		next     interface{}
+		complete = 1
+	)
+	if page.Marker != nil {
+		b, err := json.Marshal(page.Marker)
+		if err != nil {
+			return err
+		}
+		next, complete = string(b), 0
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := s.putRecords(ctx, tx, records); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO backfills (account, marker, complete)
+			VALUES (?, ?, ?)
+			ON CONFLICT (account) DO UPDATE SET marker = excluded.marker, complete = excluded.complete`),
+			account.String(), next, complete)
+		return err
+	})
+}
+
+func (s *sqlStorage) BackfillMarker(ctx context.Context, account data.Account) (map[string]interface{}, bool, error) {
+	var (
+		marker   sql.NullString
+		complete int
+	)
+	err := s.db.QueryRowContext(ctx, s.rebind(`SELECT marker, complete FROM backfills WHERE account = ?`),
+		account.String()).Scan(&marker, &complete)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	case !marker.Valid:
+		return nil, complete != 0, nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(marker.String), &m); err != nil {
+		return nil, false, err
+	}
+	return m, complete != 0, nil
+}
+
+func (s *sqlStorage) ResetBackfill(ctx context.Context, account data.Account) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM backfills WHERE account = ?`), account.String())
+	return err
+}
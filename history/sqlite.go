@@ -0,0 +1,95 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+
+	_ "modernc.org/sqlite"
+)
+
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS ledgers (
+		ledger_index INTEGER PRIMARY KEY,
+		hash         TEXT NOT NULL,
+		close_time   INTEGER NOT NULL, -- Unix seconds
+		header       BLOB NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS transactions (
+		hash         TEXT PRIMARY KEY,
+		ledger_index INTEGER NOT NULL,
+		tx_index     INTEGER NOT NULL,
+		date         INTEGER, -- Unix seconds, when known
+		type         TEXT NOT NULL,
+		account      TEXT NOT NULL,
+		result       TEXT NOT NULL,
+		tx           BLOB NOT NULL,
+		meta         BLOB NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS transactions_order ON transactions (ledger_index, tx_index)`,
+	`CREATE INDEX IF NOT EXISTS transactions_date ON transactions (date)`,
+	`CREATE TABLE IF NOT EXISTS affected_accounts (
+		account TEXT NOT NULL,
+		hash    TEXT NOT NULL REFERENCES transactions (hash),
+		PRIMARY KEY (account, hash)
+	)`,
+	`CREATE TABLE IF NOT EXISTS balance_changes (
+		hash         TEXT NOT NULL REFERENCES transactions (hash),
+		account      TEXT NOT NULL,
+		counterparty TEXT NOT NULL, -- Empty for XRP
+		currency     TEXT NOT NULL,
+		change       TEXT NOT NULL, -- Amounts, as value/currency/issuer
+		balance      TEXT NOT NULL,
+		PRIMARY KEY (hash, account, counterparty, currency)
+	)`,
+	`CREATE INDEX IF NOT EXISTS balance_changes_currency ON balance_changes (currency, account)`,
+	`CREATE TABLE IF NOT EXISTS backfills (
+		account  TEXT PRIMARY KEY,
+		marker   TEXT, -- JSON, of the next page
+		complete INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS account_state (
+		entry_index  TEXT NOT NULL,
+		ledger_index INTEGER NOT NULL,
+		type         TEXT NOT NULL, -- Empty when deleted
+		entry        BLOB, -- With its index, NULL when deleted
+		PRIMARY KEY (entry_index, ledger_index)
+	)`,
+}
+
+// SQLite has ? placeholders already
+func sqliteRebind(query string) string { return query }
+
+var sqlite = dialect{
+	schema: sqliteSchema,
+	rebind: sqliteRebind,
+	insert: func(ctx context.Context, tx *sql.Tx, table string, columns []string, rows [][]interface{}) error {
+		return insertRows(ctx, tx, sqliteRebind, table, columns, rows)
+	},
+}
+
+// OpenSQLite opens, or creates, a Store in the SQLite database at path
+func OpenSQLite(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite has a single writer, and each connection to ":memory:" would
+	// have its own database
+	db.SetMaxOpenConns(1)
+	store, err := NewSQLite(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// NewSQLite returns a Store in db, which was opened with an SQLite driver,
+// creating its tables unless they exist
+func NewSQLite(db *sql.DB) (*Store, error) {
+	storage, err := newSQLStorage(db, sqlite)
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(storage), nil
+}
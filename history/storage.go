@@ -0,0 +1,49 @@
+package history
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// ErrNotFound is returned for a ledger, transaction or ledger entry which
+// isn't stored
+var ErrNotFound = errors.New("Not found in history")
+
+// Storage is where a Store keeps ledgers, transactions and account state.
+// Putting what is stored already does nothing. NewSQLite and NewPostgres
+// return Stores with Storages in databases, and others may be given to
+// NewStore.
+type Storage interface {
+	// PutLedgers stores the headers of validated ledgers
+	PutLedgers(ctx context.Context, ledgers ...*data.Ledger) error
+	GetLedger(ctx context.Context, sequence uint32) (*data.Ledger, error)
+
+	// PutTransactions stores validated transactions, with the accounts
+	// they affected and the balances they changed
+	PutTransactions(ctx context.Context, txs ...*data.TransactionWithMetaData) error
+	GetTransaction(ctx context.Context, hash data.Hash256) (*data.TransactionWithMetaData, error)
+	Transactions(ctx context.Context, q Query) ([]*data.TransactionWithMetaData, error)
+	BalanceChanges(ctx context.Context, q Query) ([]BalanceChange, error)
+
+	// PutAccountState stores ledger entries as they were after ledger,
+	// and DeleteAccountState records that they were deleted by it
+	PutAccountState(ctx context.Context, ledger uint32, entries ...data.LedgerEntry) error
+	DeleteAccountState(ctx context.Context, ledger uint32, indexes ...data.Hash256) error
+	// GetAccountState returns the ledger entry with index as it was
+	// after ledger
+	GetAccountState(ctx context.Context, index data.Hash256, ledger uint32) (data.LedgerEntry, error)
+
+	// PutBackfillPage stores the transactions of a page of account_tx for
+	// account and the marker of the next page, all or nothing
+	PutBackfillPage(ctx context.Context, account data.Account, page *websockets.AccountTxResult) error
+	// BackfillMarker returns the marker of the next page of account to
+	// store, and whether the last has been
+	BackfillMarker(ctx context.Context, account data.Account) (map[string]interface{}, bool, error)
+	// ResetBackfill forgets where the backfill of account got to
+	ResetBackfill(ctx context.Context, account data.Account) error
+
+	Close() error
+}
@@ -0,0 +1,111 @@
+// Package history keeps the transactions of accounts in a database, so
+// that they can be queried without asking a server. Transactions are
+// ingested from account_tx, resumably, or from the transactions stream,
+// and stored in binary with the accounts they affected and the balances
+// they changed. Ledger headers and the state of accounts may be stored
+// too.
+//
+//	store, err := history.OpenSQLite("history.db")
+//	...
+//	err = store.Backfill(ctx, remote, account, websockets.AccountTxOptions{MinLedger: -1, MaxLedger: -1})
+//	...
+//	txs, err := store.Transactions(ctx, history.Query{Account: &account, From: lastWeek})
+//
+// Storing a transaction which is already stored does nothing, so pages
+// and stream messages may overlap. A Store keeps everything in a Storage,
+// of which there are SQLite and PostgreSQL ones, the latter for indexing
+// full history at scale.
+package history
+
+import (
+	"context"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// DefaultBatchSize is the most transactions Ingest stores at once
+const DefaultBatchSize = 500
+
+// Store is a history of transactions in a Storage. It may be used by
+// several goroutines at once.
+type Store struct {
+	Storage
+	BatchSize int // Of Ingest, DefaultBatchSize when zero
+}
+
+// NewStore returns a Store in storage
+func NewStore(storage Storage) *Store {
+	return &Store{Storage: storage}
+}
+
+// Ingest stores the transactions received from txs, such as the
+// channel of Remote.AccountTx, until it is closed or ctx is done. Those
+// waiting are stored together, up to BatchSize at once.
+func (s *Store) Ingest(ctx context.Context, txs <-chan *data.TransactionWithMetaData) error {
+	for {
+		select {
+		case txm, ok := <-txs:
+			if !ok {
+				return nil
+			}
+			batch, open := s.batch(txm, txs)
+			if err := s.PutTransactions(ctx, batch...); err != nil {
+				return err
+			}
+			if !open {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// batch returns first and whichever transactions are waiting in txs, and
+// whether txs is still open
+func (s *Store) batch(first *data.TransactionWithMetaData, txs <-chan *data.TransactionWithMetaData) ([]*data.TransactionWithMetaData, bool) {
+	size := s.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	batch := []*data.TransactionWithMetaData{first}
+	for len(batch) < size {
+		select {
+		case txm, ok := <-txs:
+			if !ok {
+				return batch, false
+			}
+			batch = append(batch, txm)
+		default:
+			return batch, true
+		}
+	}
+	return batch, true
+}
+
+// IngestStream stores the validated transactions of a transactions
+// stream, such as that of a Subscription, until it is closed or ctx is
+// done. Proposed transactions are skipped.
+func (s *Store) IngestStream(ctx context.Context, msgs <-chan *websockets.TransactionStreamMsg) error {
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return nil
+			}
+			if !msg.Validated {
+				continue
+			}
+			txm := msg.Transaction
+			if txm.LedgerSequence == 0 {
+				txm.LedgerSequence = msg.LedgerSequence
+			}
+			if err := s.PutTransactions(ctx, &txm); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
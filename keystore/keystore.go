@@ -0,0 +1,177 @@
+// Package keystore keeps the secrets of accounts encrypted on disk, in JSON
+// files similar to those of go-ethereum. A secret is encrypted with
+// AES-256-GCM under a key derived from a passphrase by scrypt or argon2id.
+// Signing needs the account to be unlocked, and the secret never leaves
+// the keystore, which hands out a sign.Signer instead.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/sign"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// The version of the file format
+const Version = 1
+
+// KDFParams are the parameters of a KDF, those of the other KDF being zero
+type KDFParams struct {
+	Salt  string `json:"salt"`
+	DKLen int    `json:"dklen"`
+	// scrypt
+	N int `json:"n,omitempty"`
+	R int `json:"r,omitempty"`
+	P int `json:"p,omitempty"`
+	// argon2id, with Memory in KiB
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+}
+
+// KDF derives the encryption key from a passphrase. A new salt is made
+// for every file.
+type KDF struct {
+	Name   string
+	Params KDFParams
+}
+
+var (
+	// StandardScrypt takes about a second and 256MB to unlock an account
+	StandardScrypt = KDF{"scrypt", KDFParams{DKLen: 32, N: 1 << 18, R: 8, P: 1}}
+	// LightScrypt is for machines with little memory
+	LightScrypt = KDF{"scrypt", KDFParams{DKLen: 32, N: 1 << 12, R: 8, P: 6}}
+	// Argon2id is as RFC 9106 recommends when memory is constrained
+	Argon2id = KDF{"argon2id", KDFParams{DKLen: 32, Time: 3, Memory: 64 * 1024, Threads: 4}}
+)
+
+// Crypto is the encrypted secret
+type Crypto struct {
+	Cipher     string    `json:"cipher"`
+	CipherText string    `json:"ciphertext"`
+	Nonce      string    `json:"nonce"`
+	KDF        string    `json:"kdf"`
+	KDFParams  KDFParams `json:"kdfparams"`
+}
+
+// File is the JSON keystore file of an account. The account and public
+// key are in the clear, so that a locked account can be listed.
+type File struct {
+	Version   int            `json:"version"`
+	Account   data.Account   `json:"account"`
+	PublicKey data.PublicKey `json:"public_key"`
+	Crypto    Crypto         `json:"crypto"`
+}
+
+// Encrypt encrypts secret, an "s..." or "sEd..." family seed, with a key
+// derived from passphrase by kdf
+func Encrypt(secret, passphrase string, kdf KDF) (*File, error) {
+	key, err := sign.NewKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	params := kdf.Params
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params.Salt = hex.EncodeToString(salt)
+	f := &File{
+		Version:   Version,
+		Account:   key.Account(),
+		PublicKey: key.PublicKey(),
+		Crypto:    Crypto{Cipher: "aes-256-gcm", KDF: kdf.Name, KDFParams: params},
+	}
+	aead, err := f.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	f.Crypto.Nonce = hex.EncodeToString(nonce)
+	f.Crypto.CipherText = hex.EncodeToString(aead.Seal(nil, nonce, []byte(secret), f.Account[:]))
+	return f, nil
+}
+
+// Decrypt returns the key of the account
+func (f *File) Decrypt(passphrase string) (*sign.Key, error) {
+	if f.Version != Version {
+		return nil, fmt.Errorf("Unsupported keystore version: %d", f.Version)
+	}
+	if f.Crypto.Cipher != "aes-256-gcm" {
+		return nil, fmt.Errorf("Unsupported cipher: %s", f.Crypto.Cipher)
+	}
+	aead, err := f.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(f.Crypto.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("Bad nonce: %s", f.Crypto.Nonce)
+	}
+	ciphertext, err := hex.DecodeString(f.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("Bad ciphertext: %s", err)
+	}
+	// The account is authenticated too, so a file can't be relabelled
+	secret, err := aead.Open(nil, nonce, ciphertext, f.Account[:])
+	if err != nil {
+		return nil, fmt.Errorf("Could not decrypt %s: wrong passphrase", f.Account)
+	}
+	key, err := sign.NewKey(string(secret))
+	if err != nil {
+		return nil, err
+	}
+	if key.Account() != f.Account || key.PublicKey() != f.PublicKey {
+		return nil, fmt.Errorf("Keystore file does not match its key: %s", f.Account)
+	}
+	return key, nil
+}
+
+func (f *File) aead(passphrase string) (cipher.AEAD, error) {
+	p := f.Crypto.KDFParams
+	salt, err := hex.DecodeString(p.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("Bad salt: %s", p.Salt)
+	}
+	if p.DKLen != 32 {
+		return nil, fmt.Errorf("Derived key must be 32 bytes for aes-256-gcm, not %d", p.DKLen)
+	}
+	var key []byte
+	switch f.Crypto.KDF {
+	case "scrypt":
+		if key, err = scrypt.Key([]byte(passphrase), salt, p.N, p.R, p.P, p.DKLen); err != nil {
+			return nil, err
+		}
+	case "argon2id":
+		if p.Time == 0 || p.Memory == 0 || p.Threads == 0 {
+			return nil, fmt.Errorf("Bad argon2id parameters")
+		}
+		key = argon2.IDKey([]byte(passphrase), salt, p.Time, p.Memory, p.Threads, uint32(p.DKLen))
+	default:
+		return nil, fmt.Errorf("Unsupported KDF: %s", f.Crypto.KDF)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ReadFile parses a keystore file
+func ReadFile(b []byte) (*File, error) {
+	var f File
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
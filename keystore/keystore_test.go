@@ -0,0 +1,106 @@
+package keystore
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/sign"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type KeystoreSuite struct{}
+
+var _ = Suite(&KeystoreSuite{})
+
+// Weak parameters keep the tests fast
+var (
+	testScrypt   = KDF{"scrypt", KDFParams{DKLen: 32, N: 1 << 10, R: 8, P: 1}}
+	testArgon2id = KDF{"argon2id", KDFParams{DKLen: 32, Time: 1, Memory: 1024, Threads: 1}}
+)
+
+var secrets = []string{"snoPBrXtMeMyMHUVTgbuqAfg1SUTb", "sEdVQ4wvD1AaTG6JA54qt38TengAuiz"}
+
+func (s *KeystoreSuite) TestEncrypt(c *C) {
+	for _, kdf := range []KDF{testScrypt, testArgon2id} {
+		for _, secret := range secrets {
+			key, err := sign.NewKey(secret)
+			c.Assert(err, IsNil)
+			f, err := Encrypt(secret, "correct horse", kdf)
+			c.Assert(err, IsNil)
+			c.Check(f.Account, Equals, key.Account())
+
+			b, err := json.Marshal(f)
+			c.Assert(err, IsNil)
+			c.Check(string(b), Not(Matches), ".*"+secret+".*")
+			f, err = ReadFile(b)
+			c.Assert(err, IsNil)
+			decrypted, err := f.Decrypt("correct horse")
+			c.Assert(err, IsNil)
+			c.Check(decrypted.PublicKey(), Equals, key.PublicKey())
+
+			_, err = f.Decrypt("wrong horse")
+			c.Check(err, ErrorMatches, "Could not decrypt .*: wrong passphrase")
+			f.Account[0]++
+			_, err = f.Decrypt("correct horse")
+			c.Check(err, ErrorMatches, "Could not decrypt .*: wrong passphrase")
+		}
+	}
+	_, err := Encrypt("rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh", "correct horse", testScrypt)
+	c.Check(err, ErrorMatches, "Not a family seed: .*")
+}
+
+func (s *KeystoreSuite) TestStore(c *C) {
+	dir := c.MkDir() + "/keys"
+	store := NewStore(dir)
+	store.KDF = testScrypt
+	accounts, err := store.Accounts()
+	c.Assert(err, IsNil)
+	c.Check(accounts, HasLen, 0)
+
+	account, err := store.Import(secrets[0], "correct horse")
+	c.Assert(err, IsNil)
+	_, err = store.Import(secrets[0], "correct horse")
+	c.Check(err, ErrorMatches, "Account .* is already in the keystore")
+	info, err := os.Stat(store.path(account))
+	c.Assert(err, IsNil)
+	c.Check(info.Mode().Perm(), Equals, os.FileMode(0600))
+	accounts, err = store.Accounts()
+	c.Assert(err, IsNil)
+	c.Check(accounts, DeepEquals, []data.Account{account})
+
+	// The signer signs only while the account is unlocked
+	signer, err := store.Signer(account)
+	c.Assert(err, IsNil)
+	amount, err := data.NewAmount("1000000")
+	c.Assert(err, IsNil)
+	tx := data.TxFactory[data.PAYMENT]().(*data.Payment)
+	tx.Account = account
+	tx.Destination = account
+	tx.Destination[0]++
+	tx.Amount = *amount
+	_, err = sign.Transaction(tx, signer)
+	c.Check(err, ErrorMatches, "Account .* is locked")
+
+	c.Check(store.Unlock(account, "wrong horse", 0), ErrorMatches, ".*wrong passphrase")
+	c.Assert(store.Unlock(account, "correct horse", 0), IsNil)
+	c.Check(store.Unlocked(account), Equals, true)
+	_, err = sign.Transaction(tx, signer)
+	c.Check(err, IsNil)
+	store.Lock(account)
+	c.Check(store.Unlocked(account), Equals, false)
+
+	c.Assert(store.Unlock(account, "correct horse", 10*time.Millisecond), IsNil)
+	c.Check(store.Unlocked(account), Equals, true)
+	time.Sleep(50 * time.Millisecond)
+	c.Check(store.Unlocked(account), Equals, false)
+
+	other := account
+	other[0]++
+	_, err = store.Signer(other)
+	c.Check(err, ErrorMatches, "Account .* is not in the keystore")
+}
@@ -0,0 +1,209 @@
+package keystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/sign"
+)
+
+// Store is a directory of keystore files, one per account, named after
+// the account
+type Store struct {
+	// KDF encrypts imported secrets
+	KDF KDF
+
+	dir      string
+	mu       sync.Mutex
+	unlocked map[data.Account]*unlocked
+}
+
+type unlocked struct {
+	key   *sign.Key
+	timer *time.Timer
+}
+
+// NewStore returns the store in dir, which is created when the first
+// secret is imported
+func NewStore(dir string) *Store {
+	return &Store{
+		KDF:      StandardScrypt,
+		dir:      dir,
+		unlocked: make(map[data.Account]*unlocked),
+	}
+}
+
+func (s *Store) path(account data.Account) string {
+	return filepath.Join(s.dir, account.String()+".json")
+}
+
+// Import encrypts secret with passphrase and writes it to the store
+func (s *Store) Import(secret, passphrase string) (data.Account, error) {
+	var account data.Account
+	f, err := Encrypt(secret, passphrase, s.KDF)
+	if err != nil {
+		return account, err
+	}
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return account, err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return account, err
+	}
+	path := s.path(f.Account)
+	if _, err := os.Stat(path); err == nil {
+		return account, fmt.Errorf("Account %s is already in the keystore", f.Account)
+	}
+	// Write then rename, so a file is never seen half written
+	tmp, err := os.CreateTemp(s.dir, ".import-*")
+	if err != nil {
+		return account, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return account, err
+	}
+	if err := tmp.Close(); err != nil {
+		return account, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return account, err
+	}
+	return f.Account, nil
+}
+
+// Accounts returns the accounts in the store, sorted by address
+func (s *Store) Accounts() ([]data.Account, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var accounts []data.Account
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		account, err := data.NewAccountFromAddress(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		accounts = append(accounts, *account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].String() < accounts[j].String()
+	})
+	return accounts, nil
+}
+
+// File reads the keystore file of account
+func (s *Store) File(account data.Account) (*File, error) {
+	b, err := os.ReadFile(s.path(account))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("Account %s is not in the keystore", account)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := ReadFile(b)
+	if err != nil {
+		return nil, err
+	}
+	if f.Account != account {
+		return nil, fmt.Errorf("Keystore file of %s is for %s", account, f.Account)
+	}
+	return f, nil
+}
+
+// Unlock decrypts the key of account for signing, until Lock is called or,
+// if timeout is not zero, until it expires
+func (s *Store) Unlock(account data.Account, passphrase string, timeout time.Duration) error {
+	f, err := s.File(account)
+	if err != nil {
+		return err
+	}
+	key, err := f.Decrypt(passphrase)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lock(account)
+	u := &unlocked{key: key}
+	if timeout > 0 {
+		u.timer = time.AfterFunc(timeout, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.unlocked[account] == u {
+				delete(s.unlocked, account)
+			}
+		})
+	}
+	s.unlocked[account] = u
+	return nil
+}
+
+// Lock forgets the key of account
+func (s *Store) Lock(account data.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lock(account)
+}
+
+func (s *Store) lock(account data.Account) {
+	if u, ok := s.unlocked[account]; ok {
+		if u.timer != nil {
+			u.timer.Stop()
+		}
+		delete(s.unlocked, account)
+	}
+}
+
+// Unlocked reports whether account can sign
+func (s *Store) Unlocked(account data.Account) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.unlocked[account]
+	return ok
+}
+
+// Signer returns the signer of account, which signs only while the
+// account is unlocked
+func (s *Store) Signer(account data.Account) (sign.Signer, error) {
+	f, err := s.File(account)
+	if err != nil {
+		return nil, err
+	}
+	return &signer{store: s, account: account, public: f.PublicKey}, nil
+}
+
+type signer struct {
+	store   *Store
+	account data.Account
+	public  data.PublicKey
+}
+
+func (s *signer) Account() data.Account     { return s.account }
+func (s *signer) PublicKey() data.PublicKey { return s.public }
+
+func (s *signer) Sign(hash, msg []byte) ([]byte, error) {
+	s.store.mu.Lock()
+	u, ok := s.store.unlocked[s.account]
+	s.store.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("Account %s is locked", s.account)
+	}
+	return u.key.Sign(hash, msg)
+}
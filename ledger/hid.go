@@ -0,0 +1,77 @@
+package ledger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Each APDU is split into 64 byte HID reports, with a header of the
+// channel, tag and sequence number, and the first also has the length
+const (
+	hidChannel    = 0x0101
+	hidTag        = 0x05
+	hidReportSize = 64
+	ledgerVendor  = 0x2C97
+)
+
+// Device is a Ledger connected by USB HID, and implements Transport
+type Device struct {
+	rw io.ReadWriteCloser
+	// Written before each report, as hidraw expects a report number
+	prefix []byte
+}
+
+// hidReports splits data into reports, each preceded by prefix
+func hidReports(prefix, data []byte) [][]byte {
+	var reports [][]byte
+	data = append(binary.BigEndian.AppendUint16(nil, uint16(len(data))), data...)
+	for seq := uint16(0); len(data) > 0; seq++ {
+		report := append([]byte(nil), prefix...)
+		report = binary.BigEndian.AppendUint16(report, hidChannel)
+		report = append(report, hidTag)
+		report = binary.BigEndian.AppendUint16(report, seq)
+		n := len(prefix) + hidReportSize - len(report)
+		if n > len(data) {
+			n = len(data)
+		}
+		report = append(report, data[:n]...)
+		data = data[n:]
+		reports = append(reports, append(report, make([]byte, len(prefix)+hidReportSize-len(report))...))
+	}
+	return reports
+}
+
+// Exchange writes command as reports, then reads the response
+func (d *Device) Exchange(command []byte) ([]byte, error) {
+	for _, report := range hidReports(d.prefix, command) {
+		if _, err := d.rw.Write(report); err != nil {
+			return nil, err
+		}
+	}
+	var response []byte
+	length := -1
+	for seq := uint16(0); length < 0 || len(response) < length; seq++ {
+		report := make([]byte, hidReportSize)
+		if _, err := io.ReadFull(d.rw, report); err != nil {
+			return nil, err
+		}
+		if binary.BigEndian.Uint16(report) != hidChannel || report[2] != hidTag {
+			return nil, fmt.Errorf("Unexpected HID report: %X", report[:5])
+		}
+		if binary.BigEndian.Uint16(report[3:]) != seq {
+			return nil, fmt.Errorf("HID report %d out of sequence", seq)
+		}
+		report = report[5:]
+		if seq == 0 {
+			length, report = int(binary.BigEndian.Uint16(report)), report[2:]
+		}
+		response = append(response, report...)
+	}
+	return response[:length], nil
+}
+
+// Close closes the device
+func (d *Device) Close() error {
+	return d.rw.Close()
+}
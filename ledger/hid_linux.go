@@ -0,0 +1,35 @@
+package ledger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Open opens the first Ledger found through hidraw, which needs a udev
+// rule giving the user access to the device
+func Open() (*Device, error) {
+	uevents, err := filepath.Glob("/sys/class/hidraw/*/device/uevent")
+	if err != nil {
+		return nil, err
+	}
+	for _, uevent := range uevents {
+		b, err := os.ReadFile(uevent)
+		if err != nil {
+			continue
+		}
+		// The APDU interface is the first of the device
+		id := fmt.Sprintf("HID_ID=0003:%08X:", ledgerVendor)
+		if !strings.Contains(string(b), id) || !strings.Contains(string(b), "/input0\n") {
+			continue
+		}
+		name := filepath.Base(filepath.Dir(filepath.Dir(uevent)))
+		f, err := os.OpenFile("/dev/"+name, os.O_RDWR, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &Device{rw: f, prefix: []byte{0}}, nil
+	}
+	return nil, fmt.Errorf("No Ledger found")
+}
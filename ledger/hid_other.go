@@ -0,0 +1,10 @@
+//go:build !linux
+
+package ledger
+
+import "fmt"
+
+// Open is only supported on Linux, elsewhere a Transport must be provided
+func Open() (*Device, error) {
+	return nil, fmt.Errorf("Opening a Ledger is only supported on Linux")
+}
@@ -0,0 +1,176 @@
+// Package ledger signs transactions with a Ledger hardware wallet running
+// the XRP app. The keys never leave the device, which shows each
+// transaction for approval before signing it.
+package ledger
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/kr-jaydeepp/ripple/crypto"
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// Transport exchanges an APDU command for the device's response, which
+// ends with a status word
+type Transport interface {
+	Exchange(command []byte) ([]byte, error)
+}
+
+// The APDUs of the XRP app
+const (
+	claXRP          = 0xE0
+	insGetPublicKey = 0x02
+	insSign         = 0x04
+	p1First         = 0x00
+	p1More          = 0x80
+	p1Display       = 0x01
+	p2Secp256k1     = 0x40
+	p2Ed25519       = 0x80
+	maxPayload      = 255
+	maxSignChunk    = 150
+	maxPathLength   = 10
+)
+
+var statusText = map[uint16]string{
+	0x6700: "Wrong length",
+	0x6982: "Device is locked",
+	0x6985: "Rejected by user",
+	0x6A80: "Invalid data",
+	0x6B00: "Invalid parameter",
+	0x6D00: "Instruction not supported, is the XRP app open?",
+	0x6E00: "Class not supported, is the XRP app open?",
+}
+
+func exchange(t Transport, ins, p1, p2 byte, payload []byte) ([]byte, error) {
+	if len(payload) > maxPayload {
+		return nil, fmt.Errorf("APDU payload too long: %d", len(payload))
+	}
+	command := append([]byte{claXRP, ins, p1, p2, byte(len(payload))}, payload...)
+	response, err := t.Exchange(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(response) < 2 {
+		return nil, fmt.Errorf("Short response from Ledger: %X", response)
+	}
+	n := len(response) - 2
+	if status := binary.BigEndian.Uint16(response[n:]); status != 0x9000 {
+		if text, ok := statusText[status]; ok {
+			return nil, fmt.Errorf("Ledger error %04X: %s", status, text)
+		}
+		return nil, fmt.Errorf("Ledger error %04X", status)
+	}
+	return response[:n], nil
+}
+
+// Signer signs with the key at a path of the device, and implements
+// sign.Signer
+type Signer struct {
+	Type data.KeyType
+
+	transport Transport
+	path      []byte
+	curve     byte
+	public    data.PublicKey
+	account   data.Account
+}
+
+// NewSigner fetches the public key at path, such as crypto.DefaultHDPath,
+// from the device
+func NewSigner(t Transport, path string, keyType data.KeyType) (*Signer, error) {
+	indexes, err := crypto.ParseHDPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(indexes) > maxPathLength {
+		return nil, fmt.Errorf("Path is too long: %s", path)
+	}
+	s := &Signer{Type: keyType, transport: t, path: []byte{byte(len(indexes))}}
+	for _, index := range indexes {
+		s.path = binary.BigEndian.AppendUint32(s.path, index)
+	}
+	switch keyType {
+	case data.ECDSA:
+		s.curve = p2Secp256k1
+	case data.Ed25519:
+		s.curve = p2Ed25519
+	default:
+		return nil, fmt.Errorf("Unknown key type: %s", keyType)
+	}
+	if err := s.getPublicKey(p1First); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// getPublicKey parses a response of the public key, then the address,
+// each preceded by its length
+func (s *Signer) getPublicKey(p1 byte) error {
+	response, err := exchange(s.transport, insGetPublicKey, p1, s.curve, s.path)
+	if err != nil {
+		return err
+	}
+	if len(response) < 1 || len(response) < 1+int(response[0]) {
+		return fmt.Errorf("Bad public key response: %X", response)
+	}
+	public := response[1 : 1+response[0]]
+	switch {
+	case len(public) == btcec.PubKeyBytesLenUncompressed && s.Type == data.ECDSA:
+		key, err := btcec.ParsePubKey(public, btcec.S256())
+		if err != nil {
+			return err
+		}
+		public = key.SerializeCompressed()
+	case len(public) == 32 && s.Type == data.Ed25519:
+		public = append([]byte{0xED}, public...)
+	case len(public) != len(s.public):
+		return fmt.Errorf("Bad public key: %X", public)
+	}
+	copy(s.public[:], public)
+	copy(s.account[:], crypto.Sha256RipeMD160(public))
+	if rest := response[1+int(response[0]):]; len(rest) > 0 && len(rest) >= 1+int(rest[0]) {
+		if address := string(rest[1 : 1+rest[0]]); address != s.account.String() {
+			return fmt.Errorf("Ledger address %s does not match its public key", address)
+		}
+	}
+	return nil
+}
+
+// ShowAddress asks the device to display the address, so the user can
+// check it against the one shown by the application
+func (s *Signer) ShowAddress() error {
+	return s.getPublicKey(p1Display)
+}
+
+// Account returns the account whose master key this is
+func (s *Signer) Account() data.Account { return s.account }
+
+// PublicKey returns what goes in the SigningPubKey of a transaction
+func (s *Signer) PublicKey() data.PublicKey { return s.public }
+
+// Sign sends msg to the device in chunks of at most 150 bytes, the first
+// preceded by the path, and returns the signature in the response to the
+// last, once the user approves it. The device
+// computes the hash itself.
+func (s *Signer) Sign(hash, msg []byte) ([]byte, error) {
+	p1 := byte(p1First)
+	payload := append([]byte(nil), s.path...)
+	for {
+		n := maxSignChunk - len(payload)
+		if n > len(msg) {
+			n = len(msg)
+		}
+		payload = append(payload, msg[:n]...)
+		msg = msg[n:]
+		response, err := exchange(s.transport, insSign, p1, s.curve, payload)
+		if err != nil {
+			return nil, err
+		}
+		if len(msg) == 0 {
+			return response, nil
+		}
+		p1, payload = p1More, nil
+	}
+}
@@ -0,0 +1,155 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/kr-jaydeepp/ripple/crypto"
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/sign"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type LedgerSuite struct{}
+
+var _ = Suite(&LedgerSuite{})
+
+const mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// app emulates the XRP app with a key derived in software
+type app struct {
+	c      *C
+	key    crypto.Key
+	curve  byte
+	reject bool
+	msg    []byte
+	chunks int
+}
+
+func (a *app) Exchange(command []byte) ([]byte, error) {
+	c := a.c
+	c.Assert(command[0], Equals, byte(claXRP))
+	c.Assert(int(command[4]), Equals, len(command)-5)
+	c.Check(command[3], Equals, a.curve)
+	payload := command[5:]
+	switch command[1] {
+	case insGetPublicKey:
+		var public []byte
+		if a.curve == p2Secp256k1 {
+			key, err := btcec.ParsePubKey(a.key.Public(nil), btcec.S256())
+			c.Assert(err, IsNil)
+			public = key.SerializeUncompressed()
+		} else {
+			public = a.key.Public(nil)[1:]
+		}
+		address, err := crypto.AccountId(a.key, nil)
+		c.Assert(err, IsNil)
+		response := append([]byte{byte(len(public))}, public...)
+		response = append(response, byte(len(address.String())))
+		response = append(response, address.String()...)
+		return append(response, 0x90, 0x00), nil
+	case insSign:
+		if a.reject {
+			return []byte{0x69, 0x85}, nil
+		}
+		if command[2] == p1First {
+			a.msg = append([]byte(nil), payload[1+4*payload[0]:]...)
+		} else {
+			c.Check(command[2], Equals, byte(p1More))
+			a.msg = append(a.msg, payload...)
+		}
+		a.chunks++
+		hash := crypto.Sha512Half(a.msg)
+		sig, err := crypto.Sign(a.key.Private(nil), hash, a.msg)
+		c.Assert(err, IsNil)
+		return append(sig, 0x90, 0x00), nil
+	}
+	return []byte{0x6D, 0x00}, nil
+}
+
+// hidraw passes the reports of commands to an app, and returns the reports
+// of its responses
+type hidraw struct {
+	c       *C
+	app     Transport
+	command []byte
+	length  int
+	reports bytes.Buffer
+}
+
+func (h *hidraw) Write(report []byte) (int, error) {
+	c := h.c
+	c.Assert(report, HasLen, 1+hidReportSize)
+	c.Assert(report[0], Equals, byte(0))
+	report = report[1:]
+	c.Check(binary.BigEndian.Uint16(report), Equals, uint16(hidChannel))
+	if binary.BigEndian.Uint16(report[3:]) == 0 {
+		h.length, h.command = int(binary.BigEndian.Uint16(report[5:])), append([]byte(nil), report[7:]...)
+	} else {
+		h.command = append(h.command, report[5:]...)
+	}
+	if len(h.command) >= h.length {
+		response, err := h.app.Exchange(h.command[:h.length])
+		c.Assert(err, IsNil)
+		for _, report := range hidReports(nil, response) {
+			h.reports.Write(report)
+		}
+	}
+	return len(report) + 1, nil
+}
+
+func (h *hidraw) Read(b []byte) (int, error) { return h.reports.Read(b) }
+func (h *hidraw) Close() error               { return nil }
+
+func (s *LedgerSuite) TestSigner(c *C) {
+	seed := crypto.MnemonicSeed(mnemonic, "")
+	ecdsa, err := crypto.DeriveECDSAKey(seed, crypto.DefaultHDPath)
+	c.Assert(err, IsNil)
+	ed25519, err := crypto.DeriveEd25519Key(seed, "m/44'/144'/0'/0'/0'")
+	c.Assert(err, IsNil)
+	tests := []struct {
+		key     crypto.Key
+		keyType data.KeyType
+		curve   byte
+		path    string
+	}{
+		{ecdsa, data.ECDSA, p2Secp256k1, crypto.DefaultHDPath},
+		{ed25519, data.Ed25519, p2Ed25519, "m/44'/144'/0'/0'/0'"},
+	}
+	for _, test := range tests {
+		a := &app{c: c, key: test.key, curve: test.curve}
+		device := &Device{rw: &hidraw{c: c, app: a}, prefix: []byte{0}}
+		signer, err := NewSigner(device, test.path, test.keyType)
+		c.Assert(err, IsNil)
+		expected, err := sign.NewKeyFromMnemonic(mnemonic, "", test.path, test.keyType)
+		c.Assert(err, IsNil)
+		c.Check(signer.PublicKey(), Equals, expected.PublicKey())
+		c.Check(signer.Account(), Equals, expected.Account())
+		c.Check(signer.ShowAddress(), IsNil)
+
+		// A memo makes the transaction span several chunks
+		amount, err := data.NewAmount("1000000")
+		c.Assert(err, IsNil)
+		tx := data.TxFactory[data.PAYMENT]().(*data.Payment)
+		tx.Account = signer.Account()
+		tx.Destination = signer.Account()
+		tx.Destination[0]++
+		tx.Amount = *amount
+		var memo data.Memo
+		memo.Memo.MemoData = make(data.VariableLength, 300)
+		tx.Memos = data.Memos{memo}
+		_, err = sign.Transaction(tx, signer)
+		c.Assert(err, IsNil)
+		c.Check(a.chunks > 2, Equals, true)
+
+		a.reject = true
+		_, err = sign.Transaction(tx, signer)
+		c.Check(err, ErrorMatches, "Ledger error 6985: Rejected by user")
+	}
+	_, err = NewSigner(&app{c: c}, "m/44'/144'/0'/0/0/0/0/0/0/0/0", data.ECDSA)
+	c.Check(err, ErrorMatches, "Path is too long: .*")
+}
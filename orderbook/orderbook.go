@@ -0,0 +1,372 @@
+// Package orderbook keeps a copy in memory of the two books of a market,
+// loaded with book_offers and kept up to date by applying the metadata of
+// the validated transactions which change them.
+package orderbook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// Client is the part of ripple.Client needed to load the books
+type Client interface {
+	BookOffersCtx(ctx context.Context, taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*websockets.BookOffersResult, error)
+}
+
+// Offer is an offer in a book, as placed. An unfunded offer stays in the
+// book until a transaction finds it unfunded and removes it.
+type Offer struct {
+	LedgerIndex data.Hash256
+	Account     data.Account
+	Sequence    uint32
+	TakerPays   data.Amount
+	TakerGets   data.Amount
+	Quality     data.Value // TakerPays/TakerGets
+}
+
+// Level is the total of the offers in a book at one quality
+type Level struct {
+	Quality   data.Value
+	TakerPays data.Amount
+	TakerGets data.Amount
+	Offers    int
+}
+
+// Update tells of a change to the books. An Update with no Changes is
+// sent when the books have been loaded.
+type Update struct {
+	LedgerSequence uint32
+	Hash           data.Hash256 // Of the transaction
+	Changes        map[data.Book][]data.OfferChange
+}
+
+// book holds offers sorted by quality, then by age
+type book struct {
+	offers []*Offer
+	index  map[data.Hash256]*Offer
+}
+
+func newOffer(o *data.Offer, index *data.Hash256) (*Offer, error) {
+	if index == nil || o.Account == nil || o.TakerPays == nil || o.TakerGets == nil {
+		return nil, fmt.Errorf("Offer is missing fields: %+v", o)
+	}
+	offer := &Offer{
+		LedgerIndex: *index,
+		Account:     *o.Account,
+		TakerPays:   *o.TakerPays,
+		TakerGets:   *o.TakerGets,
+	}
+	if o.Sequence != nil {
+		offer.Sequence = *o.Sequence
+	}
+	if o.BookDirectory != nil {
+		offer.Quality = *data.BookQuality(*o.BookDirectory)
+		return offer, nil
+	}
+	quality, err := o.Quality()
+	if err != nil {
+		return nil, err
+	}
+	offer.Quality = *quality
+	return offer, nil
+}
+
+func (b *book) insert(offer *Offer) {
+	if existing, ok := b.index[offer.LedgerIndex]; ok {
+		b.remove(existing.LedgerIndex)
+	}
+	i := sort.Search(len(b.offers), func(i int) bool {
+		return b.offers[i].Quality.Compare(offer.Quality) > 0
+	})
+	b.offers = append(b.offers, nil)
+	copy(b.offers[i+1:], b.offers[i:])
+	b.offers[i] = offer
+	b.index[offer.LedgerIndex] = offer
+}
+
+func (b *book) remove(index data.Hash256) {
+	if _, ok := b.index[index]; !ok {
+		return
+	}
+	delete(b.index, index)
+	for i := range b.offers {
+		if b.offers[i].LedgerIndex == index {
+			b.offers = append(b.offers[:i], b.offers[i+1:]...)
+			return
+		}
+	}
+}
+
+// apply changes the book as a transaction did. Offers beyond those
+// loaded are not known, so their changes are ignored.
+func (b *book) apply(changes []data.OfferChange) error {
+	for _, change := range changes {
+		if change.LedgerIndex == nil {
+			continue
+		}
+		switch change.State {
+		case data.Created:
+			offer, err := newOffer(change.Offer, change.LedgerIndex)
+			if err != nil {
+				return err
+			}
+			b.insert(offer)
+		case data.Modified:
+			// The Amounts are replaced rather than changed, as copies
+			// handed out share their Values
+			if offer, ok := b.index[*change.LedgerIndex]; ok {
+				offer.TakerPays, offer.TakerGets = *change.Offer.TakerPays, *change.Offer.TakerGets
+			}
+		case data.Deleted:
+			b.remove(*change.LedgerIndex)
+		}
+	}
+	return nil
+}
+
+// Mirror holds the asks, in which takers pay the quote asset to get the
+// base asset, and the bids, in which they pay the base to get the quote.
+// It is safe for concurrent use.
+type Mirror struct {
+	Base  data.Asset
+	Quote data.Asset
+
+	client   Client
+	mu       sync.RWMutex
+	books    map[data.Book]*book
+	ledger   uint32 // Of the loaded books
+	watchers []chan *Update
+}
+
+func NewMirror(client Client, base, quote data.Asset) *Mirror {
+	return &Mirror{
+		Base:   base,
+		Quote:  quote,
+		client: client,
+	}
+}
+
+// Asks is the book of offers to sell the base asset
+func (m *Mirror) Asks() data.Book { return data.Book{Pays: m.Quote, Gets: m.Base} }
+
+// Bids is the book of offers to buy the base asset
+func (m *Mirror) Bids() data.Book { return data.Book{Pays: m.Base, Gets: m.Quote} }
+
+// Subscription returns the options to subscribe to the transactions
+// which change the books
+func (m *Mirror) Subscription() websockets.SubscriptionOptions {
+	return websockets.SubscriptionOptions{
+		Books: []websockets.OrderBookSubscription{{
+			TakerPays: m.Quote,
+			TakerGets: m.Base,
+			Both:      true,
+		}},
+	}
+}
+
+// Notify returns a channel which receives an Update after each change to
+// the books. Updates are dropped while the channel is full, but the books
+// can always be read.
+func (m *Mirror) Notify(buffer int) <-chan *Update {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := make(chan *Update, buffer)
+	m.watchers = append(m.watchers, c)
+	return c
+}
+
+// notify must be called with the lock held
+func (m *Mirror) notify(update *Update) {
+	for _, c := range m.watchers {
+		select {
+		case c <- update:
+		default:
+		}
+	}
+}
+
+// Load replaces the books with those of the last validated ledger, both
+// read from the same ledger, and returns its sequence
+func (m *Mirror) Load(ctx context.Context) (uint32, error) {
+	var zeroAccount data.Account
+	asks, err := m.client.BookOffersCtx(ctx, zeroAccount, "validated", m.Quote, m.Base)
+	if err != nil {
+		return 0, err
+	}
+	if asks.LedgerSequence == 0 {
+		return 0, fmt.Errorf("book_offers result has no ledger index")
+	}
+	bids, err := m.client.BookOffersCtx(ctx, zeroAccount, asks.LedgerSequence, m.Base, m.Quote)
+	if err != nil {
+		return 0, err
+	}
+	books := make(map[data.Book]*book)
+	for key, result := range map[data.Book]*websockets.BookOffersResult{m.Asks(): asks, m.Bids(): bids} {
+		b := &book{index: make(map[data.Hash256]*Offer)}
+		for i := range result.Offers {
+			offer, err := newOffer(&result.Offers[i].Offer, result.Offers[i].LedgerIndex)
+			if err != nil {
+				return 0, err
+			}
+			b.insert(offer)
+		}
+		books[key] = b
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.books, m.ledger = books, asks.LedgerSequence
+	m.notify(&Update{LedgerSequence: m.ledger})
+	return m.ledger, nil
+}
+
+// Apply changes the books as a validated transaction did. Transactions
+// in or before the loaded ledger are ignored, as are those which arrive
+// before the books are loaded.
+func (m *Mirror) Apply(msg *websockets.TransactionStreamMsg) error {
+	if !msg.Validated {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.books == nil || msg.LedgerSequence <= m.ledger {
+		return nil
+	}
+	update := &Update{
+		LedgerSequence: msg.LedgerSequence,
+		Hash:           *msg.Transaction.GetHash(),
+		Changes:        make(map[data.Book][]data.OfferChange),
+	}
+	for key, changes := range msg.Transaction.OfferChanges() {
+		b, ok := m.books[key]
+		if !ok {
+			continue
+		}
+		if err := b.apply(changes); err != nil {
+			return err
+		}
+		update.Changes[key] = changes
+	}
+	if len(update.Changes) > 0 {
+		m.notify(update)
+	}
+	return nil
+}
+
+// Run loads the books and applies the transactions from the stream, such
+// as the Transactions of a Subscription made with the options returned
+// by Subscription, until ctx is done or the stream is closed. Those which
+// arrive while loading are held until the books are loaded. After a
+// reconnection, transactions may have been missed, so Run should be
+// called again.
+func (m *Mirror) Run(ctx context.Context, transactions <-chan *websockets.TransactionStreamMsg) error {
+	loaded := make(chan error, 1)
+	go func() {
+		_, err := m.Load(ctx)
+		loaded <- err
+	}()
+	var pending []*websockets.TransactionStreamMsg
+	for {
+		select {
+		case err := <-loaded:
+			if err != nil {
+				return err
+			}
+			for _, msg := range pending {
+				if err := m.Apply(msg); err != nil {
+					return err
+				}
+			}
+			pending, loaded = nil, nil
+		case msg, ok := <-transactions:
+			switch {
+			case !ok:
+				return fmt.Errorf("Transactions stream closed")
+			case loaded != nil:
+				pending = append(pending, msg)
+			default:
+				if err := m.Apply(msg); err != nil {
+					return err
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Ledger returns the sequence of the ledger the books were loaded from
+func (m *Mirror) Ledger() uint32 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ledger
+}
+
+// Offers returns copies of the best n offers in a book of the mirror,
+// or all of them if n is zero
+func (m *Mirror) Offers(key data.Book, n int) []Offer {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	b, ok := m.books[key]
+	if !ok {
+		return nil
+	}
+	if n <= 0 || n > len(b.offers) {
+		n = len(b.offers)
+	}
+	offers := make([]Offer, n)
+	for i := range offers {
+		offers[i] = *b.offers[i]
+	}
+	return offers
+}
+
+// best returns the best offer in a book, or nil if there are none
+func (m *Mirror) best(key data.Book) *Offer {
+	if offers := m.Offers(key, 1); len(offers) > 0 {
+		return &offers[0]
+	}
+	return nil
+}
+
+// BestAsk returns the cheapest offer to sell the base asset
+func (m *Mirror) BestAsk() *Offer { return m.best(m.Asks()) }
+
+// BestBid returns the highest offer to buy the base asset
+func (m *Mirror) BestBid() *Offer { return m.best(m.Bids()) }
+
+// Depth returns the totals of the best n qualities in a book of the
+// mirror, or of all of them if n is zero
+func (m *Mirror) Depth(key data.Book, n int) ([]Level, error) {
+	var levels []Level
+	for _, offer := range m.Offers(key, 0) {
+		last := len(levels) - 1
+		if last >= 0 && levels[last].Quality.Equals(offer.Quality) {
+			pays, err := levels[last].TakerPays.Add(&offer.TakerPays)
+			if err != nil {
+				return nil, err
+			}
+			gets, err := levels[last].TakerGets.Add(&offer.TakerGets)
+			if err != nil {
+				return nil, err
+			}
+			levels[last].TakerPays, levels[last].TakerGets = *pays, *gets
+			levels[last].Offers++
+			continue
+		}
+		if n > 0 && len(levels) == n {
+			break
+		}
+		levels = append(levels, Level{
+			Quality:   offer.Quality,
+			TakerPays: *offer.TakerPays.Clone(),
+			TakerGets: *offer.TakerGets.Clone(),
+			Offers:    1,
+		})
+	}
+	return levels, nil
+}
@@ -0,0 +1,157 @@
+package orderbook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type OrderBookSuite struct{}
+
+var _ = Suite(&OrderBookSuite{})
+
+var (
+	xrp = data.Asset{Currency: "XRP"}
+	btc = data.Asset{Currency: "BTC", Issuer: "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"}
+)
+
+// bookServer answers book_offers from the offers of its books, once
+// release is closed
+type bookServer struct {
+	ledger   uint32
+	books    map[data.Book][]data.OrderBookOffer
+	release  chan struct{}
+	requests []interface{}
+}
+
+func (b *bookServer) BookOffersCtx(ctx context.Context, taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*websockets.BookOffersResult, error) {
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	b.requests = append(b.requests, ledgerIndex)
+	return &websockets.BookOffersResult{
+		LedgerSequence: b.ledger,
+		Offers:         b.books[data.Book{Pays: pays, Gets: gets}],
+	}, nil
+}
+
+// readTransaction returns a validated transaction which consumes eight
+// offers to buy XRP with BTC, and a server with those offers as they
+// were before it
+func readTransaction(c *C) (*websockets.TransactionStreamMsg, *bookServer) {
+	b, err := ioutil.ReadFile("../data/testdata/transaction_offercreate.json")
+	c.Assert(err, IsNil)
+	msg := &websockets.TransactionStreamMsg{Validated: true}
+	c.Assert(json.Unmarshal(b, &msg.Transaction), IsNil)
+	msg.LedgerSequence = msg.Transaction.LedgerSequence
+	server := &bookServer{
+		ledger:  msg.LedgerSequence - 1,
+		books:   make(map[data.Book][]data.OrderBookOffer),
+		release: make(chan struct{}),
+	}
+	for i := range msg.Transaction.MetaData.AffectedNodes {
+		node, final, previous, _ := msg.Transaction.MetaData.AffectedNodes[i].AffectedNode()
+		offer, ok := final.(*data.Offer)
+		if !ok {
+			continue
+		}
+		before := *offer
+		before.LedgerIndex = node.LedgerIndex
+		before.TakerPays, before.TakerGets = previous.(*data.Offer).TakerPays, previous.(*data.Offer).TakerGets
+		book := before.Book()
+		server.books[book] = append(server.books[book], data.OrderBookOffer{Offer: before})
+	}
+	return msg, server
+}
+
+func (s *OrderBookSuite) TestLoadAndApply(c *C) {
+	msg, server := readTransaction(c)
+	close(server.release)
+	m := NewMirror(server, btc, xrp)
+	c.Check(m.Subscription().Books, DeepEquals, []websockets.OrderBookSubscription{{TakerPays: xrp, TakerGets: btc, Both: true}})
+	c.Check(m.BestAsk(), IsNil)
+	c.Check(m.Apply(msg), IsNil)
+
+	ledger, err := m.Load(context.Background())
+	c.Assert(err, IsNil)
+	c.Check(ledger, Equals, server.ledger)
+	c.Check(server.requests, DeepEquals, []interface{}{"validated", server.ledger})
+	c.Check(m.Offers(m.Bids(), 0), HasLen, 0)
+	c.Check(m.BestBid(), IsNil)
+	asks := m.Offers(m.Asks(), 0)
+	c.Assert(asks, HasLen, 8)
+	for i := 1; i < len(asks); i++ {
+		c.Check(asks[i-1].Quality.Compare(asks[i].Quality) <= 0, Equals, true)
+	}
+	c.Check(m.BestAsk().LedgerIndex, Equals, asks[0].LedgerIndex)
+	c.Check(m.Offers(m.Asks(), 3), HasLen, 3)
+	levels, err := m.Depth(m.Asks(), 0)
+	c.Assert(err, IsNil)
+	total := 0
+	for _, level := range levels {
+		total += level.Offers
+	}
+	c.Check(total, Equals, 8)
+	levels, err = m.Depth(m.Asks(), 2)
+	c.Assert(err, IsNil)
+	c.Check(levels, HasLen, 2)
+
+	// Nothing changes for a transaction already in the loaded ledger
+	updates := m.Notify(10)
+	old := *msg
+	old.LedgerSequence = server.ledger
+	c.Check(m.Apply(&old), IsNil)
+	c.Check(m.Offers(m.Asks(), 0), HasLen, 8)
+
+	c.Check(m.Apply(msg), IsNil)
+	asks = m.Offers(m.Asks(), 0)
+	c.Assert(asks, HasLen, 1)
+	c.Check(asks[0].Sequence, Equals, uint32(2308))
+	c.Check(asks[0].TakerPays.String(), Equals, "391246.205435/XRP")
+	update := <-updates
+	c.Check(update.LedgerSequence, Equals, msg.LedgerSequence)
+	c.Check(update.Hash, Equals, *msg.Transaction.GetHash())
+	c.Check(update.Changes[m.Asks()], HasLen, 8)
+	c.Check(updates, HasLen, 0)
+}
+
+func (s *OrderBookSuite) TestRun(c *C) {
+	msg, server := readTransaction(c)
+	m := NewMirror(server, btc, xrp)
+	updates := m.Notify(10)
+	transactions := make(chan *websockets.TransactionStreamMsg)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- m.Run(ctx, transactions) }()
+
+	// Held until the books are loaded
+	transactions <- msg
+	close(server.release)
+	c.Check((<-updates).Changes, IsNil)
+	select {
+	case update := <-updates:
+		c.Check(update.Changes[m.Asks()], HasLen, 8)
+	case <-time.After(time.Second):
+		c.Fatal("no update")
+	}
+	c.Check(m.Ledger(), Equals, server.ledger)
+	c.Check(m.Offers(m.Asks(), 0), HasLen, 1)
+
+	cancel()
+	c.Check(<-done, Equals, context.Canceled)
+
+	m = NewMirror(server, btc, xrp)
+	transactions = make(chan *websockets.TransactionStreamMsg)
+	close(transactions)
+	c.Check(m.Run(context.Background(), transactions), ErrorMatches, "Transactions stream closed")
+}
@@ -0,0 +1,373 @@
+package paths
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// ErrNoLiquidity is returned when no path can deliver the amount
+var ErrNoLiquidity = errors.New("Not enough liquidity")
+
+// Client is the part of ripple.Client needed to evaluate paths
+type Client interface {
+	BookOffersCtx(ctx context.Context, taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*websockets.BookOffersResult, error)
+	AMMInfoCtx(ctx context.Context, asset, asset2 data.Asset, ledgerIndex interface{}) (*websockets.AMMInfoResult, error)
+}
+
+// Liquidity is where a Step gets its asset from
+type Liquidity int
+
+const (
+	OrderBook Liquidity = iota
+	AMM
+)
+
+func (l Liquidity) String() string {
+	if l == AMM {
+		return "AMM"
+	}
+	return "order book"
+}
+
+// Step is one exchange of a path, with XRP at face value
+type Step struct {
+	Pays      data.Asset
+	Gets      data.Asset
+	In        *data.Value // Of Pays
+	Out       *data.Value // Of Gets
+	Spot      *data.Value // Pays for each Gets at the best price
+	Liquidity Liquidity
+}
+
+// Evaluation is the estimated cost of a payment along a path
+type Evaluation struct {
+	Path              data.Path // Nil for the default path
+	Steps             []Step
+	SourceAmount      data.Amount
+	DestinationAmount data.Amount
+	// SourceAmount paid for each of DestinationAmount, with XRP at face
+	// value
+	Quality *data.Value
+	// Quality of the smallest payment, at the best prices of each Step
+	Spot *data.Value
+	// How much worse Quality is than Spot, as a fraction of it
+	Slippage       *data.Value
+	LedgerSequence uint32
+}
+
+func (e Evaluation) String() string {
+	return fmt.Sprintf("%s for %s Slippage: %s Steps: %d Ledger: %d", e.SourceAmount, e.DestinationAmount,
+		e.Slippage, len(e.Steps), e.LedgerSequence)
+}
+
+// Evaluator estimates the cost of payments from the order books and AMMs
+// of the last validated ledger
+type Evaluator struct {
+	client Client
+}
+
+func NewEvaluator(client Client) *Evaluator {
+	return &Evaluator{client: client}
+}
+
+// market is the liquidity of one book
+type market struct {
+	offers []data.OrderBookOffer
+	amm    *websockets.AMMInfo
+}
+
+// evaluation holds what has been read for one call to Evaluate, all of it
+// from one ledger
+type evaluation struct {
+	*Evaluator
+	ledger  interface{}
+	markets map[data.Book]*market
+}
+
+// Evaluate estimates the cost of delivering amount, which may differ from
+// the DestinationAmount of alt, along the default path and each path of
+// alt alone, and returns the cheapest. Rippled may spread a payment over
+// several paths, and take from both the offers and the AMM of a book,
+// whereas each Step here takes from whichever is cheaper, so the estimate
+// errs on the high side. Transfer fees are not counted.
+func (e *Evaluator) Evaluate(ctx context.Context, alt Alternative, amount data.Amount) (*Evaluation, error) {
+	if !amount.SameAsset(alt.DestinationAmount) {
+		return nil, fmt.Errorf("Cannot evaluate %s with paths delivering %s", amount, alt.DestinationAmount.Asset())
+	}
+	ev := &evaluation{Evaluator: e, ledger: "validated", markets: make(map[data.Book]*market)}
+	var best *Evaluation
+	for _, path := range append(data.PathSet{nil}, alt.Paths...) {
+		evaluation, err := ev.path(ctx, alt, path, amount)
+		switch {
+		case err == ErrNoLiquidity:
+			continue
+		case err != nil:
+			return nil, err
+		case best == nil || evaluation.Quality.Less(*best.Quality):
+			best = evaluation
+		}
+	}
+	if best == nil {
+		return nil, ErrNoLiquidity
+	}
+	return best, nil
+}
+
+// assets returns the assets a payment along path passes through, from
+// that of SourceAmount to that of DestinationAmount. An account in a path
+// issues the asset it passes on, and the destination takes its currency
+// from any issuer when it is the issuer of DestinationAmount.
+func (alt Alternative) assets(path data.Path) []data.Asset {
+	assets := []data.Asset{*alt.SourceAmount.Asset()}
+	last := func() *data.Asset { return &assets[len(assets)-1] }
+	for _, elem := range path {
+		if elem.Currency == nil && elem.Issuer == nil {
+			if elem.Account != nil && !last().IsNative() {
+				last().Issuer = elem.Account.String()
+			}
+			continue
+		}
+		next := *last()
+		switch {
+		case elem.Currency == nil:
+		case elem.Currency.IsNative():
+			next = data.Asset{Currency: "XRP"}
+		default:
+			next.Currency = elem.Currency.String()
+		}
+		if elem.Issuer != nil && !next.IsNative() {
+			next.Issuer = elem.Issuer.String()
+		}
+		if next != *last() {
+			assets = append(assets, next)
+		}
+	}
+	destination := *alt.DestinationAmount.Asset()
+	if destination.Currency == last().Currency && destination.Issuer == alt.Destination.String() {
+		destination.Issuer = last().Issuer
+	}
+	if destination != *last() {
+		assets = append(assets, destination)
+	}
+	return assets
+}
+
+// path evaluates delivering amount along path, working back from the
+// destination as rippled does
+func (ev *evaluation) path(ctx context.Context, alt Alternative, path data.Path, amount data.Amount) (*Evaluation, error) {
+	assets := alt.assets(path)
+	out, err := face(*amount.Value)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]Step, len(assets)-1)
+	spot := one
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := &steps[i]
+		step.Pays, step.Gets, step.Out = assets[i], assets[i+1], out
+		if err := ev.step(ctx, step); err != nil {
+			return nil, err
+		}
+		if spot, err = spot.Multiply(*step.Spot); err != nil {
+			return nil, err
+		}
+		out = step.In
+	}
+	evaluation := &Evaluation{Path: path, Steps: steps, DestinationAmount: amount, Spot: spot}
+	if evaluation.SourceAmount, err = sourceAmount(alt.SourceAmount, out); err != nil {
+		return nil, err
+	}
+	if evaluation.Quality, err = out.Ratio(*amount.Value); err != nil {
+		return nil, err
+	}
+	ratio, err := evaluation.Quality.Divide(*spot)
+	if err != nil {
+		return nil, err
+	}
+	if evaluation.Slippage, err = ratio.Subtract(*one); err != nil {
+		return nil, err
+	}
+	if ledger, ok := ev.ledger.(uint32); ok {
+		evaluation.LedgerSequence = ledger
+	}
+	return evaluation, nil
+}
+
+// sourceAmount returns value, at face value, in the asset of like,
+// rounding XRP up to the next drop
+func sourceAmount(like data.Amount, value *data.Value) (data.Amount, error) {
+	amount := data.Amount{Value: value, Currency: like.Currency, Issuer: like.Issuer}
+	if !like.IsNative() {
+		return amount, nil
+	}
+	drops := value.Rat()
+	drops.Mul(drops, big.NewRat(1000000, 1))
+	n := new(big.Int).Quo(drops.Num(), drops.Denom())
+	if !drops.IsInt() {
+		n.Add(n, big.NewInt(1))
+	}
+	var err error
+	amount.Value, err = data.NewNativeValue(n.Int64())
+	return amount, err
+}
+
+// step sets In, Spot and Liquidity of step from whichever of its book and
+// AMM delivers Out more cheaply
+func (ev *evaluation) step(ctx context.Context, step *Step) error {
+	m, err := ev.market(ctx, step.Pays, step.Gets)
+	if err != nil {
+		return err
+	}
+	in, spot, err := m.fromOffers(step.Out)
+	if err != nil {
+		return err
+	}
+	if m.amm != nil {
+		ammIn, ammSpot, err := m.fromAMM(step.Pays, step.Gets, step.Out)
+		if err != nil {
+			return err
+		}
+		if ammIn != nil && (in == nil || ammIn.Less(*in)) {
+			in, spot, step.Liquidity = ammIn, ammSpot, AMM
+		}
+	}
+	if in == nil {
+		return ErrNoLiquidity
+	}
+	step.In, step.Spot = in, spot
+	return nil
+}
+
+// market returns the offers and AMM of the book in which takers pay pays
+// to get gets, reading them on first use
+func (ev *evaluation) market(ctx context.Context, pays, gets data.Asset) (*market, error) {
+	key := data.Book{Pays: pays, Gets: gets}
+	if m, ok := ev.markets[key]; ok {
+		return m, nil
+	}
+	var zeroAccount data.Account
+	offers, err := ev.client.BookOffersCtx(ctx, zeroAccount, ev.ledger, pays, gets)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := ev.ledger.(uint32); !ok {
+		if offers.LedgerSequence == 0 {
+			return nil, fmt.Errorf("book_offers result has no ledger index")
+		}
+		ev.ledger = offers.LedgerSequence
+	}
+	m := &market{offers: offers.Offers}
+	amm, err := ev.client.AMMInfoCtx(ctx, pays, gets, ev.ledger)
+	switch {
+	case websockets.IsNotFound(err):
+	case err != nil:
+		return nil, err
+	default:
+		m.amm = &amm.AMM
+	}
+	ev.markets[key] = m
+	return m, nil
+}
+
+// fromOffers returns what taking out from the offers costs, and the price
+// of the best of them, or nils when they don't add up to out
+func (m *market) fromOffers(out *data.Value) (*data.Value, *data.Value, error) {
+	in, remaining := out.ZeroClone(), out
+	var spot *data.Value
+	for i := range m.offers {
+		offer := &m.offers[i]
+		pays, gets := offer.TakerPays, offer.TakerGets
+		if pays == nil || gets == nil {
+			continue
+		}
+		if offer.TakerPaysFunded != nil && offer.TakerGetsFunded != nil {
+			pays, gets = offer.TakerPaysFunded, offer.TakerGetsFunded
+		}
+		if gets.IsZero() {
+			continue
+		}
+		quality, err := pays.Value.Ratio(*gets.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if spot == nil {
+			spot = quality
+		}
+		available, err := face(*gets.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		take := remaining.Min(*available)
+		cost, err := take.Multiply(*quality)
+		if err != nil {
+			return nil, nil, err
+		}
+		if in, err = in.Add(*cost); err != nil {
+			return nil, nil, err
+		}
+		if remaining, err = remaining.Subtract(*take); err != nil {
+			return nil, nil, err
+		}
+		if remaining.IsZero() {
+			return in, spot, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// fromAMM returns what taking out of gets from the AMM's pool costs in
+// pays, after its trading fee, and its price before the trade, or nils
+// when the pool holds no more than out
+func (m *market) fromAMM(pays, gets data.Asset, out *data.Value) (*data.Value, *data.Value, error) {
+	x, y := m.amm.Amount, m.amm.Amount2
+	if gets.Matches(&x) {
+		x, y = y, x
+	}
+	if !pays.Matches(&x) || !gets.Matches(&y) {
+		return nil, nil, fmt.Errorf("AMM %s does not hold %s and %s", m.amm.Account, pays, gets)
+	}
+	pool, err := face(*x.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+	held, err := face(*y.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !out.Less(*held) {
+		return nil, nil, nil
+	}
+	// The fraction of what is paid which goes into the pool
+	kept, err := data.NewNonNativeValue(int64(100000-int(m.amm.TradingFee)), -5)
+	if err != nil {
+		return nil, nil, err
+	}
+	price, err := pool.Divide(*held)
+	if err != nil {
+		return nil, nil, err
+	}
+	if price, err = price.Divide(*kept); err != nil {
+		return nil, nil, err
+	}
+	// The pool's product stays the same: pool*held = (pool+in)*(held-out)
+	left, err := held.Subtract(*out)
+	if err != nil {
+		return nil, nil, err
+	}
+	in, err := pool.Multiply(*out)
+	if err != nil {
+		return nil, nil, err
+	}
+	if in, err = in.Divide(*left); err != nil {
+		return nil, nil, err
+	}
+	if in, err = in.Divide(*kept); err != nil {
+		return nil, nil, err
+	}
+	return in, price, nil
+}
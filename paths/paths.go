@@ -0,0 +1,146 @@
+// Package paths ranks the alternatives found by ripple_path_find and
+// path_find, and estimates what a payment along them would cost, from the
+// order books and AMMs of the current ledger, before it is submitted.
+//
+//	result, err := remote.RipplePathFind(src, dest, amount, nil)
+//	...
+//	alternatives := paths.FromRipplePathFind(result, src, amount)
+//	err = paths.Rank(ctx, alternatives, paths.In(rates.New(remote, 0), usd))
+//	...
+//	evaluation, err := paths.NewEvaluator(remote).Evaluate(ctx, alternatives[0], amount)
+//	...
+//	fmt.Println(evaluation.SourceAmount, evaluation.Slippage)
+package paths
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/rates"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+var one = data.MustValue("1", false)
+
+// face returns v as a non-native Value, with XRP at face value rather
+// than in drops
+func face(v data.Value) (*data.Value, error) {
+	return v.Ratio(*one)
+}
+
+// Alternative is one way of paying DestinationAmount, costing about
+// SourceAmount
+type Alternative struct {
+	Source            data.Account
+	Destination       data.Account
+	SourceAmount      data.Amount
+	DestinationAmount data.Amount
+	Paths             data.PathSet
+
+	// SourceAmount paid for each of DestinationAmount, with XRP at face
+	// value
+	Quality *data.Value
+	// Steps in the shortest path, zero when there are no paths
+	Hops int
+	// What SourceAmount is worth, set by Rank
+	Cost *data.Value
+}
+
+func newAlternative(source, destination data.Account, sourceAmount, destinationAmount data.Amount, paths data.PathSet) Alternative {
+	alt := Alternative{
+		Source:            source,
+		Destination:       destination,
+		SourceAmount:      sourceAmount,
+		DestinationAmount: destinationAmount,
+		Paths:             paths,
+		Quality:           sourceAmount.Ratio(destinationAmount),
+	}
+	for i, path := range paths {
+		if i == 0 || len(path) < alt.Hops {
+			alt.Hops = len(path)
+		}
+	}
+	return alt
+}
+
+// FromRipplePathFind returns the alternatives of a ripple_path_find for
+// source to pay amount
+func FromRipplePathFind(result *websockets.RipplePathFindResult, source data.Account, amount data.Amount) []Alternative {
+	var alternatives []Alternative
+	for _, alt := range result.Alternatives {
+		alternatives = append(alternatives, newAlternative(source, result.DestAccount, alt.SrcAmount, amount, alt.PathsComputed))
+	}
+	return alternatives
+}
+
+// FromPathFind returns the alternatives of a path_find result or update
+func FromPathFind(result *websockets.PathFindResult) []Alternative {
+	var alternatives []Alternative
+	for _, alt := range result.Alternatives {
+		alternatives = append(alternatives, newAlternative(result.SourceAccount, result.DestinationAccount,
+			alt.SourceAmount, result.DestinationAmount, alt.PathsComputed))
+	}
+	return alternatives
+}
+
+// Valuer returns what amount is worth in some asset, so that alternatives
+// paying different assets can be compared
+type Valuer func(ctx context.Context, amount data.Amount) (*data.Value, error)
+
+// In returns a Valuer which values amounts in quote at the Mid of their
+// Rate from r
+func In(r *rates.Rates, quote data.Asset) Valuer {
+	return func(ctx context.Context, amount data.Amount) (*data.Value, error) {
+		value, err := face(*amount.Value)
+		if err != nil {
+			return nil, err
+		}
+		base := *amount.Asset()
+		if base == quote {
+			return value, nil
+		}
+		rate, err := r.Rate(ctx, base, quote)
+		if err != nil {
+			return nil, err
+		}
+		if rate.Mid == nil {
+			return nil, fmt.Errorf("No rate for %s in %s", base, quote)
+		}
+		return value.Multiply(*rate.Mid)
+	}
+}
+
+// Rank sets the Cost of each alternative with value, and sorts them by
+// it, cheapest first, then by fewest Hops and then fewest Paths. Without
+// a Valuer, the Cost is the SourceAmount, and every alternative must pay
+// the same asset.
+func Rank(ctx context.Context, alternatives []Alternative, value Valuer) error {
+	for i := range alternatives {
+		alt := &alternatives[i]
+		var err error
+		switch {
+		case value != nil:
+			alt.Cost, err = value(ctx, alt.SourceAmount)
+		case *alt.SourceAmount.Asset() != *alternatives[0].SourceAmount.Asset():
+			err = fmt.Errorf("Alternatives pay %s and %s", alternatives[0].SourceAmount.Asset(), alt.SourceAmount.Asset())
+		default:
+			alt.Cost, err = face(*alt.SourceAmount.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		a, b := &alternatives[i], &alternatives[j]
+		if c := a.Cost.Compare(*b.Cost); c != 0 {
+			return c < 0
+		}
+		if a.Hops != b.Hops {
+			return a.Hops < b.Hops
+		}
+		return len(a.Paths) < len(b.Paths)
+	})
+	return nil
+}
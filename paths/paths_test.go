@@ -0,0 +1,264 @@
+package paths
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/rates"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type PathsSuite struct{}
+
+var _ = Suite(&PathsSuite{})
+
+const (
+	gateway     = "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"
+	source      = "rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2"
+	destination = "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59"
+)
+
+var (
+	usd = data.Asset{Currency: "USD", Issuer: gateway}
+	eur = data.Asset{Currency: "EUR", Issuer: gateway}
+	xrp = data.Asset{Currency: "XRP"}
+)
+
+// markets is a Client which serves the offers and AMMs it has been given,
+// and records the ledger index of each request
+type markets struct {
+	books    map[data.Book][]data.OrderBookOffer
+	amms     map[data.Book]*websockets.AMMInfo // Under both orders of the assets
+	requests []interface{}
+}
+
+func newMarkets() *markets {
+	return &markets{books: make(map[data.Book][]data.OrderBookOffer), amms: make(map[data.Book]*websockets.AMMInfo)}
+}
+
+func (m *markets) BookOffersCtx(ctx context.Context, taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*websockets.BookOffersResult, error) {
+	m.requests = append(m.requests, ledgerIndex)
+	return &websockets.BookOffersResult{LedgerSequence: 100, Offers: m.books[data.Book{Pays: pays, Gets: gets}]}, nil
+}
+
+func (m *markets) AMMInfoCtx(ctx context.Context, asset, asset2 data.Asset, ledgerIndex interface{}) (*websockets.AMMInfoResult, error) {
+	m.requests = append(m.requests, ledgerIndex)
+	amm, ok := m.amms[data.Book{Pays: asset, Gets: asset2}]
+	if !ok {
+		return nil, &websockets.CommandError{Name: "actNotFound", Code: 19, Message: "Account not found."}
+	}
+	sequence := uint32(100)
+	return &websockets.AMMInfoResult{LedgerSequence: &sequence, AMM: *amm}, nil
+}
+
+func amount(c *C, s string) *data.Amount {
+	a, err := data.NewAmount(s)
+	c.Assert(err, IsNil)
+	return a
+}
+
+// offer adds an offer to pay gets for pays
+func (m *markets) offer(c *C, pays, gets string) {
+	offer := data.OrderBookOffer{Offer: data.Offer{TakerPays: amount(c, pays), TakerGets: amount(c, gets)}}
+	book := offer.Book()
+	m.books[book] = append(m.books[book], offer)
+}
+
+func (m *markets) amm(c *C, pool, pool2 string, fee uint16) {
+	amm := &websockets.AMMInfo{Amount: *amount(c, pool), Amount2: *amount(c, pool2), TradingFee: fee}
+	a, b := *amm.Amount.Asset(), *amm.Amount2.Asset()
+	m.amms[data.Book{Pays: a, Gets: b}] = amm
+	m.amms[data.Book{Pays: b, Gets: a}] = amm
+}
+
+func account(c *C, address string) data.Account {
+	a, err := data.NewAccountFromAddress(address)
+	c.Assert(err, IsNil)
+	return *a
+}
+
+func path(c *C, s string) data.Path {
+	p, err := data.NewPath(s)
+	c.Assert(err, IsNil)
+	return p
+}
+
+func alternative(c *C, sourceAmount, destinationAmount string, paths ...data.Path) Alternative {
+	return newAlternative(account(c, source), account(c, destination), *amount(c, sourceAmount), *amount(c, destinationAmount), paths)
+}
+
+func (s *PathsSuite) TestFromPathFind(c *C) {
+	result := &websockets.PathFindResult{
+		SourceAccount:      account(c, source),
+		DestinationAccount: account(c, destination),
+		DestinationAmount:  *amount(c, "10/EUR/"+destination),
+		Alternatives: []websockets.PathFindAlternative{
+			{SourceAmount: *amount(c, "12/USD/"+source), PathsComputed: data.PathSet{
+				path(c, gateway+" => EUR/"+gateway),
+				path(c, gateway),
+			}},
+			{SourceAmount: *amount(c, "25000000")},
+		},
+	}
+	alternatives := FromPathFind(result)
+	c.Assert(alternatives, HasLen, 2)
+	c.Check(alternatives[0].Quality.String(), Equals, "1.2")
+	c.Check(alternatives[0].Hops, Equals, 1)
+	c.Check(alternatives[0].Destination, Equals, account(c, destination))
+	c.Check(alternatives[1].Quality.String(), Equals, "2.5")
+	c.Check(alternatives[1].Hops, Equals, 0)
+}
+
+func (s *PathsSuite) TestRank(c *C) {
+	alternatives := []Alternative{
+		alternative(c, "20/USD/"+gateway, "10/EUR/"+gateway),
+		alternative(c, "10/USD/"+gateway, "10/EUR/"+gateway, path(c, gateway+" => EUR/"+gateway), path(c, gateway+" => XRP/"+gateway)),
+		alternative(c, "10/USD/"+gateway, "10/EUR/"+gateway, path(c, gateway+" => EUR/"+gateway)),
+		alternative(c, "10/USD/"+gateway, "10/EUR/"+gateway, path(c, gateway+" => "+destination+" => EUR/"+gateway)),
+	}
+	c.Assert(Rank(context.Background(), alternatives, nil), IsNil)
+	var costs []string
+	for _, alt := range alternatives {
+		costs = append(costs, alt.Cost.String())
+	}
+	c.Check(costs, DeepEquals, []string{"10", "10", "10", "20"})
+	c.Check(alternatives[0].Paths, HasLen, 1)
+	c.Check(alternatives[0].Hops, Equals, 2)
+	c.Check(alternatives[1].Paths, HasLen, 2)
+	c.Check(alternatives[2].Hops, Equals, 3)
+
+	// Paying XRP
+	alternatives = append(alternatives, alternative(c, "30000000", "10/EUR/"+gateway))
+	c.Check(Rank(context.Background(), alternatives, nil), ErrorMatches, "Alternatives pay USD/.* and XRP")
+
+	client := newMarkets()
+	client.offer(c, "1/USD/"+gateway, "2/XRP")
+	client.offer(c, "2/XRP", "1/USD/"+gateway)
+	c.Assert(Rank(context.Background(), alternatives, In(rates.New(client, 0), usd)), IsNil)
+	c.Check(alternatives[3].SourceAmount.IsNative(), Equals, true)
+	c.Check(alternatives[3].Cost.String(), Equals, "15")
+	c.Check(alternatives[4].Cost.String(), Equals, "20")
+
+	// No rate
+	c.Check(Rank(context.Background(), alternatives, In(rates.New(client, 0), eur)), ErrorMatches, "No rate for USD/.* in EUR/.*")
+}
+
+func (s *PathsSuite) TestAssets(c *C) {
+	xrpCurrency, err := data.NewCurrency("XRP")
+	c.Assert(err, IsNil)
+	alt := alternative(c, "10/USD/"+source, "10/EUR/"+destination)
+	route := append(path(c, gateway), data.PathElem{Currency: &xrpCurrency}, path(c, "EUR/"+gateway)[0])
+	c.Check(alt.assets(route), DeepEquals, []data.Asset{usd, xrp, eur})
+	c.Check(alt.assets(path(c, gateway+" => EUR/"+gateway+" => "+gateway)), DeepEquals, []data.Asset{usd, eur})
+	c.Check(alt.assets(nil), DeepEquals, []data.Asset{
+		{Currency: "USD", Issuer: source},
+		{Currency: "EUR", Issuer: destination},
+	})
+	// Rippling
+	alt = alternative(c, "10/USD/"+source, "10/USD/"+destination)
+	c.Check(alt.assets(path(c, gateway)), DeepEquals, []data.Asset{usd})
+}
+
+func (s *PathsSuite) TestEvaluateOffers(c *C) {
+	client := newMarkets()
+	client.offer(c, "50/USD/"+gateway, "50/EUR/"+gateway)
+	client.offer(c, "150/USD/"+gateway, "100/EUR/"+gateway)
+	alt := alternative(c, "100/USD/"+gateway, "100/EUR/"+gateway)
+	evaluator := NewEvaluator(client)
+
+	evaluation, err := evaluator.Evaluate(context.Background(), alt, *amount(c, "10/EUR/"+gateway))
+	c.Assert(err, IsNil)
+	c.Check(evaluation.SourceAmount.String(), Equals, "10/USD/"+gateway)
+	c.Check(evaluation.Slippage.IsZero(), Equals, true)
+	c.Check(evaluation.LedgerSequence, Equals, uint32(100))
+	c.Check(client.requests, DeepEquals, []interface{}{"validated", uint32(100)})
+
+	evaluation, err = evaluator.Evaluate(context.Background(), alt, *amount(c, "100/EUR/"+gateway))
+	c.Assert(err, IsNil)
+	c.Check(evaluation.Path, IsNil)
+	c.Assert(evaluation.Steps, HasLen, 1)
+	step := evaluation.Steps[0]
+	c.Check(step.Pays, Equals, usd)
+	c.Check(step.Gets, Equals, eur)
+	c.Check(step.Liquidity, Equals, OrderBook)
+	c.Check(step.In.String(), Equals, "125")
+	c.Check(step.Spot.String(), Equals, "1")
+	c.Check(evaluation.SourceAmount.String(), Equals, "125/USD/"+gateway)
+	c.Check(evaluation.Quality.String(), Equals, "1.25")
+	c.Check(evaluation.Spot.String(), Equals, "1")
+	c.Check(evaluation.Slippage.String(), Equals, "0.25")
+
+	_, err = evaluator.Evaluate(context.Background(), alt, *amount(c, "151/EUR/"+gateway))
+	c.Check(err, Equals, ErrNoLiquidity)
+	_, err = evaluator.Evaluate(context.Background(), alt, *amount(c, "10/USD/"+gateway))
+	c.Check(err, ErrorMatches, "Cannot evaluate 10/USD/.* with paths delivering EUR/.*")
+}
+
+func (s *PathsSuite) TestEvaluateAMM(c *C) {
+	client := newMarkets()
+	client.offer(c, "50/USD/"+gateway, "50/EUR/"+gateway)
+	client.offer(c, "150/USD/"+gateway, "100/EUR/"+gateway)
+	client.amm(c, "1000/EUR/"+gateway, "1000/USD/"+gateway, 1000)
+	alt := alternative(c, "100/USD/"+gateway, "100/EUR/"+gateway)
+	evaluator := NewEvaluator(client)
+
+	// The offers are cheaper for a little
+	evaluation, err := evaluator.Evaluate(context.Background(), alt, *amount(c, "10/EUR/"+gateway))
+	c.Assert(err, IsNil)
+	c.Check(evaluation.Steps[0].Liquidity, Equals, OrderBook)
+
+	// and the AMM for more, 1000*100/900/0.99
+	evaluation, err = evaluator.Evaluate(context.Background(), alt, *amount(c, "100/EUR/"+gateway))
+	c.Assert(err, IsNil)
+	step := evaluation.Steps[0]
+	c.Check(step.Liquidity, Equals, AMM)
+	c.Check(step.Liquidity.String(), Equals, "AMM")
+	c.Check(step.In.Float() > 112.2334 && step.In.Float() < 112.2335, Equals, true)
+	c.Check(step.Spot.Float() > 1.0101 && step.Spot.Float() < 1.0102, Equals, true)
+	c.Check(evaluation.Slippage.Float() > 0.11 && evaluation.Slippage.Float() < 0.112, Equals, true)
+
+	// Beyond the offers
+	evaluation, err = evaluator.Evaluate(context.Background(), alt, *amount(c, "500/EUR/"+gateway))
+	c.Assert(err, IsNil)
+	c.Check(evaluation.Steps[0].In.Float() > 1010, Equals, true)
+	_, err = evaluator.Evaluate(context.Background(), alt, *amount(c, "1000/EUR/"+gateway))
+	c.Check(err, Equals, ErrNoLiquidity)
+}
+
+func (s *PathsSuite) TestEvaluateThroughXRP(c *C) {
+	client := newMarkets()
+	// 0.5 USD for an XRP, 2 XRP for a EUR
+	client.offer(c, "10/USD/"+gateway, "20/XRP")
+	client.offer(c, "20/XRP", "10/EUR/"+gateway)
+	alt := alternative(c, "5/USD/"+source, "5/EUR/"+destination, path(c, gateway+" => XRP/"+gateway+" => EUR/"+gateway))
+	// An XRP path element has no issuer
+	alt.Paths[0][1].Issuer = nil
+	xrpCurrency, err := data.NewCurrency("XRP")
+	c.Assert(err, IsNil)
+	alt.Paths[0][1].Currency = &xrpCurrency
+
+	evaluation, err := NewEvaluator(client).Evaluate(context.Background(), alt, *amount(c, "5/EUR/"+destination))
+	c.Assert(err, IsNil)
+	c.Check(evaluation.Path, DeepEquals, alt.Paths[0])
+	c.Assert(evaluation.Steps, HasLen, 2)
+	c.Check(evaluation.Steps[0].Pays, Equals, usd)
+	c.Check(evaluation.Steps[0].In.String(), Equals, "5")
+	c.Check(evaluation.Steps[1].In.String(), Equals, "10")
+	c.Check(evaluation.SourceAmount.String(), Equals, "5/USD/"+source)
+	c.Check(evaluation.Spot.String(), Equals, "1")
+}
+
+func (s *PathsSuite) TestEvaluateXRPSource(c *C) {
+	client := newMarkets()
+	client.offer(c, "1/XRP", "3/USD/"+gateway)
+	alt := alternative(c, "1/XRP", "3/USD/"+gateway)
+	evaluation, err := NewEvaluator(client).Evaluate(context.Background(), alt, *amount(c, "1/USD/"+gateway))
+	c.Assert(err, IsNil)
+	// Rounded up to a whole drop
+	c.Check(evaluation.SourceAmount.IsNative(), Equals, true)
+	c.Check(evaluation.SourceAmount.String(), Equals, "0.333334/XRP")
+}
@@ -0,0 +1,219 @@
+// Package rates prices one asset in another from the best offers of the
+// order books between them. A pair without offers of its own on one side
+// is priced through XRP, as a payment between them would be.
+//
+//	r := rates.New(remote, time.Minute)
+//	rate, err := r.Rate(ctx, usd, eur)
+//	...
+//	fmt.Println(rate.Bid, rate.Ask, rate.Mid)
+//
+// Rates are cached for a while, and a Ticker streams those of a pair as
+// its books change.
+package rates
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// How long a Rate is cached for when Rates.TTL is zero, which is about
+// the time between ledgers
+const DefaultTTL = 4 * time.Second
+
+var (
+	xrp = data.Asset{Currency: "XRP"}
+	two = data.MustValue("2", false)
+)
+
+// Client is the part of ripple.Client needed to price assets
+type Client interface {
+	BookOffersCtx(ctx context.Context, taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*websockets.BookOffersResult, error)
+}
+
+// Rate is the price of Base in Quote, with XRP at face value rather than
+// in drops. A side is nil when no offers could be found for it.
+type Rate struct {
+	Base  data.Asset
+	Quote data.Asset
+	Bid   *data.Value // Most offered for Base, in Quote
+	Ask   *data.Value // Least Base is offered for, in Quote
+	Mid   *data.Value // Halfway between Bid and Ask, nil unless both are known
+
+	// Whether a side was priced through XRP, having no offers of its own
+	Synthetic      bool
+	LedgerSequence uint32
+	Time           time.Time // When the books were read
+}
+
+func (r Rate) String() string {
+	return fmt.Sprintf("%s/%s Bid: %s Ask: %s Ledger: %d", r.Base, r.Quote, r.Bid, r.Ask, r.LedgerSequence)
+}
+
+// setMid sets Mid from Bid and Ask
+func (r *Rate) setMid() error {
+	r.Mid = nil
+	if r.Bid == nil || r.Ask == nil {
+		return nil
+	}
+	sum, err := r.Bid.Add(*r.Ask)
+	if err != nil {
+		return err
+	}
+	r.Mid, err = sum.Divide(*two)
+	return err
+}
+
+// Rates prices pairs of assets, caching each Rate for TTL. It may be
+// used by several goroutines at once.
+type Rates struct {
+	// How long to use a Rate for. Zero means DefaultTTL.
+	TTL time.Duration
+
+	client Client
+	mu     sync.Mutex
+	cache  map[data.Book]*Rate // By the book of asks
+}
+
+func New(client Client, ttl time.Duration) *Rates {
+	return &Rates{
+		TTL:    ttl,
+		client: client,
+		cache:  make(map[data.Book]*Rate),
+	}
+}
+
+// Rate returns the price of base in quote, reading the books of the last
+// validated ledger unless it has been read within TTL
+func (r *Rates) Rate(ctx context.Context, base, quote data.Asset) (*Rate, error) {
+	key := data.Book{Pays: quote, Gets: base}
+	ttl := r.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	r.mu.Lock()
+	cached, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok && time.Since(cached.Time) < ttl {
+		rate := *cached
+		return &rate, nil
+	}
+	rate, err := r.read(ctx, base, quote)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.cache[key] = rate
+	r.mu.Unlock()
+	result := *rate
+	return &result, nil
+}
+
+// Forget removes every Rate from the cache
+func (r *Rates) Forget() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = make(map[data.Book]*Rate)
+}
+
+// read prices base in quote from the books, all of them of one ledger
+func (r *Rates) read(ctx context.Context, base, quote data.Asset) (*Rate, error) {
+	rate := &Rate{Base: base, Quote: quote, Time: time.Now()}
+	var ledger interface{} = "validated"
+	bid, ask, err := r.prices(ctx, &ledger, base, quote)
+	if err != nil {
+		return nil, err
+	}
+	rate.Bid, rate.Ask, rate.LedgerSequence = bid, ask, ledger.(uint32)
+	if (bid == nil || ask == nil) && !base.IsNative() && !quote.IsNative() {
+		// Through XRP, buying XRP with one and selling it for the other
+		baseBid, baseAsk, err := r.prices(ctx, &ledger, base, xrp)
+		if err != nil {
+			return nil, err
+		}
+		xrpBid, xrpAsk, err := r.prices(ctx, &ledger, xrp, quote)
+		if err != nil {
+			return nil, err
+		}
+		if bid == nil && baseBid != nil && xrpBid != nil {
+			if rate.Bid, err = baseBid.Multiply(*xrpBid); err != nil {
+				return nil, err
+			}
+			rate.Synthetic = true
+		}
+		if ask == nil && baseAsk != nil && xrpAsk != nil {
+			if rate.Ask, err = baseAsk.Multiply(*xrpAsk); err != nil {
+				return nil, err
+			}
+			rate.Synthetic = true
+		}
+	}
+	return rate, rate.setMid()
+}
+
+// prices returns the best bid and ask for base in quote, either nil when
+// its book has no funded offers. The first book is read from ledger,
+// which is then set to its sequence so that the rest are read from it
+// too.
+func (r *Rates) prices(ctx context.Context, ledger *interface{}, base, quote data.Asset) (*data.Value, *data.Value, error) {
+	ask, err := r.best(ctx, ledger, quote, base)
+	if err != nil {
+		return nil, nil, err
+	}
+	bid, err := r.best(ctx, ledger, base, quote)
+	if err != nil {
+		return nil, nil, err
+	}
+	var bidPrice, askPrice *data.Value
+	if ask != nil {
+		// Takers pay quote for base
+		if askPrice, err = price(*ask.TakerPays, *ask.TakerGets); err != nil {
+			return nil, nil, err
+		}
+	}
+	if bid != nil {
+		// Takers pay base for quote
+		if bidPrice, err = price(*bid.TakerGets, *bid.TakerPays); err != nil {
+			return nil, nil, err
+		}
+	}
+	return bidPrice, askPrice, nil
+}
+
+// best returns the first funded offer of a book, or nil if there isn't one
+func (r *Rates) best(ctx context.Context, ledger *interface{}, pays, gets data.Asset) (*data.OrderBookOffer, error) {
+	var zeroAccount data.Account
+	result, err := r.client.BookOffersCtx(ctx, zeroAccount, *ledger, pays, gets)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := (*ledger).(uint32); !ok {
+		if result.LedgerSequence == 0 {
+			return nil, fmt.Errorf("book_offers result has no ledger index")
+		}
+		*ledger = result.LedgerSequence
+	}
+	for i := range result.Offers {
+		offer := &result.Offers[i]
+		if offer.TakerPays == nil || offer.TakerGets == nil {
+			continue
+		}
+		if funded := offer.TakerGetsFunded; funded != nil && funded.IsZero() {
+			continue
+		}
+		return offer, nil
+	}
+	return nil, nil
+}
+
+// price returns how much of one asset is paid for each of another
+func price(paid, per data.Amount) (*data.Value, error) {
+	if per.IsZero() {
+		return nil, fmt.Errorf("Offer of nothing for %s", paid)
+	}
+	return paid.Value.Ratio(*per.Value)
+}
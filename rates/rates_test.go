@@ -0,0 +1,205 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type RatesSuite struct{}
+
+var _ = Suite(&RatesSuite{})
+
+const issuer = "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"
+
+var (
+	usd = data.Asset{Currency: "USD", Issuer: issuer}
+	eur = data.Asset{Currency: "EUR", Issuer: issuer}
+	btc = data.Asset{Currency: "BTC", Issuer: issuer}
+)
+
+// books is a Client which serves book_offers from its books, and counts
+// the requests
+type books struct {
+	sync.Mutex
+	ledger   uint32
+	books    map[data.Book][]data.OrderBookOffer
+	requests []interface{} // Ledger indexes
+}
+
+func (b *books) BookOffersCtx(ctx context.Context, taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*websockets.BookOffersResult, error) {
+	b.Lock()
+	defer b.Unlock()
+	b.requests = append(b.requests, ledgerIndex)
+	return &websockets.BookOffersResult{
+		LedgerSequence: b.ledger,
+		Offers:         b.books[data.Book{Pays: pays, Gets: gets}],
+	}, nil
+}
+
+func amount(c *C, s string) *data.Amount {
+	a, err := data.NewAmount(s)
+	c.Assert(err, IsNil)
+	return a
+}
+
+// add adds an offer of gets for pays to a book, which is funded unless
+// unfunded is set
+func (b *books) add(c *C, pays, gets string, unfunded bool) {
+	offer := data.OrderBookOffer{Offer: data.Offer{TakerPays: amount(c, pays), TakerGets: amount(c, gets)}}
+	if unfunded {
+		offer.TakerGetsFunded = amount(c, "0/"+offer.TakerGets.Currency.String()+"/"+issuer)
+	}
+	book := offer.Book()
+	if b.books == nil {
+		b.books = make(map[data.Book][]data.OrderBookOffer)
+	}
+	b.books[book] = append(b.books[book], offer)
+}
+
+func (s *RatesSuite) TestDirect(c *C) {
+	client := &books{ledger: 100}
+	client.add(c, "80/EUR/"+issuer, "100/USD/"+issuer, true)
+	client.add(c, "90/EUR/"+issuer, "100/USD/"+issuer, false)
+	client.add(c, "100/USD/"+issuer, "88/EUR/"+issuer, false)
+	r := New(client, time.Hour)
+
+	rate, err := r.Rate(context.Background(), usd, eur)
+	c.Assert(err, IsNil)
+	c.Check(rate.Ask.String(), Equals, "0.9")
+	c.Check(rate.Bid.String(), Equals, "0.88")
+	c.Check(rate.Mid.String(), Equals, "0.89")
+	c.Check(rate.Synthetic, Equals, false)
+	c.Check(rate.LedgerSequence, Equals, uint32(100))
+	c.Check(client.requests, DeepEquals, []interface{}{"validated", uint32(100)})
+
+	// Cached
+	again, err := r.Rate(context.Background(), usd, eur)
+	c.Assert(err, IsNil)
+	c.Check(again, DeepEquals, rate)
+	c.Check(client.requests, HasLen, 2)
+
+	// The other way round
+	inverse, err := r.Rate(context.Background(), eur, usd)
+	c.Assert(err, IsNil)
+	c.Check(inverse.Ask.String(), Equals, "1.136363636363636")
+	c.Check(inverse.Bid.String(), Equals, "1.111111111111111")
+	c.Check(client.requests, HasLen, 4)
+
+	r.Forget()
+	_, err = r.Rate(context.Background(), usd, eur)
+	c.Assert(err, IsNil)
+	c.Check(client.requests, HasLen, 6)
+}
+
+func (s *RatesSuite) TestTTL(c *C) {
+	client := &books{ledger: 100}
+	r := New(client, time.Millisecond)
+	rate, err := r.Rate(context.Background(), usd, eur)
+	c.Assert(err, IsNil)
+	c.Check(rate.Bid, IsNil)
+	c.Check(rate.Ask, IsNil)
+	c.Check(rate.Mid, IsNil)
+	requests := len(client.requests)
+	time.Sleep(2 * time.Millisecond)
+	_, err = r.Rate(context.Background(), usd, eur)
+	c.Assert(err, IsNil)
+	c.Check(client.requests, HasLen, 2*requests)
+}
+
+func (s *RatesSuite) TestThroughXRP(c *C) {
+	client := &books{ledger: 100}
+	// 2 XRP for a USD, 0.5 EUR for an XRP
+	client.add(c, "2/XRP", "1/USD/"+issuer, false)
+	client.add(c, "0.5/EUR/"+issuer, "1/XRP", false)
+	// 1.9 XRP for a USD, 0.45 EUR for an XRP
+	client.add(c, "1/USD/"+issuer, "1.9/XRP", false)
+	client.add(c, "1/XRP", "0.45/EUR/"+issuer, false)
+	r := New(client, 0)
+
+	rate, err := r.Rate(context.Background(), usd, eur)
+	c.Assert(err, IsNil)
+	c.Check(rate.Ask.String(), Equals, "1")
+	c.Check(rate.Bid.String(), Equals, "0.855")
+	c.Check(rate.Mid.String(), Equals, "0.9275")
+	c.Check(rate.Synthetic, Equals, true)
+	// All read from the same ledger
+	c.Check(client.requests, DeepEquals, []interface{}{"validated", uint32(100), uint32(100), uint32(100), uint32(100), uint32(100)})
+
+	// A side with offers of its own isn't synthesised
+	client.add(c, "1/USD/"+issuer, "0.8/EUR/"+issuer, false)
+	r.Forget()
+	rate, err = r.Rate(context.Background(), usd, eur)
+	c.Assert(err, IsNil)
+	c.Check(rate.Bid.String(), Equals, "0.8")
+	c.Check(rate.Ask.String(), Equals, "1")
+	c.Check(rate.Synthetic, Equals, true)
+
+	xrpRate, err := r.Rate(context.Background(), usd, xrp)
+	c.Assert(err, IsNil)
+	c.Check(xrpRate.Ask.String(), Equals, "2")
+	c.Check(xrpRate.Bid.String(), Equals, "1.9")
+	c.Check(xrpRate.Synthetic, Equals, false)
+}
+
+// readTransaction returns a validated transaction which consumes offers
+// to buy XRP with BTC, and a Client with those offers as they were before
+// it
+func readTransaction(c *C) (*websockets.TransactionStreamMsg, *books) {
+	b, err := ioutil.ReadFile("../data/testdata/transaction_offercreate.json")
+	c.Assert(err, IsNil)
+	msg := &websockets.TransactionStreamMsg{Validated: true}
+	c.Assert(json.Unmarshal(b, &msg.Transaction), IsNil)
+	msg.LedgerSequence = msg.Transaction.LedgerSequence
+	client := &books{ledger: msg.LedgerSequence - 1, books: make(map[data.Book][]data.OrderBookOffer)}
+	for i := range msg.Transaction.MetaData.AffectedNodes {
+		node, final, previous, _ := msg.Transaction.MetaData.AffectedNodes[i].AffectedNode()
+		offer, ok := final.(*data.Offer)
+		if !ok {
+			continue
+		}
+		before := *offer
+		before.LedgerIndex = node.LedgerIndex
+		before.TakerPays, before.TakerGets = previous.(*data.Offer).TakerPays, previous.(*data.Offer).TakerGets
+		book := before.Book()
+		client.books[book] = append(client.books[book], data.OrderBookOffer{Offer: before})
+	}
+	return msg, client
+}
+
+func (s *RatesSuite) TestTicker(c *C) {
+	msg, client := readTransaction(c)
+	ticker := NewTicker(client, btc, xrp)
+	c.Check(ticker.Subscription().Books, HasLen, 1)
+	transactions := make(chan *websockets.TransactionStreamMsg, 1)
+	rates := make(chan *Rate, 2)
+	done := make(chan error, 1)
+	go func() {
+		done <- ticker.Run(context.Background(), transactions, rates)
+	}()
+
+	loaded := <-rates
+	c.Check(loaded.LedgerSequence, Equals, msg.LedgerSequence-1)
+	c.Check(loaded.Base, Equals, btc)
+	c.Assert(loaded.Ask, Not(IsNil))
+
+	transactions <- msg
+	changed := <-rates
+	c.Check(changed.LedgerSequence, Equals, msg.LedgerSequence)
+	c.Check(equal(changed.Ask, loaded.Ask) && equal(changed.Bid, loaded.Bid), Equals, false)
+	current, err := ticker.Rate()
+	c.Assert(err, IsNil)
+	c.Check(equal(current.Ask, changed.Ask), Equals, true)
+
+	close(transactions)
+	c.Check(<-done, ErrorMatches, "Transactions stream closed")
+}
@@ -0,0 +1,90 @@
+package rates
+
+import (
+	"context"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/orderbook"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// Ticker prices a pair from a Mirror of its books, kept up to date by a
+// subscription to them, so its rates are never synthetic
+type Ticker struct {
+	mirror *orderbook.Mirror
+}
+
+func NewTicker(client Client, base, quote data.Asset) *Ticker {
+	return &Ticker{mirror: orderbook.NewMirror(client, base, quote)}
+}
+
+// Subscription returns the options to subscribe to the transactions
+// which change the books
+func (t *Ticker) Subscription() websockets.SubscriptionOptions {
+	return t.mirror.Subscription()
+}
+
+// Rate returns the price of the pair in the books as they are
+func (t *Ticker) Rate() (*Rate, error) {
+	return t.rate(t.mirror.Ledger())
+}
+
+func (t *Ticker) rate(ledger uint32) (*Rate, error) {
+	rate := &Rate{Base: t.mirror.Base, Quote: t.mirror.Quote, LedgerSequence: ledger, Time: time.Now()}
+	var err error
+	if ask := t.mirror.BestAsk(); ask != nil {
+		if rate.Ask, err = price(ask.TakerPays, ask.TakerGets); err != nil {
+			return nil, err
+		}
+	}
+	if bid := t.mirror.BestBid(); bid != nil {
+		if rate.Bid, err = price(bid.TakerGets, bid.TakerPays); err != nil {
+			return nil, err
+		}
+	}
+	return rate, rate.setMid()
+}
+
+// Run loads the books and applies the transactions from the stream, as
+// Mirror.Run does, sending a Rate to rates when loaded and whenever the
+// best bid or ask changes, until ctx is done or the stream is closed
+func (t *Ticker) Run(ctx context.Context, transactions <-chan *websockets.TransactionStreamMsg, rates chan<- *Rate) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	updates := t.mirror.Notify(16)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- t.mirror.Run(ctx, transactions)
+	}()
+	var last *Rate
+	for {
+		select {
+		case update := <-updates:
+			rate, err := t.rate(update.LedgerSequence)
+			if err != nil {
+				return err
+			}
+			if last != nil && equal(last.Bid, rate.Bid) && equal(last.Ask, rate.Ask) {
+				continue
+			}
+			last = rate
+			select {
+			case rates <- rate:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func equal(a, b *data.Value) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equals(*b)
+}
@@ -0,0 +1,1341 @@
+// Package rpc talks to rippled over its JSON-RPC (HTTP POST) interface.
+//
+// Client has the same method set as websockets.Remote, less the
+// subscriptions which HTTP cannot carry, and shares its command and
+// result types.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+type Client struct {
+	endpoint   string
+	http       *http.Client
+	log        websockets.Logger
+	tracer     websockets.Tracer
+	tap        websockets.Tap
+	apiVersion int
+	maxSize    int64
+}
+
+// NewClient returns a Client which posts to endpoint using http.DefaultClient
+func NewClient(endpoint string) *Client {
+	return NewClientWithHTTPClient(endpoint, http.DefaultClient)
+}
+
+// NewClientWithHTTPClient is like NewClient but sends every request with hc
+func NewClientWithHTTPClient(endpoint string, hc *http.Client) *Client {
+	return &Client{
+		endpoint: endpoint,
+		http:     hc,
+		log:      websockets.GlogLogger{},
+		maxSize:  DefaultMaxResponseSize,
+	}
+}
+
+// SetLogger sets where to log what can't be returned to a caller, which
+// is glog unless set. Call it before using the Client.
+func (c *Client) SetLogger(log websockets.Logger) {
+	c.log = log
+}
+
+// SetTracer sets what to tell of each command, to trace it. The ctx it
+// returns is that of the HTTP request, so that a Transport such as that
+// of otelhttp can pass the trace on to the server. Call it before using
+// the Client.
+func (c *Client) SetTracer(tracer websockets.Tracer) {
+	c.tracer = tracer
+}
+
+// SetTap sets what to give the body of every request and response. Call
+// it before using the Client.
+func (c *Client) SetTap(tap websockets.Tap) {
+	c.tap = tap
+}
+
+// SetAPIVersion sets the api_version sent with every command which doesn't
+// set its own. Zero, the default, leaves it to the server, which takes 1.
+// Call it before using the Client.
+func (c *Client) SetAPIVersion(version int) {
+	c.apiVersion = version
+}
+
+// SetMaxResponseSize sets the largest response to read, beyond which a
+// command fails with a *websockets.MessageTooLargeError. Zero means no
+// limit. Call it before using the Client.
+func (c *Client) SetMaxResponseSize(size int64) {
+	c.maxSize = size
+}
+
+type request struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// envelope picks the status and any error out of a response, which
+// rippled nests inside the result rather than beside it.
+type envelope struct {
+	Result struct {
+		websockets.CommandError
+		Status string `json:"status"`
+	} `json:"result"`
+}
+
+func newCommand(name string) *websockets.Command {
+	return &websockets.Command{Name: name}
+}
+
+// call posts cmd as the params of a request for cmd.Name and
+// unmarshals the response into v, which embeds cmd.
+func (c *Client) call(ctx context.Context, v interface{}, cmd *websockets.Command) (err error) {
+	if c.tracer != nil {
+		var end func(error)
+		ctx, end = c.tracer.StartCommand(ctx, websockets.NewCommandInfo(v))
+		defer func() { end(err) }()
+	}
+	if cmd.APIVersion == 0 {
+		cmd.APIVersion = c.apiVersion
+	}
+	body, err := json.Marshal(request{Method: cmd.Name, Params: []interface{}{v}})
+	if err != nil {
+		return err
+	}
+	if cmd.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cmd.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	c.tapFrame(websockets.FrameSent, body)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := c.readResponse(resp.Body)
+	if err != nil {
+		return err
+	}
+	c.log.Debug("Received", "method", cmd.Name, "response", string(b))
+	c.tapFrame(websockets.FrameReceived, b)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("rpc: %s: %s", resp.Status, bytes.TrimSpace(b))
+	}
+	var env envelope
+	if err := json.Unmarshal(b, &env); err != nil {
+		return err
+	}
+	cmd.Status = env.Result.Status
+	if env.Result.Status == "error" || env.Result.Name != "" {
+		cmd.CommandError = &env.Result.CommandError
+		return cmd.CommandError
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (c *Client) tapFrame(direction websockets.Direction, message []byte) {
+	if c.tap != nil {
+		c.tap.Frame(websockets.Frame{Direction: direction, At: time.Now(), Message: message})
+	}
+}
+
+// The largest response read unless SetMaxResponseSize is used
+const DefaultMaxResponseSize = 64 << 20
+
+// readResponse reads the whole body, unless it is larger than the limit,
+// when it is discarded and a *websockets.MessageTooLargeError returned
+func (c *Client) readResponse(body io.Reader) ([]byte, error) {
+	if c.maxSize <= 0 {
+		return ioutil.ReadAll(body)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(body, c.maxSize+1))
+	if err != nil || int64(len(b)) <= c.maxSize {
+		return b, err
+	}
+	rest, err := io.Copy(ioutil.Discard, body)
+	if err != nil {
+		return nil, err
+	}
+	return nil, &websockets.MessageTooLargeError{
+		Size:  int64(len(b)) + rest,
+		Limit: c.maxSize,
+	}
+}
+
+// Do calls method, which needn't be one the library knows, with params,
+// such as a struct with json tags or a map, and unmarshals the result into
+// result unless it is nil. Use websockets.Execute to have it typed.
+func (c *Client) Do(ctx context.Context, method string, params, result interface{}) error {
+	cmd := websockets.NewRawCommand(method, params)
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	return cmd.Decode(result)
+}
+
+// Synchronously get a single transaction
+func (c *Client) Tx(hash data.Hash256) (*websockets.TxResult, error) {
+	return c.TxCtx(context.Background(), hash)
+}
+
+// TxCtx is like Tx but gives up when ctx is done
+func (c *Client) TxCtx(ctx context.Context, hash data.Hash256) (*websockets.TxResult, error) {
+	cmd := &websockets.TxCommand{
+		Command:     newCommand("tx"),
+		Transaction: hash,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously get a page of the most recent transactions, newest first,
+// skipping start of them. Removed from rippled 2.0 onwards.
+func (c *Client) TxHistory(start uint32) (*websockets.TxHistoryResult, error) {
+	return c.TxHistoryCtx(context.Background(), start)
+}
+
+// TxHistoryCtx is like TxHistory but gives up when ctx is done
+func (c *Client) TxHistoryCtx(ctx context.Context, start uint32) (*websockets.TxHistoryResult, error) {
+	cmd := &websockets.TxHistoryCommand{
+		Command: newCommand("tx_history"),
+		Start:   start,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously get a single transaction from a particular ledger
+func (c *Client) TransactionEntry(hash data.Hash256, ledger interface{}) (*data.TransactionWithMetaData, error) {
+	return c.TransactionEntryCtx(context.Background(), hash, ledger)
+}
+
+// TransactionEntryCtx is like TransactionEntry but gives up when ctx is done
+func (c *Client) TransactionEntryCtx(ctx context.Context, hash data.Hash256, ledger interface{}) (*data.TransactionWithMetaData, error) {
+	cmd := &websockets.TransactionEntryCommand{
+		Command:     newCommand("transaction_entry"),
+		Transaction: hash,
+		LedgerIndex: ledger,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return &cmd.Result.TransactionWithMetaData, nil
+}
+
+// Retrieve all transactions for an account, calling account_tx as
+// many times as there are markers. Transactions are returned
+// asynchronously to the channel returned by this function. Errors are
+// only logged, see AccountTxCtx.
+//
+// Use minLedger -1 for the earliest ledger available.
+// Use maxLedger -1 for the most recent validated ledger.
+func (c *Client) AccountTx(account data.Account, pageSize int, minLedger, maxLedger int64) chan *data.TransactionWithMetaData {
+	ch := make(chan *data.TransactionWithMetaData)
+	txs, errs := c.AccountTxCtx(context.Background(), account, websockets.AccountTxOptions{
+		PageSize:  pageSize,
+		MinLedger: minLedger,
+		MaxLedger: maxLedger,
+	})
+	go func() {
+		defer close(ch)
+		for tx := range txs {
+			ch <- tx
+		}
+		if err := <-errs; err != nil {
+			c.log.Error("account_tx failed", "account", account, "error", err)
+		}
+	}()
+	return ch
+}
+
+// AccountTxCtx is like AccountTx but gives up when ctx is done. The error
+// which ends the transactions early, if any, is sent on the second
+// channel, which is closed after the first.
+func (c *Client) AccountTxCtx(ctx context.Context, account data.Account, options websockets.AccountTxOptions) (<-chan *data.TransactionWithMetaData, <-chan error) {
+	pages, pageErrs := c.AccountTxPagesCtx(ctx, account, options)
+	return pageTransactions(ctx, pages, pageErrs)
+}
+
+// pageTransactions sends the transactions of each page in turn, and then
+// the error which ended the pages early, if any
+func pageTransactions(ctx context.Context, pages <-chan *websockets.AccountTxResult, pageErrs <-chan error) (<-chan *data.TransactionWithMetaData, <-chan error) {
+	ch, errs := make(chan *data.TransactionWithMetaData), make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(ch)
+		for page := range pages {
+			for _, tx := range page.Transactions {
+				select {
+				case ch <- tx:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+		}
+		if err := <-pageErrs; err != nil {
+			errs <- err
+		}
+	}()
+	return ch, errs
+}
+
+// AccountTxPagesCtx is like AccountTxCtx but delivers whole pages, each
+// with the Marker to resume after it, which is nil for the last page
+func (c *Client) AccountTxPagesCtx(ctx context.Context, account data.Account, options websockets.AccountTxOptions) (<-chan *websockets.AccountTxResult, <-chan error) {
+	ch, errs := make(chan *websockets.AccountTxResult), make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(ch)
+		marker := options.Marker
+		for {
+			cmd := &websockets.AccountTxCommand{
+				Command:   newCommand("account_tx"),
+				Account:   account,
+				MinLedger: options.MinLedger,
+				MaxLedger: options.MaxLedger,
+				Binary:    options.Binary,
+				Forward:   options.Forward,
+				Limit:     options.PageSize,
+				Marker:    marker,
+			}
+			if err := c.call(ctx, cmd, cmd.Command); err != nil {
+				errs <- err
+				return
+			}
+			options.Filter(cmd.Result)
+			select {
+			case ch <- cmd.Result:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+			if cmd.Result.Marker == nil {
+				return
+			}
+			marker = cmd.Result.Marker
+		}
+	}()
+	return ch, errs
+}
+
+func newSubmitCommand(tx data.Transaction) (*websockets.SubmitCommand, error) {
+	_, raw, err := data.Raw(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &websockets.SubmitCommand{
+		Command: newCommand("submit"),
+		TxBlob:  fmt.Sprintf("%X", raw),
+	}, nil
+}
+
+// Synchronously submit a single transaction
+func (c *Client) Submit(tx data.Transaction) (*websockets.SubmitResult, error) {
+	return c.SubmitCtx(context.Background(), tx)
+}
+
+// SubmitCtx is like Submit but gives up when ctx is done
+func (c *Client) SubmitCtx(ctx context.Context, tx data.Transaction) (*websockets.SubmitResult, error) {
+	cmd, err := newSubmitCommand(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// newSubmitMultisignedCommand checks the Signers of tx against the signer
+// list found in objects, which are owned by the transaction's account.
+func newSubmitMultisignedCommand(tx data.Transaction, objects data.LedgerEntrySlice) (*websockets.SubmitMultisignedCommand, error) {
+	var list *data.SignerList
+	for _, object := range objects {
+		if l, ok := object.(*data.SignerList); ok {
+			list = l
+		}
+	}
+	if list == nil {
+		return nil, fmt.Errorf("Account %s has no signer list", tx.GetBase().Account)
+	}
+	if err := list.CheckSigners(tx); err != nil {
+		return nil, err
+	}
+	// The hash can only be known once signed, so it isn't sent
+	b, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &websockets.SubmitMultisignedCommand{Command: newCommand("submit_multisigned")}
+	if err := json.Unmarshal(b, &cmd.TxJson); err != nil {
+		return nil, err
+	}
+	delete(cmd.TxJson, "hash")
+	return cmd, nil
+}
+
+// Synchronously submit a transaction signed by several accounts, after
+// checking its Signers against the validated signer list of its account
+func (c *Client) SubmitMultisigned(tx data.Transaction) (*websockets.SubmitResult, error) {
+	return c.SubmitMultisignedCtx(context.Background(), tx)
+}
+
+// SubmitMultisignedCtx is like SubmitMultisigned but gives up when ctx is done
+func (c *Client) SubmitMultisignedCtx(ctx context.Context, tx data.Transaction) (*websockets.SubmitResult, error) {
+	if tx.GetBase() == nil {
+		return nil, fmt.Errorf("%s cannot be multisigned", tx.GetType())
+	}
+	objects, err := c.AccountObjectsCtx(ctx, tx.GetBase().Account, "signer_list", "validated")
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := newSubmitMultisignedCommand(tx, objects.AccountObjects)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously submit multiple transactions, one request at a time
+func (c *Client) SubmitBatch(txs []data.Transaction) ([]*websockets.SubmitResult, error) {
+	return c.SubmitBatchCtx(context.Background(), txs)
+}
+
+// SubmitBatchCtx is like SubmitBatch but gives up when ctx is done
+func (c *Client) SubmitBatchCtx(ctx context.Context, txs []data.Transaction) ([]*websockets.SubmitResult, error) {
+	results := make([]*websockets.SubmitResult, len(txs))
+	for i := range txs {
+		cmd, err := newSubmitCommand(txs[i])
+		if err != nil {
+			return nil, err
+		}
+		// Like Remote, a rejected submission leaves a nil result
+		// rather than abandoning the rest of the batch.
+		if err := c.call(ctx, cmd, cmd.Command); err != nil {
+			if _, ok := err.(*websockets.CommandError); !ok {
+				return nil, err
+			}
+		}
+		results[i] = cmd.Result
+	}
+	return results, nil
+}
+
+// Synchronously gets ledger entries
+func (c *Client) LedgerData(ledger interface{}, marker *data.Hash256) (*websockets.LedgerDataResult, error) {
+	return c.LedgerDataCtx(context.Background(), ledger, marker)
+}
+
+// LedgerDataCtx is like LedgerData but gives up when ctx is done
+func (c *Client) LedgerDataCtx(ctx context.Context, ledger interface{}, marker *data.Hash256) (*websockets.LedgerDataResult, error) {
+	cmd := &websockets.LedgerDataCommand{
+		Command: newCommand("ledger_data"),
+		Ledger:  ledger,
+		Marker:  marker,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+func (c *Client) streamLedgerData(ledger interface{}, ch chan data.LedgerEntrySlice) {
+	defer close(ch)
+	var marker *data.Hash256
+	var decoder data.LedgerEntryHexDecoder
+	for {
+		cmd := &websockets.BinaryLedgerDataCommand{
+			Command: newCommand("ledger_data"),
+			Ledger:  ledger,
+			Binary:  true,
+			Marker:  marker,
+		}
+		if err := c.call(context.Background(), cmd, cmd.Command); err != nil {
+			c.log.Error("ledger_data failed", "ledger", ledger, "error", err)
+			return
+		}
+		les := make(data.LedgerEntrySlice, len(cmd.Result.State))
+		for i, state := range cmd.Result.State {
+			var err error
+			les[i], err = decoder.Decode(state.Data, state.Index)
+			if err != nil {
+				c.log.Error("Unreadable ledger entry", "index", state.Index, "data", state.Data, "error", err)
+				continue
+			}
+		}
+		ch <- les
+		if cmd.Result.Marker == nil {
+			return
+		}
+		marker = cmd.Result.Marker
+	}
+}
+
+// Asynchronously retrieve all data for a ledger using the binary form
+func (c *Client) StreamLedgerData(ledger interface{}) chan data.LedgerEntrySlice {
+	ch := make(chan data.LedgerEntrySlice)
+	go c.streamLedgerData(ledger, ch)
+	return ch
+}
+
+// BinaryLedgerDataCtx gets a page of ledger entries in binary form, of at
+// most limit entries unless limit is zero
+func (c *Client) BinaryLedgerDataCtx(ctx context.Context, ledger interface{}, marker *data.Hash256, limit int) (*websockets.BinaryLedgerDataResult, error) {
+	cmd := &websockets.BinaryLedgerDataCommand{
+		Command: newCommand("ledger_data"),
+		Ledger:  ledger,
+		Binary:  true,
+		Limit:   limit,
+		Marker:  marker,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// StreamLedgerDataCtx retrieves all data for a ledger using the binary
+// form, fetching parts of the key space concurrently
+func (c *Client) StreamLedgerDataCtx(ctx context.Context, ledger interface{}, options websockets.LedgerDataOptions) *websockets.LedgerDataIterator {
+	if options.Logger == nil {
+		options.Logger = c.log
+	}
+	return websockets.StreamLedgerDataParts(ctx, c.BinaryLedgerDataCtx, ledger, options)
+}
+
+// Synchronously gets a single ledger
+func (c *Client) Ledger(ledger interface{}, transactions bool) (*websockets.LedgerResult, error) {
+	return c.LedgerCtx(context.Background(), ledger, transactions)
+}
+
+// LedgerCtx is like Ledger but gives up when ctx is done
+func (c *Client) LedgerCtx(ctx context.Context, ledger interface{}, transactions bool) (*websockets.LedgerResult, error) {
+	cmd := &websockets.LedgerCommand{
+		Command:      newCommand("ledger"),
+		Ledger:       ledger,
+		Transactions: transactions,
+		Expand:       true,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	cmd.Result.Ledger.Transactions.Sort()
+	return cmd.Result, nil
+}
+
+func (c *Client) LedgerHeader(ledger interface{}) (*websockets.LedgerHeaderResult, error) {
+	return c.LedgerHeaderCtx(context.Background(), ledger)
+}
+
+// LedgerHeaderCtx is like LedgerHeader but gives up when ctx is done
+func (c *Client) LedgerHeaderCtx(ctx context.Context, ledger interface{}) (*websockets.LedgerHeaderResult, error) {
+	cmd := &websockets.LedgerHeaderCommand{
+		Command: newCommand("ledger_header"),
+		Ledger:  ledger,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously gets a single ledger entry, decoded from its binary form
+func (c *Client) LedgerEntry(selector websockets.LedgerEntrySelector, ledger interface{}) (data.LedgerEntry, error) {
+	return c.LedgerEntryCtx(context.Background(), selector, ledger)
+}
+
+// LedgerEntryCtx is like LedgerEntry but gives up when ctx is done
+func (c *Client) LedgerEntryCtx(ctx context.Context, selector websockets.LedgerEntrySelector, ledger interface{}) (data.LedgerEntry, error) {
+	cmd := &websockets.LedgerEntryCommand{
+		Command:             newCommand("ledger_entry"),
+		LedgerEntrySelector: selector,
+		LedgerIndex:         ledger,
+		Binary:              true,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result.LedgerEntry()
+}
+
+// Synchronously requests the sequence and hash of the most recently
+// closed ledger, which may not yet be validated
+func (c *Client) LedgerClosed() (*websockets.LedgerClosedResult, error) {
+	return c.LedgerClosedCtx(context.Background())
+}
+
+// LedgerClosedCtx is like LedgerClosed but gives up when ctx is done
+func (c *Client) LedgerClosedCtx(ctx context.Context) (*websockets.LedgerClosedResult, error) {
+	cmd := &websockets.LedgerClosedCommand{
+		Command: newCommand("ledger_closed"),
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the sequence of the open ledger
+func (c *Client) LedgerCurrent() (uint32, error) {
+	return c.LedgerCurrentCtx(context.Background())
+}
+
+// LedgerCurrentCtx is like LedgerCurrent but gives up when ctx is done
+func (c *Client) LedgerCurrentCtx(ctx context.Context) (uint32, error) {
+	cmd := &websockets.LedgerCurrentCommand{
+		Command: newCommand("ledger_current"),
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return 0, err
+	}
+	return cmd.Result.LedgerSequence, nil
+}
+
+// Synchronously requests paths
+func (c *Client) RipplePathFind(src, dest data.Account, amount data.Amount, srcCurr *[]data.Currency) (*websockets.RipplePathFindResult, error) {
+	return c.RipplePathFindCtx(context.Background(), src, dest, amount, srcCurr)
+}
+
+// RipplePathFindCtx is like RipplePathFind but gives up when ctx is done
+func (c *Client) RipplePathFindCtx(ctx context.Context, src, dest data.Account, amount data.Amount, srcCurr *[]data.Currency) (*websockets.RipplePathFindResult, error) {
+	cmd := &websockets.RipplePathFindCommand{
+		Command:       newCommand("ripple_path_find"),
+		SrcAccount:    src,
+		SrcCurrencies: srcCurr,
+		DestAccount:   dest,
+		DestAmount:    amount,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests account info
+func (c *Client) AccountInfo(a data.Account) (*websockets.AccountInfoResult, error) {
+	return c.AccountInfoCtx(context.Background(), a)
+}
+
+// AccountInfoCtx is like AccountInfo but gives up when ctx is done
+func (c *Client) AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error) {
+	cmd := &websockets.AccountInfoCommand{
+		Command: newCommand("account_info"),
+		Account: a,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests account line info
+func (c *Client) AccountLines(account data.Account, ledgerIndex interface{}) (*websockets.AccountLinesResult, error) {
+	return c.AccountLinesCtx(context.Background(), account, ledgerIndex)
+}
+
+// AccountLinesCtx is like AccountLines but gives up when ctx is done
+func (c *Client) AccountLinesCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountLinesResult, error) {
+	var (
+		lines  data.AccountLineSlice
+		marker *data.Hash256
+	)
+	for {
+		cmd := &websockets.AccountLinesCommand{
+			Command:     newCommand("account_lines"),
+			Account:     account,
+			Limit:       400,
+			Marker:      marker,
+			LedgerIndex: ledgerIndex,
+		}
+		if err := c.call(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		if cmd.Result.Marker == nil {
+			cmd.Result.Lines = append(lines, cmd.Result.Lines...)
+			cmd.Result.Lines.SortByCurrencyAmount()
+			return cmd.Result, nil
+		}
+		lines = append(lines, cmd.Result.Lines...)
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests account offers
+func (c *Client) AccountOffers(account data.Account, ledgerIndex interface{}) (*websockets.AccountOffersResult, error) {
+	return c.AccountOffersCtx(context.Background(), account, ledgerIndex)
+}
+
+// AccountOffersCtx is like AccountOffers but gives up when ctx is done
+func (c *Client) AccountOffersCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountOffersResult, error) {
+	var (
+		offers data.AccountOfferSlice
+		marker *data.Hash256
+	)
+	for {
+		cmd := &websockets.AccountOffersCommand{
+			Command:     newCommand("account_offers"),
+			Account:     account,
+			Limit:       400,
+			Marker:      marker,
+			LedgerIndex: ledgerIndex,
+		}
+		if err := c.call(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		if cmd.Result.Marker == nil {
+			cmd.Result.Offers = append(offers, cmd.Result.Offers...)
+			sort.Sort(cmd.Result.Offers)
+			return cmd.Result, nil
+		}
+		offers = append(offers, cmd.Result.Offers...)
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests the ledger entries owned by an account,
+// optionally only those of one type.
+func (c *Client) AccountObjects(account data.Account, objectType string, ledgerIndex interface{}) (*websockets.AccountObjectsResult, error) {
+	return c.AccountObjectsCtx(context.Background(), account, objectType, ledgerIndex)
+}
+
+// AccountObjectsCtx is like AccountObjects but gives up when ctx is done
+func (c *Client) AccountObjectsCtx(ctx context.Context, account data.Account, objectType string, ledgerIndex interface{}) (*websockets.AccountObjectsResult, error) {
+	var (
+		objects data.LedgerEntrySlice
+		marker  interface{}
+	)
+	for {
+		cmd := &websockets.AccountObjectsCommand{
+			Command:     newCommand("account_objects"),
+			Account:     account,
+			Type:        objectType,
+			Limit:       400,
+			Marker:      marker,
+			LedgerIndex: ledgerIndex,
+		}
+		if err := c.call(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		objects = append(objects, cmd.Result.AccountObjects...)
+		if cmd.Result.Marker == nil {
+			cmd.Result.AccountObjects = objects
+			return cmd.Result, nil
+		}
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests the payment channels opened by an account,
+// optionally only those to destination
+func (c *Client) AccountChannels(account data.Account, destination *data.Account, ledgerIndex interface{}) (*websockets.AccountChannelsResult, error) {
+	return c.AccountChannelsCtx(context.Background(), account, destination, ledgerIndex)
+}
+
+// AccountChannelsCtx is like AccountChannels but gives up when ctx is done
+func (c *Client) AccountChannelsCtx(ctx context.Context, account data.Account, destination *data.Account, ledgerIndex interface{}) (*websockets.AccountChannelsResult, error) {
+	var (
+		channels []websockets.PaymentChannel
+		marker   interface{}
+	)
+	for {
+		cmd := &websockets.AccountChannelsCommand{
+			Command:     newCommand("account_channels"),
+			Account:     account,
+			Destination: destination,
+			Limit:       400,
+			Marker:      marker,
+			LedgerIndex: ledgerIndex,
+		}
+		if err := c.call(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		channels = append(channels, cmd.Result.Channels...)
+		if cmd.Result.Marker == nil {
+			cmd.Result.Channels = channels
+			return cmd.Result, nil
+		}
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests the currencies an account can send and receive
+func (c *Client) AccountCurrencies(account data.Account, ledgerIndex interface{}) (*websockets.AccountCurrenciesResult, error) {
+	return c.AccountCurrenciesCtx(context.Background(), account, ledgerIndex)
+}
+
+// AccountCurrenciesCtx is like AccountCurrencies but gives up when ctx is done
+func (c *Client) AccountCurrenciesCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountCurrenciesResult, error) {
+	cmd := &websockets.AccountCurrenciesCommand{
+		Command:     newCommand("account_currencies"),
+		Account:     account,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests all the NFTs owned by an account
+func (c *Client) AccountNFTs(account data.Account, ledgerIndex interface{}) (*websockets.AccountNFTsResult, error) {
+	return c.AccountNFTsCtx(context.Background(), account, ledgerIndex)
+}
+
+// AccountNFTsCtx is like AccountNFTs but gives up when ctx is done
+func (c *Client) AccountNFTsCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountNFTsResult, error) {
+	var (
+		nfts   []websockets.AccountNFT
+		marker interface{}
+	)
+	for {
+		cmd := &websockets.AccountNFTsCommand{
+			Command:     newCommand("account_nfts"),
+			Account:     account,
+			Limit:       400,
+			Marker:      marker,
+			LedgerIndex: ledgerIndex,
+		}
+		if err := c.call(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		nfts = append(nfts, cmd.Result.NFTs...)
+		if cmd.Result.Marker == nil {
+			cmd.Result.NFTs = nfts
+			return cmd.Result, nil
+		}
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests all the offers to buy an NFT
+func (c *Client) NFTBuyOffers(nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTOffersResult, error) {
+	return c.nftOffers(context.Background(), "nft_buy_offers", nftID, ledgerIndex)
+}
+
+// NFTBuyOffersCtx is like NFTBuyOffers but gives up when ctx is done
+func (c *Client) NFTBuyOffersCtx(ctx context.Context, nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTOffersResult, error) {
+	return c.nftOffers(ctx, "nft_buy_offers", nftID, ledgerIndex)
+}
+
+// Synchronously requests all the offers to sell an NFT
+func (c *Client) NFTSellOffers(nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTOffersResult, error) {
+	return c.nftOffers(context.Background(), "nft_sell_offers", nftID, ledgerIndex)
+}
+
+// NFTSellOffersCtx is like NFTSellOffers but gives up when ctx is done
+func (c *Client) NFTSellOffersCtx(ctx context.Context, nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTOffersResult, error) {
+	return c.nftOffers(ctx, "nft_sell_offers", nftID, ledgerIndex)
+}
+
+func (c *Client) nftOffers(ctx context.Context, name string, nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTOffersResult, error) {
+	var (
+		offers []websockets.NFTOffer
+		marker interface{}
+	)
+	for {
+		cmd := &websockets.NFTOffersCommand{
+			Command:     newCommand(name),
+			NFTokenID:   nftID,
+			Limit:       400,
+			Marker:      marker,
+			LedgerIndex: ledgerIndex,
+		}
+		if err := c.call(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		offers = append(offers, cmd.Result.Offers...)
+		if cmd.Result.Marker == nil {
+			cmd.Result.Offers = offers
+			return cmd.Result, nil
+		}
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests the current state of an NFT, including burned
+// ones. Only Clio servers support nft_info.
+func (c *Client) NFTInfo(nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTInfoResult, error) {
+	return c.NFTInfoCtx(context.Background(), nftID, ledgerIndex)
+}
+
+// NFTInfoCtx is like NFTInfo but gives up when ctx is done
+func (c *Client) NFTInfoCtx(ctx context.Context, nftID data.Hash256, ledgerIndex interface{}) (*websockets.NFTInfoResult, error) {
+	cmd := &websockets.NFTInfoCommand{
+		Command:     newCommand("nft_info"),
+		NFTokenID:   nftID,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// NFTHistoryCtx requests the transactions which affected an NFT, calling
+// nft_history as many times as there are markers. The error which ends the
+// transactions early, if any, is sent on the second channel, which is
+// closed after the first. Only Clio servers support nft_history.
+func (c *Client) NFTHistoryCtx(ctx context.Context, nftID data.Hash256, options websockets.AccountTxOptions) (<-chan *data.TransactionWithMetaData, <-chan error) {
+	pages, pageErrs := c.NFTHistoryPagesCtx(ctx, nftID, options)
+	return pageTransactions(ctx, pages, pageErrs)
+}
+
+// NFTHistoryPagesCtx is like NFTHistoryCtx but delivers whole pages, each
+// with the Marker to resume after it, which is nil for the last page
+func (c *Client) NFTHistoryPagesCtx(ctx context.Context, nftID data.Hash256, options websockets.AccountTxOptions) (<-chan *websockets.AccountTxResult, <-chan error) {
+	ch, errs := make(chan *websockets.AccountTxResult), make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(ch)
+		marker := options.Marker
+		for {
+			cmd := &websockets.NFTHistoryCommand{
+				Command:   newCommand("nft_history"),
+				NFTokenID: nftID,
+				MinLedger: options.MinLedger,
+				MaxLedger: options.MaxLedger,
+				Binary:    options.Binary,
+				Forward:   options.Forward,
+				Limit:     options.PageSize,
+				Marker:    marker,
+			}
+			if err := c.call(ctx, cmd, cmd.Command); err != nil {
+				errs <- err
+				return
+			}
+			options.Filter(cmd.Result)
+			select {
+			case ch <- cmd.Result:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+			if cmd.Result.Marker == nil {
+				return
+			}
+			marker = cmd.Result.Marker
+		}
+	}()
+	return ch, errs
+}
+
+// Synchronously requests all the NFTs of an issuer, burned ones included,
+// or only those of taxon if it isn't nil. Only Clio servers support
+// nfts_by_issuer.
+func (c *Client) NFTsByIssuer(issuer data.Account, taxon *uint32, ledgerIndex interface{}) (*websockets.NFTsByIssuerResult, error) {
+	return c.NFTsByIssuerCtx(context.Background(), issuer, taxon, ledgerIndex)
+}
+
+// NFTsByIssuerCtx is like NFTsByIssuer but gives up when ctx is done
+func (c *Client) NFTsByIssuerCtx(ctx context.Context, issuer data.Account, taxon *uint32, ledgerIndex interface{}) (*websockets.NFTsByIssuerResult, error) {
+	var (
+		nfts   []websockets.NFTInfoResult
+		marker interface{}
+	)
+	for {
+		cmd := &websockets.NFTsByIssuerCommand{
+			Command:     newCommand("nfts_by_issuer"),
+			Issuer:      issuer,
+			Taxon:       taxon,
+			LedgerIndex: ledgerIndex,
+			Limit:       100,
+			Marker:      marker,
+		}
+		if err := c.call(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		nfts = append(nfts, cmd.Result.NFTs...)
+		if cmd.Result.Marker == nil {
+			cmd.Result.NFTs = nfts
+			return cmd.Result, nil
+		}
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests the last validated ledger which closed at or
+// before date, or the last validated ledger if date is zero. Only Clio
+// servers support ledger_index.
+func (c *Client) LedgerIndex(date time.Time) (*websockets.LedgerIndexResult, error) {
+	return c.LedgerIndexCtx(context.Background(), date)
+}
+
+// LedgerIndexCtx is like LedgerIndex but gives up when ctx is done
+func (c *Client) LedgerIndexCtx(ctx context.Context, date time.Time) (*websockets.LedgerIndexResult, error) {
+	cmd := &websockets.LedgerIndexCommand{
+		Command: newCommand("ledger_index"),
+	}
+	if !date.IsZero() {
+		cmd.Date = date.UTC().Format(websockets.LedgerIndexDateFormat)
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously asks the server to sign a claim for amount, a native
+// value, from channel. The secret is sent to the server.
+func (c *Client) ChannelAuthorize(channel data.Hash256, amount data.Value, secret string) (data.VariableLength, error) {
+	return c.ChannelAuthorizeCtx(context.Background(), channel, amount, secret)
+}
+
+// ChannelAuthorizeCtx is like ChannelAuthorize but gives up when ctx is done
+func (c *Client) ChannelAuthorizeCtx(ctx context.Context, channel data.Hash256, amount data.Value, secret string) (data.VariableLength, error) {
+	cmd := &websockets.ChannelAuthorizeCommand{
+		Command:   newCommand("channel_authorize"),
+		ChannelId: channel,
+		Amount:    amount,
+		Secret:    secret,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result.Signature, nil
+}
+
+// Synchronously checks a claim's signature
+func (c *Client) ChannelVerify(channel data.Hash256, amount data.Value, publicKey data.PublicKey, signature data.VariableLength) (bool, error) {
+	return c.ChannelVerifyCtx(context.Background(), channel, amount, publicKey, signature)
+}
+
+// ChannelVerifyCtx is like ChannelVerify but gives up when ctx is done
+func (c *Client) ChannelVerifyCtx(ctx context.Context, channel data.Hash256, amount data.Value, publicKey data.PublicKey, signature data.VariableLength) (bool, error) {
+	cmd := &websockets.ChannelVerifyCommand{
+		Command:   newCommand("channel_verify"),
+		ChannelId: channel,
+		Amount:    amount,
+		PublicKey: publicKey,
+		Signature: signature,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return false, err
+	}
+	return cmd.Result.Verified, nil
+}
+
+// Synchronously checks whether source may pay destination directly,
+// which is always true unless destination requires DepositAuth
+func (c *Client) DepositAuthorized(source, destination data.Account, ledgerIndex interface{}) (bool, error) {
+	return c.DepositAuthorizedCtx(context.Background(), source, destination, ledgerIndex)
+}
+
+// DepositAuthorizedCtx is like DepositAuthorized but gives up when ctx is done
+func (c *Client) DepositAuthorizedCtx(ctx context.Context, source, destination data.Account, ledgerIndex interface{}) (bool, error) {
+	cmd := &websockets.DepositAuthorizedCommand{
+		Command:     newCommand("deposit_authorized"),
+		Source:      source,
+		Destination: destination,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return false, err
+	}
+	return cmd.Result.Authorized, nil
+}
+
+// Synchronously requests the totals issued by a gateway, less those
+// held by its hotWallets
+func (c *Client) GatewayBalances(account data.Account, hotWallets []data.Account, ledgerIndex interface{}) (*websockets.GatewayBalancesResult, error) {
+	return c.GatewayBalancesCtx(context.Background(), account, hotWallets, ledgerIndex)
+}
+
+// GatewayBalancesCtx is like GatewayBalances but gives up when ctx is done
+func (c *Client) GatewayBalancesCtx(ctx context.Context, account data.Account, hotWallets []data.Account, ledgerIndex interface{}) (*websockets.GatewayBalancesResult, error) {
+	cmd := &websockets.GatewayBalancesCommand{
+		Command:     newCommand("gateway_balances"),
+		Account:     account,
+		HotWallets:  hotWallets,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously checks an account's DefaultRipple and NoRipple flags
+// against those recommended for role, which is "gateway" or "user"
+func (c *Client) NoRippleCheck(account data.Account, role string, ledgerIndex interface{}) (*websockets.NoRippleCheckResult, error) {
+	return c.NoRippleCheckCtx(context.Background(), account, role, ledgerIndex)
+}
+
+// NoRippleCheckCtx is like NoRippleCheck but gives up when ctx is done
+func (c *Client) NoRippleCheckCtx(ctx context.Context, account data.Account, role string, ledgerIndex interface{}) (*websockets.NoRippleCheckResult, error) {
+	cmd := &websockets.NoRippleCheckCommand{
+		Command:      newCommand("noripple_check"),
+		Account:      account,
+		Role:         role,
+		Transactions: true,
+		LedgerIndex:  ledgerIndex,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+func (c *Client) BookOffers(taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*websockets.BookOffersResult, error) {
+	return c.BookOffersCtx(context.Background(), taker, ledgerIndex, pays, gets)
+}
+
+// BookOffersCtx is like BookOffers but gives up when ctx is done
+func (c *Client) BookOffersCtx(ctx context.Context, taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*websockets.BookOffersResult, error) {
+	cmd := &websockets.BookOffersCommand{
+		Command:     newCommand("book_offers"),
+		LedgerIndex: ledgerIndex,
+		Taker:       taker,
+		TakerPays:   pays,
+		TakerGets:   gets,
+		Limit:       5000,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the state of the AMM for a pair of assets
+func (c *Client) AMMInfo(asset, asset2 data.Asset, ledgerIndex interface{}) (*websockets.AMMInfoResult, error) {
+	return c.AMMInfoCtx(context.Background(), asset, asset2, ledgerIndex)
+}
+
+// AMMInfoCtx is like AMMInfo but gives up when ctx is done
+func (c *Client) AMMInfoCtx(ctx context.Context, asset, asset2 data.Asset, ledgerIndex interface{}) (*websockets.AMMInfoResult, error) {
+	cmd := &websockets.AMMInfoCommand{
+		Command:     newCommand("amm_info"),
+		Asset:       &asset,
+		Asset2:      &asset2,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the state of the AMM with the given account
+func (c *Client) AMMInfoByAccount(ammAccount data.Account, ledgerIndex interface{}) (*websockets.AMMInfoResult, error) {
+	return c.AMMInfoByAccountCtx(context.Background(), ammAccount, ledgerIndex)
+}
+
+// AMMInfoByAccountCtx is like AMMInfoByAccount but gives up when ctx is done
+func (c *Client) AMMInfoByAccountCtx(ctx context.Context, ammAccount data.Account, ledgerIndex interface{}) (*websockets.AMMInfoResult, error) {
+	cmd := &websockets.AMMInfoCommand{
+		Command:     newCommand("amm_info"),
+		AMMAccount:  &ammAccount,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the order book changes made by a ledger
+func (c *Client) BookChanges(ledger interface{}) (*websockets.BookChangesResult, error) {
+	return c.BookChangesCtx(context.Background(), ledger)
+}
+
+// BookChangesCtx is like BookChanges but gives up when ctx is done
+func (c *Client) BookChangesCtx(ctx context.Context, ledger interface{}) (*websockets.BookChangesResult, error) {
+	cmd := &websockets.BookChangesCommand{
+		Command:     newCommand("book_changes"),
+		LedgerIndex: ledger,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the human readable status of the server
+func (c *Client) ServerInfo() (*websockets.ServerInfo, error) {
+	return c.ServerInfoCtx(context.Background())
+}
+
+// ServerInfoCtx is like ServerInfo but gives up when ctx is done
+func (c *Client) ServerInfoCtx(ctx context.Context) (*websockets.ServerInfo, error) {
+	cmd := &websockets.ServerInfoCommand{
+		Command: newCommand("server_info"),
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return &cmd.Result.Info, nil
+}
+
+// Capabilities probes the server with server_info, each time it is called,
+// as each request may reach a different server behind a load balancer
+func (c *Client) Capabilities(ctx context.Context) (*websockets.Capabilities, error) {
+	info, err := c.ServerInfoCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return info.Capabilities(), nil
+}
+
+// Synchronously requests the machine readable status of the server
+func (c *Client) ServerState() (*websockets.ServerState, error) {
+	return c.ServerStateCtx(context.Background())
+}
+
+// ServerStateCtx is like ServerState but gives up when ctx is done
+func (c *Client) ServerStateCtx(ctx context.Context) (*websockets.ServerState, error) {
+	cmd := &websockets.ServerStateCommand{
+		Command: newCommand("server_state"),
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return &cmd.Result.State, nil
+}
+
+// Synchronously requests the manifest of a validator
+func (c *Client) Manifest(publicKey string) (*websockets.ManifestResult, error) {
+	return c.ManifestCtx(context.Background(), publicKey)
+}
+
+// ManifestCtx is like Manifest but gives up when ctx is done
+func (c *Client) ManifestCtx(ctx context.Context, publicKey string) (*websockets.ManifestResult, error) {
+	cmd := &websockets.ManifestCommand{
+		Command:   newCommand("manifest"),
+		PublicKey: publicKey,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the validators trusted by the server (admin only)
+func (c *Client) Validators() (*websockets.ValidatorsResult, error) {
+	return c.ValidatorsCtx(context.Background())
+}
+
+// ValidatorsCtx is like Validators but gives up when ctx is done
+func (c *Client) ValidatorsCtx(ctx context.Context) (*websockets.ValidatorsResult, error) {
+	cmd := &websockets.ValidatorsCommand{
+		Command: newCommand("validators"),
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the status of the sites the server fetches
+// validator lists from (admin only)
+func (c *Client) ValidatorListSites() ([]websockets.ValidatorSite, error) {
+	return c.ValidatorListSitesCtx(context.Background())
+}
+
+// ValidatorListSitesCtx is like ValidatorListSites but gives up when ctx is done
+func (c *Client) ValidatorListSitesCtx(ctx context.Context) ([]websockets.ValidatorSite, error) {
+	cmd := &websockets.ValidatorListSitesCommand{
+		Command: newCommand("validator_list_sites"),
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result.Sites, nil
+}
+
+// Synchronously requests the status of an amendment, given its name or
+// ID, or of every amendment when feature is empty
+func (c *Client) Feature(feature string) (websockets.Features, error) {
+	return c.FeatureCtx(context.Background(), feature)
+}
+
+// FeatureCtx is like Feature but gives up when ctx is done
+func (c *Client) FeatureCtx(ctx context.Context, feature string) (websockets.Features, error) {
+	cmd := &websockets.FeatureCommand{
+		Command: newCommand("feature"),
+		Feature: feature,
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result.Features, nil
+}
+
+// Synchronously closes the open ledger of a server in standalone mode,
+// returning the index of the new open ledger (admin only)
+func (c *Client) LedgerAccept() (uint32, error) {
+	return c.LedgerAcceptCtx(context.Background())
+}
+
+// LedgerAcceptCtx is like LedgerAccept but gives up when ctx is done
+func (c *Client) LedgerAcceptCtx(ctx context.Context) (uint32, error) {
+	cmd := &websockets.LedgerAcceptCommand{
+		Command: newCommand("ledger_accept"),
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return 0, err
+	}
+	return cmd.Result.LedgerSequence, nil
+}
+
+func (c *Client) Fee() (*websockets.FeeResult, error) {
+	return c.FeeCtx(context.Background())
+}
+
+// FeeCtx is like Fee but gives up when ctx is done
+func (c *Client) FeeCtx(ctx context.Context) (*websockets.FeeResult, error) {
+	cmd := &websockets.FeeCommand{
+		Command: newCommand("fee"),
+	}
+	if err := c.call(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
@@ -0,0 +1,289 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type ClientSuite struct{}
+
+var _ = Suite(&ClientSuite{})
+
+type received struct {
+	Method string                   `json:"method"`
+	Params []map[string]interface{} `json:"params"`
+}
+
+// fixtureServer answers every request with the contents of path and
+// records what it was asked.
+func fixtureServer(c *C, path string, got *received) *httptest.Server {
+	b, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := json.NewDecoder(req.Body).Decode(got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write(b)
+	}))
+}
+
+func (s *ClientSuite) TestAccountInfo(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/account_info.json", &got)
+	defer server.Close()
+
+	account, err := data.NewAccountFromAddress("rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(err, IsNil)
+	result, err := NewClient(server.URL).AccountInfo(*account)
+	c.Assert(err, IsNil)
+
+	c.Check(got.Method, Equals, "account_info")
+	c.Assert(got.Params, HasLen, 1)
+	c.Check(got.Params[0]["account"], Equals, "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+
+	c.Check(result.LedgerSequence, Equals, uint32(7636529))
+	c.Check(*result.AccountData.Sequence, Equals, uint32(546))
+	c.Check(result.AccountData.Balance.String(), Equals, "10321199.422233")
+}
+
+func (s *ClientSuite) TestCommandError(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/account_not_found.json", &got)
+	defer server.Close()
+
+	result, err := NewClient(server.URL).AccountInfo(data.Account{})
+	c.Assert(result, IsNil)
+	cmdErr, ok := err.(*websockets.CommandError)
+	c.Assert(ok, Equals, true)
+	c.Check(cmdErr.Name, Equals, "actNotFound")
+	c.Check(cmdErr.Code, Equals, 19)
+	c.Check(cmdErr.Message, Equals, "Account not found.")
+}
+
+func (s *ClientSuite) TestAccountTxError(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/account_not_found.json", &got)
+	defer server.Close()
+
+	marker := map[string]interface{}{"ledger": 7284002.0, "seq": 7.0}
+	txs, errs := NewClient(server.URL).AccountTxCtx(context.Background(), data.Account{}, websockets.AccountTxOptions{MinLedger: -1, MaxLedger: -1, Marker: marker})
+	for range txs {
+		c.Fatal("transaction despite error")
+	}
+	c.Check(<-errs, ErrorMatches, ".*actNotFound.*")
+	c.Check(got.Method, Equals, "account_tx")
+	c.Assert(got.Params, HasLen, 1)
+	c.Check(got.Params[0]["marker"], DeepEquals, marker)
+}
+
+func (s *ClientSuite) TestHTTPError(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL).Fee()
+	c.Assert(err, ErrorMatches, "rpc: 503 Service Unavailable: overloaded")
+}
+
+func (s *ClientSuite) TestResponseTooLarge(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"result":{"status":"success","drops":{"base_fee":"10"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	client.SetMaxResponseSize(57)
+	_, err := client.Fee()
+	c.Check(err, IsNil)
+	client.SetMaxResponseSize(56)
+	_, err = client.Fee()
+	c.Check(err, DeepEquals, &websockets.MessageTooLargeError{Size: 57, Limit: 56})
+}
+
+func (s *ClientSuite) TestContextCancellation(c *C) {
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-stop:
+		}
+	}))
+	defer server.Close()
+	defer close(stop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := NewClient(server.URL).TxCtx(ctx, data.Hash256{})
+	c.Assert(err, NotNil)
+	c.Assert(ctx.Err(), Equals, context.DeadlineExceeded)
+}
+
+func (s *ClientSuite) TestLedgerClosedAndCurrent(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/ledger_closed.json", &got)
+	defer server.Close()
+	closed, err := NewClient(server.URL).LedgerClosed()
+	c.Assert(err, IsNil)
+	c.Check(got.Method, Equals, "ledger_closed")
+	c.Check(closed.LedgerSequence, Equals, uint32(82184562))
+	c.Check(closed.Hash.String(), Equals, "17ACB57A0F73B5160713E81FE72B2AC9F6064541004E272BD09F257D57C30C02")
+
+	server = fixtureServer(c, "testdata/ledger_current.json", &got)
+	defer server.Close()
+	current, err := NewClient(server.URL).LedgerCurrent()
+	c.Assert(err, IsNil)
+	c.Check(got.Method, Equals, "ledger_current")
+	c.Check(current, Equals, uint32(82184563))
+}
+
+func (s *ClientSuite) TestAPIVersion(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/ledger_closed.json", &got)
+	defer server.Close()
+	client := NewClient(server.URL)
+	_, err := client.LedgerClosed()
+	c.Assert(err, IsNil)
+	c.Check(got.Params[0]["api_version"], IsNil)
+
+	client.SetAPIVersion(2)
+	_, err = client.LedgerClosed()
+	c.Assert(err, IsNil)
+	c.Check(got.Params[0]["api_version"], Equals, 2.0)
+}
+
+func (s *ClientSuite) TestLedgerIndex(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/ledger_index.json", &got)
+	defer server.Close()
+	result, err := NewClient(server.URL).LedgerIndex(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	c.Assert(err, IsNil)
+	c.Check(got.Method, Equals, "ledger_index")
+	c.Check(got.Params[0]["date"], Equals, "2024-05-01T12:00:00Z")
+	c.Check(result.LedgerSequence, Equals, uint32(90000))
+	c.Check(result.Closed.Equal(time.Date(2024, 5, 1, 11, 59, 59, 0, time.UTC)), Equals, true)
+}
+
+func (s *ClientSuite) TestDo(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/ledger_closed.json", &got)
+	defer server.Close()
+	closed, err := websockets.Execute[websockets.LedgerClosedResult](context.Background(), NewClient(server.URL), "ledger_closed", map[string]bool{"full": false})
+	c.Assert(err, IsNil)
+	c.Check(got.Method, Equals, "ledger_closed")
+	c.Check(got.Params[0]["full"], Equals, false)
+	c.Check(closed.LedgerSequence, Equals, uint32(82184562))
+}
+
+func (s *ClientSuite) TestTap(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/ledger_closed.json", &got)
+	defer server.Close()
+	var frames []websockets.Frame
+	client := NewClient(server.URL)
+	client.SetTap(websockets.TapFunc(func(frame websockets.Frame) {
+		frames = append(frames, frame)
+	}))
+	_, err := client.LedgerClosed()
+	c.Assert(err, IsNil)
+	c.Assert(frames, HasLen, 2)
+	c.Check(frames[0].Direction, Equals, websockets.FrameSent)
+	c.Check(string(frames[0].Message), Matches, `\{"method":"ledger_closed",.*`)
+	c.Check(frames[1].Direction, Equals, websockets.FrameReceived)
+	c.Check(string(frames[1].Message), Matches, `(?s).*"ledger_index" : 82184562.*`)
+}
+
+func (s *ClientSuite) TestDepositAuthorized(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/deposit_authorized.json", &got)
+	defer server.Close()
+
+	source, err := data.NewAccountFromAddress("rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de")
+	c.Assert(err, IsNil)
+	destination, err := data.NewAccountFromAddress("rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8")
+	c.Assert(err, IsNil)
+	authorized, err := NewClient(server.URL).DepositAuthorized(*source, *destination, "validated")
+	c.Assert(err, IsNil)
+	c.Check(authorized, Equals, true)
+	c.Check(got.Method, Equals, "deposit_authorized")
+	c.Check(got.Params[0]["source_account"], Equals, "rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de")
+	c.Check(got.Params[0]["destination_account"], Equals, "rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8")
+	c.Check(got.Params[0]["ledger_index"], Equals, "validated")
+}
+
+func (s *ClientSuite) TestChannelVerify(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/channel_verify.json", &got)
+	defer server.Close()
+
+	channel, err := data.NewHash256("5DB01B7FFED6B67E6B0414DED11E051D2EE2B7619CE0EAA6286D67A3A4D5BDB3")
+	c.Assert(err, IsNil)
+	amount, err := data.NewNativeValue(1000000)
+	c.Assert(err, IsNil)
+	var publicKey data.PublicKey
+	publicKey[0] = 0xED
+	verified, err := NewClient(server.URL).ChannelVerify(*channel, *amount, publicKey, data.VariableLength{0x30, 0x44})
+	c.Assert(err, IsNil)
+	c.Check(verified, Equals, true)
+	c.Check(got.Method, Equals, "channel_verify")
+	c.Check(got.Params[0]["channel_id"], Equals, channel.String())
+	c.Check(got.Params[0]["amount"], Equals, "1000000")
+	c.Check(got.Params[0]["signature"], Equals, "3044")
+}
+
+func (s *ClientSuite) TestFeature(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/feature.json", &got)
+	defer server.Close()
+
+	features, err := NewClient(server.URL).Feature("AMM")
+	c.Assert(err, IsNil)
+	c.Check(got.Method, Equals, "feature")
+	c.Check(got.Params[0]["feature"], Equals, "AMM")
+	c.Assert(features, HasLen, 1)
+	c.Check(features.Enabled("AMM"), Equals, true)
+}
+
+func (s *ClientSuite) TestNFTSellOffers(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/nft_sell_offers.json", &got)
+	defer server.Close()
+
+	id, err := data.NewHash256("000801F40A20B3C85F482532A9578DBB3950B85CA06594D1A048C08800000007")
+	c.Assert(err, IsNil)
+	result, err := NewClient(server.URL).NFTSellOffers(*id, "validated")
+	c.Assert(err, IsNil)
+	c.Check(got.Method, Equals, "nft_sell_offers")
+	c.Check(got.Params[0]["nft_id"], Equals, id.String())
+	c.Assert(result.Offers, HasLen, 1)
+	c.Check(result.Offers[0].Flags&data.LsSellNFToken, Equals, data.LsSellNFToken)
+}
+
+func (s *ClientSuite) TestAMMInfo(c *C) {
+	var got received
+	server := fixtureServer(c, "testdata/amm_info.json", &got)
+	defer server.Close()
+
+	xrp := data.Asset{Currency: "XRP"}
+	tst := data.Asset{Currency: "TST", Issuer: "rP9jPyP5kyvFRb6ZiRghAGw5u8SGAmU4bd"}
+	result, err := NewClient(server.URL).AMMInfo(xrp, tst, "current")
+	c.Assert(err, IsNil)
+	c.Check(got.Method, Equals, "amm_info")
+	c.Check(got.Params[0]["asset"], DeepEquals, map[string]interface{}{"currency": "XRP"})
+	c.Check(got.Params[0]["asset2"], DeepEquals, map[string]interface{}{"currency": "TST", "issuer": "rP9jPyP5kyvFRb6ZiRghAGw5u8SGAmU4bd"})
+	c.Check(got.Params[0]["amm_account"], IsNil)
+	c.Check(result.AMM.TradingFee, Equals, uint16(600))
+	c.Check(result.AMM.AuctionSlot, IsNil)
+}
@@ -0,0 +1,125 @@
+package shamap
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// The types which end each node of a proof, as in rippled's wire format
+const (
+	wireAccountState        byte = 1
+	wireInner               byte = 2
+	wireTransactionWithMeta byte = 4
+)
+
+// maxDepth is the depth of a leaf below the deepest possible inner node
+const maxDepth = 64
+
+// Proof is the path from the leaf of an item up to the root of a map,
+// each node followed by its type, as rippled builds them with getProofPath
+// for the proof path messages of the peer protocol. Anyone holding the
+// root hash, such as the StateHash of a trusted ledger header, can check
+// a single item with it rather than downloading the whole map.
+type Proof []data.VariableLength
+
+// Proof returns the proof of the item with key, which must have been
+// added with its node
+func (m *Map) Proof(key data.Hash256) (Proof, error) {
+	var path Proof
+	n := &m.root
+	for depth := 0; ; depth++ {
+		path = append(Proof{n.wire()}, path...)
+		switch child := n.children[nibble(key, depth)].(type) {
+		case *leaf:
+			if child.key != key {
+				return nil, fmt.Errorf("No item %s in the map", key)
+			}
+			if child.data == nil {
+				return nil, fmt.Errorf("Node of item %s is not known", key)
+			}
+			return append(Proof{append(child.data[:len(child.data):len(child.data)], child.typ)}, path...), nil
+		case *inner:
+			n = child
+		default:
+			return nil, fmt.Errorf("No item %s in the map", key)
+		}
+	}
+}
+
+func (n *inner) wire() []byte {
+	b := make([]byte, 0, len(n.children)*32+1)
+	for _, child := range n.children {
+		var h data.Hash256
+		if child != nil {
+			h = child.hash()
+		}
+		b = append(b, h[:]...)
+	}
+	return append(b, wireInner)
+}
+
+func hashWithPrefix(prefix data.HashPrefix, b []byte) data.Hash256 {
+	var hash data.Hash256
+	copy(hash[:], crypto.Sha512Half(append(prefix.Bytes(), b...)))
+	return hash
+}
+
+// Verify checks the proof leads from root to the item with key, and
+// returns the node of the item, which ends with the key
+func (p Proof) Verify(root, key data.Hash256) ([]byte, error) {
+	if len(p) == 0 || len(p) > maxDepth+1 {
+		return nil, fmt.Errorf("Bad proof length: %d", len(p))
+	}
+	hash := root
+	for depth := 0; depth < len(p); depth++ {
+		node := p[len(p)-1-depth]
+		if len(node) == 0 {
+			return nil, fmt.Errorf("Empty node at depth %d", depth)
+		}
+		body, typ := node[:len(node)-1], node[len(node)-1]
+		switch typ {
+		case wireInner:
+			if len(body) != 16*len(hash) {
+				return nil, fmt.Errorf("Bad inner node length at depth %d: %d", depth, len(body))
+			}
+			if computed := hashWithPrefix(data.HP_INNER_NODE, body); computed != hash {
+				return nil, fmt.Errorf("Inner node at depth %d has hash %s not %s", depth, computed, hash)
+			}
+			copy(hash[:], body[nibble(key, depth)*len(hash):])
+			if hash.IsZero() {
+				return nil, fmt.Errorf("No item %s in the map", key)
+			}
+		case wireAccountState, wireTransactionWithMeta:
+			if depth != len(p)-1 {
+				return nil, fmt.Errorf("Leaf at depth %d before the end of the proof", depth)
+			}
+			prefix := data.HP_LEAF_NODE
+			if typ == wireTransactionWithMeta {
+				prefix = data.HP_TRANSACTION_NODE
+			}
+			if computed := hashWithPrefix(prefix, body); computed != hash {
+				return nil, fmt.Errorf("Leaf has hash %s not %s", computed, hash)
+			}
+			if len(body) < len(key) || !bytes.Equal(body[len(body)-len(key):], key[:]) {
+				return nil, fmt.Errorf("Proof is not of item %s", key)
+			}
+			return body, nil
+		default:
+			return nil, fmt.Errorf("Unknown node type at depth %d: %d", depth, typ)
+		}
+	}
+	return nil, fmt.Errorf("Proof ends with an inner node")
+}
+
+// VerifyEntry checks the proof of the entry with index against the state
+// hash of a header, which must be trusted, and returns the entry
+func VerifyEntry(header *data.LedgerHeader, index data.Hash256, proof Proof) (data.LedgerEntry, error) {
+	node, err := proof.Verify(header.StateHash, index)
+	if err != nil {
+		return nil, fmt.Errorf("Ledger %d state: %s", header.LedgerSequence, err)
+	}
+	return data.ReadLedgerEntry(bytes.NewReader(node), data.Hash256{})
+}
@@ -0,0 +1,217 @@
+// Package shamap builds the SHAMaps of a ledger, the trees which hold its
+// account state and its transactions, and computes their root hashes, so
+// that entries downloaded from an untrusted server can be checked against
+// the hashes in a ledger header.
+//
+// Each inner node has sixteen branches, chosen by successive nibbles of
+// the keys. A leaf sits at the shallowest depth at which its key differs
+// from all others.
+package shamap
+
+import (
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+type node interface {
+	hash() data.Hash256
+}
+
+// leaf holds the key of an item and the hash of its node, which is all
+// that is needed to compute the hashes of the inner nodes above it. The
+// node itself, without its hash prefix, is kept when known, for proofs.
+type leaf struct {
+	key  data.Hash256
+	node data.Hash256
+	typ  byte
+	data []byte
+}
+
+func (l *leaf) hash() data.Hash256 { return l.node }
+
+type inner struct {
+	children [16]node
+	cached   *data.Hash256
+}
+
+// nibble returns the branch taken by key at depth
+func nibble(key data.Hash256, depth int) int {
+	if depth%2 == 0 {
+		return int(key[depth/2] >> 4)
+	}
+	return int(key[depth/2] & 0x0F)
+}
+
+// hash is zero for an empty inner node, which only a root may be
+func (n *inner) hash() data.Hash256 {
+	if n.cached != nil {
+		return *n.cached
+	}
+	var hash data.Hash256
+	b := data.HP_INNER_NODE.Bytes()
+	empty := true
+	for _, child := range n.children {
+		var h data.Hash256
+		if child != nil {
+			h, empty = child.hash(), false
+		}
+		b = append(b, h[:]...)
+	}
+	if !empty {
+		copy(hash[:], crypto.Sha512Half(b))
+	}
+	n.cached = &hash
+	return hash
+}
+
+// put returns false if the leaf replaced one with the same key
+func (n *inner) put(l *leaf, depth int) bool {
+	n.cached = nil
+	i := nibble(l.key, depth)
+	switch child := n.children[i].(type) {
+	case nil:
+		n.children[i] = l
+		return true
+	case *leaf:
+		if child.key == l.key {
+			n.children[i] = l
+			return false
+		}
+		next := &inner{}
+		next.put(child, depth+1)
+		n.children[i] = next
+		return next.put(l, depth+1)
+	default:
+		return child.(*inner).put(l, depth+1)
+	}
+}
+
+func (n *inner) get(key data.Hash256, depth int) *leaf {
+	switch child := n.children[nibble(key, depth)].(type) {
+	case *leaf:
+		if child.key == key {
+			return child
+		}
+		return nil
+	case *inner:
+		return child.get(key, depth+1)
+	default:
+		return nil
+	}
+}
+
+// Map is a SHAMap of either account state or transactions
+type Map struct {
+	root  inner
+	count int
+}
+
+func New() *Map {
+	return &Map{}
+}
+
+// Put adds an item by its key and the hash of its node, replacing any
+// item with the same key
+func (m *Map) Put(key, node data.Hash256) {
+	if m.root.put(&leaf{key: key, node: node}, 0) {
+		m.count++
+	}
+}
+
+// Get returns the hash of the node of the item with key
+func (m *Map) Get(key data.Hash256) (data.Hash256, bool) {
+	if l := m.root.get(key, 0); l != nil {
+		return l.node, true
+	}
+	return data.Hash256{}, false
+}
+
+// Len returns the number of items in the map
+func (m *Map) Len() int {
+	return m.count
+}
+
+// Hash returns the root hash, which is zero for an empty map
+func (m *Map) Hash() data.Hash256 {
+	return m.root.hash()
+}
+
+// add adds a node, which ends with the key of its item
+func (m *Map) add(h data.Hashable, typ byte) error {
+	node, value, err := data.Raw(h)
+	if err != nil {
+		return err
+	}
+	l := &leaf{node: node, typ: typ, data: value}
+	copy(l.key[:], value[len(value)-len(l.key):])
+	if m.root.put(l, 0) {
+		m.count++
+	}
+	return nil
+}
+
+// AddLedgerEntry adds an entry by its index
+func (m *Map) AddLedgerEntry(le data.LedgerEntry) error {
+	return m.add(le, wireAccountState)
+}
+
+// AddTransaction adds a transaction by its hash
+func (m *Map) AddTransaction(txm *data.TransactionWithMetaData) error {
+	return m.add(txm, wireTransactionWithMeta)
+}
+
+// Verify returns an error unless the root hash is expected
+func (m *Map) Verify(expected data.Hash256) error {
+	if hash := m.Hash(); hash != expected {
+		return fmt.Errorf("Root hash %s of %d items does not match %s", hash, m.count, expected)
+	}
+	return nil
+}
+
+// StateMap builds the account state map of the entries
+func StateMap(entries data.LedgerEntrySlice) (*Map, error) {
+	m := New()
+	for _, le := range entries {
+		if err := m.AddLedgerEntry(le); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// TransactionMap builds the transaction map of the transactions
+func TransactionMap(txs data.TransactionSlice) (*Map, error) {
+	m := New()
+	for _, txm := range txs {
+		if err := m.AddTransaction(txm); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// VerifyState checks the entries are the whole state of the ledger
+func VerifyState(header *data.LedgerHeader, entries data.LedgerEntrySlice) error {
+	m, err := StateMap(entries)
+	if err != nil {
+		return err
+	}
+	if err := m.Verify(header.StateHash); err != nil {
+		return fmt.Errorf("Ledger %d state: %s", header.LedgerSequence, err)
+	}
+	return nil
+}
+
+// VerifyTransactions checks the transactions are all those of the ledger
+func VerifyTransactions(header *data.LedgerHeader, txs data.TransactionSlice) error {
+	m, err := TransactionMap(txs)
+	if err != nil {
+		return err
+	}
+	if err := m.Verify(header.TransactionHash); err != nil {
+		return fmt.Errorf("Ledger %d transactions: %s", header.LedgerSequence, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package shamap
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type SHAMapSuite struct{}
+
+var _ = Suite(&SHAMapSuite{})
+
+func readLedger(c *C) *data.Ledger {
+	b, err := ioutil.ReadFile("../data/testdata/ledger_6000000.json")
+	c.Assert(err, IsNil)
+	// The close time is not needed, and its human form lacks the
+	// fraction of a second which RippleTime expects
+	var raw map[string]json.RawMessage
+	c.Assert(json.Unmarshal(b, &raw), IsNil)
+	delete(raw, "close_time_human")
+	b, err = json.Marshal(raw)
+	c.Assert(err, IsNil)
+	var ledger data.Ledger
+	c.Assert(json.Unmarshal(b, &ledger), IsNil)
+	return &ledger
+}
+
+func (s *SHAMapSuite) TestLedger(c *C) {
+	ledger := readLedger(c)
+	c.Assert(VerifyState(&ledger.LedgerHeader, ledger.AccountState), IsNil)
+	c.Assert(VerifyTransactions(&ledger.LedgerHeader, ledger.Transactions), IsNil)
+
+	state, err := StateMap(ledger.AccountState)
+	c.Assert(err, IsNil)
+	c.Check(state.Len(), Equals, len(ledger.AccountState))
+	node, ok := state.Get(*ledger.AccountState[0].GetLedgerIndex())
+	c.Check(ok, Equals, true)
+	id, err := data.NodeId(ledger.AccountState[0])
+	c.Assert(err, IsNil)
+	c.Check(node, Equals, id)
+
+	// The order of the items doesn't matter, and replacing one changes
+	// the root hash
+	reversed := New()
+	for i := len(ledger.AccountState) - 1; i >= 0; i-- {
+		c.Assert(reversed.AddLedgerEntry(ledger.AccountState[i]), IsNil)
+	}
+	c.Check(reversed.Hash(), Equals, ledger.StateHash)
+	reversed.Put(*ledger.AccountState[0].GetLedgerIndex(), data.Hash256{1})
+	c.Check(reversed.Len(), Equals, len(ledger.AccountState))
+	c.Check(reversed.Verify(ledger.StateHash), ErrorMatches, "Root hash .* of 261 items does not match 2C23D15B6B549123FB351E4B5CDE81C564318EB845449CD43C3EA7953C4DB452")
+
+	err = VerifyState(&ledger.LedgerHeader, ledger.AccountState[1:])
+	c.Check(err, ErrorMatches, "Ledger 38129 state: Root hash .* of 260 items does not match .*")
+	c.Check(VerifyTransactions(&ledger.LedgerHeader, nil), ErrorMatches, "Ledger 38129 transactions: Root hash 0000000000000000000000000000000000000000000000000000000000000000 of 0 items .*")
+}
+
+// A single item still sits below the root, and two which share their first
+// nibble are pushed down until they differ
+func (s *SHAMapSuite) TestStructure(c *C) {
+	m := New()
+	c.Check(m.Hash().IsZero(), Equals, true)
+	m.Put(data.Hash256{0x12}, data.Hash256{1})
+	c.Check(m.Hash().IsZero(), Equals, false)
+	m.Put(data.Hash256{0x13}, data.Hash256{2})
+	c.Check(m.Len(), Equals, 2)
+	c.Assert(m.root.children[1], FitsTypeOf, &inner{})
+	below := m.root.children[1].(*inner)
+	c.Check(below.children[2], DeepEquals, &leaf{key: data.Hash256{0x12}, node: data.Hash256{1}})
+	c.Check(below.children[3], DeepEquals, &leaf{key: data.Hash256{0x13}, node: data.Hash256{2}})
+	_, ok := m.Get(data.Hash256{0x14})
+	c.Check(ok, Equals, false)
+}
+
+func (s *SHAMapSuite) TestProof(c *C) {
+	ledger := readLedger(c)
+	state, err := StateMap(ledger.AccountState)
+	c.Assert(err, IsNil)
+	for _, le := range ledger.AccountState {
+		if le.GetLedgerEntryType() != data.RIPPLE_STATE {
+			continue
+		}
+		index := *le.GetLedgerIndex()
+		proof, err := state.Proof(index)
+		c.Assert(err, IsNil)
+		c.Check(len(proof) > 2, Equals, true)
+		entry, err := VerifyEntry(&ledger.LedgerHeader, index, proof)
+		c.Assert(err, IsNil)
+		line := entry.(*data.RippleState)
+		c.Check(line.Balance.Equals(*le.(*data.RippleState).Balance), Equals, true)
+		c.Check(*line.GetHash(), Equals, index)
+
+		// Proofs travel as hex
+		b, err := json.Marshal(proof)
+		c.Assert(err, IsNil)
+		var decoded Proof
+		c.Assert(json.Unmarshal(b, &decoded), IsNil)
+		_, err = VerifyEntry(&ledger.LedgerHeader, index, decoded)
+		c.Check(err, IsNil)
+
+		// Any change breaks it
+		_, err = proof.Verify(ledger.StateHash, data.Hash256{index[0], 1})
+		c.Check(err, NotNil)
+		_, err = proof.Verify(ledger.TransactionHash, index)
+		c.Check(err, ErrorMatches, "Inner node at depth 0 has hash .*")
+		_, err = proof[1:].Verify(ledger.StateHash, index)
+		c.Check(err, ErrorMatches, "Proof ends with an inner node")
+		proof[0][10]++
+		_, err = VerifyEntry(&ledger.LedgerHeader, index, proof)
+		c.Check(err, ErrorMatches, "Ledger 38129 state: Leaf has hash .*")
+		break
+	}
+
+	txs, err := TransactionMap(ledger.Transactions)
+	c.Assert(err, IsNil)
+	hash := *ledger.Transactions[0].GetHash()
+	proof, err := txs.Proof(hash)
+	c.Assert(err, IsNil)
+	c.Check(proof, HasLen, 2)
+	_, err = proof.Verify(ledger.TransactionHash, hash)
+	c.Check(err, IsNil)
+	_, err = txs.Proof(data.Hash256{})
+	c.Check(err, ErrorMatches, "No item 0{64} in the map")
+	txs.Put(data.Hash256{}, data.Hash256{1})
+	_, err = txs.Proof(data.Hash256{})
+	c.Check(err, ErrorMatches, "Node of item 0{64} is not known")
+}
@@ -0,0 +1,103 @@
+package sign
+
+import (
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// ChannelClaim authorises the payee of a payment channel to redeem up to
+// Amount from it. The payer signs it offline and hands it over, and the
+// payee puts it in a PaymentChannelClaim to be paid.
+type ChannelClaim struct {
+	Channel   data.Hash256
+	Amount    data.Value // Native, the total authorised so far
+	PublicKey data.PublicKey
+	Signature data.VariableLength
+}
+
+func claimDrops(amount data.Value) (uint64, error) {
+	if !amount.IsNative() || amount.IsNegative() {
+		return 0, fmt.Errorf("Bad claim amount: %s", amount.String())
+	}
+	drops := amount.Rat()
+	if !drops.IsInt() || !drops.Num().IsUint64() {
+		return 0, fmt.Errorf("Bad claim amount: %s", amount.String())
+	}
+	return drops.Num().Uint64(), nil
+}
+
+// Message returns the serialised claim, "CLM\0" followed by the channel
+// and the amount in drops, which is what is signed
+func (c *ChannelClaim) Message() ([]byte, error) {
+	drops, err := claimDrops(c.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.ChannelClaim(c.Channel.Bytes(), drops)
+}
+
+// Claim signs a claim of amount from channel, as channel_authorize does,
+// without contacting a server. The signer must hold the key which the
+// channel was created with.
+func Claim(channel data.Hash256, amount data.Value, signer Signer) (*ChannelClaim, error) {
+	claim := &ChannelClaim{
+		Channel:   channel,
+		Amount:    amount,
+		PublicKey: signer.PublicKey(),
+	}
+	msg, err := claim.Message()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(crypto.Sha512Half(msg), msg)
+	if err != nil {
+		return nil, err
+	}
+	claim.Signature = data.VariableLength(sig)
+	return claim, claim.Verify()
+}
+
+// Verify checks the signature of the claim, as channel_verify does. It
+// does not check that PublicKey is the key of the channel.
+func (c *ChannelClaim) Verify() error {
+	msg, err := c.Message()
+	if err != nil {
+		return err
+	}
+	ok, err := crypto.Verify(c.PublicKey.Bytes(), crypto.Sha512Half(msg), msg, c.Signature)
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		return fmt.Errorf("Bad claim signature of %s on %s", c.Amount.String(), c.Channel)
+	}
+	return nil
+}
+
+// Apply sets the Channel, Amount, Signature and PublicKey of tx from the
+// claim. The Balance is left to the caller, as it may be less than the
+// amount claimed.
+func (c *ChannelClaim) Apply(tx *data.PaymentChannelClaim) {
+	amount := data.Amount{Value: c.Amount.Clone()}
+	signature := append(data.VariableLength(nil), c.Signature...)
+	publicKey := c.PublicKey
+	tx.Channel = c.Channel
+	tx.Amount, tx.Signature, tx.PublicKey = &amount, &signature, &publicKey
+}
+
+// VerifyClaim checks the signature in a PaymentChannelClaim against its
+// Channel and Amount
+func VerifyClaim(tx *data.PaymentChannelClaim) error {
+	if tx.Signature == nil || tx.PublicKey == nil || tx.Amount == nil || tx.Amount.Value == nil {
+		return fmt.Errorf("PaymentChannelClaim has no signed claim")
+	}
+	claim := &ChannelClaim{
+		Channel:   tx.Channel,
+		Amount:    *tx.Amount.Value,
+		PublicKey: *tx.PublicKey,
+		Signature: *tx.Signature,
+	}
+	return claim.Verify()
+}
@@ -0,0 +1,51 @@
+package sign
+
+import (
+	"bytes"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	. "gopkg.in/check.v1"
+)
+
+func (s *SignSuite) TestChannelClaim(c *C) {
+	channel, err := data.NewHash256("5DB01B7FFED6B67E6B0414DED11E051D2EE2B7619CE0EAA6286D67A3A4D5BDB3")
+	c.Assert(err, IsNil)
+	amount, err := data.NewNativeValue(1000000)
+	c.Assert(err, IsNil)
+	for _, test := range keyTests {
+		key, err := NewKey(test.secret)
+		c.Assert(err, IsNil)
+		claim, err := Claim(*channel, *amount, key)
+		c.Assert(err, IsNil)
+		c.Check(claim.PublicKey, Equals, key.PublicKey())
+		msg, err := claim.Message()
+		c.Assert(err, IsNil)
+		c.Check(msg, HasLen, 44)
+		c.Check(string(msg[:4]), Equals, "CLM\x00")
+		c.Check(msg[36:], DeepEquals, []byte{0, 0, 0, 0, 0, 0x0F, 0x42, 0x40})
+
+		tx := data.TxFactory[data.PAYCHAN_CLAIM]().(*data.PaymentChannelClaim)
+		claim.Apply(tx)
+		c.Check(tx.Channel, Equals, *channel)
+		c.Check(VerifyClaim(tx), IsNil)
+
+		_, raw, err := data.Raw(tx)
+		c.Assert(err, IsNil)
+		decoded, err := data.ReadTransaction(bytes.NewReader(raw))
+		c.Assert(err, IsNil)
+		c.Check(VerifyClaim(decoded.(*data.PaymentChannelClaim)), IsNil)
+
+		more, err := data.NewNativeValue(1000001)
+		c.Assert(err, IsNil)
+		tx.Amount.Value = more
+		c.Check(VerifyClaim(tx), ErrorMatches, "Bad claim signature of .*")
+	}
+
+	key, err := NewKey(keyTests[0].secret)
+	c.Assert(err, IsNil)
+	issued, err := data.NewValue("1", false)
+	c.Assert(err, IsNil)
+	_, err = Claim(*channel, *issued, key)
+	c.Check(err, ErrorMatches, "Bad claim amount: 1")
+	c.Check(VerifyClaim(data.TxFactory[data.PAYCHAN_CLAIM]().(*data.PaymentChannelClaim)), ErrorMatches, "PaymentChannelClaim has no signed claim")
+}
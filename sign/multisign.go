@@ -0,0 +1,116 @@
+package sign
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// prepareMultisign clears what a multisigned transaction must not have.
+// The SigningPubKey stays, empty, as the signatures cover it.
+func prepareMultisign(tx data.Transaction) (*data.TxBase, error) {
+	base := tx.GetBase()
+	if base == nil {
+		return nil, fmt.Errorf("%s can't be multisigned", tx.GetType())
+	}
+	base.SigningPubKey = new(data.PublicKey)
+	base.TxnSignature = nil
+	return base, nil
+}
+
+// For signs tx with signer on behalf of account, which is in the signer
+// list of the transaction's Account, and returns the Signer to be combined
+// with those of the other signers. The signer may use the master or the
+// regular key of account. Every signer must sign the transaction with the
+// same fields, including a Fee which covers all of them.
+func For(tx data.Transaction, account data.Account, signer Signer) (*data.MultiSigner, error) {
+	base, err := prepareMultisign(tx)
+	if err != nil {
+		return nil, err
+	}
+	if account == base.Account {
+		return nil, fmt.Errorf("Account %s cannot sign for itself", account)
+	}
+	hash, msg, err := data.MultiSigningHash(tx, account)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(hash.Bytes(), append(data.HP_TRANSACTION_MULTISIGN.Bytes(), msg...))
+	if err != nil {
+		return nil, err
+	}
+	var multi data.MultiSigner
+	multi.Signer.Account = account
+	multi.Signer.SigningPubKey = signer.PublicKey()
+	multi.Signer.TxnSignature = data.VariableLength(sig)
+	return &multi, nil
+}
+
+// Combine adds signers to the Signers of tx, replacing any earlier
+// signature of the same account, and sorts them as required. Each new
+// signature is checked against tx.
+func Combine(tx data.Transaction, signers ...data.MultiSigner) error {
+	base, err := prepareMultisign(tx)
+	if err != nil {
+		return err
+	}
+	for i := range signers {
+		signer := &signers[i]
+		ok, err := data.CheckMultiSignature(tx, signer)
+		switch {
+		case err != nil:
+			return fmt.Errorf("Signer %s: %s", signer.Signer.Account, err)
+		case !ok:
+			return fmt.Errorf("Signature of %s does not verify", signer.Signer.Account)
+		}
+		replaced := false
+		for j := range base.Signers {
+			if base.Signers[j].Signer.Account == signer.Signer.Account {
+				base.Signers[j], replaced = *signer, true
+			}
+		}
+		if !replaced {
+			base.Signers = append(base.Signers, *signer)
+		}
+	}
+	sort.Slice(base.Signers, func(i, j int) bool {
+		a, b := base.Signers[i].Signer.Account, base.Signers[j].Signer.Account
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+	return nil
+}
+
+// Multisigned checks that the Signers of tx meet the quorum of list, and
+// that all their signatures verify, then sets the Hash of tx. A nil list
+// skips the quorum check, as when the signer list isn't known.
+func Multisigned(tx data.Transaction, list *data.SignerList) (*Signed, error) {
+	base := tx.GetBase()
+	if base == nil {
+		return nil, fmt.Errorf("%s can't be multisigned", tx.GetType())
+	}
+	if list != nil {
+		if err := list.CheckSigners(tx); err != nil {
+			return nil, err
+		}
+	}
+	if len(base.Signers) == 0 {
+		return nil, fmt.Errorf("Multisigned transaction has no Signers")
+	}
+	for i := range base.Signers {
+		ok, err := data.CheckMultiSignature(tx, &base.Signers[i])
+		switch {
+		case err != nil:
+			return nil, err
+		case !ok:
+			return nil, fmt.Errorf("Signature of %s does not verify", base.Signers[i].Signer.Account)
+		}
+	}
+	hash, blob, err := data.Raw(tx)
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.GetHash().Bytes(), hash.Bytes())
+	return &Signed{Tx: tx, Hash: hash, Blob: blob}, nil
+}
@@ -0,0 +1,78 @@
+package sign
+
+import (
+	"bytes"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	. "gopkg.in/check.v1"
+)
+
+func newMultisignPayment(c *C, account data.Account) *data.Payment {
+	amount, err := data.NewAmount("1000000")
+	c.Assert(err, IsNil)
+	fee, err := data.NewNativeValue(36)
+	c.Assert(err, IsNil)
+	tx := data.TxFactory[data.PAYMENT]().(*data.Payment)
+	tx.Account = account
+	tx.Destination = account
+	tx.Destination[0]++
+	tx.Amount = *amount
+	tx.Fee = *fee
+	tx.Sequence = 5
+	return tx
+}
+
+func (s *SignSuite) TestMultisign(c *C) {
+	var keys []*Key
+	for _, test := range keyTests {
+		key, err := NewKey(test.secret)
+		c.Assert(err, IsNil)
+		keys = append(keys, key)
+	}
+	owner, ecdsa, ed25519 := keys[0], keys[1], keys[2]
+	weight, quorum := uint16(1), uint32(2)
+	list := &data.SignerList{SignerQuorum: &quorum}
+	for _, key := range keys[1:] {
+		account := key.Account()
+		list.SignerEntries = append(list.SignerEntries, data.SignerEntry{Account: &account, SignerWeight: &weight})
+	}
+
+	// Each signer signs their own copy
+	var signers []data.MultiSigner
+	for _, key := range []*Key{ed25519, ecdsa} {
+		signer, err := For(newMultisignPayment(c, owner.Account()), key.Account(), key)
+		c.Assert(err, IsNil)
+		c.Check(signer.Signer.SigningPubKey, Equals, key.PublicKey())
+		signers = append(signers, *signer)
+	}
+	_, err := For(newMultisignPayment(c, owner.Account()), owner.Account(), owner)
+	c.Check(err, ErrorMatches, "Account .* cannot sign for itself")
+
+	tx := newMultisignPayment(c, owner.Account())
+	c.Assert(Combine(tx, signers[0]), IsNil)
+	_, err = Multisigned(tx, list)
+	c.Check(err, ErrorMatches, "Signer weight 1 is below the quorum of 2")
+
+	// Adding the same signature again replaces it
+	c.Assert(Combine(tx, signers...), IsNil)
+	c.Assert(tx.Signers, HasLen, 2)
+	first, second := tx.Signers[0].Signer.Account, tx.Signers[1].Signer.Account
+	c.Check(bytes.Compare(first[:], second[:]), Equals, -1)
+
+	signed, err := Multisigned(tx, list)
+	c.Assert(err, IsNil)
+	c.Check(tx.Hash, Equals, signed.Hash)
+
+	decoded, err := data.ReadTransaction(bytes.NewReader(signed.Blob))
+	c.Assert(err, IsNil)
+	c.Check(decoded.GetBase().Signers, HasLen, 2)
+	c.Check(decoded.GetBase().SigningPubKey.IsZero(), Equals, true)
+	_, err = Multisigned(decoded, list)
+	c.Check(err, IsNil)
+
+	// A signature over different fields is refused
+	tampered := signers[1]
+	other := newMultisignPayment(c, owner.Account())
+	other.Sequence++
+	c.Check(Combine(other, tampered), ErrorMatches, "Signature of .* does not verify")
+}
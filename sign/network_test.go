@@ -0,0 +1,30 @@
+package sign
+
+import (
+	"github.com/kr-jaydeepp/ripple/data"
+	. "gopkg.in/check.v1"
+)
+
+func (s *SignSuite) TestForNetwork(c *C) {
+	key, err := NewKey(keyTests[2].secret)
+	c.Assert(err, IsNil)
+	sidechain, err := data.NewNetwork("sidechain", 21337, 10000000, 2000000)
+	c.Assert(err, IsNil)
+
+	tx := newMultisignPayment(c, key.Account())
+	signed, err := ForNetwork(tx, sidechain, key)
+	c.Assert(err, IsNil)
+	c.Check(*tx.NetworkID, Equals, uint32(21337))
+	ok, err := data.CheckSignature(signed.Tx)
+	c.Assert(err, IsNil)
+	c.Check(ok, Equals, true)
+
+	_, err = ForNetwork(tx, data.Mainnet, key)
+	c.Check(err, ErrorMatches, "NetworkID 21337 is not that of mainnet \\(0\\)")
+
+	tx = newMultisignPayment(c, key.Account())
+	signed, err = ForNetwork(tx, data.Testnet, key)
+	c.Assert(err, IsNil)
+	c.Check(tx.NetworkID, IsNil)
+	c.Check(signed.Hash, Not(Equals), data.Hash256{})
+}
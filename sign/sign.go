@@ -0,0 +1,164 @@
+// Package sign signs transactions offline, with the keys of accounts
+// derived from their secrets. Both ECDSA (secp256k1) and Ed25519 keys
+// are supported, so a secret never needs to be sent to a server.
+package sign
+
+import (
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// Signer signs for an account without exposing its private key, so the
+// key may be kept elsewhere, such as in a keystore or hardware wallet
+type Signer interface {
+	// Account returns the account whose master key this is
+	Account() data.Account
+	// PublicKey returns what goes in the SigningPubKey of a transaction
+	PublicKey() data.PublicKey
+	// Sign returns the signature of msg, which begins with its hash
+	// prefix, and whose SHA512-half is hash
+	Sign(hash, msg []byte) ([]byte, error)
+}
+
+// Key is the key pair of an account
+type Key struct {
+	Type data.KeyType
+
+	key crypto.Key
+	// The account key of an ECDSA family is the first of its sequence,
+	// while Ed25519 and HD keys have no family
+	sequence *uint32
+}
+
+// NewKey derives the key of secret, which is an "s..." family seed for
+// an ECDSA key or an "sEd..." one for an Ed25519 key
+func NewKey(secret string) (*Key, error) {
+	seed, keyType, err := data.NewSeedFromSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyFromSeed(*seed, keyType), nil
+}
+
+// NewKeyFromSeed derives the key of keyType from seed
+func NewKeyFromSeed(seed data.Seed, keyType data.KeyType) *Key {
+	k := &Key{Type: keyType, key: seed.Key(keyType)}
+	if keyType == data.ECDSA {
+		k.sequence = new(uint32)
+	}
+	return k
+}
+
+// NewKeyFromMnemonic derives the key of keyType at path, such as
+// crypto.DefaultHDPath, from a BIP39 mnemonic and passphrase, as XUMM and
+// Ledger wallets do. Ed25519 keys need a path whose indexes are all
+// hardened.
+func NewKeyFromMnemonic(mnemonic, passphrase, path string, keyType data.KeyType) (*Key, error) {
+	seed := crypto.MnemonicSeed(mnemonic, passphrase)
+	switch keyType {
+	case data.ECDSA:
+		key, err := crypto.DeriveECDSAKey(seed, path)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{Type: keyType, key: key}, nil
+	case data.Ed25519:
+		key, err := crypto.DeriveEd25519Key(seed, path)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{Type: keyType, key: key}, nil
+	default:
+		return nil, fmt.Errorf("Unknown key type: %s", keyType)
+	}
+}
+
+// Account returns the account whose master key this is
+func (k *Key) Account() data.Account {
+	var account data.Account
+	copy(account[:], k.key.Id(k.sequence))
+	return account
+}
+
+// PublicKey returns what goes in the SigningPubKey of a transaction
+func (k *Key) PublicKey() data.PublicKey {
+	var public data.PublicKey
+	copy(public[:], k.key.Public(k.sequence))
+	return public
+}
+
+// Sign signs msg with the private key
+func (k *Key) Sign(hash, msg []byte) ([]byte, error) {
+	return crypto.Sign(k.key.Private(k.sequence), hash, msg)
+}
+
+// Signed is a transaction ready to be submitted
+type Signed struct {
+	Tx   data.Transaction
+	Hash data.Hash256
+	Blob []byte
+}
+
+// TxBlob returns the blob as hex, as submit expects
+func (s *Signed) TxBlob() string {
+	return fmt.Sprintf("%X", s.Blob)
+}
+
+// Transaction signs tx with signer, setting its SigningPubKey,
+// TxnSignature and Hash. The signer may use the master or the regular key
+// of the account. Nothing is signed which would not be read back as it is.
+func Transaction(tx data.Transaction, signer Signer) (*Signed, error) {
+	base := tx.GetBase()
+	if base == nil {
+		return nil, fmt.Errorf("%s can't be signed", tx.GetType())
+	}
+	if len(base.Signers) > 0 {
+		return nil, fmt.Errorf("Transaction is multisigned")
+	}
+	tx.InitialiseForSigning()
+	*tx.GetPublicKey() = signer.PublicKey()
+	if err := data.VerifyRoundTrip(tx); err != nil {
+		return nil, err
+	}
+	hash, msg, err := data.SigningHash(tx)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(hash.Bytes(), append(tx.SigningPrefix().Bytes(), msg...))
+	if err != nil {
+		return nil, err
+	}
+	*tx.GetSignature() = data.VariableLength(sig)
+	ok, err := data.CheckSignature(tx)
+	switch {
+	case err != nil:
+		return nil, err
+	case !ok:
+		return nil, fmt.Errorf("Signature of %s does not verify", signer.Account())
+	}
+	hash, blob, err := data.Raw(tx)
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.GetHash().Bytes(), hash.Bytes())
+	return &Signed{Tx: tx, Hash: hash, Blob: blob}, nil
+}
+
+// ForNetwork signs tx with signer for network, setting its NetworkID when
+// the network needs one. A transaction with the NetworkID of another
+// network is refused, as it could otherwise be replayed there.
+func ForNetwork(tx data.Transaction, network *data.Network, signer Signer) (*Signed, error) {
+	base := tx.GetBase()
+	if base == nil {
+		return nil, fmt.Errorf("%s can't be signed", tx.GetType())
+	}
+	if base.NetworkID == nil {
+		network.SetNetworkID(tx)
+	}
+	if err := network.Check(tx); err != nil {
+		return nil, err
+	}
+	return Transaction(tx, signer)
+}
@@ -0,0 +1,109 @@
+package sign
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+	"github.com/kr-jaydeepp/ripple/data"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type SignSuite struct{}
+
+var _ = Suite(&SignSuite{})
+
+var keyTests = []struct {
+	secret    string
+	keyType   data.KeyType
+	account   string
+	publicKey string
+}{
+	{"snoPBrXtMeMyMHUVTgbuqAfg1SUTb", data.ECDSA, "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh", "0330E7FC9D56BB25D6893BA3F317AE5BCF33B3291BD63DB32654A313222F7FD020"},
+	{"sp5fghtJtpUorTwvof1NpDXAzNwf5", data.ECDSA, "rU6K7V3Po4snVhBBaU29sesqs2qTQJWDw1", "030D58EB48B4420B1F7B9DF55087E0E29FEF0E8468F9A6825B01CA2C361042D435"},
+	{"sEdVQ4wvD1AaTG6JA54qt38TengAuiz", data.Ed25519, "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf", "EDAAC3F98BB94F451804EF5993C847DAAA4E6154F455635659D88AA5C80F156303"},
+}
+
+func (s *SignSuite) TestKeys(c *C) {
+	for _, test := range keyTests {
+		key, err := NewKey(test.secret)
+		c.Assert(err, IsNil)
+		c.Check(key.Type, Equals, test.keyType)
+		c.Check(key.Account().String(), Equals, test.account)
+		public := key.PublicKey()
+		c.Check(public.String(), Equals, test.publicKey)
+
+		seed, keyType, err := data.NewSeedFromSecret(test.secret)
+		c.Assert(err, IsNil)
+		c.Check(seed.Secret(keyType), Equals, test.secret)
+	}
+	_, err := NewKey("rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh")
+	c.Check(err, ErrorMatches, "Not a family seed: .*")
+}
+
+func (s *SignSuite) TestTransaction(c *C) {
+	for _, test := range keyTests {
+		key, err := NewKey(test.secret)
+		c.Assert(err, IsNil)
+		amount, err := data.NewAmount("1000000")
+		c.Assert(err, IsNil)
+		fee, err := data.NewNativeValue(12)
+		c.Assert(err, IsNil)
+		tx := data.TxFactory[data.PAYMENT]().(*data.Payment)
+		tx.Account = key.Account()
+		tx.Destination = key.Account()
+		tx.Destination[0]++
+		tx.Amount = *amount
+		tx.Fee = *fee
+		tx.Sequence = 1
+
+		signed, err := Transaction(tx, key)
+		c.Assert(err, IsNil)
+		c.Check(signed.Hash, Equals, tx.Hash)
+		c.Check(*tx.SigningPubKey, Equals, key.PublicKey())
+
+		// The blob decodes to the same transaction, whose signature verifies
+		decoded, err := data.ReadTransaction(bytes.NewReader(signed.Blob))
+		c.Assert(err, IsNil)
+		ok, err := data.CheckSignature(decoded)
+		c.Assert(err, IsNil)
+		c.Check(ok, Equals, true)
+		hash, _, err := data.Raw(decoded)
+		c.Assert(err, IsNil)
+		c.Check(hash, Equals, signed.Hash)
+	}
+}
+
+func (s *SignSuite) TestMultisigned(c *C) {
+	key, err := NewKey(keyTests[0].secret)
+	c.Assert(err, IsNil)
+	tx := data.TxFactory[data.PAYMENT]().(*data.Payment)
+	tx.Signers = make(data.MultiSigners, 1)
+	_, err = Transaction(tx, key)
+	c.Check(err, ErrorMatches, "Transaction is multisigned")
+}
+
+func (s *SignSuite) TestMnemonic(c *C) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	key, err := NewKeyFromMnemonic(mnemonic, "", crypto.DefaultHDPath, data.ECDSA)
+	c.Assert(err, IsNil)
+	public := key.PublicKey()
+	c.Check(public.String(), Equals, "031D68BC1A142E6766B2BDFB006CCFE135EF2E0E2E94ABB5CF5C9AB6104776FBAE")
+	c.Check(key.Account().String(), Equals, "rHsMGQEkVNJmpGWs8XUBoTBiAAbwxZN5v3")
+
+	_, err = NewKeyFromMnemonic(mnemonic, "", crypto.DefaultHDPath, data.Ed25519)
+	c.Check(err, ErrorMatches, "Ed25519 keys only have hardened children: .*")
+	key, err = NewKeyFromMnemonic(mnemonic, "", "m/44'/144'/0'/0'/0'", data.Ed25519)
+	c.Assert(err, IsNil)
+	amount, err := data.NewAmount("1000000")
+	c.Assert(err, IsNil)
+	tx := data.TxFactory[data.PAYMENT]().(*data.Payment)
+	tx.Account = key.Account()
+	tx.Destination = key.Account()
+	tx.Destination[0]++
+	tx.Amount = *amount
+	_, err = Transaction(tx, key)
+	c.Check(err, IsNil)
+}
@@ -0,0 +1,185 @@
+// Package testharness drives a rippled running in standalone mode, whose
+// ledgers only close when asked to, so that tests can fund accounts,
+// submit transactions and wait for them to be validated end to end.
+//
+//	h := testharness.New(remote)
+//	alice, err := h.Fund(ctx, xrp)
+//	...
+//	result, err := h.Submit(ctx, payment, alice)
+//
+// A standalone server treats every ledger it closes as validated, and
+// the genesis account starts with every XRP.
+package testharness
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/accounts"
+	"github.com/kr-jaydeepp/ripple/builder"
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/sign"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// GenesisSecret is the secret of the genesis account, derived from
+// "masterpassphrase"
+const GenesisSecret = "snoPBrXtMeMyMHUVTgbuqAfg1SUTb"
+
+// DefaultMaxLedgers is how many ledgers are closed waiting for a
+// transaction when Harness.MaxLedgers is zero
+const DefaultMaxLedgers = builder.DefaultLedgerOffset
+
+// Client is the part of ripple.Client needed by a Harness, with the
+// LedgerAcceptCtx of ripple.Admin to close ledgers
+type Client interface {
+	builder.Client
+	accounts.Client
+	LedgerAcceptCtx(ctx context.Context) (uint32, error)
+	SubmitCtx(ctx context.Context, tx data.Transaction) (*websockets.SubmitResult, error)
+	TxCtx(ctx context.Context, hash data.Hash256) (*websockets.TxResult, error)
+}
+
+// Harness funds accounts from the genesis account and submits their
+// transactions, closing ledgers until each is validated. It may be used
+// by several goroutines at once.
+type Harness struct {
+	Client  Client
+	Genesis *sign.Key
+
+	// Ledgers closed waiting for a transaction before giving up.
+	// Zero means DefaultMaxLedgers.
+	MaxLedgers int
+	// How long to wait after closing a ledger before asking again
+	// whether a transaction is validated
+	PollInterval time.Duration
+
+	sequences *accounts.SequenceManager
+	mu        sync.Mutex // Serialises the closing of ledgers
+}
+
+// New returns a Harness for a standalone server reached through client
+func New(client Client) *Harness {
+	genesis, err := sign.NewKey(GenesisSecret)
+	if err != nil {
+		panic(err)
+	}
+	return &Harness{
+		Client:    client,
+		Genesis:   genesis,
+		sequences: accounts.NewSequenceManager(client),
+	}
+}
+
+// Advance closes n ledgers and returns the index of the last one closed
+func (h *Harness) Advance(ctx context.Context, n int) (uint32, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var current uint32
+	for i := 0; i < n; i++ {
+		var err error
+		if current, err = h.Client.LedgerAcceptCtx(ctx); err != nil {
+			return 0, err
+		}
+	}
+	if current == 0 {
+		return 0, fmt.Errorf("No ledger was closed")
+	}
+	return current - 1, nil
+}
+
+// NewKey returns the key of a new account, from a random seed, which
+// exists once funded
+func NewKey() (*sign.Key, error) {
+	var seed data.Seed
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, err
+	}
+	return sign.NewKeyFromSeed(seed, data.ECDSA), nil
+}
+
+// Fund creates an account holding amount, which must be XRP and at
+// least the reserve, and returns its key
+func (h *Harness) Fund(ctx context.Context, amount data.Amount) (*sign.Key, error) {
+	key, err := NewKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := h.FundAccount(ctx, key.Account(), amount); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// FundAccount pays amount to account from the genesis account and waits
+// for the payment to be validated
+func (h *Harness) FundAccount(ctx context.Context, account data.Account, amount data.Amount) error {
+	tx, err := builder.NewPayment(h.Genesis.Account(), account, amount).Build()
+	if err != nil {
+		return err
+	}
+	result, err := h.Submit(ctx, tx, h.Genesis)
+	if err != nil {
+		return err
+	}
+	if r := result.MetaData.TransactionResult; !r.Success() {
+		return fmt.Errorf("Funding %s failed: %s", account, r)
+	}
+	return nil
+}
+
+// Submit fills in whichever of the Sequence, Fee and LastLedgerSequence
+// of tx are missing, signs it with signer and submits it, closing ledgers
+// until it is validated. The result of the transaction, which may have
+// claimed a fee without succeeding, is in the metadata.
+func (h *Harness) Submit(ctx context.Context, tx data.Transaction, signer sign.Signer) (*websockets.TxResult, error) {
+	fill := &builder.AutoFill{Client: h.Client, Sequences: h.sequences}
+	if err := fill.Fill(ctx, tx); err != nil {
+		return nil, err
+	}
+	signed, err := sign.Transaction(tx, signer)
+	if err != nil {
+		return nil, err
+	}
+	result, err := h.Client.SubmitCtx(ctx, signed.Tx)
+	if err != nil {
+		h.sequences.Reset(tx.GetBase().Account)
+		return nil, err
+	}
+	h.sequences.Report(tx, result.EngineResult)
+	if !result.EngineResult.Claimed() && !result.EngineResult.Queued() {
+		return nil, result.Err()
+	}
+	return h.WaitForValidation(ctx, signed.Hash)
+}
+
+// WaitForValidation closes ledgers until the transaction with hash is in
+// one, giving up after MaxLedgers
+func (h *Harness) WaitForValidation(ctx context.Context, hash data.Hash256) (*websockets.TxResult, error) {
+	max := h.MaxLedgers
+	if max == 0 {
+		max = DefaultMaxLedgers
+	}
+	for i := 0; ; i++ {
+		result, err := h.Client.TxCtx(ctx, hash)
+		switch {
+		case err == nil && result.Validated:
+			return result, nil
+		case err != nil && !websockets.IsNotFound(err):
+			return nil, err
+		case i == max:
+			return nil, fmt.Errorf("Transaction %s not validated after %d ledgers", hash, max)
+		}
+		if _, err := h.Advance(ctx, 1); err != nil {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(h.PollInterval):
+		}
+	}
+}
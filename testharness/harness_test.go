@@ -0,0 +1,194 @@
+package testharness
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"sync"
+	"testing"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+var rippled = flag.String("rippled", "", "Websocket URL of a standalone rippled with admin access, such as ws://127.0.0.1:6006, to test against")
+
+func Test(t *testing.T) { TestingT(t) }
+
+type HarnessSuite struct{}
+
+var _ = Suite(&HarnessSuite{})
+
+func amount(c *C, s string) data.Amount {
+	a, err := data.NewAmount(s)
+	c.Assert(err, IsNil)
+	return *a
+}
+
+// standalone is a Client for a server in standalone mode, which applies
+// transactions to the open ledger and validates them when it is closed
+type standalone struct {
+	mu        sync.Mutex
+	current   uint32
+	result    data.TransactionResult
+	sequences map[data.Account]uint32
+	submitted map[data.Hash256]uint32 // The ledgers they were applied to
+	txs       map[data.Hash256]data.Transaction
+}
+
+func newStandalone() *standalone {
+	return &standalone{
+		current:   3,
+		sequences: make(map[data.Account]uint32),
+		submitted: make(map[data.Hash256]uint32),
+		txs:       make(map[data.Hash256]data.Transaction),
+	}
+}
+
+func (s *standalone) AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sequence := s.sequences[a] + 1
+	return &websockets.AccountInfoResult{
+		AccountData: data.AccountRoot{Account: &a, Sequence: &sequence},
+	}, nil
+}
+
+func (s *standalone) AccountObjectsCtx(ctx context.Context, account data.Account, objectType string, ledgerIndex interface{}) (*websockets.AccountObjectsResult, error) {
+	return &websockets.AccountObjectsResult{}, nil
+}
+
+func (s *standalone) FeeCtx(ctx context.Context) (*websockets.FeeResult, error) {
+	var result websockets.FeeResult
+	return &result, json.Unmarshal([]byte(`{
+		"drops": {"base_fee": "10", "minimum_fee": "10", "open_ledger_fee": "10"}
+	}`), &result)
+}
+
+func (s *standalone) LedgerCurrentCtx(ctx context.Context) (uint32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current, nil
+}
+
+func (s *standalone) LedgerAcceptCtx(ctx context.Context) (uint32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current++
+	return s.current, nil
+}
+
+func (s *standalone) SubmitCtx(ctx context.Context, tx data.Transaction) (*websockets.SubmitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.result.Claimed() {
+		base := tx.GetBase()
+		s.sequences[base.Account] = base.Sequence
+		s.submitted[*tx.GetHash()] = s.current
+		s.txs[*tx.GetHash()] = tx
+	}
+	return &websockets.SubmitResult{EngineResult: s.result}, nil
+}
+
+func (s *standalone) TxCtx(ctx context.Context, hash data.Hash256) (*websockets.TxResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ledger, ok := s.submitted[hash]
+	if !ok {
+		return nil, &websockets.CommandError{Name: "txnNotFound", Code: 29}
+	}
+	return &websockets.TxResult{
+		TransactionWithMetaData: data.TransactionWithMetaData{
+			Transaction:    s.txs[hash],
+			MetaData:       data.MetaData{TransactionResult: s.result},
+			LedgerSequence: ledger,
+		},
+		Validated: s.current > ledger,
+	}, nil
+}
+
+func (s *HarnessSuite) TestFund(c *C) {
+	server := newStandalone()
+	h := New(server)
+	c.Check(h.Genesis.Account().String(), Equals, "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh")
+
+	key, err := h.Fund(context.Background(), amount(c, "1000/XRP"))
+	c.Assert(err, IsNil)
+	c.Check(server.current, Equals, uint32(4))
+	c.Assert(server.txs, HasLen, 1)
+	for _, tx := range server.txs {
+		payment := tx.(*data.Payment)
+		c.Check(payment.Account, Equals, h.Genesis.Account())
+		c.Check(payment.Destination, Equals, key.Account())
+		c.Check(payment.Sequence, Equals, uint32(1))
+		c.Check(payment.Amount.String(), Equals, "1000/XRP")
+		c.Check(*payment.LastLedgerSequence, Equals, uint32(3+DefaultMaxLedgers))
+	}
+
+	// The sequence number of the genesis account is kept track of
+	_, err = h.Fund(context.Background(), amount(c, "1000/XRP"))
+	c.Assert(err, IsNil)
+	c.Check(server.sequences[h.Genesis.Account()], Equals, uint32(2))
+
+	server.result = data.TecNO_DST_INSUF_XRP
+	_, err = h.Fund(context.Background(), amount(c, "1/XRP"))
+	c.Check(err, ErrorMatches, "Funding r.* failed: tecNO_DST_INSUF_XRP")
+}
+
+func (s *HarnessSuite) TestSubmit(c *C) {
+	server := newStandalone()
+	h := New(server)
+	ctx := context.Background()
+	last, err := h.Advance(ctx, 3)
+	c.Assert(err, IsNil)
+	c.Check(last, Equals, uint32(5))
+	_, err = h.Advance(ctx, 0)
+	c.Check(err, ErrorMatches, "No ledger was closed")
+
+	key, err := NewKey()
+	c.Assert(err, IsNil)
+	tx := data.TxFactory[data.ACCOUNT_SET]().(*data.AccountSet)
+	tx.Account = key.Account()
+	server.result = data.TecNO_PERMISSION
+	result, err := h.Submit(ctx, tx, key)
+	c.Assert(err, IsNil)
+	c.Check(result.Validated, Equals, true)
+	c.Check(result.MetaData.TransactionResult, Equals, data.TecNO_PERMISSION)
+	c.Check(result.LedgerSequence, Equals, uint32(6))
+
+	tx = data.TxFactory[data.ACCOUNT_SET]().(*data.AccountSet)
+	tx.Account = key.Account()
+	server.result = data.TefPAST_SEQ
+	_, err = h.Submit(ctx, tx, key)
+	c.Check(err, ErrorMatches, "tefPAST_SEQ .*")
+
+	_, err = h.WaitForValidation(ctx, data.Hash256{})
+	c.Check(err, ErrorMatches, "Transaction 0+ not validated after 20 ledgers")
+}
+
+// TestStandalone runs against a real server when given -rippled
+func (s *HarnessSuite) TestStandalone(c *C) {
+	if *rippled == "" {
+		c.Skip("-rippled not given")
+	}
+	remote, err := websockets.NewRemote(*rippled, false)
+	c.Assert(err, IsNil)
+	defer remote.Close()
+	h := New(remote)
+	ctx := context.Background()
+
+	alice, err := h.Fund(ctx, amount(c, "1000/XRP"))
+	c.Assert(err, IsNil)
+	bob, err := h.Fund(ctx, amount(c, "1000/XRP"))
+	c.Assert(err, IsNil)
+	payment := data.TxFactory[data.PAYMENT]().(*data.Payment)
+	payment.Account, payment.Destination, payment.Amount = alice.Account(), bob.Account(), amount(c, "10/XRP")
+	result, err := h.Submit(ctx, payment, alice)
+	c.Assert(err, IsNil)
+	c.Check(result.MetaData.TransactionResult, Equals, data.TesSUCCESS)
+
+	info, err := remote.AccountInfo(bob.Account())
+	c.Assert(err, IsNil)
+	c.Check(info.AccountData.Balance.String(), Equals, "1010")
+}
@@ -0,0 +1,249 @@
+package websockets
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// Commands which only Clio servers answer
+var clioCommands = map[string]bool{
+	"nft_info":       true,
+	"nft_history":    true,
+	"nfts_by_issuer": true,
+	"ledger_index":   true,
+}
+
+// ErrNotSupported is returned by Route when none of the servers supports
+// the command
+var ErrNotSupported = errors.New("ws: no server supports the command")
+
+// Capabilities describes a server, as probed with server_info
+type Capabilities struct {
+	Clio      bool
+	Version   string // clio_version for Clio, otherwise build_version
+	NetworkID *uint32
+}
+
+// Capabilities returns what server_info says of the server
+func (s *ServerStatus) Capabilities() *Capabilities {
+	if s.ClioVersion != "" {
+		return &Capabilities{Clio: true, Version: s.ClioVersion, NetworkID: s.NetworkID}
+	}
+	return &Capabilities{Version: s.BuildVersion, NetworkID: s.NetworkID}
+}
+
+// Supports is true unless command is one that only Clio answers and the
+// server is rippled. Clio forwards the commands it doesn't answer itself
+// to rippled.
+func (c *Capabilities) Supports(command string) bool {
+	return c.Clio || !clioCommands[command]
+}
+
+// capabilities caches the probe of the server a Remote is connected to,
+// which is forgotten when it reconnects, perhaps to another server
+type capabilities struct {
+	sync.Mutex
+	probed *Capabilities
+}
+
+func (c *capabilities) forget() {
+	c.Lock()
+	defer c.Unlock()
+	c.probed = nil
+}
+
+// Capabilities probes the server with server_info, the first time it is
+// called after connecting
+func (r *Remote) Capabilities(ctx context.Context) (*Capabilities, error) {
+	r.capabilities.Lock()
+	defer r.capabilities.Unlock()
+	if r.capabilities.probed != nil {
+		return r.capabilities.probed, nil
+	}
+	info, err := r.ServerInfoCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.capabilities.probed = info.Capabilities()
+	return r.capabilities.probed, nil
+}
+
+// Route returns the first of remotes which supports command, such as
+// "nft_history", so that Clio-only commands go to Clio in a cluster of
+// rippled and Clio servers. Remotes which can't be probed are passed over.
+func Route(ctx context.Context, command string, remotes ...*Remote) (*Remote, error) {
+	for _, r := range remotes {
+		c, err := r.Capabilities(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			r.log.Error("Can't probe server", "endpoint", r.url, "error", err)
+			continue
+		}
+		if c.Supports(command) {
+			return r, nil
+		}
+	}
+	return nil, ErrNotSupported
+}
+
+// NFTHistoryCommand is account_tx for an NFT
+type NFTHistoryCommand struct {
+	*Command
+	NFTokenID data.Hash256           `json:"nft_id"`
+	MinLedger int64                  `json:"ledger_index_min"`
+	MaxLedger int64                  `json:"ledger_index_max"`
+	Binary    bool                   `json:"binary,omitempty"`
+	Forward   bool                   `json:"forward,omitempty"`
+	Limit     int                    `json:"limit,omitempty"`
+	Marker    map[string]interface{} `json:"marker,omitempty"`
+	Result    *AccountTxResult       `json:"result,omitempty"`
+}
+
+func newNFTHistoryCommand(nftID data.Hash256, options AccountTxOptions, marker map[string]interface{}) *NFTHistoryCommand {
+	return &NFTHistoryCommand{
+		Command:   newCommand("nft_history"),
+		NFTokenID: nftID,
+		MinLedger: options.MinLedger,
+		MaxLedger: options.MaxLedger,
+		Binary:    options.Binary,
+		Forward:   options.Forward,
+		Limit:     options.PageSize,
+		Marker:    marker,
+	}
+}
+
+type NFTsByIssuerCommand struct {
+	*Command
+	Issuer      data.Account        `json:"issuer"`
+	Taxon       *uint32             `json:"nft_taxon,omitempty"`
+	LedgerIndex interface{}         `json:"ledger_index,omitempty"`
+	Limit       uint32              `json:"limit,omitempty"`
+	Marker      interface{}         `json:"marker,omitempty"`
+	Result      *NFTsByIssuerResult `json:"result,omitempty"`
+}
+
+type NFTsByIssuerResult struct {
+	LedgerSequence *uint32         `json:"ledger_index"`
+	Issuer         data.Account    `json:"issuer"`
+	Taxon          *uint32         `json:"nft_taxon,omitempty"`
+	Marker         interface{}     `json:"marker"`
+	NFTs           []NFTInfoResult `json:"nfts"`
+}
+
+type LedgerIndexCommand struct {
+	*Command
+	Date   string             `json:"date,omitempty"`
+	Result *LedgerIndexResult `json:"result,omitempty"`
+}
+
+type LedgerIndexResult struct {
+	LedgerSequence uint32       `json:"ledger_index"`
+	Hash           data.Hash256 `json:"ledger_hash"`
+	Closed         time.Time    `json:"closed"`
+}
+
+// The form of the Date of a LedgerIndexCommand, which Clio parses strictly
+const LedgerIndexDateFormat = "2006-01-02T15:04:05Z"
+
+// NFTHistoryCtx requests the transactions which affected an NFT, calling
+// nft_history as many times as there are markers. The error which ends the
+// transactions early, if any, is sent on the second channel, which is
+// closed after the first. Only Clio servers support nft_history.
+func (r *Remote) NFTHistoryCtx(ctx context.Context, nftID data.Hash256, options AccountTxOptions) (<-chan *data.TransactionWithMetaData, <-chan error) {
+	pages, pageErrs := r.NFTHistoryPagesCtx(ctx, nftID, options)
+	return pageTransactions(ctx, pages, pageErrs)
+}
+
+// NFTHistoryPagesCtx is like NFTHistoryCtx but delivers whole pages, each
+// with the Marker to resume after it, which is nil for the last page
+func (r *Remote) NFTHistoryPagesCtx(ctx context.Context, nftID data.Hash256, options AccountTxOptions) (<-chan *AccountTxResult, <-chan error) {
+	c, errs := make(chan *AccountTxResult), make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(c)
+		marker := options.Marker
+		for {
+			cmd := newNFTHistoryCommand(nftID, options, marker)
+			if err := r.send(ctx, cmd, cmd.Command); err != nil {
+				errs <- err
+				return
+			}
+			options.Filter(cmd.Result)
+			select {
+			case c <- cmd.Result:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+			if cmd.Result.Marker == nil {
+				return
+			}
+			marker = cmd.Result.Marker
+		}
+	}()
+	return c, errs
+}
+
+// Synchronously requests all the NFTs of an issuer, burned ones included,
+// or only those of taxon if it isn't nil. Only Clio servers support
+// nfts_by_issuer.
+func (r *Remote) NFTsByIssuer(issuer data.Account, taxon *uint32, ledgerIndex interface{}) (*NFTsByIssuerResult, error) {
+	return r.NFTsByIssuerCtx(context.Background(), issuer, taxon, ledgerIndex)
+}
+
+// NFTsByIssuerCtx is like NFTsByIssuer but gives up when ctx is done
+func (r *Remote) NFTsByIssuerCtx(ctx context.Context, issuer data.Account, taxon *uint32, ledgerIndex interface{}) (*NFTsByIssuerResult, error) {
+	var (
+		nfts   []NFTInfoResult
+		marker interface{}
+	)
+	for {
+		cmd := &NFTsByIssuerCommand{
+			Command:     newCommand("nfts_by_issuer"),
+			Issuer:      issuer,
+			Taxon:       taxon,
+			LedgerIndex: ledgerIndex,
+			Limit:       100,
+			Marker:      marker,
+		}
+		if err := r.send(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		nfts = append(nfts, cmd.Result.NFTs...)
+		if cmd.Result.Marker == nil {
+			cmd.Result.NFTs = nfts
+			return cmd.Result, nil
+		}
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests the last validated ledger which closed at or
+// before date, or the last validated ledger if date is zero. Only Clio
+// servers support ledger_index.
+func (r *Remote) LedgerIndex(date time.Time) (*LedgerIndexResult, error) {
+	return r.LedgerIndexCtx(context.Background(), date)
+}
+
+// LedgerIndexCtx is like LedgerIndex but gives up when ctx is done
+func (r *Remote) LedgerIndexCtx(ctx context.Context, date time.Time) (*LedgerIndexResult, error) {
+	cmd := &LedgerIndexCommand{
+		Command: newCommand("ledger_index"),
+	}
+	if !date.IsZero() {
+		cmd.Date = date.UTC().Format(LedgerIndexDateFormat)
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
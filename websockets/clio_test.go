@@ -0,0 +1,166 @@
+package websockets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync/atomic"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	. "gopkg.in/check.v1"
+)
+
+type ClioSuite struct{}
+
+var _ = Suite(&ClioSuite{})
+
+func serverInfoServer(info string, probes *int32) func(cmd map[string]interface{}) []string {
+	return func(cmd map[string]interface{}) []string {
+		if cmd["command"] == "server_info" {
+			atomic.AddInt32(probes, 1)
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{"info":` + info + `}}`}
+		}
+		return []string{`{"id":$ID,"status":"success","type":"response","result":{}}`}
+	}
+}
+
+func (s *ClioSuite) TestRoute(c *C) {
+	var rippledProbes, clioProbes int32
+	rippledServer := scriptedServer(serverInfoServer(`{"build_version":"2.3.0","network_id":1}`, &rippledProbes))
+	defer rippledServer.Close()
+	clioServer := scriptedServer(serverInfoServer(`{"clio_version":"2.3.1","rippled_version":"2.3.0"}`, &clioProbes))
+	defer clioServer.Close()
+	rippled, err := NewRemote(wsURL(rippledServer), false)
+	c.Assert(err, IsNil)
+	defer rippled.Close()
+	clio, err := NewRemote(wsURL(clioServer), false)
+	c.Assert(err, IsNil)
+	defer clio.Close()
+
+	ctx := context.Background()
+	caps, err := clio.Capabilities(ctx)
+	c.Assert(err, IsNil)
+	c.Check(*caps, DeepEquals, Capabilities{Clio: true, Version: "2.3.1"})
+	caps, err = rippled.Capabilities(ctx)
+	c.Assert(err, IsNil)
+	c.Check(caps.Clio, Equals, false)
+	c.Check(caps.Version, Equals, "2.3.0")
+	c.Check(*caps.NetworkID, Equals, uint32(1))
+	c.Check(caps.Supports("nft_history"), Equals, false)
+	c.Check(caps.Supports("account_tx"), Equals, true)
+
+	r, err := Route(ctx, "nft_history", rippled, clio)
+	c.Assert(err, IsNil)
+	c.Check(r, Equals, clio)
+	r, err = Route(ctx, "account_tx", rippled, clio)
+	c.Assert(err, IsNil)
+	c.Check(r, Equals, rippled)
+	_, err = Route(ctx, "nfts_by_issuer", rippled)
+	c.Check(err, Equals, ErrNotSupported)
+
+	// Each server is only probed once
+	c.Check(atomic.LoadInt32(&rippledProbes), Equals, int32(1))
+	c.Check(atomic.LoadInt32(&clioProbes), Equals, int32(1))
+
+	// A server which can't be probed is passed over
+	rippled.Close()
+	rippled.capabilities.forget()
+	r, err = Route(ctx, "account_tx", rippled, clio)
+	c.Assert(err, IsNil)
+	c.Check(r, Equals, clio)
+}
+
+func (s *ClioSuite) TestNFTHistory(c *C) {
+	b, err := ioutil.ReadFile("testdata/account_tx.json")
+	c.Assert(err, IsNil)
+	var fixture struct {
+		Result struct {
+			Transactions []json.RawMessage `json:"transactions"`
+		} `json:"result"`
+	}
+	c.Assert(json.Unmarshal(b, &fixture), IsNil)
+	c.Assert(fixture.Result.Transactions, HasLen, 2)
+
+	markers := make(chan interface{}, 2)
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		c.Check(cmd["command"], Equals, "nft_history")
+		c.Check(cmd["nft_id"], Equals, "000800006203F49C21D5D6E022CB16DE3538F248662FC73C00000C1900000000")
+		markers <- cmd["marker"]
+		if cmd["marker"] == nil {
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{"marker":{"ledger":1,"seq":2},"transactions":[` + string(fixture.Result.Transactions[0]) + `]}}`}
+		}
+		return []string{`{"id":$ID,"status":"success","type":"response","result":{"transactions":[` + string(fixture.Result.Transactions[1]) + `]}}`}
+	})
+	defer server.Close()
+	r, err := NewRemote(wsURL(server), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	nftID, err := data.NewHash256("000800006203F49C21D5D6E022CB16DE3538F248662FC73C00000C1900000000")
+	c.Assert(err, IsNil)
+	txs, errs := r.NFTHistoryCtx(context.Background(), *nftID, AccountTxOptions{MinLedger: -1, MaxLedger: -1})
+	var types []string
+	for tx := range txs {
+		types = append(types, tx.GetType())
+	}
+	c.Check(<-errs, IsNil)
+	c.Check(types, DeepEquals, []string{"OfferCancel", "OfferCreate"})
+	c.Check(<-markers, IsNil)
+	c.Check(<-markers, DeepEquals, map[string]interface{}{"ledger": 1.0, "seq": 2.0})
+}
+
+func (s *ClioSuite) TestNFTsByIssuer(c *C) {
+	nft := `{"nft_id":"000800006203F49C21D5D6E022CB16DE3538F248662FC73C00000C1900000000","ledger_index":90,"owner":"rwpxNWdpKu2QVgrh5LQXEygYLshhgnRL1Y","is_burned":%s,"flags":8,"transfer_fee":0,"issuer":"rwpxNWdpKu2QVgrh5LQXEygYLshhgnRL1Y","nft_taxon":7,"nft_serial":%d}`
+	ledgers := make(chan interface{}, 2)
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		c.Check(cmd["nft_taxon"], Equals, 7.0)
+		ledgers <- cmd["ledger_index"]
+		if cmd["marker"] == nil {
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{"issuer":"rwpxNWdpKu2QVgrh5LQXEygYLshhgnRL1Y","ledger_index":90,"marker":"A","nft_taxon":7,"nfts":[` + fmt.Sprintf(nft, "false", 1) + `]}}`}
+		}
+		return []string{`{"id":$ID,"status":"success","type":"response","result":{"issuer":"rwpxNWdpKu2QVgrh5LQXEygYLshhgnRL1Y","ledger_index":90,"nft_taxon":7,"nfts":[` + fmt.Sprintf(nft, "true", 2) + `]}}`}
+	})
+	defer server.Close()
+	r, err := NewRemote(wsURL(server), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	issuer, err := data.NewAccountFromAddress("rwpxNWdpKu2QVgrh5LQXEygYLshhgnRL1Y")
+	c.Assert(err, IsNil)
+	taxon := uint32(7)
+	result, err := r.NFTsByIssuer(*issuer, &taxon, "validated")
+	c.Assert(err, IsNil)
+	c.Assert(result.NFTs, HasLen, 2)
+	c.Check(result.NFTs[0].Serial, Equals, uint32(1))
+	c.Check(result.NFTs[0].IsBurned, Equals, false)
+	c.Check(result.NFTs[1].IsBurned, Equals, true)
+	c.Check(result.NFTs[1].Taxon, Equals, uint32(7))
+	c.Check(<-ledgers, Equals, "validated")
+	c.Check(<-ledgers, Equals, 90.0)
+}
+
+func (s *ClioSuite) TestLedgerIndex(c *C) {
+	dates := make(chan interface{}, 2)
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		dates <- cmd["date"]
+		return []string{`{"id":$ID,"status":"success","type":"response","result":{"ledger_index":90000,"ledger_hash":"4109C6F2045FC7EFF4CDE8F9905D19C28820D86304080FF886B299F0206E42B5","closed":"2024-05-01T11:59:59Z"}}`}
+	})
+	defer server.Close()
+	r, err := NewRemote(wsURL(server), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	date := time.Date(2024, 5, 1, 14, 0, 0, 500, time.FixedZone("", 2*60*60))
+	result, err := r.LedgerIndex(date)
+	c.Assert(err, IsNil)
+	c.Check(result.LedgerSequence, Equals, uint32(90000))
+	c.Check(result.Hash.String(), Equals, "4109C6F2045FC7EFF4CDE8F9905D19C28820D86304080FF886B299F0206E42B5")
+	c.Check(result.Closed.Equal(time.Date(2024, 5, 1, 11, 59, 59, 0, time.UTC)), Equals, true)
+
+	_, err = r.LedgerIndex(time.Time{})
+	c.Assert(err, IsNil)
+	c.Check(<-dates, Equals, "2024-05-01T12:00:00Z")
+	c.Check(<-dates, IsNil)
+}
@@ -1,10 +1,17 @@
 package websockets
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
 	"sync/atomic"
+	"time"
 
+	"github.com/kr-jaydeepp/ripple/crypto"
 	"github.com/kr-jaydeepp/ripple/data"
 )
 
@@ -13,12 +20,17 @@ var counter uint64
 type Syncer interface {
 	Done()
 	Fail(message string)
+	ID() uint64
+	SetID(uint64)
 }
 
+// CommandError is the error rippled returns for a command, or that a
+// command failed before it had a response, when the Name is "Client Error"
 type CommandError struct {
-	Name    string `json:"error"`
-	Code    int    `json:"error_code"`
-	Message string `json:"error_message"`
+	Name    string          `json:"error"`
+	Code    int             `json:"error_code"`
+	Message string          `json:"error_message"`
+	Request json.RawMessage `json:"request,omitempty"` // The command, as echoed by rippled
 }
 
 type Command struct {
@@ -28,6 +40,49 @@ type Command struct {
 	Type   string        `json:"type,omitempty"`
 	Status string        `json:"status,omitempty"`
 	Ready  chan struct{} `json:"-"`
+
+	// The api_version to send, which overrides the Remote's when non-zero
+	APIVersion int `json:"api_version,omitempty"`
+
+	// Overrides the Remote's timeout for this command when non-zero
+	Timeout  time.Duration `json:"-"`
+	timedOut *TimeoutError
+	rejected error
+}
+
+// TimeoutError is returned when no response to a command arrives
+// within the allowed time.
+type TimeoutError struct {
+	Command string
+	Id      uint64
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s command %d timed out after %s", e.Command, e.Id, e.Timeout)
+}
+
+// MessageTooLargeError is returned when the response to a command is
+// larger than RemoteOptions.MaxMessageSize. The response is discarded, but
+// the connection is kept.
+type MessageTooLargeError struct {
+	Id    uint64 // The command, or zero if it couldn't be read
+	Size  int64
+	Limit int64
+}
+
+func (e *MessageTooLargeError) Error() string {
+	return fmt.Sprintf("ws: message of %d bytes is larger than the limit of %d", e.Size, e.Limit)
+}
+
+// expirer is satisfied by every type embedding *Command
+type expirer interface {
+	expire(*TimeoutError)
+}
+
+// commander is satisfied by every type embedding *Command
+type commander interface {
+	command() *Command
 }
 
 func (c *Command) Done() {
@@ -43,6 +98,43 @@ func (c *Command) Fail(message string) {
 	c.Ready <- struct{}{}
 }
 
+func (c *Command) ID() uint64 {
+	return c.Id
+}
+
+func (c *Command) SetID(id uint64) {
+	c.Id = id
+}
+
+func (c *Command) command() *Command {
+	return c
+}
+
+func (c *Command) expire(err *TimeoutError) {
+	c.timedOut = err
+	c.Ready <- struct{}{}
+}
+
+// reject fails the command with an error other than a CommandError
+func (c *Command) reject(err error) {
+	c.rejected = err
+	c.Ready <- struct{}{}
+}
+
+// err returns the reason the command failed, or nil if it succeeded
+func (c *Command) err() error {
+	switch {
+	case c.timedOut != nil:
+		return c.timedOut
+	case c.rejected != nil:
+		return c.rejected
+	case c.CommandError != nil:
+		return c.CommandError
+	default:
+		return nil
+	}
+}
+
 func (c *Command) IncrementId() {
 	c.Id = atomic.AddUint64(&counter, 1)
 }
@@ -51,11 +143,13 @@ func (e *CommandError) Error() string {
 	return fmt.Sprintf("%s %d %s", e.Name, e.Code, e.Message)
 }
 
+// Ready is buffered so that Done and Fail never block the run loop,
+// even when the caller has stopped waiting.
 func newCommand(command string) *Command {
 	return &Command{
 		Id:    atomic.AddUint64(&counter, 1),
 		Name:  command,
-		Ready: make(chan struct{}),
+		Ready: make(chan struct{}, 1),
 	}
 }
 
@@ -76,13 +170,77 @@ type AccountTxResult struct {
 	Transactions data.TransactionSlice  `json:"transactions,omitempty"`
 }
 
-func newAccountTxCommand(account data.Account, pageSize int, marker map[string]interface{}, minLedger, maxLedger int64) *AccountTxCommand {
+// binaryTransaction is a transaction of an account_tx result in binary
+// form, which is decoded locally
+type binaryTransaction struct {
+	LedgerSequence uint32 `json:"ledger_index"`
+	TxBlob         string `json:"tx_blob"`
+	Meta           string `json:"meta"`
+	MetaBlob       string `json:"meta_blob"` // Instead of Meta under api_version 2
+}
+
+func (r *AccountTxResult) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Marker       map[string]interface{} `json:"marker"`
+		Transactions json.RawMessage        `json:"transactions"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	r.Marker, r.Transactions = raw.Marker, nil
+	if len(raw.Transactions) == 0 {
+		return nil
+	}
+	var probe []struct {
+		TxBlob string `json:"tx_blob"`
+	}
+	if err := json.Unmarshal(raw.Transactions, &probe); err != nil {
+		return err
+	}
+	if len(probe) == 0 || probe[0].TxBlob == "" {
+		return json.Unmarshal(raw.Transactions, &r.Transactions)
+	}
+	var binary []binaryTransaction
+	if err := json.Unmarshal(raw.Transactions, &binary); err != nil {
+		return err
+	}
+	for _, tx := range binary {
+		txm, err := tx.read()
+		if err != nil {
+			return err
+		}
+		r.Transactions = append(r.Transactions, txm)
+	}
+	return nil
+}
+
+// read decodes the transaction, whose hash is that of its blob
+func (t *binaryTransaction) read() (*data.TransactionWithMetaData, error) {
+	tx, err := hex.DecodeString(t.TxBlob)
+	if err != nil {
+		return nil, err
+	}
+	if t.Meta == "" {
+		t.Meta = t.MetaBlob
+	}
+	meta, err := hex.DecodeString(t.Meta)
+	if err != nil {
+		return nil, err
+	}
+	var hash data.Hash256
+	copy(hash[:], crypto.Sha512Half(append(data.HP_TRANSACTION_ID.Bytes(), tx...)))
+	return data.ReadTransactionAndMetadata(bytes.NewReader(tx), bytes.NewReader(meta), hash, t.LedgerSequence)
+}
+
+func newAccountTxCommand(account data.Account, options AccountTxOptions, marker map[string]interface{}) *AccountTxCommand {
 	return &AccountTxCommand{
 		Command:   newCommand("account_tx"),
 		Account:   account,
-		MinLedger: minLedger,
-		MaxLedger: maxLedger,
-		Limit:     pageSize,
+		MinLedger: options.MinLedger,
+		MaxLedger: options.MaxLedger,
+		Binary:    options.Binary,
+		Forward:   options.Forward,
+		Limit:     options.PageSize,
 		Marker:    marker,
 	}
 }
@@ -124,6 +282,59 @@ func (txr *TxResult) UnmarshalJSON(b []byte) error {
 	return json.Unmarshal(b, &txr.TransactionWithMetaData)
 }
 
+type TxHistoryCommand struct {
+	*Command
+	Start  uint32           `json:"start"`
+	Result *TxHistoryResult `json:"result,omitempty"`
+}
+
+type TxHistoryResult struct {
+	Index uint32                `json:"index"`
+	Txs   data.TransactionSlice `json:"txs"`
+}
+
+type TransactionEntryCommand struct {
+	*Command
+	Transaction data.Hash256            `json:"tx_hash"`
+	LedgerIndex interface{}             `json:"ledger_index"`
+	Result      *TransactionEntryResult `json:"result,omitempty"`
+}
+
+type TransactionEntryResult struct {
+	data.TransactionWithMetaData
+}
+
+// transaction_entry splits the transaction and its metadata into
+// "tx_json" and "metadata", so rename them to the "tx" and "meta" form
+// which TransactionWithMetaData already understands. Under api_version 2
+// the hash is beside "tx_json" rather than in it.
+func (r *TransactionEntryResult) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		LedgerSequence uint32          `json:"ledger_index"`
+		Hash           *data.Hash256   `json:"hash"`
+		Tx             json.RawMessage `json:"tx_json"`
+		Meta           json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	split, err := json.Marshal(struct {
+		Tx   json.RawMessage `json:"tx"`
+		Meta json.RawMessage `json:"meta"`
+	}{raw.Tx, raw.Meta})
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(split, &r.TransactionWithMetaData); err != nil {
+		return err
+	}
+	r.LedgerSequence = raw.LedgerSequence
+	if hash := r.GetHash(); hash != nil && raw.Hash != nil {
+		*hash = *raw.Hash
+	}
+	return nil
+}
+
 type SubmitCommand struct {
 	*Command
 	TxBlob string        `json:"tx_blob"`
@@ -138,6 +349,12 @@ type SubmitResult struct {
 	Tx                  interface{}            `json:"tx_json"`
 }
 
+type SubmitMultisignedCommand struct {
+	*Command
+	TxJson map[string]interface{} `json:"tx_json"`
+	Result *SubmitResult          `json:"result,omitempty"`
+}
+
 type LedgerCommand struct {
 	*Command
 	Ledger       interface{}   `json:"ledger"`
@@ -164,6 +381,116 @@ type LedgerHeaderResult struct {
 	LedgerData     data.VariableLength `json:"ledger_data"`
 }
 
+type LedgerClosedCommand struct {
+	*Command
+	Result *LedgerClosedResult `json:"result,omitempty"`
+}
+
+type LedgerClosedResult struct {
+	LedgerSequence uint32       `json:"ledger_index"`
+	Hash           data.Hash256 `json:"ledger_hash"`
+}
+
+type LedgerCurrentCommand struct {
+	*Command
+	Result *LedgerCurrentResult `json:"result,omitempty"`
+}
+
+type LedgerCurrentResult struct {
+	LedgerSequence uint32 `json:"ledger_current_index"`
+}
+
+// LedgerEntrySelector picks out a single ledger entry.
+// Exactly one field should be set.
+type LedgerEntrySelector struct {
+	Index              *data.Hash256               `json:"index,omitempty"`
+	AccountRoot        *data.Account               `json:"account_root,omitempty"`
+	Offer              *OfferSelector              `json:"offer,omitempty"`
+	RippleState        *RippleStateSelector        `json:"ripple_state,omitempty"`
+	Escrow             *EscrowSelector             `json:"escrow,omitempty"`
+	PayChannel         *data.Hash256               `json:"payment_channel,omitempty"`
+	Check              *data.Hash256               `json:"check,omitempty"`
+	Ticket             *TicketSelector             `json:"ticket,omitempty"`
+	NFTokenPage        *data.Hash256               `json:"nft_page,omitempty"`
+	AMM                *AMMSelector                `json:"amm,omitempty"`
+	Oracle             *OracleSelector             `json:"oracle,omitempty"`
+	DepositPreauth     *DepositPreauthSelector     `json:"deposit_preauth,omitempty"`
+	Credential         *CredentialSelector         `json:"credential,omitempty"`
+	PermissionedDomain *PermissionedDomainSelector `json:"permissioned_domain,omitempty"`
+}
+
+type OfferSelector struct {
+	Account  data.Account `json:"account"`
+	Sequence uint32       `json:"seq"`
+}
+
+type RippleStateSelector struct {
+	Accounts [2]data.Account `json:"accounts"`
+	Currency data.Currency   `json:"currency"`
+}
+
+type EscrowSelector struct {
+	Owner    data.Account `json:"owner"`
+	Sequence uint32       `json:"seq"`
+}
+
+type TicketSelector struct {
+	Account        data.Account `json:"account"`
+	TicketSequence uint32       `json:"ticket_seq"`
+}
+
+type AMMSelector struct {
+	Asset  data.Asset `json:"asset"`
+	Asset2 data.Asset `json:"asset2"`
+}
+
+// OracleSelector picks out a price oracle by its owner and document ID
+type OracleSelector struct {
+	Account          data.Account `json:"account"`
+	OracleDocumentID uint32       `json:"oracle_document_id"`
+}
+
+type DepositPreauthSelector struct {
+	Owner      data.Account `json:"owner"`
+	Authorized data.Account `json:"authorized"`
+}
+
+// CredentialSelector picks out a credential by its subject, issuer and type
+type CredentialSelector struct {
+	Subject        data.Account        `json:"subject"`
+	Issuer         data.Account        `json:"issuer"`
+	CredentialType data.VariableLength `json:"credential_type"`
+}
+
+type PermissionedDomainSelector struct {
+	Account  data.Account `json:"account"`
+	Sequence uint32       `json:"seq"`
+}
+
+type LedgerEntryCommand struct {
+	*Command
+	LedgerEntrySelector
+	LedgerIndex interface{}        `json:"ledger_index,omitempty"`
+	Binary      bool               `json:"binary"`
+	Result      *LedgerEntryResult `json:"result,omitempty"`
+}
+
+type LedgerEntryResult struct {
+	LedgerSequence *uint32      `json:"ledger_index"`
+	Index          data.Hash256 `json:"index"`
+	NodeBinary     string       `json:"node_binary"`
+}
+
+// LedgerEntry decodes the binary node, which like those from
+// ledger_data is followed by its index
+func (r *LedgerEntryResult) LedgerEntry() (data.LedgerEntry, error) {
+	b, err := hex.DecodeString(r.NodeBinary)
+	if err != nil {
+		return nil, err
+	}
+	return data.ReadLedgerEntry(bytes.NewReader(append(b, r.Index[:]...)), data.Hash256{})
+}
+
 type LedgerDataCommand struct {
 	*Command
 	Ledger interface{}       `json:"ledger"`
@@ -175,6 +502,7 @@ type BinaryLedgerDataCommand struct {
 	*Command
 	Ledger interface{}             `json:"ledger"`
 	Binary bool                    `json:"binary"`
+	Limit  int                     `json:"limit,omitempty"`
 	Marker *data.Hash256           `json:"marker,omitempty"`
 	Result *BinaryLedgerDataResult `json:"result,omitempty"`
 }
@@ -260,6 +588,210 @@ type AccountOffersResult struct {
 	Offers         data.AccountOfferSlice `json:"offers"`
 }
 
+type AccountObjectsCommand struct {
+	*Command
+	Account     data.Account          `json:"account"`
+	Type        string                `json:"type,omitempty"`
+	Limit       uint32                `json:"limit"`
+	LedgerIndex interface{}           `json:"ledger_index,omitempty"`
+	Marker      interface{}           `json:"marker,omitempty"`
+	Result      *AccountObjectsResult `json:"result,omitempty"`
+}
+
+type AccountObjectsResult struct {
+	LedgerSequence *uint32               `json:"ledger_index"`
+	Account        data.Account          `json:"account"`
+	Marker         interface{}           `json:"marker"`
+	AccountObjects data.LedgerEntrySlice `json:"account_objects"`
+}
+
+// Checks returns the checks among the objects
+func (r *AccountObjectsResult) Checks() []*data.Check {
+	var checks []*data.Check
+	for _, le := range r.AccountObjects {
+		if check, ok := le.(*data.Check); ok {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// Tickets returns the tickets among the objects
+func (r *AccountObjectsResult) Tickets() []*data.Ticket {
+	var tickets []*data.Ticket
+	for _, le := range r.AccountObjects {
+		if ticket, ok := le.(*data.Ticket); ok {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets
+}
+
+// DepositPreauths returns the deposit preauthorisations among the objects
+func (r *AccountObjectsResult) DepositPreauths() []*data.DepositPreAuth {
+	var preauths []*data.DepositPreAuth
+	for _, le := range r.AccountObjects {
+		if preauth, ok := le.(*data.DepositPreAuth); ok {
+			preauths = append(preauths, preauth)
+		}
+	}
+	return preauths
+}
+
+type AccountChannelsCommand struct {
+	*Command
+	Account     data.Account           `json:"account"`
+	Destination *data.Account          `json:"destination_account,omitempty"`
+	Limit       uint32                 `json:"limit"`
+	LedgerIndex interface{}            `json:"ledger_index,omitempty"`
+	Marker      interface{}            `json:"marker,omitempty"`
+	Result      *AccountChannelsResult `json:"result,omitempty"`
+}
+
+type AccountChannelsResult struct {
+	LedgerSequence *uint32          `json:"ledger_index"`
+	Account        data.Account     `json:"account"`
+	Marker         interface{}      `json:"marker"`
+	Channels       []PaymentChannel `json:"channels"`
+}
+
+// PaymentChannel summarises a PayChannel ledger entry. Amount and
+// Balance are native values.
+type PaymentChannel struct {
+	ChannelId      data.Hash256    `json:"channel_id"`
+	Account        data.Account    `json:"account"`
+	Destination    data.Account    `json:"destination_account"`
+	Amount         data.Value      `json:"amount"`
+	Balance        data.Value      `json:"balance"`
+	PublicKey      *data.PublicKey `json:"public_key_hex,omitempty"`
+	SettleDelay    uint32          `json:"settle_delay"`
+	Expiration     *uint32         `json:"expiration,omitempty"`
+	CancelAfter    *uint32         `json:"cancel_after,omitempty"`
+	SourceTag      *uint32         `json:"source_tag,omitempty"`
+	DestinationTag *uint32         `json:"destination_tag,omitempty"`
+}
+
+type AccountCurrenciesCommand struct {
+	*Command
+	Account     data.Account             `json:"account"`
+	LedgerIndex interface{}              `json:"ledger_index,omitempty"`
+	Result      *AccountCurrenciesResult `json:"result,omitempty"`
+}
+
+type AccountCurrenciesResult struct {
+	LedgerSequence *uint32         `json:"ledger_index"`
+	Receive        []data.Currency `json:"receive_currencies"`
+	Send           []data.Currency `json:"send_currencies"`
+}
+
+type ChannelAuthorizeCommand struct {
+	*Command
+	ChannelId data.Hash256            `json:"channel_id"`
+	Amount    data.Value              `json:"amount"`
+	Secret    string                  `json:"secret"`
+	Result    *ChannelAuthorizeResult `json:"result,omitempty"`
+}
+
+type ChannelAuthorizeResult struct {
+	Signature data.VariableLength `json:"signature"`
+}
+
+type ChannelVerifyCommand struct {
+	*Command
+	ChannelId data.Hash256         `json:"channel_id"`
+	Amount    data.Value           `json:"amount"`
+	PublicKey data.PublicKey       `json:"public_key"`
+	Signature data.VariableLength  `json:"signature"`
+	Result    *ChannelVerifyResult `json:"result,omitempty"`
+}
+
+type ChannelVerifyResult struct {
+	Verified bool `json:"signature_verified"`
+}
+
+type DepositAuthorizedCommand struct {
+	*Command
+	Source      data.Account             `json:"source_account"`
+	Destination data.Account             `json:"destination_account"`
+	LedgerIndex interface{}              `json:"ledger_index,omitempty"`
+	Result      *DepositAuthorizedResult `json:"result,omitempty"`
+}
+
+type DepositAuthorizedResult struct {
+	Authorized bool `json:"deposit_authorized"`
+}
+
+type GatewayBalancesCommand struct {
+	*Command
+	Account     data.Account           `json:"account"`
+	HotWallets  []data.Account         `json:"hotwallet,omitempty"`
+	LedgerIndex interface{}            `json:"ledger_index,omitempty"`
+	Result      *GatewayBalancesResult `json:"result,omitempty"`
+}
+
+type CurrencyBalance struct {
+	Currency data.Currency       `json:"currency"`
+	Value    data.NonNativeValue `json:"value"`
+}
+
+// GatewayBalancesResult groups an issuer's balances by currency.
+// Obligations exclude the balances of the hot wallets, which are
+// listed separately along with any assets issued to the account.
+type GatewayBalancesResult struct {
+	LedgerSequence *uint32                               `json:"ledger_index"`
+	Account        data.Account                          `json:"account"`
+	Obligations    map[data.Currency]data.NonNativeValue `json:"obligations"`
+	Balances       map[data.Account][]CurrencyBalance    `json:"balances"`
+	FrozenBalances map[data.Account][]CurrencyBalance    `json:"frozen_balances"`
+	Assets         map[data.Account][]CurrencyBalance    `json:"assets"`
+	Locked         map[data.Currency]data.NonNativeValue `json:"locked"`
+}
+
+type NoRippleCheckCommand struct {
+	*Command
+	Account      data.Account         `json:"account"`
+	Role         string               `json:"role"`
+	Transactions bool                 `json:"transactions"`
+	Limit        uint32               `json:"limit,omitempty"`
+	LedgerIndex  interface{}          `json:"ledger_index,omitempty"`
+	Result       *NoRippleCheckResult `json:"result,omitempty"`
+}
+
+type NoRippleCheckResult struct {
+	LedgerSequence uint32              `json:"ledger_current_index"`
+	Problems       []string            `json:"problems"`
+	Transactions   ProblemTransactions `json:"transactions"`
+}
+
+// ProblemTransactions are the fixes suggested by noripple_check, ready
+// to be signed. rippled sends their Fee as a number rather than the
+// usual string.
+type ProblemTransactions []data.Transaction
+
+var (
+	txTypeRegex     = regexp.MustCompile(`"TransactionType"\s*:\s*"(\w+)"`)
+	numericFeeRegex = regexp.MustCompile(`"Fee"\s*:\s*(\d+)`)
+)
+
+func (p *ProblemTransactions) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	for _, tx := range raw {
+		match := txTypeRegex.FindSubmatch(tx)
+		if match == nil {
+			return fmt.Errorf("Missing TransactionType")
+		}
+		t := data.GetTxFactoryByType(string(match[1]))()
+		if err := json.Unmarshal(numericFeeRegex.ReplaceAll(tx, []byte(`"Fee":"$1"`)), t); err != nil {
+			return err
+		}
+		*p = append(*p, t)
+	}
+	return nil
+}
+
 type BookOffersCommand struct {
 	*Command
 	LedgerIndex interface{}  `json:"ledger_index,omitempty"`
@@ -275,12 +807,233 @@ type BookOffersResult struct {
 	Offers         []data.OrderBookOffer `json:"offers"`
 }
 
+type AccountNFTsCommand struct {
+	*Command
+	Account     data.Account       `json:"account"`
+	Limit       uint32             `json:"limit"`
+	LedgerIndex interface{}        `json:"ledger_index,omitempty"`
+	Marker      interface{}        `json:"marker,omitempty"`
+	Result      *AccountNFTsResult `json:"result,omitempty"`
+}
+
+type AccountNFTsResult struct {
+	LedgerSequence *uint32      `json:"ledger_index"`
+	Account        data.Account `json:"account"`
+	Marker         interface{}  `json:"marker"`
+	NFTs           []AccountNFT `json:"account_nfts"`
+}
+
+type AccountNFT struct {
+	NFTokenID   data.Hash256        `json:"NFTokenID"`
+	Issuer      data.Account        `json:"Issuer"`
+	Flags       data.NFTokenFlag    `json:"Flags"`
+	TransferFee uint16              `json:"TransferFee"`
+	Taxon       uint32              `json:"NFTokenTaxon"`
+	Serial      uint32              `json:"nft_serial"`
+	URI         data.VariableLength `json:"URI,omitempty"`
+}
+
+// Used for both nft_buy_offers and nft_sell_offers
+type NFTOffersCommand struct {
+	*Command
+	NFTokenID   data.Hash256     `json:"nft_id"`
+	Limit       uint32           `json:"limit"`
+	LedgerIndex interface{}      `json:"ledger_index,omitempty"`
+	Marker      interface{}      `json:"marker,omitempty"`
+	Result      *NFTOffersResult `json:"result,omitempty"`
+}
+
+type NFTOffersResult struct {
+	LedgerSequence *uint32      `json:"ledger_index"`
+	NFTokenID      data.Hash256 `json:"nft_id"`
+	Marker         interface{}  `json:"marker"`
+	Offers         []NFTOffer   `json:"offers"`
+}
+
+// NFTOffer summarises an NFTokenOffer ledger entry
+type NFTOffer struct {
+	Index       data.Hash256         `json:"nft_offer_index"`
+	Owner       data.Account         `json:"owner"`
+	Amount      data.Amount          `json:"amount"`
+	Flags       data.LedgerEntryFlag `json:"flags"`
+	Destination *data.Account        `json:"destination,omitempty"`
+	Expiration  *uint32              `json:"expiration,omitempty"`
+}
+
+type NFTInfoCommand struct {
+	*Command
+	NFTokenID   data.Hash256   `json:"nft_id"`
+	LedgerIndex interface{}    `json:"ledger_index,omitempty"`
+	Result      *NFTInfoResult `json:"result,omitempty"`
+}
+
+type NFTInfoResult struct {
+	LedgerSequence uint32              `json:"ledger_index"`
+	NFTokenID      data.Hash256        `json:"nft_id"`
+	Owner          data.Account        `json:"owner"`
+	Issuer         data.Account        `json:"issuer"`
+	IsBurned       bool                `json:"is_burned"`
+	Flags          data.NFTokenFlag    `json:"flags"`
+	TransferFee    uint16              `json:"transfer_fee"`
+	Taxon          uint32              `json:"nft_taxon"`
+	Serial         uint32              `json:"nft_serial"`
+	URI            data.VariableLength `json:"uri,omitempty"`
+}
+
+// Identifies an AMM by either its pair of assets or its account
+type AMMInfoCommand struct {
+	*Command
+	Asset       *data.Asset    `json:"asset,omitempty"`
+	Asset2      *data.Asset    `json:"asset2,omitempty"`
+	AMMAccount  *data.Account  `json:"amm_account,omitempty"`
+	LedgerIndex interface{}    `json:"ledger_index,omitempty"`
+	Result      *AMMInfoResult `json:"result,omitempty"`
+}
+
+type AMMInfoResult struct {
+	LedgerSequence *uint32 `json:"ledger_index"`
+	AMM            AMMInfo `json:"amm"`
+}
+
+// AMMInfo describes an AMM's pool. Amount and Amount2 are the pool's
+// holdings of each asset and LPToken the outstanding LP tokens.
+type AMMInfo struct {
+	Account      data.Account    `json:"account"`
+	Amount       data.Amount     `json:"amount"`
+	Amount2      data.Amount     `json:"amount2"`
+	AssetFrozen  bool            `json:"asset_frozen,omitempty"`
+	Asset2Frozen bool            `json:"asset2_frozen,omitempty"`
+	LPToken      data.Amount     `json:"lp_token"`
+	TradingFee   uint16          `json:"trading_fee"`
+	AuctionSlot  *AMMAuctionSlot `json:"auction_slot,omitempty"`
+	VoteSlots    []AMMVoteSlot   `json:"vote_slots,omitempty"`
+}
+
+type AMMAuctionSlot struct {
+	Account      data.Account `json:"account"`
+	AuthAccounts []struct {
+		Account data.Account `json:"account"`
+	} `json:"auth_accounts,omitempty"`
+	DiscountedFee uint16      `json:"discounted_fee"`
+	Expiration    string      `json:"expiration"`
+	Price         data.Amount `json:"price"`
+	TimeInterval  uint32      `json:"time_interval"`
+}
+
+type AMMVoteSlot struct {
+	Account    data.Account `json:"account"`
+	TradingFee uint16       `json:"trading_fee"`
+	VoteWeight uint32       `json:"vote_weight"`
+}
+
+type GetAggregatePriceCommand struct {
+	*Command
+	BaseAsset     data.Currency            `json:"base_asset"`
+	QuoteAsset    data.Currency            `json:"quote_asset"`
+	Oracles       []OracleSelector         `json:"oracles"`
+	Trim          uint32                   `json:"trim,omitempty"`
+	TimeThreshold uint32                   `json:"time_threshold,omitempty"`
+	LedgerIndex   interface{}              `json:"ledger_index,omitempty"`
+	Result        *GetAggregatePriceResult `json:"result,omitempty"`
+}
+
+// GetAggregatePriceResult has the statistics of the prices of a pair from
+// a set of oracles. TrimmedSet leaves out the highest and lowest Trim
+// percent of them. Time is that of the latest price, in seconds since the
+// Unix epoch.
+type GetAggregatePriceResult struct {
+	EntireSet          AggregatePriceSet   `json:"entire_set"`
+	TrimmedSet         *AggregatePriceSet  `json:"trimmed_set,omitempty"`
+	Median             data.NonNativeValue `json:"median"`
+	Time               uint32              `json:"time"`
+	LedgerSequence     *uint32             `json:"ledger_index,omitempty"`
+	LedgerCurrentIndex *uint32             `json:"ledger_current_index,omitempty"`
+	Validated          bool                `json:"validated"`
+}
+
+type AggregatePriceSet struct {
+	Mean              data.NonNativeValue `json:"mean"`
+	Size              uint32              `json:"size"`
+	StandardDeviation data.NonNativeValue `json:"standard_deviation"`
+}
+
+type BookChangesCommand struct {
+	*Command
+	LedgerIndex interface{}        `json:"ledger_index,omitempty"`
+	Result      *BookChangesResult `json:"result,omitempty"`
+}
+
+type BookChangesResult struct {
+	LedgerSequence uint32          `json:"ledger_index"`
+	LedgerHash     data.Hash256    `json:"ledger_hash"`
+	LedgerTime     data.RippleTime `json:"ledger_time"`
+	Validated      bool            `json:"validated"`
+	Changes        []BookChange    `json:"changes"`
+}
+
+// BookChange aggregates the trades in one order book over a ledger.
+// Rates are amounts of CurrencyB per unit of CurrencyA.
+type BookChange struct {
+	CurrencyA data.Asset
+	CurrencyB data.Asset
+	VolumeA   data.Value
+	VolumeB   data.Value
+	Open      data.NonNativeValue
+	High      data.NonNativeValue
+	Low       data.NonNativeValue
+	Close     data.NonNativeValue
+}
+
+func (c *BookChange) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		CurrencyA string              `json:"currency_a"`
+		CurrencyB string              `json:"currency_b"`
+		VolumeA   string              `json:"volume_a"`
+		VolumeB   string              `json:"volume_b"`
+		Open      data.NonNativeValue `json:"open"`
+		High      data.NonNativeValue `json:"high"`
+		Low       data.NonNativeValue `json:"low"`
+		Close     data.NonNativeValue `json:"close"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	for _, side := range []struct {
+		currency, volume string
+		asset            *data.Asset
+		value            *data.Value
+	}{
+		{raw.CurrencyA, raw.VolumeA, &c.CurrencyA, &c.VolumeA},
+		{raw.CurrencyB, raw.VolumeB, &c.CurrencyB, &c.VolumeB},
+	} {
+		// Either "XRP_drops" or "issuer/currency"
+		native := side.currency == "XRP_drops"
+		if native {
+			*side.asset = data.Asset{Currency: "XRP"}
+		} else {
+			parts := strings.Split(side.currency, "/")
+			if len(parts) != 2 {
+				return fmt.Errorf("bad book_changes currency: %s", side.currency)
+			}
+			*side.asset = data.Asset{Currency: parts[1], Issuer: parts[0]}
+		}
+		value, err := data.NewValue(side.volume, native)
+		if err != nil {
+			return err
+		}
+		*side.value = *value
+	}
+	c.Open, c.High, c.Low, c.Close = raw.Open, raw.High, raw.Low, raw.Close
+	return nil
+}
+
 type FeeCommand struct {
 	*Command
 	Result *FeeResult
 }
 
 type FeeResult struct {
+	LedgerSequence    uint32 `json:"ledger_current_index"`
 	CurrentLedgerSize uint32 `json:"current_ledger_size,string"`
 	CurrentQueueSize  uint32 `json:"current_queue_size,string"`
 	Drops             struct {
@@ -299,3 +1052,84 @@ type FeeResult struct {
 	MaxQueueSize uint32 `json:"max_queue_size,string"`
 	Status       string `json:"status"`
 }
+
+// FeeLevels are the Levels of a FeeResult as the integers they are. Fee
+// levels are relative to Reference, which is the base fee.
+type FeeLevels struct {
+	Median     uint64
+	Minimum    uint64
+	OpenLedger uint64
+	Reference  uint64
+}
+
+// FeeLevels returns the Levels as integers
+func (f *FeeResult) FeeLevels() FeeLevels {
+	level := func(v data.Value) uint64 {
+		if v.IsZero() {
+			return 0
+		}
+		return v.Rat().Num().Uint64()
+	}
+	return FeeLevels{
+		Median:     level(f.Levels.MedianLevel),
+		Minimum:    level(f.Levels.MinimumLevel),
+		OpenLedger: level(f.Levels.OpenLedgerLevel),
+		Reference:  level(f.Levels.ReferenceLevel),
+	}
+}
+
+// FeeUrgency chooses how soon a transaction should be applied
+type FeeUrgency int
+
+const (
+	// Wait in the queue for a quieter ledger
+	FeeLow FeeUrgency = iota
+	// Apply to the current open ledger
+	FeeMedium
+	// Apply to the open ledger ahead of everything already queued
+	FeeHigh
+)
+
+// SuggestedFee returns the transaction cost, in drops, for the urgency.
+// FeeHigh escalates the open ledger fee as rippled would once every
+// queued transaction had been applied: the median level scaled by the
+// square of how far the ledger is beyond its expected size.
+func (f *FeeResult) SuggestedFee(urgency FeeUrgency) (*data.Value, error) {
+	switch urgency {
+	case FeeLow:
+		return f.Drops.MinimumFee.Clone(), nil
+	case FeeMedium:
+		return f.Drops.OpenLedgerFee.Clone(), nil
+	case FeeHigh:
+	default:
+		return nil, fmt.Errorf("Unknown fee urgency: %d", urgency)
+	}
+	levels := f.FeeLevels()
+	if levels.Reference == 0 {
+		return nil, fmt.Errorf("fee: missing reference level")
+	}
+	size := uint64(f.CurrentLedgerSize) + uint64(f.CurrentQueueSize)
+	expected := uint64(f.ExpectedLedgerSize)
+	if size <= expected || expected == 0 {
+		return f.Drops.OpenLedgerFee.Clone(), nil
+	}
+	// drops = base * median * size² / (expected² * reference), rounded up
+	num := new(big.Int).SetUint64(size)
+	num.Mul(num, num)
+	num.Mul(num, new(big.Int).SetUint64(levels.Median))
+	num.Mul(num, f.Drops.BaseFee.Rat().Num())
+	den := new(big.Int).SetUint64(expected)
+	den.Mul(den, den)
+	den.Mul(den, new(big.Int).SetUint64(levels.Reference))
+	drops, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() > 0 {
+		drops.Add(drops, big.NewInt(1))
+	}
+	if open := f.Drops.OpenLedgerFee.Rat().Num(); drops.Cmp(open) < 0 {
+		drops = open
+	}
+	if !drops.IsInt64() {
+		return nil, fmt.Errorf("fee: %s drops out of range", drops)
+	}
+	return data.NewNativeValue(drops.Int64())
+}
@@ -1,7 +1,9 @@
 package websockets
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"testing"
 
@@ -98,6 +100,24 @@ func (s *MessagesSuite) TestTxResponse(c *C) {
 	c.Assert(offer.Sequence, Equals, uint32(1681497))
 }
 
+// Responses under api_version 2 decode into the same values
+func (s *MessagesSuite) TestAPIVersion2Responses(c *C) {
+	var v1, v2 TxCommand
+	readResponseFile(c, &v1, "testdata/tx.json")
+	readResponseFile(c, &v2, "testdata/tx_v2.json")
+	c.Assert(v2.Result, NotNil)
+	c.Check(v2.APIVersion, Equals, 2)
+	c.Check(*v2.Result, DeepEquals, *v1.Result)
+	c.Check(v2.Result.GetHash().String(), Equals, "2D0CE11154B655A2BFE7F3F857AAC344622EC7DAB11B1EBD920DCDB00E8646FF")
+	c.Check(v2.Result.LedgerSequence, Equals, uint32(6917762))
+
+	var txs1, txs2 AccountTxCommand
+	readResponseFile(c, &txs1, "testdata/account_tx.json")
+	readResponseFile(c, &txs2, "testdata/account_tx_v2.json")
+	c.Assert(txs2.Result, NotNil)
+	c.Check(txs2.Result, DeepEquals, txs1.Result)
+}
+
 func (s *MessagesSuite) TestAccountTxResponse(c *C) {
 	msg := &AccountTxCommand{}
 	readResponseFile(c, msg, "testdata/account_tx.json")
@@ -112,6 +132,72 @@ func (s *MessagesSuite) TestAccountTxResponse(c *C) {
 	c.Assert(offer.TakerPays.String(), Equals, "0.034800328/BTC/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
 }
 
+func (s *MessagesSuite) TestSkipPartialPayments(c *C) {
+	msg := &AccountTxCommand{}
+	readResponseFile(c, msg, "testdata/account_tx.json")
+	partial, full := data.TxPartialPayment, data.TransactionFlag(0)
+	payments := data.TransactionSlice{
+		{Transaction: &data.Payment{TxBase: data.TxBase{TransactionType: data.PAYMENT, Flags: &partial}}},
+		{Transaction: &data.Payment{TxBase: data.TxBase{TransactionType: data.PAYMENT, Flags: &full}}},
+		{Transaction: &data.Payment{TxBase: data.TxBase{TransactionType: data.PAYMENT}}},
+	}
+	page := &AccountTxResult{Transactions: append(payments, msg.Result.Transactions...)}
+	AccountTxOptions{}.Filter(page)
+	c.Check(page.Transactions, HasLen, 5)
+	AccountTxOptions{SkipPartialPayments: true}.Filter(page)
+	c.Check(page.Transactions, DeepEquals, append(payments[1:3], msg.Result.Transactions...))
+}
+
+// The binary form of account_tx is made from the JSON one, as the blobs
+// found in the value of a transaction node
+func (s *MessagesSuite) TestAccountTxBinaryResponse(c *C) {
+	msg := &AccountTxCommand{}
+	readResponseFile(c, msg, "testdata/account_tx.json")
+	var binary []binaryTransaction
+	for _, txm := range msg.Result.Transactions {
+		_, value, err := data.Node(txm)
+		c.Assert(err, IsNil)
+		r := bytes.NewReader(value[13:])
+		var blobs [2][]byte
+		for i := range blobs {
+			blob, err := data.NewVariableByteReader(r)
+			c.Assert(err, IsNil)
+			blobs[i], err = ioutil.ReadAll(blob)
+			c.Assert(err, IsNil)
+		}
+		tx := binaryTransaction{LedgerSequence: txm.LedgerSequence, TxBlob: fmt.Sprintf("%X", blobs[0])}
+		// The second as api_version 2 sends it
+		if meta := fmt.Sprintf("%X", blobs[1]); len(binary) == 0 {
+			tx.Meta = meta
+		} else {
+			tx.MetaBlob = meta
+		}
+		binary = append(binary, tx)
+	}
+	b, err := json.Marshal(map[string]interface{}{"marker": msg.Result.Marker, "transactions": binary})
+	c.Assert(err, IsNil)
+
+	var result AccountTxResult
+	c.Assert(json.Unmarshal(b, &result), IsNil)
+	c.Check(result.Marker, DeepEquals, msg.Result.Marker)
+	c.Assert(result.Transactions, HasLen, 2)
+	for i, txm := range result.Transactions {
+		expected := msg.Result.Transactions[i]
+		c.Check(*txm.GetHash(), Equals, *expected.GetHash())
+		id, err := data.NodeId(expected)
+		c.Assert(err, IsNil)
+		c.Check(txm.Id, Equals, id)
+		c.Check(txm.LedgerSequence, Equals, expected.LedgerSequence)
+		c.Check(txm.MetaData.AffectedNodes, HasLen, len(expected.MetaData.AffectedNodes))
+	}
+	offer := result.Transactions[1].Transaction.(*data.OfferCreate)
+	c.Check(offer.TakerPays.String(), Equals, "0.034800328/BTC/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+
+	b, err = json.Marshal(newAccountTxCommand(data.Account{}, AccountTxOptions{MinLedger: -1, MaxLedger: -1, Binary: true, Forward: true}, nil))
+	c.Assert(err, IsNil)
+	c.Check(string(b), Matches, `.*"binary":true,"forward":true.*`)
+}
+
 func (s *MessagesSuite) TestLedgerDataResponse(c *C) {
 	msg := &LedgerDataCommand{}
 	readResponseFile(c, msg, "testdata/ledger_data.json")
@@ -160,3 +246,350 @@ func (s *MessagesSuite) TestAccountInfoResponse(c *C) {
 	c.Assert(*msg.Result.AccountData.Sequence, Equals, uint32(546))
 	c.Assert(msg.Result.AccountData.Balance.String(), Equals, "10321199.422233")
 }
+
+func (s *MessagesSuite) TestAccountObjectsResponse(c *C) {
+	msg := &AccountObjectsCommand{}
+	readResponseFile(c, msg, "testdata/account_objects.json")
+
+	c.Assert(msg.Status, Equals, "success")
+	c.Assert(*msg.Result.LedgerSequence, Equals, uint32(12373188))
+	c.Assert(msg.Result.Marker, NotNil)
+	c.Assert(msg.Result.AccountObjects, HasLen, 3)
+
+	offer, ok := msg.Result.AccountObjects[0].(*data.Offer)
+	c.Assert(ok, Equals, true)
+	c.Assert(*offer.Sequence, Equals, uint32(6))
+	c.Assert(offer.TakerPays.String(), Equals, "5/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+
+	line, ok := msg.Result.AccountObjects[1].(*data.RippleState)
+	c.Assert(ok, Equals, true)
+	c.Assert(line.Balance.String(), Equals, "-3/USD/rrrrrrrrrrrrrrrrrrrrBZbvji")
+
+	page, ok := msg.Result.AccountObjects[2].(*data.NFTokenPage)
+	c.Assert(ok, Equals, true)
+	c.Assert(page.NFTokens, HasLen, 1)
+	c.Assert(page.NFTokens[0].NFToken.NFTokenID.String(), Equals, "000B013A95F14B0044F78A264E41713C64B5F89242540EE208C3098E00000D65")
+	c.Assert(page.NFTokens[0].NFToken.URI.String(), Equals, "697066733A2F2F62616679")
+}
+
+func (s *MessagesSuite) TestAccountChannelsResponse(c *C) {
+	msg := &AccountChannelsCommand{}
+	readResponseFile(c, msg, "testdata/account_channels.json")
+
+	c.Assert(msg.Status, Equals, "success")
+	c.Assert(*msg.Result.LedgerSequence, Equals, uint32(71766314))
+	c.Assert(msg.Result.Marker, IsNil)
+	c.Assert(msg.Result.Channels, HasLen, 1)
+
+	channel := msg.Result.Channels[0]
+	c.Assert(channel.ChannelId.String(), Equals, "C7F634794B79DB40E87179A9D1BF05D05797AE7E92DF8E93FD6656E8C4BE3AE7")
+	c.Assert(channel.Destination.String(), Equals, "ra5nK24KXen9AHvsdFTKHSANinZseWnPcX")
+	c.Assert(channel.Amount.String(), Equals, "0.001")
+	c.Assert(channel.Balance.String(), Equals, "0.00025")
+	c.Assert(channel.PublicKey.String(), Equals, "023693F15967AE357D0327974AD46FE3C127113B1110D6044FD41E723689F81CC6")
+	c.Assert(channel.SettleDelay, Equals, uint32(60))
+	c.Assert(*channel.CancelAfter, Equals, uint32(553300000))
+	c.Assert(channel.Expiration, IsNil)
+}
+
+func (s *MessagesSuite) TestGatewayBalancesResponse(c *C) {
+	msg := &GatewayBalancesCommand{}
+	readResponseFile(c, msg, "testdata/gateway_balances.json")
+
+	c.Assert(msg.Status, Equals, "success")
+	c.Assert(*msg.Result.LedgerSequence, Equals, uint32(14483212))
+	c.Assert(msg.Result.Account.String(), Equals, "rMwjYedjc7qqtKYVLiAccJSmCwih4LnE2q")
+
+	usd, err := data.NewCurrency("USD")
+	c.Assert(err, IsNil)
+	c.Assert(msg.Result.Obligations, HasLen, 3)
+	c.Assert(msg.Result.Obligations[usd].String(), Equals, "12345.9")
+
+	hot, err := data.NewAccountFromAddress("ra7JkEzrgeKHdzKgo4EUUVBnxggY4z37kt")
+	c.Assert(err, IsNil)
+	c.Assert(msg.Result.Balances, HasLen, 2)
+	c.Assert(msg.Result.Balances[*hot], HasLen, 1)
+	c.Assert(msg.Result.Balances[*hot][0].Currency, Equals, usd)
+	c.Assert(msg.Result.Balances[*hot][0].Value.String(), Equals, "13857.70416")
+
+	c.Assert(msg.Result.Assets, HasLen, 1)
+	c.Assert(msg.Result.FrozenBalances, HasLen, 0)
+}
+
+func (s *MessagesSuite) TestNoRippleCheckResponse(c *C) {
+	msg := &NoRippleCheckCommand{}
+	readResponseFile(c, msg, "testdata/noripple_check.json")
+
+	c.Assert(msg.Status, Equals, "success")
+	c.Assert(msg.Result.LedgerSequence, Equals, uint32(14342939))
+	c.Assert(msg.Result.Problems, HasLen, 2)
+	c.Assert(msg.Result.Transactions, HasLen, 2)
+
+	accountSet, ok := msg.Result.Transactions[0].(*data.AccountSet)
+	c.Assert(ok, Equals, true)
+	c.Assert(*accountSet.SetFlag, Equals, uint32(8))
+	c.Assert(accountSet.Sequence, Equals, uint32(1406))
+	c.Assert(accountSet.Fee.String(), Equals, "0.01")
+
+	trustSet, ok := msg.Result.Transactions[1].(*data.TrustSet)
+	c.Assert(ok, Equals, true)
+	c.Assert(trustSet.LimitAmount.String(), Equals, "0/XAU/r3vi7mWxru9rJCxETCyA1CHvzL96eZWx5z")
+	c.Assert(trustSet.Sequence, Equals, uint32(1407))
+}
+
+func (s *MessagesSuite) TestAccountCurrenciesResponse(c *C) {
+	msg := &AccountCurrenciesCommand{}
+	readResponseFile(c, msg, "testdata/account_currencies.json")
+
+	c.Assert(msg.Status, Equals, "success")
+	c.Assert(*msg.Result.LedgerSequence, Equals, uint32(11775844))
+	c.Assert(msg.Result.Receive, HasLen, 4)
+	c.Assert(msg.Result.Receive[1].String(), Equals, "CNY")
+	c.Assert(msg.Result.Receive[3].IsNative(), Equals, false)
+	c.Assert(msg.Result.Send, HasLen, 2)
+	c.Assert(msg.Result.Send[1].String(), Equals, "USD")
+}
+
+func (s *MessagesSuite) TestServerInfoResponse(c *C) {
+	msg := &ServerInfoCommand{}
+	readResponseFile(c, msg, "testdata/server_info.json")
+
+	info := msg.Result.Info
+	c.Assert(info.BuildVersion, Equals, "1.12.0")
+	c.Assert(info.CompleteLedgers, HasLen, 2)
+	c.Assert(info.CompleteLedgers.Contains(6595043), Equals, false)
+	c.Assert(info.CompleteLedgers.Contains(82184562), Equals, true)
+	c.Assert(info.LoadFactor, Equals, float64(1))
+	c.Assert(*info.NetworkID, Equals, uint32(0))
+	c.Assert(info.ServerStateDuration, Equals, uint64(91758491912))
+	c.Assert(info.ValidatedLedger.LedgerSequence, Equals, uint32(82184562))
+	c.Assert(info.ValidatedLedger.BaseFee.String(), Equals, "0.00001")
+	c.Assert(info.ValidatedLedger.ReserveBase.String(), Equals, "10")
+	c.Assert(info.ValidatedLedger.ReserveIncrement.String(), Equals, "2")
+}
+
+func (s *MessagesSuite) TestServerStateResponse(c *C) {
+	msg := &ServerStateCommand{}
+	readResponseFile(c, msg, "testdata/server_state.json")
+
+	state := msg.Result.State
+	c.Assert(state.CompleteLedgers.String(), Equals, "82183001-82184562")
+	c.Assert(state.LoadBase, Equals, uint64(256))
+	c.Assert(state.LoadFactorFeeEscalation, Equals, uint64(256))
+	c.Assert(state.NetworkID, IsNil)
+	c.Assert(state.ValidatedLedger.CloseTime.Uint32(), Equals, uint32(748044750))
+	c.Assert(state.ValidatedLedger.BaseFee.String(), Equals, "0.00001")
+	c.Assert(state.ValidatedLedger.ReserveBase.String(), Equals, "10")
+	c.Assert(state.ValidatedLedger.ReserveIncrement.String(), Equals, "2")
+}
+
+func (s *MessagesSuite) TestTxHistoryResponse(c *C) {
+	msg := &TxHistoryCommand{}
+	readResponseFile(c, msg, "testdata/tx_history.json")
+
+	c.Assert(msg.Status, Equals, "success")
+	c.Assert(msg.Result.Index, Equals, uint32(0))
+	c.Assert(msg.Result.Txs, HasLen, 2)
+
+	payment := msg.Result.Txs[0].Transaction.(*data.Payment)
+	c.Assert(msg.Result.Txs[0].GetHash().String(), Equals, "C53ECF838647FA5A4C780377025FEC7999AB4182590510CA461444B207AB74A9")
+	c.Assert(msg.Result.Txs[0].LedgerSequence, Equals, uint32(82184562))
+	c.Assert(payment.Amount.String(), Equals, "1/XRP")
+	c.Assert(payment.Sequence, Equals, uint32(7))
+
+	offer := msg.Result.Txs[1].Transaction.(*data.OfferCreate)
+	c.Assert(offer.TakerPays.String(), Equals, "12.5/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+}
+
+func (s *MessagesSuite) TestTransactionEntryResponse(c *C) {
+	msg := &TransactionEntryCommand{}
+	readResponseFile(c, msg, "testdata/transaction_entry.json")
+
+	c.Assert(msg.Status, Equals, "success")
+	c.Assert(msg.Result.LedgerSequence, Equals, uint32(6917762))
+	c.Assert(msg.Result.GetHash().String(), Equals, "2D0CE11154B655A2BFE7F3F857AAC344622EC7DAB11B1EBD920DCDB00E8646FF")
+	c.Assert(msg.Result.MetaData.AffectedNodes, HasLen, 4)
+	c.Assert(msg.Result.MetaData.TransactionResult.String(), Equals, "tesSUCCESS")
+
+	offer, ok := msg.Result.Transaction.(*data.OfferCreate)
+	c.Assert(ok, Equals, true)
+	c.Assert(offer.Account.String(), Equals, "rwpxNWdpKu2QVgrh5LQXEygYLshhgnRL1Y")
+	c.Assert(offer.Sequence, Equals, uint32(1681497))
+}
+
+func (s *MessagesSuite) TestLedgerEntryResponse(c *C) {
+	msg := &LedgerEntryCommand{}
+	readResponseFile(c, msg, "testdata/ledger_entry.json")
+
+	c.Assert(*msg.Result.LedgerSequence, Equals, uint32(7636529))
+	le, err := msg.Result.LedgerEntry()
+	c.Assert(err, IsNil)
+	account, ok := le.(*data.AccountRoot)
+	c.Assert(ok, Equals, true)
+	c.Assert(account.Account.String(), Equals, "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(*account.Sequence, Equals, uint32(546))
+	c.Assert(*account.TransferRate, Equals, uint32(1002000000))
+	c.Assert(account.Balance.String(), Equals, "10321199.422233")
+	c.Assert(account.PreviousTxnID.String(), Equals, "B737C6C9F46FD87E9FA78201E60E3B34CBAD1EA325099D687FA155EE0766870A")
+}
+
+func (s *MessagesSuite) TestLedgerEntrySelector(c *C) {
+	owner, err := data.NewAccountFromAddress("rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(err, IsNil)
+	cmd := &LedgerEntryCommand{
+		Command:             &Command{Id: 1, Name: "ledger_entry"},
+		LedgerEntrySelector: LedgerEntrySelector{Escrow: &EscrowSelector{Owner: *owner, Sequence: 7}},
+		LedgerIndex:         "validated",
+		Binary:              true,
+	}
+	b, err := json.Marshal(cmd)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `{"id":1,"command":"ledger_entry","escrow":{"owner":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","seq":7},"ledger_index":"validated","binary":true}`)
+
+	cmd.LedgerEntrySelector = LedgerEntrySelector{Credential: &CredentialSelector{Subject: *owner, Issuer: *owner, CredentialType: data.VariableLength("kyc")}}
+	b, err = json.Marshal(cmd)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `{"id":1,"command":"ledger_entry","credential":{"subject":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","issuer":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","credential_type":"6B7963"},"ledger_index":"validated","binary":true}`)
+}
+
+func (s *MessagesSuite) TestBookChangesResponse(c *C) {
+	msg := &BookChangesCommand{}
+	readResponseFile(c, msg, "testdata/book_changes.json")
+
+	c.Assert(msg.Result.LedgerSequence, Equals, uint32(82184562))
+	c.Assert(msg.Result.Validated, Equals, true)
+	c.Assert(msg.Result.Changes, HasLen, 2)
+
+	xrp := msg.Result.Changes[0]
+	c.Assert(xrp.CurrencyA.IsNative(), Equals, true)
+	c.Assert(xrp.CurrencyB, Equals, data.Asset{Currency: "USD", Issuer: "rhub8VRN55s94qWKDv6jmDy1pUykJzF3wq"})
+	c.Assert(xrp.VolumeA.String(), Equals, "23.546")
+	c.Assert(xrp.VolumeB.String(), Equals, "7.887433")
+	c.Assert(xrp.High.String(), Equals, "0.3352574208814003")
+	c.Assert(xrp.Open.String(), Equals, "0.3347842098515869")
+
+	iou := msg.Result.Changes[1]
+	c.Assert(iou.CurrencyA, Equals, data.Asset{Currency: "BTC", Issuer: "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"})
+	c.Assert(iou.VolumeA.String(), Equals, "0.5")
+	c.Assert(iou.Close.String(), Equals, "0.00001")
+}
+
+func (s *MessagesSuite) TestValidatorsResponse(c *C) {
+	msg := &ValidatorsCommand{}
+	readResponseFile(c, msg, "testdata/validators.json")
+
+	c.Assert(msg.Result.ValidationQuorum, Equals, uint32(2))
+	c.Assert(msg.Result.TrustedValidatorKeys, HasLen, 2)
+	c.Assert(msg.Result.PublisherLists, HasLen, 1)
+	c.Assert(msg.Result.PublisherLists[0].Sequence, Equals, uint32(68))
+	c.Assert(msg.Result.PublisherLists[0].Available, Equals, true)
+	c.Assert(msg.Result.SigningKeys["nHBidG3pZK11zQD6kpNDoAhDxH6WLGui6ZxSbUx7LSqLHsgzMPec"], Equals, "n9KaxgJv69FucW5kkiaMhCqS6sAR1wUVxpZaZmLGVXxAcAse9YhR")
+	c.Assert(msg.Result.ValidatorList.Status, Equals, "active")
+	c.Assert(msg.Result.ValidatorList.Threshold, Equals, uint32(1))
+}
+
+func (s *MessagesSuite) TestManifestResponse(c *C) {
+	msg := &ManifestCommand{}
+	readResponseFile(c, msg, "testdata/manifest.json")
+
+	c.Assert(msg.Result.Details, NotNil)
+	c.Assert(msg.Result.Details.Sequence, Equals, uint32(3))
+	c.Assert(msg.Result.Details.Domain, Equals, "ripple.com")
+	c.Assert(msg.Result.Manifest, DeepEquals, []byte{0x24, 0, 0, 0, 3, 0x71, 0x21, 0xED})
+}
+
+func (s *MessagesSuite) TestFeatureResponse(c *C) {
+	msg := &FeatureCommand{}
+	readResponseFile(c, msg, "testdata/feature.json")
+
+	c.Assert(msg.Result.Features, HasLen, 3)
+	c.Assert(msg.Result.Features.Enabled("AMM"), Equals, true)
+	c.Assert(msg.Result.Features.Enabled("fixTakerDryOfferRemoval"), Equals, false)
+	c.Assert(msg.Result.Features.Enabled("Unknown"), Equals, false)
+	id, err := data.NewHash256("4C97EBA926031A7CF7D7B36FDE3ED66DDA5421192D63DE53FFB46E43B9DC8373")
+	c.Assert(err, IsNil)
+	c.Assert(msg.Result.Features[*id], Equals, Feature{Name: "MultiSign", Supported: true, Vetoed: true, Obsolete: true})
+}
+
+func (s *MessagesSuite) TestFeeResponse(c *C) {
+	msg := &FeeCommand{}
+	readResponseFile(c, msg, "testdata/fee.json")
+
+	fee := msg.Result
+	c.Assert(fee.LedgerSequence, Equals, uint32(26575101))
+	c.Assert(fee.CurrentQueueSize, Equals, uint32(10))
+	c.Assert(fee.MaxQueueSize, Equals, uint32(480))
+	c.Assert(fee.Drops.MedianFee.String(), Equals, "0.011")
+	c.Assert(fee.Levels.OpenLedgerLevel.String(), Equals, "0.44")
+	c.Assert(fee.FeeLevels(), Equals, FeeLevels{Median: 281600, Minimum: 256, OpenLedger: 440000, Reference: 256})
+
+	for urgency, drops := range map[FeeUrgency]string{
+		FeeLow:    "10",
+		FeeMedium: "17188",
+		// 10 * 281600 * 40² / (24² * 256), rounded up
+		FeeHigh: "30556",
+	} {
+		suggested, err := fee.SuggestedFee(urgency)
+		c.Assert(err, IsNil)
+		b, err := suggested.MarshalText()
+		c.Assert(err, IsNil)
+		c.Check(string(b), Equals, drops, Commentf("urgency %d", urgency))
+	}
+
+	// No escalation while the ledger and queue fit
+	fee.CurrentLedgerSize, fee.CurrentQueueSize = 10, 0
+	suggested, err := fee.SuggestedFee(FeeHigh)
+	c.Assert(err, IsNil)
+	c.Assert(suggested.String(), Equals, "0.017188")
+}
+
+func (s *MessagesSuite) TestAccountNFTsResponse(c *C) {
+	msg := &AccountNFTsCommand{}
+	readResponseFile(c, msg, "testdata/account_nfts.json")
+
+	c.Assert(msg.Result.Marker, IsNil)
+	c.Assert(msg.Result.NFTs, HasLen, 1)
+	nft := msg.Result.NFTs[0]
+	c.Assert(nft.Flags, Equals, data.NFTokenTransferable)
+	c.Assert(nft.TransferFee, Equals, uint16(500))
+	c.Assert(nft.Taxon, Equals, uint32(42))
+	c.Assert(string(nft.URI), Equals, "ipfs://bafy")
+	c.Assert(*data.NewNFTokenDetails(nft.NFTokenID), Equals, data.NFTokenDetails{
+		Flags:       nft.Flags,
+		TransferFee: nft.TransferFee,
+		Issuer:      nft.Issuer,
+		Taxon:       nft.Taxon,
+		Serial:      nft.Serial,
+	})
+}
+
+func (s *MessagesSuite) TestNFTOffersResponse(c *C) {
+	msg := &NFTOffersCommand{}
+	readResponseFile(c, msg, "testdata/nft_buy_offers.json")
+
+	c.Assert(msg.Result.Offers, HasLen, 2)
+	c.Assert(msg.Result.Offers[0].Amount.String(), Equals, "1.5/XRP")
+	c.Assert(msg.Result.Offers[0].Destination, IsNil)
+	c.Assert(msg.Result.Offers[1].Amount.String(), Equals, "12.5/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(msg.Result.Offers[1].Destination.String(), Equals, "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(*msg.Result.Offers[1].Expiration, Equals, uint32(780000000))
+}
+
+func (s *MessagesSuite) TestAMMInfoResponse(c *C) {
+	msg := &AMMInfoCommand{}
+	readResponseFile(c, msg, "testdata/amm_info.json")
+
+	amm := msg.Result.AMM
+	c.Assert(*msg.Result.LedgerSequence, Equals, uint32(316745))
+	c.Assert(amm.Account.String(), Equals, "rE54zDvgnghAoPopCgvtiqWNq3dU5y836S")
+	c.Assert(amm.Amount.String(), Equals, "227.464315/XRP")
+	c.Assert(amm.Amount2.String(), Equals, "25.68834136269866/TST/rP9jPyP5kyvFRb6ZiRghAGw5u8SGAmU4bd")
+	c.Assert(amm.LPToken.Value.String(), Equals, "71150.53584131501")
+	c.Assert(amm.TradingFee, Equals, uint16(600))
+	c.Assert(amm.AuctionSlot, NotNil)
+	c.Assert(amm.AuctionSlot.AuthAccounts, HasLen, 1)
+	c.Assert(amm.AuctionSlot.DiscountedFee, Equals, uint16(60))
+	c.Assert(amm.VoteSlots, HasLen, 1)
+	c.Assert(amm.VoteSlots[0].Account, Equals, amm.AuctionSlot.Account)
+	c.Assert(amm.VoteSlots[0].VoteWeight, Equals, uint32(100000))
+}
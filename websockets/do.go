@@ -0,0 +1,93 @@
+package websockets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// RawCommand calls any method. Its Params, which must marshal to an
+// object, are sent beside the fields of the Command, and its Result is
+// left for the caller to decode.
+type RawCommand struct {
+	*Command
+	Params interface{}     `json:"-"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// NewRawCommand returns a command which calls method with params
+func NewRawCommand(method string, params interface{}) *RawCommand {
+	return &RawCommand{
+		Command: newCommand(method),
+		Params:  params,
+	}
+}
+
+func (c *RawCommand) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]json.RawMessage)
+	if c.Params != nil {
+		b, err := json.Marshal(c.Params)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &fields); err != nil {
+			return nil, fmt.Errorf("ws: params of %s aren't an object: %s", c.Name, b)
+		}
+		if fields == nil {
+			fields = make(map[string]json.RawMessage)
+		}
+	}
+	b, err := json.Marshal(c.Command)
+	if err != nil {
+		return nil, err
+	}
+	var command map[string]json.RawMessage
+	if err := json.Unmarshal(b, &command); err != nil {
+		return nil, err
+	}
+	for key, value := range command {
+		fields[key] = value
+	}
+	return json.Marshal(fields)
+}
+
+// Decode unmarshals the Result into v
+func (c *RawCommand) Decode(v interface{}) error {
+	if len(c.Result) == 0 {
+		return fmt.Errorf("ws: %s has no result", c.Name)
+	}
+	return json.Unmarshal(c.Result, v)
+}
+
+// Doer calls methods which have no wrapper. Both Remote and rpc.Client
+// satisfy it.
+type Doer interface {
+	Do(ctx context.Context, method string, params, result interface{}) error
+}
+
+// Do calls method, which needn't be one the library knows, with params,
+// such as a struct with json tags or a map, and unmarshals the result into
+// result unless it is nil. Errors are those of the other methods.
+func (r *Remote) Do(ctx context.Context, method string, params, result interface{}) error {
+	cmd := NewRawCommand(method, params)
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	return cmd.Decode(result)
+}
+
+// Execute calls method with params, as Do does, and returns the result as
+// a T, so that methods which have no wrapper yet can be typed by the
+// caller:
+//
+//	info, err := websockets.Execute[websockets.ServerInfoResult](ctx, r, "server_info", nil)
+func Execute[T any](ctx context.Context, d Doer, method string, params interface{}) (*T, error) {
+	result := new(T)
+	if err := d.Do(ctx, method, params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
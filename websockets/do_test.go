@@ -0,0 +1,69 @@
+package websockets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	. "gopkg.in/check.v1"
+)
+
+type DoSuite struct{}
+
+var _ = Suite(&DoSuite{})
+
+func (s *DoSuite) TestRawCommand(c *C) {
+	cmd := NewRawCommand("vault_info", struct {
+		VaultID string `json:"vault_id"`
+		Command string `json:"command"`
+	}{"ABC", "ignored"})
+	cmd.APIVersion = 2
+	b, err := json.Marshal(cmd)
+	c.Assert(err, IsNil)
+	c.Check(string(b), Equals, fmt.Sprintf(`{"api_version":2,"command":"vault_info","id":%d,"vault_id":"ABC"}`, cmd.Id))
+
+	b, err = json.Marshal(NewRawCommand("ping", nil))
+	c.Assert(err, IsNil)
+	c.Check(string(b), Matches, `\{"command":"ping","id":\d+\}`)
+
+	_, err = json.Marshal(NewRawCommand("ping", []int{1}))
+	c.Check(err, ErrorMatches, ".*ws: params of ping aren't an object: \\[1\\]")
+}
+
+func (s *DoSuite) TestDo(c *C) {
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		if cmd["command"] != "vault_info" {
+			return []string{`{"id":$ID,"status":"error","type":"response","error":"unknownCmd","error_code":32,"error_message":"Unknown method."}`}
+		}
+		return []string{fmt.Sprintf(`{"id":$ID,"status":"success","type":"response","result":{"vault":{"index":%q,"shares":"100"}}}`, cmd["vault_id"])}
+	})
+	defer server.Close()
+	r, err := NewRemote(wsURL(server), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	type vaultInfo struct {
+		Vault struct {
+			Index  string `json:"index"`
+			Shares string `json:"shares"`
+		} `json:"vault"`
+	}
+	ctx := context.Background()
+	info, err := Execute[vaultInfo](ctx, r, "vault_info", map[string]string{"vault_id": "ABC"})
+	c.Assert(err, IsNil)
+	c.Check(info.Vault.Index, Equals, "ABC")
+	c.Check(info.Vault.Shares, Equals, "100")
+
+	var raw json.RawMessage
+	params := struct {
+		VaultID string `json:"vault_id"`
+	}{"DEF"}
+	c.Assert(r.Do(ctx, "vault_info", params, &raw), IsNil)
+	c.Check(string(raw), Equals, `{"vault":{"index":"DEF","shares":"100"}}`)
+	c.Check(r.Do(ctx, "vault_info", params, nil), IsNil)
+
+	err = r.Do(ctx, "no_such_method", nil, &raw)
+	cmdErr, ok := err.(*CommandError)
+	c.Assert(ok, Equals, true)
+	c.Check(cmdErr.Name, Equals, "unknownCmd")
+}
@@ -0,0 +1,82 @@
+package websockets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// The errors of rippled which say that what was asked for isn't there
+var notFoundErrors = map[string]bool{
+	"actNotFound":    true,
+	"lgrNotFound":    true,
+	"txnNotFound":    true,
+	"entryNotFound":  true,
+	"objectNotFound": true,
+	"srcActNotFound": true,
+}
+
+// The errors of rippled which say to ask again later
+var tooBusyErrors = map[string]bool{
+	"tooBusy":  true,
+	"slowDown": true,
+}
+
+// AsCommandError returns the CommandError which err is or wraps
+func AsCommandError(err error) (*CommandError, bool) {
+	var e *CommandError
+	if !errors.As(err, &e) || e == nil {
+		return nil, false
+	}
+	return e, true
+}
+
+// IsNotFound reports whether err is rippled saying that the account,
+// ledger, transaction or ledger entry asked for doesn't exist
+func IsNotFound(err error) bool {
+	e, ok := AsCommandError(err)
+	return ok && notFoundErrors[e.Name]
+}
+
+// IsAmendmentBlocked reports whether err is rippled refusing the command
+// because it doesn't know an amendment which has been enabled, so that
+// another server should be asked
+func IsAmendmentBlocked(err error) bool {
+	e, ok := AsCommandError(err)
+	return ok && e.Name == "amendmentBlocked"
+}
+
+// IsTooBusy reports whether err is rippled turning the command away
+// because of its load or that of this client, so that it may be tried
+// again after a while
+func IsTooBusy(err error) bool {
+	e, ok := AsCommandError(err)
+	return ok && tooBusyErrors[e.Name]
+}
+
+// EngineResultError is the result of a submitted transaction which was
+// neither applied successfully nor queued
+type EngineResultError struct {
+	EngineResult        data.TransactionResult
+	EngineResultCode    int
+	EngineResultMessage string
+}
+
+func (e *EngineResultError) Error() string {
+	return fmt.Sprintf("%s %d %s", e.EngineResult, e.EngineResultCode, e.EngineResultMessage)
+}
+
+// Err returns an EngineResultError unless the transaction was applied
+// successfully or queued. As Submit only returns an error when the
+// submission itself fails, this is how to tell whether it did anything.
+func (r *SubmitResult) Err() error {
+	if r.EngineResult.Success() || r.EngineResult.Queued() {
+		return nil
+	}
+	return &EngineResultError{
+		EngineResult:        r.EngineResult,
+		EngineResultCode:    r.EngineResultCode,
+		EngineResultMessage: r.EngineResultMessage,
+	}
+}
@@ -0,0 +1,76 @@
+package websockets
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	. "gopkg.in/check.v1"
+)
+
+type ErrorsSuite struct{}
+
+var _ = Suite(&ErrorsSuite{})
+
+func (s *ErrorsSuite) TestCommandError(c *C) {
+	const notFound = `{"id":$ID,"status":"error","type":"response","error":"actNotFound","error_code":19,"error_message":"Account not found.","request":{"account":"r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59","command":"account_info","id":$ID}}`
+	replies := []string{
+		notFound,
+		`{"id":$ID,"status":"error","type":"response","error":"amendmentBlocked","error_code":14,"error_message":"Amendment blocked, need upgrade."}`,
+		`{"id":$ID,"status":"error","type":"response","error":"slowDown","error_code":10,"error_message":"You are placing too much load on the server."}`,
+	}
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		reply := replies[0]
+		replies = replies[1:]
+		return []string{reply}
+	})
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+	account, err := data.NewAccountFromAddress("r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59")
+	c.Assert(err, IsNil)
+
+	_, err = r.AccountInfo(*account)
+	c.Check(err, ErrorMatches, "actNotFound 19 Account not found.")
+	c.Check(IsNotFound(err), Equals, true)
+	c.Check(IsNotFound(fmt.Errorf("account_info: %w", err)), Equals, true)
+	c.Check(IsAmendmentBlocked(err), Equals, false)
+	e, ok := AsCommandError(err)
+	c.Assert(ok, Equals, true)
+	var request map[string]interface{}
+	c.Assert(json.Unmarshal(e.Request, &request), IsNil)
+	c.Check(request["command"], Equals, "account_info")
+
+	_, err = r.AccountInfo(*account)
+	c.Check(IsAmendmentBlocked(err), Equals, true)
+	c.Check(IsTooBusy(err), Equals, false)
+	_, err = r.AccountInfo(*account)
+	c.Check(IsTooBusy(err), Equals, true)
+	c.Check(IsNotFound(err), Equals, false)
+
+	c.Check(IsNotFound(nil), Equals, false)
+	c.Check(IsNotFound(fmt.Errorf("actNotFound")), Equals, false)
+}
+
+func (s *ErrorsSuite) TestSubmitResultErr(c *C) {
+	for _, test := range []struct {
+		result string
+		err    string
+	}{
+		{`{"engine_result":"tesSUCCESS","engine_result_code":0,"engine_result_message":"The transaction was applied."}`, ""},
+		{`{"engine_result":"terQUEUED","engine_result_code":-89,"engine_result_message":"Held until escalated fee drops."}`, ""},
+		{`{"engine_result":"tecPATH_DRY","engine_result_code":128,"engine_result_message":"Path could not send partial amount."}`, "tecPATH_DRY 128 Path could not send partial amount."},
+		{`{"engine_result":"tefPAST_SEQ","engine_result_code":-190,"engine_result_message":"This sequence number has already passed."}`, "tefPAST_SEQ -190 .*"},
+	} {
+		var result SubmitResult
+		c.Assert(json.Unmarshal([]byte(test.result), &result), IsNil)
+		err := result.Err()
+		if test.err == "" {
+			c.Check(err, IsNil)
+			continue
+		}
+		c.Check(err, ErrorMatches, test.err)
+		c.Check(err.(*EngineResultError).EngineResult, Equals, result.EngineResult)
+	}
+}
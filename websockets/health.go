@@ -0,0 +1,124 @@
+package websockets
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ConnectionState is whether a Remote is connected to its server
+type ConnectionState int
+
+const (
+	// Connected, or at least not yet known to be disconnected
+	StateConnected ConnectionState = iota
+
+	// The connection was lost and will be tried again every
+	// RemoteOptions.ReconnectInterval
+	StateReconnecting
+
+	// The connection was lost or closed for good
+	StateClosed
+)
+
+var connectionStateNames = [...]string{"connected", "reconnecting", "closed"}
+
+func (s ConnectionState) String() string {
+	if s < 0 || int(s) >= len(connectionStateNames) {
+		return "unknown"
+	}
+	return connectionStateNames[s]
+}
+
+// Health is how the connection of a Remote is faring. Times are zero until
+// what they record first happens.
+type Health struct {
+	State      ConnectionState
+	Since      time.Time     // When the Remote entered State
+	LastPong   time.Time     // When the server last answered a ping
+	Latency    time.Duration // The round trip of the last ping
+	LastLedger uint32        // The last ledger seen closing, from the ledger stream
+	LastClosed time.Time     // When LastLedger was seen
+}
+
+// HealthEvent is a change of the state of a Remote
+type HealthEvent struct {
+	From ConnectionState
+	To   ConnectionState
+	At   time.Time
+	Err  error // Why the connection was lost, if known
+}
+
+// The capacity of the channel returned by HealthEvents
+const healthEventsBuffer = 16
+
+// health is the Health of a Remote, kept up to date by its goroutines
+type health struct {
+	sync.Mutex
+	Health
+	events chan HealthEvent
+}
+
+func newHealth() *health {
+	return &health{
+		Health: Health{State: StateConnected, Since: time.Now()},
+		events: make(chan HealthEvent, healthEventsBuffer),
+	}
+}
+
+func (h *health) get() Health {
+	h.Lock()
+	defer h.Unlock()
+	return h.Health
+}
+
+// setState changes the state and sends a HealthEvent, unless the channel
+// is full. The channel is closed once the state is StateClosed.
+func (h *health) setState(state ConnectionState, err error) {
+	h.Lock()
+	defer h.Unlock()
+	if h.State == state || h.State == StateClosed {
+		return
+	}
+	now := time.Now()
+	select {
+	case h.events <- HealthEvent{From: h.State, To: state, At: now, Err: err}:
+	default:
+	}
+	h.State, h.Since = state, now
+	if state == StateClosed {
+		close(h.events)
+	}
+}
+
+func (h *health) pong(now time.Time, payload string) {
+	h.Lock()
+	defer h.Unlock()
+	h.LastPong = now
+	if sent, err := strconv.ParseInt(payload, 10, 64); err == nil {
+		h.Latency = now.Sub(time.Unix(0, sent))
+	}
+}
+
+func (h *health) ledgerClosed(sequence uint32) {
+	h.Lock()
+	defer h.Unlock()
+	h.LastLedger, h.LastClosed = sequence, time.Now()
+}
+
+// pingPayload is the time, which the server echoes in its pong
+func pingPayload() []byte {
+	return strconv.AppendInt(nil, time.Now().UnixNano(), 10)
+}
+
+// Health returns how the connection to the server is faring
+func (r *Remote) Health() Health {
+	return r.health.get()
+}
+
+// HealthEvents returns a channel of the changes of state of the
+// connection, which is closed after the change to StateClosed. Changes
+// are dropped rather than wait for a reader once it holds 16.
+func (r *Remote) HealthEvents() <-chan HealthEvent {
+	return r.health.events
+}
@@ -0,0 +1,75 @@
+package websockets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	. "gopkg.in/check.v1"
+)
+
+type HealthSuite struct{}
+
+var _ = Suite(&HealthSuite{})
+
+func (s *HealthSuite) TestHealth(c *C) {
+	drop := make(chan struct{})
+	var upgrader websocket.Upgrader
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		sent := time.Now().Add(-20 * time.Millisecond).UnixNano()
+		ws.WriteControl(websocket.PongMessage, []byte(strconv.FormatInt(sent, 10)), time.Now().Add(time.Second))
+		ws.WriteMessage(websocket.TextMessage, []byte(`{"type":"ledgerClosed","ledger_index":42}`))
+		<-drop
+	}))
+	defer srv.Close()
+	r, err := NewRemoteWithOptions(wsURL(srv), RemoteOptions{Reconnect: true})
+	c.Assert(err, IsNil)
+	start := r.Health()
+	c.Check(start.State, Equals, StateConnected)
+	c.Check(start.Since.IsZero(), Equals, false)
+
+	c.Check((<-r.Incoming).(*LedgerStreamMsg).LedgerSequence, Equals, uint32(42))
+	health := r.Health()
+	c.Check(health.LastLedger, Equals, uint32(42))
+	c.Check(health.LastClosed.IsZero(), Equals, false)
+	c.Check(health.LastPong.IsZero(), Equals, false)
+	c.Check(health.Latency >= 20*time.Millisecond, Equals, true, Commentf("%s", health.Latency))
+
+	close(drop)
+	event := <-r.HealthEvents()
+	c.Check(event.From, Equals, StateConnected)
+	c.Check(event.To, Equals, StateReconnecting)
+	c.Check(event.Err, NotNil)
+	c.Check(r.Health().State, Equals, StateReconnecting)
+	c.Check(r.Health().Since.After(start.Since), Equals, true)
+
+	r.Close()
+	event = <-r.HealthEvents()
+	c.Check(event.From, Equals, StateReconnecting)
+	c.Check(event.To, Equals, StateClosed)
+	_, ok := <-r.HealthEvents()
+	c.Check(ok, Equals, false)
+	c.Check(r.Health().State, Equals, StateClosed)
+	c.Check(StateClosed.String(), Equals, "closed")
+}
+
+func (s *HealthSuite) TestClose(c *C) {
+	srv := silentServer()
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	r.Close()
+	event, ok := <-r.HealthEvents()
+	c.Assert(ok, Equals, true)
+	c.Check(event.To, Equals, StateClosed)
+	c.Check(event.Err, IsNil)
+	_, ok = <-r.HealthEvents()
+	c.Check(ok, Equals, false)
+}
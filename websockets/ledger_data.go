@@ -0,0 +1,321 @@
+package websockets
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/bits"
+	"sync"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// LedgerDataOptions tunes a StreamLedgerDataCtx
+type LedgerDataOptions struct {
+	// How many parts to split the key space into, each fetched and decoded
+	// by its own worker. Zero means one part.
+	Workers int
+
+	// Deliver the pages in key order. Otherwise they are delivered as soon
+	// as they are decoded, with the pages of each part still in order.
+	// Ordered delivery holds back the workers of later parts once their
+	// next pages are ready, so it is slower.
+	Ordered bool
+
+	// Entries in each page. Zero lets the server choose.
+	PageSize int
+
+	// Where to log the entries which can't be decoded. Nil means the
+	// Logger of the Remote or Client, or GlogLogger.
+	Logger Logger
+}
+
+// LedgerDataProgress is how far a StreamLedgerDataCtx has got when a page
+// is delivered
+type LedgerDataProgress struct {
+	Pages   int
+	Entries int
+	Skipped int     // Entries which could not be decoded
+	Done    float64 // Estimated from the keys reached in each part, from 0 to 1
+}
+
+// LedgerDataPage is a decoded page of the state of a ledger
+type LedgerDataPage struct {
+	LedgerSequence uint32
+	Part           int
+	State          data.LedgerEntrySlice
+	Progress       LedgerDataProgress
+
+	done float64 // Of the part
+}
+
+// LedgerDataIterator delivers the pages of a StreamLedgerDataCtx on Pages,
+// which is closed once the whole ledger has been read or a request fails.
+// Err may be used after that.
+type LedgerDataIterator struct {
+	Pages chan *LedgerDataPage
+
+	err error
+}
+
+// Err returns the error which ended the iteration early, if any
+func (it *LedgerDataIterator) Err() error {
+	return it.err
+}
+
+// BinaryLedgerDataFunc requests a page of the state of a ledger in binary
+// form, with at most limit entries unless limit is zero
+type BinaryLedgerDataFunc func(ctx context.Context, ledger interface{}, marker *data.Hash256, limit int) (*BinaryLedgerDataResult, error)
+
+// Key returns the index of the entry
+func (b *BinaryLedgerData) Key() (data.Hash256, error) {
+	var key data.Hash256
+	index, err := hex.DecodeString(b.Index)
+	if err != nil {
+		return key, err
+	}
+	if len(index) != len(key) {
+		return key, fmt.Errorf("Bad ledger entry index: %s", b.Index)
+	}
+	copy(key[:], index)
+	return key, nil
+}
+
+// LedgerEntry decodes the entry
+func (b *BinaryLedgerData) LedgerEntry() (data.LedgerEntry, error) {
+	return data.ReadLedgerEntryHex(b.Data, b.Index)
+}
+
+// ledgerDataPart is an equal share of the key space, split on the first
+// eight bytes of the keys. The last part runs to the end of the key space.
+type ledgerDataPart struct {
+	index int
+	start uint64
+	end   uint64
+	last  bool
+	pages chan *LedgerDataPage
+	log   Logger
+
+	decoder data.LedgerEntryHexDecoder // Only used by the worker of the part
+}
+
+func newLedgerDataParts(n int) []*ledgerDataPart {
+	parts := make([]*ledgerDataPart, n)
+	for i := range parts {
+		start, _ := bits.Div64(uint64(i), 0, uint64(n))
+		parts[i] = &ledgerDataPart{index: i, start: start, last: i == n-1}
+		if i > 0 {
+			parts[i-1].end = start
+		}
+	}
+	return parts
+}
+
+// marker returns the marker to read the part from, as the server returns
+// the entries after the marker
+func (p *ledgerDataPart) marker() *data.Hash256 {
+	if p.start == 0 {
+		return nil
+	}
+	var marker data.Hash256
+	binary.BigEndian.PutUint64(marker[:], p.start-1)
+	for i := 8; i < len(marker); i++ {
+		marker[i] = 0xFF
+	}
+	return &marker
+}
+
+func (p *ledgerDataPart) contains(key data.Hash256) bool {
+	return p.last || binary.BigEndian.Uint64(key[:]) < p.end
+}
+
+// done estimates how much of the part has been read up to key
+func (p *ledgerDataPart) done(key data.Hash256) float64 {
+	size := float64(p.end - p.start)
+	if p.last {
+		size = math.Exp2(64) - float64(p.start)
+	}
+	return float64(binary.BigEndian.Uint64(key[:])-p.start) / size
+}
+
+// decode returns the entries of the page which are in the part, and
+// whether the part has been read to its end
+func (p *ledgerDataPart) decode(result *BinaryLedgerDataResult) (*LedgerDataPage, bool, error) {
+	page := &LedgerDataPage{
+		LedgerSequence: result.LedgerSequence,
+		Part:           p.index,
+		State:          make(data.LedgerEntrySlice, 0, len(result.State)),
+	}
+	for i := range result.State {
+		key, err := result.State[i].Key()
+		if err != nil {
+			return nil, false, err
+		}
+		if !p.contains(key) {
+			page.done = 1
+			return page, true, nil
+		}
+		page.done = p.done(key)
+		le, err := p.decoder.Decode(result.State[i].Data, result.State[i].Index)
+		if err != nil {
+			p.log.Error("Unreadable ledger entry", "index", result.State[i].Index, "error", err)
+			page.Progress.Skipped++
+			continue
+		}
+		page.State = append(page.State, le)
+	}
+	if result.Marker == nil {
+		page.done = 1
+		return page, true, nil
+	}
+	return page, false, nil
+}
+
+// fetch reads the part from the first page, if any, and sends its pages
+// until it reaches the end of the part
+func (p *ledgerDataPart) fetch(ctx context.Context, fetch BinaryLedgerDataFunc, ledger uint32, first *BinaryLedgerDataResult, limit int, pages chan<- *LedgerDataPage) error {
+	marker := p.marker()
+	for {
+		result := first
+		first = nil
+		if result == nil {
+			var err error
+			if result, err = fetch(ctx, ledger, marker, limit); err != nil {
+				return err
+			}
+		}
+		page, done, err := p.decode(result)
+		if err != nil {
+			return err
+		}
+		select {
+		case pages <- page:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if done {
+			return nil
+		}
+		marker = result.Marker
+	}
+}
+
+// StreamLedgerDataParts reads the state of a ledger with fetch, splitting
+// the key space across the workers of options. The first page fixes the
+// ledger sequence for the rest, so ledger may be "validated". The server
+// must accept markers which are not keys in the ledger, as rippled does.
+func StreamLedgerDataParts(ctx context.Context, fetch BinaryLedgerDataFunc, ledger interface{}, options LedgerDataOptions) *LedgerDataIterator {
+	it := &LedgerDataIterator{
+		Pages: make(chan *LedgerDataPage),
+	}
+	go it.run(ctx, fetch, ledger, options)
+	return it
+}
+
+func (it *LedgerDataIterator) run(ctx context.Context, fetch BinaryLedgerDataFunc, ledger interface{}, options LedgerDataOptions) {
+	defer close(it.Pages)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := options.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	parts := newLedgerDataParts(workers)
+	log := options.Logger
+	if log == nil {
+		log = GlogLogger{}
+	}
+	first, err := fetch(ctx, ledger, nil, options.PageSize)
+	if err != nil {
+		it.err = err
+		return
+	}
+	if first.LedgerSequence == 0 {
+		it.err = fmt.Errorf("ledger_data result has no ledger index")
+		return
+	}
+
+	// Each part has its own channel when ordered, and otherwise they share
+	// one. The first error ends the iteration.
+	shared := make(chan *LedgerDataPage, workers)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for _, part := range parts {
+		part.log = log
+		pages := shared
+		if options.Ordered {
+			part.pages = make(chan *LedgerDataPage, 1)
+			pages = part.pages
+		}
+		var start *BinaryLedgerDataResult
+		if part.index == 0 {
+			start = first
+		}
+		wg.Add(1)
+		go func(part *ledgerDataPart, pages chan *LedgerDataPage, start *BinaryLedgerDataResult) {
+			defer wg.Done()
+			if options.Ordered {
+				defer close(pages)
+			}
+			if err := part.fetch(ctx, fetch, first.LedgerSequence, start, options.PageSize, pages); err != nil {
+				select {
+				case errs <- err:
+				default:
+				}
+				cancel()
+			}
+		}(part, pages, start)
+	}
+	go func() {
+		wg.Wait()
+		close(shared)
+	}()
+
+	var progress LedgerDataProgress
+	done := make([]float64, len(parts))
+	deliver := func(page *LedgerDataPage) bool {
+		done[page.Part] = page.done
+		progress.Pages++
+		progress.Entries += len(page.State)
+		progress.Skipped += page.Progress.Skipped
+		progress.Done = 0
+		for _, d := range done {
+			progress.Done += d / float64(len(done))
+		}
+		page.Progress = progress
+		select {
+		case it.Pages <- page:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	if options.Ordered {
+	ordered:
+		for _, part := range parts {
+			for page := range part.pages {
+				if !deliver(page) {
+					break ordered
+				}
+			}
+		}
+	} else {
+		for page := range shared {
+			if !deliver(page) {
+				break
+			}
+		}
+	}
+	select {
+	case it.err = <-errs:
+	default:
+		it.err = ctx.Err()
+	}
+
+	// No requests are left behind once Pages is closed
+	cancel()
+	wg.Wait()
+}
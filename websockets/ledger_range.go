@@ -0,0 +1,178 @@
+package websockets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// LedgerRangeOptions tunes a LedgerRange
+type LedgerRangeOptions struct {
+	// Include the transactions of each ledger
+	Transactions bool
+
+	// How many ledgers to request at once. Zero means one at a time.
+	// Ledgers are delivered in order however many are in flight.
+	Workers int
+}
+
+// LedgerIterator delivers the ledgers of a LedgerRange in sequence order
+// on Ledgers, which is closed once the range is exhausted or a request
+// fails. Err and Missing may be used after that.
+type LedgerIterator struct {
+	Ledgers chan *LedgerResult
+
+	missing data.LedgerSlice
+	err     error
+}
+
+// Err returns the error which ended the iteration early, if any
+func (it *LedgerIterator) Err() error {
+	return it.err
+}
+
+// Missing returns the ledgers skipped because the server doesn't have them
+func (it *LedgerIterator) Missing() data.LedgerSlice {
+	return it.missing
+}
+
+// ledgerFetch is the outcome of requesting a single ledger
+type ledgerFetch struct {
+	sequence uint32
+	result   *LedgerResult
+	err      error
+}
+
+// Asynchronously gets the ledgers from one sequence to another inclusive.
+// Ledgers the server reports as not found, and which are absent from the
+// complete_ledgers of its server_info, are skipped rather than ending the
+// iteration.
+func (r *Remote) LedgerRange(from, to uint32, options LedgerRangeOptions) *LedgerIterator {
+	return r.LedgerRangeCtx(context.Background(), from, to, options)
+}
+
+// LedgerRangeCtx is like LedgerRange but gives up when ctx is done, which
+// is also how a consumer which stops reading early releases the requests.
+func (r *Remote) LedgerRangeCtx(ctx context.Context, from, to uint32, options LedgerRangeOptions) *LedgerIterator {
+	it := &LedgerIterator{
+		Ledgers: make(chan *LedgerResult),
+	}
+	go r.ledgerRange(ctx, it, from, to, options)
+	return it
+}
+
+func (r *Remote) ledgerRange(ctx context.Context, it *LedgerIterator, from, to uint32, options LedgerRangeOptions) {
+	defer close(it.Ledgers)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Each request gets its own buffered channel, queued in sequence
+	// order, so that no more than Workers are ever outstanding.
+	workers := options.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	queue := make(chan chan ledgerFetch, workers-1)
+	go func() {
+		defer close(queue)
+		for sequence := uint64(from); sequence <= uint64(to); sequence++ {
+			fetched := make(chan ledgerFetch, 1)
+			select {
+			case queue <- fetched:
+			case <-ctx.Done():
+				return
+			}
+			go func(sequence uint32) {
+				result, err := r.LedgerCtx(ctx, sequence, options.Transactions)
+				fetched <- ledgerFetch{sequence, result, err}
+			}(uint32(sequence))
+		}
+	}()
+
+	var complete data.LedgerRanges
+	for fetched := range queue {
+		var f ledgerFetch
+		select {
+		case f = <-fetched:
+		case <-ctx.Done():
+			it.err = ctx.Err()
+			return
+		}
+		if f.err != nil {
+			if complete, f.err = r.checkLedgerGap(ctx, f, complete); f.err != nil {
+				it.err = f.err
+				return
+			}
+			it.missing = append(it.missing, f.sequence)
+			continue
+		}
+		select {
+		case it.Ledgers <- f.result:
+		case <-ctx.Done():
+			it.err = ctx.Err()
+			return
+		}
+	}
+}
+
+// checkLedgerGap returns nil if f failed because the server doesn't have
+// the ledger. complete_ledgers is only requested again when the last known
+// ranges include the sequence, as they grow while the range is walked.
+func (r *Remote) checkLedgerGap(ctx context.Context, f ledgerFetch, complete data.LedgerRanges) (data.LedgerRanges, error) {
+	if cmdErr, ok := f.err.(*CommandError); !ok || cmdErr.Name != "lgrNotFound" {
+		return complete, f.err
+	}
+	if complete == nil || complete.Contains(f.sequence) {
+		info, err := r.ServerInfoCtx(ctx)
+		if err != nil {
+			return complete, err
+		}
+		complete = info.CompleteLedgers
+	}
+	if complete.Contains(f.sequence) {
+		return complete, fmt.Errorf("Ledger %d not found but within complete ledgers %s", f.sequence, complete)
+	}
+	return complete, nil
+}
+
+// VerifyLedgerChain checks that the ledgers from one sequence to another
+// inclusive each hash to the hash reported for them, and name the one
+// before as their parent, so that the range is one unbroken chain. A
+// ledger missing from the server breaks the chain.
+func (r *Remote) VerifyLedgerChain(from, to uint32) error {
+	return r.VerifyLedgerChainCtx(context.Background(), from, to, 1)
+}
+
+// VerifyLedgerChainCtx is like VerifyLedgerChain but gives up when ctx is
+// done, and requests as many ledgers at once as workers
+func (r *Remote) VerifyLedgerChainCtx(ctx context.Context, from, to uint32, workers int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	it := r.LedgerRangeCtx(ctx, from, to, LedgerRangeOptions{Workers: workers})
+	next := from
+	var parent *data.Ledger
+	for result := range it.Ledgers {
+		ledger := &result.Ledger
+		if ledger.LedgerSequence != next {
+			return fmt.Errorf("Ledger %d is missing", next)
+		}
+		if err := ledger.Verify(); err != nil {
+			return err
+		}
+		if parent != nil {
+			if err := ledger.VerifyParent(parent); err != nil {
+				return err
+			}
+		}
+		parent = ledger
+		next++
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	if uint64(next) <= uint64(to) {
+		return fmt.Errorf("Ledger %d is missing", next)
+	}
+	return nil
+}
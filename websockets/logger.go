@@ -0,0 +1,61 @@
+package websockets
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// Logger is told what a Remote can't return to a caller, such as a lost
+// connection or a stream message which can't be read. The arguments after
+// the message are alternating keys and values, as for log/slog, so that a
+// *slog.Logger is a Logger, and any other logger can be adapted to one in
+// a few lines.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+var _ Logger = (*slog.Logger)(nil)
+
+// GlogLogger logs to glog, as Remotes did before they had a Logger, with
+// Debug at verbosity 2
+type GlogLogger struct{}
+
+func (GlogLogger) Debug(msg string, args ...interface{}) {
+	if glog.V(2) {
+		glog.InfoDepth(1, formatLog(msg, args))
+	}
+}
+
+func (GlogLogger) Info(msg string, args ...interface{}) {
+	glog.InfoDepth(1, formatLog(msg, args))
+}
+
+func (GlogLogger) Error(msg string, args ...interface{}) {
+	glog.ErrorDepth(1, formatLog(msg, args))
+}
+
+// formatLog appends the keys and values to msg as key=value
+func formatLog(msg string, args []interface{}) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			fmt.Fprintf(&b, " %v", args[i])
+			break
+		}
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+	return b.String()
+}
+
+func (o *RemoteOptions) logger() Logger {
+	if o.Logger == nil {
+		return GlogLogger{}
+	}
+	return o.Logger
+}
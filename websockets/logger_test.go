@@ -0,0 +1,99 @@
+package websockets
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	. "gopkg.in/check.v1"
+)
+
+type LoggerSuite struct{}
+
+var _ = Suite(&LoggerSuite{})
+
+// syncBuffer may be written by the Remote while the test reads it
+type syncBuffer struct {
+	sync.Mutex
+	bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.Buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.Buffer.String()
+}
+
+func (s *LoggerSuite) TestFormatLog(c *C) {
+	c.Check(formatLog("Closed", nil), Equals, "Closed")
+	c.Check(formatLog("Closed", []interface{}{"endpoint", "ws://localhost", "error", nil, "odd"}), Equals, "Closed endpoint=ws://localhost error=<nil> odd")
+}
+
+func (s *LoggerSuite) TestSlog(c *C) {
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		return []string{
+			`{"type":"ledgerClosed","ledger_index":"bad"}`,
+			`{"id":$ID,"status":"success","type":"response","result":[]}`,
+		}
+	})
+	defer srv.Close()
+	var buf syncBuffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+	r, err := NewRemoteWithOptions(wsURL(srv), RemoteOptions{Logger: log})
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	_, err = r.Fee()
+	c.Check(err, ErrorMatches, "Client Error -1 ws: can't read response: .*")
+	c.Check(buf.String(), Matches, `(?s).*level=INFO msg=Connecting endpoint=ws://.*`)
+	c.Check(buf.String(), Matches, `(?s).*level=ERROR msg="Unreadable stream message" type=ledgerClosed error=.*`)
+}
+
+type unsendableCommand struct {
+	*Command
+	Callback func() `json:"callback"`
+}
+
+func (s *LoggerSuite) TestUnsendable(c *C) {
+	srv := silentServer()
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	cmd := &unsendableCommand{Command: newCommand("callback")}
+	c.Assert(r.TrySend(cmd), IsNil)
+	<-cmd.Ready
+	c.Check(cmd.CommandError, ErrorMatches, "Client Error -1 ws: can't send command: json: unsupported type: func\\(\\)")
+}
+
+func (s *LoggerSuite) TestDisconnected(c *C) {
+	var upgrader websocket.Upgrader
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		ws.ReadMessage()
+		ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "busy"))
+		ws.Close()
+	}))
+	defer srv.Close()
+	var buf syncBuffer
+	r, err := NewRemoteWithOptions(wsURL(srv), RemoteOptions{Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	_, err = r.Fee()
+	c.Check(err, ErrorMatches, "Client Error -1 ws: server disconnected: websocket: close 1013: busy")
+	c.Check(buf.String(), Matches, `(?s).*level=ERROR msg="Connection closed by server" .*busy.*`)
+}
@@ -0,0 +1,60 @@
+package websockets
+
+import "time"
+
+// Metrics is told what a Remote sends and receives, so that it can be
+// counted and timed. Commands are identified by name, e.g. "account_info",
+// and stream messages by type, e.g. "ledgerClosed". The methods are called
+// from the Remote's run loop, so must not block. The metrics package
+// implements Metrics as a Prometheus Collector.
+type Metrics interface {
+	// A command was sent to the server
+	CommandSent(command string)
+
+	// The response to a sent command arrived after rtt, failed if it
+	// was an error
+	CommandAnswered(command string, rtt time.Duration, failed bool)
+
+	// A sent command failed without a response, because the connection
+	// was lost or the caller stopped waiting
+	CommandFailed(command string)
+
+	// No response to a sent command arrived in time
+	CommandTimedOut(command string)
+
+	// The connection was lost and made again
+	Reconnected()
+
+	// A stream message, including a path_find update, arrived
+	StreamMessage(typ string)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) CommandSent(string)                          {}
+func (nopMetrics) CommandAnswered(string, time.Duration, bool) {}
+func (nopMetrics) CommandFailed(string)                        {}
+func (nopMetrics) CommandTimedOut(string)                      {}
+func (nopMetrics) Reconnected()                                {}
+func (nopMetrics) StreamMessage(string)                        {}
+
+func (o *RemoteOptions) metrics() Metrics {
+	if o.Metrics == nil {
+		return nopMetrics{}
+	}
+	return o.Metrics
+}
+
+// commandName returns the name of the command s, or "" if it isn't one
+func commandName(s Syncer) string {
+	if c, ok := s.(commander); ok {
+		return c.command().Name
+	}
+	return ""
+}
+
+// commandFailed reports whether the response to s was an error
+func commandFailed(s Syncer) bool {
+	c, ok := s.(commander)
+	return ok && c.command().CommandError != nil
+}
@@ -0,0 +1,107 @@
+// Package metrics measures websockets.Remotes for Prometheus.
+//
+// A Collector is both the websockets.Metrics of any number of Remotes and
+// a prometheus.Collector to register with an existing registry:
+//
+//	collector := metrics.NewCollector("myapp")
+//	prometheus.MustRegister(collector)
+//	remote, err := websockets.NewRemoteWithOptions(endpoint, websockets.RemoteOptions{
+//		Metrics: collector,
+//	})
+package metrics
+
+import (
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/websockets"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The buckets of the round trip histogram, in seconds, from a server on
+// the same host up to a ledger_data page from a busy one
+var DefaultBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+
+// Collector counts commands by name and stream messages by type. Commands
+// without a name, such as a resubscription, are counted with an empty one.
+type Collector struct {
+	sent      *prometheus.CounterVec
+	failed    *prometheus.CounterVec
+	timedOut  *prometheus.CounterVec
+	rtt       *prometheus.HistogramVec
+	pending   prometheus.Gauge
+	reconnect prometheus.Counter
+	stream    *prometheus.CounterVec
+}
+
+var _ websockets.Metrics = (*Collector)(nil)
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector returns a Collector with metrics named
+// namespace_websocket_*, e.g. myapp_websocket_commands_sent_total
+func NewCollector(namespace string) *Collector {
+	opts := func(name, help string) prometheus.Opts {
+		return prometheus.Opts{Namespace: namespace, Subsystem: "websocket", Name: name, Help: help}
+	}
+	return &Collector{
+		sent:     prometheus.NewCounterVec(prometheus.CounterOpts(opts("commands_sent_total", "Commands sent to rippled.")), []string{"command"}),
+		failed:   prometheus.NewCounterVec(prometheus.CounterOpts(opts("commands_failed_total", "Commands answered with an error or lost with the connection.")), []string{"command"}),
+		timedOut: prometheus.NewCounterVec(prometheus.CounterOpts(opts("commands_timed_out_total", "Commands not answered in time.")), []string{"command"}),
+		rtt: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "websocket",
+			Name:      "command_duration_seconds",
+			Help:      "Time from sending a command to its response.",
+			Buckets:   DefaultBuckets,
+		}, []string{"command"}),
+		pending:   prometheus.NewGauge(prometheus.GaugeOpts(opts("commands_pending", "Commands sent and awaiting a response."))),
+		reconnect: prometheus.NewCounter(prometheus.CounterOpts(opts("reconnects_total", "Connections made again after being lost."))),
+		stream:    prometheus.NewCounterVec(prometheus.CounterOpts(opts("stream_messages_total", "Stream messages received.")), []string{"type"}),
+	}
+}
+
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{c.sent, c.failed, c.timedOut, c.rtt, c.pending, c.reconnect, c.stream}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.collectors() {
+		m.Describe(ch)
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.collectors() {
+		m.Collect(ch)
+	}
+}
+
+func (c *Collector) CommandSent(command string) {
+	c.sent.WithLabelValues(command).Inc()
+	c.pending.Inc()
+}
+
+func (c *Collector) CommandAnswered(command string, rtt time.Duration, failed bool) {
+	c.pending.Dec()
+	c.rtt.WithLabelValues(command).Observe(rtt.Seconds())
+	if failed {
+		c.failed.WithLabelValues(command).Inc()
+	}
+}
+
+func (c *Collector) CommandFailed(command string) {
+	c.pending.Dec()
+	c.failed.WithLabelValues(command).Inc()
+}
+
+func (c *Collector) CommandTimedOut(command string) {
+	c.pending.Dec()
+	c.timedOut.WithLabelValues(command).Inc()
+}
+
+func (c *Collector) Reconnected() {
+	c.reconnect.Inc()
+}
+
+func (c *Collector) StreamMessage(typ string) {
+	c.stream.WithLabelValues(typ).Inc()
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MetricsSuite struct{}
+
+var _ = Suite(&MetricsSuite{})
+
+// server answers fee with a ledgerClosed message then success, server_info
+// with an error, and nothing else
+func server() *httptest.Server {
+	var upgrader websocket.Upgrader
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			var cmd map[string]interface{}
+			if err := ws.ReadJSON(&cmd); err != nil {
+				return
+			}
+			var replies []string
+			switch cmd["command"] {
+			case "fee":
+				replies = []string{
+					`{"type":"ledgerClosed","ledger_index":7}`,
+					`{"id":$ID,"status":"success","type":"response","result":{}}`,
+				}
+			case "server_info":
+				replies = []string{`{"id":$ID,"status":"error","type":"response","error":"tooBusy","error_code":9,"error_message":"The server is too busy to help you now."}`}
+			}
+			for _, reply := range replies {
+				if err := ws.WriteMessage(websocket.TextMessage, []byte(strings.Replace(reply, "$ID", fmt.Sprint(cmd["id"]), -1))); err != nil {
+					return
+				}
+			}
+		}
+	}))
+}
+
+func (s *MetricsSuite) TestCollector(c *C) {
+	srv := server()
+	defer srv.Close()
+	collector := NewCollector("test")
+	registry := prometheus.NewPedanticRegistry()
+	c.Assert(registry.Register(collector), IsNil)
+	r, err := websockets.NewRemoteWithOptions("ws"+strings.TrimPrefix(srv.URL, "http"), websockets.RemoteOptions{
+		Metrics:         collector,
+		CommandTimeouts: map[string]time.Duration{"ledger_current": 50 * time.Millisecond},
+	})
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	_, err = r.Fee()
+	c.Assert(err, IsNil)
+	_, err = r.ServerInfo()
+	c.Assert(err, NotNil)
+	_, err = r.LedgerCurrent()
+	c.Assert(err, FitsTypeOf, &websockets.TimeoutError{})
+
+	c.Check(testutil.ToFloat64(collector.sent.WithLabelValues("fee")), Equals, 1.0)
+	c.Check(testutil.ToFloat64(collector.sent.WithLabelValues("server_info")), Equals, 1.0)
+	c.Check(testutil.ToFloat64(collector.failed.WithLabelValues("fee")), Equals, 0.0)
+	c.Check(testutil.ToFloat64(collector.failed.WithLabelValues("server_info")), Equals, 1.0)
+	c.Check(testutil.ToFloat64(collector.timedOut.WithLabelValues("ledger_current")), Equals, 1.0)
+	c.Check(testutil.ToFloat64(collector.stream.WithLabelValues("ledgerClosed")), Equals, 1.0)
+	c.Check(testutil.ToFloat64(collector.pending), Equals, 0.0)
+	c.Check(testutil.CollectAndCount(collector.rtt), Equals, 2)
+	c.Check(testutil.CollectAndCount(collector), Equals, 11)
+
+	families, err := registry.Gather()
+	c.Assert(err, IsNil)
+	var names []string
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	c.Check(names, DeepEquals, []string{
+		"test_websocket_command_duration_seconds",
+		"test_websocket_commands_failed_total",
+		"test_websocket_commands_pending",
+		"test_websocket_commands_sent_total",
+		"test_websocket_commands_timed_out_total",
+		"test_websocket_reconnects_total",
+		"test_websocket_stream_messages_total",
+	})
+}
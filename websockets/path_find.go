@@ -1,6 +1,9 @@
 package websockets
 
 import (
+	"context"
+	"sync"
+
 	"github.com/kr-jaydeepp/ripple/data"
 )
 
@@ -27,16 +30,41 @@ type PathFindCreateCommand struct {
 	DestinationAmount  data.Amount       `json:"destination_amount"`
 	SendMax            *data.Amount      `json:"send_max,omitempty"`
 	SourceCurrencies   *[]SourceCurrency `json:"source_currencies,omitempty"`
+	Result             *PathFindResult   `json:"result,omitempty"`
+}
 
-	// All commands have Result in their struct?
-	Result *PathFindCreateResult
+// Used for the status and close subcommands
+type PathFindCommand struct {
+	*Command
+	Subcommand string          `json:"subcommand"`
+	Result     *PathFindResult `json:"result,omitempty"`
 }
 
 type SourceCurrency struct {
 	Currency string `json:"currency"`
 }
 
-func (r *Remote) PathFindCreate(src, dest data.Account, amt data.Amount, sendMax *data.Amount, sourceCurrencies *[]SourceCurrency) (*PathFindCreateResult, error) {
+// PathFind is an open path_find request. Until it is closed, rippled
+// keeps searching and each better set of paths is sent on Updates.
+// Only one path_find may be open per connection, so creating another
+// closes this one. Updates is closed when the path_find is closed or
+// the connection is lost.
+type PathFind struct {
+	*PathFindResult
+	Updates chan *PathFindResult
+
+	remote *Remote
+	id     uint64
+}
+
+// Opens a path_find request, returning the initial result. Later
+// results arrive on the Updates channel of the returned PathFind.
+func (r *Remote) PathFindCreate(src, dest data.Account, amt data.Amount, sendMax *data.Amount, sourceCurrencies *[]SourceCurrency) (*PathFind, error) {
+	return r.PathFindCreateCtx(context.Background(), src, dest, amt, sendMax, sourceCurrencies)
+}
+
+// PathFindCreateCtx is like PathFindCreate but gives up when ctx is done
+func (r *Remote) PathFindCreateCtx(ctx context.Context, src, dest data.Account, amt data.Amount, sendMax *data.Amount, sourceCurrencies *[]SourceCurrency) (*PathFind, error) {
 	cmd := &PathFindCreateCommand{
 		Command:            newCommand("path_find"),
 		Subcommand:         "create",
@@ -46,14 +74,117 @@ func (r *Remote) PathFindCreate(src, dest data.Account, amt data.Amount, sendMax
 		SendMax:            sendMax,
 		SourceCurrencies:   sourceCurrencies,
 	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
+	// Registered before sending so that no update can slip past
+	p := &PathFind{
+		Updates: make(chan *PathFindResult, 1),
+		remote:  r,
+		id:      cmd.Id,
+	}
+	r.pathFinds.open(p)
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		r.pathFinds.close(p)
+		return nil, err
+	}
+	p.PathFindResult = cmd.Result
+	return p, nil
+}
+
+// Synchronously requests the latest result of the path_find
+func (p *PathFind) Status() (*PathFindResult, error) {
+	return p.StatusCtx(context.Background())
+}
+
+// StatusCtx is like Status but gives up when ctx is done
+func (p *PathFind) StatusCtx(ctx context.Context) (*PathFindResult, error) {
+	cmd := &PathFindCommand{
+		Command:    newCommand("path_find"),
+		Subcommand: "status",
+	}
+	if err := p.remote.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
 	}
 	return cmd.Result, nil
 }
 
+// Stops the path_find and closes the Updates channel
+func (p *PathFind) Close() error {
+	return p.CloseCtx(context.Background())
+}
+
+// CloseCtx is like Close but gives up when ctx is done. Updates is
+// closed even if the server could not be told.
+func (p *PathFind) CloseCtx(ctx context.Context) error {
+	if !p.remote.pathFinds.close(p) {
+		return nil
+	}
+	cmd := &PathFindCommand{
+		Command:    newCommand("path_find"),
+		Subcommand: "close",
+	}
+	return p.remote.send(ctx, cmd, cmd.Command)
+}
+
+// pathFinds holds the open path_find, if any, so that the run loop can
+// route its updates.
+type pathFinds struct {
+	sync.Mutex
+	current *PathFind
+}
+
+func (s *pathFinds) open(p *PathFind) {
+	s.Lock()
+	defer s.Unlock()
+	if s.current != nil {
+		close(s.current.Updates)
+	}
+	s.current = p
+}
+
+// close reports whether p was still open
+func (s *pathFinds) close(p *PathFind) bool {
+	s.Lock()
+	defer s.Unlock()
+	if s.current != p {
+		return false
+	}
+	close(p.Updates)
+	s.current = nil
+	return true
+}
+
+// closeAll is called when the connection is lost, taking the
+// server side path_find with it.
+func (s *pathFinds) closeAll() {
+	s.Lock()
+	defer s.Unlock()
+	if s.current != nil {
+		close(s.current.Updates)
+		s.current = nil
+	}
+}
+
+// deliver passes an update to the path_find created by command id.
+// Each update supersedes the last, so an unread one is replaced
+// rather than blocking the run loop.
+func (s *pathFinds) deliver(id uint64, update *PathFindResult) {
+	s.Lock()
+	defer s.Unlock()
+	if s.current == nil || s.current.id != id {
+		return
+	}
+	for {
+		select {
+		case s.current.Updates <- update:
+			return
+		default:
+		}
+		select {
+		case <-s.current.Updates:
+		default:
+		}
+	}
+}
+
 /*
 
 {
@@ -93,14 +224,15 @@ func (r *Remote) PathFindCreate(src, dest data.Account, amt data.Amount, sendMax
 */
 
 type PathFindAlternative struct {
-	// TODO paths_computed
-	SourceAmount data.Amount `json:"source_amount"`
+	SourceAmount  data.Amount  `json:"source_amount"`
+	PathsComputed data.PathSet `json:"paths_computed,omitempty"`
 }
 
-type PathFindCreateResult struct {
-	SourceAccount      data.Account `json:"source_account"`
-	DestinationAccount data.Account `json:"destination_account"`
-	DestinationAmount  data.Amount  `json:"destination_amount"`
-	// TODO
-	Alternatives []PathFindAlternative
+type PathFindResult struct {
+	SourceAccount      data.Account          `json:"source_account"`
+	DestinationAccount data.Account          `json:"destination_account"`
+	DestinationAmount  data.Amount           `json:"destination_amount"`
+	Alternatives       []PathFindAlternative `json:"alternatives"`
+	FullReply          bool                  `json:"full_reply"`
+	Closed             bool                  `json:"closed,omitempty"`
 }
@@ -0,0 +1,157 @@
+package websockets
+
+import (
+	"errors"
+	"sync"
+)
+
+// Capacity of the command queue when no other size is configured
+const DefaultQueueSize = 10
+
+// ErrQueueFull is returned by TrySend when the command queue has no room
+var ErrQueueFull = errors.New("ws: queue full")
+
+// Priority decides which queued commands are sent first. Commands of the
+// same priority are sent in the order they were queued.
+type Priority int
+
+const (
+	// Commands which only ask about the ledger
+	PriorityQuery Priority = iota
+
+	// submit and submit_multisigned
+	PrioritySubmit
+
+	// subscribe, unsubscribe and path_find, which control streams
+	PriorityStream
+
+	priorities = iota
+)
+
+func commandPriority(name string) Priority {
+	switch name {
+	case "subscribe", "unsubscribe", "path_find":
+		return PriorityStream
+	case "submit", "submit_multisigned":
+		return PrioritySubmit
+	default:
+		return PriorityQuery
+	}
+}
+
+// commandQueue holds the commands waiting for the run loop, which is
+// signalled on ready while any are queued
+type commandQueue struct {
+	sync.Mutex
+	queued [priorities][]Syncer
+	len    int
+	cap    int
+	ready  chan struct{}
+	popped chan struct{} // Closed and replaced when there is room again
+}
+
+func newCommandQueue(size int) *commandQueue {
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+	return &commandQueue{
+		cap:    size,
+		ready:  make(chan struct{}, 1),
+		popped: make(chan struct{}),
+	}
+}
+
+// push queues s unless the queue is full, when it returns a channel which
+// is closed once there may be room
+func (q *commandQueue) push(s Syncer) (bool, <-chan struct{}) {
+	q.Lock()
+	defer q.Unlock()
+	if q.len >= q.cap {
+		return false, q.popped
+	}
+	p := commandPriority(commandName(s))
+	q.queued[p] = append(q.queued[p], s)
+	q.len++
+	q.signal()
+	return true, nil
+}
+
+// pop takes the first command of the highest priority
+func (q *commandQueue) pop() (Syncer, bool) {
+	q.Lock()
+	defer q.Unlock()
+	for p := len(q.queued) - 1; p >= 0; p-- {
+		if len(q.queued[p]) == 0 {
+			continue
+		}
+		s := q.queued[p][0]
+		q.queued[p][0] = nil
+		q.queued[p] = q.queued[p][1:]
+		q.len--
+		close(q.popped)
+		q.popped = make(chan struct{})
+		if q.len > 0 {
+			q.signal()
+		}
+		return s, true
+	}
+	return nil, false
+}
+
+// remove takes s from the queue, unless it has been popped already
+func (q *commandQueue) remove(s Syncer) bool {
+	q.Lock()
+	defer q.Unlock()
+	p := commandPriority(commandName(s))
+	queued := q.queued[p]
+	for i := range queued {
+		if queued[i] != s {
+			continue
+		}
+		copy(queued[i:], queued[i+1:])
+		queued[len(queued)-1] = nil
+		q.queued[p] = queued[:len(queued)-1]
+		q.len--
+		close(q.popped)
+		q.popped = make(chan struct{})
+		return true
+	}
+	return false
+}
+
+func (q *commandQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *commandQueue) Len() int {
+	q.Lock()
+	defer q.Unlock()
+	return q.len
+}
+
+// TrySend queues cmd, such as a *FeeCommand, unless the queue is full,
+// when it returns ErrQueueFull at once, or the Remote is closing, when it
+// returns ErrClosed. Ready receives once the response, or an error, is in
+// cmd. Unlike the other methods TrySend doesn't wait for the rate limits.
+func (r *Remote) TrySend(cmd Syncer) error {
+	select {
+	case <-r.closing:
+		return ErrClosed
+	case <-r.done:
+		return ErrClosed
+	default:
+	}
+	if ok, _ := r.outgoing.push(cmd); !ok {
+		return ErrQueueFull
+	}
+	return nil
+}
+
+// QueueLen returns how many commands are waiting to be sent, out of
+// RemoteOptions.QueueSize, so that callers can hold back when it is high
+func (r *Remote) QueueLen() int {
+	return r.outgoing.Len()
+}
@@ -0,0 +1,141 @@
+package websockets
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type QueueSuite struct{}
+
+var _ = Suite(&QueueSuite{})
+
+func (s *QueueSuite) TestPriority(c *C) {
+	q := newCommandQueue(4)
+	for _, name := range []string{"account_info", "submit", "subscribe", "fee"} {
+		ok, _ := q.push(newCommand(name))
+		c.Assert(ok, Equals, true)
+	}
+	ok, popped := q.push(newCommand("tx"))
+	c.Assert(ok, Equals, false)
+	c.Check(q.Len(), Equals, 4)
+
+	var names []string
+	for cmd, ok := q.pop(); ok; cmd, ok = q.pop() {
+		names = append(names, commandName(cmd))
+	}
+	c.Check(names, DeepEquals, []string{"subscribe", "submit", "account_info", "fee"})
+	select {
+	case <-popped:
+	default:
+		c.Error("popped wasn't closed")
+	}
+	c.Check(q.Len(), Equals, 0)
+}
+
+func (s *QueueSuite) TestTrySend(c *C) {
+	// A Remote without a run loop, whose queue is never emptied
+	r := &Remote{
+		outgoing: newCommandQueue(2),
+		limits:   newRateLimits(&RemoteOptions{}),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	c.Check(r.TrySend(newCommand("fee")), IsNil)
+	c.Check(r.TrySend(newCommand("fee")), IsNil)
+	c.Check(r.TrySend(newCommand("fee")), Equals, ErrQueueFull)
+	c.Check(r.QueueLen(), Equals, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	c.Check(r.queue(ctx, newCommand("fee")), Equals, context.DeadlineExceeded)
+
+	queued := make(chan error)
+	go func() { queued <- r.queue(context.Background(), newCommand("fee")) }()
+	r.outgoing.pop()
+	c.Check(<-queued, IsNil)
+
+	close(r.closing)
+	c.Check(r.TrySend(newCommand("fee")), Equals, ErrClosed)
+}
+
+func (s *QueueSuite) TestRemote(c *C) {
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		return []string{`{"id":$ID,"status":"success","type":"response","result":{}}`}
+	})
+	defer server.Close()
+	r, err := NewRemoteWithOptions(wsURL(server), RemoteOptions{QueueSize: 1})
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := r.Fee()
+			c.Check(err, IsNil)
+		}()
+	}
+	wg.Wait()
+	c.Check(r.QueueLen(), Equals, 0)
+}
+
+func (s *QueueSuite) TestCancel(c *C) {
+	// A Remote without a run loop, whose rate limit lets one command
+	// through at a time
+	r := &Remote{
+		outgoing:  newCommandQueue(2),
+		cancelled: make(chan Syncer, 1),
+		limits:    newRateLimits(&RemoteOptions{RateLimit: RateLimit{Rate: 1, Burst: 1}}),
+		closing:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	// The first is queued and the second waits for the rate limit, and
+	// neither is left to be sent once cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	errs := make(chan error)
+	for i := 0; i < 2; i++ {
+		go func() {
+			cmd := newCommand("fee")
+			errs <- r.send(ctx, cmd, cmd)
+		}()
+	}
+	c.Check(<-errs, Equals, context.DeadlineExceeded)
+	c.Check(<-errs, Equals, context.DeadlineExceeded)
+	c.Check(r.QueueLen(), Equals, 0)
+	c.Check(r.cancelled, HasLen, 0)
+
+	// Once sent, the run loop is asked to forget it, when there is room
+	sent := newCommand("fee")
+	c.Assert(r.TrySend(sent), IsNil)
+	r.outgoing.pop()
+	r.cancelled <- newCommand("fee")
+	cancelled := make(chan struct{})
+	go func() {
+		defer close(cancelled)
+		r.cancel(sent)
+	}()
+	select {
+	case <-cancelled:
+		c.Fatal("cancel didn't wait for room")
+	case <-time.After(10 * time.Millisecond):
+	}
+	<-r.cancelled
+	<-cancelled
+	c.Check(<-r.cancelled, Equals, Syncer(sent))
+
+	// or until the Remote is done
+	r.cancelled <- newCommand("fee")
+	cancelled = make(chan struct{})
+	go func() {
+		defer close(cancelled)
+		r.cancel(sent)
+	}()
+	close(r.done)
+	<-cancelled
+}
@@ -0,0 +1,153 @@
+package websockets
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit is how fast commands may be sent. When the server answers
+// tooBusy or slowDown the rate is halved, down to a sixty-fourth of Rate,
+// and then recovers by a sixteenth of Rate with each command answered.
+type RateLimit struct {
+	Rate  float64 // Commands per second. Zero means no limit.
+	Burst int     // Commands which may be sent at once. Zero means 1.
+}
+
+// limiter is a token bucket for a RateLimit
+type limiter struct {
+	sync.Mutex
+	limit  RateLimit
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(limit RateLimit) *limiter {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+	return &limiter{
+		limit:  limit,
+		rate:   limit.Rate,
+		tokens: float64(limit.Burst),
+		last:   time.Now(),
+	}
+}
+
+func (l *limiter) refill(now time.Time) {
+	if now.After(l.last) {
+		l.tokens = math.Min(float64(l.limit.Burst), l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+	}
+}
+
+// reserve takes a token and returns how long to wait before it is there
+func (l *limiter) reserve(now time.Time) time.Duration {
+	l.Lock()
+	defer l.Unlock()
+	l.refill(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// unreserve gives back a token which wasn't used
+func (l *limiter) unreserve() {
+	l.Lock()
+	defer l.Unlock()
+	l.tokens = math.Min(float64(l.limit.Burst), l.tokens+1)
+}
+
+func (l *limiter) slowDown(now time.Time) {
+	l.Lock()
+	defer l.Unlock()
+	l.refill(now)
+	l.rate = math.Max(l.rate/2, l.limit.Rate/64)
+}
+
+func (l *limiter) speedUp(now time.Time) {
+	l.Lock()
+	defer l.Unlock()
+	l.refill(now)
+	l.rate = math.Min(l.rate+l.limit.Rate/16, l.limit.Rate)
+}
+
+// rateLimits are the limiters of a Remote, all of which a command waits
+// for
+type rateLimits struct {
+	remote   *limiter
+	commands map[string]*limiter
+}
+
+func newRateLimits(o *RemoteOptions) *rateLimits {
+	l := &rateLimits{
+		remote:   newLimiter(o.RateLimit),
+		commands: make(map[string]*limiter),
+	}
+	for name, limit := range o.CommandRateLimits {
+		if cl := newLimiter(limit); cl != nil {
+			l.commands[name] = cl
+		}
+	}
+	return l
+}
+
+func (l *rateLimits) limiters(name string) []*limiter {
+	var limiters []*limiter
+	if cl := l.commands[name]; cl != nil {
+		limiters = append(limiters, cl)
+	}
+	if l.remote != nil {
+		limiters = append(limiters, l.remote)
+	}
+	return limiters
+}
+
+// wait blocks until the named command may be sent, or ctx is done
+func (l *rateLimits) wait(ctx context.Context, name string) error {
+	limiters := l.limiters(name)
+	if len(limiters) == 0 {
+		return nil
+	}
+	var delay time.Duration
+	now := time.Now()
+	for _, limiter := range limiters {
+		if d := limiter.reserve(now); d > delay {
+			delay = d
+		}
+	}
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		for _, limiter := range limiters {
+			limiter.unreserve()
+		}
+		return ctx.Err()
+	}
+}
+
+// observe adapts the rates to the outcome of the named command
+func (l *rateLimits) observe(name string, err error) {
+	now := time.Now()
+	for _, limiter := range l.limiters(name) {
+		switch {
+		case IsTooBusy(err):
+			limiter.slowDown(now)
+		case err == nil:
+			limiter.speedUp(now)
+		}
+	}
+}
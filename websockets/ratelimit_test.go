@@ -0,0 +1,79 @@
+package websockets
+
+import (
+	"context"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type RateLimitSuite struct{}
+
+var _ = Suite(&RateLimitSuite{})
+
+func (s *RateLimitSuite) TestLimiter(c *C) {
+	c.Check(newLimiter(RateLimit{}), IsNil)
+	l := newLimiter(RateLimit{Rate: 10, Burst: 2})
+	now := l.last
+	c.Check(l.reserve(now), Equals, time.Duration(0))
+	c.Check(l.reserve(now), Equals, time.Duration(0))
+	c.Check(l.reserve(now), Equals, 100*time.Millisecond)
+	now = now.Add(100 * time.Millisecond)
+	c.Check(l.reserve(now), Equals, 100*time.Millisecond)
+
+	l.slowDown(now)
+	c.Check(l.rate, Equals, 5.0)
+	now = now.Add(100 * time.Millisecond)
+	c.Check(l.reserve(now), Equals, 300*time.Millisecond)
+	l.unreserve()
+	c.Check(l.tokens, Equals, -0.5)
+
+	for i := 0; i < 10; i++ {
+		l.slowDown(now)
+	}
+	c.Check(l.rate, Equals, 10.0/64)
+	l.speedUp(now)
+	c.Check(l.rate, Equals, 10.0/64+10.0/16)
+	for i := 0; i < 20; i++ {
+		l.speedUp(now)
+	}
+	c.Check(l.rate, Equals, 10.0)
+}
+
+func (s *RateLimitSuite) TestRemote(c *C) {
+	busy := true
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		if busy {
+			busy = false
+			return []string{`{"id":$ID,"status":"error","type":"response","error":"tooBusy","error_code":9,"error_message":"The server is too busy to help you now."}`}
+		}
+		return []string{`{"id":$ID,"status":"success","type":"response","result":{"ledger_current_index":5}}`}
+	})
+	defer server.Close()
+	r, err := NewRemoteWithOptions(wsURL(server), RemoteOptions{
+		RateLimit:         RateLimit{Rate: 1000, Burst: 1},
+		CommandRateLimits: map[string]RateLimit{"fee": {Rate: 20}},
+	})
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	_, err = r.LedgerCurrent()
+	c.Check(IsTooBusy(err), Equals, true)
+	c.Check(r.limits.remote.rate, Equals, 500.0)
+	_, err = r.LedgerCurrent()
+	c.Check(err, IsNil)
+	c.Check(r.limits.remote.rate, Equals, 500.0+1000.0/16)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err = r.Fee()
+		c.Check(err, IsNil)
+	}
+	c.Check(time.Since(start) >= 100*time.Millisecond, Equals, true)
+
+	// Waiting for the limit gives up with ctx
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = r.FeeCtx(ctx)
+	c.Check(err, Equals, context.DeadlineExceeded)
+}
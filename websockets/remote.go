@@ -2,16 +2,19 @@ package websockets
 
 import (
 	"bytes"
-	"encoding/hex"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
 	"net/url"
-	"reflect"
 	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/golang/glog"
 	"github.com/gorilla/websocket"
 	"github.com/kr-jaydeepp/ripple/data"
 )
@@ -29,83 +32,286 @@ const (
 	// Time allowed to connect to server.
 	dialTimeout = 5 * time.Second
 
-	// time gap between reconnection
+	// time gap between reconnection, unless RemoteOptions.ReconnectInterval
+	// is set
 	connReconnectInterval = 30 * time.Second
 
+	// Time allowed for a command response when no other timeout applies.
+	DefaultCommandTimeout = time.Minute
+
 	// server disconnect error message
 	ServerDisconnectErrorMsg = "Client Error -1 ws: server disconnected"
+
+	// Capacity of the Incoming channel when no other size is configured.
+	DefaultIncomingBuffer = 1000
+)
+
+// ErrClosed is returned for a command sent to a Remote which is closed, or
+// is shutting down.
+var ErrClosed = errors.New("ws: remote closed")
+
+// OverflowPolicy decides what becomes of a stream message which arrives
+// while the Incoming channel is full.
+type OverflowPolicy int
+
+const (
+	// Wait for the consumer, which holds up every command and stream.
+	OverflowBlock OverflowPolicy = iota
+
+	// Discard the oldest unread message to make room.
+	OverflowDropOldest
+
+	// Discard the message which doesn't fit.
+	OverflowDropNewest
+
+	// Discard the oldest unread message to make room for an *OverflowError,
+	// then disconnect without reconnecting.
+	OverflowDisconnect
 )
 
+// OverflowError is the last message on the Incoming channel of a Remote
+// disconnected by OverflowDisconnect.
+type OverflowError struct {
+	Capacity int
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("ws: Incoming channel overflowed its %d messages", e.Capacity)
+}
+
+// RemoteOptions configures a Remote created with NewRemoteWithOptions.
+type RemoteOptions struct {
+	// Reconnect to the server when the connection is lost
+	Reconnect bool
+
+	// Time between attempts to reconnect. Zero means 30 seconds.
+	ReconnectInterval time.Duration
+
+	// Time allowed for each command response. Zero means DefaultCommandTimeout.
+	Timeout time.Duration
+
+	// Per command overrides of Timeout, keyed by command name,
+	// e.g. "ledger_data" or "submit".
+	CommandTimeouts map[string]time.Duration
+
+	// How fast commands may be sent. Zero means no limit.
+	RateLimit RateLimit
+
+	// Per command limits, keyed by command name, which apply as well as
+	// RateLimit.
+	CommandRateLimits map[string]RateLimit
+
+	// An http, https or socks5 proxy to connect through. When nil the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+	Proxy *url.URL
+
+	// Capacity of the Incoming channel. Zero means DefaultIncomingBuffer.
+	IncomingBuffer int
+
+	// Capacity of the queue of commands waiting to be sent, beyond which
+	// they wait or TrySend fails. Zero means DefaultQueueSize.
+	QueueSize int
+
+	// What to do with stream messages when the Incoming channel is full.
+	Overflow OverflowPolicy
+
+	// Sizes of the buffers of the connection. Zero means 1024 bytes,
+	// whereas a ledger_data page is a few hundred kilobytes.
+	ReadBufferSize  int
+	WriteBufferSize int
+
+	// Negotiate permessage-deflate compression, which the server may
+	// decline
+	EnableCompression bool
+
+	// The api_version sent with every command which doesn't set its own.
+	// Zero leaves it to the server, which takes 1. The responses of both
+	// versions decode into the same types.
+	APIVersion int
+
+	// Responses and stream messages larger than this are discarded, and
+	// the command fails with a *MessageTooLargeError. Zero means no
+	// limit.
+	MaxMessageSize int64
+
+	// Allows Sign and SignFor, which send secrets to the server. Only set
+	// this for a trusted rippled in admin mode, reached over TLS or
+	// localhost, as anyone who can read the traffic can steal the keys.
+	AllowServerSigning bool
+
+	// Where to log what can't be returned to a caller. Nil means
+	// GlogLogger.
+	Logger Logger
+
+	// What to tell of the commands and stream messages. Nil means
+	// nothing is measured.
+	Metrics Metrics
+
+	// What to tell of each command, to trace it. Nil means nothing is
+	// traced.
+	Tracer Tracer
+
+	// What to give every message sent and received. Nil means nothing is
+	// tapped.
+	Tap Tap
+}
+
+func (o *RemoteOptions) proxy(req *http.Request) (*url.URL, error) {
+	if o.Proxy != nil {
+		return o.Proxy, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+func (o *RemoteOptions) reconnectInterval() time.Duration {
+	if o.ReconnectInterval > 0 {
+		return o.ReconnectInterval
+	}
+	return connReconnectInterval
+}
+
+// timeout returns how long to wait for a response to the named command.
+// A non-zero override, usually set via Command.Timeout, always wins.
+func (o *RemoteOptions) timeout(name string, override time.Duration) time.Duration {
+	switch {
+	case override > 0:
+		return override
+	case o.CommandTimeouts[name] > 0:
+		return o.CommandTimeouts[name]
+	case o.Timeout > 0:
+		return o.Timeout
+	default:
+		return DefaultCommandTimeout
+	}
+}
+
+// Remote is a connection to a rippled server over websocket. Each
+// connection is owned by a run loop, and its read and write pumps, which
+// hand over to reConnect when it is lost, so that only one goroutine at a
+// time uses the pending commands and the Incoming channel. The rest of the
+// Remote is only read, or guarded, after NewRemoteWithOptions.
 type Remote struct {
-	Incoming chan interface{}
-	outgoing chan Syncer
-	ws       *websocket.Conn
-	url      *url.URL
-	reConn   bool
-	shutdown bool
+	Incoming   chan interface{}
+	outgoing   *commandQueue
+	cancelled  chan Syncer
+	url        *url.URL
+	options    RemoteOptions
+	overflowed bool // Only used by the run loop
+	dropped    uint64
+	log        Logger
+	metrics    Metrics
+	health     *health
+	limits     *rateLimits
+
+	capabilities capabilities
+
+	subscriptions subscriptions
+	pathFinds     pathFinds
+
+	closing     chan struct{} // Closed when no more commands are accepted
+	stop        chan struct{} // Closed to disconnect without waiting
+	done        chan struct{} // Closed once the Remote is cleaned up
+	closingOnce sync.Once
+	stopOnce    sync.Once
 }
 
 // NewRemote returns a new remote session connected to the specified
 // server endpoint URI. To close the connection, use Close().
 func NewRemote(endpoint string, enableReconnection bool) (*Remote, error) {
-	glog.Infoln(endpoint)
+	return NewRemoteWithOptions(endpoint, RemoteOptions{Reconnect: enableReconnection})
+}
+
+// NewRemoteWithOptions is like NewRemote but allows the connection
+// behaviour to be tuned.
+func NewRemoteWithOptions(endpoint string, options RemoteOptions) (*Remote, error) {
+	options.logger().Info("Connecting", "endpoint", endpoint)
 	u, err := url.Parse(endpoint)
 	if err != nil {
 		return nil, err
 	}
-	c, err := net.DialTimeout("tcp", u.Host, dialTimeout)
-	if err != nil {
-		return nil, err
+	if options.IncomingBuffer <= 0 {
+		options.IncomingBuffer = DefaultIncomingBuffer
 	}
-	ws, _, err := websocket.NewClient(c, u, nil, 1024, 1024)
-	if err != nil {
-		return nil, err
+	if options.ReadBufferSize <= 0 {
+		options.ReadBufferSize = 1024
+	}
+	if options.WriteBufferSize <= 0 {
+		options.WriteBufferSize = 1024
 	}
 	r := &Remote{
-		Incoming: make(chan interface{}, 1000),
-		outgoing: make(chan Syncer, 10),
-		ws:       ws,
-		url:      u,
-		reConn:   enableReconnection,
+		Incoming:  make(chan interface{}, options.IncomingBuffer),
+		outgoing:  newCommandQueue(options.QueueSize),
+		cancelled: make(chan Syncer, 100),
+		url:       u,
+		options:   options,
+		log:       options.logger(),
+		metrics:   options.metrics(),
+		health:    newHealth(),
+		limits:    newRateLimits(&options),
+		closing:   make(chan struct{}),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	ws, err := r.dial()
+	if err != nil {
+		return nil, err
 	}
 
-	go r.run()
+	go r.run(ws, nil)
 	return r, nil
 }
 
+// dial opens a new websocket connection to the server,
+// going through a proxy if one is configured.
+func (r *Remote) dial() (*websocket.Conn, error) {
+	dialer := &websocket.Dialer{
+		NetDial:           (&net.Dialer{Timeout: dialTimeout}).Dial,
+		Proxy:             r.options.proxy,
+		HandshakeTimeout:  dialTimeout,
+		ReadBufferSize:    r.options.ReadBufferSize,
+		WriteBufferSize:   r.options.WriteBufferSize,
+		EnableCompression: r.options.EnableCompression,
+	}
+	ws, _, err := dialer.Dial(r.url.String(), nil)
+	return ws, err
+}
+
 // reConnect try to reconnect to server in case connection gets disconnected
 func (r *Remote) reConnect() {
-	glog.V(2).Info("reConnect!")
-	ticker := time.NewTicker(connReconnectInterval)
+	r.log.Debug("Reconnecting")
+	ticker := time.NewTicker(r.options.reconnectInterval())
 	defer ticker.Stop()
 
 connectLoop:
 	for {
 		select {
-		case command, ok := <-r.outgoing:
-			if !ok {
-				glog.Errorln("outgoing channel closed")
-				return
+		case <-r.outgoing.ready:
+			for command, ok := r.outgoing.pop(); ok; command, ok = r.outgoing.pop() {
+				command.Fail("ws: server disconnected")
 			}
-			command.Fail("ws: server disconnected")
+
+		// Nothing is pending to wait for
+		case <-r.closing:
+			r.finish(nil)
+			return
+
+		// Nothing is pending while disconnected
+		case <-r.cancelled:
 
 		// Time to reconnect
 		case <-ticker.C:
-			glog.Info("reConnect: Trying to reconnect")
+			r.log.Info("Trying to reconnect", "endpoint", r.url)
 
-			c, err := net.DialTimeout("tcp", r.url.Host, dialTimeout)
+			ws, err := r.dial()
 			if err != nil {
-				glog.Error("reConnect: DailTimeout Error: ", err)
+				r.log.Error("Reconnection failed", "endpoint", r.url, "error", err)
 				continue
 			}
-			ws, _, err := websocket.NewClient(c, r.url, nil, 1024, 1024)
-			if err != nil {
-				glog.Error("reConnect: NewClient Error: ", err)
-				continue
-			}
-			r.ws = ws
-			go r.run()
-			glog.Info("reConnect: successfull")
+			r.capabilities.forget()
+			go r.run(ws, r.subscriptions.replay(r.Incoming))
+			r.log.Info("Reconnected", "endpoint", r.url)
+			r.metrics.Reconnected()
+			r.health.setState(StateConnected, nil)
 			break connectLoop
 		}
 	}
@@ -113,11 +319,12 @@ connectLoop:
 
 // Close shuts down the Remote session and blocks until all internal
 // goroutines have been cleaned up.
-// Any commands that are pending a response will return with an error.
+// Any commands that are pending a response will return with an error,
+// and any sent afterwards with ErrClosed.
 func (r *Remote) Close() {
-	glog.Info("closing remote connection")
-	r.shutdown = true
-	close(r.outgoing)
+	r.log.Info("Closing connection", "endpoint", r.url)
+	r.closingOnce.Do(func() { close(r.closing) })
+	r.stopOnce.Do(func() { close(r.stop) })
 
 	// Drain the Incoming channel and block until it is closed,
 	// indicating that this Remote is fully cleaned up.
@@ -125,406 +332,1356 @@ func (r *Remote) Close() {
 	}
 }
 
-// run spawns the read/write pumps and then runs until Close() is called.
-func (r *Remote) run() {
-	outbound := make(chan interface{})
-	inbound := make(chan []byte)
+// Shutdown is like Close, but waits for the responses to the commands
+// already sent before disconnecting. Commands sent meanwhile fail with
+// ErrClosed. If ctx is done first, the Remote is closed at once and
+// ctx.Err() is returned.
+func (r *Remote) Shutdown(ctx context.Context) error {
+	r.log.Info("Shutting down", "endpoint", r.url)
+	r.closingOnce.Do(func() { close(r.closing) })
+	for {
+		select {
+		case _, ok := <-r.Incoming:
+			if !ok {
+				return nil
+			}
+		case <-ctx.Done():
+			r.Close()
+			return ctx.Err()
+		}
+	}
+}
+
+// CloseWithTimeout is Shutdown, giving up waiting after d
+func (r *Remote) CloseWithTimeout(d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return r.Shutdown(ctx)
+}
+
+// finish cleans up once the Remote won't connect again
+func (r *Remote) finish(err error) {
+	r.health.setState(StateClosed, err)
+	r.subscriptions.closeAll()
+	close(r.Incoming)
+	close(r.done)
+}
+
+// run spawns the read/write pumps for ws and then runs until Close() is
+// called or ws is lost. A non-nil resubscribe command is sent before any
+// other.
+func (r *Remote) run(ws *websocket.Conn, resubscribe Syncer) {
+	outbound := make(chan []byte)
+	inbound := make(chan received)
 	pending := make(map[uint64]Syncer)
-	timeout := make(chan uint64)
+	sent := make(map[uint64]time.Time)
+	timeout := make(chan *TimeoutError)
 	timeoutCancellers := make(map[uint64]chan struct{})
 	writePumpStopped := make(chan struct{})
+	disconnected := "ws: server disconnected"
+	var readErr error
+	closed := false // by Close or Shutdown
+	closing := r.closing
+	draining := false
 
 	defer func() {
 		close(outbound) // Shuts down the writePump
 
 		// Cancel all pending commands with an error
 		for _, c := range pending {
-			c.Fail("ws: server disconnected")
+			r.metrics.CommandFailed(commandName(c))
+			c.Fail(disconnected)
+		}
+		for _, canceller := range timeoutCancellers {
+			close(canceller)
 		}
+		r.pathFinds.closeAll()
 
 		// Drain the inbound channel and block until it is closed,
-		// indicating that the readPump has returned.
+		// indicating that the readPump has returned, then wait for
+		// the writePump to close ws.
 		for range inbound {
 		}
+		<-writePumpStopped
 
-		if r.reConn && !r.shutdown {
+		if r.options.Reconnect && !closed && !r.overflowed {
+			r.health.setState(StateReconnecting, readErr)
 			go r.reConnect()
 		} else {
-			close(r.Incoming)
+			if closed {
+				readErr = nil // The connection was closed by Close
+			}
+			r.finish(readErr)
 		}
 	}()
 
 	// Spawn read/write goroutines
 	go func() {
-		defer r.ws.Close()
 		defer close(writePumpStopped)
-		r.writePump(outbound)
+		defer ws.Close()
+		r.writePump(ws, outbound)
 	}()
 	go func() {
 		defer close(inbound)
-		r.readPump(inbound)
+		readErr = r.readPump(ws, inbound)
 	}()
 
-	commandTimeoutFunc := func(commandID uint64, timeoutCanceller chan struct{}) {
-		timer := time.NewTimer(time.Minute)
+	commandTimeoutFunc := func(expired *TimeoutError, timeoutCanceller chan struct{}) {
+		timer := time.NewTimer(expired.Timeout)
 		select {
 		case <-timeoutCanceller:
 			timer.Stop()
 			return
 		case <-timer.C:
-			timeout <- commandID
+			select {
+			case timeout <- expired:
+			case <-timeoutCanceller:
+			}
 			return
 		}
 	}
 
+	// answered takes the command which a response is for from pending,
+	// returning how long it took
+	answered := func(id uint64) (Syncer, time.Duration, bool) {
+		cmd, ok := pending[id]
+		if !ok {
+			return nil, 0, false
+		}
+		delete(pending, id)
+		rtt := time.Since(sent[id])
+		delete(sent, id)
+		if canceller, exists := timeoutCancellers[id]; exists {
+			close(canceller)
+			delete(timeoutCancellers, id)
+		}
+		return cmd, rtt, true
+	}
+
+	// dispatch sends a command to the writePump and starts its timeout.
+	// Returns false if the writePump has stopped.
+	dispatch := func(command Syncer) bool {
+		if command.ID() == 0 {
+			command.SetID(atomic.AddUint64(&counter, 1))
+		}
+		if c, ok := command.(commander); ok && c.command().APIVersion == 0 {
+			c.command().APIVersion = r.options.APIVersion
+		}
+		b, err := json.Marshal(command)
+		if err != nil {
+			command.Fail(fmt.Sprintf("ws: can't send command: %s", err))
+			return true
+		}
+		// add the command to "pending" so that it doesn't get stuck if writepump has stopped
+		id := command.ID()
+		pending[id] = command
+		sent[id] = time.Now()
+		r.metrics.CommandSent(commandName(command))
+		expired := &TimeoutError{Id: id}
+		if c, ok := command.(commander); ok {
+			expired.Command = c.command().Name
+			expired.Timeout = r.options.timeout(c.command().Name, c.command().Timeout)
+		} else {
+			expired.Timeout = r.options.timeout("", 0)
+		}
+
+		// add cancellation before sending the command info
+		canceller := make(chan struct{})
+		timeoutCancellers[id] = canceller
+
+		select {
+		case <-writePumpStopped:
+			delete(timeoutCancellers, id) // never actually sent the command
+			return false
+		case outbound <- b:
+			go commandTimeoutFunc(expired, canceller)
+			return true
+		}
+	}
+
+	// Replay subscriptions lost with the previous connection
+	if resubscribe != nil && !dispatch(resubscribe) {
+		return
+	}
+
 	// Main run loop
 	var response Command
 	for {
+		// Shutdown waits for what was sent, or queued before it
+		if draining && len(pending) == 0 && r.outgoing.Len() == 0 {
+			closed = true
+			return
+		}
 		select {
-		case command, ok := <-r.outgoing:
-			if !ok {
+		case <-r.outgoing.ready:
+			command, ok := r.outgoing.pop()
+			if ok && !dispatch(command) {
 				return
 			}
 
-			// add the command to "pending" so that it doesn't get stuck if writepump has stopped
-			id := reflect.ValueOf(command).Elem().FieldByName("Id").Uint()
-			pending[id] = command
+		case <-closing:
+			closing = nil
+			draining = true
 
-			// add cancellation before sending the command info
-			canceller := make(chan struct{})
-			timeoutCancellers[id] = canceller
+		case <-r.stop:
+			closed = true
+			return
 
-			select {
-			case <-writePumpStopped:
-				delete(timeoutCancellers, id) // never actually sent the command
+		case msg, ok := <-inbound:
+			if !ok {
+				if readErr != nil {
+					disconnected = fmt.Sprintf("ws: server disconnected: %s", readErr)
+				}
+				r.log.Error("Connection closed by server", "endpoint", r.url, "error", readErr)
 				return
-			case outbound <- command:
-				go commandTimeoutFunc(id, canceller)
 			}
 
-		case in, ok := <-inbound:
-			if !ok {
-				glog.Errorln("Connection closed by server")
-				return
+			// A message over MaxMessageSize fails its command, if it
+			// has one
+			if tooLarge := msg.tooLarge; tooLarge != nil {
+				cmd, rtt, ok := answered(tooLarge.Id)
+				if !ok {
+					r.log.Error("Discarded message", "error", tooLarge)
+					continue
+				}
+				r.metrics.CommandAnswered(commandName(cmd), rtt, true)
+				if c, ok := cmd.(commander); ok {
+					c.command().reject(tooLarge)
+				} else {
+					cmd.Fail(tooLarge.Error())
+				}
+				continue
 			}
 
+			in := msg.message
 			if err := json.Unmarshal(in, &response); err != nil {
-				glog.Errorln(err.Error())
+				r.log.Error("Unreadable message", "error", err, "message", string(in))
+				continue
+			}
+			// Path find updates go to the PathFind which asked for them
+			if response.Type == "path_find" {
+				r.metrics.StreamMessage(response.Type)
+				update := &PathFindResult{}
+				if err := json.Unmarshal(in, update); err != nil {
+					r.log.Error("Unreadable path_find update", "error", err, "message", string(in))
+					continue
+				}
+				r.pathFinds.deliver(response.Id, update)
 				continue
 			}
+
 			// Stream message
 			factory, ok := streamMessageFactory[response.Type]
 			if ok {
+				r.metrics.StreamMessage(response.Type)
 				cmd := factory()
 				if err := json.Unmarshal(in, &cmd); err != nil {
-					glog.Errorln(err.Error(), string(in))
+					r.log.Error("Unreadable stream message", "type", response.Type, "error", err, "message", string(in))
 					continue
 				}
-				r.Incoming <- cmd
+				if ledger, ok := cmd.(*LedgerStreamMsg); ok {
+					r.health.ledgerClosed(ledger.LedgerSequence)
+				}
+				// Unless a Subscription wants it
+				if !r.subscriptions.deliver(cmd) && !r.publish(cmd) {
+					return
+				}
 				continue
 			}
 
 			// Command response message
-			cmd, ok := pending[response.Id]
+			cmd, rtt, ok := answered(response.Id)
 			if !ok {
-				glog.Errorf("Unexpected message: %+v", response)
+				r.log.Error("Unexpected message", "message", string(in))
 				continue
 			}
-			delete(pending, response.Id)
-			if canceller, exists := timeoutCancellers[response.Id]; exists {
-				canceller <- struct{}{}
-				delete(timeoutCancellers, response.Id)
-			}
 			if err := json.Unmarshal(in, &cmd); err != nil {
-				glog.Errorln(err.Error())
-				cmd.Fail("error occured while unmarshalling")
+				r.metrics.CommandAnswered(commandName(cmd), rtt, true)
+				cmd.Fail(fmt.Sprintf("ws: can't read response: %s", err))
 				continue
 			}
+			r.metrics.CommandAnswered(commandName(cmd), rtt, commandFailed(cmd))
 			cmd.Done()
 
-		case id := <-timeout:
+		case cancelled := <-r.cancelled:
+			// The caller has given up waiting for this command
+			id := cancelled.ID()
 			if cmd, exists := pending[id]; exists {
-				// this command has timed out
-				delete(pending, id)
-				cmd.Fail("command timed out")
-				return
+				r.metrics.CommandFailed(commandName(cmd))
+			}
+			delete(pending, id)
+			delete(sent, id)
+			if canceller, exists := timeoutCancellers[id]; exists {
+				close(canceller)
+				delete(timeoutCancellers, id)
 			}
 
-			delete(timeoutCancellers, id)
+		case expired := <-timeout:
+			// The connection is left alone, a dead server is
+			// detected by the readPump's pong deadline instead.
+			if cmd, exists := pending[expired.Id]; exists {
+				delete(pending, expired.Id)
+				delete(sent, expired.Id)
+				r.metrics.CommandTimedOut(commandName(cmd))
+				if e, ok := cmd.(expirer); ok {
+					e.expire(expired)
+				} else {
+					cmd.Fail(expired.Error())
+				}
+			}
+			delete(timeoutCancellers, expired.Id)
 		}
 	}
 }
 
-// Synchronously get a single transaction
-func (r *Remote) Tx(hash data.Hash256) (*TxResult, error) {
-	cmd := &TxCommand{
-		Command:     newCommand("tx"),
-		Transaction: hash,
+// publish passes a stream message to the Incoming channel, applying the
+// overflow policy when it is full. Returns false if the Remote must
+// disconnect.
+func (r *Remote) publish(msg interface{}) bool {
+	select {
+	case r.Incoming <- msg:
+		return true
+	default:
 	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
+	switch r.options.Overflow {
+	case OverflowDropNewest:
+		atomic.AddUint64(&r.dropped, 1)
+		return true
+	case OverflowDropOldest:
+		r.evict(msg)
+		return true
+	case OverflowDisconnect:
+		atomic.AddUint64(&r.dropped, 1)
+		r.evict(&OverflowError{Capacity: cap(r.Incoming)})
+		r.overflowed = true
+		return false
+	default:
+		r.Incoming <- msg
+		return true
 	}
-	return cmd.Result, nil
 }
 
-func (r *Remote) accountTx(account data.Account, c chan *data.TransactionWithMetaData, pageSize int, minLedger, maxLedger int64) {
-	defer close(c)
-	cmd := newAccountTxCommand(account, pageSize, nil, minLedger, maxLedger)
-	for ; ; cmd = newAccountTxCommand(account, pageSize, cmd.Result.Marker, minLedger, maxLedger) {
-		r.outgoing <- cmd
-		<-cmd.Ready
-		if cmd.CommandError != nil {
-			glog.Errorln(cmd.Error())
+// evict discards unread messages until msg fits. Only the run loop writes
+// to Incoming, so the consumer can only make more room meanwhile.
+func (r *Remote) evict(msg interface{}) {
+	for {
+		select {
+		case r.Incoming <- msg:
 			return
+		default:
 		}
-		for _, tx := range cmd.Result.Transactions {
-			c <- tx
-		}
-		if cmd.Result.Marker == nil {
-			return
+		select {
+		case <-r.Incoming:
+			atomic.AddUint64(&r.dropped, 1)
+		default:
 		}
 	}
 }
 
-// Retrieve all transactions for an account via
-// https://ripple.com/build/rippled-apis/#account-tx. Will call
-// `account_tx` multiple times, if a marker is returned.  Transactions
-// are returned asynchonously to the channel returned by this
-// function.
-//
-// Use minLedger -1 for the earliest ledger available.
-// Use maxLedger -1 for the most recent validated ledger.
-func (r *Remote) AccountTx(account data.Account, pageSize int, minLedger, maxLedger int64) chan *data.TransactionWithMetaData {
-	c := make(chan *data.TransactionWithMetaData)
-	go r.accountTx(account, c, pageSize, minLedger, maxLedger)
-	return c
+// DroppedMessages returns how many stream messages have been discarded
+// because the Incoming channel was full.
+func (r *Remote) DroppedMessages() uint64 {
+	return atomic.LoadUint64(&r.dropped)
 }
 
-// Synchronously submit a single transaction
-func (r *Remote) Submit(tx data.Transaction) (*SubmitResult, error) {
-	_, raw, err := data.Raw(tx)
-	if err != nil {
-		return nil, err
-	}
-	cmd := &SubmitCommand{
-		Command: newCommand("submit"),
-		TxBlob:  fmt.Sprintf("%X", raw),
+// send queues a command and blocks until its response arrives or ctx is
+// done. If ctx expires first the command is withdrawn from the pending set
+// and ctx.Err() is returned.
+func (r *Remote) send(ctx context.Context, s Syncer, cmd *Command) (err error) {
+	if r.options.Tracer != nil {
+		var end func(error)
+		ctx, end = r.options.Tracer.StartCommand(ctx, NewCommandInfo(s))
+		defer func() { end(err) }()
 	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
+	if err := r.queue(ctx, s); err != nil {
+		return err
 	}
-	return cmd.Result, nil
+	return r.wait(ctx, s, cmd)
 }
 
-// Synchronously submit multiple transactions
-func (r *Remote) SubmitBatch(txs []data.Transaction) ([]*SubmitResult, error) {
-	commands := make([]*SubmitCommand, len(txs))
-	results := make([]*SubmitResult, len(txs))
-	for i := range txs {
-		_, raw, err := data.Raw(txs[i])
-		if err != nil {
-			return nil, err
+// queue passes a command to the run loop once the rate limits allow,
+// unless ctx is done first or the Remote is closing.
+func (r *Remote) queue(ctx context.Context, s Syncer) error {
+	select {
+	case <-r.closing:
+		return ErrClosed
+	default:
+	}
+	if err := r.limits.wait(ctx, commandName(s)); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-r.closing:
+			return ErrClosed
+		case <-r.done:
+			return ErrClosed
+		default:
 		}
-		cmd := &SubmitCommand{
-			Command: newCommand("submit"),
-			TxBlob:  fmt.Sprintf("%X", raw),
+		ok, popped := r.outgoing.push(s)
+		if ok {
+			return nil
+		}
+		select {
+		case <-popped:
+		case <-r.closing:
+			return ErrClosed
+		case <-r.done:
+			return ErrClosed
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-		r.outgoing <- cmd
-		commands[i] = cmd
-	}
-	for i := range commands {
-		<-commands[i].Ready
-		results[i] = commands[i].Result
 	}
-	return results, nil
 }
 
-// Synchronously gets ledger entries
-func (r *Remote) LedgerData(ledger interface{}, marker *data.Hash256) (*LedgerDataResult, error) {
-	cmd := &LedgerDataCommand{
-		Command: newCommand("ledger_data"),
-		Ledger:  ledger,
-		Marker:  marker,
-	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
+// wait blocks until a queued command, s, is done and returns its error.
+// If ctx is done first, the command is cancelled.
+func (r *Remote) wait(ctx context.Context, s Syncer, cmd *Command) error {
+	select {
+	case <-cmd.Ready:
+		err := cmd.err()
+		r.limits.observe(cmd.Name, err)
+		return err
+	case <-ctx.Done():
+		r.cancel(s)
+		return ctx.Err()
+	case <-r.done:
+		// The command may have failed as the Remote closed, or never
+		// left the queue
+		select {
+		case <-cmd.Ready:
+			return cmd.err()
+		default:
+			return ErrClosed
+		}
 	}
-	return cmd.Result, nil
 }
 
-func (r *Remote) streamLedgerData(ledger interface{}, c chan data.LedgerEntrySlice) {
-	defer close(c)
-	cmd := newBinaryLedgerDataCommand(ledger, nil)
-	for ; ; cmd = newBinaryLedgerDataCommand(ledger, cmd.Result.Marker) {
-		r.outgoing <- cmd
-		<-cmd.Ready
-		if cmd.CommandError != nil {
-			glog.Errorln(cmd.Error())
-			return
-		}
-		les := make(data.LedgerEntrySlice, len(cmd.Result.State))
-		for i, state := range cmd.Result.State {
-			b, err := hex.DecodeString(state.Data + state.Index)
-			if err != nil {
-				glog.Errorln(cmd.Error())
-				return
-			}
-			les[i], err = data.ReadLedgerEntry(bytes.NewReader(b), data.Hash256{})
-			if err != nil {
-				glog.Errorln(err.Error())
-				glog.Errorln(state.Data)
-				glog.Errorln(state.Index)
-				continue
-			}
-		}
-		c <- les
-		if cmd.Result.Marker == nil {
-			return
-		}
+// cancel takes a command from the queue if it hasn't been sent, or else
+// asks the run loop to forget about it. The run loop pops and sends a
+// command in one go, so it has its ID by the time the run loop reads it.
+func (r *Remote) cancel(s Syncer) {
+	if r.outgoing.remove(s) {
+		return
+	}
+	select {
+	case r.cancelled <- s:
+	case <-r.done:
 	}
 }
 
-// Asynchronously retrieve all data for a ledger using the binary form
-func (r *Remote) StreamLedgerData(ledger interface{}) chan data.LedgerEntrySlice {
-	c := make(chan data.LedgerEntrySlice)
-	go r.streamLedgerData(ledger, c)
-	return c
+// Synchronously get a single transaction
+func (r *Remote) Tx(hash data.Hash256) (*TxResult, error) {
+	return r.TxCtx(context.Background(), hash)
 }
 
-// Synchronously gets a single ledger
-func (r *Remote) Ledger(ledger interface{}, transactions bool) (*LedgerResult, error) {
-	cmd := &LedgerCommand{
-		Command:      newCommand("ledger"),
-		Ledger:       ledger,
-		Transactions: transactions,
-		Expand:       true,
+// TxCtx is like Tx but gives up when ctx is done
+func (r *Remote) TxCtx(ctx context.Context, hash data.Hash256) (*TxResult, error) {
+	cmd := &TxCommand{
+		Command:     newCommand("tx"),
+		Transaction: hash,
 	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
 	}
-	cmd.Result.Ledger.Transactions.Sort()
 	return cmd.Result, nil
 }
 
-func (r *Remote) LedgerHeader(ledger interface{}) (*LedgerHeaderResult, error) {
-	cmd := &LedgerHeaderCommand{
-		Command: newCommand("ledger_header"),
-		Ledger:  ledger,
-	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
-	}
-	return cmd.Result, nil
+// Synchronously get a page of the most recent transactions, newest first,
+// skipping start of them. Removed from rippled 2.0 onwards.
+func (r *Remote) TxHistory(start uint32) (*TxHistoryResult, error) {
+	return r.TxHistoryCtx(context.Background(), start)
 }
 
-// Synchronously requests paths
-func (r *Remote) RipplePathFind(src, dest data.Account, amount data.Amount, srcCurr *[]data.Currency) (*RipplePathFindResult, error) {
-	cmd := &RipplePathFindCommand{
-		Command:       newCommand("ripple_path_find"),
-		SrcAccount:    src,
-		SrcCurrencies: srcCurr,
-		DestAccount:   dest,
-		DestAmount:    amount,
+// TxHistoryCtx is like TxHistory but gives up when ctx is done
+func (r *Remote) TxHistoryCtx(ctx context.Context, start uint32) (*TxHistoryResult, error) {
+	cmd := &TxHistoryCommand{
+		Command: newCommand("tx_history"),
+		Start:   start,
 	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
 	}
 	return cmd.Result, nil
 }
 
-// Synchronously requests account info
-func (r *Remote) AccountInfo(a data.Account) (*AccountInfoResult, error) {
-	cmd := &AccountInfoCommand{
-		Command: newCommand("account_info"),
-		Account: a,
-	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
-	}
-	return cmd.Result, nil
+// Synchronously get a single transaction from a particular ledger
+func (r *Remote) TransactionEntry(hash data.Hash256, ledger interface{}) (*data.TransactionWithMetaData, error) {
+	return r.TransactionEntryCtx(context.Background(), hash, ledger)
+}
+
+// TransactionEntryCtx is like TransactionEntry but gives up when ctx is done
+func (r *Remote) TransactionEntryCtx(ctx context.Context, hash data.Hash256, ledger interface{}) (*data.TransactionWithMetaData, error) {
+	cmd := &TransactionEntryCommand{
+		Command:     newCommand("transaction_entry"),
+		Transaction: hash,
+		LedgerIndex: ledger,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return &cmd.Result.TransactionWithMetaData, nil
+}
+
+// AccountTxOptions selects the transactions of an AccountTxCtx
+type AccountTxOptions struct {
+	// Transactions in each page. Zero lets the server choose.
+	PageSize int
+
+	// Use -1 for the earliest ledger available, and for the most recent
+	// validated ledger
+	MinLedger int64
+	MaxLedger int64
+
+	// The Marker of a page returned earlier, to resume after it
+	Marker map[string]interface{}
+
+	// Oldest transactions first, rather than newest
+	Forward bool
+
+	// Fetch the transactions in binary form and decode them locally,
+	// which is faster and keeps amounts exact. The Date is not filled in.
+	Binary bool
+
+	// Leave out payments with the PartialPayment flag, which may deliver
+	// much less than their Amount. Those kept must be credited with what
+	// their DeliveredAmount method returns.
+	SkipPartialPayments bool
+}
+
+// Filter removes the transactions which options leave out from page
+func (options AccountTxOptions) Filter(page *AccountTxResult) {
+	if !options.SkipPartialPayments {
+		return
+	}
+	kept := page.Transactions[:0]
+	for _, tx := range page.Transactions {
+		if !tx.IsPartialPayment() {
+			kept = append(kept, tx)
+		}
+	}
+	page.Transactions = kept
+}
+
+// Retrieve all transactions for an account via
+// https://ripple.com/build/rippled-apis/#account-tx. Will call
+// `account_tx` multiple times, if a marker is returned.  Transactions
+// are returned asynchonously to the channel returned by this
+// function. Errors are only logged, see AccountTxCtx.
+//
+// Use minLedger -1 for the earliest ledger available.
+// Use maxLedger -1 for the most recent validated ledger.
+func (r *Remote) AccountTx(account data.Account, pageSize int, minLedger, maxLedger int64) chan *data.TransactionWithMetaData {
+	c := make(chan *data.TransactionWithMetaData)
+	txs, errs := r.AccountTxCtx(context.Background(), account, AccountTxOptions{
+		PageSize:  pageSize,
+		MinLedger: minLedger,
+		MaxLedger: maxLedger,
+	})
+	go func() {
+		defer close(c)
+		for tx := range txs {
+			c <- tx
+		}
+		if err := <-errs; err != nil {
+			r.log.Error("account_tx failed", "account", account, "error", err)
+		}
+	}()
+	return c
+}
+
+// AccountTxCtx is like AccountTx but gives up when ctx is done. The error
+// which ends the transactions early, if any, is sent on the second
+// channel, which is closed after the first.
+func (r *Remote) AccountTxCtx(ctx context.Context, account data.Account, options AccountTxOptions) (<-chan *data.TransactionWithMetaData, <-chan error) {
+	pages, pageErrs := r.AccountTxPagesCtx(ctx, account, options)
+	return pageTransactions(ctx, pages, pageErrs)
+}
+
+// pageTransactions sends the transactions of each page in turn, and then
+// the error which ended the pages early, if any
+func pageTransactions(ctx context.Context, pages <-chan *AccountTxResult, pageErrs <-chan error) (<-chan *data.TransactionWithMetaData, <-chan error) {
+	c, errs := make(chan *data.TransactionWithMetaData), make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(c)
+		for page := range pages {
+			for _, tx := range page.Transactions {
+				select {
+				case c <- tx:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+		}
+		if err := <-pageErrs; err != nil {
+			errs <- err
+		}
+	}()
+	return c, errs
+}
+
+// AccountTxPagesCtx is like AccountTxCtx but delivers whole pages, each
+// with the Marker to resume after it, which is nil for the last page
+func (r *Remote) AccountTxPagesCtx(ctx context.Context, account data.Account, options AccountTxOptions) (<-chan *AccountTxResult, <-chan error) {
+	c, errs := make(chan *AccountTxResult), make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(c)
+		marker := options.Marker
+		for {
+			cmd := newAccountTxCommand(account, options, marker)
+			if err := r.send(ctx, cmd, cmd.Command); err != nil {
+				errs <- err
+				return
+			}
+			options.Filter(cmd.Result)
+			select {
+			case c <- cmd.Result:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+			if cmd.Result.Marker == nil {
+				return
+			}
+			marker = cmd.Result.Marker
+		}
+	}()
+	return c, errs
+}
+
+func newSubmitCommand(tx data.Transaction) (*SubmitCommand, error) {
+	_, raw, err := data.Raw(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &SubmitCommand{
+		Command: newCommand("submit"),
+		TxBlob:  fmt.Sprintf("%X", raw),
+	}, nil
+}
+
+// Synchronously submit a single transaction
+func (r *Remote) Submit(tx data.Transaction) (*SubmitResult, error) {
+	return r.SubmitCtx(context.Background(), tx)
+}
+
+// SubmitCtx is like Submit but gives up when ctx is done
+func (r *Remote) SubmitCtx(ctx context.Context, tx data.Transaction) (*SubmitResult, error) {
+	cmd, err := newSubmitCommand(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// newSubmitMultisignedCommand checks the Signers of tx against the signer
+// list found in objects, which are owned by the transaction's account.
+func newSubmitMultisignedCommand(tx data.Transaction, objects data.LedgerEntrySlice) (*SubmitMultisignedCommand, error) {
+	var list *data.SignerList
+	for _, object := range objects {
+		if l, ok := object.(*data.SignerList); ok {
+			list = l
+		}
+	}
+	if list == nil {
+		return nil, fmt.Errorf("Account %s has no signer list", tx.GetBase().Account)
+	}
+	if err := list.CheckSigners(tx); err != nil {
+		return nil, err
+	}
+	fields, err := txJSON(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &SubmitMultisignedCommand{
+		Command: newCommand("submit_multisigned"),
+		TxJson:  fields,
+	}, nil
+}
+
+// Synchronously submit a transaction signed by several accounts, after
+// checking its Signers against the validated signer list of its account
+func (r *Remote) SubmitMultisigned(tx data.Transaction) (*SubmitResult, error) {
+	return r.SubmitMultisignedCtx(context.Background(), tx)
+}
+
+// SubmitMultisignedCtx is like SubmitMultisigned but gives up when ctx is done
+func (r *Remote) SubmitMultisignedCtx(ctx context.Context, tx data.Transaction) (*SubmitResult, error) {
+	if tx.GetBase() == nil {
+		return nil, fmt.Errorf("%s cannot be multisigned", tx.GetType())
+	}
+	objects, err := r.AccountObjectsCtx(ctx, tx.GetBase().Account, "signer_list", "validated")
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := newSubmitMultisignedCommand(tx, objects.AccountObjects)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously submit multiple transactions
+func (r *Remote) SubmitBatch(txs []data.Transaction) ([]*SubmitResult, error) {
+	return r.SubmitBatchCtx(context.Background(), txs)
+}
+
+// SubmitBatchCtx is like SubmitBatch but gives up when ctx is done.
+// Commands which have not yet been answered are cancelled.
+func (r *Remote) SubmitBatchCtx(ctx context.Context, txs []data.Transaction) ([]*SubmitResult, error) {
+	commands := make([]*SubmitCommand, len(txs))
+	results := make([]*SubmitResult, len(txs))
+	for i := range txs {
+		cmd, err := newSubmitCommand(txs[i])
+		if err != nil {
+			return nil, err
+		}
+		commands[i] = cmd
+	}
+	for i := range commands {
+		if err := r.queue(ctx, commands[i]); err != nil {
+			r.cancelAll(commands[:i])
+			return nil, err
+		}
+	}
+	for i := range commands {
+		switch err := r.wait(ctx, commands[i], commands[i].Command); {
+		case err == ErrClosed:
+			return nil, err
+		case ctx.Err() != nil:
+			r.cancelAll(commands[i:])
+			return nil, ctx.Err()
+		}
+		results[i] = commands[i].Result
+	}
+	return results, nil
+}
+
+func (r *Remote) cancelAll(commands []*SubmitCommand) {
+	for _, cmd := range commands {
+		r.cancel(cmd)
+	}
+}
+
+// Synchronously gets ledger entries
+func (r *Remote) LedgerData(ledger interface{}, marker *data.Hash256) (*LedgerDataResult, error) {
+	return r.LedgerDataCtx(context.Background(), ledger, marker)
+}
+
+// LedgerDataCtx is like LedgerData but gives up when ctx is done
+func (r *Remote) LedgerDataCtx(ctx context.Context, ledger interface{}, marker *data.Hash256) (*LedgerDataResult, error) {
+	cmd := &LedgerDataCommand{
+		Command: newCommand("ledger_data"),
+		Ledger:  ledger,
+		Marker:  marker,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+func (r *Remote) streamLedgerData(ledger interface{}, c chan data.LedgerEntrySlice) {
+	defer close(c)
+	var decoder data.LedgerEntryHexDecoder
+	cmd := newBinaryLedgerDataCommand(ledger, nil)
+	for ; ; cmd = newBinaryLedgerDataCommand(ledger, cmd.Result.Marker) {
+		if err := r.send(context.Background(), cmd, cmd.Command); err != nil {
+			r.log.Error("ledger_data failed", "ledger", ledger, "error", err)
+			return
+		}
+		les := make(data.LedgerEntrySlice, len(cmd.Result.State))
+		for i, state := range cmd.Result.State {
+			var err error
+			les[i], err = decoder.Decode(state.Data, state.Index)
+			if err != nil {
+				r.log.Error("Unreadable ledger entry", "index", state.Index, "data", state.Data, "error", err)
+				continue
+			}
+		}
+		c <- les
+		if cmd.Result.Marker == nil {
+			return
+		}
+	}
+}
+
+// Asynchronously retrieve all data for a ledger using the binary form
+func (r *Remote) StreamLedgerData(ledger interface{}) chan data.LedgerEntrySlice {
+	c := make(chan data.LedgerEntrySlice)
+	go r.streamLedgerData(ledger, c)
+	return c
+}
+
+// BinaryLedgerDataCtx gets a page of ledger entries in binary form, of at
+// most limit entries unless limit is zero
+func (r *Remote) BinaryLedgerDataCtx(ctx context.Context, ledger interface{}, marker *data.Hash256, limit int) (*BinaryLedgerDataResult, error) {
+	cmd := newBinaryLedgerDataCommand(ledger, marker)
+	cmd.Limit = limit
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// StreamLedgerDataCtx retrieves all data for a ledger using the binary
+// form, fetching parts of the key space concurrently
+func (r *Remote) StreamLedgerDataCtx(ctx context.Context, ledger interface{}, options LedgerDataOptions) *LedgerDataIterator {
+	if options.Logger == nil {
+		options.Logger = r.log
+	}
+	return StreamLedgerDataParts(ctx, r.BinaryLedgerDataCtx, ledger, options)
+}
+
+// Synchronously gets a single ledger
+func (r *Remote) Ledger(ledger interface{}, transactions bool) (*LedgerResult, error) {
+	return r.LedgerCtx(context.Background(), ledger, transactions)
+}
+
+// LedgerCtx is like Ledger but gives up when ctx is done
+func (r *Remote) LedgerCtx(ctx context.Context, ledger interface{}, transactions bool) (*LedgerResult, error) {
+	cmd := &LedgerCommand{
+		Command:      newCommand("ledger"),
+		Ledger:       ledger,
+		Transactions: transactions,
+		Expand:       true,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	cmd.Result.Ledger.Transactions.Sort()
+	return cmd.Result, nil
+}
+
+func (r *Remote) LedgerHeader(ledger interface{}) (*LedgerHeaderResult, error) {
+	return r.LedgerHeaderCtx(context.Background(), ledger)
+}
+
+// LedgerHeaderCtx is like LedgerHeader but gives up when ctx is done
+func (r *Remote) LedgerHeaderCtx(ctx context.Context, ledger interface{}) (*LedgerHeaderResult, error) {
+	cmd := &LedgerHeaderCommand{
+		Command: newCommand("ledger_header"),
+		Ledger:  ledger,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously gets a single ledger entry, decoded from its binary form
+func (r *Remote) LedgerEntry(selector LedgerEntrySelector, ledger interface{}) (data.LedgerEntry, error) {
+	return r.LedgerEntryCtx(context.Background(), selector, ledger)
+}
+
+// LedgerEntryCtx is like LedgerEntry but gives up when ctx is done
+func (r *Remote) LedgerEntryCtx(ctx context.Context, selector LedgerEntrySelector, ledger interface{}) (data.LedgerEntry, error) {
+	cmd := &LedgerEntryCommand{
+		Command:             newCommand("ledger_entry"),
+		LedgerEntrySelector: selector,
+		LedgerIndex:         ledger,
+		Binary:              true,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result.LedgerEntry()
+}
+
+// Synchronously requests the sequence and hash of the most recently
+// closed ledger, which may not yet be validated
+func (r *Remote) LedgerClosed() (*LedgerClosedResult, error) {
+	return r.LedgerClosedCtx(context.Background())
+}
+
+// LedgerClosedCtx is like LedgerClosed but gives up when ctx is done
+func (r *Remote) LedgerClosedCtx(ctx context.Context) (*LedgerClosedResult, error) {
+	cmd := &LedgerClosedCommand{
+		Command: newCommand("ledger_closed"),
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the sequence of the open ledger
+func (r *Remote) LedgerCurrent() (uint32, error) {
+	return r.LedgerCurrentCtx(context.Background())
+}
+
+// LedgerCurrentCtx is like LedgerCurrent but gives up when ctx is done
+func (r *Remote) LedgerCurrentCtx(ctx context.Context) (uint32, error) {
+	cmd := &LedgerCurrentCommand{
+		Command: newCommand("ledger_current"),
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return 0, err
+	}
+	return cmd.Result.LedgerSequence, nil
+}
+
+// Synchronously requests paths
+func (r *Remote) RipplePathFind(src, dest data.Account, amount data.Amount, srcCurr *[]data.Currency) (*RipplePathFindResult, error) {
+	return r.RipplePathFindCtx(context.Background(), src, dest, amount, srcCurr)
+}
+
+// RipplePathFindCtx is like RipplePathFind but gives up when ctx is done
+func (r *Remote) RipplePathFindCtx(ctx context.Context, src, dest data.Account, amount data.Amount, srcCurr *[]data.Currency) (*RipplePathFindResult, error) {
+	cmd := &RipplePathFindCommand{
+		Command:       newCommand("ripple_path_find"),
+		SrcAccount:    src,
+		SrcCurrencies: srcCurr,
+		DestAccount:   dest,
+		DestAmount:    amount,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests account info
+func (r *Remote) AccountInfo(a data.Account) (*AccountInfoResult, error) {
+	return r.AccountInfoCtx(context.Background(), a)
+}
+
+// AccountInfoCtx is like AccountInfo but gives up when ctx is done
+func (r *Remote) AccountInfoCtx(ctx context.Context, a data.Account) (*AccountInfoResult, error) {
+	cmd := &AccountInfoCommand{
+		Command: newCommand("account_info"),
+		Account: a,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
 }
 
 // Synchronously requests account line info
 func (r *Remote) AccountLines(account data.Account, ledgerIndex interface{}) (*AccountLinesResult, error) {
+	return r.AccountLinesCtx(context.Background(), account, ledgerIndex)
+}
+
+// AccountLinesCtx is like AccountLines but gives up when ctx is done
+func (r *Remote) AccountLinesCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*AccountLinesResult, error) {
+	var (
+		lines  data.AccountLineSlice
+		marker *data.Hash256
+	)
+	for {
+		cmd := &AccountLinesCommand{
+			Command:     newCommand("account_lines"),
+			Account:     account,
+			Limit:       400,
+			Marker:      marker,
+			LedgerIndex: ledgerIndex,
+		}
+		if err := r.send(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		if cmd.Result.Marker == nil {
+			cmd.Result.Lines = append(lines, cmd.Result.Lines...)
+			cmd.Result.Lines.SortByCurrencyAmount()
+			return cmd.Result, nil
+		}
+		lines = append(lines, cmd.Result.Lines...)
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests account offers
+func (r *Remote) AccountOffers(account data.Account, ledgerIndex interface{}) (*AccountOffersResult, error) {
+	return r.AccountOffersCtx(context.Background(), account, ledgerIndex)
+}
+
+// AccountOffersCtx is like AccountOffers but gives up when ctx is done
+func (r *Remote) AccountOffersCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*AccountOffersResult, error) {
+	var (
+		offers data.AccountOfferSlice
+		marker *data.Hash256
+	)
+	for {
+		cmd := &AccountOffersCommand{
+			Command:     newCommand("account_offers"),
+			Account:     account,
+			Limit:       400,
+			Marker:      marker,
+			LedgerIndex: ledgerIndex,
+		}
+		if err := r.send(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		if cmd.Result.Marker == nil {
+			cmd.Result.Offers = append(offers, cmd.Result.Offers...)
+			sort.Sort(cmd.Result.Offers)
+			return cmd.Result, nil
+		}
+		offers = append(offers, cmd.Result.Offers...)
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests the ledger entries owned by an account,
+// optionally only those of one type such as "offer", "state", "escrow",
+// "payment_channel", "check", "ticket", "deposit_preauth" or "nft_page".
+func (r *Remote) AccountObjects(account data.Account, objectType string, ledgerIndex interface{}) (*AccountObjectsResult, error) {
+	return r.AccountObjectsCtx(context.Background(), account, objectType, ledgerIndex)
+}
+
+// AccountObjectsCtx is like AccountObjects but gives up when ctx is done
+func (r *Remote) AccountObjectsCtx(ctx context.Context, account data.Account, objectType string, ledgerIndex interface{}) (*AccountObjectsResult, error) {
 	var (
-		lines  data.AccountLineSlice
-		marker *data.Hash256
+		objects data.LedgerEntrySlice
+		marker  interface{}
 	)
 	for {
-		cmd := &AccountLinesCommand{
-			Command:     newCommand("account_lines"),
+		cmd := &AccountObjectsCommand{
+			Command:     newCommand("account_objects"),
 			Account:     account,
+			Type:        objectType,
 			Limit:       400,
 			Marker:      marker,
 			LedgerIndex: ledgerIndex,
 		}
-		r.outgoing <- cmd
-		<-cmd.Ready
-		switch {
-		case cmd.CommandError != nil:
-			return nil, cmd.CommandError
-		case cmd.Result.Marker != nil:
-			lines = append(lines, cmd.Result.Lines...)
-			marker = cmd.Result.Marker
-			if cmd.Result.LedgerSequence != nil {
-				ledgerIndex = *cmd.Result.LedgerSequence
-			}
-		default:
-			cmd.Result.Lines = append(lines, cmd.Result.Lines...)
-			cmd.Result.Lines.SortByCurrencyAmount()
+		if err := r.send(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		objects = append(objects, cmd.Result.AccountObjects...)
+		if cmd.Result.Marker == nil {
+			cmd.Result.AccountObjects = objects
 			return cmd.Result, nil
 		}
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
 	}
 }
 
-// Synchronously requests account offers
-func (r *Remote) AccountOffers(account data.Account, ledgerIndex interface{}) (*AccountOffersResult, error) {
+// Synchronously requests the checks sent or received by an account
+func (r *Remote) AccountChecks(account data.Account, ledgerIndex interface{}) ([]*data.Check, error) {
+	return r.AccountChecksCtx(context.Background(), account, ledgerIndex)
+}
+
+// AccountChecksCtx is like AccountChecks but gives up when ctx is done
+func (r *Remote) AccountChecksCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) ([]*data.Check, error) {
+	result, err := r.AccountObjectsCtx(ctx, account, "check", ledgerIndex)
+	if err != nil {
+		return nil, err
+	}
+	return result.Checks(), nil
+}
+
+// Synchronously requests the tickets an account has yet to use
+func (r *Remote) AccountTickets(account data.Account, ledgerIndex interface{}) ([]*data.Ticket, error) {
+	return r.AccountTicketsCtx(context.Background(), account, ledgerIndex)
+}
+
+// AccountTicketsCtx is like AccountTickets but gives up when ctx is done
+func (r *Remote) AccountTicketsCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) ([]*data.Ticket, error) {
+	result, err := r.AccountObjectsCtx(ctx, account, "ticket", ledgerIndex)
+	if err != nil {
+		return nil, err
+	}
+	return result.Tickets(), nil
+}
+
+// Synchronously requests the accounts preauthorised to deposit to an
+// account
+func (r *Remote) AccountDepositPreauths(account data.Account, ledgerIndex interface{}) ([]*data.DepositPreAuth, error) {
+	return r.AccountDepositPreauthsCtx(context.Background(), account, ledgerIndex)
+}
+
+// AccountDepositPreauthsCtx is like AccountDepositPreauths but gives up
+// when ctx is done
+func (r *Remote) AccountDepositPreauthsCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) ([]*data.DepositPreAuth, error) {
+	result, err := r.AccountObjectsCtx(ctx, account, "deposit_preauth", ledgerIndex)
+	if err != nil {
+		return nil, err
+	}
+	return result.DepositPreauths(), nil
+}
+
+// Synchronously requests the payment channels opened by an account,
+// optionally only those to destination
+func (r *Remote) AccountChannels(account data.Account, destination *data.Account, ledgerIndex interface{}) (*AccountChannelsResult, error) {
+	return r.AccountChannelsCtx(context.Background(), account, destination, ledgerIndex)
+}
+
+// AccountChannelsCtx is like AccountChannels but gives up when ctx is done
+func (r *Remote) AccountChannelsCtx(ctx context.Context, account data.Account, destination *data.Account, ledgerIndex interface{}) (*AccountChannelsResult, error) {
 	var (
-		offers data.AccountOfferSlice
-		marker *data.Hash256
+		channels []PaymentChannel
+		marker   interface{}
 	)
 	for {
-		cmd := &AccountOffersCommand{
-			Command:     newCommand("account_offers"),
+		cmd := &AccountChannelsCommand{
+			Command:     newCommand("account_channels"),
 			Account:     account,
+			Destination: destination,
 			Limit:       400,
 			Marker:      marker,
 			LedgerIndex: ledgerIndex,
 		}
-		r.outgoing <- cmd
-		<-cmd.Ready
-		switch {
-		case cmd.CommandError != nil:
-			return nil, cmd.CommandError
-		case cmd.Result.Marker != nil:
-			offers = append(offers, cmd.Result.Offers...)
-			marker = cmd.Result.Marker
-			if cmd.Result.LedgerSequence != nil {
-				ledgerIndex = *cmd.Result.LedgerSequence
-			}
-		default:
-			cmd.Result.Offers = append(offers, cmd.Result.Offers...)
-			sort.Sort(cmd.Result.Offers)
+		if err := r.send(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		channels = append(channels, cmd.Result.Channels...)
+		if cmd.Result.Marker == nil {
+			cmd.Result.Channels = channels
+			return cmd.Result, nil
+		}
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests the currencies an account can send and receive
+func (r *Remote) AccountCurrencies(account data.Account, ledgerIndex interface{}) (*AccountCurrenciesResult, error) {
+	return r.AccountCurrenciesCtx(context.Background(), account, ledgerIndex)
+}
+
+// AccountCurrenciesCtx is like AccountCurrencies but gives up when ctx is done
+func (r *Remote) AccountCurrenciesCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*AccountCurrenciesResult, error) {
+	cmd := &AccountCurrenciesCommand{
+		Command:     newCommand("account_currencies"),
+		Account:     account,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests all the NFTs owned by an account
+func (r *Remote) AccountNFTs(account data.Account, ledgerIndex interface{}) (*AccountNFTsResult, error) {
+	return r.AccountNFTsCtx(context.Background(), account, ledgerIndex)
+}
+
+// AccountNFTsCtx is like AccountNFTs but gives up when ctx is done
+func (r *Remote) AccountNFTsCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*AccountNFTsResult, error) {
+	var (
+		nfts   []AccountNFT
+		marker interface{}
+	)
+	for {
+		cmd := &AccountNFTsCommand{
+			Command:     newCommand("account_nfts"),
+			Account:     account,
+			Limit:       400,
+			Marker:      marker,
+			LedgerIndex: ledgerIndex,
+		}
+		if err := r.send(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		nfts = append(nfts, cmd.Result.NFTs...)
+		if cmd.Result.Marker == nil {
+			cmd.Result.NFTs = nfts
+			return cmd.Result, nil
+		}
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests all the offers to buy an NFT
+func (r *Remote) NFTBuyOffers(nftID data.Hash256, ledgerIndex interface{}) (*NFTOffersResult, error) {
+	return r.nftOffers(context.Background(), "nft_buy_offers", nftID, ledgerIndex)
+}
+
+// NFTBuyOffersCtx is like NFTBuyOffers but gives up when ctx is done
+func (r *Remote) NFTBuyOffersCtx(ctx context.Context, nftID data.Hash256, ledgerIndex interface{}) (*NFTOffersResult, error) {
+	return r.nftOffers(ctx, "nft_buy_offers", nftID, ledgerIndex)
+}
+
+// Synchronously requests all the offers to sell an NFT
+func (r *Remote) NFTSellOffers(nftID data.Hash256, ledgerIndex interface{}) (*NFTOffersResult, error) {
+	return r.nftOffers(context.Background(), "nft_sell_offers", nftID, ledgerIndex)
+}
+
+// NFTSellOffersCtx is like NFTSellOffers but gives up when ctx is done
+func (r *Remote) NFTSellOffersCtx(ctx context.Context, nftID data.Hash256, ledgerIndex interface{}) (*NFTOffersResult, error) {
+	return r.nftOffers(ctx, "nft_sell_offers", nftID, ledgerIndex)
+}
+
+func (r *Remote) nftOffers(ctx context.Context, name string, nftID data.Hash256, ledgerIndex interface{}) (*NFTOffersResult, error) {
+	var (
+		offers []NFTOffer
+		marker interface{}
+	)
+	for {
+		cmd := &NFTOffersCommand{
+			Command:     newCommand(name),
+			NFTokenID:   nftID,
+			Limit:       400,
+			Marker:      marker,
+			LedgerIndex: ledgerIndex,
+		}
+		if err := r.send(ctx, cmd, cmd.Command); err != nil {
+			return nil, err
+		}
+		offers = append(offers, cmd.Result.Offers...)
+		if cmd.Result.Marker == nil {
+			cmd.Result.Offers = offers
 			return cmd.Result, nil
 		}
+		marker = cmd.Result.Marker
+		if cmd.Result.LedgerSequence != nil {
+			ledgerIndex = *cmd.Result.LedgerSequence
+		}
+	}
+}
+
+// Synchronously requests the current state of an NFT, including burned
+// ones. Only Clio servers support nft_info.
+func (r *Remote) NFTInfo(nftID data.Hash256, ledgerIndex interface{}) (*NFTInfoResult, error) {
+	return r.NFTInfoCtx(context.Background(), nftID, ledgerIndex)
+}
+
+// NFTInfoCtx is like NFTInfo but gives up when ctx is done
+func (r *Remote) NFTInfoCtx(ctx context.Context, nftID data.Hash256, ledgerIndex interface{}) (*NFTInfoResult, error) {
+	cmd := &NFTInfoCommand{
+		Command:     newCommand("nft_info"),
+		NFTokenID:   nftID,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously asks the server to sign a claim for amount, a native
+// value, from channel. The secret is sent to the server, so only use
+// this with a server you trust; sign.Claim works offline.
+func (r *Remote) ChannelAuthorize(channel data.Hash256, amount data.Value, secret string) (data.VariableLength, error) {
+	return r.ChannelAuthorizeCtx(context.Background(), channel, amount, secret)
+}
+
+// ChannelAuthorizeCtx is like ChannelAuthorize but gives up when ctx is done
+func (r *Remote) ChannelAuthorizeCtx(ctx context.Context, channel data.Hash256, amount data.Value, secret string) (data.VariableLength, error) {
+	cmd := &ChannelAuthorizeCommand{
+		Command:   newCommand("channel_authorize"),
+		ChannelId: channel,
+		Amount:    amount,
+		Secret:    secret,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result.Signature, nil
+}
+
+// Synchronously checks a claim's signature
+func (r *Remote) ChannelVerify(channel data.Hash256, amount data.Value, publicKey data.PublicKey, signature data.VariableLength) (bool, error) {
+	return r.ChannelVerifyCtx(context.Background(), channel, amount, publicKey, signature)
+}
+
+// ChannelVerifyCtx is like ChannelVerify but gives up when ctx is done
+func (r *Remote) ChannelVerifyCtx(ctx context.Context, channel data.Hash256, amount data.Value, publicKey data.PublicKey, signature data.VariableLength) (bool, error) {
+	cmd := &ChannelVerifyCommand{
+		Command:   newCommand("channel_verify"),
+		ChannelId: channel,
+		Amount:    amount,
+		PublicKey: publicKey,
+		Signature: signature,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return false, err
+	}
+	return cmd.Result.Verified, nil
+}
+
+// Synchronously checks whether source may pay destination directly,
+// which is always true unless destination requires DepositAuth
+func (r *Remote) DepositAuthorized(source, destination data.Account, ledgerIndex interface{}) (bool, error) {
+	return r.DepositAuthorizedCtx(context.Background(), source, destination, ledgerIndex)
+}
+
+// DepositAuthorizedCtx is like DepositAuthorized but gives up when ctx is done
+func (r *Remote) DepositAuthorizedCtx(ctx context.Context, source, destination data.Account, ledgerIndex interface{}) (bool, error) {
+	cmd := &DepositAuthorizedCommand{
+		Command:     newCommand("deposit_authorized"),
+		Source:      source,
+		Destination: destination,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return false, err
+	}
+	return cmd.Result.Authorized, nil
+}
+
+// Synchronously requests the totals issued by a gateway, less those
+// held by its hotWallets
+func (r *Remote) GatewayBalances(account data.Account, hotWallets []data.Account, ledgerIndex interface{}) (*GatewayBalancesResult, error) {
+	return r.GatewayBalancesCtx(context.Background(), account, hotWallets, ledgerIndex)
+}
+
+// GatewayBalancesCtx is like GatewayBalances but gives up when ctx is done
+func (r *Remote) GatewayBalancesCtx(ctx context.Context, account data.Account, hotWallets []data.Account, ledgerIndex interface{}) (*GatewayBalancesResult, error) {
+	cmd := &GatewayBalancesCommand{
+		Command:     newCommand("gateway_balances"),
+		Account:     account,
+		HotWallets:  hotWallets,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously checks an account's DefaultRipple and NoRipple flags
+// against those recommended for role, which is "gateway" or "user".
+// The result includes transactions which would fix any problems.
+func (r *Remote) NoRippleCheck(account data.Account, role string, ledgerIndex interface{}) (*NoRippleCheckResult, error) {
+	return r.NoRippleCheckCtx(context.Background(), account, role, ledgerIndex)
+}
+
+// NoRippleCheckCtx is like NoRippleCheck but gives up when ctx is done
+func (r *Remote) NoRippleCheckCtx(ctx context.Context, account data.Account, role string, ledgerIndex interface{}) (*NoRippleCheckResult, error) {
+	cmd := &NoRippleCheckCommand{
+		Command:      newCommand("noripple_check"),
+		Account:      account,
+		Role:         role,
+		Transactions: true,
+		LedgerIndex:  ledgerIndex,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
 	}
+	return cmd.Result, nil
 }
 
 func (r *Remote) BookOffers(taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*BookOffersResult, error) {
+	return r.BookOffersCtx(context.Background(), taker, ledgerIndex, pays, gets)
+}
+
+// BookOffersCtx is like BookOffers but gives up when ctx is done
+func (r *Remote) BookOffersCtx(ctx context.Context, taker data.Account, ledgerIndex interface{}, pays, gets data.Asset) (*BookOffersResult, error) {
 	cmd := &BookOffersCommand{
 		Command:     newCommand("book_offers"),
 		LedgerIndex: ledgerIndex,
@@ -533,10 +1690,94 @@ func (r *Remote) BookOffers(taker data.Account, ledgerIndex interface{}, pays, g
 		TakerGets:   gets,
 		Limit:       5000, // Marker not implemented....
 	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the state of the AMM for a pair of assets
+func (r *Remote) AMMInfo(asset, asset2 data.Asset, ledgerIndex interface{}) (*AMMInfoResult, error) {
+	return r.AMMInfoCtx(context.Background(), asset, asset2, ledgerIndex)
+}
+
+// AMMInfoCtx is like AMMInfo but gives up when ctx is done
+func (r *Remote) AMMInfoCtx(ctx context.Context, asset, asset2 data.Asset, ledgerIndex interface{}) (*AMMInfoResult, error) {
+	cmd := &AMMInfoCommand{
+		Command:     newCommand("amm_info"),
+		Asset:       &asset,
+		Asset2:      &asset2,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the state of the AMM with the given account
+func (r *Remote) AMMInfoByAccount(ammAccount data.Account, ledgerIndex interface{}) (*AMMInfoResult, error) {
+	return r.AMMInfoByAccountCtx(context.Background(), ammAccount, ledgerIndex)
+}
+
+// AMMInfoByAccountCtx is like AMMInfoByAccount but gives up when ctx is done
+func (r *Remote) AMMInfoByAccountCtx(ctx context.Context, ammAccount data.Account, ledgerIndex interface{}) (*AMMInfoResult, error) {
+	cmd := &AMMInfoCommand{
+		Command:     newCommand("amm_info"),
+		AMMAccount:  &ammAccount,
+		LedgerIndex: ledgerIndex,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// AggregatePriceOptions trims the outliers from the prices of
+// GetAggregatePrice, and ignores prices older than TimeThreshold seconds
+// before the latest one. Zero values leave them out.
+type AggregatePriceOptions struct {
+	Trim          uint32 // Percent, from 1 to 25
+	TimeThreshold uint32
+	LedgerIndex   interface{}
+}
+
+// Synchronously requests the aggregate price of base in quote from the
+// oracles
+func (r *Remote) GetAggregatePrice(base, quote data.Currency, oracles []OracleSelector, options AggregatePriceOptions) (*GetAggregatePriceResult, error) {
+	return r.GetAggregatePriceCtx(context.Background(), base, quote, oracles, options)
+}
+
+// GetAggregatePriceCtx is like GetAggregatePrice but gives up when ctx is done
+func (r *Remote) GetAggregatePriceCtx(ctx context.Context, base, quote data.Currency, oracles []OracleSelector, options AggregatePriceOptions) (*GetAggregatePriceResult, error) {
+	cmd := &GetAggregatePriceCommand{
+		Command:       newCommand("get_aggregate_price"),
+		BaseAsset:     base,
+		QuoteAsset:    quote,
+		Oracles:       oracles,
+		Trim:          options.Trim,
+		TimeThreshold: options.TimeThreshold,
+		LedgerIndex:   options.LedgerIndex,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the order book changes made by a ledger
+func (r *Remote) BookChanges(ledger interface{}) (*BookChangesResult, error) {
+	return r.BookChangesCtx(context.Background(), ledger)
+}
+
+// BookChangesCtx is like BookChanges but gives up when ctx is done
+func (r *Remote) BookChangesCtx(ctx context.Context, ledger interface{}) (*BookChangesResult, error) {
+	cmd := &BookChangesCommand{
+		Command:     newCommand("book_changes"),
+		LedgerIndex: ledger,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
 	}
 	return cmd.Result, nil
 }
@@ -544,6 +1785,11 @@ func (r *Remote) BookOffers(taker data.Account, ledgerIndex interface{}, pays, g
 // Synchronously subscribe to streams and receive a confirmation message
 // Streams are recived asynchronously over the Incoming channel
 func (r *Remote) Subscribe(ledger, transactions, transactionsProposed, server bool) (*SubscribeResult, error) {
+	return r.SubscribeCtx(context.Background(), ledger, transactions, transactionsProposed, server)
+}
+
+// SubscribeCtx is like Subscribe but gives up when ctx is done
+func (r *Remote) SubscribeCtx(ctx context.Context, ledger, transactions, transactionsProposed, server bool) (*SubscribeResult, error) {
 	streams := []string{}
 	if ledger {
 		streams = append(streams, "ledger")
@@ -561,10 +1807,8 @@ func (r *Remote) Subscribe(ledger, transactions, transactionsProposed, server bo
 		Command: newCommand("subscribe"),
 		Streams: streams,
 	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
 	}
 
 	if ledger && cmd.Result.LedgerStreamMsg == nil {
@@ -573,6 +1817,7 @@ func (r *Remote) Subscribe(ledger, transactions, transactionsProposed, server bo
 	if server && cmd.Result.ServerStreamMsg == nil {
 		return nil, fmt.Errorf("Missing server subscribe response")
 	}
+	r.subscriptions.add(cmd.Streams, nil)
 	return cmd.Result, nil
 }
 
@@ -584,52 +1829,200 @@ type OrderBookSubscription struct {
 }
 
 func (r *Remote) SubscribeOrderBooks(books []OrderBookSubscription) (*SubscribeResult, error) {
+	return r.SubscribeOrderBooksCtx(context.Background(), books)
+}
+
+// SubscribeOrderBooksCtx is like SubscribeOrderBooks but gives up when ctx is done
+func (r *Remote) SubscribeOrderBooksCtx(ctx context.Context, books []OrderBookSubscription) (*SubscribeResult, error) {
 	cmd := &SubscribeCommand{
 		Command: newCommand("subscribe"),
 		Streams: []string{"ledger", "server"},
 		Books:   books,
 	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
 	}
+	r.subscriptions.add(cmd.Streams, cmd.Books)
 	return cmd.Result, nil
 }
 
+// Synchronously subscribe to the validated transactions affecting accounts.
+// These arrive as TransactionStreamMsgs over the Incoming channel.
+func (r *Remote) SubscribeAccounts(accounts []data.Account) error {
+	return r.subscribeAccounts(context.Background(), accounts, false)
+}
+
+// SubscribeAccountsCtx is like SubscribeAccounts but gives up when ctx is done
+func (r *Remote) SubscribeAccountsCtx(ctx context.Context, accounts []data.Account) error {
+	return r.subscribeAccounts(ctx, accounts, false)
+}
+
+// Synchronously subscribe to the transactions affecting accounts as soon
+// as they are proposed, as well as when they are validated.
+func (r *Remote) SubscribeAccountsProposed(accounts []data.Account) error {
+	return r.subscribeAccounts(context.Background(), accounts, true)
+}
+
+// SubscribeAccountsProposedCtx is like SubscribeAccountsProposed but gives up when ctx is done
+func (r *Remote) SubscribeAccountsProposedCtx(ctx context.Context, accounts []data.Account) error {
+	return r.subscribeAccounts(ctx, accounts, true)
+}
+
+func (r *Remote) subscribeAccounts(ctx context.Context, accounts []data.Account, proposed bool) error {
+	cmd := &SubscribeCommand{
+		Command: newCommand("subscribe"),
+		Streams: []string{},
+	}
+	if proposed {
+		cmd.AccountsProposed = accounts
+	} else {
+		cmd.Accounts = accounts
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return err
+	}
+	r.subscriptions.addAccounts(accounts, proposed)
+	return nil
+}
+
+// Synchronously stop the validated transactions affecting accounts
+func (r *Remote) UnsubscribeAccounts(accounts []data.Account) error {
+	return r.unsubscribeAccounts(context.Background(), accounts, false)
+}
+
+// UnsubscribeAccountsCtx is like UnsubscribeAccounts but gives up when ctx is done
+func (r *Remote) UnsubscribeAccountsCtx(ctx context.Context, accounts []data.Account) error {
+	return r.unsubscribeAccounts(ctx, accounts, false)
+}
+
+// Synchronously stop the proposed transactions affecting accounts
+func (r *Remote) UnsubscribeAccountsProposed(accounts []data.Account) error {
+	return r.unsubscribeAccounts(context.Background(), accounts, true)
+}
+
+// UnsubscribeAccountsProposedCtx is like UnsubscribeAccountsProposed but gives up when ctx is done
+func (r *Remote) UnsubscribeAccountsProposedCtx(ctx context.Context, accounts []data.Account) error {
+	return r.unsubscribeAccounts(ctx, accounts, true)
+}
+
+func (r *Remote) unsubscribeAccounts(ctx context.Context, accounts []data.Account, proposed bool) error {
+	cmd := &UnsubscribeCommand{
+		Command: newCommand("unsubscribe"),
+	}
+	if proposed {
+		cmd.AccountsProposed = accounts
+	} else {
+		cmd.Accounts = accounts
+	}
+	// Forgotten first, so that a lost connection can't bring them back
+	r.subscriptions.removeAccounts(accounts, proposed)
+	return r.send(ctx, cmd, cmd.Command)
+}
+
 func (r *Remote) Fee() (*FeeResult, error) {
+	return r.FeeCtx(context.Background())
+}
+
+// FeeCtx is like Fee but gives up when ctx is done
+func (r *Remote) FeeCtx(ctx context.Context) (*FeeResult, error) {
 	cmd := &FeeCommand{
 		Command: newCommand("fee"),
 	}
-	r.outgoing <- cmd
-	<-cmd.Ready
-	if cmd.CommandError != nil {
-		return nil, cmd.CommandError
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
 	}
 	return cmd.Result, nil
 }
 
+// received is a message read by the readPump, or why it was discarded
+type received struct {
+	message  []byte
+	tooLarge *MessageTooLargeError
+}
+
 // readPump reads from the websocket and sends to inbound channel.
-// Expects to receive PONGs at specified interval, or logs an error and returns.
-func (r *Remote) readPump(inbound chan<- []byte) {
-	r.ws.SetReadDeadline(time.Now().Add(pongWait))
-	r.ws.SetPongHandler(func(string) error { r.ws.SetReadDeadline(time.Now().Add(pongWait)); return nil })
+// Expects to receive PONGs at specified interval, or returns an error.
+func (r *Remote) readPump(ws *websocket.Conn, inbound chan<- received) error {
+	ws.SetReadDeadline(time.Now().Add(pongWait))
+	ws.SetPongHandler(func(payload string) error {
+		r.health.pong(time.Now(), payload)
+		ws.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
 	for {
-		_, message, err := r.ws.ReadMessage()
+		_, reader, err := ws.NextReader()
 		if err != nil {
-			glog.Errorln(err)
-			return
+			return err
+		}
+		message, err := readMessage(reader, r.options.MaxMessageSize)
+		if tooLarge, ok := err.(*MessageTooLargeError); ok {
+			ws.SetReadDeadline(time.Now().Add(pongWait))
+			inbound <- received{tooLarge: tooLarge}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		r.log.Debug("Received", "message", dump(message))
+		r.tap(FrameReceived, message)
+		ws.SetReadDeadline(time.Now().Add(pongWait))
+		inbound <- received{message: message}
+	}
+}
+
+// readMessage reads a whole message, unless it is larger than limit, when
+// it is discarded and a *MessageTooLargeError returned
+func readMessage(reader io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(reader)
+	}
+	b, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil || int64(len(b)) <= limit {
+		return b, err
+	}
+	rest, err := io.Copy(io.Discard, reader)
+	if err != nil {
+		return nil, err
+	}
+	return nil, &MessageTooLargeError{
+		Id:    messageId(b),
+		Size:  int64(len(b)) + rest,
+		Limit: limit,
+	}
+}
+
+// messageId reads the id from the start of a message, which rippled
+// writes before the result
+func messageId(b []byte) uint64 {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return 0
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return 0
+		}
+		if key == "id" {
+			// The number may have been cut short by the end of b
+			var id uint64
+			if dec.Decode(&id) != nil || dec.InputOffset() >= int64(len(b)) {
+				return 0
+			}
+			return id
+		}
+		var value json.RawMessage
+		if dec.Decode(&value) != nil {
+			return 0
 		}
-		glog.V(2).Infoln(dump(message))
-		r.ws.SetReadDeadline(time.Now().Add(pongWait))
-		inbound <- message
 	}
+	return 0
 }
 
 // Consumes from the outbound channel and sends them over the websocket.
 // Also sends PING messages at the specified interval.
 // Returns when outbound channel is closed, or an error is encountered.
-func (r *Remote) writePump(outbound <-chan interface{}) {
+func (r *Remote) writePump(ws *websocket.Conn, outbound <-chan []byte) {
 	ticker := time.NewTicker(pingPeriod)
 	defer ticker.Stop()
 
@@ -637,29 +2030,24 @@ func (r *Remote) writePump(outbound <-chan interface{}) {
 		select {
 
 		// An outbound message is available to send
-		case message, ok := <-outbound:
+		case b, ok := <-outbound:
 			if !ok {
-				r.ws.WriteMessage(websocket.CloseMessage, []byte{})
+				ws.WriteControl(websocket.CloseMessage, []byte{}, time.Now().Add(writeWait))
 				return
 			}
 
-			b, err := json.Marshal(message)
-			if err != nil {
-				// Outbound message cannot be JSON serialized (log it and continue)
-				glog.Errorln(err)
-				continue
-			}
-
-			glog.V(2).Infoln(dump(b))
-			if err := r.ws.WriteMessage(websocket.TextMessage, b); err != nil {
-				glog.Errorln(err)
+			r.log.Debug("Sending", "message", dump(b))
+			ws.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := ws.WriteMessage(websocket.TextMessage, b); err != nil {
+				r.log.Error("Write failed", "endpoint", r.url, "error", err)
 				return
 			}
+			r.tap(FrameSent, b)
 
 		// Time to send a ping
 		case <-ticker.C:
-			if err := r.ws.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
-				glog.Errorln(err)
+			if err := ws.WriteControl(websocket.PingMessage, pingPayload(), time.Now().Add(writeWait)); err != nil {
+				r.log.Error("Ping failed", "endpoint", r.url, "error", err)
 				return
 			}
 		}
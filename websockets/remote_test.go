@@ -0,0 +1,1172 @@
+package websockets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kr-jaydeepp/ripple/crypto"
+	"github.com/kr-jaydeepp/ripple/data"
+	. "gopkg.in/check.v1"
+)
+
+type RemoteSuite struct{}
+
+var _ = Suite(&RemoteSuite{})
+
+// silentServer accepts websocket connections and reads every message
+// without ever replying.
+func silentServer() *httptest.Server {
+	var upgrader websocket.Upgrader
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+// connectProxy is a minimal HTTP CONNECT proxy which counts its tunnels.
+func connectProxy(tunnels *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodConnect {
+			http.Error(w, "CONNECT only", http.StatusMethodNotAllowed)
+			return
+		}
+		upstream, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+		client, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer client.Close()
+		atomic.AddInt32(tunnels, 1)
+		io.WriteString(client, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go io.Copy(upstream, client)
+		io.Copy(client, upstream)
+	}))
+}
+
+func wsURL(s *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func (s *RemoteSuite) TestContextCancellation(c *C) {
+	server := silentServer()
+	defer server.Close()
+	r, err := NewRemote(wsURL(server), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := r.TxCtx(ctx, data.Hash256{})
+	c.Assert(result, IsNil)
+	c.Assert(err, Equals, context.DeadlineExceeded)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = r.AccountInfoCtx(ctx, data.Account{})
+	c.Assert(err, Equals, context.Canceled)
+}
+
+func (s *RemoteSuite) TestCommandTimeout(c *C) {
+	server := silentServer()
+	defer server.Close()
+	r, err := NewRemoteWithOptions(wsURL(server), RemoteOptions{
+		Timeout:         time.Hour,
+		CommandTimeouts: map[string]time.Duration{"tx": 50 * time.Millisecond},
+	})
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	_, err = r.Tx(data.Hash256{})
+	timeout, ok := err.(*TimeoutError)
+	c.Assert(ok, Equals, true)
+	c.Check(timeout.Command, Equals, "tx")
+	c.Check(timeout.Timeout, Equals, 50*time.Millisecond)
+
+	// The connection survives a timed out command
+	cmd := &FeeCommand{Command: newCommand("fee")}
+	cmd.Timeout = 10 * time.Millisecond
+	err = r.send(context.Background(), cmd, cmd.Command)
+	c.Assert(err, FitsTypeOf, &TimeoutError{})
+}
+
+func (s *RemoteSuite) TestProxy(c *C) {
+	var tunnels int32
+	proxy := connectProxy(&tunnels)
+	defer proxy.Close()
+	server := silentServer()
+	defer server.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	c.Assert(err, IsNil)
+	r, err := NewRemoteWithOptions(wsURL(server), RemoteOptions{Proxy: proxyURL})
+	c.Assert(err, IsNil)
+	r.Close()
+	c.Assert(atomic.LoadInt32(&tunnels), Equals, int32(1))
+}
+
+func (s *RemoteSuite) TestCommandID(c *C) {
+	server := silentServer()
+	defer server.Close()
+	r, err := NewRemoteWithOptions(wsURL(server), RemoteOptions{Timeout: 10 * time.Millisecond})
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	// Commands built without newCommand are given an id when dispatched
+	cmd := &FeeCommand{Command: &Command{Name: "fee", Ready: make(chan struct{}, 1)}}
+	c.Assert(cmd.ID(), Equals, uint64(0))
+	err = r.send(context.Background(), cmd, cmd.Command)
+	timeout, ok := err.(*TimeoutError)
+	c.Assert(ok, Equals, true)
+	c.Check(cmd.ID(), Not(Equals), uint64(0))
+	c.Check(timeout.Id, Equals, cmd.ID())
+	c.Check(timeout.Command, Equals, "fee")
+}
+
+func (s *RemoteSuite) TestAPIVersion(c *C) {
+	versions := make(chan interface{}, 3)
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		versions <- cmd["api_version"]
+		return []string{`{"id":$ID,"status":"success","type":"response","api_version":2,"result":{}}`}
+	})
+	defer server.Close()
+	r, err := NewRemoteWithOptions(wsURL(server), RemoteOptions{APIVersion: 2})
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	_, err = r.Fee()
+	c.Assert(err, IsNil)
+	c.Check(<-versions, Equals, 2.0)
+
+	// A command's own version wins
+	cmd := &FeeCommand{Command: newCommand("fee")}
+	cmd.APIVersion = 1
+	c.Assert(r.send(context.Background(), cmd, cmd.Command), IsNil)
+	c.Check(<-versions, Equals, 1.0)
+
+	plain, err := NewRemote(wsURL(server), false)
+	c.Assert(err, IsNil)
+	defer plain.Close()
+	_, err = plain.Fee()
+	c.Assert(err, IsNil)
+	c.Check(<-versions, IsNil)
+}
+
+func (s *RemoteSuite) TestShutdown(c *C) {
+	received, release := make(chan struct{}), make(chan struct{})
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		if cmd["command"] == "fee" {
+			close(received)
+			<-release
+		}
+		return []string{`{"id":$ID,"status":"success","type":"response","result":{"ledger_current_index":5}}`}
+	})
+	defer server.Close()
+	r, err := NewRemote(wsURL(server), true)
+	c.Assert(err, IsNil)
+
+	fee := make(chan error)
+	go func() {
+		_, err := r.Fee()
+		fee <- err
+	}()
+	<-received
+	shutdown := make(chan error)
+	go func() { shutdown <- r.Shutdown(context.Background()) }()
+
+	// New commands are refused while the fee is awaited
+	for err != ErrClosed {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err = r.LedgerCurrentCtx(ctx)
+		cancel()
+	}
+	close(release)
+	c.Check(<-fee, IsNil)
+	c.Check(<-shutdown, IsNil)
+	c.Check(r.Health().State, Equals, StateClosed)
+	_, err = r.Fee()
+	c.Check(err, Equals, ErrClosed)
+	r.Close()
+}
+
+func (s *RemoteSuite) TestCloseWithTimeout(c *C) {
+	received := make(chan struct{})
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		close(received)
+		return nil
+	})
+	defer server.Close()
+	r, err := NewRemote(wsURL(server), false)
+	c.Assert(err, IsNil)
+
+	fee := make(chan error)
+	go func() {
+		_, err := r.Fee()
+		fee <- err
+	}()
+	<-received
+	c.Check(r.CloseWithTimeout(20*time.Millisecond), Equals, context.DeadlineExceeded)
+	c.Check(<-fee, ErrorMatches, "Client Error -1 ws: server disconnected")
+	_, err = r.SubmitBatch(nil)
+	c.Check(err, IsNil)
+	_, err = r.Fee()
+	c.Check(err, Equals, ErrClosed)
+}
+
+func (s *RemoteSuite) TestReconnectUnderLoad(c *C) {
+	// Each connection answers four commands then drops
+	var connections int32
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		atomic.AddInt32(&connections, 1)
+		for i := 0; i < 4; i++ {
+			var cmd map[string]interface{}
+			if err := ws.ReadJSON(&cmd); err != nil {
+				return
+			}
+			ws.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"type":"ledgerClosed","ledger_index":%d}`, i)))
+			ws.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"id":%v,"status":"success","type":"response","result":{}}`, cmd["id"])))
+		}
+	}))
+	defer server.Close()
+	r, err := NewRemoteWithOptions(wsURL(server), RemoteOptions{
+		Reconnect:         true,
+		ReconnectInterval: time.Millisecond,
+		Overflow:          OverflowDropOldest,
+		IncomingBuffer:    1,
+	})
+	c.Assert(err, IsNil)
+
+	var wg sync.WaitGroup
+	var answered, failed int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				_, err := r.Fee()
+				switch {
+				case err == nil:
+					atomic.AddInt32(&answered, 1)
+				case strings.Contains(err.Error(), "ws: server disconnected"):
+					atomic.AddInt32(&failed, 1)
+					time.Sleep(time.Millisecond)
+				default:
+					c.Error(err)
+				}
+				r.Health()
+			}
+		}()
+	}
+	wg.Wait()
+	r.Close()
+	c.Check(answered+failed, Equals, int32(200))
+	c.Check(answered > 0, Equals, true)
+	c.Check(atomic.LoadInt32(&connections) > 1, Equals, true)
+	c.Check(r.Health().State, Equals, StateClosed)
+}
+
+func (s *RemoteSuite) TestClosedByServer(c *C) {
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if ws, err := upgrader.Upgrade(w, req, nil); err == nil {
+			ws.Close()
+		}
+	}))
+	defer server.Close()
+	r, err := NewRemote(wsURL(server), false)
+	c.Assert(err, IsNil)
+	for range r.Incoming {
+	}
+
+	// Rather than wait for ever in the queue
+	for i := 0; i < 20; i++ {
+		_, err = r.Fee()
+		c.Assert(err, Equals, ErrClosed)
+	}
+	r.Close()
+}
+
+func (s *RemoteSuite) TestMaxMessageSize(c *C) {
+	large := true
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		if large {
+			large = false
+			padding := strings.Repeat("x", 200)
+			return []string{
+				`{"id":$ID,"status":"success","type":"response","result":{"padding":"` + padding + `"}}`,
+				`{"type":"ledgerClosed","ledger_index":7,"padding":"` + padding + `"}`,
+			}
+		}
+		return []string{`{"id":$ID,"status":"success","type":"response","result":{}}`}
+	})
+	defer server.Close()
+	r, err := NewRemoteWithOptions(wsURL(server), RemoteOptions{MaxMessageSize: 100})
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	_, err = r.Fee()
+	tooLarge, ok := err.(*MessageTooLargeError)
+	c.Assert(ok, Equals, true, Commentf("%v", err))
+	c.Check(tooLarge.Id, Not(Equals), uint64(0))
+	c.Check(tooLarge.Limit, Equals, int64(100))
+	c.Check(tooLarge.Size > 200, Equals, true)
+
+	// The connection is kept
+	_, err = r.Fee()
+	c.Check(err, IsNil)
+	c.Check(r.Health().State, Equals, StateConnected)
+}
+
+func (s *RemoteSuite) TestReadMessage(c *C) {
+	msg := `{"id":12,"status":"success","result":{"ledger":"abc"}}`
+	b, err := readMessage(strings.NewReader(msg), 0)
+	c.Check(err, IsNil)
+	c.Check(string(b), Equals, msg)
+	b, err = readMessage(strings.NewReader(msg), int64(len(msg)))
+	c.Check(err, IsNil)
+	c.Check(string(b), Equals, msg)
+
+	_, err = readMessage(strings.NewReader(msg), 20)
+	c.Check(err, DeepEquals, &MessageTooLargeError{Id: 12, Size: int64(len(msg)), Limit: 20})
+	_, err = readMessage(strings.NewReader(msg), 5)
+	c.Check(err, DeepEquals, &MessageTooLargeError{Size: int64(len(msg)), Limit: 5})
+
+	for prefix, id := range map[string]uint64{
+		`{"type":"response","result":{"x":[1,2]},"id":3,`: 3,
+		`{"id":4`:           0,
+		`{"result":{"id":5`: 0,
+		`[{"id":6}]`:        0,
+	} {
+		c.Check(messageId([]byte(prefix)), Equals, id, Commentf(prefix))
+	}
+}
+
+func (s *RemoteSuite) TestCompression(c *C) {
+	upgrader := websocket.Upgrader{EnableCompression: true}
+	extensions := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		extensions <- req.Header.Get("Sec-Websocket-Extensions")
+		ws, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		var cmd map[string]interface{}
+		if ws.ReadJSON(&cmd) == nil {
+			ws.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"id":%v,"status":"success","type":"response","result":{}}`, cmd["id"])))
+		}
+		ws.ReadMessage()
+	}))
+	defer server.Close()
+	r, err := NewRemoteWithOptions(wsURL(server), RemoteOptions{EnableCompression: true})
+	c.Assert(err, IsNil)
+	defer r.Close()
+	c.Check(<-extensions, Matches, "permessage-deflate.*")
+	_, err = r.Fee()
+	c.Check(err, IsNil)
+}
+
+// scriptedServer replies to each command with the messages returned by
+// reply, after substituting the command's id.
+func scriptedServer(reply func(cmd map[string]interface{}) []string) *httptest.Server {
+	var upgrader websocket.Upgrader
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			var cmd map[string]interface{}
+			if err := ws.ReadJSON(&cmd); err != nil {
+				return
+			}
+			id := fmt.Sprint(cmd["id"])
+			for _, msg := range reply(cmd) {
+				msg = strings.Replace(msg, "$ID", id, -1)
+				if err := ws.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+					return
+				}
+			}
+		}
+	}))
+}
+
+func (s *RemoteSuite) TestPathFind(c *C) {
+	const alternative = `{"source_amount":"%d","paths_computed":[[{"currency":"USD","issuer":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","type":48,"type_hex":"0000000000000030"}]]}`
+	body := func(drops int, full bool) string {
+		return fmt.Sprintf(`"source_account":"r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59","destination_account":"r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59","destination_amount":{"currency":"USD","issuer":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","value":"0.001"},"full_reply":%t,"alternatives":[`+alternative+`]`, full, drops)
+	}
+	var subcommands []string
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		subcommands = append(subcommands, cmd["subcommand"].(string))
+		switch cmd["subcommand"] {
+		case "create":
+			return []string{
+				`{"id":$ID,"status":"success","type":"response","result":{` + body(1200, false) + `}}`,
+				`{"id":$ID,"type":"path_find",` + body(1100, false) + `}`,
+				`{"id":$ID,"type":"path_find",` + body(1000, true) + `}`,
+			}
+		case "status":
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{` + body(1000, true) + `}}`}
+		default:
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{"closed":true}}`}
+		}
+	})
+	defer server.Close()
+	r, err := NewRemote(wsURL(server), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	account, err := data.NewAccountFromAddress("r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59")
+	c.Assert(err, IsNil)
+	amount, err := data.NewAmount("0.001/USD/rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(err, IsNil)
+	pf, err := r.PathFindCreate(*account, *account, *amount, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(pf.Alternatives, HasLen, 1)
+	c.Check(pf.Alternatives[0].SourceAmount.String(), Equals, "0.0012/XRP")
+	c.Check(pf.Alternatives[0].PathsComputed, HasLen, 1)
+
+	// Only the latest update is kept for a slow reader
+	var update *PathFindResult
+	for update == nil || !update.FullReply {
+		select {
+		case update = <-pf.Updates:
+		case <-time.After(time.Second):
+			c.Fatal("no full reply")
+		}
+	}
+	c.Check(update.Alternatives[0].SourceAmount.String(), Equals, "0.001/XRP")
+
+	status, err := pf.Status()
+	c.Assert(err, IsNil)
+	c.Check(status.FullReply, Equals, true)
+
+	c.Assert(pf.Close(), IsNil)
+	_, ok := <-pf.Updates
+	c.Check(ok, Equals, false)
+	c.Assert(pf.Close(), IsNil)
+	c.Check(subcommands, DeepEquals, []string{"create", "status", "close"})
+}
+
+func (s *RemoteSuite) TestSubscription(c *C) {
+	const (
+		ledger = `"fee_base":10,"fee_ref":10,"ledger_hash":"E23869F043A46C2735BCA40781A674C5F24460BAC26C6B7475550493A9180200","ledger_index":%d,"ledger_time":454971400,"reserve_base":20000000,"reserve_inc":5000000,"validated_ledgers":"32570-%d"`
+		server = `"server_status":"full","base_fee":10,"load_base":256,"load_factor":256`
+	)
+	var unsubscribed [][]interface{}
+	sequence := 100
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		if cmd["command"] == "unsubscribe" {
+			unsubscribed = append(unsubscribed, cmd["streams"].([]interface{}))
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{}}`}
+		}
+		sequence++
+		result := fmt.Sprintf(ledger, sequence, sequence)
+		for _, stream := range cmd["streams"].([]interface{}) {
+			if stream == "server" {
+				result += "," + server
+			}
+		}
+		return []string{
+			`{"id":$ID,"status":"success","type":"response","result":{` + result + `}}`,
+			`{"type":"ledgerClosed",` + fmt.Sprintf(ledger, sequence+1, sequence+1) + `}`,
+			`{"type":"serverStatus",` + server + `}`,
+		}
+	})
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	_, err = r.SubscribeTo(SubscriptionOptions{})
+	c.Assert(err, NotNil)
+
+	ledgers, err := r.SubscribeTo(SubscriptionOptions{Ledger: true})
+	c.Assert(err, IsNil)
+	c.Check(ledgers.Result.LedgerSequence, Equals, uint32(101))
+	c.Check(ledgers.Transactions, IsNil)
+	c.Check(ledgers.Server, IsNil)
+	select {
+	case msg := <-ledgers.Ledgers:
+		c.Check(msg.LedgerSequence, Equals, uint32(102))
+	case <-time.After(time.Second):
+		c.Fatal("no ledger")
+	}
+	// Nobody else wants the server status
+	select {
+	case msg := <-r.Incoming:
+		c.Check(msg, FitsTypeOf, &ServerStreamMsg{})
+	case <-time.After(time.Second):
+		c.Fatal("no server status")
+	}
+
+	both, err := r.SubscribeTo(SubscriptionOptions{Ledger: true, Server: true})
+	c.Assert(err, IsNil)
+	c.Check(both.Result.ServerStreamMsg.Status, Equals, "full")
+	for _, sub := range []*Subscription{ledgers, both} {
+		select {
+		case msg := <-sub.Ledgers:
+			c.Check(msg.LedgerSequence, Equals, uint32(103))
+		case <-time.After(time.Second):
+			c.Fatal("no ledger")
+		}
+	}
+	select {
+	case msg := <-both.Server:
+		c.Check(msg.TransactionCost(), Equals, uint64(10))
+	case <-time.After(time.Second):
+		c.Fatal("no server status")
+	}
+
+	// The ledger stream is still wanted by both
+	c.Assert(ledgers.Close(), IsNil)
+	_, ok := <-ledgers.Ledgers
+	c.Check(ok, Equals, false)
+	c.Assert(ledgers.Close(), IsNil)
+	c.Check(unsubscribed, HasLen, 0)
+
+	c.Assert(both.Close(), IsNil)
+	_, ok = <-both.Server
+	c.Check(ok, Equals, false)
+	c.Check(unsubscribed, DeepEquals, [][]interface{}{{"ledger", "server"}})
+	c.Check(r.subscriptions.replay(r.Incoming), IsNil)
+}
+
+func (s *RemoteSuite) TestSubscribeAdminStreams(c *C) {
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		if cmd["command"] == "unsubscribe" {
+			c.Check(cmd["streams"], DeepEquals, []interface{}{"consensus", "peer_status"})
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{}}`}
+		}
+		c.Check(cmd["streams"], DeepEquals, []interface{}{"consensus", "peer_status"})
+		return []string{
+			`{"id":$ID,"status":"success","type":"response","result":{}}`,
+			`{"type":"consensusPhase","consensus":"establish"}`,
+			`{"type":"peerStatusChange","action":"LOST_SYNC","date":508546525}`,
+			`{"type":"consensusPhase","consensus":"accepted"}`,
+		}
+	})
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	sub, err := r.SubscribeTo(SubscriptionOptions{Consensus: true, PeerStatus: true})
+	c.Assert(err, IsNil)
+	c.Check(sub.Validations, IsNil)
+	for _, phase := range []string{ConsensusEstablish, ConsensusAccepted} {
+		select {
+		case msg := <-sub.Consensus:
+			c.Check(msg.Phase, Equals, phase)
+		case <-time.After(time.Second):
+			c.Fatal("no consensus phase")
+		}
+	}
+	select {
+	case msg := <-sub.PeerStatus:
+		c.Check(msg.Action, Equals, PeerLostSync)
+		c.Check(msg.LedgerHash, IsNil)
+	case <-time.After(time.Second):
+		c.Fatal("no peer status")
+	}
+	c.Assert(sub.Close(), IsNil)
+	_, ok := <-sub.PeerStatus
+	c.Check(ok, Equals, false)
+}
+
+func (s *RemoteSuite) TestSubscribeValidations(c *C) {
+	var keys []crypto.Key
+	var nodes []string
+	for i := 0; i < 4; i++ {
+		key, err := crypto.NewECDSAKey(nil)
+		c.Assert(err, IsNil)
+		node, err := crypto.NodePublicKey(key)
+		c.Assert(err, IsNil)
+		keys, nodes = append(keys, key), append(nodes, node.String())
+	}
+	hash, err := data.NewHash256("1A8194A501C8C9AC779A96495365D596371C09636E63F62BB0B4B81CF1239BAF")
+	c.Assert(err, IsNil)
+	validation := func(signer int, master string, flags uint32, sequence uint32) string {
+		v := &data.Validation{Flags: flags, LedgerHash: *hash, LedgerSequence: 7}
+		c.Assert(data.Sign(v, keys[signer], nil), IsNil)
+		_, raw, err := data.Raw(v)
+		c.Assert(err, IsNil)
+		return fmt.Sprintf(`{"type":"validationReceived","flags":%d,"full":%t,"ledger_hash":"%s","ledger_index":"%d","signature":"%s","validation_public_key":"%s","master_key":"%s","data":"%X"}`,
+			flags, v.IsFull(), hash, sequence, v.Signature.String(), nodes[signer], master, raw)
+	}
+	full := data.FullValidation
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		if cmd["command"] == "unsubscribe" {
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{}}`}
+		}
+		c.Check(cmd["streams"], DeepEquals, []interface{}{"validations"})
+		return []string{
+			`{"id":$ID,"status":"success","type":"response","result":{}}`,
+			validation(0, "", full, 7),
+			validation(0, "", full, 7),       // Repeated
+			validation(1, "", 0, 7),          // Partial
+			validation(2, "", full, 7),       // Untrusted
+			validation(1, nodes[3], full, 8), // Of another ledger
+			validation(1, nodes[3], full, 7), // Ephemeral key of a trusted master
+		}
+	})
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	_, err = NewValidationQuorum(3, nodes[:2])
+	c.Check(err, ErrorMatches, "Bad quorum: 3 of 2 validators")
+	_, err = NewValidationQuorum(1, []string{"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"})
+	c.Check(err, ErrorMatches, "Bad validator key: .*")
+	quorum, err := NewValidationQuorum(2, []string{nodes[0], nodes[1], nodes[3]})
+	c.Assert(err, IsNil)
+
+	sub, err := r.SubscribeTo(SubscriptionOptions{Validations: true})
+	c.Assert(err, IsNil)
+	c.Check(sub.Ledgers, IsNil)
+	for i, expected := range []struct {
+		count   int
+		reached bool
+		err     string
+	}{
+		{1, false, ""},
+		{1, false, ""},
+		{1, false, ""},
+		{1, false, ""},
+		{1, false, "Validation is of ledger 7 .* not 8 .*"},
+		{2, true, ""},
+	} {
+		select {
+		case msg := <-sub.Validations:
+			count, reached, err := quorum.Add(msg)
+			c.Check(count, Equals, expected.count, Commentf("%d", i))
+			c.Check(reached, Equals, expected.reached, Commentf("%d", i))
+			if expected.err == "" {
+				c.Check(err, IsNil, Commentf("%d", i))
+			} else {
+				c.Check(err, ErrorMatches, expected.err, Commentf("%d", i))
+			}
+		case <-time.After(time.Second):
+			c.Fatal("no validation")
+		}
+	}
+	c.Check(quorum.Validated(*hash), Equals, true)
+	quorum.Forget(7)
+	c.Check(quorum.Count(*hash), Equals, 2)
+	quorum.Forget(8)
+	c.Check(quorum.Count(*hash), Equals, 0)
+
+	c.Assert(sub.Close(), IsNil)
+	_, ok := <-sub.Validations
+	c.Check(ok, Equals, false)
+}
+
+func (s *RemoteSuite) TestIncomingOverflow(c *C) {
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		var msgs []string
+		for i := 1; i <= 3; i++ {
+			msgs = append(msgs, fmt.Sprintf(`{"type":"ledgerClosed","ledger_index":%d}`, i))
+		}
+		return append(msgs, `{"id":$ID,"status":"success","type":"response","result":{}}`)
+	})
+	defer srv.Close()
+
+	for _, test := range []struct {
+		policy  OverflowPolicy
+		ledgers []uint32
+		dropped uint64
+	}{
+		{OverflowDropNewest, []uint32{1, 2}, 1},
+		{OverflowDropOldest, []uint32{2, 3}, 1},
+		{OverflowDisconnect, []uint32{2}, 2},
+	} {
+		r, err := NewRemoteWithOptions(wsURL(srv), RemoteOptions{IncomingBuffer: 2, Overflow: test.policy})
+		c.Assert(err, IsNil)
+		_, err = r.Fee()
+		c.Check(err != nil, Equals, test.policy == OverflowDisconnect)
+		c.Check(r.DroppedMessages(), Equals, test.dropped)
+
+		var ledgers []uint32
+		for range test.ledgers {
+			ledgers = append(ledgers, (<-r.Incoming).(*LedgerStreamMsg).LedgerSequence)
+		}
+		c.Check(ledgers, DeepEquals, test.ledgers)
+		if test.policy == OverflowDisconnect {
+			c.Check(<-r.Incoming, DeepEquals, &OverflowError{Capacity: 2})
+			_, ok := <-r.Incoming
+			c.Check(ok, Equals, false)
+		}
+		r.Close()
+	}
+}
+
+func (s *RemoteSuite) TestSubmitMultisigned(c *C) {
+	var sent map[string]interface{}
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		switch cmd["command"] {
+		case "account_objects":
+			c.Check(cmd["type"], Equals, "signer_list")
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{"account":"rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe","ledger_index":100,"validated":true,"account_objects":[{"LedgerEntryType":"SignerList","Flags":0,"OwnerNode":"0000000000000000","SignerQuorum":2,"SignerListID":0,"SignerEntries":[{"SignerEntry":{"Account":"rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B","SignerWeight":1}},{"SignerEntry":{"Account":"r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59","SignerWeight":1}}],"index":"A9C28A28B85CD533217F5C0A0C7767666B093FA58A0F2D80026FCC4CD932DDC7"}]}}`}
+		default:
+			sent = cmd["tx_json"].(map[string]interface{})
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{"engine_result":"tesSUCCESS","engine_result_code":0,"engine_result_message":"The transaction was applied. Only final in a validated ledger.","tx_blob":"00","tx_json":{}}}`}
+		}
+	})
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	var tx data.Payment
+	c.Assert(json.Unmarshal([]byte(`{
+		"TransactionType": "Payment",
+		"Account": "rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe",
+		"Destination": "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh",
+		"Amount": "1000000",
+		"Fee": "30",
+		"Sequence": 4,
+		"SigningPubKey": "",
+		"Signers": [{
+			"Signer": {
+				"Account": "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59",
+				"SigningPubKey": "02B3EC4E5DD96029A647CFA20DA07FE1F85296505552CCAC114087E66B46BD77DF",
+				"TxnSignature": "30440220"
+			}
+		}]
+	}`), &tx), IsNil)
+
+	// One signer falls short of the quorum
+	_, err = r.SubmitMultisigned(&tx)
+	c.Check(err, ErrorMatches, "Signer weight 1 is below the quorum of 2")
+	c.Check(sent, IsNil)
+
+	signer := tx.Signers[0]
+	signer.Signer.Account = tx.Destination
+	tx.Signers = append(tx.Signers, signer)
+	_, err = r.SubmitMultisigned(&tx)
+	c.Check(err, ErrorMatches, "Signer rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh is not in the signer list")
+
+	first, err := data.NewAccountFromAddress("rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(err, IsNil)
+	tx.Signers[1].Signer.Account = tx.Signers[0].Signer.Account
+	tx.Signers[0].Signer.Account = *first
+	result, err := r.SubmitMultisigned(&tx)
+	c.Assert(err, IsNil)
+	c.Check(result.EngineResult.String(), Equals, "tesSUCCESS")
+	c.Check(sent["SigningPubKey"], Equals, "")
+	c.Check(sent["Signers"], HasLen, 2)
+	_, hashed := sent["hash"]
+	c.Check(hashed, Equals, false)
+}
+
+func (s *RemoteSuite) TestSign(c *C) {
+	var signed data.Payment
+	c.Assert(json.Unmarshal([]byte(`{
+		"TransactionType": "Payment",
+		"Account": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B",
+		"Destination": "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59",
+		"Amount": "1000000",
+		"Fee": "12",
+		"Sequence": 7,
+		"SigningPubKey": "02B3EC4E5DD96029A647CFA20DA07FE1F85296505552CCAC114087E66B46BD77DF",
+		"TxnSignature": "3044022012"
+	}`), &signed), IsNil)
+	hash, raw, err := data.Raw(&signed)
+	c.Assert(err, IsNil)
+
+	var sent map[string]interface{}
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		sent = cmd
+		return []string{fmt.Sprintf(`{"id":$ID,"status":"success","type":"response","result":{"tx_blob":"%X","tx_json":{}}}`, raw)}
+	})
+	defer srv.Close()
+
+	unsigned := signed
+	unsigned.Fee = data.Value{}
+	unsigned.Sequence = 0
+	unsigned.SigningPubKey = nil
+	unsigned.TxnSignature = nil
+
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	_, err = r.Sign(&unsigned, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb")
+	c.Check(err, ErrorMatches, "Signing by the server sends it a secret.*")
+	r.Close()
+	c.Check(sent, IsNil)
+
+	r, err = NewRemoteWithOptions(wsURL(srv), RemoteOptions{AllowServerSigning: true})
+	c.Assert(err, IsNil)
+	defer r.Close()
+	result, err := r.Sign(&unsigned, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb")
+	c.Assert(err, IsNil)
+	c.Check(sent["command"], Equals, "sign")
+	c.Check(sent["secret"], Equals, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb")
+	tx := sent["tx_json"].(map[string]interface{})
+	c.Check(tx["Account"], Equals, "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	for _, field := range []string{"Fee", "Sequence", "hash"} {
+		_, ok := tx[field]
+		c.Check(ok, Equals, false, Commentf(field))
+	}
+	c.Assert(result.Tx, FitsTypeOf, &data.Payment{})
+	c.Check(result.Tx.(*data.Payment).Sequence, Equals, uint32(7))
+	c.Check(*result.Tx.GetHash(), Equals, hash)
+
+	_, err = r.SignFor(result.Tx, signed.Destination, "snoPBrXtMeMyMHUVTgbuqAfg1SUTb")
+	c.Assert(err, IsNil)
+	c.Check(sent["command"], Equals, "sign_for")
+	c.Check(sent["account"], Equals, "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59")
+}
+
+func (s *RemoteSuite) TestLedgerRange(c *C) {
+	var infos int32
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		if cmd["command"] == "server_info" {
+			atomic.AddInt32(&infos, 1)
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{"info":{"complete_ledgers":"1-6,8-20"}}}`}
+		}
+		switch sequence := cmd["ledger"].(float64); sequence {
+		case 7, 9:
+			return []string{`{"id":$ID,"status":"error","type":"response","error":"lgrNotFound","error_code":21,"error_message":"ledgerNotFound","request":{}}`}
+		default:
+			return []string{fmt.Sprintf(`{"id":$ID,"status":"success","type":"response","result":{"ledger":{"ledger_index":"%d","closed":true}}}`, int(sequence))}
+		}
+	})
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	// 7 is a gap in the server's history
+	it := r.LedgerRange(4, 8, LedgerRangeOptions{Workers: 3})
+	var sequences []uint32
+	for ledger := range it.Ledgers {
+		sequences = append(sequences, ledger.Ledger.LedgerSequence)
+	}
+	c.Check(it.Err(), IsNil)
+	c.Check(sequences, DeepEquals, []uint32{4, 5, 6, 8})
+	c.Check(it.Missing(), DeepEquals, data.LedgerSlice{7})
+	c.Check(atomic.LoadInt32(&infos), Equals, int32(1))
+
+	// 9 should be there
+	it = r.LedgerRange(8, 11, LedgerRangeOptions{})
+	sequences = nil
+	for ledger := range it.Ledgers {
+		sequences = append(sequences, ledger.Ledger.LedgerSequence)
+	}
+	c.Check(it.Err(), ErrorMatches, "Ledger 9 not found but within complete ledgers 1-6,8-20")
+	c.Check(sequences, DeepEquals, []uint32{8})
+	c.Check(it.Missing(), HasLen, 0)
+}
+
+func (s *RemoteSuite) TestVerifyLedgerChain(c *C) {
+	ledgers := make(map[uint32]*data.Ledger)
+	var parent data.Hash256
+	for sequence := uint32(1); sequence <= 9; sequence++ {
+		ledger := data.NewEmptyLedger(sequence)
+		ledger.PreviousLedger = parent
+		ledger.CloseTime.SetUint32(sequence * 10)
+		var err error
+		ledger.Hash, err = data.LedgerHash(&ledger.LedgerHeader)
+		c.Assert(err, IsNil)
+		ledgers[sequence], parent = ledger, ledger.Hash
+	}
+	// 5 claims a hash its header doesn't have, and 7 is missing
+	ledgers[5].TotalXRP++
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		if cmd["command"] == "server_info" {
+			return []string{`{"id":$ID,"status":"success","type":"response","result":{"info":{"complete_ledgers":"1-6,8-9"}}}`}
+		}
+		ledger, ok := ledgers[uint32(cmd["ledger"].(float64))]
+		if !ok || ledger.LedgerSequence == 7 {
+			return []string{`{"id":$ID,"status":"error","type":"response","error":"lgrNotFound","error_code":21,"error_message":"ledgerNotFound","request":{}}`}
+		}
+		b, err := json.Marshal(ledger)
+		c.Assert(err, IsNil)
+		return []string{fmt.Sprintf(`{"id":$ID,"status":"success","type":"response","result":{"ledger":%s}}`, b)}
+	})
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	c.Check(r.VerifyLedgerChain(1, 4), IsNil)
+	c.Check(r.VerifyLedgerChainCtx(context.Background(), 2, 6, 3), ErrorMatches, "Ledger 5 has hash .* but its header hashes to .*")
+	c.Check(r.VerifyLedgerChain(6, 9), ErrorMatches, "Ledger 7 is missing")
+	c.Check(r.VerifyLedgerChain(8, 10), ErrorMatches, "Ledger 10 is missing")
+
+	// A ledger from another chain
+	ledgers[2].PreviousLedger = data.Hash256{1}
+	ledgers[2].Hash, err = data.LedgerHash(&ledgers[2].LedgerHeader)
+	c.Assert(err, IsNil)
+	c.Check(r.VerifyLedgerChainCtx(context.Background(), 1, 3, 2), ErrorMatches, "Ledger 2 has parent 01.* not .*")
+}
+
+func (s *RemoteSuite) TestStreamLedgerData(c *C) {
+	const node = "1100612200020000240000022225007486012B3BB94E802D0000000055B737C6C9F46FD87E9FA78201E60E3B34CBAD1EA325099D687FA155EE0766870A6240000963176CDB1981140A20B3C85F482532A9578DBB3950B85CA06594D1"
+	var keys []string
+	for i := 0; i < 40; i++ {
+		keys = append(keys, fmt.Sprintf("%02X%02X%060d", i*6, i, 0))
+	}
+	var mu sync.Mutex
+	ledgers := make(map[interface{}]int)
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		mu.Lock()
+		ledgers[cmd["ledger"]]++
+		mu.Unlock()
+		limit, ok := cmd["limit"].(float64)
+		if !ok {
+			return []string{`{"id":$ID,"status":"error","type":"response","error":"invalidParams","error_code":31,"error_message":"Invalid parameters.","request":{}}`}
+		}
+		i := 0
+		if marker, ok := cmd["marker"].(string); ok {
+			i = sort.SearchStrings(keys, marker)
+			if i < len(keys) && keys[i] == marker {
+				i++
+			}
+		}
+		var state []string
+		for ; i < len(keys) && len(state) < int(limit); i++ {
+			state = append(state, fmt.Sprintf(`{"data":"%s","index":"%s"}`, node, keys[i]))
+		}
+		marker := ""
+		if i < len(keys) {
+			marker = fmt.Sprintf(`"marker":"%s",`, keys[i-1])
+		}
+		return []string{fmt.Sprintf(`{"id":$ID,"status":"success","type":"response","result":{"ledger_index":100,%s"state":[%s]}}`, marker, strings.Join(state, ","))}
+	})
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	for _, options := range []LedgerDataOptions{
+		{Workers: 4, PageSize: 3},
+		{Workers: 3, PageSize: 4, Ordered: true},
+		{PageSize: 7},
+	} {
+		mu.Lock()
+		ledgers = make(map[interface{}]int)
+		mu.Unlock()
+		it := r.StreamLedgerDataCtx(context.Background(), "validated", options)
+		var indexes []string
+		var last LedgerDataProgress
+		for page := range it.Pages {
+			c.Check(page.LedgerSequence, Equals, uint32(100))
+			c.Check(page.Progress.Pages, Equals, last.Pages+1)
+			c.Check(page.Progress.Done >= last.Done, Equals, true)
+			last = page.Progress
+			for _, le := range page.State {
+				c.Check(le.GetLedgerEntryType(), Equals, data.ACCOUNT_ROOT)
+				indexes = append(indexes, le.GetHash().String())
+			}
+		}
+		c.Assert(it.Err(), IsNil)
+		c.Check(last.Entries, Equals, len(keys))
+		c.Check(last.Done, Equals, 1.0)
+		if !options.Ordered && options.Workers > 1 {
+			sort.Strings(indexes)
+		}
+		c.Check(indexes, DeepEquals, keys, Commentf("%+v", options))
+		mu.Lock()
+		c.Check(ledgers["validated"], Equals, 1)
+		c.Check(ledgers[100.0], Equals, last.Pages-1)
+		mu.Unlock()
+	}
+
+	// Errors end the iteration
+	it := r.StreamLedgerDataCtx(context.Background(), "validated", LedgerDataOptions{Workers: 2})
+	for range it.Pages {
+	}
+	c.Check(it.Err(), ErrorMatches, ".*invalidParams.*")
+}
+
+func (s *RemoteSuite) TestAccountTx(c *C) {
+	b, err := ioutil.ReadFile("testdata/account_tx.json")
+	c.Assert(err, IsNil)
+	var response map[string]interface{}
+	c.Assert(json.Unmarshal(b, &response), IsNil)
+	var markers []interface{}
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		markers = append(markers, cmd["marker"])
+		if cmd["ledger_index_min"].(float64) < -1 {
+			return []string{`{"id":$ID,"status":"error","type":"response","error":"lgrIdxMalformed","error_code":57,"error_message":"Ledger index malformed.","request":{}}`}
+		}
+		result := response["result"].(map[string]interface{})
+		delete(result, "marker")
+		if cmd["marker"] == nil {
+			result["marker"] = map[string]interface{}{"ledger": 7284002, "seq": 7}
+		}
+		response["id"] = cmd["id"]
+		b, err := json.Marshal(response)
+		c.Assert(err, IsNil)
+		return []string{string(b)}
+	})
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+	account, err := data.NewAccountFromAddress("rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh")
+	c.Assert(err, IsNil)
+
+	pages, errs := r.AccountTxPagesCtx(context.Background(), *account, AccountTxOptions{PageSize: 2, MinLedger: -1, MaxLedger: -1})
+	var saved map[string]interface{}
+	for page := range pages {
+		c.Check(page.Transactions, HasLen, 2)
+		if saved == nil {
+			saved = page.Marker
+		} else {
+			c.Check(page.Marker, IsNil)
+		}
+	}
+	c.Check(<-errs, IsNil)
+	c.Check(saved, DeepEquals, map[string]interface{}{"ledger": 7284002.0, "seq": 7.0})
+
+	// Resumed from the saved marker
+	markers = nil
+	txs, errs := r.AccountTxCtx(context.Background(), *account, AccountTxOptions{MinLedger: -1, MaxLedger: -1, Marker: saved})
+	n := 0
+	for range txs {
+		n++
+	}
+	c.Check(<-errs, IsNil)
+	c.Check(n, Equals, 2)
+	c.Check(markers, DeepEquals, []interface{}{map[string]interface{}{"ledger": 7284002.0, "seq": 7.0}})
+
+	txs, errs = r.AccountTxCtx(context.Background(), *account, AccountTxOptions{MinLedger: -2, MaxLedger: -1})
+	for range txs {
+		c.Fatal("transaction despite error")
+	}
+	c.Check(<-errs, ErrorMatches, ".*lgrIdxMalformed.*")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	txs, errs = r.AccountTxCtx(ctx, *account, AccountTxOptions{MinLedger: -1, MaxLedger: -1})
+	for range txs {
+	}
+	c.Check(<-errs, Equals, context.Canceled)
+
+	// The old API still gets every page
+	n = 0
+	for range r.AccountTx(*account, 2, -1, -1) {
+		n++
+	}
+	c.Check(n, Equals, 4)
+}
+
+func (s *RemoteSuite) TestGetAggregatePrice(c *C) {
+	var sent map[string]interface{}
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		sent = cmd
+		return []string{`{"id":$ID,"status":"success","type":"response","result":{"entire_set":{"mean":"0.78","size":3,"standard_deviation":"0.0125"},"trimmed_set":{"mean":"0.78","size":1,"standard_deviation":"0"},"ledger_current_index":25,"median":"0.78","time":1724871860,"validated":false}}`}
+	})
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	base, err := data.NewCurrency("XRP")
+	c.Assert(err, IsNil)
+	quote, err := data.NewCurrency("USD")
+	c.Assert(err, IsNil)
+	owner, err := data.NewAccountFromAddress("rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(err, IsNil)
+	result, err := r.GetAggregatePrice(base, quote, []OracleSelector{{*owner, 1}, {*owner, 2}}, AggregatePriceOptions{Trim: 20})
+	c.Assert(err, IsNil)
+	c.Check(sent["command"], Equals, "get_aggregate_price")
+	c.Check(sent["base_asset"], Equals, "XRP")
+	c.Check(sent["quote_asset"], Equals, "USD")
+	c.Check(sent["trim"], Equals, float64(20))
+	_, ok := sent["time_threshold"]
+	c.Check(ok, Equals, false)
+	c.Check(sent["oracles"], DeepEquals, []interface{}{
+		map[string]interface{}{"account": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "oracle_document_id": float64(1)},
+		map[string]interface{}{"account": "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B", "oracle_document_id": float64(2)},
+	})
+
+	c.Check(result.EntireSet.Size, Equals, uint32(3))
+	c.Check(result.EntireSet.Mean.String(), Equals, "0.78")
+	c.Check(result.TrimmedSet.Size, Equals, uint32(1))
+	c.Check(result.Median.String(), Equals, "0.78")
+	c.Check(*result.LedgerCurrentIndex, Equals, uint32(25))
+	c.Check(result.Time, Equals, uint32(1724871860))
+}
+
+func (s *RemoteSuite) TestAccountObjectFilters(c *C) {
+	var types []interface{}
+	srv := scriptedServer(func(cmd map[string]interface{}) []string {
+		types = append(types, cmd["type"])
+		return []string{`{"id":$ID,"status":"success","type":"response","result":{"account":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","ledger_index":100,"validated":true,"account_objects":[` +
+			`{"LedgerEntryType":"Check","Account":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","Destination":"rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de","SendMax":"100000000","Sequence":2,"Flags":0,"OwnerNode":"0","DestinationNode":"0","index":"49647F0D748DC3FE26BDACBC57F251AADEFFF391403EC9BF87C97F67E9977FB0"},` +
+			`{"LedgerEntryType":"Ticket","Account":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","TicketSequence":3,"Flags":0,"OwnerNode":"0","index":"F78AC975CA66541A1CF6039EC1463687394E2AB1CF18BF76F8786D1493A22FFB"},` +
+			`{"LedgerEntryType":"DepositPreauth","Account":"rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8","Authorize":"rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de","Flags":0,"OwnerNode":"0","index":"4A255038CC3ADCC1A9C91509279B59908251728D0DAADB248FFE297D0F7E068C"}]}}`}
+	})
+	defer srv.Close()
+	r, err := NewRemote(wsURL(srv), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	account, err := data.NewAccountFromAddress("rsUiUMpnrgxQp24dJYZDhmV4bE3aBtQyt8")
+	c.Assert(err, IsNil)
+	checks, err := r.AccountChecks(*account, "validated")
+	c.Assert(err, IsNil)
+	c.Assert(checks, HasLen, 1)
+	c.Check(*checks[0].Sequence, Equals, uint32(2))
+	tickets, err := r.AccountTickets(*account, "validated")
+	c.Assert(err, IsNil)
+	c.Assert(tickets, HasLen, 1)
+	c.Check(*tickets[0].TicketSequence, Equals, uint32(3))
+	preauths, err := r.AccountDepositPreauths(*account, "validated")
+	c.Assert(err, IsNil)
+	c.Assert(preauths, HasLen, 1)
+	c.Check(preauths[0].Authorize.String(), Equals, "rEhxGqkqPPSxQ3P25J66ft5TwpzV14k2de")
+	c.Check(types, DeepEquals, []interface{}{"check", "ticket", "deposit_preauth"})
+}
+
+func (s *RemoteSuite) TestLedgerAccept(c *C) {
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		c.Check(cmd["command"], Equals, "ledger_accept")
+		return []string{`{"id":$ID,"status":"success","type":"response","result":{"ledger_current_index":7}}`}
+	})
+	defer server.Close()
+	r, err := NewRemote(wsURL(server), false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	current, err := r.LedgerAccept()
+	c.Assert(err, IsNil)
+	c.Check(current, Equals, uint32(7))
+}
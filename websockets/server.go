@@ -0,0 +1,401 @@
+package websockets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+type ServerInfoCommand struct {
+	*Command
+	Result *ServerInfoResult `json:"result,omitempty"`
+}
+
+type ServerInfoResult struct {
+	Info ServerInfo `json:"info"`
+}
+
+type ServerStateCommand struct {
+	*Command
+	Result *ServerStateResult `json:"result,omitempty"`
+}
+
+type ServerStateResult struct {
+	State ServerState `json:"state"`
+}
+
+type ManifestCommand struct {
+	*Command
+	PublicKey string          `json:"public_key"`
+	Result    *ManifestResult `json:"result,omitempty"`
+}
+
+type ManifestResult struct {
+	Requested string           `json:"requested"`
+	Details   *ManifestDetails `json:"details,omitempty"` // Absent when unknown
+	Manifest  []byte           `json:"manifest,omitempty"`
+}
+
+// Decode returns the manifest, which is not verified
+func (r *ManifestResult) Decode() (*data.Manifest, error) {
+	if len(r.Manifest) == 0 {
+		return nil, fmt.Errorf("No manifest of %s", r.Requested)
+	}
+	return data.ReadManifest(bytes.NewReader(r.Manifest))
+}
+
+type ManifestDetails struct {
+	Domain       string `json:"domain"`
+	EphemeralKey string `json:"ephemeral_key"`
+	MasterKey    string `json:"master_key"`
+	Sequence     uint32 `json:"seq"`
+}
+
+type ValidatorsCommand struct {
+	*Command
+	Result *ValidatorsResult `json:"result,omitempty"`
+}
+
+// Keys are node public keys in their base58 form
+type ValidatorsResult struct {
+	LocalStaticKeys      []string             `json:"local_static_keys"`
+	PublisherLists       []ValidatorList      `json:"publisher_lists"`
+	SigningKeys          map[string]string    `json:"signing_keys"`
+	TrustedValidatorKeys []string             `json:"trusted_validator_keys"`
+	ValidationQuorum     uint32               `json:"validation_quorum"`
+	ValidatorList        ValidatorListSummary `json:"validator_list"`
+}
+
+type ValidatorList struct {
+	Available    bool     `json:"available"`
+	Expiration   string   `json:"expiration"`
+	List         []string `json:"list"`
+	PublisherKey string   `json:"pubkey_publisher"`
+	Sequence     uint32   `json:"seq"`
+	URI          string   `json:"uri"`
+	Version      uint32   `json:"version"`
+}
+
+type ValidatorListSummary struct {
+	Count      uint32 `json:"count"`
+	Expiration string `json:"expiration"`
+	Status     string `json:"status"`
+	Threshold  uint32 `json:"validator_list_threshold"`
+}
+
+type ValidatorListSitesCommand struct {
+	*Command
+	Result *ValidatorListSitesResult `json:"result,omitempty"`
+}
+
+type ValidatorListSitesResult struct {
+	Sites []ValidatorSite `json:"validator_sites"`
+}
+
+type ValidatorSite struct {
+	URI                string `json:"uri"`
+	LastRefreshStatus  string `json:"last_refresh_status"`
+	LastRefreshTime    string `json:"last_refresh_time"`
+	LastRefreshMessage string `json:"last_refresh_message,omitempty"`
+	NextRefreshTime    string `json:"next_refresh_time"`
+	RefreshInterval    uint32 `json:"refresh_interval_min"`
+}
+
+type FeatureCommand struct {
+	*Command
+	Feature string         `json:"feature,omitempty"`
+	Result  *FeatureResult `json:"result,omitempty"`
+}
+
+// FeatureResult holds every amendment the server knows of, or just the
+// requested one.
+type FeatureResult struct {
+	Features Features
+}
+
+// Features maps amendment IDs to their status on the server
+type Features map[data.Hash256]Feature
+
+type Feature struct {
+	Name      string
+	Enabled   bool
+	Supported bool
+	Vetoed    bool
+	Obsolete  bool
+}
+
+// Enabled reports whether the named amendment is active on the ledger
+func (f Features) Enabled(name string) bool {
+	for _, feature := range f {
+		if feature.Name == name {
+			return feature.Enabled
+		}
+	}
+	return false
+}
+
+func (f *Feature) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Name      string          `json:"name"`
+		Enabled   bool            `json:"enabled"`
+		Supported bool            `json:"supported"`
+		Vetoed    json.RawMessage `json:"vetoed"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	f.Name, f.Enabled, f.Supported = raw.Name, raw.Enabled, raw.Supported
+	// Newer servers say "Obsolete" rather than true or false
+	switch string(raw.Vetoed) {
+	case "true":
+		f.Vetoed = true
+	case `"Obsolete"`:
+		f.Vetoed, f.Obsolete = true, true
+	}
+	return nil
+}
+
+// Every amendment is listed under "features", but a single one is keyed
+// by its ID at the top level alongside fields such as "status".
+func (r *FeatureResult) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if features, ok := raw["features"]; ok {
+		return json.Unmarshal(features, &r.Features)
+	}
+	r.Features = make(Features)
+	for key, value := range raw {
+		if len(key) != 64 {
+			continue
+		}
+		id, err := data.NewHash256(key)
+		if err != nil {
+			continue
+		}
+		var feature Feature
+		if err := json.Unmarshal(value, &feature); err != nil {
+			return err
+		}
+		r.Features[*id] = feature
+	}
+	return nil
+}
+
+type LedgerAcceptCommand struct {
+	*Command
+	Result *LedgerCurrentResult `json:"result,omitempty"`
+}
+
+// Fields common to server_info and server_state
+type ServerStatus struct {
+	BuildVersion        string            `json:"build_version"`
+	ClioVersion         string            `json:"clio_version,omitempty"` // Only from Clio
+	CompleteLedgers     data.LedgerRanges `json:"complete_ledgers"`
+	HostID              string            `json:"hostid"`
+	IOLatency           uint32            `json:"io_latency_ms"`
+	NetworkID           *uint32           `json:"network_id,omitempty"`
+	Peers               uint32            `json:"peers"`
+	PubKeyNode          string            `json:"pubkey_node"`
+	PubKeyValidator     string            `json:"pubkey_validator"`
+	ServerState         string            `json:"server_state"`
+	ServerStateDuration uint64            `json:"server_state_duration_us,string"`
+	Uptime              uint64            `json:"uptime"`
+	ValidationQuorum    uint32            `json:"validation_quorum"`
+	AmendmentBlocked    bool              `json:"amendment_blocked"`
+	ValidatedLedger     *ServerLedger     `json:"validated_ledger,omitempty"`
+	ClosedLedger        *ServerLedger     `json:"closed_ledger,omitempty"`
+}
+
+// ServerInfo is human readable, with load factors already scaled.
+type ServerInfo struct {
+	ServerStatus
+	LoadFactor float64 `json:"load_factor"`
+}
+
+// ServerState is machine readable, with unscaled load factors.
+type ServerState struct {
+	ServerStatus
+	LoadBase                uint64 `json:"load_base"`
+	LoadFactor              uint64 `json:"load_factor"`
+	LoadFactorFeeEscalation uint64 `json:"load_factor_fee_escalation"`
+	LoadFactorFeeQueue      uint64 `json:"load_factor_fee_queue"`
+	LoadFactorFeeReference  uint64 `json:"load_factor_fee_reference"`
+	LoadFactorServer        uint64 `json:"load_factor_server"`
+}
+
+// ServerLedger describes the last closed or validated ledger. The fees
+// and reserves are native values whether rippled sent XRP or drops.
+type ServerLedger struct {
+	Age              uint32
+	LedgerSequence   uint32
+	Hash             data.Hash256
+	CloseTime        *data.RippleTime
+	BaseFee          data.Value
+	ReserveBase      data.Value
+	ReserveIncrement data.Value
+}
+
+func (l *ServerLedger) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Age            uint32           `json:"age"`
+		Seq            uint32           `json:"seq"`
+		Hash           data.Hash256     `json:"hash"`
+		CloseTime      *data.RippleTime `json:"close_time"`
+		BaseFee        *uint64          `json:"base_fee"`
+		ReserveBase    *uint64          `json:"reserve_base"`
+		ReserveInc     *uint64          `json:"reserve_inc"`
+		BaseFeeXRP     *float64         `json:"base_fee_xrp"`
+		ReserveBaseXRP *float64         `json:"reserve_base_xrp"`
+		ReserveIncXRP  *float64         `json:"reserve_inc_xrp"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	l.Age, l.LedgerSequence, l.Hash, l.CloseTime = raw.Age, raw.Seq, raw.Hash, raw.CloseTime
+	for _, v := range []struct {
+		dest  *data.Value
+		drops *uint64
+		xrp   *float64
+	}{
+		{&l.BaseFee, raw.BaseFee, raw.BaseFeeXRP},
+		{&l.ReserveBase, raw.ReserveBase, raw.ReserveBaseXRP},
+		{&l.ReserveIncrement, raw.ReserveInc, raw.ReserveIncXRP},
+	} {
+		var drops int64
+		switch {
+		case v.drops != nil:
+			drops = int64(*v.drops)
+		case v.xrp != nil:
+			drops = int64(math.Round(*v.xrp * 1e6))
+		}
+		value, err := data.NewNativeValue(drops)
+		if err != nil {
+			return err
+		}
+		*v.dest = *value
+	}
+	return nil
+}
+
+// Synchronously requests the human readable status of the server
+func (r *Remote) ServerInfo() (*ServerInfo, error) {
+	return r.ServerInfoCtx(context.Background())
+}
+
+// ServerInfoCtx is like ServerInfo but gives up when ctx is done
+func (r *Remote) ServerInfoCtx(ctx context.Context) (*ServerInfo, error) {
+	cmd := &ServerInfoCommand{
+		Command: newCommand("server_info"),
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return &cmd.Result.Info, nil
+}
+
+// Synchronously requests the machine readable status of the server
+func (r *Remote) ServerState() (*ServerState, error) {
+	return r.ServerStateCtx(context.Background())
+}
+
+// ServerStateCtx is like ServerState but gives up when ctx is done
+func (r *Remote) ServerStateCtx(ctx context.Context) (*ServerState, error) {
+	cmd := &ServerStateCommand{
+		Command: newCommand("server_state"),
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return &cmd.Result.State, nil
+}
+
+// Synchronously requests the manifest of a validator
+func (r *Remote) Manifest(publicKey string) (*ManifestResult, error) {
+	return r.ManifestCtx(context.Background(), publicKey)
+}
+
+// ManifestCtx is like Manifest but gives up when ctx is done
+func (r *Remote) ManifestCtx(ctx context.Context, publicKey string) (*ManifestResult, error) {
+	cmd := &ManifestCommand{
+		Command:   newCommand("manifest"),
+		PublicKey: publicKey,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the validators trusted by the server (admin only)
+func (r *Remote) Validators() (*ValidatorsResult, error) {
+	return r.ValidatorsCtx(context.Background())
+}
+
+// ValidatorsCtx is like Validators but gives up when ctx is done
+func (r *Remote) ValidatorsCtx(ctx context.Context) (*ValidatorsResult, error) {
+	cmd := &ValidatorsCommand{
+		Command: newCommand("validators"),
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously requests the status of the sites the server fetches
+// validator lists from (admin only)
+func (r *Remote) ValidatorListSites() ([]ValidatorSite, error) {
+	return r.ValidatorListSitesCtx(context.Background())
+}
+
+// ValidatorListSitesCtx is like ValidatorListSites but gives up when ctx is done
+func (r *Remote) ValidatorListSitesCtx(ctx context.Context) ([]ValidatorSite, error) {
+	cmd := &ValidatorListSitesCommand{
+		Command: newCommand("validator_list_sites"),
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result.Sites, nil
+}
+
+// Synchronously requests the status of an amendment, given its name or
+// ID, or of every amendment when feature is empty
+func (r *Remote) Feature(feature string) (Features, error) {
+	return r.FeatureCtx(context.Background(), feature)
+}
+
+// FeatureCtx is like Feature but gives up when ctx is done
+func (r *Remote) FeatureCtx(ctx context.Context, feature string) (Features, error) {
+	cmd := &FeatureCommand{
+		Command: newCommand("feature"),
+		Feature: feature,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result.Features, nil
+}
+
+// Synchronously closes the open ledger of a server in standalone mode,
+// returning the index of the new open ledger (admin only)
+func (r *Remote) LedgerAccept() (uint32, error) {
+	return r.LedgerAcceptCtx(context.Background())
+}
+
+// LedgerAcceptCtx is like LedgerAccept but gives up when ctx is done
+func (r *Remote) LedgerAcceptCtx(ctx context.Context) (uint32, error) {
+	cmd := &LedgerAcceptCommand{
+		Command: newCommand("ledger_accept"),
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return 0, err
+	}
+	return cmd.Result.LedgerSequence, nil
+}
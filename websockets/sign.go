@@ -0,0 +1,145 @@
+package websockets
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+type SignCommand struct {
+	*Command
+	TxJson map[string]interface{} `json:"tx_json"`
+	Secret string                 `json:"secret"`
+	Result *SignResult            `json:"result,omitempty"`
+}
+
+type SignForCommand struct {
+	*Command
+	Account data.Account           `json:"account"`
+	TxJson  map[string]interface{} `json:"tx_json"`
+	Secret  string                 `json:"secret"`
+	Result  *SignResult            `json:"result,omitempty"`
+}
+
+type SignResult struct {
+	TxBlob string `json:"tx_blob"`
+	// Decoded from TxBlob, ready to Submit or, once enough
+	// accounts have signed for it, SubmitMultisigned
+	Tx data.Transaction `json:"-"`
+}
+
+// Wrapper to stop recursive unmarshalling
+type signResultJSON SignResult
+
+func (r *SignResult) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, (*signResultJSON)(r)); err != nil {
+		return err
+	}
+	if r.TxBlob == "" {
+		return nil
+	}
+	blob, err := hex.DecodeString(r.TxBlob)
+	if err != nil {
+		return err
+	}
+	if r.Tx, err = data.ReadTransaction(bytes.NewReader(blob)); err != nil {
+		return err
+	}
+	if h := r.Tx.GetHash(); h != nil {
+		hash, _, err := data.Raw(r.Tx)
+		if err != nil {
+			return err
+		}
+		copy(h.Bytes(), hash.Bytes())
+	}
+	return nil
+}
+
+// txJSON converts tx to the tx_json of a command. The hash can only be
+// known once signed, so it isn't sent, nor are a zero Fee and Sequence,
+// which leaves them for the server to fill in where it can.
+func txJSON(tx data.Transaction) (map[string]interface{}, error) {
+	b, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "hash")
+	if base := tx.GetBase(); base != nil {
+		if base.Fee.IsZero() {
+			delete(fields, "Fee")
+		}
+		if base.Sequence == 0 {
+			delete(fields, "Sequence")
+		}
+	}
+	return fields, nil
+}
+
+func (r *Remote) checkServerSigning() error {
+	if !r.options.AllowServerSigning {
+		return fmt.Errorf("Signing by the server sends it a secret, see RemoteOptions.AllowServerSigning")
+	}
+	return nil
+}
+
+// Synchronously has the server sign tx with secret, filling in a zero Fee
+// or Sequence. Only available when RemoteOptions.AllowServerSigning is set.
+func (r *Remote) Sign(tx data.Transaction, secret string) (*SignResult, error) {
+	return r.SignCtx(context.Background(), tx, secret)
+}
+
+// SignCtx is like Sign but gives up when ctx is done
+func (r *Remote) SignCtx(ctx context.Context, tx data.Transaction, secret string) (*SignResult, error) {
+	if err := r.checkServerSigning(); err != nil {
+		return nil, err
+	}
+	fields, err := txJSON(tx)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &SignCommand{
+		Command: newCommand("sign"),
+		TxJson:  fields,
+		Secret:  secret,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
+
+// Synchronously has the server add the signature of account, made with
+// secret, to the Signers of tx. Only available when
+// RemoteOptions.AllowServerSigning is set.
+func (r *Remote) SignFor(tx data.Transaction, account data.Account, secret string) (*SignResult, error) {
+	return r.SignForCtx(context.Background(), tx, account, secret)
+}
+
+// SignForCtx is like SignFor but gives up when ctx is done
+func (r *Remote) SignForCtx(ctx context.Context, tx data.Transaction, account data.Account, secret string) (*SignResult, error) {
+	if err := r.checkServerSigning(); err != nil {
+		return nil, err
+	}
+	fields, err := txJSON(tx)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &SignForCommand{
+		Command: newCommand("sign_for"),
+		Account: account,
+		TxJson:  fields,
+		Secret:  secret,
+	}
+	if err := r.send(ctx, cmd, cmd.Command); err != nil {
+		return nil, err
+	}
+	return cmd.Result, nil
+}
@@ -2,6 +2,7 @@ package websockets
 
 import (
 	"encoding/json"
+	"sync"
 
 	"github.com/kr-jaydeepp/ripple/data"
 )
@@ -48,19 +49,64 @@ func (s *ServerStreamMsg) TransactionCost() uint64 {
 	return (s.BaseFee * s.LoadFactor) / s.LoadBase
 }
 
+// Phases of consensus in consensus stream messages
+const (
+	ConsensusOpen      = "open"
+	ConsensusEstablish = "establish"
+	ConsensusAccepted  = "accepted"
+)
+
+// Fields from subscribed consensus stream messages, which are only sent
+// to admin connections
+type ConsensusStreamMsg struct {
+	Phase string `json:"consensus"`
+}
+
+// Actions of peer status stream messages
+const (
+	PeerClosingLedger  = "CLOSING_LEDGER"
+	PeerAcceptedLedger = "ACCEPTED_LEDGER"
+	PeerSwitchedLedger = "SWITCHED_LEDGER"
+	PeerLostSync       = "LOST_SYNC"
+)
+
+// Fields from subscribed peer status stream messages, which are only sent
+// to admin connections. The ledger fields are absent for some actions.
+type PeerStatusStreamMsg struct {
+	Action            string          `json:"action"`
+	Date              data.RippleTime `json:"date"`
+	LedgerHash        *data.Hash256   `json:"ledger_hash"`
+	LedgerSequence    uint32          `json:"ledger_index"`
+	LedgerSequenceMin uint32          `json:"ledger_index_min"`
+	LedgerSequenceMax uint32          `json:"ledger_index_max"`
+}
+
 // Map message types to the appropriate data structure
 var streamMessageFactory = map[string]func() interface{}{
-	"ledgerClosed": func() interface{} { return &LedgerStreamMsg{} },
-	"transaction":  func() interface{} { return &TransactionStreamMsg{} },
-	"serverStatus": func() interface{} { return &ServerStreamMsg{} },
-	"path_find":    func() interface{} { return &PathFindCreateResult{} },
+	"ledgerClosed":       func() interface{} { return &LedgerStreamMsg{} },
+	"transaction":        func() interface{} { return &TransactionStreamMsg{} },
+	"serverStatus":       func() interface{} { return &ServerStreamMsg{} },
+	"validationReceived": func() interface{} { return &ValidationStreamMsg{} },
+	"consensusPhase":     func() interface{} { return &ConsensusStreamMsg{} },
+	"peerStatusChange":   func() interface{} { return &PeerStatusStreamMsg{} },
 }
 
 type SubscribeCommand struct {
 	*Command
-	Streams []string                `json:"streams"`
-	Books   []OrderBookSubscription `json:"books,omitempty"`
-	Result  *SubscribeResult        `json:"result,omitempty"`
+	Streams          []string                `json:"streams"`
+	Books            []OrderBookSubscription `json:"books,omitempty"`
+	Accounts         []data.Account          `json:"accounts,omitempty"`
+	AccountsProposed []data.Account          `json:"accounts_proposed,omitempty"`
+	Result           *SubscribeResult        `json:"result,omitempty"`
+}
+
+type UnsubscribeCommand struct {
+	*Command
+	Streams          []string                `json:"streams,omitempty"`
+	Books            []OrderBookSubscription `json:"books,omitempty"`
+	Accounts         []data.Account          `json:"accounts,omitempty"`
+	AccountsProposed []data.Account          `json:"accounts_proposed,omitempty"`
+	Result           *struct{}               `json:"result,omitempty"`
 }
 
 type SubscribeResult struct {
@@ -74,15 +120,157 @@ type SubscribeResult struct {
 	Offers []data.OrderBookOffer
 }
 
+// ResubscribedMsg is sent on the Incoming channel after a reconnection,
+// once the subscriptions made on the previous connection, including those of
+// open Subscriptions, have been replayed.
+// Stream messages published while disconnected will have been missed.
+type ResubscribedMsg struct {
+	Streams          []string
+	Books            []OrderBookSubscription
+	Accounts         []data.Account
+	AccountsProposed []data.Account
+	Result           *SubscribeResult
+	Error            error
+}
+
+// subscriptions tracks everything successfully subscribed to, so that it
+// can be replayed when the connection is re-established.
+type subscriptions struct {
+	sync.Mutex
+	streams          []string
+	books            []OrderBookSubscription
+	accounts         []data.Account
+	accountsProposed []data.Account
+	handles          []*Subscription
+}
+
+func (s *subscriptions) add(streams []string, books []OrderBookSubscription) {
+	s.Lock()
+	defer s.Unlock()
+	s.streams = appendStreams(s.streams, streams...)
+	for _, book := range books {
+		if i := s.findBook(book); i >= 0 {
+			s.books[i] = book
+			continue
+		}
+		s.books = append(s.books, book)
+	}
+}
+
+// addAccounts tracks accounts subscribed to, either validated or proposed
+func (s *subscriptions) addAccounts(accounts []data.Account, proposed bool) {
+	s.Lock()
+	defer s.Unlock()
+	if proposed {
+		s.accountsProposed = appendAccounts(s.accountsProposed, accounts...)
+	} else {
+		s.accounts = appendAccounts(s.accounts, accounts...)
+	}
+}
+
+func (s *subscriptions) removeAccounts(accounts []data.Account, proposed bool) {
+	s.Lock()
+	defer s.Unlock()
+	tracked := &s.accounts
+	if proposed {
+		tracked = &s.accountsProposed
+	}
+	kept := (*tracked)[:0]
+	for _, existing := range *tracked {
+		if !containsAccount(accounts, existing) {
+			kept = append(kept, existing)
+		}
+	}
+	*tracked = kept
+}
+
+func (s *subscriptions) findBook(book OrderBookSubscription) int {
+	for i, existing := range s.books {
+		if existing.TakerGets == book.TakerGets && existing.TakerPays == book.TakerPays {
+			return i
+		}
+	}
+	return -1
+}
+
+// replay returns a command which restores every tracked subscription and
+// reports the outcome on incoming, or nil if there is nothing to restore.
+func (s *subscriptions) replay(incoming chan interface{}) Syncer {
+	s.Lock()
+	defer s.Unlock()
+	streams, books, accounts, proposed := s.wanted()
+	if len(streams) == 0 && len(books) == 0 && len(accounts) == 0 && len(proposed) == 0 {
+		return nil
+	}
+	return &resubscribeCommand{
+		SubscribeCommand: &SubscribeCommand{
+			Command:          newCommand("subscribe"),
+			Streams:          streams,
+			Books:            books,
+			Accounts:         accounts,
+			AccountsProposed: proposed,
+		},
+		incoming: incoming,
+	}
+}
+
+// resubscribeCommand is issued by the run loop itself, so Done and Fail,
+// which are also called from the run loop, may safely write to Incoming.
+type resubscribeCommand struct {
+	*SubscribeCommand
+	incoming chan interface{}
+}
+
+func (c *resubscribeCommand) msg() *ResubscribedMsg {
+	return &ResubscribedMsg{
+		Streams:          c.Streams,
+		Books:            c.Books,
+		Accounts:         c.Accounts,
+		AccountsProposed: c.AccountsProposed,
+	}
+}
+
+func (c *resubscribeCommand) Done() {
+	msg := c.msg()
+	if c.CommandError != nil {
+		msg.Error = c.CommandError
+	} else {
+		msg.Result = c.Result
+	}
+	c.incoming <- msg
+}
+
+func (c *resubscribeCommand) Fail(message string) {
+	c.Command.Fail(message)
+	msg := c.msg()
+	msg.Error = c.CommandError
+	c.incoming <- msg
+}
+
+func (c *resubscribeCommand) expire(err *TimeoutError) {
+	msg := c.msg()
+	msg.Error = err
+	c.incoming <- msg
+}
+
 // Wrapper to stop recursive unmarshalling
 type txStreamJSON TransactionStreamMsg
 
 func (msg *TransactionStreamMsg) UnmarshalJSON(b []byte) error {
 	var extract struct {
 		*txStreamJSON
-		MetaData *data.MetaData `json:"meta"`
+		MetaData *data.MetaData  `json:"meta"`
+		TxJSON   json.RawMessage `json:"tx_json"`
 	}
 	extract.txStreamJSON = (*txStreamJSON)(msg)
 	extract.MetaData = &msg.Transaction.MetaData
-	return json.Unmarshal(b, &extract)
+	if err := json.Unmarshal(b, &extract); err != nil {
+		return err
+	}
+	// Under api_version 2 the transaction is "tx_json", with its hash
+	// beside it, which is a form TransactionWithMetaData understands
+	if len(extract.TxJSON) > 0 {
+		return json.Unmarshal(b, &msg.Transaction)
+	}
+	return nil
 }
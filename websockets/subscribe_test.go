@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"testing"
 
+	"github.com/kr-jaydeepp/ripple/crypto"
 	"github.com/kr-jaydeepp/ripple/data"
 	. "gopkg.in/check.v1"
 )
@@ -119,6 +120,114 @@ func (s *MessagesSuite) TestServerStreamMsg(c *C) {
 	c.Assert(msg.LoadFactor, Equals, uint64(256))
 }
 
+func (s *MessagesSuite) TestPeerStatusStreamMsg(c *C) {
+	msg := streamMessageFactory["peerStatusChange"]().(*PeerStatusStreamMsg)
+	readResponseFile(c, msg, "testdata/peer_status_stream.json")
+
+	c.Assert(msg.Action, Equals, PeerClosingLedger)
+	c.Assert(msg.Date.Uint32(), Equals, uint32(508546525))
+	c.Assert(msg.LedgerHash.String(), Equals, "4D4CD9CD543F0C1EF023CC457F5BEFEA59EEF73E4552542D40E7C4FA08D3C320")
+	c.Assert(msg.LedgerSequence, Equals, uint32(18853106))
+	c.Assert(msg.LedgerSequenceMin, Equals, uint32(18852082))
+	c.Assert(msg.LedgerSequenceMax, Equals, uint32(18853106))
+}
+
+func (s *MessagesSuite) TestValidationStreamMsg(c *C) {
+	msg := streamMessageFactory["validationReceived"]().(*ValidationStreamMsg)
+	readResponseFile(c, msg, "testdata/validation_stream.json")
+
+	c.Assert(msg.LedgerSequence, Equals, uint32(6951500))
+	c.Assert(msg.SigningTime.Uint32(), Equals, uint32(454934438))
+	c.Assert(msg.Full, Equals, false)
+	c.Assert(msg.Validator(), Equals, "n9L81uNCaPgtUJfaHh89gmdvXKAmSt5Gdsw2g1iPWaPkAHW5Nm4C")
+
+	v, err := msg.Verify()
+	c.Assert(err, IsNil)
+	c.Assert(v.LedgerHash, Equals, msg.LedgerHash)
+	c.Assert(v.IsFull(), Equals, false)
+
+	msg.ValidationPublicKey = "n9KAa2zVWjPHgfzsE3iZ8HAbzJtPrnoh4H2M2HgE7dfqtvyEb1KJ"
+	_, err = msg.Verify()
+	c.Assert(err, ErrorMatches, "Validation is signed by n9L81u.* not n9KAa2.*")
+	msg.ValidationPublicKey = v.SigningPubKey.NodePublicKey()
+	msg.LedgerSequence++
+	_, err = msg.Verify()
+	c.Assert(err, ErrorMatches, "Validation is of ledger 6951500 .* not 6951501 .*")
+	msg.LedgerSequence--
+	msg.Data[len(msg.Data)-1]++
+	_, err = msg.Verify()
+	c.Assert(err, NotNil)
+}
+
+func (s *MessagesSuite) TestValidationQuorumManifests(c *C) {
+	master, err := crypto.NewEd25519Key([]byte("master"))
+	c.Assert(err, IsNil)
+	signing, err := crypto.NewECDSAKey([]byte("signing"))
+	c.Assert(err, IsNil)
+	manifest := &data.Manifest{Sequence: 1}
+	c.Assert(data.SignManifest(manifest, master, signing), IsNil)
+	b, err := manifest.MarshalBinary()
+	c.Assert(err, IsNil)
+	result := &ManifestResult{Manifest: b}
+	decoded, err := result.Decode()
+	c.Assert(err, IsNil)
+	manifests := data.NewManifestCache()
+	applied, err := manifests.Apply(decoded)
+	c.Assert(err, IsNil)
+	c.Assert(applied, Equals, true)
+
+	v := &data.Validation{Flags: data.FullValidation, LedgerSequence: 7}
+	c.Assert(data.Sign(v, signing, nil), IsNil)
+	msg := &ValidationStreamMsg{
+		LedgerSequence:      7,
+		ValidationPublicKey: v.SigningPubKey.NodePublicKey(),
+		MasterKey:           "n9KAa2zVWjPHgfzsE3iZ8HAbzJtPrnoh4H2M2HgE7dfqtvyEb1KJ", // Not to be believed
+	}
+	_, msg.Data, err = data.Raw(v)
+	c.Assert(err, IsNil)
+
+	quorum, err := NewValidationQuorum(1, []string{manifest.PublicKey.NodePublicKey()})
+	c.Assert(err, IsNil)
+	count, reached, err := quorum.Add(msg)
+	c.Assert(err, IsNil)
+	c.Check(count, Equals, 0)
+	c.Check(reached, Equals, false)
+	// Even when the server names the trusted master key
+	msg.MasterKey = manifest.PublicKey.NodePublicKey()
+	count, reached, err = quorum.Add(msg)
+	c.Assert(err, IsNil)
+	c.Check(count, Equals, 0)
+	c.Check(reached, Equals, false)
+	quorum.UseManifests(manifests)
+	count, reached, err = quorum.Add(msg)
+	c.Assert(err, IsNil)
+	c.Check(count, Equals, 1)
+	c.Check(reached, Equals, true)
+
+	// A trusted signing key needs no manifest
+	bySigningKey, err := NewValidationQuorum(1, []string{msg.ValidationPublicKey})
+	c.Assert(err, IsNil)
+	count, reached, err = bySigningKey.Add(msg)
+	c.Assert(err, IsNil)
+	c.Check(count, Equals, 1)
+	c.Check(reached, Equals, true)
+
+	_, err = (&ManifestResult{Requested: "nHB"}).Decode()
+	c.Check(err, ErrorMatches, "No manifest of nHB")
+}
+
+func (s *MessagesSuite) TestTransactionStreamMsgAPIVersion2(c *C) {
+	v1 := streamMessageFactory["transaction"]().(*TransactionStreamMsg)
+	readResponseFile(c, v1, "testdata/transactions_stream.json")
+	v2 := streamMessageFactory["transaction"]().(*TransactionStreamMsg)
+	readResponseFile(c, v2, "testdata/transactions_stream_v2.json")
+	// Which also gives the transaction its ledger
+	c.Check(v2.Transaction.LedgerSequence, Equals, v1.LedgerSequence)
+	v1.Transaction.LedgerSequence = v1.LedgerSequence
+	c.Check(v2, DeepEquals, v1)
+	c.Check(v2.Transaction.GetHash().String(), Equals, "25174B56C40B090D4AFCDAC3F07DCCF8A49A096D62CE1CE6864A8624F790F980")
+}
+
 func (s *MessagesSuite) TestProposedTransactionStreamMsg(c *C) {
 	msg := streamMessageFactory["transaction"]().(*TransactionStreamMsg)
 	readResponseFile(c, msg, "testdata/proposed_transaction_stream.json")
@@ -144,6 +253,91 @@ func (s *MessagesSuite) TestProposedTransactionStreamMsg(c *C) {
 	c.Assert(offer.TakerPays.String(), Equals, "4285.465077979/CNY/razqQKzJRdB4UxFPWf5NEpEG3WMkmwgcXA")
 }
 
+func (s *MessagesSuite) TestResubscribe(c *C) {
+	var subs subscriptions
+	incoming := make(chan interface{}, 1)
+	c.Assert(subs.replay(incoming), IsNil)
+
+	usd := data.Asset{Currency: "USD", Issuer: "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"}
+	xrp := data.Asset{Currency: "XRP"}
+	subs.add([]string{"ledger", "transactions"}, nil)
+	subs.add([]string{"ledger", "server"}, []OrderBookSubscription{{TakerGets: usd, TakerPays: xrp}})
+	subs.add(nil, []OrderBookSubscription{{TakerGets: usd, TakerPays: xrp, Both: true}})
+
+	cmd := subs.replay(incoming).(*resubscribeCommand)
+	c.Assert(cmd.Streams, DeepEquals, []string{"ledger", "transactions", "server"})
+	c.Assert(cmd.Books, HasLen, 1)
+	c.Assert(cmd.Books[0].Both, Equals, true)
+
+	readResponseFile(c, cmd, "testdata/subscribe_ledger.json")
+	cmd.Done()
+	msg := (<-incoming).(*ResubscribedMsg)
+	c.Assert(msg.Error, IsNil)
+	c.Assert(msg.Result.LedgerSequence, Equals, uint32(6959228))
+
+	cmd.Fail("ws: server disconnected")
+	msg = (<-incoming).(*ResubscribedMsg)
+	c.Assert(msg.Error, NotNil)
+}
+
+func (s *MessagesSuite) TestResubscribeAccounts(c *C) {
+	var subs subscriptions
+	alice, err := data.NewAccountFromAddress("rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B")
+	c.Assert(err, IsNil)
+	bob, err := data.NewAccountFromAddress("r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59")
+	c.Assert(err, IsNil)
+
+	subs.addAccounts([]data.Account{*alice, *bob}, false)
+	subs.addAccounts([]data.Account{*alice}, false)
+	subs.addAccounts([]data.Account{*bob}, true)
+	subs.removeAccounts([]data.Account{*alice}, true)
+	cmd := subs.replay(make(chan interface{}, 1)).(*resubscribeCommand)
+	c.Assert(cmd.Accounts, DeepEquals, []data.Account{*alice, *bob})
+	c.Assert(cmd.AccountsProposed, DeepEquals, []data.Account{*bob})
+
+	subs.removeAccounts([]data.Account{*alice, *bob}, false)
+	subs.removeAccounts([]data.Account{*bob}, true)
+	c.Assert(subs.replay(make(chan interface{}, 1)), IsNil)
+
+	b, err := json.Marshal(&UnsubscribeCommand{
+		Command:          &Command{Id: 4, Name: "unsubscribe"},
+		AccountsProposed: []data.Account{*bob},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `{"id":4,"command":"unsubscribe","accounts_proposed":["r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59"]}`)
+}
+
+func (s *MessagesSuite) TestBookSubscription(c *C) {
+	msg := streamMessageFactory["transaction"]().(*TransactionStreamMsg)
+	readResponseFile(c, msg, "testdata/transactions_stream.json")
+	cny := data.Asset{Currency: "CNY", Issuer: "razqQKzJRdB4UxFPWf5NEpEG3WMkmwgcXA"}
+	xrp := data.Asset{Currency: "XRP"}
+	asks := OrderBookSubscription{TakerPays: cny, TakerGets: xrp}
+	bids := OrderBookSubscription{TakerPays: xrp, TakerGets: cny}
+	both := OrderBookSubscription{TakerPays: xrp, TakerGets: cny, Both: true}
+	for _, test := range []struct {
+		book  OrderBookSubscription
+		wants bool
+	}{{asks, true}, {bids, false}, {both, true}} {
+		h := &Subscription{options: SubscriptionOptions{Books: []OrderBookSubscription{test.book}}}
+		c.Check(h.wants(msg), Equals, test.wants, Commentf("%+v", test.book))
+	}
+
+	var subs subscriptions
+	first := &Subscription{options: SubscriptionOptions{Books: []OrderBookSubscription{asks, bids}}}
+	second := &Subscription{options: SubscriptionOptions{Books: []OrderBookSubscription{asks}}}
+	subs.open(first)
+	subs.open(second)
+	cmd := subs.replay(make(chan interface{}, 1)).(*resubscribeCommand)
+	c.Check(cmd.Books, DeepEquals, []OrderBookSubscription{asks, bids})
+	unsubscribe, ok := subs.release(first)
+	c.Assert(ok, Equals, true)
+	c.Check(unsubscribe.Books, DeepEquals, []OrderBookSubscription{bids})
+	unsubscribe, ok = subs.release(second)
+	c.Assert(ok, Equals, true)
+	c.Check(unsubscribe.Books, DeepEquals, []OrderBookSubscription{asks})
+}
+
 func BenchmarkProposedTransactionStreamJSON(b *testing.B) {
 	bites, err := ioutil.ReadFile("testdata/proposed_transaction_stream.json")
 	if err != nil {
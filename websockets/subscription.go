@@ -0,0 +1,459 @@
+package websockets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// Capacity of each Subscription channel when SubscriptionOptions.Buffer is zero
+const DefaultSubscriptionBuffer = 100
+
+// SubscriptionOptions selects the streams and accounts of a Subscription
+type SubscriptionOptions struct {
+	Ledger               bool
+	Transactions         bool
+	TransactionsProposed bool
+	Server               bool
+	Validations          bool
+	Consensus            bool // Admin only
+	PeerStatus           bool // Admin only
+	Accounts             []data.Account
+	AccountsProposed     []data.Account
+
+	// The validated transactions which change the offers in these books
+	// arrive on the Transactions channel. The snapshots asked for are in
+	// the Result.
+	Books []OrderBookSubscription
+
+	// Capacity of each channel. Zero means DefaultSubscriptionBuffer.
+	Buffer int
+}
+
+func (o *SubscriptionOptions) streams() []string {
+	streams := []string{}
+	if o.Ledger {
+		streams = append(streams, "ledger")
+	}
+	if o.Transactions {
+		streams = append(streams, "transactions")
+	}
+	if o.TransactionsProposed {
+		streams = append(streams, "transactions_proposed")
+	}
+	if o.Server {
+		streams = append(streams, "server")
+	}
+	if o.Validations {
+		streams = append(streams, "validations")
+	}
+	if o.Consensus {
+		streams = append(streams, "consensus")
+	}
+	if o.PeerStatus {
+		streams = append(streams, "peer_status")
+	}
+	return streams
+}
+
+// Subscription receives the messages of its own streams and accounts, which
+// no longer arrive on the Incoming channel. Only the channels needed for the
+// requested streams are made, the others are nil. A slow reader holds up the
+// Remote, just as for Incoming. All channels are closed by Close, or when
+// the connection is lost and not reconnected.
+type Subscription struct {
+	Ledgers      chan *LedgerStreamMsg
+	Transactions chan *TransactionStreamMsg
+	Server       chan *ServerStreamMsg
+	Validations  chan *ValidationStreamMsg
+	Consensus    chan *ConsensusStreamMsg
+	PeerStatus   chan *PeerStatusStreamMsg
+	Result       *SubscribeResult
+
+	remote  *Remote
+	options SubscriptionOptions
+	mu      sync.Mutex
+	closed  bool
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Synchronously subscribe to streams and accounts, which are then received
+// asynchronously on the channels of the returned Subscription
+func (r *Remote) SubscribeTo(options SubscriptionOptions) (*Subscription, error) {
+	return r.SubscribeToCtx(context.Background(), options)
+}
+
+// SubscribeToCtx is like SubscribeTo but gives up when ctx is done
+func (r *Remote) SubscribeToCtx(ctx context.Context, options SubscriptionOptions) (*Subscription, error) {
+	options.Accounts = append([]data.Account(nil), options.Accounts...)
+	options.AccountsProposed = append([]data.Account(nil), options.AccountsProposed...)
+	options.Books = append([]OrderBookSubscription(nil), options.Books...)
+	cmd := &SubscribeCommand{
+		Command:          newCommand("subscribe"),
+		Streams:          options.streams(),
+		Books:            options.Books,
+		Accounts:         options.Accounts,
+		AccountsProposed: options.AccountsProposed,
+	}
+	if len(cmd.Streams) == 0 && len(cmd.Books) == 0 && len(cmd.Accounts) == 0 && len(cmd.AccountsProposed) == 0 {
+		return nil, fmt.Errorf("Nothing to subscribe to")
+	}
+	buffer := options.Buffer
+	if buffer <= 0 {
+		buffer = DefaultSubscriptionBuffer
+	}
+	s := &Subscription{
+		remote:  r,
+		options: options,
+		done:    make(chan struct{}),
+	}
+	if options.Ledger {
+		s.Ledgers = make(chan *LedgerStreamMsg, buffer)
+	}
+	if options.Transactions || options.TransactionsProposed || len(options.Accounts) > 0 || len(options.AccountsProposed) > 0 || len(options.Books) > 0 {
+		s.Transactions = make(chan *TransactionStreamMsg, buffer)
+	}
+	if options.Server {
+		s.Server = make(chan *ServerStreamMsg, buffer)
+	}
+	if options.Validations {
+		s.Validations = make(chan *ValidationStreamMsg, buffer)
+	}
+	if options.Consensus {
+		s.Consensus = make(chan *ConsensusStreamMsg, buffer)
+	}
+	if options.PeerStatus {
+		s.PeerStatus = make(chan *PeerStatusStreamMsg, buffer)
+	}
+
+	// Registered first, so that no message following the response is missed
+	r.subscriptions.open(s)
+	err := r.send(ctx, cmd, cmd.Command)
+	switch {
+	case err != nil:
+	case options.Ledger && cmd.Result.LedgerStreamMsg == nil:
+		err = fmt.Errorf("Missing ledger subscribe response")
+	case options.Server && cmd.Result.ServerStreamMsg == nil:
+		err = fmt.Errorf("Missing server subscribe response")
+	}
+	if err != nil {
+		r.subscriptions.release(s)
+		s.close()
+		return nil, err
+	}
+	s.Result = cmd.Result
+	return s, nil
+}
+
+// Synchronously unsubscribe from whatever is not wanted by another
+// Subscription, or by the Subscribe methods, and close the channels.
+func (s *Subscription) Close() error {
+	return s.CloseCtx(context.Background())
+}
+
+// CloseCtx is like Close but gives up when ctx is done
+func (s *Subscription) CloseCtx(ctx context.Context) error {
+	cmd, ok := s.remote.subscriptions.release(s)
+	if !ok {
+		return nil
+	}
+	s.close()
+	if cmd == nil {
+		return nil
+	}
+	// Nothing needs unsubscribing from a closed Remote
+	if err := s.remote.send(ctx, cmd, cmd.Command); err != ErrClosed {
+		return err
+	}
+	return nil
+}
+
+// close is safe to call from both the run loop and the owner
+func (s *Subscription) close() {
+	s.once.Do(func() {
+		close(s.done) // Unblocks a pending deliver
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.closed = true
+		if s.Ledgers != nil {
+			close(s.Ledgers)
+		}
+		if s.Transactions != nil {
+			close(s.Transactions)
+		}
+		if s.Server != nil {
+			close(s.Server)
+		}
+		if s.Validations != nil {
+			close(s.Validations)
+		}
+		if s.Consensus != nil {
+			close(s.Consensus)
+		}
+		if s.PeerStatus != nil {
+			close(s.PeerStatus)
+		}
+	})
+}
+
+// wants reports whether a stream message belongs to this Subscription.
+// Proposed streams also carry the validated transactions.
+func (s *Subscription) wants(msg interface{}) bool {
+	o := &s.options
+	switch msg := msg.(type) {
+	case *LedgerStreamMsg:
+		return o.Ledger
+	case *ServerStreamMsg:
+		return o.Server
+	case *ValidationStreamMsg:
+		return o.Validations
+	case *ConsensusStreamMsg:
+		return o.Consensus
+	case *PeerStatusStreamMsg:
+		return o.PeerStatus
+	case *TransactionStreamMsg:
+		switch {
+		case o.TransactionsProposed, o.Transactions && msg.Validated:
+			return true
+		case msg.Validated && touches(msg, o.Accounts):
+			return true
+		case msg.Validated && touchesBooks(msg, o.Books):
+			return true
+		default:
+			return touches(msg, o.AccountsProposed)
+		}
+	}
+	return false
+}
+
+func (s *Subscription) deliver(msg interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	switch msg := msg.(type) {
+	case *LedgerStreamMsg:
+		select {
+		case s.Ledgers <- msg:
+		case <-s.done:
+		}
+	case *TransactionStreamMsg:
+		select {
+		case s.Transactions <- msg:
+		case <-s.done:
+		}
+	case *ServerStreamMsg:
+		select {
+		case s.Server <- msg:
+		case <-s.done:
+		}
+	case *ValidationStreamMsg:
+		select {
+		case s.Validations <- msg:
+		case <-s.done:
+		}
+	case *ConsensusStreamMsg:
+		select {
+		case s.Consensus <- msg:
+		case <-s.done:
+		}
+	case *PeerStatusStreamMsg:
+		select {
+		case s.PeerStatus <- msg:
+		case <-s.done:
+		}
+	}
+}
+
+// touches reports whether a transaction affects any of accounts. Proposed
+// transactions have no metadata, so their sender is checked as well.
+func touches(msg *TransactionStreamMsg, accounts []data.Account) bool {
+	tx := &msg.Transaction
+	for _, account := range accounts {
+		if tx.Affects(account) {
+			return true
+		}
+		if tx.Transaction != nil && tx.GetBase() != nil && tx.GetBase().Account == account {
+			return true
+		}
+	}
+	return false
+}
+
+// touchesBooks reports whether a transaction changes an offer in any of
+// books, or in the reverse of those which want both sides
+func touchesBooks(msg *TransactionStreamMsg, books []OrderBookSubscription) bool {
+	if len(books) == 0 {
+		return false
+	}
+	for changed := range msg.Transaction.OfferChanges() {
+		for _, book := range books {
+			switch {
+			case changed.Pays == book.TakerPays && changed.Gets == book.TakerGets:
+				return true
+			case book.Both && changed.Pays == book.TakerGets && changed.Gets == book.TakerPays:
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *subscriptions) open(h *Subscription) {
+	s.Lock()
+	defer s.Unlock()
+	s.handles = append(s.handles, h)
+}
+
+// release forgets h and returns a command for whatever only h wanted,
+// which is nil when there is nothing to unsubscribe from. The bool
+// reports whether h was still open.
+func (s *subscriptions) release(h *Subscription) (*UnsubscribeCommand, bool) {
+	s.Lock()
+	defer s.Unlock()
+	found := false
+	kept := s.handles[:0]
+	for _, existing := range s.handles {
+		if existing == h {
+			found = true
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	for i := len(kept); i < len(s.handles); i++ {
+		s.handles[i] = nil
+	}
+	s.handles = kept
+	if !found {
+		return nil, false
+	}
+
+	streams, books, accounts, proposed := s.wanted()
+	cmd := &UnsubscribeCommand{Command: newCommand("unsubscribe")}
+	for _, stream := range h.options.streams() {
+		if !containsStream(streams, stream) {
+			cmd.Streams = append(cmd.Streams, stream)
+		}
+	}
+	for _, book := range h.options.Books {
+		if !containsBook(books, book) {
+			cmd.Books = append(cmd.Books, book)
+		}
+	}
+	for _, account := range h.options.Accounts {
+		if !containsAccount(accounts, account) {
+			cmd.Accounts = append(cmd.Accounts, account)
+		}
+	}
+	for _, account := range h.options.AccountsProposed {
+		if !containsAccount(proposed, account) {
+			cmd.AccountsProposed = append(cmd.AccountsProposed, account)
+		}
+	}
+	if len(cmd.Streams) == 0 && len(cmd.Books) == 0 && len(cmd.Accounts) == 0 && len(cmd.AccountsProposed) == 0 {
+		return nil, true
+	}
+	return cmd, true
+}
+
+// closeAll is called when the connection is lost for good
+func (s *subscriptions) closeAll() {
+	s.Lock()
+	handles := s.handles
+	s.handles = nil
+	s.Unlock()
+	for _, h := range handles {
+		h.close()
+	}
+}
+
+// deliver passes a stream message to every Subscription which wants it
+// and reports whether there were any.
+func (s *subscriptions) deliver(msg interface{}) bool {
+	s.Lock()
+	handles := append([]*Subscription(nil), s.handles...)
+	s.Unlock()
+	delivered := false
+	for _, h := range handles {
+		if h.wants(msg) {
+			h.deliver(msg)
+			delivered = true
+		}
+	}
+	return delivered
+}
+
+// wanted returns the union of everything subscribed to, both by the
+// Subscribe methods and by open Subscriptions. The lock must be held.
+func (s *subscriptions) wanted() (streams []string, books []OrderBookSubscription, accounts, proposed []data.Account) {
+	streams = appendStreams(nil, s.streams...)
+	books = appendBooks(nil, s.books...)
+	accounts = appendAccounts(nil, s.accounts...)
+	proposed = appendAccounts(nil, s.accountsProposed...)
+	for _, h := range s.handles {
+		streams = appendStreams(streams, h.options.streams()...)
+		books = appendBooks(books, h.options.Books...)
+		accounts = appendAccounts(accounts, h.options.Accounts...)
+		proposed = appendAccounts(proposed, h.options.AccountsProposed...)
+	}
+	return
+}
+
+func containsStream(streams []string, stream string) bool {
+	for _, existing := range streams {
+		if existing == stream {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAccount(accounts []data.Account, account data.Account) bool {
+	for _, existing := range accounts {
+		if existing == account {
+			return true
+		}
+	}
+	return false
+}
+
+// containsBook compares only the assets of the books
+func containsBook(books []OrderBookSubscription, book OrderBookSubscription) bool {
+	for _, existing := range books {
+		if existing.TakerGets == book.TakerGets && existing.TakerPays == book.TakerPays {
+			return true
+		}
+	}
+	return false
+}
+
+func appendBooks(books []OrderBookSubscription, more ...OrderBookSubscription) []OrderBookSubscription {
+	for _, book := range more {
+		if !containsBook(books, book) {
+			books = append(books, book)
+		}
+	}
+	return books
+}
+
+func appendStreams(streams []string, more ...string) []string {
+	for _, stream := range more {
+		if !containsStream(streams, stream) {
+			streams = append(streams, stream)
+		}
+	}
+	return streams
+}
+
+func appendAccounts(accounts []data.Account, more ...data.Account) []data.Account {
+	for _, account := range more {
+		if !containsAccount(accounts, account) {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts
+}
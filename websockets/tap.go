@@ -0,0 +1,58 @@
+package websockets
+
+import "time"
+
+// Direction is which way a Frame went
+type Direction int
+
+const (
+	FrameSent Direction = iota
+	FrameReceived
+)
+
+func (d Direction) String() string {
+	if d == FrameReceived {
+		return "received"
+	}
+	return "sent"
+}
+
+// Frame is a message sent to or received from the server, as the raw JSON
+// which went over the connection. Pings and pongs aren't Frames.
+type Frame struct {
+	Direction Direction
+	At        time.Time
+	Message   []byte
+}
+
+// Tap is given every Frame, for audit logging, debugging or recording a
+// session. It is called by the goroutines which write and read the
+// connection, which wait for it, so it should be quick, and it mustn't
+// modify the Message. Frames include the secrets sent by Sign and SignFor.
+type Tap interface {
+	Frame(Frame)
+}
+
+// TapFunc adapts a function to a Tap
+type TapFunc func(Frame)
+
+func (f TapFunc) Frame(frame Frame) {
+	f(frame)
+}
+
+// TapChannel is a Tap which sends every Frame on the channel, dropping
+// those it has no room for rather than holding up the connection
+type TapChannel chan Frame
+
+func (c TapChannel) Frame(frame Frame) {
+	select {
+	case c <- frame:
+	default:
+	}
+}
+
+func (r *Remote) tap(direction Direction, message []byte) {
+	if r.options.Tap != nil {
+		r.options.Tap.Frame(Frame{Direction: direction, At: time.Now(), Message: message})
+	}
+}
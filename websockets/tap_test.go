@@ -0,0 +1,45 @@
+package websockets
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type TapSuite struct{}
+
+var _ = Suite(&TapSuite{})
+
+func (s *TapSuite) TestTap(c *C) {
+	server := scriptedServer(func(cmd map[string]interface{}) []string {
+		return []string{
+			`{"type":"ledgerClosed","ledger_index":7}`,
+			`{"id":$ID,"status":"success","type":"response","result":{}}`,
+		}
+	})
+	defer server.Close()
+	frames := make(TapChannel, 3)
+	r, err := NewRemoteWithOptions(wsURL(server), RemoteOptions{Tap: frames})
+	c.Assert(err, IsNil)
+	defer r.Close()
+	_, err = r.Fee()
+	c.Assert(err, IsNil)
+
+	var sent, stream, response map[string]interface{}
+	for _, f := range []struct {
+		direction Direction
+		message   *map[string]interface{}
+	}{{FrameSent, &sent}, {FrameReceived, &stream}, {FrameReceived, &response}} {
+		frame := <-frames
+		c.Check(frame.Direction, Equals, f.direction)
+		c.Check(frame.At.IsZero(), Equals, false)
+		c.Assert(json.Unmarshal(frame.Message, f.message), IsNil)
+	}
+	c.Check(sent["command"], Equals, "fee")
+	c.Check(stream["type"], Equals, "ledgerClosed")
+	c.Check(response["id"], Equals, sent["id"])
+	c.Check(FrameReceived.String(), Equals, "received")
+
+	// A full TapChannel drops frames rather than wait
+	TapChannel(make(chan Frame)).Frame(Frame{})
+}
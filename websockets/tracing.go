@@ -0,0 +1,59 @@
+package websockets
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// Tracer is told of each command sent by a ctx-aware method, such as
+// AccountInfoCtx, so that it can be traced as part of whatever ctx belongs
+// to. The methods without a ctx use context.Background(). The tracing
+// package implements Tracer with OpenTelemetry.
+type Tracer interface {
+	// StartCommand is called before the command is sent and returns the
+	// ctx to send it with, and a function to call with its error, or
+	// nil, once it is done
+	StartCommand(ctx context.Context, cmd CommandInfo) (context.Context, func(error))
+}
+
+// CommandInfo describes a command to a Tracer
+type CommandInfo struct {
+	Name        string // e.g. "account_info"
+	Account     string // The account asked about, if any
+	LedgerIndex string // The ledger asked about, if any, e.g. "validated" or "90000000"
+}
+
+// NewCommandInfo describes v, a command such as an *AccountInfoCommand,
+// from its "command", "account" and "ledger_index" or "ledger" fields
+func NewCommandInfo(v interface{}) CommandInfo {
+	var info CommandInfo
+	if c, ok := v.(commander); ok {
+		info.Name = c.command().Name
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return info
+	}
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Field(i)
+		if !f.CanInterface() {
+			continue
+		}
+		name, _, _ := strings.Cut(rv.Type().Field(i).Tag.Get("json"), ",")
+		switch name {
+		case "account":
+			if account, ok := f.Interface().(data.Account); ok && !account.IsZero() {
+				info.Account = account.String()
+			}
+		case "ledger_index", "ledger":
+			if f.Kind() == reflect.Interface && !f.IsNil() {
+				info.LedgerIndex = fmt.Sprint(f.Interface())
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,71 @@
+// Package tracing traces the commands of websockets.Remotes and
+// rpc.Clients with OpenTelemetry.
+//
+// Each command is a client span, a child of any span in the ctx given to
+// the ctx-aware method which sent it:
+//
+//	remote, err := websockets.NewRemoteWithOptions(endpoint, websockets.RemoteOptions{
+//		Tracer: tracing.NewTracer(nil),
+//	})
+//	...
+//	info, err := remote.AccountInfoCtx(ctx, account)
+package tracing
+
+import (
+	"context"
+
+	"github.com/kr-jaydeepp/ripple/websockets"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/kr-jaydeepp/ripple/websockets/tracing"
+
+// The attributes of a command span
+const (
+	CommandKey     = attribute.Key("xrpl.command")
+	AccountKey     = attribute.Key("xrpl.account")
+	LedgerIndexKey = attribute.Key("xrpl.ledger_index")
+	ErrorKey       = attribute.Key("xrpl.error") // The error rippled returned, e.g. "actNotFound"
+)
+
+// Tracer is a websockets.Tracer which starts a span for each command
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+var _ websockets.Tracer = (*Tracer)(nil)
+
+// NewTracer returns a Tracer with spans from provider, or the global
+// TracerProvider if provider is nil
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+// StartCommand starts a span named after the command, e.g. "account_info",
+// which ends as an error if the command fails
+func (t *Tracer) StartCommand(ctx context.Context, cmd websockets.CommandInfo) (context.Context, func(error)) {
+	attributes := []attribute.KeyValue{CommandKey.String(cmd.Name)}
+	if cmd.Account != "" {
+		attributes = append(attributes, AccountKey.String(cmd.Account))
+	}
+	if cmd.LedgerIndex != "" {
+		attributes = append(attributes, LedgerIndexKey.String(cmd.LedgerIndex))
+	}
+	ctx, span := t.tracer.Start(ctx, cmd.Name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	return ctx, func(err error) {
+		if err != nil {
+			if e, ok := websockets.AsCommandError(err); ok {
+				span.SetAttributes(ErrorKey.String(e.Name))
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type TracingSuite struct{}
+
+var _ = Suite(&TracingSuite{})
+
+// server answers account_lines with no lines and anything else with
+// actNotFound
+func server() *httptest.Server {
+	var upgrader websocket.Upgrader
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			var cmd map[string]interface{}
+			if err := ws.ReadJSON(&cmd); err != nil {
+				return
+			}
+			reply := `{"id":$ID,"status":"error","type":"response","error":"actNotFound","error_code":19,"error_message":"Account not found."}`
+			if cmd["command"] == "account_lines" {
+				reply = `{"id":$ID,"status":"success","type":"response","result":{"account":"r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59","lines":[]}}`
+			}
+			if err := ws.WriteMessage(websocket.TextMessage, []byte(strings.Replace(reply, "$ID", fmt.Sprint(cmd["id"]), -1))); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	m := make(map[attribute.Key]string)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value.Emit()
+	}
+	return m
+}
+
+func (s *TracingSuite) TestSpans(c *C) {
+	srv := server()
+	defer srv.Close()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	r, err := websockets.NewRemoteWithOptions("ws"+strings.TrimPrefix(srv.URL, "http"), websockets.RemoteOptions{
+		Tracer: NewTracer(provider),
+	})
+	c.Assert(err, IsNil)
+	defer r.Close()
+	account, err := data.NewAccountFromAddress("r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59")
+	c.Assert(err, IsNil)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	_, err = r.AccountLinesCtx(ctx, *account, "validated")
+	c.Assert(err, IsNil)
+	_, err = r.AccountInfoCtx(ctx, *account)
+	c.Assert(websockets.IsNotFound(err), Equals, true)
+	parent.End()
+
+	spans := recorder.Ended()
+	c.Assert(spans, HasLen, 3)
+	lines, info := spans[0], spans[1]
+	for _, span := range []sdktrace.ReadOnlySpan{lines, info} {
+		c.Check(span.Parent().SpanID(), Equals, parent.SpanContext().SpanID())
+		c.Check(span.SpanKind(), Equals, trace.SpanKindClient)
+	}
+
+	c.Check(lines.Name(), Equals, "account_lines")
+	c.Check(lines.Status().Code, Equals, codes.Unset)
+	c.Check(attributes(lines), DeepEquals, map[attribute.Key]string{
+		CommandKey:     "account_lines",
+		AccountKey:     "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59",
+		LedgerIndexKey: "validated",
+	})
+
+	c.Check(info.Name(), Equals, "account_info")
+	c.Check(info.Status().Code, Equals, codes.Error)
+	c.Check(info.Status().Description, Equals, "actNotFound 19 Account not found.")
+	c.Check(attributes(info)[ErrorKey], Equals, "actNotFound")
+	c.Check(info.Events(), HasLen, 1)
+}
@@ -0,0 +1,198 @@
+package websockets
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/kr-jaydeepp/ripple/crypto"
+	"github.com/kr-jaydeepp/ripple/data"
+)
+
+// Fields from subscribed validation stream messages. The keys are node
+// public keys, written as "n...". MasterKey is only sent for validators
+// which sign with an ephemeral key published in a manifest.
+type ValidationStreamMsg struct {
+	Flags               uint32              `json:"flags"`
+	Full                bool                `json:"full"`
+	LedgerHash          data.Hash256        `json:"ledger_hash"`
+	LedgerSequence      uint32              `json:"ledger_index,string"`
+	SigningTime         data.RippleTime     `json:"signing_time"`
+	Signature           data.VariableLength `json:"signature"`
+	ValidationPublicKey string              `json:"validation_public_key"`
+	MasterKey           string              `json:"master_key"`
+	Cookie              string              `json:"cookie"`
+	ServerVersion       string              `json:"server_version"`
+	ValidatedHash       *data.Hash256       `json:"validated_hash"`
+	Amendments          []data.Hash256      `json:"amendments"`
+	BaseFee             *uint64             `json:"base_fee"`
+	ReserveBase         *uint64             `json:"reserve_base"`
+	ReserveIncrement    *uint64             `json:"reserve_inc"`
+	LoadFee             *uint32             `json:"load_fee"`
+	Data                data.VariableLength `json:"data"` // The serialised validation
+}
+
+// Validation decodes the serialised validation, without checking it
+func (msg *ValidationStreamMsg) Validation() (*data.Validation, error) {
+	if len(msg.Data) == 0 {
+		return nil, fmt.Errorf("Validation of ledger %d has no data", msg.LedgerSequence)
+	}
+	return data.ReadValidation(bytes.NewReader(msg.Data))
+}
+
+// Verify decodes the serialised validation and checks that it was signed
+// by ValidationPublicKey and is of the ledger in the message
+func (msg *ValidationStreamMsg) Verify() (*data.Validation, error) {
+	v, err := msg.Validation()
+	if err != nil {
+		return nil, err
+	}
+	if key := v.SigningPubKey.NodePublicKey(); key != msg.ValidationPublicKey {
+		return nil, fmt.Errorf("Validation is signed by %s not %s", key, msg.ValidationPublicKey)
+	}
+	if v.LedgerHash != msg.LedgerHash || v.LedgerSequence != msg.LedgerSequence {
+		return nil, fmt.Errorf("Validation is of ledger %d %s not %d %s", v.LedgerSequence, v.LedgerHash, msg.LedgerSequence, msg.LedgerHash)
+	}
+	ok, err := data.CheckSignature(v)
+	switch {
+	case err != nil:
+		return nil, err
+	case !ok:
+		return nil, fmt.Errorf("Bad signature of %s on ledger %d", msg.ValidationPublicKey, msg.LedgerSequence)
+	}
+	return v, nil
+}
+
+// Validator returns the key which identifies the validator, its master
+// key if it has one, otherwise its signing key. The master key is as the
+// server says, and nothing binds it to the signing key.
+func (msg *ValidationStreamMsg) Validator() string {
+	if msg.MasterKey != "" {
+		return msg.MasterKey
+	}
+	return msg.ValidationPublicKey
+}
+
+// ValidationQuorum counts the validations of trusted validators of each
+// ledger, and reports when a ledger has been validated by a quorum of them.
+// Validators are identified by their signing keys, unless UseManifests
+// gives the manifests binding signing keys to master keys. The master key
+// a server sends isn't believed, as a dishonest server could use it to
+// pass off validations signed with its own keys.
+type ValidationQuorum struct {
+	quorum    int
+	trusted   map[string]bool
+	manifests *data.ManifestCache
+	mu        sync.Mutex
+	ledgers   map[data.Hash256]*ledgerValidations
+}
+
+type ledgerValidations struct {
+	sequence   uint32
+	validators map[string]bool
+}
+
+// NewValidationQuorum trusts validators with the keys, and needs quorum of
+// them to agree on a ledger. Master keys need UseManifests, and signing
+// keys are trusted without.
+func NewValidationQuorum(quorum int, trusted []string) (*ValidationQuorum, error) {
+	if quorum <= 0 || quorum > len(trusted) {
+		return nil, fmt.Errorf("Bad quorum: %d of %d validators", quorum, len(trusted))
+	}
+	q := &ValidationQuorum{
+		quorum:  quorum,
+		trusted: make(map[string]bool),
+		ledgers: make(map[data.Hash256]*ledgerValidations),
+	}
+	for _, key := range trusted {
+		if _, err := crypto.NewRippleHashCheck(key, crypto.RIPPLE_NODE_PUBLIC); err != nil {
+			return nil, fmt.Errorf("Bad validator key: %s: %s", key, err)
+		}
+		q.trusted[key] = true
+	}
+	return q, nil
+}
+
+// UseManifests identifies validators by the master keys of their signing
+// keys in manifests, rather than by the master keys sent by the server.
+// Validations signed with keys not in manifests are identified by their
+// signing keys.
+func (q *ValidationQuorum) UseManifests(manifests *data.ManifestCache) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.manifests = manifests
+}
+
+// validator returns the key which identifies the signer of msg
+func (q *ValidationQuorum) validator(msg *ValidationStreamMsg) string {
+	q.mu.Lock()
+	manifests := q.manifests
+	q.mu.Unlock()
+	if manifests == nil {
+		return msg.ValidationPublicKey
+	}
+	signing, err := crypto.NewRippleHashCheck(msg.ValidationPublicKey, crypto.RIPPLE_NODE_PUBLIC)
+	if err != nil {
+		return msg.ValidationPublicKey
+	}
+	var key data.PublicKey
+	copy(key[:], signing.Payload())
+	if master, ok := manifests.MasterKey(key); ok {
+		return master.NodePublicKey()
+	}
+	return msg.ValidationPublicKey
+}
+
+// Add verifies a validation and counts it towards the quorum of its
+// ledger. It returns the number of trusted validators of the ledger so
+// far and whether this validation is the one which reached the quorum.
+// Partial validations, those of untrusted validators and repeats are not
+// counted.
+func (q *ValidationQuorum) Add(msg *ValidationStreamMsg) (int, bool, error) {
+	validator := q.validator(msg)
+	if !q.trusted[validator] {
+		return q.Count(msg.LedgerHash), false, nil
+	}
+	v, err := msg.Verify()
+	if err != nil {
+		return q.Count(msg.LedgerHash), false, err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ledger := q.ledgers[v.LedgerHash]
+	if ledger == nil {
+		ledger = &ledgerValidations{sequence: v.LedgerSequence, validators: make(map[string]bool)}
+		q.ledgers[v.LedgerHash] = ledger
+	}
+	if !v.IsFull() || ledger.validators[validator] {
+		return len(ledger.validators), false, nil
+	}
+	ledger.validators[validator] = true
+	return len(ledger.validators), len(ledger.validators) == q.quorum, nil
+}
+
+// Count returns the number of trusted validators of a ledger
+func (q *ValidationQuorum) Count(hash data.Hash256) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if ledger := q.ledgers[hash]; ledger != nil {
+		return len(ledger.validators)
+	}
+	return 0
+}
+
+// Validated reports whether a ledger has reached the quorum
+func (q *ValidationQuorum) Validated(hash data.Hash256) bool {
+	return q.Count(hash) >= q.quorum
+}
+
+// Forget drops the counts of the ledgers before sequence
+func (q *ValidationQuorum) Forget(sequence uint32) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for hash, ledger := range q.ledgers {
+		if ledger.sequence < sequence {
+			delete(q.ledgers, hash)
+		}
+	}
+}
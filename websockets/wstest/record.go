@@ -0,0 +1,155 @@
+package wstest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// Recorder is a websockets.Tap which keeps every Frame, to be saved as a
+// session and replayed by a Server
+type Recorder struct {
+	mu     sync.Mutex
+	frames []websockets.Frame
+}
+
+func (r *Recorder) Frame(frame websockets.Frame) {
+	frame.Message = append([]byte(nil), frame.Message...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frames = append(r.frames, frame)
+}
+
+// Frames returns the frames recorded so far
+func (r *Recorder) Frames() []websockets.Frame {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]websockets.Frame(nil), r.frames...)
+}
+
+// How a Frame is saved, one to a line
+type savedFrame struct {
+	Direction string          `json:"direction"`
+	At        time.Time       `json:"at"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// Save writes the frames recorded so far as lines of JSON, which Load
+// reads back
+func (r *Recorder) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, frame := range r.Frames() {
+		if err := enc.Encode(savedFrame{
+			Direction: frame.Direction.String(),
+			At:        frame.At,
+			Message:   frame.Message,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads a session written by Recorder.Save
+func Load(r io.Reader) ([]websockets.Frame, error) {
+	var frames []websockets.Frame
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var saved savedFrame
+		if err := json.Unmarshal(scanner.Bytes(), &saved); err != nil {
+			return nil, fmt.Errorf("wstest: line %d: %s", line, err)
+		}
+		frame := websockets.Frame{At: saved.At, Message: []byte(saved.Message)}
+		switch saved.Direction {
+		case websockets.FrameSent.String():
+			frame.Direction = websockets.FrameSent
+		case websockets.FrameReceived.String():
+			frame.Direction = websockets.FrameReceived
+		default:
+			return nil, fmt.Errorf("wstest: line %d: unknown direction %q", line, saved.Direction)
+		}
+		frames = append(frames, frame)
+	}
+	return frames, scanner.Err()
+}
+
+// exchange is a recorded command, its response and the stream messages
+// received after the response and before any other
+type exchange struct {
+	request  Request // Without its id
+	response map[string]interface{}
+	then     [][]byte
+	answered bool
+}
+
+type replay struct {
+	exchanges []*exchange
+}
+
+func newReplay(frames []websockets.Frame) (*replay, error) {
+	var (
+		r    replay
+		byID = make(map[string]*exchange)
+		last *exchange
+	)
+	for i, frame := range frames {
+		var message map[string]interface{}
+		if err := json.Unmarshal(frame.Message, &message); err != nil {
+			return nil, fmt.Errorf("wstest: frame %d: %s", i, err)
+		}
+		id, hasID := message["id"]
+		switch {
+		case frame.Direction == websockets.FrameSent:
+			delete(message, "id")
+			ex := &exchange{request: message}
+			r.exchanges = append(r.exchanges, ex)
+			if hasID {
+				byID[fmt.Sprint(id)] = ex
+			}
+		case hasID && byID[fmt.Sprint(id)] != nil:
+			last = byID[fmt.Sprint(id)]
+			last.response = message
+		case last != nil:
+			last.then = append(last.then, frame.Message)
+		}
+	}
+	return &r, nil
+}
+
+// answer returns the messages recorded for the first unanswered command
+// like req, with the id of req
+func (r *replay) answer(req Request) ([][]byte, bool) {
+	request := make(Request, len(req))
+	for key, value := range req {
+		if key != "id" {
+			request[key] = value
+		}
+	}
+	for _, ex := range r.exchanges {
+		if ex.answered || ex.response == nil || !reflect.DeepEqual(ex.request, request) {
+			continue
+		}
+		ex.answered = true
+		response := make(map[string]interface{}, len(ex.response))
+		for key, value := range ex.response {
+			response[key] = value
+		}
+		response["id"] = req["id"]
+		b, err := json.Marshal(response)
+		if err != nil {
+			return nil, false
+		}
+		return append([][]byte{b}, ex.then...), true
+	}
+	return nil, false
+}
@@ -0,0 +1,244 @@
+// Package wstest provides an in-process mock rippled, serving websockets,
+// for deterministic tests of code which uses a websockets.Remote.
+//
+// A Server answers each command with the first of: a matching command of
+// a replayed session, a Handler or canned result for the command's name,
+// or rippled's unknownCmd error. Sessions are recorded from a real server
+// with a Recorder, which is a websockets.Tap.
+package wstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// Request is a command the Server received, as decoded from JSON
+type Request map[string]interface{}
+
+// Command is the name of the command
+func (r Request) Command() string {
+	name, _ := r["command"].(string)
+	return name
+}
+
+// Handler answers a Request with a result, which is marshalled, or with
+// an error, which is sent as a *websockets.CommandError
+type Handler func(req Request) (interface{}, error)
+
+// Server is a mock rippled. Its methods may be called while clients are
+// connected.
+type Server struct {
+	URL string // ws://127.0.0.1:port
+
+	http     *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	replay   *replay
+	latency  time.Duration
+	conns    map[*conn]struct{}
+	requests []Request
+}
+
+// conn is a connection to a client, whose writes may come from both the
+// goroutine answering commands and Publish
+type conn struct {
+	sync.Mutex
+	ws *websocket.Conn
+}
+
+func (c *conn) write(message []byte) error {
+	c.Lock()
+	defer c.Unlock()
+	return c.ws.WriteMessage(websocket.TextMessage, message)
+}
+
+// NewServer starts a Server, which should be closed when done with
+func NewServer() *Server {
+	s := &Server{
+		handlers: make(map[string]Handler),
+		conns:    make(map[*conn]struct{}),
+	}
+	s.http = httptest.NewServer(http.HandlerFunc(s.serve))
+	s.URL = "ws" + strings.TrimPrefix(s.http.URL, "http")
+	return s
+}
+
+// Close disconnects every client and stops the Server
+func (s *Server) Close() {
+	s.Disconnect()
+	s.http.Close()
+}
+
+// Handle answers every command called name with h
+func (s *Server) Handle(name string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[name] = h
+}
+
+// Respond answers every command called name with result, such as a
+// result type of the websockets package or a json.RawMessage
+func (s *Server) Respond(name string, result interface{}) {
+	s.Handle(name, func(Request) (interface{}, error) {
+		return result, nil
+	})
+}
+
+// Replay answers the commands of a recorded session, whatever their
+// order, with the responses and stream messages which followed them.
+// Commands are matched ignoring their ids, and each is answered once.
+func (s *Server) Replay(frames []websockets.Frame) error {
+	replay, err := newReplay(frames)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replay = replay
+	return nil
+}
+
+// SetLatency delays every answer by d
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// Publish sends a stream message, which is marshalled, to every client
+func (s *Server) Publish(message interface{}) error {
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	for _, c := range s.clients() {
+		c.write(b)
+	}
+	return nil
+}
+
+// Disconnect drops every client without a close message, as a crashed
+// server would
+func (s *Server) Disconnect() {
+	for _, c := range s.clients() {
+		c.ws.Close()
+	}
+}
+
+// Requests returns the commands received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) clients() []*conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var clients []*conn
+	for c := range s.conns {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+func (s *Server) serve(w http.ResponseWriter, req *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	c := &conn{ws: ws}
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		ws.Close()
+	}()
+	for {
+		_, b, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req Request
+		if err := json.Unmarshal(b, &req); err != nil {
+			return
+		}
+		messages, latency := s.answer(req)
+		time.Sleep(latency)
+		for _, message := range messages {
+			if err := c.write(message); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// answer returns the messages to send for req, and how long to wait first
+func (s *Server) answer(req Request) ([][]byte, time.Duration) {
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	latency := s.latency
+	if s.replay != nil {
+		if messages, ok := s.replay.answer(req); ok {
+			s.mu.Unlock()
+			return messages, latency
+		}
+	}
+	h, ok := s.handlers[req.Command()]
+	s.mu.Unlock()
+	if !ok {
+		return [][]byte{errorResponse(req, &websockets.CommandError{
+			Name:    "unknownCmd",
+			Code:    32,
+			Message: "Unknown method.",
+		})}, latency
+	}
+	result, err := h(req)
+	if err != nil {
+		cmdErr, ok := err.(*websockets.CommandError)
+		if !ok {
+			cmdErr = &websockets.CommandError{Name: "internal", Code: 73, Message: err.Error()}
+		}
+		return [][]byte{errorResponse(req, cmdErr)}, latency
+	}
+	b, err := json.Marshal(map[string]interface{}{
+		"id":     req["id"],
+		"status": "success",
+		"type":   "response",
+		"result": result,
+	})
+	if err != nil {
+		return [][]byte{errorResponse(req, &websockets.CommandError{
+			Name:    "internal",
+			Code:    73,
+			Message: fmt.Sprintf("Can't marshal result: %s", err),
+		})}, latency
+	}
+	return [][]byte{b}, latency
+}
+
+func errorResponse(req Request, err *websockets.CommandError) []byte {
+	b, _ := json.Marshal(map[string]interface{}{
+		"id":            req["id"],
+		"status":        "error",
+		"type":          "response",
+		"error":         err.Name,
+		"error_code":    err.Code,
+		"error_message": err.Message,
+		"request":       req,
+	})
+	return b
+}
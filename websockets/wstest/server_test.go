@@ -0,0 +1,147 @@
+package wstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type ServerSuite struct{}
+
+var _ = Suite(&ServerSuite{})
+
+func (s *ServerSuite) TestRespond(c *C) {
+	server := NewServer()
+	defer server.Close()
+	server.Respond("ledger_current", map[string]uint32{"ledger_current_index": 42})
+	server.Handle("account_info", func(req Request) (interface{}, error) {
+		if req["account"] != "rwpxNWdpKu2QVgrh5LQXEygYLshhgnRL1Y" {
+			return nil, &websockets.CommandError{Name: "actNotFound", Code: 19, Message: "Account not found."}
+		}
+		return nil, errors.New("broken")
+	})
+	r, err := websockets.NewRemote(server.URL, false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	current, err := r.LedgerCurrent()
+	c.Assert(err, IsNil)
+	c.Check(current, Equals, uint32(42))
+
+	_, err = r.AccountInfo(data.Account{})
+	c.Check(err, ErrorMatches, "actNotFound 19 Account not found.")
+	account, err := data.NewAccountFromAddress("rwpxNWdpKu2QVgrh5LQXEygYLshhgnRL1Y")
+	c.Assert(err, IsNil)
+	_, err = r.AccountInfo(*account)
+	c.Check(err, ErrorMatches, "internal 73 broken")
+
+	_, err = r.Fee()
+	c.Check(err, ErrorMatches, "unknownCmd 32 Unknown method.")
+
+	requests := server.Requests()
+	c.Assert(requests, HasLen, 4)
+	c.Check(requests[0].Command(), Equals, "ledger_current")
+	c.Check(requests[3].Command(), Equals, "fee")
+}
+
+func (s *ServerSuite) TestRecordAndReplay(c *C) {
+	live := NewServer()
+	defer live.Close()
+	live.Respond("ledger_current", map[string]uint32{"ledger_current_index": 42})
+	live.Handle("subscribe", func(req Request) (interface{}, error) {
+		// The stream message follows the response
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			live.Publish(map[string]interface{}{"type": "ledgerClosed", "ledger_index": 43})
+		}()
+		return map[string]interface{}{"ledger_index": 42}, nil
+	})
+	var recorder Recorder
+	r, err := websockets.NewRemoteWithOptions(live.URL, websockets.RemoteOptions{Tap: &recorder})
+	c.Assert(err, IsNil)
+	_, err = r.LedgerCurrent()
+	c.Assert(err, IsNil)
+	_, err = r.Subscribe(true, false, false, false)
+	c.Assert(err, IsNil)
+	c.Check((<-r.Incoming).(*websockets.LedgerStreamMsg).LedgerSequence, Equals, uint32(43))
+	r.Close()
+
+	var saved bytes.Buffer
+	c.Assert(recorder.Save(&saved), IsNil)
+	frames, err := Load(&saved)
+	c.Assert(err, IsNil)
+	c.Assert(frames, HasLen, 5)
+	c.Check(frames[0].Direction, Equals, websockets.FrameSent)
+	c.Check(frames[4].Direction, Equals, websockets.FrameReceived)
+
+	// The replay answers the same commands, in any order, without the
+	// live server
+	replayed := NewServer()
+	defer replayed.Close()
+	c.Assert(replayed.Replay(frames), IsNil)
+	r, err = websockets.NewRemote(replayed.URL, false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+	subscribed, err := r.Subscribe(true, false, false, false)
+	c.Assert(err, IsNil)
+	c.Check(subscribed.LedgerSequence, Equals, uint32(42))
+	c.Check((<-r.Incoming).(*websockets.LedgerStreamMsg).LedgerSequence, Equals, uint32(43))
+	current, err := r.LedgerCurrent()
+	c.Assert(err, IsNil)
+	c.Check(current, Equals, uint32(42))
+
+	// Each recorded command is answered once
+	_, err = r.LedgerCurrent()
+	c.Check(err, ErrorMatches, "unknownCmd .*")
+
+	_, err = Load(bytes.NewBufferString(`{"direction":"up","message":{}}`))
+	c.Check(err, ErrorMatches, `wstest: line 1: unknown direction "up"`)
+}
+
+func (s *ServerSuite) TestDisconnect(c *C) {
+	server := NewServer()
+	defer server.Close()
+	server.Respond("ledger_current", map[string]uint32{"ledger_current_index": 42})
+	r, err := websockets.NewRemoteWithOptions(server.URL, websockets.RemoteOptions{
+		Reconnect:         true,
+		ReconnectInterval: 10 * time.Millisecond,
+	})
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	server.Disconnect()
+	event := <-r.HealthEvents()
+	c.Check(event.To, Equals, websockets.StateReconnecting)
+	event = <-r.HealthEvents()
+	c.Check(event.To, Equals, websockets.StateConnected)
+	_, err = r.LedgerCurrent()
+	c.Check(err, IsNil)
+}
+
+func (s *ServerSuite) TestLatency(c *C) {
+	server := NewServer()
+	defer server.Close()
+	server.Respond("ledger_current", map[string]uint32{"ledger_current_index": 42})
+	server.SetLatency(50 * time.Millisecond)
+	r, err := websockets.NewRemote(server.URL, false)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	start := time.Now()
+	_, err = r.LedgerCurrent()
+	c.Assert(err, IsNil)
+	c.Check(time.Since(start) >= 50*time.Millisecond, Equals, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = r.LedgerCurrentCtx(ctx)
+	c.Check(err, Equals, context.DeadlineExceeded)
+}
@@ -0,0 +1,278 @@
+package withdrawals
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kr-jaydeepp/ripple/builder"
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/sign"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// Run submits withdrawals as they are queued, and follows them up as each
+// validated ledger arrives on ledgers, such as the Ledgers channel of a
+// Subscription, until ctx is done or ledgers is closed. What is left to
+// do after an error is tried again with the next ledger.
+func (w *Wallet) Run(ctx context.Context, ledgers <-chan *websockets.LedgerStreamMsg) error {
+	for {
+		w.report(w.Process(ctx))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ledgers:
+			if !ok {
+				return nil
+			}
+			w.report(w.Expire(ctx, msg.LedgerSequence))
+		case <-w.ready:
+		}
+	}
+}
+
+func (w *Wallet) report(err error) {
+	if err != nil && w.Errors != nil {
+		w.Errors <- err
+	}
+}
+
+// Process submits the queued withdrawals, oldest first. It stops at the
+// first which can't be signed or submitted, and leaves it queued.
+func (w *Wallet) Process(ctx context.Context) error {
+	w.processing.Lock()
+	defer w.processing.Unlock()
+	for {
+		w.mu.Lock()
+		if len(w.queue) == 0 {
+			w.mu.Unlock()
+			return nil
+		}
+		withdrawal := w.queue[0]
+		w.mu.Unlock()
+		if err := w.submit(ctx, withdrawal); err != nil {
+			return err
+		}
+	}
+}
+
+// submit signs and submits withdrawal, which is first in the queue
+func (w *Wallet) submit(ctx context.Context, withdrawal *Withdrawal) error {
+	tx, err := w.payment(withdrawal).
+		WithAutoFill(&builder.AutoFill{
+			Client:       w.client,
+			Sequences:    w.sequences,
+			Fees:         w.Fees,
+			LedgerOffset: w.LedgerOffset,
+		}).
+		BuildCtx(ctx)
+	if err != nil {
+		// A sequence number may have been assigned before the rest failed
+		w.sequences.Reset(w.account)
+		return err
+	}
+	signed, err := w.sign(tx)
+	if err != nil {
+		w.sequences.Reset(w.account)
+		return err
+	}
+	result, err := w.client.SubmitCtx(ctx, signed.Tx)
+
+	w.mu.Lock()
+	w.queue = w.queue[1:]
+	withdrawal.Attempts++
+	withdrawal.Hash = signed.Hash
+	withdrawal.Sequence = tx.Sequence
+	withdrawal.LastLedgerSequence = *tx.LastLedgerSequence
+	withdrawal.signed = signed
+	withdrawal.Err = err
+	switch {
+	case err != nil:
+		// It may have been received, so it is followed up like any other
+		withdrawal.State = Submitted
+		w.pending[signed.Hash] = withdrawal
+	case result.EngineResult == data.TefPAST_SEQ:
+		// The sequence number was stale and nothing was done
+		w.sequences.Report(tx, result.EngineResult)
+		withdrawal.Result = result.EngineResult
+		if !w.retry(withdrawal) {
+			w.queue = append([]*Withdrawal{withdrawal}, w.queue...)
+		}
+	case result.EngineResult.IsFinal() && !result.EngineResult.Claimed():
+		w.sequences.Report(tx, result.EngineResult)
+		withdrawal.Result = result.EngineResult
+		withdrawal.State = Failed
+		withdrawal.Err = result.Err()
+	default:
+		withdrawal.Result = result.EngineResult
+		withdrawal.State = Submitted
+		w.pending[signed.Hash] = withdrawal
+	}
+	changed := *withdrawal
+	w.mu.Unlock()
+	w.notify([]Withdrawal{changed})
+	return nil
+}
+
+// sign signs tx for the Network, if any
+func (w *Wallet) sign(tx data.Transaction) (*sign.Signed, error) {
+	if w.Network != nil {
+		return sign.ForNetwork(tx, w.Network, w.signer)
+	}
+	return sign.Transaction(tx, w.signer)
+}
+
+// retry fails withdrawal if it has been submitted MaxAttempts times, and
+// otherwise leaves it to be queued again
+func (w *Wallet) retry(withdrawal *Withdrawal) bool {
+	attempts := w.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultMaxAttempts
+	}
+	if withdrawal.Attempts < attempts {
+		withdrawal.State = Queued
+		return false
+	}
+	withdrawal.State = Failed
+	withdrawal.Err = fmt.Errorf("Withdrawal %s was not applied after %d attempts", withdrawal.ID, withdrawal.Attempts)
+	return true
+}
+
+// Update settles the submitted withdrawal which a validated transaction
+// is, if any. Proposed transactions are ignored.
+func (w *Wallet) Update(msg *websockets.TransactionStreamMsg) {
+	if !msg.Validated {
+		return
+	}
+	ledger := msg.LedgerSequence
+	if ledger == 0 {
+		ledger = msg.Transaction.LedgerSequence
+	}
+	w.settle(&msg.Transaction, ledger)
+}
+
+// Watch calls Update for every message on transactions until it is
+// closed, such as the Transactions channel of a Subscription
+func (w *Wallet) Watch(transactions <-chan *websockets.TransactionStreamMsg) {
+	go func() {
+		for msg := range transactions {
+			w.Update(msg)
+		}
+	}()
+}
+
+// settle records the result of txm, validated in ledger
+func (w *Wallet) settle(txm *data.TransactionWithMetaData, ledger uint32) {
+	w.mu.Lock()
+	withdrawal, ok := w.pending[*txm.GetHash()]
+	if !ok {
+		w.mu.Unlock()
+		return
+	}
+	delete(w.pending, *txm.GetHash())
+	withdrawal.Result = txm.MetaData.TransactionResult
+	withdrawal.LedgerSequence = ledger
+	if withdrawal.Result.Success() {
+		withdrawal.State = Succeeded
+		withdrawal.Delivered, _ = txm.DeliveredAmount()
+		withdrawal.Err = nil
+	} else {
+		withdrawal.State = Failed
+		withdrawal.Err = fmt.Errorf("Withdrawal %s failed with %s", withdrawal.ID, withdrawal.Result)
+	}
+	changed := *withdrawal
+	w.mu.Unlock()
+	w.notify([]Withdrawal{changed})
+}
+
+// Expire follows up the submitted withdrawals once ledger is validated.
+// Those which weren't accepted when submitted, and may still be applied,
+// are submitted again as they are. Those whose LastLedgerSequence has
+// passed are looked up, and queued again if their sequence number is
+// unused.
+func (w *Wallet) Expire(ctx context.Context, ledger uint32) error {
+	var expired, unaccepted []*Withdrawal
+	w.mu.Lock()
+	for _, withdrawal := range w.pending {
+		switch {
+		case withdrawal.LastLedgerSequence <= ledger:
+			expired = append(expired, withdrawal)
+		case withdrawal.Err != nil || withdrawal.Result.IsRetriable():
+			unaccepted = append(unaccepted, withdrawal)
+		}
+	}
+	w.mu.Unlock()
+	sort.Slice(expired, func(i, j int) bool { return expired[i].Sequence < expired[j].Sequence })
+	sort.Slice(unaccepted, func(i, j int) bool { return unaccepted[i].Sequence < unaccepted[j].Sequence })
+	for _, withdrawal := range unaccepted {
+		if err := w.resubmit(ctx, withdrawal); err != nil {
+			return err
+		}
+	}
+	for _, withdrawal := range expired {
+		if err := w.expire(ctx, withdrawal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resubmit submits the same transaction again
+func (w *Wallet) resubmit(ctx context.Context, withdrawal *Withdrawal) error {
+	w.mu.Lock()
+	signed := withdrawal.signed
+	w.mu.Unlock()
+	result, err := w.client.SubmitCtx(ctx, signed.Tx)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pending[signed.Hash] == withdrawal {
+		withdrawal.Result, withdrawal.Err = result.EngineResult, nil
+	}
+	return nil
+}
+
+// expire settles or queues again withdrawal, whose LastLedgerSequence has
+// been validated
+func (w *Wallet) expire(ctx context.Context, withdrawal *Withdrawal) error {
+	w.mu.Lock()
+	hash, sequence := withdrawal.Hash, withdrawal.Sequence
+	w.mu.Unlock()
+	tx, err := w.client.TxCtx(ctx, hash)
+	switch {
+	case err == nil && tx.Validated:
+		w.settle(&tx.TransactionWithMetaData, tx.LedgerSequence)
+		return nil
+	case err != nil && !websockets.IsNotFound(err):
+		return err
+	}
+	info, err := w.client.AccountInfoCtx(ctx, w.account)
+	if err != nil {
+		return err
+	}
+	if info.AccountData.Sequence == nil {
+		return fmt.Errorf("Account %s has no sequence", w.account)
+	}
+
+	w.mu.Lock()
+	if w.pending[hash] != withdrawal {
+		// Settled meanwhile
+		w.mu.Unlock()
+		return nil
+	}
+	delete(w.pending, hash)
+	if *info.AccountData.Sequence > sequence {
+		withdrawal.State = Failed
+		withdrawal.Err = fmt.Errorf("Sequence %d of %s was used, but not by %s", sequence, w.account, hash)
+	} else if !w.retry(withdrawal) {
+		w.sequences.Reset(w.account)
+		w.queue = append(w.queue, withdrawal)
+	}
+	changed := *withdrawal
+	w.mu.Unlock()
+	w.wake()
+	w.notify([]Withdrawal{changed})
+	return nil
+}
@@ -0,0 +1,330 @@
+// Package withdrawals pays out of a hot wallet, as an exchange paying its
+// customers needs to. Each withdrawal is checked when it is queued, then
+// signed, submitted and followed until it is in a validated ledger, so
+// that it is paid once only, however often it has to be submitted.
+//
+//	updates := make(chan *withdrawals.Withdrawal)
+//	w := withdrawals.NewWallet(remote, hot, key, updates)
+//	sub, err := remote.SubscribeTo(w.Subscription())
+//	...
+//	w.Watch(sub.Transactions)
+//	go w.Run(ctx, sub.Ledgers)
+//	_, err = w.Queue(ctx, withdrawals.Request{ID: "42", Destination: "X7...", Amount: *amount})
+//
+// A withdrawal whose LastLedgerSequence passes without it being applied
+// is queued again, but only once its sequence number is seen to be
+// unused, as it could otherwise still be applied.
+package withdrawals
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kr-jaydeepp/ripple/accounts"
+	"github.com/kr-jaydeepp/ripple/builder"
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/sign"
+	"github.com/kr-jaydeepp/ripple/websockets"
+)
+
+// How many times a withdrawal is submitted before it fails, when
+// Wallet.MaxAttempts is zero
+const DefaultMaxAttempts = 5
+
+// Client is the part of ripple.Client needed to make withdrawals: that
+// of the AutoFill and SequenceManager, and the balances, submissions and
+// results of the Wallet itself
+type Client interface {
+	builder.Client
+	accounts.Client
+	AccountLinesCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountLinesResult, error)
+	SubmitCtx(ctx context.Context, tx data.Transaction) (*websockets.SubmitResult, error)
+	TxCtx(ctx context.Context, hash data.Hash256) (*websockets.TxResult, error)
+}
+
+// State is how far a withdrawal has got
+type State int
+
+const (
+	Queued    State = iota // Waiting to be submitted
+	Submitted              // Waiting to be validated
+	Succeeded              // Paid, in a validated ledger
+	Failed                 // Not paid, and never will be
+)
+
+var stateNames = [...]string{
+	Queued:    "Queued",
+	Submitted: "Submitted",
+	Succeeded: "Succeeded",
+	Failed:    "Failed",
+}
+
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+	return stateNames[s]
+}
+
+// Request asks for Amount to be paid to Destination
+type Request struct {
+	// Chosen by the caller, and unique to the withdrawal
+	ID string
+	// A classic address or an X-address, which may carry the tag
+	Destination    string
+	DestinationTag *uint32
+	Amount         data.Amount
+	// The most to spend, for a currency of another issuer with a
+	// transfer fee. Nil pays Amount.
+	SendMax *data.Amount
+}
+
+// Withdrawal is a Request and what has become of it
+type Withdrawal struct {
+	Request
+	// The Destination and tag, from either form of address
+	Account data.Account
+	Tag     *uint32
+
+	State State
+	// Of the last submission
+	Hash               data.Hash256
+	Sequence           uint32
+	LastLedgerSequence uint32
+	Attempts           int
+	// The engine result when submitted, and then the final one
+	Result data.TransactionResult
+	// Once validated
+	LedgerSequence uint32
+	Delivered      *data.Amount
+	// Why it failed, or the last error submitting it
+	Err error
+
+	signed *sign.Signed
+}
+
+func (w Withdrawal) String() string {
+	return fmt.Sprintf("%s: %s to %s %s %s", w.ID, w.Amount, w.Destination, w.State, w.Result)
+}
+
+// Wallet makes the withdrawals of a hot wallet, sending a copy of each
+// Withdrawal to updates whenever its State changes
+type Wallet struct {
+	// Sets the NetworkID of transactions and refuses X-addresses of other
+	// networks. Nil signs without a NetworkID and accepts any X-address.
+	Network *data.Network
+	// Fills in the fees, which it leaves to the medium urgency of
+	// accounts.FeePolicy unless replaced before use
+	Fees *accounts.FeeProvider
+	// Ledgers after the current one in which a submission may be applied.
+	// Zero means builder.DefaultLedgerOffset.
+	LedgerOffset uint32
+	// Zero means DefaultMaxAttempts
+	MaxAttempts int
+	// If not nil, errors met by Run are reported to Errors
+	Errors chan<- error
+
+	client    Client
+	account   data.Account
+	signer    sign.Signer
+	sequences *accounts.SequenceManager
+	updates   chan<- *Withdrawal
+	ready     chan struct{}
+	// Held while submitting, so that the queue is submitted in order
+	processing sync.Mutex
+
+	mu          sync.Mutex
+	withdrawals map[string]*Withdrawal
+	queue       []*Withdrawal
+	pending     map[data.Hash256]*Withdrawal
+}
+
+// NewWallet returns a Wallet paying from account, which signer signs
+// for with its master or regular key
+func NewWallet(client Client, account data.Account, signer sign.Signer, updates chan<- *Withdrawal) *Wallet {
+	return &Wallet{
+		Fees:        accounts.NewFeeProvider(client, accounts.FeePolicy{Urgency: websockets.FeeMedium}),
+		client:      client,
+		account:     account,
+		signer:      signer,
+		sequences:   accounts.NewSequenceManager(client),
+		updates:     updates,
+		ready:       make(chan struct{}, 1),
+		withdrawals: make(map[string]*Withdrawal),
+		pending:     make(map[data.Hash256]*Withdrawal),
+	}
+}
+
+// Subscription returns the options to subscribe to the validated ledgers
+// and the transactions of the hot wallet
+func (w *Wallet) Subscription() websockets.SubscriptionOptions {
+	return websockets.SubscriptionOptions{
+		Ledger:   true,
+		Accounts: []data.Account{w.account},
+	}
+}
+
+// Get returns a copy of the withdrawal with id
+func (w *Wallet) Get(id string) (*Withdrawal, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	withdrawal, ok := w.withdrawals[id]
+	if !ok {
+		return nil, false
+	}
+	result := *withdrawal
+	return &result, true
+}
+
+// Queue checks req and queues it to be submitted. A Request is refused
+// if its ID has been queued before, if its destination doesn't exist,
+// needs a tag it doesn't have or refuses XRP, or if the currency can't be
+// held by the destination or isn't held by the hot wallet.
+func (w *Wallet) Queue(ctx context.Context, req Request) (*Withdrawal, error) {
+	withdrawal, err := w.check(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	w.mu.Lock()
+	if _, ok := w.withdrawals[req.ID]; ok {
+		w.mu.Unlock()
+		return nil, fmt.Errorf("Withdrawal %s already exists", req.ID)
+	}
+	w.withdrawals[req.ID] = withdrawal
+	w.queue = append(w.queue, withdrawal)
+	result := *withdrawal
+	w.mu.Unlock()
+	w.wake()
+	w.notify([]Withdrawal{result})
+	return &result, nil
+}
+
+// check returns the Withdrawal of req, unless it can't be paid
+func (w *Wallet) check(ctx context.Context, req Request) (*Withdrawal, error) {
+	if req.ID == "" {
+		return nil, fmt.Errorf("Withdrawal has no ID")
+	}
+	account, tag, err := w.destination(req.Destination, req.DestinationTag)
+	if err != nil {
+		return nil, err
+	}
+	withdrawal := &Withdrawal{Request: req, Account: account, Tag: tag}
+	if _, err := w.payment(withdrawal).Build(); err != nil {
+		return nil, err
+	}
+	amount := req.Amount
+	info, err := w.client.AccountInfoCtx(ctx, account)
+	switch {
+	case websockets.IsNotFound(err) && !amount.IsNative():
+		return nil, fmt.Errorf("Account %s does not exist", account)
+	case websockets.IsNotFound(err) && w.Network != nil && amount.Value.Less(w.Network.BaseReserve):
+		return nil, fmt.Errorf("%s is less than the reserve of %s XRP needed to create %s", amount, w.Network.BaseReserve, account)
+	case websockets.IsNotFound(err):
+	case err != nil:
+		return nil, err
+	case info.AccountData.Flags == nil:
+	case *info.AccountData.Flags&data.LsRequireDestTag != 0 && tag == nil:
+		return nil, fmt.Errorf("Account %s requires a destination tag", account)
+	case *info.AccountData.Flags&data.LsDisallowXRP != 0 && amount.IsNative():
+		return nil, fmt.Errorf("Account %s does not accept XRP", account)
+	}
+	if amount.IsNative() {
+		return withdrawal, nil
+	}
+	if account != amount.Issuer {
+		line, err := w.line(ctx, account, amount)
+		if err != nil {
+			return nil, err
+		}
+		room, err := line.Limit.Subtract(line.Balance.Value)
+		if err != nil {
+			return nil, err
+		}
+		if room.Less(*amount.Value) {
+			return nil, fmt.Errorf("Account %s can only receive %s more %s", account, room, amount.Currency)
+		}
+	}
+	if w.account != amount.Issuer {
+		line, err := w.line(ctx, w.account, amount)
+		if err != nil {
+			return nil, err
+		}
+		if line.Balance.Less(*amount.Value) {
+			return nil, fmt.Errorf("Hot wallet %s holds only %s %s", w.account, line.Balance, amount.Currency)
+		}
+	}
+	return withdrawal, nil
+}
+
+// destination returns the account and tag of address and tag, where
+// address may be an X-address with a tag of its own
+func (w *Wallet) destination(address string, tag *uint32) (data.Account, *uint32, error) {
+	if strings.HasPrefix(address, "r") {
+		account, err := data.NewAccountFromAddress(address)
+		if err != nil {
+			return data.Account{}, nil, err
+		}
+		return *account, tag, nil
+	}
+	x, err := data.NewXAddress(address)
+	if err != nil {
+		return data.Account{}, nil, err
+	}
+	switch {
+	case w.Network != nil && x.Testnet != (w.Network.ID != data.Mainnet.ID):
+		return data.Account{}, nil, fmt.Errorf("X-address %s is not for %s", address, w.Network.Name)
+	case x.Tag == nil:
+		return x.Account, tag, nil
+	case tag != nil && *tag != *x.Tag:
+		return data.Account{}, nil, fmt.Errorf("X-address %s has tag %d, not %d", address, *x.Tag, *tag)
+	}
+	return x.Account, x.Tag, nil
+}
+
+// line returns the trust line of account for the currency of amount
+func (w *Wallet) line(ctx context.Context, account data.Account, amount data.Amount) (*data.AccountLine, error) {
+	lines, err := w.client.AccountLinesCtx(ctx, account, "validated")
+	if err != nil {
+		return nil, err
+	}
+	for i := range lines.Lines {
+		line := &lines.Lines[i]
+		if line.Account == amount.Issuer && line.Currency == amount.Currency {
+			return line, nil
+		}
+	}
+	return nil, fmt.Errorf("Account %s has no trust line for %s/%s", account, amount.Currency, amount.Issuer)
+}
+
+// payment returns the builder of the Payment of withdrawal
+func (w *Wallet) payment(withdrawal *Withdrawal) *builder.Payment {
+	b := builder.NewPayment(w.account, withdrawal.Account, withdrawal.Amount).
+		WithNetwork(w.Network)
+	if withdrawal.Tag != nil {
+		b.WithDestinationTag(*withdrawal.Tag)
+	}
+	if withdrawal.SendMax != nil {
+		b.WithSendMax(*withdrawal.SendMax)
+	}
+	return b
+}
+
+// wake makes Run submit what has been queued
+func (w *Wallet) wake() {
+	select {
+	case w.ready <- struct{}{}:
+	default:
+	}
+}
+
+// notify sends the changed withdrawals to updates, without holding the lock
+func (w *Wallet) notify(changed []Withdrawal) {
+	if w.updates == nil {
+		return
+	}
+	for i := range changed {
+		w.updates <- &changed[i]
+	}
+}
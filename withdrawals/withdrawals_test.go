@@ -0,0 +1,441 @@
+package withdrawals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/kr-jaydeepp/ripple/data"
+	"github.com/kr-jaydeepp/ripple/sign"
+	"github.com/kr-jaydeepp/ripple/websockets"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type WithdrawalsSuite struct{}
+
+var _ = Suite(&WithdrawalsSuite{})
+
+const (
+	secret   = "snoPBrXtMeMyMHUVTgbuqAfg1SUTb"
+	hot      = "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"
+	customer = "rU6K7V3Po4snVhBBaU29sesqs2qTQJWDw1"
+	tagged   = "rGgj3GurcrAqgBXGVoS9wvQG3Hjkj5oCbj" // Requires a tag and refuses XRP
+	issuer   = "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"
+	unknown  = "rpDMez6pm6dBve2TJsmDpv7Yae6V5Pyvy2"
+)
+
+const feeJSON = `{
+	"drops": {
+		"base_fee": "10",
+		"median_fee": "5000",
+		"minimum_fee": "10",
+		"open_ledger_fee": "12"
+	},
+	"levels": {
+		"median_level": "128000",
+		"minimum_level": "256",
+		"open_ledger_level": "300",
+		"reference_level": "256"
+	}
+}`
+
+// ledger is a Client where the hot wallet has sequence, the customer has
+// a line of USD with room for 5 more, and the hot wallet holds 1 USD.
+// Submissions get the next of results, or tesSUCCESS.
+type ledger struct {
+	sync.Mutex
+	sequence  uint32
+	current   uint32
+	results   []data.TransactionResult
+	failure   error
+	feeErrors int // How many fee requests fail
+	submitted []*data.Payment
+	validated map[data.Hash256]*websockets.TxResult
+}
+
+func newLedger() *ledger {
+	return &ledger{sequence: 5, current: 100, validated: make(map[data.Hash256]*websockets.TxResult)}
+}
+
+func (l *ledger) AccountInfoCtx(ctx context.Context, a data.Account) (*websockets.AccountInfoResult, error) {
+	l.Lock()
+	defer l.Unlock()
+	var root data.AccountRoot
+	switch a.String() {
+	case hot:
+		sequence := l.sequence
+		root.Sequence = &sequence
+	case tagged:
+		flags := data.LsRequireDestTag | data.LsDisallowXRP
+		root.Flags = &flags
+	case customer, issuer:
+	default:
+		return nil, &websockets.CommandError{Name: "actNotFound", Code: 19}
+	}
+	return &websockets.AccountInfoResult{LedgerSequence: l.current, AccountData: root}, nil
+}
+
+func (l *ledger) AccountObjectsCtx(ctx context.Context, account data.Account, objectType string, ledgerIndex interface{}) (*websockets.AccountObjectsResult, error) {
+	return &websockets.AccountObjectsResult{Account: account}, nil
+}
+
+func (l *ledger) AccountLinesCtx(ctx context.Context, account data.Account, ledgerIndex interface{}) (*websockets.AccountLinesResult, error) {
+	balance := "95"
+	if account.String() == hot {
+		balance = "1"
+	}
+	var result websockets.AccountLinesResult
+	return &result, json.Unmarshal([]byte(`{
+		"account": "`+account.String()+`",
+		"lines": [{
+			"account": "`+issuer+`",
+			"balance": "`+balance+`",
+			"currency": "USD",
+			"limit": "100",
+			"limit_peer": "0"
+		}]
+	}`), &result)
+}
+
+func (l *ledger) FeeCtx(ctx context.Context) (*websockets.FeeResult, error) {
+	l.Lock()
+	defer l.Unlock()
+	if l.feeErrors > 0 {
+		l.feeErrors--
+		return nil, fmt.Errorf("Fee unavailable")
+	}
+	var result websockets.FeeResult
+	return &result, json.Unmarshal([]byte(feeJSON), &result)
+}
+
+func (l *ledger) LedgerCurrentCtx(ctx context.Context) (uint32, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.current, nil
+}
+
+func (l *ledger) SubmitCtx(ctx context.Context, tx data.Transaction) (*websockets.SubmitResult, error) {
+	l.Lock()
+	defer l.Unlock()
+	l.submitted = append(l.submitted, tx.(*data.Payment))
+	if l.failure != nil {
+		return nil, l.failure
+	}
+	result := data.TesSUCCESS
+	if len(l.results) > 0 {
+		result, l.results = l.results[0], l.results[1:]
+	}
+	return &websockets.SubmitResult{EngineResult: result}, nil
+}
+
+func (l *ledger) TxCtx(ctx context.Context, hash data.Hash256) (*websockets.TxResult, error) {
+	l.Lock()
+	defer l.Unlock()
+	if tx, ok := l.validated[hash]; ok {
+		return tx, nil
+	}
+	return nil, &websockets.CommandError{Name: "txnNotFound", Code: 29}
+}
+
+// last returns the last transaction submitted
+func (l *ledger) last(c *C) *data.Payment {
+	l.Lock()
+	defer l.Unlock()
+	c.Assert(l.submitted, Not(HasLen), 0)
+	return l.submitted[len(l.submitted)-1]
+}
+
+func account(c *C, address string) data.Account {
+	a, err := data.NewAccountFromAddress(address)
+	c.Assert(err, IsNil)
+	return *a
+}
+
+func amount(c *C, s string) data.Amount {
+	a, err := data.NewAmount(s)
+	c.Assert(err, IsNil)
+	return *a
+}
+
+func newWallet(c *C, l *ledger, updates chan<- *Withdrawal) *Wallet {
+	key, err := sign.NewKey(secret)
+	c.Assert(err, IsNil)
+	c.Assert(key.Account().String(), Equals, hot)
+	return NewWallet(l, key.Account(), key, updates)
+}
+
+// validated returns the message of tx being validated in ledger with result
+func validated(tx *data.Payment, ledger uint32, result data.TransactionResult) *websockets.TransactionStreamMsg {
+	return &websockets.TransactionStreamMsg{
+		Validated:      true,
+		LedgerSequence: ledger,
+		Transaction: data.TransactionWithMetaData{
+			Transaction:    tx,
+			MetaData:       data.MetaData{TransactionResult: result},
+			LedgerSequence: ledger,
+		},
+	}
+}
+
+func (s *WithdrawalsSuite) TestQueue(c *C) {
+	w := newWallet(c, newLedger(), nil)
+	w.Network = data.Mainnet
+	tag1, tag2 := uint32(1), uint32(2)
+	x, err := data.NewXAddressFromClassic(customer, &tag1, false)
+	c.Assert(err, IsNil)
+	testnet, err := data.NewXAddressFromClassic(customer, nil, true)
+	c.Assert(err, IsNil)
+
+	for _, test := range []struct {
+		req Request
+		err string
+	}{
+		{Request{Destination: customer, Amount: amount(c, "1")}, "Withdrawal has no ID"},
+		{Request{ID: "1", Destination: "nonsense", Amount: amount(c, "1")}, ".+"},
+		{Request{ID: "1", Destination: x.String(), DestinationTag: &tag2, Amount: amount(c, "1")}, "X-address .* has tag 1, not 2"},
+		{Request{ID: "1", Destination: testnet.String(), Amount: amount(c, "1")}, "X-address .* is not for mainnet"},
+		{Request{ID: "1", Destination: hot, Amount: amount(c, "1")}, "Payment: Destination is the Account"},
+		{Request{ID: "1", Destination: customer, Amount: amount(c, "0")}, "Payment: Amount must be positive"},
+		{Request{ID: "1", Destination: tagged, Amount: amount(c, "1/USD/"+issuer)}, "Account .* requires a destination tag"},
+		{Request{ID: "1", Destination: tagged, DestinationTag: &tag1, Amount: amount(c, "1")}, "Account .* does not accept XRP"},
+		{Request{ID: "1", Destination: unknown, Amount: amount(c, "1/USD/"+issuer)}, "Account " + unknown + " does not exist"},
+		{Request{ID: "1", Destination: unknown, Amount: amount(c, "0.5")}, "0.5/XRP is less than the reserve of 1 XRP needed to create " + unknown},
+		{Request{ID: "1", Destination: customer, Amount: amount(c, "6/USD/"+issuer)}, "Account " + customer + " can only receive 5 more USD"},
+		{Request{ID: "1", Destination: customer, Amount: amount(c, "1/EUR/"+issuer)}, "Account " + customer + " has no trust line for EUR/" + issuer},
+		{Request{ID: "1", Destination: customer, Amount: amount(c, "2/USD/"+issuer)}, "Hot wallet " + hot + " holds only 1 USD"},
+	} {
+		_, err := w.Queue(context.Background(), test.req)
+		c.Check(err, ErrorMatches, test.err, Commentf("%+v", test.req))
+	}
+
+	// Returned to the issuer, which needs no line
+	_, err = w.Queue(context.Background(), Request{ID: "issuer", Destination: issuer, Amount: amount(c, "1/USD/"+issuer)})
+	c.Check(err, IsNil)
+	// Bringing a new account into being
+	_, err = w.Queue(context.Background(), Request{ID: "unknown", Destination: unknown, Amount: amount(c, "2.5")})
+	c.Check(err, IsNil)
+
+	withdrawal, err := w.Queue(context.Background(), Request{ID: "1", Destination: x.String(), Amount: amount(c, "1/USD/"+issuer)})
+	c.Assert(err, IsNil)
+	c.Check(withdrawal.Account, Equals, account(c, customer))
+	c.Check(*withdrawal.Tag, Equals, tag1)
+	c.Check(withdrawal.State, Equals, Queued)
+	_, err = w.Queue(context.Background(), Request{ID: "1", Destination: customer, Amount: amount(c, "1")})
+	c.Check(err, ErrorMatches, "Withdrawal 1 already exists")
+}
+
+func (s *WithdrawalsSuite) TestSubmit(c *C) {
+	l := newLedger()
+	updates := make(chan *Withdrawal, 10)
+	w := newWallet(c, l, updates)
+	tag := uint32(7)
+	_, err := w.Queue(context.Background(), Request{ID: "a", Destination: customer, DestinationTag: &tag, Amount: amount(c, "10")})
+	c.Assert(err, IsNil)
+	c.Check((<-updates).State, Equals, Queued)
+
+	c.Assert(w.Process(context.Background()), IsNil)
+	tx := l.last(c)
+	c.Check(tx.Account.String(), Equals, hot)
+	c.Check(tx.Destination.String(), Equals, customer)
+	c.Check(*tx.DestinationTag, Equals, tag)
+	c.Check(tx.Sequence, Equals, uint32(5))
+	c.Check(*tx.LastLedgerSequence, Equals, uint32(120))
+	c.Check(tx.Fee.String(), Equals, "0.000012")
+	c.Check(tx.TxnSignature, NotNil)
+	withdrawal := <-updates
+	c.Check(withdrawal.State, Equals, Submitted)
+	c.Check(withdrawal.Hash, Equals, *tx.GetHash())
+	c.Check(withdrawal.Sequence, Equals, uint32(5))
+	c.Check(withdrawal.Attempts, Equals, 1)
+
+	proposed := validated(tx, 101, data.TesSUCCESS)
+	proposed.Validated = false
+	w.Update(proposed)
+	c.Check(updates, HasLen, 0)
+
+	w.Update(validated(tx, 101, data.TesSUCCESS))
+	withdrawal = <-updates
+	c.Check(withdrawal.State, Equals, Succeeded)
+	c.Check(withdrawal.LedgerSequence, Equals, uint32(101))
+	c.Check(withdrawal.Delivered.String(), Equals, "0.00001/XRP")
+	c.Check(withdrawal.Err, IsNil)
+
+	// Once only
+	w.Update(validated(tx, 101, data.TesSUCCESS))
+	c.Check(updates, HasLen, 0)
+	withdrawal, ok := w.Get("a")
+	c.Assert(ok, Equals, true)
+	c.Check(withdrawal.State, Equals, Succeeded)
+}
+
+func (s *WithdrawalsSuite) TestFeeError(c *C) {
+	l := newLedger()
+	l.feeErrors = 1
+	w := newWallet(c, l, nil)
+	_, err := w.Queue(context.Background(), Request{ID: "a", Destination: customer, Amount: amount(c, "10")})
+	c.Assert(err, IsNil)
+	c.Check(w.Process(context.Background()), ErrorMatches, "Fee unavailable")
+	withdrawal, ok := w.Get("a")
+	c.Assert(ok, Equals, true)
+	c.Check(withdrawal.State, Equals, Queued)
+
+	// The sequence number assigned before the fee failed is used again
+	c.Assert(w.Process(context.Background()), IsNil)
+	c.Check(l.last(c).Sequence, Equals, uint32(5))
+}
+
+func (s *WithdrawalsSuite) TestRejected(c *C) {
+	l := newLedger()
+	updates := make(chan *Withdrawal, 10)
+	w := newWallet(c, l, updates)
+	l.results = []data.TransactionResult{data.TemBAD_AMOUNT, data.TefPAST_SEQ, data.TesSUCCESS, data.TecUNFUNDED_PAYMENT}
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := w.Queue(context.Background(), Request{ID: id, Destination: customer, Amount: amount(c, "10")})
+		c.Assert(err, IsNil)
+		<-updates
+	}
+	c.Assert(w.Process(context.Background()), IsNil)
+
+	withdrawal := <-updates
+	c.Check(withdrawal.ID, Equals, "a")
+	c.Check(withdrawal.State, Equals, Failed)
+	c.Check(withdrawal.Err, ErrorMatches, "temBAD_AMOUNT.*")
+
+	// Again, with the sequence number reloaded
+	withdrawal = <-updates
+	c.Check(withdrawal.ID, Equals, "b")
+	c.Check(withdrawal.State, Equals, Queued)
+	withdrawal = <-updates
+	c.Check(withdrawal.ID, Equals, "b")
+	c.Check(withdrawal.State, Equals, Submitted)
+	c.Check(withdrawal.Attempts, Equals, 2)
+	c.Check(withdrawal.Sequence, Equals, uint32(5))
+
+	// Claimed, so only settled once validated
+	withdrawal = <-updates
+	c.Check(withdrawal.ID, Equals, "c")
+	c.Check(withdrawal.State, Equals, Submitted)
+	c.Check(withdrawal.Sequence, Equals, uint32(6))
+	w.Update(validated(l.last(c), 101, data.TecUNFUNDED_PAYMENT))
+	withdrawal = <-updates
+	c.Check(withdrawal.State, Equals, Failed)
+	c.Check(withdrawal.Err, ErrorMatches, "Withdrawal c failed with tecUNFUNDED_PAYMENT")
+}
+
+func (s *WithdrawalsSuite) TestResubmit(c *C) {
+	l := newLedger()
+	w := newWallet(c, l, nil)
+	l.results = []data.TransactionResult{data.TerPRE_SEQ}
+	_, err := w.Queue(context.Background(), Request{ID: "a", Destination: customer, Amount: amount(c, "10")})
+	c.Assert(err, IsNil)
+	c.Assert(w.Process(context.Background()), IsNil)
+	first := l.last(c)
+
+	c.Assert(w.Expire(context.Background(), 101), IsNil)
+	c.Check(l.submitted, HasLen, 2)
+	c.Check(*l.last(c).GetHash(), Equals, *first.GetHash())
+	withdrawal, _ := w.Get("a")
+	c.Check(withdrawal.Result, Equals, data.TesSUCCESS)
+
+	// Accepted, so left alone
+	c.Assert(w.Expire(context.Background(), 102), IsNil)
+	c.Check(l.submitted, HasLen, 2)
+
+	// Unknown, so followed up
+	l.failure = fmt.Errorf("Connection lost")
+	_, err = w.Queue(context.Background(), Request{ID: "b", Destination: customer, Amount: amount(c, "10")})
+	c.Assert(err, IsNil)
+	c.Assert(w.Process(context.Background()), IsNil)
+	withdrawal, _ = w.Get("b")
+	c.Check(withdrawal.State, Equals, Submitted)
+	c.Check(withdrawal.Err, ErrorMatches, "Connection lost")
+	c.Check(w.Expire(context.Background(), 103), ErrorMatches, "Connection lost")
+	l.failure = nil
+	c.Assert(w.Expire(context.Background(), 103), IsNil)
+	withdrawal, _ = w.Get("b")
+	c.Check(withdrawal.Err, IsNil)
+}
+
+func (s *WithdrawalsSuite) TestExpire(c *C) {
+	l := newLedger()
+	updates := make(chan *Withdrawal, 10)
+	w := newWallet(c, l, updates)
+	_, err := w.Queue(context.Background(), Request{ID: "a", Destination: customer, Amount: amount(c, "10")})
+	c.Assert(err, IsNil)
+	<-updates
+	c.Assert(w.Process(context.Background()), IsNil)
+	first := (<-updates).Hash
+
+	c.Assert(w.Expire(context.Background(), 119), IsNil)
+	c.Check(updates, HasLen, 0)
+
+	// Not applied, and can no longer be
+	c.Assert(w.Expire(context.Background(), 120), IsNil)
+	withdrawal := <-updates
+	c.Check(withdrawal.State, Equals, Queued)
+	l.current = 130
+	c.Assert(w.Process(context.Background()), IsNil)
+	withdrawal = <-updates
+	c.Check(withdrawal.State, Equals, Submitted)
+	c.Check(withdrawal.Attempts, Equals, 2)
+	c.Check(withdrawal.Sequence, Equals, uint32(5))
+	c.Check(withdrawal.LastLedgerSequence, Equals, uint32(150))
+	c.Check(withdrawal.Hash, Not(Equals), first)
+
+	// Validated, but missed by the stream
+	msg := validated(l.last(c), 140, data.TesSUCCESS)
+	l.validated[withdrawal.Hash] = &websockets.TxResult{TransactionWithMetaData: msg.Transaction, Validated: true}
+	c.Assert(w.Expire(context.Background(), 150), IsNil)
+	withdrawal = <-updates
+	c.Check(withdrawal.State, Equals, Succeeded)
+	c.Check(withdrawal.LedgerSequence, Equals, uint32(140))
+
+	// Sequence number used by something else
+	_, err = w.Queue(context.Background(), Request{ID: "b", Destination: customer, Amount: amount(c, "10")})
+	c.Assert(err, IsNil)
+	<-updates
+	c.Assert(w.Process(context.Background()), IsNil)
+	withdrawal = <-updates
+	l.sequence = withdrawal.Sequence + 1
+	c.Assert(w.Expire(context.Background(), withdrawal.LastLedgerSequence), IsNil)
+	withdrawal = <-updates
+	c.Check(withdrawal.State, Equals, Failed)
+	c.Check(withdrawal.Err, ErrorMatches, "Sequence 6 of "+hot+" was used, but not by .*")
+
+	// Too often
+	w.MaxAttempts = 1
+	_, err = w.Queue(context.Background(), Request{ID: "c", Destination: customer, Amount: amount(c, "10")})
+	c.Assert(err, IsNil)
+	<-updates
+	c.Assert(w.Process(context.Background()), IsNil)
+	withdrawal = <-updates
+	c.Assert(w.Expire(context.Background(), withdrawal.LastLedgerSequence), IsNil)
+	withdrawal = <-updates
+	c.Check(withdrawal.State, Equals, Failed)
+	c.Check(withdrawal.Err, ErrorMatches, "Withdrawal c was not applied after 1 attempts")
+}
+
+func (s *WithdrawalsSuite) TestRun(c *C) {
+	l := newLedger()
+	updates := make(chan *Withdrawal, 10)
+	w := newWallet(c, l, updates)
+	ledgers := make(chan *websockets.LedgerStreamMsg)
+	done := make(chan error)
+	go func() { done <- w.Run(context.Background(), ledgers) }()
+
+	_, err := w.Queue(context.Background(), Request{ID: "a", Destination: customer, Amount: amount(c, "10")})
+	c.Assert(err, IsNil)
+	c.Check((<-updates).State, Equals, Queued)
+	withdrawal := <-updates
+	c.Check(withdrawal.State, Equals, Submitted)
+	ledgers <- &websockets.LedgerStreamMsg{LedgerSequence: withdrawal.LastLedgerSequence}
+	c.Check((<-updates).State, Equals, Queued)
+	c.Check((<-updates).State, Equals, Submitted)
+	close(ledgers)
+	c.Check(<-done, IsNil)
+	c.Check(w.Subscription(), DeepEquals, websockets.SubscriptionOptions{Ledger: true, Accounts: []data.Account{account(c, hot)}})
+}